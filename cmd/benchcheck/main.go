@@ -0,0 +1,56 @@
+// Command benchcheck compares benchmark results against a baseline and fails
+// when a benchmark got slower or allocates more, see make bench.
+//
+//	benchcheck -baseline bench/baseline.txt -threshold 0.2 bench/current.txt
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/application/benchcheck"
+)
+
+func main() {
+	baselineFile := flag.String("baseline", "bench/baseline.txt", "Benchmark results to compare against")
+	threshold := flag.Float64("threshold", 0.2, "Relative growth of a metric that fails the check, e.g. 0.2 for 20%")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: benchcheck [-baseline file] [-threshold ratio] results.txt")
+		os.Exit(2)
+	}
+
+	baseline, err := readResults(*baselineFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(2)
+	}
+	current, err := readResults(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(2)
+	}
+
+	deltas, added := benchcheck.Compare(baseline, current, *threshold)
+	benchcheck.Write(os.Stdout, deltas, added)
+	if regressions := benchcheck.Regressions(deltas); len(regressions) > 0 {
+		fmt.Fprintf(os.Stderr, "\n%d metrics regressed by more than %.0f%%\n", len(regressions), *threshold*100)
+		os.Exit(1)
+	}
+}
+
+// readResults parses a file of benchmark results
+func readResults(filename string) (benchcheck.Results, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open benchmark results: %w", err)
+	}
+	defer file.Close()
+
+	results, err := benchcheck.Parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return results, nil
+}
@@ -3,43 +3,152 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/edgardnogueira/swagger-to-http/internal/application"
+	appsnapshot "github.com/edgardnogueira/swagger-to-http/internal/application/snapshot"
 	"github.com/edgardnogueira/swagger-to-http/internal/cli"
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
 	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/config"
 	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/extractor"
 	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/fs"
 	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/http"
 	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/reporter"
 	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/snapshot"
+	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/snapstore"
 	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/test"
-	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/validator"
-	"github.com/edgardnogueira/swagger-to-http/internal/version"
+	"github.com/edgardnogueira/swagger-to-http/pkg/plugin"
 )
 
 func main() {
 	// Initialize configuration
-	configProvider := config.NewConfig()
+	configProvider := config.NewConfigProvider()
 
-	// Create HTTP parser
-	httpParser := http.NewParser()
+	// Load the custom methods requests may use besides the standard ones
+	extraMethods, err := models.ParseExtraMethods(configProvider.GetStringSlice("executor.extra_methods"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(cli.ExitConfigError)
+	}
+
+	// Create HTTP parser, naming the requests without "@name" with the template
+	httpParser := http.NewParser(
+		http.WithNameTemplate(configProvider.GetString("test.name_template")),
+		http.WithExtraMethods(extraMethods),
+	)
+
+	// Load how asynchronous operations are polled
+	pollOptions, err := loadPollOptions(configProvider)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(cli.ExitConfigError)
+	}
+
+	// Load the timeouts of the phases of requests
+	timeouts, err := loadTimeouts(configProvider)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(cli.ExitConfigError)
+	}
+
+	// Load how idempotent requests are retried after transient errors
+	retryOptions, err := loadRetryOptions(configProvider)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(cli.ExitConfigError)
+	}
+
+	// Load when requests to a failing host fail fast
+	circuitBreaker, err := loadCircuitBreaker(configProvider)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(cli.ExitConfigError)
+	}
+
+	// Load the largest response body read
+	maxBodyBytes, err := models.ParseByteSize(configProvider.GetString("executor.max_body_bytes"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: executor.max_body_bytes: %s\n", err)
+		os.Exit(cli.ExitConfigError)
+	}
 
 	// Create HTTP executor
-	httpExecutor := http.NewExecutor(30*time.Second, nil)
+	httpExecutor := http.NewExecutor(timeouts.Request, nil,
+		http.WithTimeouts(timeouts),
+		http.WithPolling(pollOptions),
+		http.WithRetries(retryOptions),
+		http.WithCircuitBreaker(circuitBreaker),
+		http.WithExtraMethods(extraMethods),
+		http.WithMaxBodyBytes(maxBodyBytes),
+		http.WithGRPCDescriptorSets(configProvider.GetStringSlice("executor.grpc.descriptor_sets")),
+		http.WithCompression(models.CompressionOptions{
+			AcceptEncoding: configProvider.GetString("executor.accept_encoding"),
+			Decompress:     configProvider.GetBool("executor.decompress"),
+		}),
+	)
+
+	// Load numeric tolerances for snapshot comparison
+	tolerances, err := loadTolerances(configProvider)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(cli.ExitConfigError)
+	}
 
-	// Create file system services
+	// Load the headers left out of response comparisons
+	ignoredHeaders, err := models.ParseHeaderIgnoreRules(
+		configProvider.GetStringSlice("snapshots.ignore_headers"),
+		configProvider.GetStringMap("snapshots.ignore_headers_by_tag"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid snapshots.ignore_headers: %s\n", err)
+		os.Exit(cli.ExitConfigError)
+	}
+
+	// Load how snapshots are compressed
+	snapshotCompression, err := snapstore.ParseCompression(configProvider.GetString("snapshots.compression"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(cli.ExitConfigError)
+	}
+
+	// Create file system services, storing snapshots where snapshots.store
+	// says and checking their version as snapshots.strict_version says once
+	// the command line has had its say
 	fileWriter := fs.NewFileWriter()
-	snapshotManager := snapshot.NewSnapshotManager(fileWriter)
+	snapshotStore := snapstore.NewLazy(func() (snapstore.Store, error) {
+		return snapstore.Open(configProvider.GetString("snapshots.store"))
+	})
+	snapshotManager := snapshot.NewSnapshotManager(
+		snapshot.WithStore(snapshotStore),
+		snapshot.WithDedupe(configProvider.GetBool("snapshots.dedupe")),
+		snapshot.WithCompression(snapshotCompression),
+		snapshot.WithStrictVersionFunc(func() bool {
+			return configProvider.GetBool("snapshots.strict_version")
+		}),
+		snapshot.WithIgnoredHeaders(ignoredHeaders),
+		snapshot.WithCompareOptions(appsnapshot.CompareOptions{
+			IgnoreArrayOrder: configProvider.GetBool("snapshots.ignore_array_order"),
+			ArrayOrderKey:    configProvider.GetString("snapshots.array_order_key"),
+			Tolerances:       tolerances,
+		}),
+	)
+
+	// Resolve variables from variables.json/.http-env files next to the tests
+	variableScopes := application.WithVariableResolver(extractor.NewVariableScopeService())
+
+	// Register the output and report formats of plugins
+	if err := loadPlugins(configProvider, plugin.DefaultRegistry); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(cli.ExitConfigError)
+	}
 
 	// Create basic test services
-	testRunner := test.NewTestRunnerService(httpExecutor, snapshotManager, fileWriter)
+	runnerSnapshots := snapshot.NewRunnerAdapter(snapshotManager)
+	testRunner := application.NewTestRunnerService(httpExecutor, runnerSnapshots, fileWriter, variableScopes)
 	testReporter := reporter.NewTestReporterService()
 
 	// Create advanced test services
-	variableExtractor := extractor.NewVariableExtractorService()
-	schemaValidator := validator.NewSchemaValidatorService()
-	advancedTestRunner := test.NewAdvancedTestRunnerService(httpExecutor, snapshotManager, fileWriter)
+	advancedTestRunner := test.NewAdvancedTestRunnerService(httpExecutor, runnerSnapshots, fileWriter, variableScopes)
 
 	// Initialize CLI
 	if err := cli.Execute(
@@ -52,6 +161,130 @@ func main() {
 		fileWriter,
 	); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
-		os.Exit(1)
+		os.Exit(cli.ExitCode(err))
+	}
+}
+
+// loadTolerances reads the numeric tolerances configured as "<path> [abs=<n>] [rel=<n>]"
+func loadTolerances(configProvider application.ConfigProvider) (map[string]models.Tolerance, error) {
+	tolerances := make(map[string]models.Tolerance)
+	for _, entry := range configProvider.GetStringSlice("snapshots.tolerances") {
+		fields := strings.SplitN(strings.TrimSpace(entry), " ", 2)
+		spec := ""
+		if len(fields) > 1 {
+			spec = fields[1]
+		}
+
+		tolerance, err := models.ParseTolerance(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tolerance for %s: %w", fields[0], err)
+		}
+		tolerances[fields[0]] = tolerance
+	}
+	return tolerances, nil
+}
+
+// loadPollOptions reads how 202 Accepted responses of asynchronous operations are polled
+func loadPollOptions(configProvider application.ConfigProvider) (models.PollOptions, error) {
+	options := models.PollOptions{Enabled: configProvider.GetBool("executor.poll.enabled")}
+
+	interval, err := time.ParseDuration(configProvider.GetString("executor.poll.interval"))
+	if err != nil || interval <= 0 {
+		return models.PollOptions{}, fmt.Errorf("invalid executor.poll.interval %q", configProvider.GetString("executor.poll.interval"))
+	}
+	options.Interval = interval
+
+	timeout, err := time.ParseDuration(configProvider.GetString("executor.poll.timeout"))
+	if err != nil || timeout <= 0 {
+		return models.PollOptions{}, fmt.Errorf("invalid executor.poll.timeout %q", configProvider.GetString("executor.poll.timeout"))
+	}
+	options.Timeout = timeout
+
+	return options, nil
+}
+
+// loadRetryOptions reads how many times idempotent requests answered with a
+// transient error status are retried, and the backoff between attempts
+func loadRetryOptions(configProvider application.ConfigProvider) (models.RetryOptions, error) {
+	options := models.RetryOptions{Max: configProvider.GetInt("executor.retry.max")}
+	if options.Max < 0 {
+		return models.RetryOptions{}, fmt.Errorf("invalid executor.retry.max %d", options.Max)
+	}
+
+	initial, err := time.ParseDuration(configProvider.GetString("executor.retry.initial_backoff"))
+	if err != nil || initial <= 0 {
+		return models.RetryOptions{}, fmt.Errorf("invalid executor.retry.initial_backoff %q", configProvider.GetString("executor.retry.initial_backoff"))
+	}
+	options.InitialBackoff = initial
+
+	max, err := time.ParseDuration(configProvider.GetString("executor.retry.max_backoff"))
+	if err != nil || max < initial {
+		return models.RetryOptions{}, fmt.Errorf("invalid executor.retry.max_backoff %q", configProvider.GetString("executor.retry.max_backoff"))
+	}
+	options.MaxBackoff = max
+
+	return options, nil
+}
+
+// loadCircuitBreaker reads after how many consecutive connection errors the
+// requests to a host fail fast, and for how long, where a threshold of 0
+// turns the breaker off
+func loadCircuitBreaker(configProvider application.ConfigProvider) (models.CircuitBreakerOptions, error) {
+	options := models.CircuitBreakerOptions{Threshold: configProvider.GetInt("executor.circuit_breaker.threshold")}
+	if options.Threshold < 0 {
+		return models.CircuitBreakerOptions{}, fmt.Errorf("invalid executor.circuit_breaker.threshold %d", options.Threshold)
+	}
+
+	coolDown, err := time.ParseDuration(configProvider.GetString("executor.circuit_breaker.cool_down"))
+	if err != nil || coolDown <= 0 {
+		return models.CircuitBreakerOptions{}, fmt.Errorf("invalid executor.circuit_breaker.cool_down %q", configProvider.GetString("executor.circuit_breaker.cool_down"))
+	}
+	options.CoolDown = coolDown
+
+	return options, nil
+}
+
+// loadPlugins registers the generators and reporters of the executables
+// configured by format in plugins.generators and plugins.reporters, and loads
+// the Go plugins listed in plugins.go
+func loadPlugins(configProvider application.ConfigProvider, registry *plugin.Registry) error {
+	for format, command := range configProvider.GetStringMap("plugins.generators") {
+		commandLine, ok := command.(string)
+		if !ok || strings.TrimSpace(commandLine) == "" {
+			return fmt.Errorf("invalid plugins.generators.%s: must be a command", format)
+		}
+		registry.RegisterGenerator(format, plugin.NewExecutable(commandLine))
+	}
+	for format, command := range configProvider.GetStringMap("plugins.reporters") {
+		commandLine, ok := command.(string)
+		if !ok || strings.TrimSpace(commandLine) == "" {
+			return fmt.Errorf("invalid plugins.reporters.%s: must be a command", format)
+		}
+		registry.RegisterReporter(format, plugin.NewExecutable(commandLine))
+	}
+	for _, path := range configProvider.GetStringSlice("plugins.go") {
+		if err := plugin.LoadGoPlugin(path, registry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadTimeouts reads the timeouts of the connect, TLS handshake, response
+// header and overall phases of requests, where 0 leaves a phase unbounded
+func loadTimeouts(configProvider application.ConfigProvider) (models.Timeouts, error) {
+	var timeouts models.Timeouts
+	for key, timeout := range map[string]*time.Duration{
+		"executor.timeouts.connect":         &timeouts.Connect,
+		"executor.timeouts.tls_handshake":   &timeouts.TLSHandshake,
+		"executor.timeouts.response_header": &timeouts.ResponseHeader,
+		"executor.timeouts.request":         &timeouts.Request,
+	} {
+		value, err := time.ParseDuration(configProvider.GetString(key))
+		if err != nil || value < 0 {
+			return models.Timeouts{}, fmt.Errorf("invalid %s %q", key, configProvider.GetString(key))
+		}
+		*timeout = value
 	}
+	return timeouts, nil
 }
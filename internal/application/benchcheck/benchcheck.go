@@ -0,0 +1,165 @@
+// Package benchcheck compares the output of Go benchmarks against a baseline
+// to catch performance regressions of the hot paths before a release.
+package benchcheck
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Metrics compared between runs, as reported with -benchmem
+const (
+	MetricTime   = "ns/op"
+	MetricBytes  = "B/op"
+	MetricAllocs = "allocs/op"
+)
+
+// cpuSuffix matches the GOMAXPROCS suffix of benchmark names, e.g. "-8"
+var cpuSuffix = regexp.MustCompile(`-\d+$`)
+
+// Results holds the values of the metrics of each benchmark, by package and
+// name, with one value per run
+type Results map[string]map[string][]float64
+
+// Delta is the change of a metric of a benchmark from the baseline
+type Delta struct {
+	Benchmark string
+	Metric    string
+	Old       float64
+	New       float64
+	Regressed bool
+}
+
+// Change returns the relative change from the baseline, e.g. 0.1 for 10% more
+func (d Delta) Change() float64 {
+	if d.Old == 0 {
+		if d.New == 0 {
+			return 0
+		}
+		return 1
+	}
+	return (d.New - d.Old) / d.Old
+}
+
+// Parse reads the output of go test -bench, keying the benchmarks by the
+// package of the preceding "pkg:" line
+func Parse(r io.Reader) (Results, error) {
+	results := make(Results)
+	pkg := ""
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "pkg:") {
+			pkg = strings.TrimSpace(strings.TrimPrefix(line, "pkg:"))
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 4 || !strings.HasPrefix(fields[0], "Benchmark") {
+			continue
+		}
+
+		name := cpuSuffix.ReplaceAllString(fields[0], "")
+		if pkg != "" {
+			name = pkg + "." + name
+		}
+		// Values come in pairs after the number of iterations: 1234 ns/op
+		for i := 2; i+1 < len(fields); i += 2 {
+			value, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q of %s", fields[i], name)
+			}
+			if results[name] == nil {
+				results[name] = make(map[string][]float64)
+			}
+			results[name][fields[i+1]] = append(results[name][fields[i+1]], value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read benchmark results: %w", err)
+	}
+	return results, nil
+}
+
+// Compare returns the changes of the time and memory metrics of the
+// benchmarks of current that are in the baseline, comparing the medians of
+// their runs. A metric regresses when it grows by more than threshold, e.g.
+// 0.2 for 20%. The names of the benchmarks missing from the baseline are
+// returned apart.
+func Compare(baseline, current Results, threshold float64) (deltas []Delta, added []string) {
+	names := make([]string, 0, len(current))
+	for name := range current {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		old, ok := baseline[name]
+		if !ok {
+			added = append(added, name)
+			continue
+		}
+		for _, metric := range []string{MetricTime, MetricBytes, MetricAllocs} {
+			if len(old[metric]) == 0 || len(current[name][metric]) == 0 {
+				continue
+			}
+			delta := Delta{Benchmark: name, Metric: metric, Old: median(old[metric]), New: median(current[name][metric])}
+			delta.Regressed = delta.Change() > threshold
+			deltas = append(deltas, delta)
+		}
+	}
+	return deltas, added
+}
+
+// Regressions returns the deltas that regressed
+func Regressions(deltas []Delta) []Delta {
+	var regressed []Delta
+	for _, delta := range deltas {
+		if delta.Regressed {
+			regressed = append(regressed, delta)
+		}
+	}
+	return regressed
+}
+
+// Write prints the deltas as a table, followed by the benchmarks missing from
+// the baseline
+func Write(w io.Writer, deltas []Delta, added []string) {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "BENCHMARK\tMETRIC\tBASELINE\tCURRENT\tCHANGE\t")
+	for _, delta := range deltas {
+		status := ""
+		if delta.Regressed {
+			status = "REGRESSION"
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%+.1f%%\t%s\n", delta.Benchmark, delta.Metric,
+			formatValue(delta.Old), formatValue(delta.New), delta.Change()*100, status)
+	}
+	table.Flush()
+
+	for _, name := range added {
+		fmt.Fprintf(w, "%s: not in the baseline\n", name)
+	}
+}
+
+// median returns the median of values, which is less sensitive to a noisy
+// run than the mean
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
+
+// formatValue formats a metric value without needless decimals
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
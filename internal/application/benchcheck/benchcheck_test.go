@@ -0,0 +1,52 @@
+package benchcheck
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const baselineOutput = `goos: linux
+goarch: amd64
+pkg: example.com/parser
+BenchmarkParse-8     	    1000	      1000 ns/op	     512 B/op	      10 allocs/op
+BenchmarkParse-8     	    1000	      1200 ns/op	     512 B/op	      10 allocs/op
+BenchmarkParse-8     	    1000	      1100 ns/op	     512 B/op	      10 allocs/op
+BenchmarkCompare/equal-8	     500	      2000 ns/op	    1024 B/op	      20 allocs/op
+PASS
+ok  	example.com/parser	1.234s
+`
+
+func TestParse(t *testing.T) {
+	results, err := Parse(strings.NewReader(baselineOutput))
+	require.NoError(t, err)
+
+	assert.Equal(t, []float64{1000, 1200, 1100}, results["example.com/parser.BenchmarkParse"][MetricTime])
+	assert.Equal(t, []float64{20}, results["example.com/parser.BenchmarkCompare/equal"][MetricAllocs])
+}
+
+func TestCompare(t *testing.T) {
+	baseline, err := Parse(strings.NewReader(baselineOutput))
+	require.NoError(t, err)
+	current, err := Parse(strings.NewReader(`pkg: example.com/parser
+BenchmarkParse-4     	    1000	      1150 ns/op	     512 B/op	      10 allocs/op
+BenchmarkCompare/equal-4	     500	      2100 ns/op	    1024 B/op	      30 allocs/op
+BenchmarkNew-4       	     500	      2100 ns/op
+`))
+	require.NoError(t, err)
+
+	deltas, added := Compare(baseline, current, 0.2)
+	assert.Equal(t, []string{"example.com/parser.BenchmarkNew"}, added)
+	assert.Len(t, deltas, 6)
+	assert.Equal(t, []Delta{
+		{Benchmark: "example.com/parser.BenchmarkCompare/equal", Metric: MetricAllocs, Old: 20, New: 30, Regressed: true},
+	}, Regressions(deltas))
+
+	var out bytes.Buffer
+	Write(&out, deltas, added)
+	assert.Contains(t, out.String(), "example.com/parser.BenchmarkCompare/equal  allocs/op  20        30       +50.0%  REGRESSION")
+	assert.Contains(t, out.String(), "example.com/parser.BenchmarkNew: not in the baseline")
+}
@@ -0,0 +1,40 @@
+// Package bodytemplate renders the block helpers a .http request body may
+// embed, so a single request can build payloads whose shape depends on
+// environment variables or data rows. Templates are rendered just before a
+// request is sent, ahead of the usual {{variable}} substitution.
+//
+// Supported syntax: {{#each list}}...{{/each}}, {{#if cond}}...{{else}}...{{/if}},
+// {{#unless cond}}...{{/unless}} and, inside #each, {{this}}, {{this.field}},
+// {{@index}}, {{@first}} and {{@last}}. A condition is a reference, optionally
+// compared to a literal with == or !=. Any other {{...}} is left untouched.
+package bodytemplate
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Render evaluates the block helpers of a body against the variables of a
+// request. A body without blocks is returned as is.
+func Render(text string, variables map[string]string) (string, error) {
+	if !HasBlocks(text) {
+		return text, nil
+	}
+
+	nodes, err := parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid body template: %w", err)
+	}
+
+	var out strings.Builder
+	r := &renderer{variables: variables}
+	if err := r.render(&out, nodes); err != nil {
+		return "", fmt.Errorf("failed to render body template: %w", err)
+	}
+	return out.String(), nil
+}
+
+// HasBlocks reports whether a body uses any block helper
+func HasBlocks(text string) bool {
+	return strings.Contains(text, "{{#")
+}
@@ -0,0 +1,26 @@
+package bodytemplate
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func BenchmarkRender(b *testing.B) {
+	items := make([]string, 100)
+	for i := range items {
+		items[i] = fmt.Sprintf(`{"sku": "SKU-%d", "qty": %d}`, i, i%5+1)
+	}
+	variables := map[string]string{
+		"env":   "staging",
+		"items": "[" + strings.Join(items, ", ") + "]",
+	}
+	body := `{"env": "{{#if env == "prod"}}live{{else}}test{{/if}}", "lines": [{{#each items}}{"sku": "{{this.sku}}", "qty": {{this.qty}}}{{#unless @last}},{{/unless}}{{/each}}], "token": "{{token}}"}`
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := Render(body, variables); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
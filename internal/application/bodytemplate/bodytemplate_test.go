@@ -0,0 +1,65 @@
+package bodytemplate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	variables := map[string]string{
+		"env":   "staging",
+		"ids":   "1, 2, 3",
+		"items": `[{"sku": "A-1", "qty": 2, "tags": ["new"]}, {"sku": "B-2", "qty": 1, "tags": []}]`,
+		"debug": "false",
+		"token": "abc",
+	}
+
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{"no blocks", `{"token": "{{token}}"}`, `{"token": "{{token}}"}`},
+		{"each over comma list", `[{{#each ids}}{{this}}{{#unless @last}},{{/unless}}{{/each}}]`, `[1,2,3]`},
+		{"each over JSON array", `{{#each items}}{{@index}}:{{this.sku}}x{{this.qty}} {{/each}}`, `0:A-1x2 1:B-2x1 `},
+		{"object items as JSON", `{{#each items}}{{#if @first}}{{this.tags}}{{/if}}{{/each}}`, `["new"]`},
+		{"nested each", `{{#each items}}{{#each this.tags}}{{this}}{{else}}-{{/each}}{{/each}}`, `new-`},
+		{"undefined list takes else", `{{#each missing}}x{{else}}none{{/each}}`, `none`},
+		{"if comparison", `{{#if env == "prod"}}live{{else}}{{env}}{{/if}}`, `{{env}}`},
+		{"if not equal", `{{#if env != 'prod'}}test{{/if}}`, `test`},
+		{"falsy variable", `{{#if debug}}on{{else}}off{{/if}}`, `off`},
+		{"other references are kept", `{{#if token}}Bearer {{token}}{{/if}}`, `Bearer {{token}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Render(tt.body, variables)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		err  string
+	}{
+		{"unclosed block", `{{#each ids}}{{this}}`, "{{#each ids}} is never closed"},
+		{"mismatched close", `{{#if a}}x{{/each}}`, "{{/each}} closes {{#if a}}"},
+		{"unknown helper", `{{#with a}}{{/with}}`, "unknown block helper"},
+		{"item outside each", `{{#if a}}{{this}}{{/if}}`, "only available inside #each"},
+		{"invalid array", `{{#each bad}}{{/each}}`, "not a valid JSON array"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Render(tt.body, map[string]string{"a": "1", "bad": "[1,"})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package bodytemplate
+
+import (
+	"fmt"
+	"strings"
+)
+
+// node is a part of a parsed template
+type node interface{}
+
+// textNode is literal text, including references left for substitution
+type textNode struct {
+	text string
+}
+
+// valueNode is a reference to the current item of an #each ({{this}},
+// {{this.field}}, {{@index}}, ...)
+type valueNode struct {
+	ref string
+	tag string
+}
+
+// blockNode is an #each, #if or #unless block
+type blockNode struct {
+	kind     string
+	arg      string
+	tag      string
+	body     []node
+	elseBody []node
+	inElse   bool
+}
+
+// Block helpers
+const (
+	blockEach   = "each"
+	blockIf     = "if"
+	blockUnless = "unless"
+)
+
+// parse splits a template into text, values and nested blocks
+func parse(text string) ([]node, error) {
+	root := &blockNode{}
+	stack := []*blockNode{root}
+
+	appendNode := func(n node) {
+		current := stack[len(stack)-1]
+		if current.inElse {
+			current.elseBody = append(current.elseBody, n)
+		} else {
+			current.body = append(current.body, n)
+		}
+	}
+
+	for len(text) > 0 {
+		start := strings.Index(text, "{{")
+		if start < 0 {
+			appendNode(&textNode{text: text})
+			break
+		}
+		end := strings.Index(text[start:], "}}")
+		if end < 0 {
+			appendNode(&textNode{text: text})
+			break
+		}
+		end += start + 2
+		if start > 0 {
+			appendNode(&textNode{text: text[:start]})
+		}
+		tag := text[start:end]
+		content := strings.TrimSpace(tag[2 : len(tag)-2])
+		text = text[end:]
+
+		switch {
+		case strings.HasPrefix(content, "#"):
+			kind, arg, _ := strings.Cut(content[1:], " ")
+			arg = strings.TrimSpace(arg)
+			if kind != blockEach && kind != blockIf && kind != blockUnless {
+				return nil, fmt.Errorf("unknown block helper %s", tag)
+			}
+			if arg == "" {
+				return nil, fmt.Errorf("%s is missing its argument", tag)
+			}
+			block := &blockNode{kind: kind, arg: arg, tag: tag}
+			appendNode(block)
+			stack = append(stack, block)
+
+		case strings.HasPrefix(content, "/"):
+			current := stack[len(stack)-1]
+			if current == root {
+				return nil, fmt.Errorf("unexpected %s", tag)
+			}
+			if kind := strings.TrimSpace(content[1:]); kind != current.kind {
+				return nil, fmt.Errorf("%s closes %s", tag, current.tag)
+			}
+			stack = stack[:len(stack)-1]
+
+		case content == "else":
+			current := stack[len(stack)-1]
+			if current == root || current.inElse {
+				return nil, fmt.Errorf("unexpected %s", tag)
+			}
+			current.inElse = true
+
+		case isItemRef(content):
+			appendNode(&valueNode{ref: content, tag: tag})
+
+		default:
+			appendNode(&textNode{text: tag})
+		}
+	}
+
+	if len(stack) > 1 {
+		return nil, fmt.Errorf("%s is never closed", stack[len(stack)-1].tag)
+	}
+	return root.body, nil
+}
+
+// isItemRef reports whether a reference reads the current item of an #each
+func isItemRef(ref string) bool {
+	return ref == "this" || strings.HasPrefix(ref, "this.") || strings.HasPrefix(ref, "@")
+}
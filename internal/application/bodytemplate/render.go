@@ -0,0 +1,239 @@
+package bodytemplate
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// scope is the current item of an #each
+type scope struct {
+	item  interface{}
+	index int
+	count int
+}
+
+// renderer evaluates parsed templates against the variables of a request
+type renderer struct {
+	variables map[string]string
+	scopes    []scope
+}
+
+func (r *renderer) render(out *strings.Builder, nodes []node) error {
+	for _, n := range nodes {
+		switch n := n.(type) {
+		case *textNode:
+			out.WriteString(n.text)
+
+		case *valueNode:
+			value, found, err := r.item(n.ref)
+			if err != nil {
+				return err
+			}
+			if !found {
+				return fmt.Errorf("%s: the current item has no such field", n.tag)
+			}
+			out.WriteString(format(value))
+
+		case *blockNode:
+			if err := r.renderBlock(out, n); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (r *renderer) renderBlock(out *strings.Builder, block *blockNode) error {
+	if block.kind == blockEach {
+		items, err := r.list(block.arg)
+		if err != nil {
+			return fmt.Errorf("%s: %w", block.tag, err)
+		}
+		if len(items) == 0 {
+			return r.render(out, block.elseBody)
+		}
+		for i, item := range items {
+			r.scopes = append(r.scopes, scope{item: item, index: i, count: len(items)})
+			err := r.render(out, block.body)
+			r.scopes = r.scopes[:len(r.scopes)-1]
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	ok, err := r.condition(block.arg)
+	if err != nil {
+		return fmt.Errorf("%s: %w", block.tag, err)
+	}
+	if block.kind == blockUnless {
+		ok = !ok
+	}
+	if ok {
+		return r.render(out, block.body)
+	}
+	return r.render(out, block.elseBody)
+}
+
+// lookup resolves a reference to the current item or to a variable
+func (r *renderer) lookup(ref string) (interface{}, bool, error) {
+	if isItemRef(ref) {
+		return r.item(ref)
+	}
+	value, ok := r.variables[ref]
+	return value, ok, nil
+}
+
+// item resolves a reference to the current item of the innermost #each
+func (r *renderer) item(ref string) (interface{}, bool, error) {
+	if len(r.scopes) == 0 {
+		return nil, false, fmt.Errorf("{{%s}} is only available inside #each", ref)
+	}
+	current := r.scopes[len(r.scopes)-1]
+
+	switch ref {
+	case "this":
+		return current.item, true, nil
+	case "@index":
+		return current.index, true, nil
+	case "@first":
+		return current.index == 0, true, nil
+	case "@last":
+		return current.index == current.count-1, true, nil
+	}
+	if !strings.HasPrefix(ref, "this.") {
+		return nil, false, fmt.Errorf("unknown reference {{%s}}", ref)
+	}
+
+	value := current.item
+	for _, field := range strings.Split(strings.TrimPrefix(ref, "this."), ".") {
+		switch container := value.(type) {
+		case map[string]interface{}:
+			next, ok := container[field]
+			if !ok {
+				return nil, false, nil
+			}
+			value = next
+		case []interface{}:
+			index, err := strconv.Atoi(field)
+			if err != nil || index < 0 || index >= len(container) {
+				return nil, false, nil
+			}
+			value = container[index]
+		default:
+			return nil, false, nil
+		}
+	}
+	return value, true, nil
+}
+
+// list resolves the argument of an #each: a JSON array or a comma-separated
+// list. An undefined variable is an empty list.
+func (r *renderer) list(ref string) ([]interface{}, error) {
+	value, found, err := r.lookup(ref)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	switch value := value.(type) {
+	case []interface{}:
+		return value, nil
+	case string:
+		text := strings.TrimSpace(value)
+		if text == "" {
+			return nil, nil
+		}
+		if strings.HasPrefix(text, "[") {
+			var items []interface{}
+			decoder := json.NewDecoder(strings.NewReader(text))
+			decoder.UseNumber()
+			if err := decoder.Decode(&items); err != nil {
+				return nil, fmt.Errorf("%s is not a valid JSON array: %w", ref, err)
+			}
+			return items, nil
+		}
+		var items []interface{}
+		for _, item := range strings.Split(text, ",") {
+			items = append(items, strings.TrimSpace(item))
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("%s is not a list", ref)
+	}
+}
+
+// condition evaluates the argument of an #if or #unless: a reference, true
+// when set to a non-empty value other than false, 0 or null, or a reference
+// compared to a literal with == or !=
+func (r *renderer) condition(arg string) (bool, error) {
+	for _, op := range []string{"==", "!="} {
+		left, right, ok := strings.Cut(arg, op)
+		if !ok {
+			continue
+		}
+		value, found, err := r.lookup(strings.TrimSpace(left))
+		if err != nil {
+			return false, err
+		}
+		if !found {
+			value = ""
+		}
+		equal := format(value) == unquote(strings.TrimSpace(right))
+		return equal == (op == "=="), nil
+	}
+
+	value, found, err := r.lookup(arg)
+	if err != nil || !found {
+		return false, err
+	}
+	return truthy(value), nil
+}
+
+// truthy reports whether a value counts as set in a condition
+func truthy(value interface{}) bool {
+	switch value := value.(type) {
+	case nil:
+		return false
+	case []interface{}:
+		return len(value) > 0
+	case map[string]interface{}:
+		return len(value) > 0
+	}
+	switch format(value) {
+	case "", "false", "0", "null":
+		return false
+	}
+	return true
+}
+
+// format renders a value into the body, objects and arrays as JSON
+func format(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return value
+	case json.Number:
+		return value.String()
+	case bool:
+		return strconv.FormatBool(value)
+	case int:
+		return strconv.Itoa(value)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// unquote strips the quotes around a literal of a comparison
+func unquote(literal string) string {
+	if len(literal) >= 2 && (literal[0] == '"' || literal[0] == '\'') && literal[len(literal)-1] == literal[0] {
+		return literal[1 : len(literal)-1]
+	}
+	return literal
+}
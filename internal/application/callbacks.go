@@ -0,0 +1,172 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// DefaultCallbackAddr is the address the callback listener binds to: a free
+// port on the loopback interface
+const DefaultCallbackAddr = "127.0.0.1:0"
+
+// maxCallbackBody is the largest callback payload kept
+const maxCallbackBody = 1 << 20
+
+// callbackListener is a temporary HTTP server receiving the callbacks of a
+// request. Callback "name" is expected on "<base URL>/name".
+type callbackListener struct {
+	server  *http.Server
+	baseURL string
+
+	mu       sync.Mutex
+	received map[string][]models.ReceivedCallback
+	arrived  chan struct{}
+}
+
+// startCallbackListener starts a callback listener on the address. Callbacks are
+// sent to publicURL when set, e.g. a tunnel to the listener, or to its local address.
+func startCallbackListener(addr, publicURL string) (*callbackListener, error) {
+	if addr == "" {
+		addr = DefaultCallbackAddr
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start callback listener: %w", err)
+	}
+
+	l := &callbackListener{
+		baseURL:  strings.TrimSuffix(publicURL, "/"),
+		received: make(map[string][]models.ReceivedCallback),
+		arrived:  make(chan struct{}, 1),
+	}
+	if l.baseURL == "" {
+		l.baseURL = "http://" + listener.Addr().String()
+	}
+	l.server = &http.Server{Handler: http.HandlerFunc(l.handle), ReadHeaderTimeout: 10 * time.Second}
+
+	go l.server.Serve(listener)
+
+	return l, nil
+}
+
+// URL returns the URL callback name is expected on
+func (l *callbackListener) URL(name string) string {
+	return l.baseURL + "/" + name
+}
+
+// handle records a callback, named by the first segment of its path
+func (l *callbackListener) handle(w http.ResponseWriter, r *http.Request) {
+	name := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)[0]
+	body, _ := io.ReadAll(io.LimitReader(r.Body, maxCallbackBody))
+
+	l.mu.Lock()
+	l.received[name] = append(l.received[name], models.ReceivedCallback{
+		Name:       name,
+		Method:     r.Method,
+		Path:       r.URL.RequestURI(),
+		Headers:    r.Header.Clone(),
+		Body:       string(body),
+		ReceivedAt: time.Now(),
+	})
+	l.mu.Unlock()
+
+	// Wake up the waiting runner
+	select {
+	case l.arrived <- struct{}{}:
+	default:
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// wait returns the first callback received for the expectation, waiting up to
+// its timeout
+func (l *callbackListener) wait(ctx context.Context, expectation models.CallbackExpectation) (*models.ReceivedCallback, error) {
+	timer := time.NewTimer(expectation.TimeoutOrDefault())
+	defer timer.Stop()
+
+	for {
+		l.mu.Lock()
+		for _, callback := range l.received[expectation.Name] {
+			if expectation.Method == "" || strings.EqualFold(callback.Method, expectation.Method) {
+				l.mu.Unlock()
+				return &callback, nil
+			}
+		}
+		l.mu.Unlock()
+
+		select {
+		case <-l.arrived:
+		case <-timer.C:
+			return nil, fmt.Errorf("callback %s not received within %s", expectation.Name, expectation.TimeoutOrDefault())
+		case <-ctx.Done():
+			return nil, fmt.Errorf("callback %s: %w", expectation.Name, ctx.Err())
+		}
+	}
+}
+
+// Close stops the listener
+func (l *callbackListener) Close() error {
+	return l.server.Close()
+}
+
+// awaitCallbacks waits for the callbacks of a request and checks their payloads,
+// returning the callbacks received and an assertion result per callback
+func awaitCallbacks(ctx context.Context, listener *callbackListener, callbacks []models.CallbackExpectation) ([]models.ReceivedCallback, []models.TestAssertionResult) {
+	var received []models.ReceivedCallback
+	var results []models.TestAssertionResult
+
+	for _, expectation := range callbacks {
+		result := models.TestAssertionResult{
+			Type:        "callback",
+			Source:      expectation.Name,
+			Expected:    expectation.String(),
+			Description: fmt.Sprintf("callback %s is received", expectation.Name),
+		}
+
+		callback, err := listener.wait(ctx, expectation)
+		if err != nil {
+			result.Error = err.Error()
+			results = append(results, result)
+			continue
+		}
+		received = append(received, *callback)
+		result.Actual = fmt.Sprintf("%s %s", callback.Method, callback.Path)
+
+		if missing := missingFields(callback.Body, expectation.Fields); len(missing) > 0 {
+			result.Error = fmt.Sprintf("callback %s payload is missing fields: %s", expectation.Name, strings.Join(missing, ", "))
+		} else {
+			result.Passed = true
+		}
+		results = append(results, result)
+	}
+
+	return received, results
+}
+
+// missingFields returns the fields a JSON object payload doesn't have
+func missingFields(body string, fields []string) []string {
+	if len(fields) == 0 {
+		return nil
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &payload); err != nil {
+		return fields
+	}
+	var missing []string
+	for _, field := range fields {
+		if _, ok := payload[field]; !ok {
+			missing = append(missing, field)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,53 @@
+package application
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallbackListener(t *testing.T) {
+	listener, err := startCallbackListener("", "")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	// The server sends the callback some time after the request
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		response, err := http.Post(listener.URL("onEvent")+"/events?id=1", "application/json", strings.NewReader(`{"id": 1, "status": "done"}`))
+		if err == nil {
+			response.Body.Close()
+		}
+	}()
+
+	received, results := awaitCallbacks(context.Background(), listener, []models.CallbackExpectation{
+		{Name: "onEvent", Method: "POST", Timeout: 5 * time.Second, Fields: []string{"id", "status"}},
+		{Name: "onEvent", Method: "POST", Timeout: time.Second, Fields: []string{"error"}},
+		{Name: "onCancel", Timeout: 50 * time.Millisecond},
+	})
+
+	require.Len(t, received, 2)
+	assert.Equal(t, "/onEvent/events?id=1", received[0].Path)
+	assert.JSONEq(t, `{"id": 1, "status": "done"}`, received[0].Body)
+
+	require.Len(t, results, 3)
+	assert.True(t, results[0].Passed)
+	assert.False(t, results[1].Passed)
+	assert.Equal(t, "callback onEvent payload is missing fields: error", results[1].Error)
+	assert.False(t, results[2].Passed)
+	assert.Equal(t, "callback onCancel not received within 50ms", results[2].Error)
+}
+
+func TestCallbackListenerPublicURL(t *testing.T) {
+	listener, err := startCallbackListener("127.0.0.1:0", "https://hooks.example.com/tunnel/")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	assert.Equal(t, "https://hooks.example.com/tunnel/onEvent", listener.URL("onEvent"))
+}
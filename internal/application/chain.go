@@ -0,0 +1,145 @@
+package application
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// chainReferencePattern matches references to earlier responses, e.g.
+// {{createUser.response.body.$.id}}, {{login.response.headers.Location}} or
+// {{createUser.response.status}}
+var chainReferencePattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_-]+)\.response\.(body|headers|status)(?:\.([^}]*?))?\s*\}\}`)
+
+// ResponseChain keeps the responses of named requests executed earlier in a
+// file so later requests can reference them
+type ResponseChain struct {
+	mu        sync.RWMutex
+	responses map[string]*models.HTTPResponse
+}
+
+// NewResponseChain creates an empty response chain
+func NewResponseChain() *ResponseChain {
+	return &ResponseChain{
+		responses: make(map[string]*models.HTTPResponse),
+	}
+}
+
+// Add records the response of a named request
+func (c *ResponseChain) Add(name string, response *models.HTTPResponse) {
+	if name == "" || response == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.responses[name] = response
+}
+
+// HasChainReferences reports whether any request in the file references an earlier response
+func HasChainReferences(file *models.HTTPFile) bool {
+	for _, request := range file.Requests {
+		if chainReferencePattern.MatchString(request.URL) || chainReferencePattern.MatchString(request.Body) {
+			return true
+		}
+		for _, value := range request.Headers {
+			if chainReferencePattern.MatchString(value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Resolve returns a copy of the request with all response references replaced by
+// their values, along with the resolved values keyed by reference
+func (c *ResponseChain) Resolve(request *models.HTTPRequest) (*models.HTTPRequest, map[string]string, error) {
+	resolved := make(map[string]string)
+	var resolveErr error
+
+	replace := func(text string) string {
+		return chainReferencePattern.ReplaceAllStringFunc(text, func(match string) string {
+			parts := chainReferencePattern.FindStringSubmatch(match)
+			value, err := c.lookup(parts[1], parts[2], strings.TrimSpace(parts[3]))
+			if err != nil {
+				if resolveErr == nil {
+					resolveErr = fmt.Errorf("failed to resolve %s: %w", match, err)
+				}
+				return match
+			}
+
+			// Record the value under the reference without braces
+			resolved[strings.Trim(match, "{} ")] = value
+			return value
+		})
+	}
+
+	// Replace references in a copy so the parsed request stays reusable, the
+	// values in the URL escaped for where they appear
+	result := *request
+	var url strings.Builder
+	last := 0
+	for _, loc := range chainReferencePattern.FindAllStringIndex(request.URL, -1) {
+		url.WriteString(request.URL[last:loc[0]])
+		url.WriteString(models.EscapeURLValue(request.URL, loc[0], replace(request.URL[loc[0]:loc[1]])))
+		last = loc[1]
+	}
+	url.WriteString(request.URL[last:])
+	result.URL = url.String()
+	if request.QueryParams != nil {
+		result.QueryParams = make(map[string][]string, len(request.QueryParams))
+		for name, values := range request.QueryParams {
+			for _, value := range values {
+				result.QueryParams[name] = append(result.QueryParams[name], replace(value))
+			}
+		}
+	}
+	result.Body = replace(request.Body)
+	if request.Headers != nil {
+		result.Headers = make(map[string]string, len(request.Headers))
+		for name, value := range request.Headers {
+			result.Headers[name] = replace(value)
+		}
+	}
+
+	if resolveErr != nil {
+		return nil, nil, resolveErr
+	}
+
+	return &result, resolved, nil
+}
+
+// lookup finds the value for a single response reference
+func (c *ResponseChain) lookup(name, part, selector string) (string, error) {
+	c.mu.RLock()
+	response, ok := c.responses[name]
+	c.mu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("no response recorded for request %q", name)
+	}
+
+	switch part {
+	case "status":
+		return strconv.Itoa(response.StatusCode), nil
+	case "headers":
+		values := response.Headers[http.CanonicalHeaderKey(selector)]
+		if len(values) == 0 {
+			values = response.Headers[selector]
+		}
+		if len(values) == 0 {
+			return "", fmt.Errorf("header %q not found", selector)
+		}
+		return values[0], nil
+	default:
+		body := string(response.Body)
+		if selector == "" || selector == "*" {
+			return body, nil
+		}
+		return models.ExtractJSONPath(body, selector)
+	}
+}
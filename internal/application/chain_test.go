@@ -0,0 +1,115 @@
+package application
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainReferencePattern(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected []string
+	}{
+		{text: "{{login.response.body.$.token}}", expected: []string{"login", "body", "$.token"}},
+		{text: "{{ login.response.body.$.token }}", expected: []string{"login", "body", "$.token"}},
+		{text: "{{create-user_2.response.headers.Location}}", expected: []string{"create-user_2", "headers", "Location"}},
+		{text: "{{login.response.status}}", expected: []string{"login", "status", ""}},
+		{text: "{{login.response.body}}", expected: []string{"login", "body", ""}},
+		{text: "{{login.response.cookies.id}}"},
+		{text: "{{login.request.body.$.user}}"},
+		{text: "{{baseUrl}}"},
+		{text: "{{env.login.response.body}}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			match := chainReferencePattern.FindStringSubmatch(tt.text)
+			if tt.expected == nil {
+				assert.Nil(t, match)
+				return
+			}
+			require.NotNil(t, match)
+			assert.Equal(t, tt.expected, match[1:])
+		})
+	}
+}
+
+func TestResponseChain_Resolve(t *testing.T) {
+	chain := NewResponseChain()
+	chain.Add("login", &models.HTTPResponse{
+		StatusCode: 201,
+		Headers:    map[string][]string{"Location": {"/users/a b"}, "x-trace": {"abc"}},
+		Body:       `{"token": "secret", "user": {"id": 7, "roles": ["admin"]}}`,
+	})
+
+	tests := []struct {
+		reference string
+		expected  string
+		err       string
+	}{
+		{reference: "{{login.response.body.$.token}}", expected: "secret"},
+		{reference: "{{login.response.body.$.user.id}}", expected: "7"},
+		{reference: "{{login.response.body.$.user.roles[0]}}", expected: "admin"},
+		{reference: "{{login.response.body}}", expected: `{"token": "secret", "user": {"id": 7, "roles": ["admin"]}}`},
+		{reference: "{{login.response.body.*}}", expected: `{"token": "secret", "user": {"id": 7, "roles": ["admin"]}}`},
+		{reference: "{{login.response.headers.location}}", expected: "/users/a b"},
+		{reference: "{{login.response.headers.x-trace}}", expected: "abc"},
+		{reference: "{{login.response.status}}", expected: "201"},
+		{reference: "{{login.response.headers.X-Missing}}", err: `failed to resolve {{login.response.headers.X-Missing}}: header "X-Missing" not found`},
+		{reference: "{{logout.response.status}}", err: `failed to resolve {{logout.response.status}}: no response recorded for request "logout"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.reference, func(t *testing.T) {
+			request := &models.HTTPRequest{
+				URL:     "https://api.example.com/items",
+				Headers: map[string]string{"X-Value": tt.reference, "Accept": "application/json"},
+				Body:    tt.reference,
+			}
+
+			resolved, values, err := chain.Resolve(request)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, resolved.Body)
+			assert.Equal(t, map[string]string{"X-Value": tt.expected, "Accept": "application/json"}, resolved.Headers)
+			assert.Equal(t, map[string]string{strings.Trim(tt.reference, "{}"): tt.expected}, values)
+
+			// The parsed request is left as it was
+			assert.Equal(t, tt.reference, request.Headers["X-Value"])
+		})
+	}
+}
+
+func TestResponseChain_ResolveURL(t *testing.T) {
+	chain := NewResponseChain()
+	chain.Add("createUser", &models.HTTPResponse{StatusCode: 201, Body: `{"id": "a/b", "name": "Ada Lovelace"}`})
+
+	resolved, _, err := chain.Resolve(&models.HTTPRequest{
+		URL:         "https://api.example.com/users/{{createUser.response.body.$.id}}?name={{createUser.response.body.$.name}}",
+		QueryParams: map[string][]string{"name": {"{{createUser.response.body.$.name}}"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "https://api.example.com/users/a%2Fb?name=Ada+Lovelace", resolved.URL)
+	assert.Equal(t, map[string][]string{"name": {"Ada Lovelace"}}, resolved.QueryParams)
+	assert.Nil(t, resolved.Headers)
+}
+
+func TestHasChainReferences(t *testing.T) {
+	assert.False(t, HasChainReferences(&models.HTTPFile{Requests: []models.HTTPRequest{
+		{URL: "{{baseUrl}}/users", Headers: map[string]string{"Authorization": "Bearer {{token}}"}},
+	}}))
+	assert.True(t, HasChainReferences(&models.HTTPFile{Requests: []models.HTTPRequest{
+		{URL: "{{baseUrl}}/users"},
+		{URL: "{{baseUrl}}/me", Headers: map[string]string{"Authorization": "Bearer {{login.response.body.$.token}}"}},
+	}}))
+	assert.True(t, HasChainReferences(&models.HTTPFile{Requests: []models.HTTPRequest{
+		{URL: "{{baseUrl}}/users", Body: `{"token": "{{login.response.body.$.token}}"}`},
+	}}))
+}
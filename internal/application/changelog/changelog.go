@@ -0,0 +1,238 @@
+// Package changelog describes how the responses of endpoints changed between
+// two versions of their stored snapshots, e.g. across git refs, as a
+// human-readable changelog for release notes.
+package changelog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kinds of changes of a snapshot
+const (
+	KindAdded   = "added"
+	KindRemoved = "removed"
+	KindChanged = "changed"
+)
+
+// Snapshot is the part of a stored snapshot the changelog compares
+type Snapshot struct {
+	Request    string // Method and URL of the recorded request, if any
+	StatusCode int
+	Body       string
+}
+
+// Change is how the response of an endpoint changed
+type Change struct {
+	Endpoint      string   `json:"endpoint"`
+	Path          string   `json:"path"`
+	Kind          string   `json:"kind"`
+	OldStatus     int      `json:"oldStatus,omitempty"`
+	NewStatus     int      `json:"newStatus,omitempty"`
+	AddedFields   []string `json:"addedFields,omitempty"`
+	RemovedFields []string `json:"removedFields,omitempty"`
+	ValuesChanged bool     `json:"valuesChanged,omitempty"`
+}
+
+// Parse parses the content of a snapshot: its comment lines, recording the
+// request after "# >", the status line, the headers and the body
+func Parse(content string) *Snapshot {
+	snapshot := &Snapshot{}
+	lines := strings.Split(content, "\n")
+	i := 0
+	for ; i < len(lines) && strings.HasPrefix(lines[i], "#"); i++ {
+		if request, ok := strings.CutPrefix(lines[i], "# >"); ok && snapshot.Request == "" {
+			snapshot.Request = strings.TrimSpace(request)
+		}
+	}
+	if i < len(lines) && strings.HasPrefix(lines[i], "HTTP ") {
+		if fields := strings.Fields(lines[i]); len(fields) > 1 {
+			snapshot.StatusCode, _ = strconv.Atoi(fields[1])
+		}
+		i++
+	}
+	for i < len(lines) && lines[i] != "" {
+		i++
+	}
+	if i < len(lines) {
+		snapshot.Body = strings.Join(lines[i+1:], "\n")
+	}
+	return snapshot
+}
+
+// Compare describes how the snapshot at a path changed, old or current being
+// nil when it was added or removed; nil when the response didn't change
+func Compare(path string, old, current *Snapshot) *Change {
+	change := &Change{Path: path}
+	switch {
+	case old == nil && current == nil:
+		return nil
+	case old == nil:
+		change.Kind = KindAdded
+		change.Endpoint = endpoint(path, current)
+		change.NewStatus = current.StatusCode
+		return change
+	case current == nil:
+		change.Kind = KindRemoved
+		change.Endpoint = endpoint(path, old)
+		change.OldStatus = old.StatusCode
+		return change
+	}
+
+	change.Kind = KindChanged
+	change.Endpoint = endpoint(path, current)
+	if old.StatusCode != current.StatusCode {
+		change.OldStatus = old.StatusCode
+		change.NewStatus = current.StatusCode
+	}
+
+	oldFields, oldJSON := fieldPaths(old.Body)
+	currentFields, currentJSON := fieldPaths(current.Body)
+	if oldJSON && currentJSON {
+		change.AddedFields = difference(currentFields, oldFields)
+		change.RemovedFields = difference(oldFields, currentFields)
+		change.ValuesChanged = !jsonEqual(old.Body, current.Body) && len(change.AddedFields) == 0 && len(change.RemovedFields) == 0
+	} else {
+		change.ValuesChanged = strings.TrimSpace(old.Body) != strings.TrimSpace(current.Body)
+	}
+
+	if change.OldStatus == change.NewStatus && len(change.AddedFields) == 0 && len(change.RemovedFields) == 0 && !change.ValuesChanged {
+		return nil
+	}
+	return change
+}
+
+// endpoint names the endpoint of a snapshot by its request, or its path when
+// it wasn't recorded
+func endpoint(path string, snapshot *Snapshot) string {
+	if snapshot.Request != "" {
+		return snapshot.Request
+	}
+	return path
+}
+
+// fieldPaths returns the paths of the fields of a JSON body, with "[]" for
+// the elements of arrays, and whether the body is JSON
+func fieldPaths(body string) (map[string]bool, bool) {
+	var value interface{}
+	if err := json.Unmarshal([]byte(body), &value); err != nil {
+		return nil, false
+	}
+	paths := make(map[string]bool)
+	collectPaths(value, "", paths)
+	return paths, true
+}
+
+func collectPaths(value interface{}, prefix string, paths map[string]bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			path := key
+			if prefix != "" {
+				path = prefix + "." + key
+			}
+			paths[path] = true
+			collectPaths(child, path, paths)
+		}
+	case []interface{}:
+		for _, child := range v {
+			collectPaths(child, prefix+"[]", paths)
+		}
+	}
+}
+
+// difference returns the paths of a that b doesn't have, sorted
+func difference(a, b map[string]bool) []string {
+	var paths []string
+	for path := range a {
+		if !b[path] {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// jsonEqual reports whether two JSON bodies hold the same value
+func jsonEqual(a, b string) bool {
+	var va, vb interface{}
+	if json.Unmarshal([]byte(a), &va) != nil || json.Unmarshal([]byte(b), &vb) != nil {
+		return a == b
+	}
+	ja, _ := json.Marshal(va)
+	jb, _ := json.Marshal(vb)
+	return string(ja) == string(jb)
+}
+
+// Write prints the changes as a Markdown changelog, grouped by kind and
+// sorted by endpoint
+func Write(w io.Writer, title string, changes []*Change) {
+	fmt.Fprintf(w, "## %s\n", title)
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "\nNo response changes.")
+		return
+	}
+
+	sorted := append([]*Change(nil), changes...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Endpoint != sorted[j].Endpoint {
+			return sorted[i].Endpoint < sorted[j].Endpoint
+		}
+		return sorted[i].Path < sorted[j].Path
+	})
+
+	for _, section := range []struct{ kind, title string }{
+		{KindAdded, "Added"},
+		{KindChanged, "Changed"},
+		{KindRemoved, "Removed"},
+	} {
+		var entries []*Change
+		for _, change := range sorted {
+			if change.Kind == section.kind {
+				entries = append(entries, change)
+			}
+		}
+		if len(entries) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(w, "\n### %s\n\n", section.title)
+		for _, change := range entries {
+			fmt.Fprintf(w, "- `%s`", change.Endpoint)
+			switch change.Kind {
+			case KindAdded:
+				fmt.Fprintf(w, " (status %d)\n", change.NewStatus)
+				continue
+			case KindRemoved:
+				fmt.Fprintln(w)
+				continue
+			}
+			fmt.Fprintln(w)
+			if change.OldStatus != change.NewStatus {
+				fmt.Fprintf(w, "  - status %d → %d\n", change.OldStatus, change.NewStatus)
+			}
+			if len(change.AddedFields) > 0 {
+				fmt.Fprintf(w, "  - added fields: %s\n", codeList(change.AddedFields))
+			}
+			if len(change.RemovedFields) > 0 {
+				fmt.Fprintf(w, "  - removed fields: %s\n", codeList(change.RemovedFields))
+			}
+			if change.ValuesChanged {
+				fmt.Fprintln(w, "  - response values changed")
+			}
+		}
+	}
+}
+
+// codeList formats names as a comma-separated list of code spans
+func codeList(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = "`" + name + "`"
+	}
+	return strings.Join(quoted, ", ")
+}
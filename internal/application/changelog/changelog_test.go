@@ -0,0 +1,70 @@
+package changelog
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	snapshot := Parse("# @generated-by swagger-to-http/1.4.0 spec=3f2a9c\n# > GET https://api.example.com/users\n# > Accept: application/json\nHTTP 200 OK\nContent-Type: application/json\n\n{\"id\": 1}")
+
+	assert.Equal(t, &Snapshot{Request: "GET https://api.example.com/users", StatusCode: 200, Body: "{\"id\": 1}"}, snapshot)
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name     string
+		old      *Snapshot
+		current  *Snapshot
+		expected *Change
+	}{
+		{
+			name:     "added",
+			current:  &Snapshot{Request: "GET /users", StatusCode: 200},
+			expected: &Change{Endpoint: "GET /users", Path: "users.json", Kind: KindAdded, NewStatus: 200},
+		},
+		{
+			name:     "removed",
+			old:      &Snapshot{StatusCode: 200},
+			expected: &Change{Endpoint: "users.json", Path: "users.json", Kind: KindRemoved, OldStatus: 200},
+		},
+		{
+			name:    "status and fields",
+			old:     &Snapshot{Request: "GET /users", StatusCode: 200, Body: `{"items": [{"id": 1, "phone": "1"}]}`},
+			current: &Snapshot{Request: "GET /users", StatusCode: 206, Body: `{"items": [{"id": 1, "email": "a@b.c"}], "next": null}`},
+			expected: &Change{
+				Endpoint: "GET /users", Path: "users.json", Kind: KindChanged, OldStatus: 200, NewStatus: 206,
+				AddedFields: []string{"items[].email", "next"}, RemovedFields: []string{"items[].phone"},
+			},
+		},
+		{
+			name:     "values",
+			old:      &Snapshot{StatusCode: 200, Body: `{"id": 1}`},
+			current:  &Snapshot{StatusCode: 200, Body: `{"id": 2}`},
+			expected: &Change{Endpoint: "users.json", Path: "users.json", Kind: KindChanged, ValuesChanged: true},
+		},
+		{
+			name:    "formatting only",
+			old:     &Snapshot{StatusCode: 200, Body: `{"id": 1}`},
+			current: &Snapshot{StatusCode: 200, Body: "{\n  \"id\": 1\n}"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Compare("users.json", tt.old, tt.current))
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	var out bytes.Buffer
+	Write(&out, "API changes since v1.0.0", []*Change{
+		{Endpoint: "GET /users", Kind: KindChanged, OldStatus: 200, NewStatus: 206, AddedFields: []string{"next"}},
+		{Endpoint: "GET /orders", Kind: KindAdded, NewStatus: 200},
+	})
+
+	assert.Equal(t, "## API changes since v1.0.0\n\n### Added\n\n- `GET /orders` (status 200)\n\n### Changed\n\n- `GET /users`\n  - status 200 → 206\n  - added fields: `next`\n", out.String())
+}
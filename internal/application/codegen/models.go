@@ -0,0 +1,388 @@
+// Package codegen generates Go code from the schemas of a Swagger/OpenAPI
+// document, so programs can decode responses into types that match the spec
+// the tests are generated from.
+package codegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"go/token"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// DefaultPackage is the package name of the generated code when none is given
+const DefaultPackage = "apitypes"
+
+// initialisms are the name parts written in upper case, as golint expects
+var initialisms = map[string]bool{
+	"API": true, "ASCII": true, "CPU": true, "CSS": true, "DNS": true, "EOF": true,
+	"HTML": true, "HTTP": true, "HTTPS": true, "ID": true, "IP": true, "JSON": true,
+	"SQL": true, "TCP": true, "TLS": true, "TTL": true, "UI": true, "URI": true,
+	"URL": true, "UTC": true, "UUID": true, "XML": true,
+}
+
+// ModelsOptions configures the generated models
+type ModelsOptions struct {
+	Package string // Package name, DefaultPackage when empty
+}
+
+// generator accumulates the declarations of a generated file
+type generator struct {
+	schemas map[string]*models.Schema
+	names   map[string]string // Schema name to type name
+	taken   map[string]bool   // Type names in use
+	decls   []string
+	pending []namedSchema
+	imports map[string]bool
+}
+
+// namedSchema is a schema waiting to be declared as a type
+type namedSchema struct {
+	typeName string
+	schema   *models.Schema
+	comment  string
+}
+
+// Schemas returns the named schemas of a document: its component schemas, or
+// the definitions of a Swagger 2 document
+func Schemas(doc *models.SwaggerDoc) (map[string]*models.Schema, error) {
+	schemas := make(map[string]*models.Schema)
+	if doc.Components != nil {
+		for name, schema := range doc.Components.Schemas {
+			schema := schema
+			schemas[name] = &schema
+		}
+	}
+	for name, definition := range doc.Definitions {
+		// Definitions are decoded loosely; round-trip them through JSON
+		data, err := json.Marshal(definition)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode definition %s: %w", name, err)
+		}
+		var schema models.Schema
+		if err := json.Unmarshal(data, &schema); err != nil {
+			return nil, fmt.Errorf("failed to decode definition %s: %w", name, err)
+		}
+		schemas[name] = &schema
+	}
+	return schemas, nil
+}
+
+// GenerateModels returns gofmt'ed Go source declaring a type per named schema
+// of the document, with json tags matching the property names. Inline object
+// properties become types named after their parent and property.
+func GenerateModels(doc *models.SwaggerDoc, options ModelsOptions) ([]byte, error) {
+	pkg := options.Package
+	if pkg == "" {
+		pkg = DefaultPackage
+	}
+	if !token.IsIdentifier(pkg) || token.IsKeyword(pkg) {
+		return nil, fmt.Errorf("invalid package name %q", pkg)
+	}
+
+	schemas, err := Schemas(doc)
+	if err != nil {
+		return nil, err
+	}
+	if len(schemas) == 0 {
+		return nil, fmt.Errorf("the document declares no schemas")
+	}
+
+	g := &generator{
+		schemas: schemas,
+		names:   make(map[string]string),
+		taken:   make(map[string]bool),
+		imports: make(map[string]bool),
+	}
+
+	// Name every schema first so references resolve regardless of order
+	names := make([]string, 0, len(schemas))
+	for name := range schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		g.names[name] = g.reserve(exportedName(name))
+	}
+	for _, name := range names {
+		comment := fmt.Sprintf("%s is the %s schema", g.names[name], name)
+		if description := strings.TrimSpace(schemas[name].Description); description != "" {
+			comment += "\n\n" + description
+		}
+		g.pending = append(g.pending, namedSchema{typeName: g.names[name], schema: schemas[name], comment: comment})
+	}
+
+	for len(g.pending) > 0 {
+		next := g.pending[0]
+		g.pending = g.pending[1:]
+		g.declare(next)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by swagger-to-http codegen models. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n", pkg)
+	if len(g.imports) > 0 {
+		imports := make([]string, 0, len(g.imports))
+		for path := range g.imports {
+			imports = append(imports, path)
+		}
+		sort.Strings(imports)
+		buf.WriteString("\nimport (\n")
+		for _, path := range imports {
+			fmt.Fprintf(&buf, "\t%q\n", path)
+		}
+		buf.WriteString(")\n")
+	}
+	for _, decl := range g.decls {
+		buf.WriteString("\n")
+		buf.WriteString(decl)
+	}
+
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %w", err)
+	}
+	return source, nil
+}
+
+// reserve returns a type name not in use yet, based on the name given
+func (g *generator) reserve(name string) string {
+	candidate := name
+	for i := 2; g.taken[candidate]; i++ {
+		candidate = fmt.Sprintf("%s%d", name, i)
+	}
+	g.taken[candidate] = true
+	return candidate
+}
+
+// declare writes the type declaration of a named schema
+func (g *generator) declare(named namedSchema) {
+	var buf strings.Builder
+	writeComment(&buf, "", named.comment)
+
+	schema := named.schema
+	if hasFields(schema) {
+		fmt.Fprintf(&buf, "type %s struct {\n", named.typeName)
+		g.writeFields(&buf, named.typeName, schema)
+		buf.WriteString("}\n")
+	} else {
+		fmt.Fprintf(&buf, "type %s %s\n", named.typeName, g.goType(named.typeName, schema, "an item of "+named.typeName))
+	}
+	g.decls = append(g.decls, buf.String())
+}
+
+// writeFields writes the struct fields of an object schema. The types an allOf
+// references are embedded; the properties of its inline members are merged in.
+func (g *generator) writeFields(buf *strings.Builder, parent string, schema *models.Schema) {
+	for _, member := range schema.AllOf {
+		if member == nil {
+			continue
+		}
+		if member.Ref != "" {
+			fmt.Fprintf(buf, "\t%s\n", g.refType(member.Ref))
+			continue
+		}
+		g.writeFields(buf, parent, member)
+	}
+
+	required := make(map[string]bool, len(schema.Required))
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+
+	properties := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		properties = append(properties, name)
+	}
+	sort.Strings(properties)
+
+	for _, name := range properties {
+		property := schema.Properties[name]
+		if property == nil {
+			property = &models.Schema{}
+		}
+		field := exportedName(name)
+
+		fieldType := g.goType(parent+field, property, fmt.Sprintf("the %s property of %s", name, parent))
+		tag := name
+		if !required[name] {
+			tag += ",omitempty"
+			// Optional objects are pointers so omitempty leaves them out
+			if g.isStruct(property) && !strings.HasPrefix(fieldType, "map[") {
+				fieldType = "*" + fieldType
+			}
+		}
+
+		if property.Description != "" {
+			writeComment(buf, "\t", property.Description)
+		}
+		fmt.Fprintf(buf, "\t%s %s `json:\"%s\"`\n", field, fieldType, tag)
+	}
+}
+
+// goType returns the Go type of a schema, queueing the declaration of inline
+// objects under the name given, described by what
+func (g *generator) goType(name string, schema *models.Schema, what string) string {
+	if schema.Ref != "" {
+		return g.refType(schema.Ref)
+	}
+	if len(schema.OneOf) > 0 || len(schema.AnyOf) > 0 {
+		return "interface{}"
+	}
+
+	switch {
+	case schema.Type == "array":
+		if schema.Items == nil {
+			return "[]interface{}"
+		}
+		return "[]" + g.itemsType(schema.Items)
+	case isObject(schema):
+		if !hasFields(schema) {
+			if schema.AdditionalProperties != nil {
+				return "map[string]" + g.goType(name+"Value", schema.AdditionalProperties, "a value of "+what)
+			}
+			return "map[string]interface{}"
+		}
+		typeName := g.reserve(name)
+		g.pending = append(g.pending, namedSchema{typeName: typeName, schema: schema, comment: typeName + " is " + what})
+		return typeName
+	}
+	return g.scalarType(schema.Type, schema.Format)
+}
+
+// itemsType returns the Go type of the items of an array
+func (g *generator) itemsType(items *models.Items) string {
+	switch {
+	case items.Ref != "":
+		return g.refType(items.Ref)
+	case items.Type == "array":
+		if items.Items == nil {
+			return "[]interface{}"
+		}
+		return "[]" + g.itemsType(items.Items)
+	case items.Type == "object":
+		return "map[string]interface{}"
+	}
+	return g.scalarType(items.Type, items.Format)
+}
+
+// scalarType returns the Go type of a schema type and format
+func (g *generator) scalarType(schemaType, schemaFormat string) string {
+	switch schemaType {
+	case "integer":
+		if schemaFormat == "int32" {
+			return "int32"
+		}
+		return "int64"
+	case "number":
+		if schemaFormat == "float" {
+			return "float32"
+		}
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "string":
+		if schemaFormat == "date-time" {
+			g.imports["time"] = true
+			return "time.Time"
+		}
+		return "string"
+	}
+	return "interface{}"
+}
+
+// refType returns the type name of a schema reference
+func (g *generator) refType(ref string) string {
+	name := ref[strings.LastIndex(ref, "/")+1:]
+	if typeName, ok := g.names[name]; ok {
+		return typeName
+	}
+	// References outside the document decode as anything
+	return "interface{}"
+}
+
+// isStruct reports whether a schema, or the schema it references, is declared
+// as a struct
+func (g *generator) isStruct(schema *models.Schema) bool {
+	if schema.Ref != "" {
+		target, ok := g.schemas[schema.Ref[strings.LastIndex(schema.Ref, "/")+1:]]
+		return ok && hasFields(target)
+	}
+	return hasFields(schema) && len(schema.OneOf)+len(schema.AnyOf) == 0
+}
+
+// hasFields reports whether a schema is an object with declared properties
+func hasFields(schema *models.Schema) bool {
+	return isObject(schema) && len(schema.Properties)+len(schema.AllOf) > 0
+}
+
+// isObject reports whether a schema describes an object
+func isObject(schema *models.Schema) bool {
+	return schema.Type == "object" || (schema.Type == "" && (len(schema.Properties) > 0 || len(schema.AllOf) > 0))
+}
+
+// exportedName converts a schema or property name to an exported Go identifier
+func exportedName(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var result strings.Builder
+	for _, word := range words {
+		for _, part := range splitCamel(word) {
+			if upper := strings.ToUpper(part); initialisms[upper] {
+				result.WriteString(upper)
+				continue
+			}
+			// Plurals of initialisms, such as "urls" and "ids"
+			if singular := strings.ToUpper(strings.TrimSuffix(part, "s")); len(part) > 2 && strings.HasSuffix(part, "s") && initialisms[singular] {
+				result.WriteString(singular + "s")
+				continue
+			}
+			runes := []rune(part)
+			runes[0] = unicode.ToUpper(runes[0])
+			result.WriteString(string(runes))
+		}
+	}
+
+	identifier := result.String()
+	if identifier == "" {
+		return "Field"
+	}
+	if unicode.IsDigit([]rune(identifier)[0]) {
+		return "X" + identifier
+	}
+	return identifier
+}
+
+// splitCamel splits a camelCase word into its parts
+func splitCamel(word string) []string {
+	var parts []string
+	runes := []rune(word)
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		if unicode.IsUpper(runes[i]) && !unicode.IsUpper(runes[i-1]) {
+			parts = append(parts, string(runes[start:i]))
+			start = i
+		}
+	}
+	return append(parts, string(runes[start:]))
+}
+
+// writeComment writes a possibly multi-line text as a Go comment
+func writeComment(buf *strings.Builder, indent, text string) {
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" {
+			fmt.Fprintf(buf, "%s//\n", indent)
+			continue
+		}
+		fmt.Fprintf(buf, "%s// %s\n", indent, line)
+	}
+}
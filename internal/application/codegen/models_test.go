@@ -0,0 +1,133 @@
+package codegen
+
+import (
+	"go/parser"
+	"go/token"
+	"regexp"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// collapseSpaces undoes the alignment of struct fields by gofmt
+func collapseSpaces(code string) string {
+	return regexp.MustCompile(` +`).ReplaceAllString(code, " ")
+}
+
+func testDoc() *models.SwaggerDoc {
+	return &models.SwaggerDoc{
+		Version: "3.0.3",
+		Components: &models.Components{
+			Schemas: map[string]models.Schema{
+				"User": {
+					Type:        "object",
+					Description: "A registered user.",
+					Required:    []string{"id", "email"},
+					Properties: map[string]*models.Schema{
+						"id":        {Type: "integer", Format: "int64"},
+						"email":     {Type: "string", Description: "Login address"},
+						"createdAt": {Type: "string", Format: "date-time"},
+						"status":    {Ref: "#/components/schemas/user_status"},
+						"tags":      {Type: "array", Items: &models.Items{Type: "string"}},
+						"address": {
+							Type:       "object",
+							Properties: map[string]*models.Schema{"city": {Type: "string"}},
+						},
+						"labels":  {Type: "object", AdditionalProperties: &models.Schema{Type: "string"}},
+						"manager": {Ref: "#/components/schemas/User"},
+					},
+				},
+				"user_status": {Type: "string", Enum: []interface{}{"active", "disabled"}},
+				"Admin": {
+					AllOf: []*models.Schema{
+						{Ref: "#/components/schemas/User"},
+						{Type: "object", Properties: map[string]*models.Schema{"level": {Type: "number", Format: "float"}}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestGenerateModels(t *testing.T) {
+	source, err := GenerateModels(testDoc(), ModelsOptions{Package: "apitypes"})
+	require.NoError(t, err)
+	code := collapseSpaces(string(source))
+
+	_, err = parser.ParseFile(token.NewFileSet(), "models.go", source, 0)
+	require.NoError(t, err, code)
+
+	assert.Contains(t, code, "package apitypes")
+	assert.Contains(t, code, `import (
+	"time"
+)`)
+	assert.Contains(t, code, "// User is the User schema\n//\n// A registered user.\ntype User struct {")
+	assert.Contains(t, code, "ID int64 `json:\"id\"`")
+	assert.Contains(t, code, "// Login address\n\tEmail string `json:\"email\"`")
+	assert.Contains(t, code, "CreatedAt time.Time `json:\"createdAt,omitempty\"`")
+	assert.Contains(t, code, "Status UserStatus `json:\"status,omitempty\"`")
+	assert.Contains(t, code, "Tags []string `json:\"tags,omitempty\"`")
+	assert.Contains(t, code, "Address *UserAddress `json:\"address,omitempty\"`")
+	assert.Contains(t, code, "Labels map[string]string `json:\"labels,omitempty\"`")
+	assert.Contains(t, code, "Manager *User `json:\"manager,omitempty\"`")
+	assert.Contains(t, code, "// UserAddress is the address property of User\ntype UserAddress struct {")
+	assert.Contains(t, code, "type UserStatus string")
+	assert.Contains(t, code, "type Admin struct {\n\tUser\n\tLevel float32 `json:\"level,omitempty\"`")
+}
+
+func TestGenerateModelsSwagger2(t *testing.T) {
+	doc := &models.SwaggerDoc{
+		SwaggerVersion: "2.0",
+		Definitions: map[string]interface{}{
+			"Pet": map[string]interface{}{
+				"type":     "object",
+				"required": []interface{}{"name"},
+				"properties": map[string]interface{}{
+					"name":      map[string]interface{}{"type": "string"},
+					"photoUrls": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+				},
+			},
+		},
+	}
+
+	source, err := GenerateModels(doc, ModelsOptions{})
+	require.NoError(t, err)
+	code := collapseSpaces(string(source))
+
+	assert.Contains(t, code, "package "+DefaultPackage)
+	assert.NotContains(t, code, "import")
+	assert.Contains(t, code, "Name string `json:\"name\"`")
+	assert.Contains(t, code, "PhotoURLs []string `json:\"photoUrls,omitempty\"`")
+}
+
+func TestGenerateModelsErrors(t *testing.T) {
+	_, err := GenerateModels(testDoc(), ModelsOptions{Package: "api-types"})
+	assert.Error(t, err)
+
+	_, err = GenerateModels(&models.SwaggerDoc{}, ModelsOptions{})
+	assert.Error(t, err)
+}
+
+func TestExportedName(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"id", "ID"},
+		{"user_id", "UserID"},
+		{"avatarUrl", "AvatarURL"},
+		{"photoUrls", "PhotoURLs"},
+		{"created-at", "CreatedAt"},
+		{"HTTPStatus", "HTTPStatus"},
+		{"2fa", "X2fa"},
+		{"$", "Field"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, exportedName(tt.name))
+		})
+	}
+}
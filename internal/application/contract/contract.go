@@ -0,0 +1,160 @@
+// Package contract checks stored snapshot responses against a Swagger/OpenAPI
+// document without sending any request, telling which recorded behaviors an
+// updated spec no longer allows.
+package contract
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/application/explain"
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// Outcomes of the check of a snapshot
+const (
+	OutcomePassed  = "passed"
+	OutcomeFailed  = "failed"
+	OutcomeSkipped = "skipped"
+)
+
+// ValidateFunc validates a response against the schema the spec declares for
+// the operation at path and method
+type ValidateFunc func(response *models.HTTPResponse, path, method string) (*models.SchemaValidationResult, error)
+
+// Snapshot is a stored response, with the request recorded along with it
+type Snapshot struct {
+	Path     string
+	Response *models.HTTPResponse
+}
+
+// Result is the check of a snapshot against the spec
+type Result struct {
+	Snapshot string                   `json:"snapshot"`
+	Method   string                   `json:"method,omitempty"`
+	Path     string                   `json:"path,omitempty"`
+	Status   int                      `json:"status"`
+	Outcome  string                   `json:"outcome"`
+	Reason   string                   `json:"reason,omitempty"`
+	Errors   []models.ValidationError `json:"errors,omitempty"`
+	Warnings []models.ValidationError `json:"warnings,omitempty"`
+}
+
+// Report holds the checks of the snapshots
+type Report struct {
+	Results []Result `json:"results"`
+	Passed  int      `json:"passed"`
+	Failed  int      `json:"failed"`
+	Skipped int      `json:"skipped"`
+}
+
+// Checker checks snapshots against the operations of a spec
+type Checker struct {
+	explainer *explain.Explainer
+	validate  ValidateFunc
+}
+
+// NewChecker creates a Checker mapping snapshots to the operations of doc and
+// validating their bodies with validate
+func NewChecker(doc *models.SwaggerDoc, validate ValidateFunc) *Checker {
+	return &Checker{
+		explainer: explain.NewExplainer(doc),
+		validate:  validate,
+	}
+}
+
+// Check checks every snapshot. A snapshot fails when no operation matches
+// its request any more, when its status isn't declared for the operation or
+// when its body doesn't match the schema of the response. Snapshots that
+// don't record their request or whose body isn't JSON are skipped.
+func (c *Checker) Check(snapshots []Snapshot) *Report {
+	report := &Report{Results: []Result{}}
+	for _, snapshot := range snapshots {
+		result := c.check(snapshot)
+		switch result.Outcome {
+		case OutcomePassed:
+			report.Passed++
+		case OutcomeFailed:
+			report.Failed++
+		default:
+			report.Skipped++
+		}
+		report.Results = append(report.Results, result)
+	}
+	return report
+}
+
+// check checks a single snapshot
+func (c *Checker) check(snapshot Snapshot) Result {
+	result := Result{Snapshot: snapshot.Path}
+	response := snapshot.Response
+	if response == nil || response.ResolvedRequest == nil {
+		return result.with(OutcomeSkipped, "the snapshot doesn't record its request, update it to check it")
+	}
+	result.Status = response.StatusCode
+
+	request := response.ResolvedRequest
+	explanation := c.explainer.ExplainRequest(models.HTTPRequest{Method: request.Method, URL: request.URL}, nil, nil)
+	if explanation.Operation == nil {
+		return result.with(OutcomeFailed, fmt.Sprintf("no operation in the spec matches %s %s", explanation.Method, request.URL))
+	}
+	operation := explanation.Operation
+	result.Method, result.Path = operation.Method, operation.Path
+
+	if !declaresStatus(operation, response.StatusCode) {
+		return result.with(OutcomeFailed, fmt.Sprintf("status %d is not declared for %s %s", response.StatusCode, operation.Method, operation.Path))
+	}
+
+	if !isJSON(response) {
+		return result.with(OutcomeSkipped, "response is not JSON")
+	}
+	validation, err := c.validate(response, operation.Path, operation.Method)
+	if err != nil {
+		return result.with(OutcomeSkipped, fmt.Sprintf("failed to validate response: %v", err))
+	}
+	result.Errors, result.Warnings = validation.Errors, validation.Warnings
+	if !validation.Valid {
+		return result.with(OutcomeFailed, "response doesn't match the schema")
+	}
+	return result.with(OutcomePassed, "")
+}
+
+// with returns the result with an outcome and the reason for it
+func (r Result) with(outcome, reason string) Result {
+	r.Outcome, r.Reason = outcome, reason
+	return r
+}
+
+// declaresStatus reports whether an operation declares a response for a
+// status, exactly, by its class such as 2XX or by default. Operations
+// declaring no responses accept any status.
+func declaresStatus(operation *explain.OperationMatch, status int) bool {
+	if len(operation.Responses) == 0 {
+		return true
+	}
+	code := strconv.Itoa(status)
+	for _, response := range operation.Responses {
+		declared := strings.ToUpper(response.Status)
+		if declared == code || declared == "DEFAULT" || (len(code) == 3 && declared == code[:1]+"XX") {
+			return true
+		}
+	}
+	return false
+}
+
+// isJSON reports whether a response has a JSON body
+func isJSON(response *models.HTTPResponse) bool {
+	contentType := response.ContentType
+	for name, values := range response.Headers {
+		if contentType == "" && strings.EqualFold(name, "Content-Type") && len(values) > 0 {
+			contentType = values[0]
+		}
+	}
+	mediaType := strings.TrimSpace(strings.ToLower(strings.SplitN(contentType, ";", 2)[0]))
+	if mediaType != "" && mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") {
+		return false
+	}
+	return json.Valid([]byte(response.Body))
+}
@@ -0,0 +1,62 @@
+package contract
+
+import (
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	doc := &models.SwaggerDoc{
+		Paths: map[string]models.PathItem{
+			"/users/{id}": {Get: &models.Operation{
+				OperationID: "getUser",
+				Responses: map[string]models.Response{
+					"200": {Description: "OK"},
+					"4XX": {Description: "Client error"},
+				},
+			}},
+		},
+	}
+	validate := func(response *models.HTTPResponse, path, method string) (*models.SchemaValidationResult, error) {
+		if response.Body == `{}` {
+			return &models.SchemaValidationResult{Errors: []models.ValidationError{{Path: "id", Message: "required property missing"}}}, nil
+		}
+		return &models.SchemaValidationResult{Valid: true}, nil
+	}
+	snapshot := func(path, method, url string, status int, body string) Snapshot {
+		return Snapshot{Path: path, Response: &models.HTTPResponse{
+			StatusCode:      status,
+			Headers:         map[string][]string{"Content-Type": {"application/json"}},
+			Body:            body,
+			ResolvedRequest: &models.ResolvedRequest{Method: method, URL: url},
+		}}
+	}
+
+	report := NewChecker(doc, validate).Check([]Snapshot{
+		snapshot("ok.snap", "GET", "https://api.example.com/users/1", 200, `{"id": 1}`),
+		snapshot("not-found.snap", "GET", "https://api.example.com/users/2", 404, `{"error": "not found"}`),
+		snapshot("invalid.snap", "GET", "https://api.example.com/users/3", 200, `{}`),
+		snapshot("server-error.snap", "GET", "https://api.example.com/users/4", 500, `{}`),
+		snapshot("removed.snap", "DELETE", "https://api.example.com/users/5", 204, ``),
+		{Path: "old.snap", Response: &models.HTTPResponse{StatusCode: 200, Body: `{}`}},
+	})
+
+	var outcomes []string
+	for _, result := range report.Results {
+		outcomes = append(outcomes, result.Snapshot+": "+result.Outcome+" "+result.Reason)
+	}
+	assert.Equal(t, []string{
+		"ok.snap: passed ",
+		"not-found.snap: passed ",
+		"invalid.snap: failed response doesn't match the schema",
+		"server-error.snap: failed status 500 is not declared for GET /users/{id}",
+		"removed.snap: failed no operation in the spec matches DELETE https://api.example.com/users/5",
+		"old.snap: skipped the snapshot doesn't record its request, update it to check it",
+	}, outcomes)
+	assert.Equal(t, []models.ValidationError{{Path: "id", Message: "required property missing"}}, report.Results[2].Errors)
+	assert.Equal(t, 2, report.Passed)
+	assert.Equal(t, 3, report.Failed)
+	assert.Equal(t, 1, report.Skipped)
+}
@@ -0,0 +1,132 @@
+package application
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// DependencyGraph orders requests declared with "# @depends-on" so that every
+// request runs after the requests it depends on
+type DependencyGraph struct {
+	requests []*models.HTTPRequest
+	byName   map[string]int
+	deps     [][]int
+}
+
+// NewDependencyGraph builds the dependency graph for a set of requests.
+// It fails when a dependency is unknown or the dependencies form a cycle.
+func NewDependencyGraph(requests []*models.HTTPRequest) (*DependencyGraph, error) {
+	graph := &DependencyGraph{
+		requests: requests,
+		byName:   make(map[string]int),
+		deps:     make([][]int, len(requests)),
+	}
+
+	// Index requests by name
+	for i, request := range requests {
+		if request.Name == "" {
+			continue
+		}
+		if _, exists := graph.byName[request.Name]; exists {
+			return nil, fmt.Errorf("duplicate request name %q", request.Name)
+		}
+		graph.byName[request.Name] = i
+	}
+
+	// Resolve dependency names
+	for i, request := range requests {
+		for _, dep := range request.DependsOn {
+			j, ok := graph.byName[dep]
+			if !ok {
+				return nil, fmt.Errorf("request %q depends on unknown request %q", request.Name, dep)
+			}
+			graph.deps[i] = append(graph.deps[i], j)
+		}
+	}
+
+	// Make sure the graph can be ordered
+	if _, err := graph.Levels(); err != nil {
+		return nil, err
+	}
+
+	return graph, nil
+}
+
+// HasDependencies reports whether any of the files declares request dependencies
+func HasDependencies(files []*models.HTTPFile) bool {
+	for _, file := range files {
+		for _, request := range file.Requests {
+			if len(request.DependsOn) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Levels groups the request indexes into topological levels. Requests in the
+// same level don't depend on each other and can run in parallel.
+func (g *DependencyGraph) Levels() ([][]int, error) {
+	remaining := make([]int, len(g.requests))
+	dependents := make([][]int, len(g.requests))
+	for i, deps := range g.deps {
+		remaining[i] = len(deps)
+		for _, j := range deps {
+			dependents[j] = append(dependents[j], i)
+		}
+	}
+
+	// Start with requests without dependencies
+	var current []int
+	for i := range g.requests {
+		if remaining[i] == 0 {
+			current = append(current, i)
+		}
+	}
+
+	var levels [][]int
+	visited := 0
+	for len(current) > 0 {
+		levels = append(levels, current)
+		visited += len(current)
+
+		var next []int
+		for _, i := range current {
+			for _, dependent := range dependents[i] {
+				remaining[dependent]--
+				if remaining[dependent] == 0 {
+					next = append(next, dependent)
+				}
+			}
+		}
+
+		// Keep the file order within a level
+		sort.Ints(next)
+		current = next
+	}
+
+	if visited < len(g.requests) {
+		var cycle []string
+		for i, count := range remaining {
+			if count > 0 {
+				cycle = append(cycle, g.requests[i].Name)
+			}
+		}
+		return nil, fmt.Errorf("dependency cycle between requests: %s", strings.Join(cycle, ", "))
+	}
+
+	return levels, nil
+}
+
+// Dependencies returns the indexes of the requests the given request depends on
+func (g *DependencyGraph) Dependencies(index int) []int {
+	return g.deps[index]
+}
+
+// Request returns the request at the given index
+func (g *DependencyGraph) Request(index int) *models.HTTPRequest {
+	return g.requests[index]
+}
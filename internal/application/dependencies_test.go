@@ -0,0 +1,163 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDependencyGraph(t *testing.T) {
+	tests := []struct {
+		name     string
+		requests []*models.HTTPRequest
+		levels   [][]string
+		err      string
+	}{
+		{
+			name: "ordering",
+			requests: []*models.HTTPRequest{
+				{Name: "getUser", DependsOn: []string{"createUser", "login"}},
+				{Name: "createUser", DependsOn: []string{"login"}},
+				{Name: "health"},
+				{Name: "login"},
+				{Name: "deleteUser", DependsOn: []string{"getUser"}},
+			},
+			levels: [][]string{{"health", "login"}, {"createUser"}, {"getUser"}, {"deleteUser"}},
+		},
+		{
+			name: "unnamed requests",
+			requests: []*models.HTTPRequest{
+				{},
+				{Name: "login"},
+				{},
+			},
+			levels: [][]string{{"", "login", ""}},
+		},
+		{
+			name: "cycle",
+			requests: []*models.HTTPRequest{
+				{Name: "login"},
+				{Name: "a", DependsOn: []string{"c"}},
+				{Name: "b", DependsOn: []string{"a", "login"}},
+				{Name: "c", DependsOn: []string{"b"}},
+			},
+			err: "dependency cycle between requests: a, b, c",
+		},
+		{
+			name:     "self dependency",
+			requests: []*models.HTTPRequest{{Name: "login", DependsOn: []string{"login"}}},
+			err:      "dependency cycle between requests: login",
+		},
+		{
+			name:     "duplicate names",
+			requests: []*models.HTTPRequest{{Name: "login"}, {Name: "login"}},
+			err:      `duplicate request name "login"`,
+		},
+		{
+			name:     "unknown dependency",
+			requests: []*models.HTTPRequest{{Name: "getUser", DependsOn: []string{"createUser"}}},
+			err:      `request "getUser" depends on unknown request "createUser"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			graph, err := NewDependencyGraph(tt.requests)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+
+			levels, err := graph.Levels()
+			require.NoError(t, err)
+			var names [][]string
+			for _, level := range levels {
+				var levelNames []string
+				for _, i := range level {
+					levelNames = append(levelNames, graph.Request(i).Name)
+				}
+				names = append(names, levelNames)
+			}
+			assert.Equal(t, tt.levels, names)
+		})
+	}
+}
+
+func TestRunTestsWithDependencies_PerFile(t *testing.T) {
+	// Each file logs in with its own user and reads its own token
+	file := func(filename, user string) *models.HTTPFile {
+		return &models.HTTPFile{
+			Filename: filename,
+			Requests: []models.HTTPRequest{
+				{Name: "me", Method: "GET", URL: "https://api.example.com/me", DependsOn: []string{"login"},
+					Headers: map[string]string{"Authorization": "Bearer {{login.response.body.$.token}}"}},
+				{Name: "login", Method: "POST", URL: "https://api.example.com/login", Body: user},
+			},
+		}
+	}
+	executor := &recordingExecutor{respond: func(request *models.HTTPRequest, variables map[string]string) (*models.HTTPResponse, error) {
+		if request.Name == "login" {
+			return &models.HTTPResponse{StatusCode: 200, Request: request, Body: fmt.Sprintf(`{"token": "%s-token"}`, request.Body)}, nil
+		}
+		return &models.HTTPResponse{StatusCode: 200, Request: request}, nil
+	}}
+	runner := NewTestRunnerService(executor, noSnapshots{}, nil)
+
+	options := models.TestRunOptions{Guard: models.MutationGuard{AllowMutations: true}}
+	results, err := runner.runFiles(context.Background(), []*models.HTTPFile{file("admin.http", "admin"), file("guest.http", "guest")}, options)
+	require.NoError(t, err)
+
+	// Files run one after the other, each in the order of its dependencies
+	assert.Equal(t, []string{"login", "me", "login", "me"}, executor.sent())
+	assert.Equal(t, "Bearer admin-token", executor.requests[1].Headers["Authorization"])
+	assert.Equal(t, "Bearer guest-token", executor.requests[3].Headers["Authorization"])
+
+	// Results are in file order
+	require.Len(t, results, 4)
+	for i, expected := range []string{"admin.http", "admin.http", "guest.http", "guest.http"} {
+		assert.Equal(t, expected, results[i].FilePath)
+		assert.Equal(t, models.TestStatusPassed, results[i].Status)
+	}
+}
+
+func TestRunTestsWithDependencies_ResolvesWithinFile(t *testing.T) {
+	files := []*models.HTTPFile{
+		{
+			Filename: "auth.http",
+			Requests: []models.HTTPRequest{{Name: "login", Method: "POST", URL: "https://api.example.com/login"}},
+		},
+		{
+			Filename: "users.http",
+			Requests: []models.HTTPRequest{
+				{Name: "health", Method: "GET", URL: "https://api.example.com/health"},
+				{Name: "me", Method: "GET", URL: "https://api.example.com/me", DependsOn: []string{"health"},
+					Headers: map[string]string{"Authorization": "Bearer {{login.response.body.$.token}}"}},
+			},
+		},
+	}
+	executor := &recordingExecutor{}
+	runner := NewTestRunnerService(executor, noSnapshots{}, nil)
+
+	// References to the responses of other files don't resolve
+	options := models.TestRunOptions{Guard: models.MutationGuard{AllowMutations: true}}
+	results, err := runner.runFiles(context.Background(), files, options)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.Equal(t, models.TestStatusError, results[2].Status)
+	assert.Contains(t, results[2].Error, `no response recorded for request "login"`)
+
+	// Nor do dependencies
+	files[1].Requests[1].DependsOn = []string{"login"}
+	_, err = runner.runFiles(context.Background(), files, options)
+	assert.EqualError(t, err, `invalid request dependencies in users.http: request "me" depends on unknown request "login"`)
+
+	// Names must be unique within a file
+	files[1].Requests[0].Name = "me"
+	_, err = runner.runFiles(context.Background(), files, options)
+	assert.EqualError(t, err, `invalid request dependencies in users.http: duplicate request name "me"`)
+}
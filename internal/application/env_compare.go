@@ -0,0 +1,303 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/application/snapshot"
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// BaseURLVariable is set to the environment's base URL so HTTP files using
+// "{{baseUrl}}" follow the environment being compared
+const BaseURLVariable = "baseUrl"
+
+// CompareEnvironments runs the requests matching the patterns against two environments
+// and reports, per request, whether the normalized responses differ. Snapshots are
+// neither read nor written.
+func (s *TestRunnerService) CompareEnvironments(ctx context.Context, patterns []string, envA, envB models.Environment, options models.TestRunOptions) (*models.TestReport, error) {
+	files, err := s.FindTests(ctx, patterns, options.Filter)
+	if err != nil {
+		return nil, fmt.Errorf("error finding tests: %w", err)
+	}
+
+	report := &models.TestReport{
+		Name:        fmt.Sprintf("Environment Comparison: %s vs %s", envA.Name, envB.Name),
+		Summary:     models.TestSummary{},
+		Results:     []models.TestResult{},
+		CreatedAt:   time.Now(),
+		Environment: map[string]string{envA.Name: envA.BaseURL, envB.Name: envB.BaseURL},
+	}
+	report.Summary.StartTime = time.Now()
+
+	// Fail requests using variables without a value in either environment
+	// when they are strict
+	ctx = models.ContextWithStrictVariables(ctx, options.StrictVars)
+
+files:
+	for _, file := range files {
+		// Each environment chains its own responses
+		chainA, chainB := NewResponseChain(), NewResponseChain()
+
+		for _, request := range file.Requests {
+			if !s.matchesFilter(&request, options.Filter) {
+				continue
+			}
+			request.Path = file.Filename
+
+			result := s.compareRequest(ctx, &request, envA, envB, chainA, chainB, options)
+			report.Results = append(report.Results, *result)
+
+			if options.StopOnFailure && (result.Status == models.TestStatusFailed || result.Status == models.TestStatusError) {
+				break files
+			}
+		}
+	}
+
+	report.Summary.EndTime = time.Now()
+	report.Summary.DurationMs = report.Summary.EndTime.Sub(report.Summary.StartTime).Milliseconds()
+	s.calculateSummary(&report.Summary, report.Results)
+
+	return report, nil
+}
+
+// compareRequest executes a request against both environments at the same time and
+// compares the normalized responses
+func (s *TestRunnerService) compareRequest(ctx context.Context, request *models.HTTPRequest, envA, envB models.Environment, chainA, chainB *ResponseChain, options models.TestRunOptions) *models.TestResult {
+	startTime := time.Now()
+
+	result := &models.TestResult{
+		Name:     request.Name,
+		Request:  request,
+		FilePath: request.Path,
+		Tags:     request.TagList(),
+		Status:   models.TestStatusError,
+		MetaData: make(map[string]string),
+	}
+
+	var wg sync.WaitGroup
+	var responseA, responseB *models.HTTPResponse
+	var errA, errB error
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		responseA, errA = s.executeInEnvironment(ctx, request, envA, chainA, options)
+	}()
+	go func() {
+		defer wg.Done()
+		responseB, errB = s.executeInEnvironment(ctx, request, envB, chainB, options)
+	}()
+	wg.Wait()
+
+	result.Duration = time.Since(startTime)
+	if errA != nil {
+		result.Error = fmt.Sprintf("%s: %v", envA.Name, errA)
+		return result
+	}
+	if errB != nil {
+		result.Error = fmt.Sprintf("%s: %v", envB.Name, errB)
+		return result
+	}
+
+	result.Response = responseA
+	result.MetaData[envA.Name+".status"] = strconv.Itoa(responseA.StatusCode)
+	result.MetaData[envB.Name+".status"] = strconv.Itoa(responseB.StatusCode)
+
+	// Apply the same normalization rules as snapshot comparison
+	normalizedA, err := normalizeForComparison(responseA, request, options)
+	if err == nil {
+		var normalizedB *models.HTTPResponse
+		normalizedB, err = normalizeForComparison(responseB, request, options)
+		if err == nil {
+			err = compareResponses(result, normalizedA, normalizedB, request, envA, envB, options)
+		}
+	}
+	if err != nil {
+		result.Status = models.TestStatusError
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+// executeInEnvironment resolves chained references, points the request at the
+// environment and executes it
+func (s *TestRunnerService) executeInEnvironment(ctx context.Context, request *models.HTTPRequest, env models.Environment, chain *ResponseChain, options models.TestRunOptions) (*models.HTTPResponse, error) {
+	resolved, _, err := chain.Resolve(request)
+	if err != nil {
+		return nil, err
+	}
+
+	rebased := *resolved
+	rebased.URL = rebaseURL(resolved.URL, env.BaseURL)
+
+	variables, err := s.requestVariables(ctx, &rebased, options)
+	if err != nil {
+		return nil, err
+	}
+	withBase := make(map[string]string, len(variables)+1)
+	for k, v := range variables {
+		withBase[k] = v
+	}
+	withBase[BaseURLVariable] = strings.TrimSuffix(env.BaseURL, "/")
+
+	// Don't change data on shared environments unless allowed
+	if err := CheckMutation(&rebased, withBase, options.Guard); err != nil {
+		return nil, err
+	}
+
+	response, err := s.httpExecutor.Execute(ctx, &rebased, withBase)
+	if err != nil {
+		return nil, err
+	}
+	chain.Add(request.Name, response)
+
+	return response, nil
+}
+
+// normalizeForComparison applies the request's snapshot transform and drops the
+// ignored headers from a copy of the response
+func normalizeForComparison(response *models.HTTPResponse, request *models.HTTPRequest, options models.TestRunOptions) (*models.HTTPResponse, error) {
+	normalized := *response
+	if request.SnapshotTransform != "" {
+		transformed, err := transformResponse(response, request.SnapshotTransform)
+		if err != nil {
+			return nil, err
+		}
+		normalized = *transformed
+	}
+
+	ignored, err := ignoredHeaders(request, options)
+	if err != nil {
+		return nil, err
+	}
+	normalized.Headers = make(map[string][]string, len(response.Headers))
+	for name, values := range response.Headers {
+		if !ignored.Matches(name) {
+			normalized.Headers[name] = values
+		}
+	}
+
+	return &normalized, nil
+}
+
+// compareResponses compares the normalized responses of both environments and
+// records the outcome in the result
+func compareResponses(result *models.TestResult, responseA, responseB *models.HTTPResponse, request *models.HTTPRequest, envA, envB models.Environment, options models.TestRunOptions) error {
+	compareOptions := snapshot.CompareOptions{
+		IgnoreArrayOrder: options.IgnoreArrayOrder || request.IgnoreArrayOrder,
+		ArrayOrderKey:    options.ArrayOrderKey,
+		Tolerances:       request.Tolerances,
+	}
+	if request.ArrayOrderKey != "" {
+		compareOptions.ArrayOrderKey = request.ArrayOrderKey
+	}
+
+	ignored, err := ignoredHeaders(request, options)
+	if err != nil {
+		return err
+	}
+	compareOptions.IgnoreHeaders = ignored
+
+	formatter, err := snapshot.GetFormatterWithOptions(responseA.ContentType, compareOptions)
+	if err != nil {
+		return err
+	}
+	comparison, err := formatter.Compare(responseA, responseB)
+	if err != nil {
+		return fmt.Errorf("comparison error: %w", err)
+	}
+
+	result.SnapshotResult = &models.SnapshotResult{
+		RequestPath:   request.Path,
+		RequestMethod: request.Method,
+		Passed:        comparison.Matches,
+		Diff: &models.SnapshotDiff{
+			HasDiff:    !comparison.Matches,
+			DiffString: comparison.Diff,
+			StatusDiff: !comparison.StatusMatch,
+		},
+	}
+
+	if comparison.Matches {
+		result.Status = models.TestStatusPassed
+		return nil
+	}
+
+	result.Status = models.TestStatusFailed
+	var parts []string
+	if !comparison.StatusMatch {
+		parts = append(parts, fmt.Sprintf("status %d vs %d", responseA.StatusCode, responseB.StatusCode))
+	}
+	if !comparison.HeadersMatch {
+		parts = append(parts, "headers")
+	}
+	if !comparison.BodyMatch {
+		parts = append(parts, "body")
+	}
+	result.Error = fmt.Sprintf("responses differ between %s and %s: %s", envA.Name, envB.Name, strings.Join(parts, ", "))
+
+	return nil
+}
+
+// rebaseURL points a request URL at another base URL. Relative URLs are appended to
+// the base; absolute URLs keep their path and query but take the base's scheme and
+// host, and its path prefix when they don't already start with it. URLs built from
+// variables are left alone and follow the base URL variable instead. The URL is
+// rewritten as a string so unresolved "{{variables}}" aren't escaped.
+func rebaseURL(rawURL, baseURL string) string {
+	if baseURL == "" || strings.HasPrefix(rawURL, "{{") {
+		return rawURL
+	}
+
+	base, err := url.Parse(baseURL)
+	if err != nil || base.Host == "" {
+		return rawURL
+	}
+	origin := base.Scheme + "://" + base.Host
+	basePath := strings.TrimSuffix(base.Path, "/")
+
+	// Split off the scheme and host of absolute URLs
+	rest := rawURL
+	if i := strings.Index(rawURL, "://"); i >= 0 {
+		rest = rawURL[i+3:]
+		if j := strings.IndexAny(rest, "/?#"); j >= 0 {
+			rest = rest[j:]
+		} else {
+			rest = ""
+		}
+	}
+	if rest != "" && !strings.HasPrefix(rest, "/") && !strings.HasPrefix(rest, "?") && !strings.HasPrefix(rest, "#") {
+		rest = "/" + rest
+	}
+
+	path := rest
+	if i := strings.IndexAny(rest, "?#"); i >= 0 {
+		path = rest[:i]
+	}
+	if basePath != "" && path != basePath && !strings.HasPrefix(path, basePath+"/") {
+		rest = basePath + rest
+	}
+
+	return origin + rest
+}
+
+// ignoredHeaders matches the headers left out of the comparison of a request:
+// the configured ones for its tag, then those of the run and its own
+func ignoredHeaders(request *models.HTTPRequest, options models.TestRunOptions) (*models.HeaderMatcher, error) {
+	rules := models.DefaultHeaderIgnoreRules()
+	if options.HeaderRules != nil {
+		rules = *options.HeaderRules
+	}
+	patterns := rules.Resolve(request.TagList(), models.MergeHeaderPatterns(options.IgnoreHeaders, request.IgnoreHeaders))
+	matcher, err := models.NewHeaderMatcher(patterns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile ignored headers: %w", err)
+	}
+	return matcher, nil
+}
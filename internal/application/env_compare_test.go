@@ -0,0 +1,143 @@
+package application
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareRequest(t *testing.T) {
+	staging := models.Environment{Name: "staging", BaseURL: "https://staging.example.com"}
+	production := models.Environment{Name: "production", BaseURL: "https://api.example.com/v1"}
+
+	jsonResponse := func(status int, headers map[string][]string, body string) *models.HTTPResponse {
+		return &models.HTTPResponse{StatusCode: status, Headers: headers, Body: body, ContentType: "application/json"}
+	}
+
+	tests := []struct {
+		name       string
+		url        string
+		strict     bool
+		responses  map[string]*models.HTTPResponse // By host
+		wantStatus models.TestStatus
+		wantError  string
+		wantURLs   []string
+	}{
+		{
+			name: "same responses",
+			url:  "/users",
+			responses: map[string]*models.HTTPResponse{
+				"staging.example.com": jsonResponse(200, nil, `{"id": 1, "name": "Ada"}`),
+				"api.example.com":     jsonResponse(200, nil, `{"name": "Ada", "id": 1}`),
+			},
+			wantStatus: models.TestStatusPassed,
+			wantURLs:   []string{"https://api.example.com/v1/users", "https://staging.example.com/users"},
+		},
+		{
+			name: "ignored headers differ",
+			url:  "https://localhost:8080/users",
+			responses: map[string]*models.HTTPResponse{
+				"staging.example.com": jsonResponse(200, map[string][]string{"Date": {"Mon"}, "Server": {"nginx"}}, `{}`),
+				"api.example.com":     jsonResponse(200, map[string][]string{"Date": {"Tue"}}, `{}`),
+			},
+			wantStatus: models.TestStatusPassed,
+			wantURLs:   []string{"https://api.example.com/v1/users", "https://staging.example.com/users"},
+		},
+		{
+			name: "status differs",
+			url:  "/users",
+			responses: map[string]*models.HTTPResponse{
+				"staging.example.com": jsonResponse(200, nil, `{}`),
+				"api.example.com":     jsonResponse(404, nil, `{}`),
+			},
+			wantStatus: models.TestStatusFailed,
+			wantError:  "responses differ between staging and production: status 200 vs 404",
+			wantURLs:   []string{"https://api.example.com/v1/users", "https://staging.example.com/users"},
+		},
+		{
+			name: "body differs",
+			url:  "/users",
+			responses: map[string]*models.HTTPResponse{
+				"staging.example.com": jsonResponse(200, nil, `{"id": 1}`),
+				"api.example.com":     jsonResponse(200, nil, `{"id": 2}`),
+			},
+			wantStatus: models.TestStatusFailed,
+			wantError:  "responses differ between staging and production: body",
+			wantURLs:   []string{"https://api.example.com/v1/users", "https://staging.example.com/users"},
+		},
+		{
+			name: "headers and body differ",
+			url:  "/users",
+			responses: map[string]*models.HTTPResponse{
+				"staging.example.com": jsonResponse(200, map[string][]string{"X-Version": {"2"}}, `{"id": 1}`),
+				"api.example.com":     jsonResponse(200, map[string][]string{"X-Version": {"1"}}, `{"id": 2}`),
+			},
+			wantStatus: models.TestStatusFailed,
+			wantError:  "responses differ between staging and production: headers, body",
+			wantURLs:   []string{"https://api.example.com/v1/users", "https://staging.example.com/users"},
+		},
+		{
+			name:       "strict with a missing variable",
+			url:        "/users/{{id}}",
+			strict:     true,
+			wantStatus: models.TestStatusError,
+			wantError:  "staging: unresolved variables: id",
+			wantURLs:   []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := &recordingExecutor{respond: func(request *models.HTTPRequest, variables map[string]string) (*models.HTTPResponse, error) {
+				u, err := url.Parse(request.URL)
+				if err != nil {
+					return nil, err
+				}
+				return tt.responses[u.Host], nil
+			}}
+			runner := NewTestRunnerService(executor, noSnapshots{}, nil)
+			request := &models.HTTPRequest{Name: "listUsers", Method: "GET", URL: tt.url}
+
+			ctx := models.ContextWithStrictVariables(context.Background(), tt.strict)
+			result := runner.compareRequest(ctx, request, staging, production, NewResponseChain(), NewResponseChain(), models.TestRunOptions{})
+
+			assert.Equal(t, tt.wantStatus, result.Status)
+			assert.Equal(t, tt.wantError, result.Error)
+
+			urls := []string{}
+			for _, sent := range executor.requests {
+				urls = append(urls, sent.URL)
+			}
+			assert.ElementsMatch(t, tt.wantURLs, urls)
+		})
+	}
+}
+
+func TestRebaseURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		rawURL  string
+		baseURL string
+		want    string
+	}{
+		{name: "relative", rawURL: "/users?page=2", baseURL: "https://api.example.com", want: "https://api.example.com/users?page=2"},
+		{name: "relative without a slash", rawURL: "users", baseURL: "https://api.example.com/", want: "https://api.example.com/users"},
+		{name: "absolute", rawURL: "http://localhost:8080/users#top", baseURL: "https://api.example.com", want: "https://api.example.com/users#top"},
+		{name: "base path prefix", rawURL: "http://localhost/users", baseURL: "https://api.example.com/v1/", want: "https://api.example.com/v1/users"},
+		{name: "base path already there", rawURL: "http://localhost/v1/users", baseURL: "https://api.example.com/v1", want: "https://api.example.com/v1/users"},
+		{name: "host only", rawURL: "http://localhost", baseURL: "https://api.example.com", want: "https://api.example.com"},
+		{name: "variables kept", rawURL: "/users/{{id}}", baseURL: "https://api.example.com", want: "https://api.example.com/users/{{id}}"},
+		{name: "base URL variable", rawURL: "{{baseUrl}}/users", baseURL: "https://api.example.com", want: "{{baseUrl}}/users"},
+		{name: "no base URL", rawURL: "/users", baseURL: "", want: "/users"},
+		{name: "base URL without a host", rawURL: "/users", baseURL: "api.example.com", want: "/users"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, rebaseURL(tt.rawURL, tt.baseURL))
+		})
+	}
+}
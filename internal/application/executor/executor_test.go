@@ -234,8 +234,7 @@ Accept: application/json
 	assert.Equal(t, "https://api.example.com/users", file.Requests[0].URL)
 	assert.Equal(t, "/users", file.Requests[0].Path)
 	assert.Len(t, file.Requests[0].Headers, 1)
-	assert.Equal(t, "Accept", file.Requests[0].Headers[0].Name)
-	assert.Equal(t, "application/json", file.Requests[0].Headers[0].Value)
+	assert.Equal(t, "application/json", file.Requests[0].Headers["Accept"])
 	assert.Empty(t, file.Requests[0].Body)
 
 	// Check second request
@@ -243,8 +242,7 @@ Accept: application/json
 	assert.Equal(t, "https://api.example.com/users", file.Requests[1].URL)
 	assert.Equal(t, "/users", file.Requests[1].Path)
 	assert.Len(t, file.Requests[1].Headers, 2)
-	assert.Equal(t, "Content-Type", file.Requests[1].Headers[0].Name)
-	assert.Equal(t, "application/json", file.Requests[1].Headers[0].Value)
+	assert.Equal(t, "application/json", file.Requests[1].Headers["Content-Type"])
 	assert.Contains(t, file.Requests[1].Body, "John Doe")
 }
 
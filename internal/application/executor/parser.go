@@ -81,7 +81,7 @@ func (p *HTTPFileParser) parseRequests(reader io.Reader) ([]models.HTTPRequest,
 	lineNum := 0
 
 	// Regex for HTTP method and URL
-	methodURLRegex := regexp.MustCompile(`^(GET|POST|PUT|DELETE|PATCH|HEAD|OPTIONS)\s+(.+)$`)
+	methodURLRegex := regexp.MustCompile(`^(GET|POST|PUT|DELETE|PATCH|HEAD|OPTIONS|TRACE)\s+(.+)$`)
 	// Regex for header
 	headerRegex := regexp.MustCompile(`^([^:]+):\s*(.*)$`)
 
@@ -0,0 +1,383 @@
+// Package explain describes how the requests of a .http file will be executed:
+// the URL they resolve to, the spec operation they map to, the variables they
+// are missing and what the spec expects of their responses.
+package explain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// Diagnostic severities
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+// Diagnostic is a problem or note about a request
+type Diagnostic struct {
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// ResponseExpectation describes a response the spec declares for an operation
+type ResponseExpectation struct {
+	Status       string   `json:"status"`
+	Description  string   `json:"description,omitempty"`
+	ContentTypes []string `json:"contentTypes,omitempty"`
+	Schema       string   `json:"schema,omitempty"`
+}
+
+// OperationMatch is the spec operation a request maps to
+type OperationMatch struct {
+	OperationID         string                `json:"operationId,omitempty"`
+	Method              string                `json:"method"`
+	Path                string                `json:"path"`
+	Summary             string                `json:"summary,omitempty"`
+	Deprecated          bool                  `json:"deprecated,omitempty"`
+	RequiredParameters  []string              `json:"requiredParameters,omitempty"`
+	RequestBodyRequired bool                  `json:"requestBodyRequired,omitempty"`
+	Responses           []ResponseExpectation `json:"responses,omitempty"`
+}
+
+// RequestExplanation describes a single request of a .http file
+type RequestExplanation struct {
+	Name             string          `json:"name"`
+	Line             int             `json:"line,omitempty"`
+	Method           string          `json:"method"`
+	URL              string          `json:"url"`
+	ResolvedURL      string          `json:"resolvedUrl"`
+	Variables        []string        `json:"variables,omitempty"`
+	MissingVariables []string        `json:"missingVariables,omitempty"`
+	ChainReferences  []string        `json:"chainReferences,omitempty"`
+	Operation        *OperationMatch `json:"operation,omitempty"`
+	Diagnostics      []Diagnostic    `json:"diagnostics,omitempty"`
+}
+
+// FileExplanation describes the requests of a .http file
+type FileExplanation struct {
+	File     string               `json:"file"`
+	Requests []RequestExplanation `json:"requests"`
+}
+
+// Explainer explains requests, mapping them to the operations of a spec
+type Explainer struct {
+	doc *models.SwaggerDoc
+}
+
+// NewExplainer creates an Explainer. The spec is optional; without it requests
+// are explained without operation details.
+func NewExplainer(doc *models.SwaggerDoc) *Explainer {
+	return &Explainer{doc: doc}
+}
+
+// ExplainFile explains the requests of a file with the given variables
+func (e *Explainer) ExplainFile(filename string, requests []models.HTTPRequest, variables map[string]string) *FileExplanation {
+	explanation := &FileExplanation{
+		File:     filename,
+		Requests: []RequestExplanation{},
+	}
+
+	// Requests may reference the responses of named requests before them
+	earlier := make(map[string]bool)
+	for _, request := range requests {
+		explanation.Requests = append(explanation.Requests, e.ExplainRequest(request, variables, earlier))
+		if request.Name != "" {
+			earlier[request.Name] = true
+		}
+	}
+
+	return explanation
+}
+
+// ExplainRequest explains a request. earlier holds the names of the requests
+// whose responses it may reference.
+func (e *Explainer) ExplainRequest(request models.HTTPRequest, variables map[string]string, earlier map[string]bool) RequestExplanation {
+	explanation := RequestExplanation{
+		Name:   request.Name,
+		Line:   request.Line,
+		Method: strings.ToUpper(request.Method),
+		URL:    request.URL,
+	}
+
+	// Collect the references of the URL, host, headers, query and body
+	seen := make(map[string]bool)
+	for _, text := range models.VariableTexts(&request) {
+		for _, reference := range models.FindVariableReferences(text) {
+			name := reference.Name
+			if name == "" || seen[name] {
+				continue
+			}
+			seen[name] = true
+
+			if response, ok := models.ResponseReference(name); ok {
+				explanation.ChainReferences = append(explanation.ChainReferences, name)
+				if !earlier[response] {
+					explanation.addDiagnostic(SeverityError, "%q references the response of %q, which is not a named request earlier in the file", name, response)
+				}
+				continue
+			}
+			if !models.IsPlainVariable(name) {
+				continue
+			}
+
+			explanation.Variables = append(explanation.Variables, name)
+			if _, ok := variables[name]; !ok {
+				explanation.MissingVariables = append(explanation.MissingVariables, name)
+				explanation.addDiagnostic(SeverityError, "variable %q is not defined", name)
+			}
+		}
+	}
+
+	explanation.ResolvedURL = models.ReplaceVariables(request.URL, variables)
+
+	if e.doc != nil {
+		e.explainOperation(&explanation, request)
+	}
+
+	return explanation
+}
+
+// explainOperation maps the request to a spec operation and checks it against
+// the operation's parameters
+func (e *Explainer) explainOperation(explanation *RequestExplanation, request models.HTTPRequest) {
+	path := requestPath(explanation.ResolvedURL)
+	specPath, item := e.matchPath(explanation.Method, path)
+	if item == nil {
+		explanation.addDiagnostic(SeverityWarning, "no operation in the spec matches %s %s", explanation.Method, path)
+		return
+	}
+
+	operation := item.Operation(explanation.Method)
+	if operation == nil {
+		explanation.addDiagnostic(SeverityWarning, "path %s has no %s operation in the spec", specPath, explanation.Method)
+		return
+	}
+
+	match := &OperationMatch{
+		OperationID: operation.OperationID,
+		Method:      explanation.Method,
+		Path:        specPath,
+		Summary:     operation.Summary,
+		Deprecated:  operation.Deprecated,
+		Responses:   responseExpectations(operation),
+	}
+	explanation.Operation = match
+
+	if operation.Deprecated {
+		explanation.addDiagnostic(SeverityWarning, "operation %s is deprecated", operationLabel(match))
+	}
+
+	// Check the required query and header parameters
+	query := requestQuery(explanation.ResolvedURL)
+	for _, parameter := range append(append([]models.Parameter{}, item.Parameters...), operation.Parameters...) {
+		if !parameter.Required {
+			continue
+		}
+		match.RequiredParameters = append(match.RequiredParameters, fmt.Sprintf("%s:%s", parameter.In, parameter.Name))
+
+		switch parameter.In {
+		case "query":
+			if !query[parameter.Name] {
+				explanation.addDiagnostic(SeverityError, "required query parameter %q is missing", parameter.Name)
+			}
+		case "header":
+			if !models.HasHeader(request.Headers, parameter.Name) {
+				explanation.addDiagnostic(SeverityError, "required header %q is missing", parameter.Name)
+			}
+		case "body":
+			match.RequestBodyRequired = true
+		}
+	}
+
+	if operation.RequestBody != nil && operation.RequestBody.Required {
+		match.RequestBodyRequired = true
+	}
+	if match.RequestBodyRequired && strings.TrimSpace(request.Body) == "" {
+		explanation.addDiagnostic(SeverityError, "operation %s requires a request body", operationLabel(match))
+	}
+}
+
+// matchPath finds the spec path matching a request path, preferring paths with
+// an operation for the method, more literal segments and fewer prefix segments
+// (such as a base path the spec doesn't declare)
+func (e *Explainer) matchPath(method, path string) (string, *models.PathItem) {
+	segments := splitPath(path)
+
+	var bestPath string
+	var bestItem *models.PathItem
+	bestScore := -1
+	for specPath, item := range e.doc.Paths {
+		specSegments := splitPath(specPath)
+		if len(specSegments) > len(segments) {
+			continue
+		}
+		offset := len(segments) - len(specSegments)
+		literal, ok := matchSegments(segments[offset:], specSegments)
+		if !ok {
+			continue
+		}
+
+		item := item
+		score := literal*100 - offset
+		if item.Operation(method) != nil {
+			score += 1000000
+		}
+		if score > bestScore || (score == bestScore && specPath < bestPath) {
+			bestPath, bestItem, bestScore = specPath, &item, score
+		}
+	}
+
+	return bestPath, bestItem
+}
+
+// matchSegments matches request path segments against spec path segments,
+// returning the number of literal segments that matched. Spec parameters such as
+// {id} and unresolved variables in the request match any segment.
+func matchSegments(segments, specSegments []string) (int, bool) {
+	literal := 0
+	for i, specSegment := range specSegments {
+		segment := segments[i]
+		switch {
+		case strings.HasPrefix(specSegment, "{") && strings.HasSuffix(specSegment, "}"):
+			if segment == "" {
+				return 0, false
+			}
+		case strings.Contains(segment, "{{"):
+		case segment == specSegment:
+			literal++
+		default:
+			return 0, false
+		}
+	}
+	return literal, true
+}
+
+// splitPath splits a path into its segments
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+// requestPath returns the path of a request URL without its scheme, host,
+// query string or an unresolved base URL variable
+func requestPath(url string) string {
+	if i := strings.IndexAny(url, "?#"); i >= 0 {
+		url = url[:i]
+	}
+	if i := strings.Index(url, "://"); i >= 0 {
+		rest := url[i+3:]
+		if j := strings.Index(rest, "/"); j >= 0 {
+			return rest[j:]
+		}
+		return "/"
+	}
+	if strings.HasPrefix(url, "{{") {
+		if j := strings.Index(url, "}}"); j >= 0 {
+			url = url[j+2:]
+		}
+	}
+	if !strings.HasPrefix(url, "/") {
+		url = "/" + url
+	}
+	return url
+}
+
+// requestQuery returns the names of the query parameters of a request URL
+func requestQuery(url string) map[string]bool {
+	names := make(map[string]bool)
+	i := strings.Index(url, "?")
+	if i < 0 {
+		return names
+	}
+	query := url[i+1:]
+	if j := strings.Index(query, "#"); j >= 0 {
+		query = query[:j]
+	}
+	for _, pair := range strings.Split(query, "&") {
+		if name := strings.SplitN(pair, "=", 2)[0]; name != "" {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// responseExpectations lists the responses declared by an operation in status order
+func responseExpectations(operation *models.Operation) []ResponseExpectation {
+	statuses := make([]string, 0, len(operation.Responses))
+	for status := range operation.Responses {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+
+	var expectations []ResponseExpectation
+	for _, status := range statuses {
+		response := operation.Responses[status]
+		expectation := ResponseExpectation{
+			Status:      status,
+			Description: response.Description,
+			Schema:      describeSchema(response.Schema),
+		}
+
+		for contentType := range response.Content {
+			expectation.ContentTypes = append(expectation.ContentTypes, contentType)
+		}
+		sort.Strings(expectation.ContentTypes)
+		for _, contentType := range expectation.ContentTypes {
+			if expectation.Schema == "" {
+				expectation.Schema = describeSchema(response.Content[contentType].Schema)
+			}
+		}
+
+		expectations = append(expectations, expectation)
+	}
+	return expectations
+}
+
+// describeSchema returns a short description of a schema, such as "User",
+// "array of User" or "object (required: id, name)"
+func describeSchema(schema *models.Schema) string {
+	if schema == nil {
+		return ""
+	}
+	if schema.Ref != "" {
+		return refName(schema.Ref)
+	}
+	if schema.Type == "array" && schema.Items != nil {
+		if schema.Items.Ref != "" {
+			return "array of " + refName(schema.Items.Ref)
+		}
+		if schema.Items.Type != "" {
+			return "array of " + schema.Items.Type
+		}
+	}
+	if schema.Type == "object" && len(schema.Required) > 0 {
+		return fmt.Sprintf("object (required: %s)", strings.Join(schema.Required, ", "))
+	}
+	return schema.Type
+}
+
+// refName returns the name of the definition a $ref points to
+func refName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+// operationLabel names an operation by its operationId, or method and path
+func operationLabel(match *OperationMatch) string {
+	if match.OperationID != "" {
+		return match.OperationID
+	}
+	return match.Method + " " + match.Path
+}
+
+// addDiagnostic records a diagnostic for the request
+func (r *RequestExplanation) addDiagnostic(severity, format string, args ...interface{}) {
+	r.Diagnostics = append(r.Diagnostics, Diagnostic{Severity: severity, Message: fmt.Sprintf(format, args...)})
+}
@@ -0,0 +1,134 @@
+package explain
+
+import (
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testDoc() *models.SwaggerDoc {
+	return &models.SwaggerDoc{
+		Paths: map[string]models.PathItem{
+			"/users": {
+				Post: &models.Operation{
+					OperationID: "createUser",
+					RequestBody: &models.RequestBody{Required: true},
+					Responses: map[string]models.Response{
+						"201": {Description: "Created", Schema: &models.Schema{Ref: "#/definitions/User"}},
+					},
+				},
+			},
+			"/users/{id}": {
+				Get: &models.Operation{
+					OperationID: "getUser",
+					Deprecated:  true,
+					Parameters: []models.Parameter{
+						{Name: "id", In: "path", Required: true},
+						{Name: "fields", In: "query", Required: true},
+						{Name: "X-Tenant", In: "header", Required: true},
+					},
+					Responses: map[string]models.Response{
+						"404": {Description: "Not found"},
+						"200": {Description: "OK", Schema: &models.Schema{Ref: "#/definitions/User"}},
+					},
+				},
+			},
+			"/users/me": {
+				Get: &models.Operation{OperationID: "getMe"},
+			},
+		},
+	}
+}
+
+func severities(diagnostics []Diagnostic) []string {
+	var result []string
+	for _, diagnostic := range diagnostics {
+		result = append(result, diagnostic.Severity+": "+diagnostic.Message)
+	}
+	return result
+}
+
+func TestExplainFile(t *testing.T) {
+	explainer := NewExplainer(testDoc())
+	requests := []models.HTTPRequest{
+		{
+			Name:    "getUser",
+			Method:  "GET",
+			URL:     "{{baseUrl}}/api/users/{{userId}}?fields=name",
+			Headers: map[string]string{"Authorization": "Bearer {{token}}"},
+			Line:    3,
+		},
+		{
+			Name:   "me",
+			Method: "GET",
+			URL:    "https://example.com/users/me",
+		},
+		{
+			Name:   "create",
+			Method: "POST",
+			URL:    "https://example.com/users",
+			Body:   "",
+		},
+		{
+			Name:   "orphan",
+			Method: "DELETE",
+			URL:    "https://example.com/orders/{{getUser.response.body.$.id}}/{{later.response.body.$.id}}",
+		},
+	}
+	variables := map[string]string{"baseUrl": "https://example.com", "userId": "42"}
+
+	explanation := explainer.ExplainFile("users.http", requests, variables)
+	require.Len(t, explanation.Requests, 4)
+
+	getUser := explanation.Requests[0]
+	assert.Equal(t, "https://example.com/api/users/42?fields=name", getUser.ResolvedURL)
+	assert.Equal(t, []string{"baseUrl", "userId", "token"}, getUser.Variables)
+	assert.Equal(t, []string{"token"}, getUser.MissingVariables)
+	require.NotNil(t, getUser.Operation)
+	assert.Equal(t, "getUser", getUser.Operation.OperationID)
+	assert.Equal(t, "/users/{id}", getUser.Operation.Path)
+	assert.Equal(t, []string{"path:id", "query:fields", "header:X-Tenant"}, getUser.Operation.RequiredParameters)
+	assert.Equal(t, []ResponseExpectation{
+		{Status: "200", Description: "OK", Schema: "User"},
+		{Status: "404", Description: "Not found"},
+	}, getUser.Operation.Responses)
+	assert.Equal(t, []string{
+		`error: variable "token" is not defined`,
+		"warning: operation getUser is deprecated",
+		`error: required header "X-Tenant" is missing`,
+	}, severities(getUser.Diagnostics))
+
+	// Literal segments win over path parameters
+	me := explanation.Requests[1]
+	require.NotNil(t, me.Operation)
+	assert.Equal(t, "getMe", me.Operation.OperationID)
+	assert.Empty(t, me.Diagnostics)
+
+	create := explanation.Requests[2]
+	require.NotNil(t, create.Operation)
+	assert.True(t, create.Operation.RequestBodyRequired)
+	assert.Equal(t, []string{"error: operation createUser requires a request body"}, severities(create.Diagnostics))
+
+	orphan := explanation.Requests[3]
+	assert.Nil(t, orphan.Operation)
+	assert.Equal(t, []string{"getUser.response.body.$.id", "later.response.body.$.id"}, orphan.ChainReferences)
+	assert.Equal(t, []string{
+		`error: "later.response.body.$.id" references the response of "later", which is not a named request earlier in the file`,
+		"warning: no operation in the spec matches DELETE /orders/{{getUser.response.body.$.id}}/{{later.response.body.$.id}}",
+	}, severities(orphan.Diagnostics))
+}
+
+func TestExplainRequestWithoutSpec(t *testing.T) {
+	explanation := NewExplainer(nil).ExplainRequest(models.HTTPRequest{
+		Method: "get",
+		URL:    "{{baseUrl}}/health",
+	}, nil, nil)
+
+	assert.Equal(t, "GET", explanation.Method)
+	assert.Equal(t, "{{baseUrl}}/health", explanation.ResolvedURL)
+	assert.Equal(t, []string{"baseUrl"}, explanation.MissingVariables)
+	assert.Nil(t, explanation.Operation)
+	assert.Len(t, explanation.Diagnostics, 1)
+}
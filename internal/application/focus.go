@@ -0,0 +1,17 @@
+package application
+
+import "github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+
+// countOnly counts the requests marked "@only", which are the only ones run
+// when there are any
+func countOnly(files []*models.HTTPFile) int {
+	count := 0
+	for _, file := range files {
+		for _, request := range file.Requests {
+			if request.Only {
+				count++
+			}
+		}
+	}
+	return count
+}
@@ -0,0 +1,163 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// callbackExpressionPattern matches the runtime expressions of callback URLs
+// that take the URL from the request, e.g. "{$request.body#/callbackUrl}"
+var callbackExpressionPattern = regexp.MustCompile(`^\{\$request\.(?:body#(/[^}]*)|query\.([^}]+)|header\.([^}]+))\}`)
+
+// callbackMethods is the order in which the methods of a callback path item are tried
+var callbackMethods = []string{"POST", "PUT", "PATCH", "GET", "DELETE"}
+
+// applyCallbacks points the callback URLs of an operation at the test runner's
+// callback listener, through the "{{callback.<name>}}" variable, and declares
+// the callbacks on the request so the runner waits for them
+func (g *HTTPGenerator) applyCallbacks(operation *models.Operation, request *models.HTTPRequest) error {
+	names := make([]string, 0, len(operation.Callbacks))
+	for name := range operation.Callbacks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		callback := operation.Callbacks[name]
+		expressions := make([]string, 0, len(callback))
+		for expression := range callback {
+			expressions = append(expressions, expression)
+		}
+		sort.Strings(expressions)
+
+		injected := false
+		for _, expression := range expressions {
+			matches := callbackExpressionPattern.FindStringSubmatch(expression)
+			if matches == nil {
+				continue
+			}
+
+			variable := "{{" + models.CallbackVariable(name) + "}}"
+			switch {
+			case matches[1] != "":
+				body, err := setJSONPointer(request.Body, matches[1], variable, g.indentJSON)
+				if err != nil {
+					return fmt.Errorf("callback %s: %w", name, err)
+				}
+				request.Body = body
+			case matches[2] != "":
+				separator := "?"
+				if strings.Contains(request.URL, "?") {
+					separator = "&"
+				}
+				request.URL += separator + matches[2] + "=" + variable
+			default:
+				if request.Headers == nil {
+					request.Headers = make(map[string]string)
+				}
+				request.Headers[matches[3]] = variable
+			}
+
+			pathItem := callback[expression]
+			request.Callbacks = append(request.Callbacks, callbackExpectation(name, &pathItem))
+			injected = true
+			break
+		}
+
+		if !injected {
+			request.Comments = append(request.Comments, fmt.Sprintf("Callback %s is sent to a URL that can't be pointed at the test listener", name))
+		}
+	}
+
+	return nil
+}
+
+// callbackExpectation describes the callback the server sends for a path item:
+// its method and the required top-level fields of its JSON payload
+func callbackExpectation(name string, pathItem *models.PathItem) models.CallbackExpectation {
+	expectation := models.CallbackExpectation{Name: name}
+	for _, method := range callbackMethods {
+		operation := pathItem.Operation(method)
+		if operation == nil {
+			continue
+		}
+		expectation.Method = method
+		if operation.RequestBody != nil {
+			if media, ok := operation.RequestBody.Content["application/json"]; ok && media.Schema != nil {
+				expectation.Fields = append([]string(nil), media.Schema.Required...)
+				sort.Strings(expectation.Fields)
+			}
+		}
+		break
+	}
+	return expectation
+}
+
+// setJSONPointer sets the value at a JSON pointer of a JSON body, creating the
+// objects on the way. An empty body starts as an empty object.
+func setJSONPointer(body, pointer string, value interface{}, indent bool) (string, error) {
+	var document interface{} = map[string]interface{}{}
+	if strings.TrimSpace(body) != "" {
+		if err := json.Unmarshal([]byte(body), &document); err != nil {
+			return "", fmt.Errorf("request body is not JSON: %w", err)
+		}
+	}
+
+	tokens := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for i := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(tokens[i], "~1", "/"), "~0", "~")
+	}
+
+	updated, err := setAt(document, tokens, value)
+	if err != nil {
+		return "", fmt.Errorf("cannot set %s: %w", pointer, err)
+	}
+
+	var data []byte
+	if indent {
+		data, err = json.MarshalIndent(updated, "", "  ")
+	} else {
+		data, err = json.Marshal(updated)
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// setAt sets the value at the path of tokens in a decoded JSON value
+func setAt(node interface{}, tokens []string, value interface{}) (interface{}, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+
+	switch v := node.(type) {
+	case map[string]interface{}:
+		child, err := setAt(v[tokens[0]], tokens[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		v[tokens[0]] = child
+		return v, nil
+	case []interface{}:
+		index, err := strconv.Atoi(tokens[0])
+		if err != nil || index < 0 || index >= len(v) {
+			return nil, fmt.Errorf("invalid array index %q", tokens[0])
+		}
+		child, err := setAt(v[index], tokens[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		v[index] = child
+		return v, nil
+	case nil:
+		return setAt(map[string]interface{}{}, tokens, value)
+	}
+	return nil, fmt.Errorf("%q is not an object", tokens[0])
+}
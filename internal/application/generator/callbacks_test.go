@@ -0,0 +1,76 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyCallbacks(t *testing.T) {
+	event := models.PathItem{
+		Post: &models.Operation{
+			RequestBody: &models.RequestBody{Content: map[string]models.MediaType{
+				"application/json": {Schema: &models.Schema{Type: "object", Required: []string{"status", "id"}}},
+			}},
+		},
+	}
+	operation := &models.Operation{
+		Callbacks: map[string]models.Callback{
+			"onEvent":  {"{$request.body#/subscription/callbackUrl}": event},
+			"onCancel": {"{$request.query.cancelUrl}/cancelled": {Get: &models.Operation{}}},
+			"onAudit":  {"https://audit.example.com/{$request.body#/id}": event},
+		},
+	}
+	request := &models.HTTPRequest{URL: "https://api.example.com/subscriptions", Body: `{"id": 1}`}
+
+	generator := NewHTTPGenerator(WithIndentJSON(false))
+	require.NoError(t, generator.applyCallbacks(operation, request))
+
+	assert.Equal(t, "https://api.example.com/subscriptions?cancelUrl={{callback.onCancel}}", request.URL)
+	assert.JSONEq(t, `{"id": 1, "subscription": {"callbackUrl": "{{callback.onEvent}}"}}`, request.Body)
+	assert.Equal(t, []models.CallbackExpectation{
+		{Name: "onCancel", Method: "GET"},
+		{Name: "onEvent", Method: "POST", Fields: []string{"id", "status"}},
+	}, request.Callbacks)
+	assert.Equal(t, []string{"Callback onAudit is sent to a URL that can't be pointed at the test listener"}, request.Comments)
+}
+
+func TestApplyCallbacks_Header(t *testing.T) {
+	operation := &models.Operation{
+		Callbacks: map[string]models.Callback{
+			"onHook": {"{$request.header.X-Callback-Url}": {Post: &models.Operation{}}},
+		},
+	}
+
+	// The header is added to requests without headers, and to those with some
+	for _, request := range []*models.HTTPRequest{
+		{URL: "https://api.example.com/hooks"},
+		{URL: "https://api.example.com/hooks", Headers: map[string]string{"Accept": "application/json"}},
+	} {
+		require.NoError(t, NewHTTPGenerator().applyCallbacks(operation, request))
+		assert.Equal(t, "{{callback.onHook}}", request.Headers["X-Callback-Url"])
+		assert.Equal(t, []models.CallbackExpectation{{Name: "onHook", Method: "POST"}}, request.Callbacks)
+	}
+}
+
+func TestSetJSONPointer(t *testing.T) {
+	body, err := setJSONPointer("", "/hook/url", "x", false)
+	require.NoError(t, err)
+	assert.Equal(t, `{"hook":{"url":"x"}}`, body)
+
+	body, err = setJSONPointer(`{"hooks": [{"url": ""}]}`, "/hooks/0/url", "x", false)
+	require.NoError(t, err)
+	assert.Equal(t, `{"hooks":[{"url":"x"}]}`, body)
+
+	body, err = setJSONPointer(`{"a~b": {}}`, "/a~0b/c~1d", "x", false)
+	require.NoError(t, err)
+	assert.Equal(t, `{"a~b":{"c/d":"x"}}`, body)
+
+	_, err = setJSONPointer(`{"id": 1}`, "/id/url", "x", false)
+	assert.Error(t, err)
+
+	_, err = setJSONPointer(`{"hooks": []}`, "/hooks/0", "x", false)
+	assert.Error(t, err)
+}
@@ -0,0 +1,180 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// componentSchemas returns the named schemas of a document that references
+// may point to: components of OpenAPI 3.0 and definitions of Swagger 2.0
+func componentSchemas(doc *models.SwaggerDoc) map[string]*models.Schema {
+	schemas := make(map[string]*models.Schema)
+	if doc.Components != nil {
+		for name, schema := range doc.Components.Schemas {
+			schema := schema
+			schemas["#/components/schemas/"+name] = &schema
+		}
+	}
+	for name, definition := range doc.Definitions {
+		data, err := json.Marshal(definition)
+		if err != nil {
+			continue
+		}
+		var schema models.Schema
+		if err := json.Unmarshal(data, &schema); err == nil {
+			schemas["#/definitions/"+name] = &schema
+		}
+	}
+	return schemas
+}
+
+// resolveSchema follows the reference of a schema. It returns the schema
+// itself when it has no reference, and false for unknown references.
+func (g *HTTPGenerator) resolveSchema(schema *models.Schema) (*models.Schema, bool) {
+	if schema.Ref == "" {
+		return schema, true
+	}
+	target, ok := g.schemas[schema.Ref]
+	return target, ok
+}
+
+// mergeAllOf merges the members of allOf into a single schema: their
+// properties and required properties add up, and the first discriminator and
+// oneOf or anyOf found are kept
+func (g *HTTPGenerator) mergeAllOf(schema *models.Schema) *models.Schema {
+	merged := *schema
+	merged.AllOf = nil
+	merged.Properties = make(map[string]*models.Schema)
+	merged.Required = append([]string(nil), schema.Required...)
+	for name, property := range schema.Properties {
+		merged.Properties[name] = property
+	}
+
+	for _, member := range schema.AllOf {
+		resolved, ok := g.resolveSchema(member)
+		if !ok || g.resolving[member.Ref] {
+			continue
+		}
+		if member.Ref != "" {
+			g.resolving[member.Ref] = true
+		}
+		if len(resolved.AllOf) > 0 {
+			resolved = g.mergeAllOf(resolved)
+		}
+		delete(g.resolving, member.Ref)
+
+		for name, property := range resolved.Properties {
+			merged.Properties[name] = property
+		}
+		merged.Required = append(merged.Required, resolved.Required...)
+		if merged.Type == "" {
+			merged.Type = resolved.Type
+		}
+		if merged.Discriminator == nil {
+			merged.Discriminator = resolved.Discriminator
+		}
+		if len(merged.OneOf) == 0 && len(merged.AnyOf) == 0 {
+			merged.OneOf, merged.AnyOf = resolved.OneOf, resolved.AnyOf
+		}
+	}
+
+	if merged.Type == "" && len(merged.Properties) > 0 {
+		merged.Type = "object"
+	}
+	return &merged
+}
+
+// branches returns the alternatives of a oneOf or anyOf schema
+func branches(schema *models.Schema) []*models.Schema {
+	if len(schema.OneOf) > 0 {
+		return schema.OneOf
+	}
+	return schema.AnyOf
+}
+
+// branchExample generates the example of a branch of a oneOf or anyOf schema,
+// along with the properties the schema declares itself, setting the
+// discriminator property to the value selecting the branch
+func (g *HTTPGenerator) branchExample(schema, branch *models.Schema) interface{} {
+	example := g.generateExample(branch)
+	object, ok := example.(map[string]interface{})
+	if !ok {
+		return example
+	}
+
+	if len(schema.Properties) > 0 {
+		own, _ := g.generateObjectExample(schema).(map[string]interface{})
+		for name, value := range own {
+			if _, exists := object[name]; !exists {
+				object[name] = value
+			}
+		}
+	}
+	if schema.Discriminator != nil && schema.Discriminator.PropertyName != "" {
+		if value := discriminatorValue(schema.Discriminator, branch); value != "" {
+			object[schema.Discriminator.PropertyName] = value
+		}
+	}
+	return object
+}
+
+// discriminatorValue returns the value of the discriminator property that
+// selects a branch: its key in the mapping, or the name of the schema it
+// references
+func discriminatorValue(discriminator *models.Discriminator, branch *models.Schema) string {
+	if branch.Ref == "" {
+		return ""
+	}
+	keys := make([]string, 0, len(discriminator.Mapping))
+	for key := range discriminator.Mapping {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if target := discriminator.Mapping[key]; target == branch.Ref || target == schemaName(branch.Ref) {
+			return key
+		}
+	}
+	return schemaName(branch.Ref)
+}
+
+// schemaName returns the name of the schema a reference points to
+func schemaName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+// bodyVariants renders the examples of the branches of a oneOf or anyOf body
+// not used by the request, as comments showing the alternate bodies
+func (g *HTTPGenerator) bodyVariants(schema *models.Schema) []string {
+	if schema == nil || schema.Example != nil || g.bodyMode == BodyModeRequiredOnly {
+		return nil
+	}
+	if resolved, ok := g.resolveSchema(schema); ok {
+		schema = resolved
+	}
+	if len(schema.AllOf) > 0 {
+		schema = g.mergeAllOf(schema)
+	}
+
+	alternatives := branches(schema)
+	if len(alternatives) < 2 {
+		return nil
+	}
+
+	var variants []string
+	for i, branch := range alternatives[1:] {
+		label := branch.Title
+		if label == "" && branch.Ref != "" {
+			label = schemaName(branch.Ref)
+		}
+		if label == "" {
+			label = fmt.Sprintf("option %d", i+2)
+		}
+		variants = append(variants, fmt.Sprintf("Alternate body (%s):\n%s", label, g.marshalExample(g.branchExample(schema, branch))))
+	}
+	return variants
+}
@@ -0,0 +1,287 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// Vendor extensions handled by default
+const (
+	// ExtensionInternal set to true leaves the operation out of the generated files
+	ExtensionInternal = "x-internal"
+
+	// ExtensionRateLimit throttles the requests of the operation when testing,
+	// e.g. "10/s", 5 (per second) or {requests: 100, period: 1m}
+	ExtensionRateLimit = "x-rate-limit"
+
+	// ExtensionFaker names the kind of value to generate for a schema, e.g. "internet.email"
+	ExtensionFaker = "x-faker"
+)
+
+// ErrSkipOperation is returned by operation extensions to leave the operation
+// out of the generated files
+var ErrSkipOperation = errors.New("operation skipped by extension")
+
+// OperationExtensionFunc applies the value of a vendor extension of an operation,
+// or of its path item, to the request generated for it
+type OperationExtensionFunc func(value interface{}, request *models.HTTPRequest) error
+
+// SchemaExtensionFunc returns the example value of a schema with a vendor
+// extension, or false to generate the example from the schema as usual
+type SchemaExtensionFunc func(value interface{}, schema *models.Schema) (interface{}, bool)
+
+// ExtensionRegistry maps vendor extensions to the behaviors they drive during
+// generation. Handlers run in extension name order.
+type ExtensionRegistry struct {
+	operations map[string]OperationExtensionFunc
+	schemas    map[string]SchemaExtensionFunc
+}
+
+// NewExtensionRegistry creates an empty ExtensionRegistry
+func NewExtensionRegistry() *ExtensionRegistry {
+	return &ExtensionRegistry{
+		operations: make(map[string]OperationExtensionFunc),
+		schemas:    make(map[string]SchemaExtensionFunc),
+	}
+}
+
+// DefaultExtensions creates a registry with the x-internal, x-rate-limit and
+// x-faker extensions
+func DefaultExtensions() *ExtensionRegistry {
+	registry := NewExtensionRegistry()
+	registry.RegisterOperation(ExtensionInternal, applyInternal)
+	registry.RegisterOperation(ExtensionRateLimit, applyRateLimit)
+	registry.RegisterSchema(ExtensionFaker, fakerExample)
+	return registry
+}
+
+// RegisterOperation sets the handler of a vendor extension of operations,
+// replacing any previous one
+func (r *ExtensionRegistry) RegisterOperation(name string, handler OperationExtensionFunc) {
+	r.operations[name] = handler
+}
+
+// RegisterSchema sets the handler of a vendor extension of schemas, replacing
+// any previous one
+func (r *ExtensionRegistry) RegisterSchema(name string, handler SchemaExtensionFunc) {
+	r.schemas[name] = handler
+}
+
+// applyOperation runs the handlers of the extensions set on an operation
+func (r *ExtensionRegistry) applyOperation(extensions models.Extensions, request *models.HTTPRequest) error {
+	for _, name := range sortedExtensionNames(extensions) {
+		handler, ok := r.operations[name]
+		if !ok {
+			continue
+		}
+		if err := handler(extensions[name], request); err != nil {
+			if errors.Is(err, ErrSkipOperation) {
+				return err
+			}
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// schemaExample returns the example value given by the first handler of the
+// extensions set on a schema that produces one
+func (r *ExtensionRegistry) schemaExample(schema *models.Schema) (interface{}, bool) {
+	for _, name := range sortedExtensionNames(schema.Extensions) {
+		handler, ok := r.schemas[name]
+		if !ok {
+			continue
+		}
+		if value, ok := handler(schema.Extensions[name], schema); ok {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// sortedExtensionNames returns the names of the extensions in order
+func sortedExtensionNames(extensions models.Extensions) []string {
+	names := make([]string, 0, len(extensions))
+	for name := range extensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyInternal skips operations marked with x-internal: true
+func applyInternal(value interface{}, request *models.HTTPRequest) error {
+	if (models.Extensions{ExtensionInternal: value}).Bool(ExtensionInternal) {
+		return ErrSkipOperation
+	}
+	return nil
+}
+
+// applyRateLimit sets the rate limit of the request from x-rate-limit
+func applyRateLimit(value interface{}, request *models.HTTPRequest) error {
+	limit, err := parseRateLimitExtension(value)
+	if err != nil {
+		return err
+	}
+	request.RateLimit = &limit
+	return nil
+}
+
+// parseRateLimitExtension parses the value of x-rate-limit: a number of requests
+// per second, a "<requests>/<period>" string, or an object with "requests" (or
+// "limit") and "period" (or "window", a duration or a number of seconds)
+func parseRateLimitExtension(value interface{}) (models.RateLimit, error) {
+	switch v := value.(type) {
+	case int:
+		return perSecond(float64(v))
+	case float64:
+		return perSecond(v)
+	case string:
+		if number, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+			return perSecond(number)
+		}
+		return models.ParseRateLimit(v)
+	case map[string]interface{}:
+		requests := firstValue(v, "requests", "limit")
+		period := firstValue(v, "period", "window")
+		count, ok := toFloat(requests)
+		if !ok || count < 1 {
+			return models.RateLimit{}, fmt.Errorf("invalid rate limit %v: requests must be a positive number", value)
+		}
+		limit := models.RateLimit{Requests: int(count), Per: time.Second}
+		switch p := period.(type) {
+		case nil:
+		case string:
+			per, err := models.ParseRateLimit("1/" + p)
+			if err != nil {
+				return models.RateLimit{}, fmt.Errorf("invalid rate limit period %q", p)
+			}
+			limit.Per = per.Per
+		default:
+			seconds, ok := toFloat(p)
+			if !ok || seconds <= 0 {
+				return models.RateLimit{}, fmt.Errorf("invalid rate limit period %v", p)
+			}
+			limit.Per = time.Duration(seconds * float64(time.Second))
+		}
+		return limit, nil
+	}
+	return models.RateLimit{}, fmt.Errorf("invalid rate limit %v", value)
+}
+
+// perSecond returns a rate limit of a number of requests per second
+func perSecond(requests float64) (models.RateLimit, error) {
+	if requests < 1 {
+		return models.RateLimit{}, fmt.Errorf("invalid rate limit %v: requests must be a positive number", requests)
+	}
+	return models.RateLimit{Requests: int(requests), Per: time.Second}, nil
+}
+
+// firstValue returns the value of the first key present in the object
+func firstValue(object map[string]interface{}, keys ...string) interface{} {
+	for _, key := range keys {
+		if value, ok := object[key]; ok {
+			return value
+		}
+	}
+	return nil
+}
+
+// toFloat converts a decoded JSON or YAML number to a float64
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// fakerValues are the example values of the x-faker kinds, keyed by their
+// lower-cased faker.js style name. Values are fixed so generated files are stable.
+var fakerValues = map[string]interface{}{
+	"name.firstname":         "Ada",
+	"name.lastname":          "Lovelace",
+	"name.fullname":          "Ada Lovelace",
+	"name.findname":          "Ada Lovelace",
+	"name.jobtitle":          "Software Engineer",
+	"person.firstname":       "Ada",
+	"person.lastname":        "Lovelace",
+	"person.fullname":        "Ada Lovelace",
+	"person.jobtitle":        "Software Engineer",
+	"internet.email":         "ada.lovelace@example.com",
+	"internet.username":      "ada.lovelace",
+	"internet.url":           "https://example.com",
+	"internet.domainname":    "example.com",
+	"internet.ip":            "192.0.2.1",
+	"internet.ipv4":          "192.0.2.1",
+	"internet.ipv6":          "2001:db8::1",
+	"internet.password":      "correct-horse-battery-staple",
+	"phone.number":           "+1-555-0100",
+	"phone.phonenumber":      "+1-555-0100",
+	"address.streetaddress":  "1 Main Street",
+	"address.city":           "Springfield",
+	"address.state":          "Illinois",
+	"address.zipcode":        "62701",
+	"address.country":        "United States",
+	"address.countrycode":    "US",
+	"location.streetaddress": "1 Main Street",
+	"location.city":          "Springfield",
+	"location.state":         "Illinois",
+	"location.zipcode":       "62701",
+	"location.country":       "United States",
+	"location.countrycode":   "US",
+	"company.name":           "Acme Inc",
+	"company.companyname":    "Acme Inc",
+	"commerce.productname":   "Ergonomic Chair",
+	"commerce.price":         "19.99",
+	"lorem.word":             "lorem",
+	"lorem.words":            "lorem ipsum dolor",
+	"lorem.sentence":         "Lorem ipsum dolor sit amet.",
+	"lorem.paragraph":        "Lorem ipsum dolor sit amet, consectetur adipiscing elit.",
+	"datatype.uuid":          "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+	"string.uuid":            "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+	"datatype.number":        42,
+	"number.int":             42,
+	"datatype.float":         4.2,
+	"number.float":           4.2,
+	"datatype.boolean":       true,
+	"date.past":              "2024-01-15T09:30:00Z",
+	"date.recent":            "2025-01-01T12:00:00Z",
+	"date.future":            "2026-01-15T09:30:00Z",
+	"date.birthdate":         "1990-12-10",
+	"finance.amount":         "100.00",
+	"finance.currencycode":   "USD",
+	"finance.iban":           "GB82WEST12345698765432",
+	"image.url":              "https://example.com/image.png",
+}
+
+// fakerExample returns the example value of the x-faker kind, e.g.
+// "internet.email" or "{{internet.email}}"; unknown kinds fall back to the schema
+func fakerExample(value interface{}, schema *models.Schema) (interface{}, bool) {
+	kind, ok := value.(string)
+	if !ok {
+		return nil, false
+	}
+	kind = strings.ToLower(strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(kind), "{{"), "}}")))
+
+	example, ok := fakerValues[kind]
+	if !ok {
+		return nil, false
+	}
+
+	// Numbers written as strings in the table follow the schema's type
+	if text, isString := example.(string); isString && (schema.Type == "number" || schema.Type == "integer") {
+		if number, err := strconv.ParseFloat(text, 64); err == nil {
+			return number, true
+		}
+	}
+	return example, true
+}
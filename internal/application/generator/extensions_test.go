@@ -0,0 +1,100 @@
+package generator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRateLimitExtension(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   interface{}
+		want    models.RateLimit
+		wantErr bool
+	}{
+		{name: "integer", value: 5, want: models.RateLimit{Requests: 5, Per: time.Second}},
+		{name: "number string", value: "5", want: models.RateLimit{Requests: 5, Per: time.Second}},
+		{name: "rate string", value: "100/m", want: models.RateLimit{Requests: 100, Per: time.Minute}},
+		{name: "object", value: map[string]interface{}{"requests": 100, "period": "1h"}, want: models.RateLimit{Requests: 100, Per: time.Hour}},
+		{name: "object with seconds", value: map[string]interface{}{"limit": float64(3), "window": float64(10)}, want: models.RateLimit{Requests: 3, Per: 10 * time.Second}},
+		{name: "zero", value: 0, wantErr: true},
+		{name: "object without requests", value: map[string]interface{}{"period": "1m"}, wantErr: true},
+		{name: "list", value: []interface{}{1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRateLimitExtension(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestExtensionRegistry(t *testing.T) {
+	registry := DefaultExtensions()
+
+	request := &models.HTTPRequest{Name: "listUsers"}
+	require.NoError(t, registry.applyOperation(models.Extensions{"x-rate-limit": "10/s", "x-unknown": true}, request))
+	assert.Equal(t, &models.RateLimit{Requests: 10, Per: time.Second}, request.RateLimit)
+
+	assert.ErrorIs(t, registry.applyOperation(models.Extensions{"x-internal": true}, request), ErrSkipOperation)
+	assert.NoError(t, registry.applyOperation(models.Extensions{"x-internal": "false"}, request))
+	assert.ErrorContains(t, registry.applyOperation(models.Extensions{"x-rate-limit": "often"}, request), "x-rate-limit")
+
+	email, ok := registry.schemaExample(&models.Schema{Type: "string", Extensions: models.Extensions{"x-faker": "{{internet.email}}"}})
+	assert.True(t, ok)
+	assert.Equal(t, "ada.lovelace@example.com", email)
+
+	price, ok := registry.schemaExample(&models.Schema{Type: "number", Extensions: models.Extensions{"x-faker": "commerce.price"}})
+	assert.True(t, ok)
+	assert.Equal(t, 19.99, price)
+
+	_, ok = registry.schemaExample(&models.Schema{Type: "string", Extensions: models.Extensions{"x-faker": "unknown.kind"}})
+	assert.False(t, ok)
+
+	// New extensions hook into the registry
+	registry.RegisterOperation("x-team", func(value interface{}, request *models.HTTPRequest) error {
+		request.Comments = append(request.Comments, "Team: "+value.(string))
+		return nil
+	})
+	require.NoError(t, registry.applyOperation(models.Extensions{"x-team": "payments"}, request))
+	assert.Equal(t, []string{"Team: payments"}, request.Comments)
+}
+
+func TestGenerateAppliesExtensions(t *testing.T) {
+	doc := goldenDoc()
+	users := doc.Paths["/users"]
+	users.Get.Extensions = models.Extensions{"x-internal": true}
+	users.Post.Extensions = models.Extensions{"x-rate-limit": "2/s"}
+	users.Post.RequestBody.Content["application/json"].Schema.Properties["email"].Extensions = models.Extensions{"x-faker": "internet.email"}
+
+	collection, err := NewHTTPGenerator(WithBaseURL("https://api.example.com")).Generate(context.Background(), doc)
+	require.NoError(t, err)
+
+	var names []string
+	var create *models.HTTPRequest
+	for _, directory := range collection.Directories {
+		for _, file := range directory.Files {
+			for i, request := range file.Requests {
+				names = append(names, request.Name)
+				if request.Name == "createUser" {
+					create = &file.Requests[i]
+				}
+			}
+		}
+	}
+	assert.NotContains(t, names, "listUsers")
+	require.NotNil(t, create)
+	assert.Equal(t, &models.RateLimit{Requests: 2, Per: time.Second}, create.RateLimit)
+	assert.Contains(t, create.Body, `"email": "ada.lovelace@example.com"`)
+}
@@ -3,11 +3,14 @@ package generator
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/edgardnogueira/swagger-to-http/internal/version"
 )
 
 // HTTPGenerator implements the HTTPGenerator interface
@@ -18,8 +21,48 @@ type HTTPGenerator struct {
 	includeAuth  bool
 	authHeader   string
 	authToken    string
+	multiTag     string
+	sharedDir    string
+	server       models.ServerSelector
+	extensions   *ExtensionRegistry
+	bodyMode     string
+	readOnly     bool
+	securityFilter      []string
+	skipUnauthenticated bool
+	manifest            bool
+	schemas      map[string]*models.Schema // Schemas references point to, set for a run
+	resolving    map[string]bool           // References being resolved, to stop at cycles
 }
 
+// Ways of placing operations that have several tags
+const (
+	// MultiTagFirst places the operation in the directory of its first tag
+	MultiTagFirst = "first"
+
+	// MultiTagDuplicate places a copy of the operation in the directory of every tag
+	MultiTagDuplicate = "duplicate"
+
+	// MultiTagShared places the operation in the shared directory and lists it
+	// in the files of its tags
+	MultiTagShared = "shared"
+)
+
+// DefaultSharedDir is the directory of operations shared by several tags
+const DefaultSharedDir = "shared"
+
+// Verbosity of the example request bodies generated from schemas
+const (
+	// BodyModeRequiredOnly only includes the required properties of objects
+	BodyModeRequiredOnly = "required-only"
+
+	// BodyModeMinimal includes the required properties, following the first
+	// branch of oneOf and anyOf
+	BodyModeMinimal = "minimal"
+
+	// BodyModeFull includes every property and one item in each array
+	BodyModeFull = "full"
+)
+
 // HTTPGeneratorOption represents an option for configuring the HTTP generator
 type HTTPGeneratorOption func(*HTTPGenerator)
 
@@ -53,12 +96,78 @@ func WithAuth(include bool, header, token string) HTTPGeneratorOption {
 	}
 }
 
+// WithMultiTag sets how operations with several tags are placed: MultiTagFirst,
+// MultiTagDuplicate or MultiTagShared
+func WithMultiTag(mode string) HTTPGeneratorOption {
+	return func(g *HTTPGenerator) {
+		if mode != "" {
+			g.multiTag = mode
+		}
+	}
+}
+
+// WithSharedDir sets the directory of operations shared by several tags
+func WithSharedDir(dir string) HTTPGeneratorOption {
+	return func(g *HTTPGenerator) {
+		if dir != "" {
+			g.sharedDir = dir
+		}
+	}
+}
+
+// WithServer selects the server of the document requests are sent to, and the
+// values of its variables. It has no effect when a base URL is set.
+func WithServer(selector models.ServerSelector) HTTPGeneratorOption {
+	return func(g *HTTPGenerator) {
+		g.server = selector
+	}
+}
+
+// WithExtensions sets the registry of the vendor extensions applied during generation
+func WithExtensions(registry *ExtensionRegistry) HTTPGeneratorOption {
+	return func(g *HTTPGenerator) {
+		if registry != nil {
+			g.extensions = registry
+		}
+	}
+}
+
+// WithBodyMode sets the verbosity of generated request bodies: BodyModeRequiredOnly,
+// BodyModeMinimal or BodyModeFull
+func WithBodyMode(mode string) HTTPGeneratorOption {
+	return func(g *HTTPGenerator) {
+		if mode != "" {
+			g.bodyMode = mode
+		}
+	}
+}
+
+// WithReadOnly includes the readOnly properties of schemas in request bodies,
+// which servers set themselves and are left out by default
+func WithReadOnly(include bool) HTTPGeneratorOption {
+	return func(g *HTTPGenerator) {
+		g.readOnly = include
+	}
+}
+
+// WithManifest enables or disables the manifest listing the generated requests
+func WithManifest(enabled bool) HTTPGeneratorOption {
+	return func(g *HTTPGenerator) {
+		g.manifest = enabled
+	}
+}
+
 // NewHTTPGenerator creates a new HTTPGenerator with options
 func NewHTTPGenerator(opts ...HTTPGeneratorOption) *HTTPGenerator {
 	generator := &HTTPGenerator{
 		defaultTag: "default",
 		indentJSON: true,
 		authHeader: "Authorization",
+		multiTag:   MultiTagFirst,
+		sharedDir:  DefaultSharedDir,
+		extensions: DefaultExtensions(),
+		bodyMode:   BodyModeFull,
+		manifest:   true,
 	}
 
 	for _, opt := range opts {
@@ -75,78 +184,118 @@ func (g *HTTPGenerator) Generate(ctx context.Context, doc *models.SwaggerDoc) (*
 		RootFiles:   []models.HTTPFile{},
 	}
 
-	// Use servers from OpenAPI 3.0 or host+basePath from Swagger 2.0
-	baseURL := g.baseURL
-	if baseURL == "" {
-		if len(doc.Servers) > 0 && doc.Servers[0].URL != "" {
-			baseURL = doc.Servers[0].URL
-		} else if doc.Host != "" {
-			scheme := "https"
-			if len(doc.Schemes) > 0 {
-				scheme = doc.Schemes[0]
-			}
-			baseURL = fmt.Sprintf("%s://%s%s", scheme, doc.Host, doc.BasePath)
+	// Examples resolve the references to the schemas of the document
+	run := *g
+	run.schemas = componentSchemas(doc)
+	run.resolving = make(map[string]bool)
+	g = &run
+
+	// Requests use the base URL option, or the URL of the selected server: one of
+	// the servers from OpenAPI 3.0 or host+basePath from Swagger 2.0
+	if g.baseURL == "" {
+		serverURL, err := doc.ServerURL(g.server)
+		if err != nil {
+			return nil, fmt.Errorf("failed to select server: %w", err)
 		}
+		g.baseURL = serverURL
 	}
 
 	// Create a map to organize requests by tag
 	requestsByTag := make(map[string][]models.HTTPRequest)
 
-	// Process each path and operation
-	for path, pathItem := range doc.Paths {
-		if pathItem.Get != nil {
-			req, err := g.GenerateRequest(ctx, path, &pathItem, "GET", pathItem.Get)
-			if err == nil {
-				tag := g.getTag(pathItem.Get)
-				requestsByTag[tag] = append(requestsByTag[tag], *req)
-			}
-		}
-		if pathItem.Post != nil {
-			req, err := g.GenerateRequest(ctx, path, &pathItem, "POST", pathItem.Post)
-			if err == nil {
-				tag := g.getTag(pathItem.Post)
-				requestsByTag[tag] = append(requestsByTag[tag], *req)
+	// Requests placed in the shared directory, listed in the files of their tags
+	referencesByTag := make(map[string][]string)
+
+	// Operations already placed, by operationId, to skip duplicates
+	placed := make(map[string]bool)
+
+	// Manifest entries of the generated requests, by name, without their file
+	described := make(map[string]models.ManifestRequest)
+
+	// Process each path and operation in path and method order, so the
+	// requests are generated in the same order on every run and the first of
+	// duplicate operations is always the one kept
+	paths := make([]string, 0, len(doc.Paths))
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		pathItem := doc.Paths[path]
+		for _, method := range models.StandardMethods {
+			operation := pathItem.Operation(method)
+			if operation == nil || !g.allowsSecurity(doc, operation) {
+				continue
 			}
-		}
-		if pathItem.Put != nil {
-			req, err := g.GenerateRequest(ctx, path, &pathItem, "PUT", pathItem.Put)
-			if err == nil {
-				tag := g.getTag(pathItem.Put)
-				requestsByTag[tag] = append(requestsByTag[tag], *req)
+			req, err := g.GenerateRequest(ctx, path, &pathItem, method, operation)
+			if errors.Is(err, ErrSkipOperation) {
+				continue
 			}
-		}
-		if pathItem.Delete != nil {
-			req, err := g.GenerateRequest(ctx, path, &pathItem, "DELETE", pathItem.Delete)
-			if err == nil {
-				tag := g.getTag(pathItem.Delete)
-				requestsByTag[tag] = append(requestsByTag[tag], *req)
+			if err != nil {
+				return nil, fmt.Errorf("failed to generate %s %s: %w", method, path, err)
 			}
-		}
-		if pathItem.Patch != nil {
-			req, err := g.GenerateRequest(ctx, path, &pathItem, "PATCH", pathItem.Patch)
-			if err == nil {
-				tag := g.getTag(pathItem.Patch)
-				requestsByTag[tag] = append(requestsByTag[tag], *req)
+			req.Scopes = models.SecurityScopes(doc.OperationSecurity(operation))
+
+			tags := g.getTags(operation)
+			if _, ok := described[req.Name]; !ok {
+				described[req.Name] = models.ManifestRequest{
+					Name:        req.Name,
+					OperationID: operation.OperationID,
+					Method:      method,
+					Path:        path,
+					Tags:        operation.Tags,
+					Security:    doc.OperationSecurity(operation),
+					Deprecated:  operation.Deprecated,
+				}
 			}
-		}
-		if pathItem.Options != nil {
-			req, err := g.GenerateRequest(ctx, path, &pathItem, "OPTIONS", pathItem.Options)
-			if err == nil {
-				tag := g.getTag(pathItem.Options)
-				requestsByTag[tag] = append(requestsByTag[tag], *req)
+			switch {
+			case g.multiTag == MultiTagDuplicate:
+				for _, tag := range tags {
+					if placed[tag+" "+req.Name] {
+						continue
+					}
+					placed[tag+" "+req.Name] = true
+					tagged := *req
+					tagged.Tag = tag
+					requestsByTag[tag] = append(requestsByTag[tag], tagged)
+				}
+			case g.multiTag == MultiTagShared && len(tags) > 1:
+				if placed[req.Name] {
+					continue
+				}
+				placed[req.Name] = true
+				req.Comments = append(req.Comments, fmt.Sprintf("Tags: %s", strings.Join(tags, ", ")))
+				requestsByTag[g.sharedDir] = append(requestsByTag[g.sharedDir], *req)
+				for _, tag := range tags {
+					referencesByTag[tag] = append(referencesByTag[tag], req.Name)
+				}
+			default:
+				if placed[req.Name] {
+					continue
+				}
+				placed[req.Name] = true
+				requestsByTag[tags[0]] = append(requestsByTag[tags[0]], *req)
 			}
 		}
-		if pathItem.Head != nil {
-			req, err := g.GenerateRequest(ctx, path, &pathItem, "HEAD", pathItem.Head)
-			if err == nil {
-				tag := g.getTag(pathItem.Head)
-				requestsByTag[tag] = append(requestsByTag[tag], *req)
-			}
+	}
+
+	// Tags whose operations are all shared still get a file listing them
+	for tag := range referencesByTag {
+		if _, ok := requestsByTag[tag]; !ok {
+			requestsByTag[tag] = nil
 		}
 	}
 
-	// Create HTTP files for each tag
-	for tag, requests := range requestsByTag {
+	// Create HTTP files for each tag, in tag order so the output is stable
+	tags := make([]string, 0, len(requestsByTag))
+	for tag := range requestsByTag {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	for _, tag := range tags {
+		requests := requestsByTag[tag]
 		directory := models.HTTPDirectory{
 			Name:  tag,
 			Path:  tag,
@@ -154,8 +303,18 @@ func (g *HTTPGenerator) Generate(ctx context.Context, doc *models.SwaggerDoc) (*
 		}
 
 		file := models.HTTPFile{
-			Filename: fmt.Sprintf("%s.http", sanitizeFilename(tag)),
-			Requests: requests,
+			Filename:    fmt.Sprintf("%s.http", sanitizeFilename(tag)),
+			Requests:    requests,
+			ToolVersion: version.Version,
+			SpecHash:    doc.SpecHash,
+		}
+		if names := referencesByTag[tag]; len(names) > 0 {
+			sharedFile := fmt.Sprintf("%s.http", sanitizeFilename(g.sharedDir))
+			file.SharedFile = filepath.ToSlash(filepath.Join("..", g.sharedDir, sharedFile))
+			if tag == g.defaultTag {
+				file.SharedFile = filepath.ToSlash(filepath.Join(g.sharedDir, sharedFile))
+			}
+			file.SharedRequests = names
 		}
 
 		// If it's the default tag, add to root files
@@ -167,9 +326,43 @@ func (g *HTTPGenerator) Generate(ctx context.Context, doc *models.SwaggerDoc) (*
 		}
 	}
 
+	if g.manifest {
+		collection.Manifest = buildManifest(collection, described, doc.SpecHash)
+	}
+
 	return collection, nil
 }
 
+// buildManifest lists the requests of a collection with their files, relative
+// to the root of the collection
+func buildManifest(collection *models.HTTPCollection, described map[string]models.ManifestRequest, specHash string) *models.CollectionManifest {
+	manifest := &models.CollectionManifest{
+		ToolVersion: version.Version,
+		SpecHash:    specHash,
+		Requests:    []models.ManifestRequest{},
+	}
+	add := func(dir string, file models.HTTPFile) {
+		for _, request := range file.Requests {
+			entry, ok := described[request.Name]
+			if !ok {
+				continue
+			}
+			entry.File = filepath.ToSlash(filepath.Join(dir, file.Filename))
+			manifest.Requests = append(manifest.Requests, entry)
+		}
+	}
+
+	for _, file := range collection.RootFiles {
+		add("", file)
+	}
+	for _, directory := range collection.Directories {
+		for _, file := range directory.Files {
+			add(directory.Path, file)
+		}
+	}
+	return manifest
+}
+
 // GenerateRequest generates an HTTP request from a path and operation
 func (g *HTTPGenerator) GenerateRequest(ctx context.Context, path string, pathItem *models.PathItem, method string, operation *models.Operation) (*models.HTTPRequest, error) {
 	if operation == nil {
@@ -184,18 +377,33 @@ func (g *HTTPGenerator) GenerateRequest(ctx context.Context, path string, pathIt
 	url := g.buildURL(path)
 	headers := g.buildHeaders(operation)
 	body := g.buildRequestBody(operation)
-	comments := g.buildComments(operation)
+	comments := append(g.buildComments(operation), g.bodyVariants(requestBodySchema(operation))...)
 	tag := g.getTag(operation)
 
 	request := &models.HTTPRequest{
-		Name:     name,
-		Method:   method,
-		URL:      url,
-		Headers:  headers,
-		Body:     body,
-		Comments: comments,
-		Tag:      tag,
-		Path:     path,
+		Name:       name,
+		Method:     method,
+		URL:        url,
+		Headers:    headers,
+		Body:       body,
+		Comments:   comments,
+		Tag:        tag,
+		Path:       path,
+		Deprecated: operation.Deprecated,
+	}
+
+	// Apply the vendor extensions of the operation, which override those of its path
+	extensions := operation.Extensions
+	if pathItem != nil {
+		extensions = pathItem.Extensions.Merge(operation.Extensions)
+	}
+	if err := g.extensions.applyOperation(extensions, request); err != nil {
+		return nil, err
+	}
+
+	// Point callback URLs at the test runner's listener
+	if err := g.applyCallbacks(operation, request); err != nil {
+		return nil, err
 	}
 
 	return request, nil
@@ -219,18 +427,15 @@ func (g *HTTPGenerator) buildURL(path string) string {
 }
 
 // buildHeaders builds the headers for a request
-func (g *HTTPGenerator) buildHeaders(operation *models.Operation) []models.HTTPHeader {
-	headers := []models.HTTPHeader{
-		{Name: "Content-Type", Value: "application/json"},
-		{Name: "Accept", Value: "application/json"},
+func (g *HTTPGenerator) buildHeaders(operation *models.Operation) map[string]string {
+	headers := map[string]string{
+		"Content-Type": "application/json",
+		"Accept":       "application/json",
 	}
 
 	// Add authentication header if enabled
 	if g.includeAuth && g.authToken != "" {
-		headers = append(headers, models.HTTPHeader{
-			Name:  g.authHeader,
-			Value: g.authToken,
-		})
+		headers[g.authHeader] = g.authToken
 	}
 
 	return headers
@@ -238,21 +443,26 @@ func (g *HTTPGenerator) buildHeaders(operation *models.Operation) []models.HTTPH
 
 // buildRequestBody builds the body for a request
 func (g *HTTPGenerator) buildRequestBody(operation *models.Operation) string {
+	return g.generateExampleFromSchema(requestBodySchema(operation))
+}
+
+// requestBodySchema returns the schema of the JSON body of an operation
+func requestBodySchema(operation *models.Operation) *models.Schema {
 	// For OpenAPI 3.0
 	if operation.RequestBody != nil && operation.RequestBody.Content != nil {
 		if mediaType, ok := operation.RequestBody.Content["application/json"]; ok && mediaType.Schema != nil {
-			return g.generateExampleFromSchema(mediaType.Schema)
+			return mediaType.Schema
 		}
 	}
 
 	// For Swagger 2.0
 	for _, param := range operation.Parameters {
 		if param.In == "body" && param.Schema != nil {
-			return g.generateExampleFromSchema(param.Schema)
+			return param.Schema
 		}
 	}
 
-	return ""
+	return nil
 }
 
 // buildComments builds comments for a request
@@ -278,6 +488,22 @@ func (g *HTTPGenerator) getTag(operation *models.Operation) string {
 	return g.defaultTag
 }
 
+// getTags gets all the tags of an operation, without duplicates
+func (g *HTTPGenerator) getTags(operation *models.Operation) []string {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, tag := range operation.Tags {
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) == 0 {
+		return []string{g.defaultTag}
+	}
+	return tags
+}
+
 // generateExampleFromSchema generates an example from a schema
 func (g *HTTPGenerator) generateExampleFromSchema(schema *models.Schema) string {
 	if schema == nil {
@@ -293,8 +519,11 @@ func (g *HTTPGenerator) generateExampleFromSchema(schema *models.Schema) string
 	}
 
 	// Generate example based on schema type
-	example := g.generateExample(schema)
-	
+	return g.marshalExample(g.generateExample(schema))
+}
+
+// marshalExample renders an example as JSON
+func (g *HTTPGenerator) marshalExample(example interface{}) string {
 	if g.indentJSON {
 		jsonBytes, err := json.MarshalIndent(example, "", "  ")
 		if err == nil {
@@ -316,11 +545,31 @@ func (g *HTTPGenerator) generateExample(schema *models.Schema) interface{} {
 		return nil
 	}
 
-	// Handle $ref
+	// Vendor extensions such as x-faker may provide the value
+	if example, ok := g.extensions.schemaExample(schema); ok {
+		return example
+	}
+
+	// Resolve references to the schemas of the document, leaving a placeholder
+	// for unknown and cyclic ones
 	if schema.Ref != "" {
-		// In a real implementation, we would resolve the reference
-		// For simplicity, we'll return a placeholder
-		return map[string]interface{}{"__ref": schema.Ref}
+		target, ok := g.resolveSchema(schema)
+		if !ok || g.resolving[schema.Ref] {
+			return map[string]interface{}{"__ref": schema.Ref}
+		}
+		g.resolving[schema.Ref] = true
+		defer delete(g.resolving, schema.Ref)
+		return g.generateExample(target)
+	}
+
+	// allOf members add up into one schema
+	if len(schema.AllOf) > 0 {
+		return g.generateExample(g.mergeAllOf(schema))
+	}
+
+	// Follow the first branch of oneOf and anyOf, skipped by required-only bodies
+	if alternatives := branches(schema); len(alternatives) > 0 && g.bodyMode != BodyModeRequiredOnly {
+		return g.branchExample(schema, alternatives[0])
 	}
 
 	switch schema.Type {
@@ -345,8 +594,15 @@ func (g *HTTPGenerator) generateObjectExample(schema *models.Schema) interface{}
 		return map[string]interface{}{}
 	}
 
+	// Properties are written in name order, as encoding/json sorts map keys
 	example := map[string]interface{}{}
 	for name, propSchema := range schema.Properties {
+		if g.bodyMode != BodyModeFull && !isRequired(schema, name) {
+			continue
+		}
+		if propSchema != nil && propSchema.ReadOnly && !g.readOnly {
+			continue
+		}
 		example[name] = g.generateExample(propSchema)
 	}
 	return example
@@ -366,8 +622,29 @@ func (g *HTTPGenerator) generateArrayExample(schema *models.Schema) interface{}
 		Items:      schema.Items.Items,
 	}
 
-	// Just return a single example item
-	return []interface{}{g.generateExample(itemSchema)}
+	// Full bodies have a single example item, others as many as required
+	count := 1
+	if g.bodyMode != BodyModeFull {
+		count = 0
+		if schema.MinItems != nil {
+			count = int(*schema.MinItems)
+		}
+	}
+	items := make([]interface{}, 0, count)
+	for i := 0; i < count; i++ {
+		items = append(items, g.generateExample(itemSchema))
+	}
+	return items
+}
+
+// isRequired reports whether a property of an object schema is required
+func isRequired(schema *models.Schema, name string) bool {
+	for _, required := range schema.Required {
+		if required == name {
+			return true
+		}
+	}
+	return false
 }
 
 // generateStringExample generates an example for a string schema
@@ -0,0 +1,51 @@
+package generator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+func BenchmarkHTTPGenerator_Generate(b *testing.B) {
+	doc := goldenDoc()
+	generator := NewHTTPGenerator(WithIndentJSON(true))
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := generator.Generate(context.Background(), doc); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkHTTPGenerator_GenerateExample(b *testing.B) {
+	address := &models.Schema{
+		Type: "object",
+		Properties: map[string]*models.Schema{
+			"street": {Type: "string"},
+			"city":   {Type: "string"},
+			"zip":    {Type: "string", Pattern: "^[0-9]{4}-[0-9]{3}$"},
+		},
+	}
+	order := &models.Schema{
+		Type:     "object",
+		Required: []string{"id", "status"},
+		Properties: map[string]*models.Schema{
+			"id":        {Type: "string", Format: "uuid"},
+			"status":    {Type: "string", Enum: []interface{}{"pending", "paid", "shipped"}},
+			"total":     {Type: "number", Format: "double"},
+			"createdAt": {Type: "string", Format: "date-time"},
+			"shipping":  address,
+			"billing":   address,
+			"tags":      {Type: "array", Items: &models.Items{Type: "string"}},
+			"quantity":  {Type: "integer", Format: "int32"},
+		},
+	}
+	generator := NewHTTPGenerator(WithIndentJSON(true))
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		generator.generateExampleFromSchema(order)
+	}
+}
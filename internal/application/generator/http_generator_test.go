@@ -0,0 +1,357 @@
+package generator
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/fs"
+	"github.com/edgardnogueira/swagger-to-http/internal/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// goldenDoc is a spec with several paths, methods, tags and schema properties,
+// so that any map iteration leaking into the output changes it
+func goldenDoc() *models.SwaggerDoc {
+	user := &models.Schema{
+		Type: "object",
+		Properties: map[string]*models.Schema{
+			"name":      {Type: "string"},
+			"email":     {Type: "string", Format: "email"},
+			"id":        {Type: "integer"},
+			"active":    {Type: "boolean"},
+			"createdAt": {Type: "string", Format: "date-time"},
+			"roles":     {Type: "array", Items: &models.Items{Type: "string"}},
+		},
+	}
+
+	return &models.SwaggerDoc{
+		Version:  "3.0.0",
+		Info:     models.Info{Title: "Golden", Version: "1.0.0"},
+		SpecHash: "sha256:golden",
+		Paths: map[string]models.PathItem{
+			"/users": {
+				Get: &models.Operation{OperationID: "listUsers", Summary: "List users", Tags: []string{"users"}},
+				Post: &models.Operation{
+					OperationID: "createUser",
+					Tags:        []string{"users", "admin"},
+					RequestBody: &models.RequestBody{Content: map[string]models.MediaType{"application/json": {Schema: user}}},
+				},
+			},
+			"/users/{id}": {
+				Get:    &models.Operation{OperationID: "getUser", Tags: []string{"users"}},
+				Put:    &models.Operation{OperationID: "updateUser", Tags: []string{"users"}, RequestBody: &models.RequestBody{Content: map[string]models.MediaType{"application/json": {Schema: user}}}},
+				Delete: &models.Operation{OperationID: "deleteUser", Tags: []string{"admin", "users"}, Deprecated: true},
+			},
+			"/orders": {
+				Get: &models.Operation{OperationID: "listOrders", Tags: []string{"orders"}},
+			},
+			"/health": {
+				Get: &models.Operation{OperationID: "health"},
+			},
+			"/status": {
+				Head:    &models.Operation{OperationID: "statusHead"},
+				Options: &models.Operation{OperationID: "statusOptions"},
+				Patch:   &models.Operation{OperationID: "statusPatch", Tags: []string{"admin"}},
+			},
+		},
+	}
+}
+
+// readTree reads every file under dir, keyed by its slash-separated relative path
+func readTree(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	files := make(map[string]string)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = string(data)
+		return nil
+	})
+	require.NoError(t, err)
+	return files
+}
+
+func TestGenerateGolden(t *testing.T) {
+	original := version.Version
+	version.Version = "golden"
+	defer func() { version.Version = original }()
+
+	for _, mode := range []string{MultiTagFirst, MultiTagDuplicate, MultiTagShared} {
+		t.Run(mode, func(t *testing.T) {
+			generator := NewHTTPGenerator(WithBaseURL("https://api.example.com"), WithMultiTag(mode))
+
+			// Map iteration order changes between calls, so generate several times
+			var outputs []map[string]string
+			for i := 0; i < 5; i++ {
+				collection, err := generator.Generate(context.Background(), goldenDoc())
+				require.NoError(t, err)
+
+				collection.RootDir = t.TempDir()
+				require.NoError(t, fs.NewFileWriter().WriteCollection(context.Background(), collection))
+				outputs = append(outputs, readTree(t, collection.RootDir))
+			}
+			for _, output := range outputs[1:] {
+				require.Equal(t, outputs[0], output)
+			}
+
+			goldenDir := filepath.Join("testdata", "golden", mode)
+			if *update {
+				require.NoError(t, os.RemoveAll(goldenDir))
+				for name, content := range outputs[0] {
+					path := filepath.Join(goldenDir, filepath.FromSlash(name))
+					require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+					require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+				}
+			}
+
+			assert.Equal(t, readTree(t, goldenDir), outputs[0], "run go test ./internal/application/generator -update to update the golden files")
+		})
+	}
+}
+
+func TestGenerateWithServer(t *testing.T) {
+	doc := goldenDoc()
+	doc.Servers = []models.Server{
+		{
+			URL:       "https://{region}.api.example.com/v1",
+			Variables: map[string]models.ServerVariable{"region": {Default: "eu", Enum: []string{"eu", "us"}}},
+		},
+		{URL: "http://localhost:8080"},
+	}
+
+	tests := []struct {
+		name     string
+		selector models.ServerSelector
+		want     string
+	}{
+		{name: "defaults", want: "https://eu.api.example.com/v1/health"},
+		{name: "variables", selector: models.ServerSelector{Variables: map[string]string{"region": "us"}}, want: "https://us.api.example.com/v1/health"},
+		{name: "index", selector: models.ServerSelector{Index: 1}, want: "http://localhost:8080/health"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collection, err := NewHTTPGenerator(WithServer(tt.selector)).Generate(context.Background(), doc)
+			require.NoError(t, err)
+			require.NotEmpty(t, collection.RootFiles)
+			assert.Equal(t, tt.want, collection.RootFiles[0].Requests[0].URL)
+		})
+	}
+
+	_, err := NewHTTPGenerator(WithServer(models.ServerSelector{Variables: map[string]string{"region": "ap"}})).Generate(context.Background(), doc)
+	assert.Error(t, err)
+}
+
+func TestGenerateBodyModes(t *testing.T) {
+	one := int64(1)
+	schema := &models.Schema{
+		Type:     "object",
+		Required: []string{"name", "tags", "pet"},
+		Properties: map[string]*models.Schema{
+			"name":  {Type: "string"},
+			"email": {Type: "string", Format: "email"},
+			"tags":  {Type: "array", MinItems: &one, Items: &models.Items{Type: "string"}},
+			"roles": {Type: "array", Items: &models.Items{Type: "string"}},
+			"pet": {
+				Type: "object",
+				OneOf: []*models.Schema{
+					{Type: "object", Required: []string{"bark"}, Properties: map[string]*models.Schema{"bark": {Type: "boolean"}}},
+					{Type: "object", Properties: map[string]*models.Schema{"meow": {Type: "boolean"}}},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		mode string
+		want string
+	}{
+		{BodyModeRequiredOnly, `{"name":"string","pet":{},"tags":["string"]}`},
+		{BodyModeMinimal, `{"name":"string","pet":{"bark":false},"tags":["string"]}`},
+		{BodyModeFull, `{"email":"user@example.com","name":"string","pet":{"bark":false},"roles":["string"],"tags":["string"]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			g := NewHTTPGenerator(WithBodyMode(tt.mode), WithIndentJSON(false))
+			assert.Equal(t, tt.want, g.generateExampleFromSchema(schema))
+		})
+	}
+}
+
+func TestGenerateComposition(t *testing.T) {
+	doc := &models.SwaggerDoc{
+		Version: "3.0.0",
+		Info:    models.Info{Title: "Pets", Version: "1.0.0"},
+		Components: &models.Components{Schemas: map[string]models.Schema{
+			"Pet": {Type: "object", Required: []string{"name"}, Properties: map[string]*models.Schema{"name": {Type: "string"}}},
+			"Cat": {AllOf: []*models.Schema{{Ref: "#/components/schemas/Pet"}, {Properties: map[string]*models.Schema{"indoor": {Type: "boolean"}}}}},
+			"Dog": {AllOf: []*models.Schema{{Ref: "#/components/schemas/Pet"}, {Properties: map[string]*models.Schema{"owner": {Ref: "#/components/schemas/Dog"}}}}},
+			"NewPet": {
+				OneOf:         []*models.Schema{{Ref: "#/components/schemas/Cat"}, {Ref: "#/components/schemas/Dog"}},
+				Discriminator: &models.Discriminator{PropertyName: "kind", Mapping: map[string]string{"dog": "#/components/schemas/Dog"}},
+			},
+		}},
+		Paths: map[string]models.PathItem{
+			"/pets": {Post: &models.Operation{
+				OperationID: "createPet",
+				RequestBody: &models.RequestBody{Content: map[string]models.MediaType{"application/json": {Schema: &models.Schema{Ref: "#/components/schemas/NewPet"}}}},
+			}},
+		},
+	}
+
+	collection, err := NewHTTPGenerator(WithBaseURL("http://localhost"), WithIndentJSON(false)).Generate(context.Background(), doc)
+	require.NoError(t, err)
+	require.NotEmpty(t, collection.RootFiles)
+	request := collection.RootFiles[0].Requests[0]
+	assert.Equal(t, `{"indoor":false,"kind":"Cat","name":"string"}`, request.Body)
+	assert.Contains(t, request.Comments, `Alternate body (Dog):`+"\n"+`{"kind":"dog","name":"string","owner":{"__ref":"#/components/schemas/Dog"}}`)
+}
+
+func TestGenerateReadOnly(t *testing.T) {
+	schema := &models.Schema{
+		Type: "object",
+		Properties: map[string]*models.Schema{
+			"id":       {Type: "integer", ReadOnly: true},
+			"password": {Type: "string", WriteOnly: true},
+		},
+	}
+
+	assert.Equal(t, `{"password":"string"}`, NewHTTPGenerator(WithIndentJSON(false)).generateExampleFromSchema(schema))
+	assert.Equal(t, `{"id":0,"password":"string"}`, NewHTTPGenerator(WithIndentJSON(false), WithReadOnly(true)).generateExampleFromSchema(schema))
+}
+
+func TestGenerateTraceOperation(t *testing.T) {
+	doc := &models.SwaggerDoc{
+		Version: "3.0.0",
+		Info:    models.Info{Title: "Debug", Version: "1.0.0"},
+		Paths: map[string]models.PathItem{
+			"/echo": {
+				Get:   &models.Operation{OperationID: "getEcho"},
+				Trace: &models.Operation{OperationID: "traceEcho"},
+			},
+		},
+	}
+
+	collection, err := NewHTTPGenerator(WithBaseURL("http://localhost")).Generate(context.Background(), doc)
+	require.NoError(t, err)
+	require.NotEmpty(t, collection.RootFiles)
+	requests := collection.RootFiles[0].Requests
+	require.Len(t, requests, 2)
+	assert.Equal(t, "GET", requests[0].Method)
+	assert.Equal(t, "TRACE", requests[1].Method)
+}
+
+// placements lists the requests of each file of a collection, keyed by the
+// path of its directory, as "name (tag)", and the shared requests it lists
+func placements(collection *models.HTTPCollection) map[string][]string {
+	placed := make(map[string][]string)
+	add := func(dir string, file models.HTTPFile) {
+		placed[dir] = []string{}
+		for _, request := range file.Requests {
+			placed[dir] = append(placed[dir], fmt.Sprintf("%s (%s)", request.Name, request.Tag))
+		}
+		for _, name := range file.SharedRequests {
+			placed[dir] = append(placed[dir], "see "+file.SharedFile+" "+name)
+		}
+	}
+	for _, file := range collection.RootFiles {
+		add("", file)
+	}
+	for _, directory := range collection.Directories {
+		for _, file := range directory.Files {
+			add(directory.Path, file)
+		}
+	}
+	return placed
+}
+
+func TestGenerateMultiTag(t *testing.T) {
+	doc := &models.SwaggerDoc{
+		Paths: map[string]models.PathItem{
+			"/pets": {
+				Get:  &models.Operation{OperationID: "listPets", Tags: []string{"pets"}},
+				Post: &models.Operation{OperationID: "addPet", Tags: []string{"pets", "admin"}},
+			},
+			"/audit": {
+				Get: &models.Operation{OperationID: "audit", Tags: []string{"admin", "audit"}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		options []HTTPGeneratorOption
+		want    map[string][]string
+	}{
+		{
+			name: "first tag by default",
+			want: map[string][]string{
+				"admin": {"audit (admin)"},
+				"pets":  {"listPets (pets)", "addPet (pets)"},
+			},
+		},
+		{
+			name:    "first",
+			options: []HTTPGeneratorOption{WithMultiTag(MultiTagFirst)},
+			want: map[string][]string{
+				"admin": {"audit (admin)"},
+				"pets":  {"listPets (pets)", "addPet (pets)"},
+			},
+		},
+		{
+			name:    "duplicate",
+			options: []HTTPGeneratorOption{WithMultiTag(MultiTagDuplicate)},
+			want: map[string][]string{
+				"admin": {"audit (admin)", "addPet (admin)"},
+				"audit": {"audit (audit)"},
+				"pets":  {"listPets (pets)", "addPet (pets)"},
+			},
+		},
+		{
+			name:    "shared",
+			options: []HTTPGeneratorOption{WithMultiTag(MultiTagShared)},
+			want: map[string][]string{
+				"admin":  {"see ../shared/shared.http audit", "see ../shared/shared.http addPet"},
+				"audit":  {"see ../shared/shared.http audit"},
+				"pets":   {"listPets (pets)", "see ../shared/shared.http addPet"},
+				"shared": {"audit (admin)", "addPet (pets)"},
+			},
+		},
+		{
+			name:    "shared in another directory",
+			options: []HTTPGeneratorOption{WithMultiTag(MultiTagShared), WithSharedDir("common")},
+			want: map[string][]string{
+				"admin":  {"see ../common/common.http audit", "see ../common/common.http addPet"},
+				"audit":  {"see ../common/common.http audit"},
+				"common": {"audit (admin)", "addPet (pets)"},
+				"pets":   {"listPets (pets)", "see ../common/common.http addPet"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collection, err := NewHTTPGenerator(tt.options...).Generate(context.Background(), doc)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, placements(collection))
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// SecurityNone matches, in security filters, the operations that can be
+// called without credentials
+const SecurityNone = "none"
+
+// WithSecurityFilter only generates the operations using one of the given
+// security schemes, named or by type such as apiKey or oauth2. Schemes
+// starting with "!" leave out the operations using them instead, and
+// SecurityNone matches the operations that can be called without credentials.
+func WithSecurityFilter(schemes []string) HTTPGeneratorOption {
+	return func(g *HTTPGenerator) {
+		g.securityFilter = schemes
+	}
+}
+
+// WithSkipUnauthenticated leaves out the operations that can be called
+// without credentials
+func WithSkipUnauthenticated(skip bool) HTTPGeneratorOption {
+	return func(g *HTTPGenerator) {
+		g.skipUnauthenticated = skip
+	}
+}
+
+// allowsSecurity reports whether the security filters keep an operation
+func (g *HTTPGenerator) allowsSecurity(doc *models.SwaggerDoc, operation *models.Operation) bool {
+	requirements := doc.OperationSecurity(operation)
+	anonymous := models.AllowsAnonymous(requirements)
+	if g.skipUnauthenticated && anonymous {
+		return false
+	}
+
+	// The names and types of the schemes the operation uses
+	used := make(map[string]bool)
+	if anonymous {
+		used[SecurityNone] = true
+	}
+	for _, name := range models.SecuritySchemeNames(requirements) {
+		used[strings.ToLower(name)] = true
+		if scheme, ok := doc.SecuritySchemeNamed(name); ok && scheme.Type != "" {
+			used[strings.ToLower(scheme.Type)] = true
+		}
+	}
+
+	included, wanted := false, false
+	for _, scheme := range g.securityFilter {
+		scheme = strings.ToLower(strings.TrimSpace(scheme))
+		if excluded := strings.TrimPrefix(scheme, "!"); excluded != scheme {
+			if used[excluded] {
+				return false
+			}
+			continue
+		}
+		if scheme == "" {
+			continue
+		}
+		wanted = true
+		included = included || used[scheme]
+	}
+	return included || !wanted
+}
@@ -0,0 +1,85 @@
+package generator
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecurityFilter(t *testing.T) {
+	doc := &models.SwaggerDoc{
+		Security: []map[string][]string{{"keyAuth": {}}},
+		Components: &models.Components{SecuritySchemes: map[string]models.SecurityScheme{
+			"keyAuth": {Type: "apiKey", Name: "X-API-Key", In: "header"},
+			"oauth":   {Type: "oauth2"},
+		}},
+	}
+	operations := map[string]*models.Operation{
+		"listUsers":   {},
+		"createOrder": {Security: []map[string][]string{{"oauth": {"write"}}}},
+		"health":      {Security: []map[string][]string{}},
+	}
+
+	tests := []struct {
+		name     string
+		options  []HTTPGeneratorOption
+		expected []string
+	}{
+		{"no filter", nil, []string{"createOrder", "health", "listUsers"}},
+		{"by type", []HTTPGeneratorOption{WithSecurityFilter([]string{"apiKey"})}, []string{"listUsers"}},
+		{"by name", []HTTPGeneratorOption{WithSecurityFilter([]string{"oauth", "keyAuth"})}, []string{"createOrder", "listUsers"}},
+		{"public only", []HTTPGeneratorOption{WithSecurityFilter([]string{SecurityNone})}, []string{"health"}},
+		{"excluded", []HTTPGeneratorOption{WithSecurityFilter([]string{"!oauth2"})}, []string{"health", "listUsers"}},
+		{"skip unauthenticated", []HTTPGeneratorOption{WithSkipUnauthenticated(true)}, []string{"createOrder", "listUsers"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := NewHTTPGenerator(tt.options...)
+
+			var kept []string
+			for name, operation := range operations {
+				if generator.allowsSecurity(doc, operation) {
+					kept = append(kept, name)
+				}
+			}
+			sort.Strings(kept)
+			assert.Equal(t, tt.expected, kept)
+		})
+	}
+}
+
+func TestGenerateScopes(t *testing.T) {
+	doc := &models.SwaggerDoc{
+		Version:  "3.0.0",
+		Info:     models.Info{Title: "Pets", Version: "1.0.0"},
+		Security: []map[string][]string{{"oauth": {"read:pets"}}},
+		Paths: map[string]models.PathItem{
+			"/pets": {
+				Get:  &models.Operation{OperationID: "listPets"},
+				Post: &models.Operation{OperationID: "createPet", Security: []map[string][]string{{"oauth": {"write:pets", "read:pets"}}}},
+			},
+			"/health": {
+				Get: &models.Operation{OperationID: "health", Security: []map[string][]string{}},
+			},
+		},
+	}
+
+	collection, err := NewHTTPGenerator(WithBaseURL("http://localhost")).Generate(context.Background(), doc)
+	require.NoError(t, err)
+	require.NotEmpty(t, collection.RootFiles)
+
+	scopes := make(map[string][]string)
+	for _, request := range collection.RootFiles[0].Requests {
+		scopes[request.Name] = request.Scopes
+	}
+	assert.Equal(t, map[string][]string{
+		"health":    nil,
+		"listPets":  {"read:pets"},
+		"createPet": {"read:pets", "write:pets"},
+	}, scopes)
+}
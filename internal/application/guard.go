@@ -0,0 +1,79 @@
+package application
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// CheckMutation returns why the guard blocks a request, or nil when it may
+// run. The request URL is resolved with the variables to find its host.
+func CheckMutation(request *models.HTTPRequest, variables map[string]string, guard models.MutationGuard) error {
+	method := strings.ToUpper(request.Method)
+	if method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions {
+		return nil
+	}
+
+	requestURL := expandVariables(request.URL, variables)
+	host := requestHost(requestURL)
+	if isLocalHost(host) {
+		return nil
+	}
+	if host == "" {
+		host = "an unresolved host"
+	}
+
+	if !guard.AllowMutations && !request.Safe {
+		return fmt.Errorf("%s request to %s blocked: pass --allow-mutations or mark the request with @safe", method, host)
+	}
+
+	if method == http.MethodDelete && !request.Safe && !hostAllowed(host, guard.DeleteAllowlist) {
+		if guard.Confirm == nil || !guard.Confirm(request, requestURL) {
+			return fmt.Errorf("DELETE request to %s blocked: add the host with --allow-delete, mark the request with @safe or confirm it", host)
+		}
+	}
+
+	return nil
+}
+
+// requestHost returns the host name of a request URL, or "" when it can't be
+// parsed, e.g. because of unresolved variables
+func requestHost(rawURL string) string {
+	if strings.Contains(rawURL, "{{") {
+		return ""
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsed.Hostname())
+}
+
+// isLocalHost reports whether a host is the local machine
+func isLocalHost(host string) bool {
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// hostAllowed reports whether a host matches one of the allowlist entries,
+// which may use shell-style wildcards
+func hostAllowed(host string, allowlist []string) bool {
+	for _, pattern := range allowlist {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern == host {
+			return true
+		}
+		if matched, err := path.Match(pattern, host); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package application
+
+import (
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckMutation(t *testing.T) {
+	confirmed := func(request *models.HTTPRequest, url string) bool { return true }
+	declined := func(request *models.HTTPRequest, url string) bool { return false }
+
+	tests := []struct {
+		name    string
+		request models.HTTPRequest
+		guard   models.MutationGuard
+		blocked bool
+	}{
+		{name: "GET on shared host", request: models.HTTPRequest{Method: "GET", URL: "https://api.example.com/users"}},
+		{name: "POST on localhost", request: models.HTTPRequest{Method: "POST", URL: "http://localhost:8080/users"}},
+		{name: "DELETE on loopback", request: models.HTTPRequest{Method: "DELETE", URL: "{{baseUrl}}/users/1"}},
+		{name: "POST on shared host", request: models.HTTPRequest{Method: "POST", URL: "https://api.example.com/users"}, blocked: true},
+		{name: "POST with unresolved host", request: models.HTTPRequest{Method: "POST", URL: "{{apiUrl}}/users"}, blocked: true},
+		{name: "POST allowed", request: models.HTTPRequest{Method: "POST", URL: "https://api.example.com/users"}, guard: models.MutationGuard{AllowMutations: true}},
+		{name: "safe POST", request: models.HTTPRequest{Method: "POST", URL: "https://api.example.com/users", Safe: true}},
+		{name: "DELETE needs confirmation", request: models.HTTPRequest{Method: "DELETE", URL: "https://api.example.com/users/1"}, guard: models.MutationGuard{AllowMutations: true}, blocked: true},
+		{name: "DELETE declined", request: models.HTTPRequest{Method: "DELETE", URL: "https://api.example.com/users/1"}, guard: models.MutationGuard{AllowMutations: true, Confirm: declined}, blocked: true},
+		{name: "DELETE confirmed", request: models.HTTPRequest{Method: "DELETE", URL: "https://api.example.com/users/1"}, guard: models.MutationGuard{AllowMutations: true, Confirm: confirmed}},
+		{name: "DELETE on allowlisted host", request: models.HTTPRequest{Method: "DELETE", URL: "https://qa.test.example.com/users/1"}, guard: models.MutationGuard{AllowMutations: true, DeleteAllowlist: []string{"*.test.example.com"}}},
+		{name: "allowlist without mutations", request: models.HTTPRequest{Method: "DELETE", URL: "https://qa.test.example.com/users/1"}, guard: models.MutationGuard{DeleteAllowlist: []string{"qa.test.example.com"}}, blocked: true},
+		{name: "safe DELETE", request: models.HTTPRequest{Method: "DELETE", URL: "https://api.example.com/users/1", Safe: true}},
+	}
+
+	variables := map[string]string{"baseUrl": "http://127.0.0.1:3000"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckMutation(&tt.request, variables, tt.guard)
+			if tt.blocked {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package harvest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Apply writes the examples into an OpenAPI 3 or Swagger 2 document in YAML or
+// JSON, keeping the order of its keys and, for YAML, its comments. Examples for
+// responses the document doesn't declare are returned as skipped.
+func Apply(document []byte, examples []Example) ([]byte, []Skipped, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(document, &root); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse document: %w", err)
+	}
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("document is not an OpenAPI object")
+	}
+	spec := root.Content[0]
+	openAPI3 := mappingValue(spec, "openapi") != nil
+
+	var skipped []Skipped
+	for _, example := range examples {
+		var err error
+		if openAPI3 {
+			err = applyOpenAPI3(spec, example)
+		} else {
+			err = applySwagger2(spec, example)
+		}
+		if err != nil {
+			skipped = append(skipped, Skipped{Name: example.Name, Reason: err.Error()})
+		}
+	}
+
+	if isJSONDocument(document) {
+		var buf bytes.Buffer
+		if err := writeJSON(&buf, spec, ""); err != nil {
+			return nil, nil, err
+		}
+		buf.WriteByte('\n')
+		return buf.Bytes(), skipped, nil
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&root); err != nil {
+		return nil, nil, fmt.Errorf("failed to encode document: %w", err)
+	}
+	encoder.Close()
+	return buf.Bytes(), skipped, nil
+}
+
+// applyOpenAPI3 adds the example to the examples of the response's media type
+func applyOpenAPI3(spec *yaml.Node, example Example) error {
+	response, err := responseNode(spec, example)
+	if err != nil {
+		return err
+	}
+
+	content := mappingValue(response, "content")
+	if content == nil || content.Kind != yaml.MappingNode {
+		return fmt.Errorf("response %s of %s %s declares no content", example.Status, example.Method, example.Path)
+	}
+
+	// Prefer the recorded media type, then any JSON media type
+	media := mappingValue(content, example.ContentType)
+	if media == nil {
+		for i := 0; i+1 < len(content.Content); i += 2 {
+			if isJSON(mediaType(content.Content[i].Value)) {
+				media = content.Content[i+1]
+				break
+			}
+		}
+	}
+	if media == nil || media.Kind != yaml.MappingNode {
+		return fmt.Errorf("response %s of %s %s declares no JSON content", example.Status, example.Method, example.Path)
+	}
+
+	value, err := bodyNode(example.Body)
+	if err != nil {
+		return err
+	}
+
+	// A single example is replaced, as it can't be combined with examples
+	if mappingValue(media, "example") != nil {
+		setMappingValue(media, "example", value)
+		return nil
+	}
+
+	entry := &yaml.Node{Kind: yaml.MappingNode}
+	setMappingValue(entry, "summary", scalarNode("Recorded response of "+example.Name))
+	setMappingValue(entry, "value", value)
+	setMappingValue(ensureMapping(media, "examples"), example.Name, entry)
+	return nil
+}
+
+// applySwagger2 sets the example of the response for its media type
+func applySwagger2(spec *yaml.Node, example Example) error {
+	response, err := responseNode(spec, example)
+	if err != nil {
+		return err
+	}
+
+	value, err := bodyNode(example.Body)
+	if err != nil {
+		return err
+	}
+
+	setMappingValue(ensureMapping(response, "examples"), example.ContentType, value)
+	return nil
+}
+
+// responseNode finds the response of the example's operation and status
+func responseNode(spec *yaml.Node, example Example) (*yaml.Node, error) {
+	operation := mappingValue(mappingValue(mappingValue(spec, "paths"), example.Path), strings.ToLower(example.Method))
+	if operation == nil {
+		return nil, fmt.Errorf("operation %s %s not found", example.Method, example.Path)
+	}
+
+	response := mappingValue(mappingValue(operation, "responses"), example.Status)
+	if response == nil || response.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("response %s of %s %s is not declared", example.Status, example.Method, example.Path)
+	}
+	if mappingValue(response, "$ref") != nil {
+		return nil, fmt.Errorf("response %s of %s %s is a reference", example.Status, example.Method, example.Path)
+	}
+	return response, nil
+}
+
+// bodyNode parses a JSON body into a node with the default YAML style
+func bodyNode(body string) (*yaml.Node, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(body), &document); err != nil || len(document.Content) == 0 {
+		return nil, fmt.Errorf("failed to parse response body")
+	}
+	value := document.Content[0]
+	resetStyle(value)
+	return value, nil
+}
+
+// resetStyle clears the flow and quoting style a JSON body was parsed with
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetStyle(child)
+	}
+}
+
+// mappingValue returns the value of key in a mapping node, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setMappingValue sets the value of key in a mapping node, appending the key
+// if it is new
+func setMappingValue(node *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content[i+1] = value
+			return
+		}
+	}
+	node.Content = append(node.Content, scalarNode(key), value)
+}
+
+// ensureMapping returns the mapping value of key, creating it if needed
+func ensureMapping(node *yaml.Node, key string) *yaml.Node {
+	if value := mappingValue(node, key); value != nil && value.Kind == yaml.MappingNode {
+		return value
+	}
+	value := &yaml.Node{Kind: yaml.MappingNode}
+	setMappingValue(node, key, value)
+	return value
+}
+
+// scalarNode creates a string node
+func scalarNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+// isJSONDocument reports whether a document is written in JSON
+func isJSONDocument(document []byte) bool {
+	trimmed := bytes.TrimSpace(document)
+	return len(trimmed) > 0 && trimmed[0] == '{'
+}
+
+// writeJSON writes a node as indented JSON, keeping the order of mapping keys
+func writeJSON(buf *bytes.Buffer, node *yaml.Node, indent string) error {
+	switch node.Kind {
+	case yaml.AliasNode:
+		return writeJSON(buf, node.Alias, indent)
+	case yaml.MappingNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("{}")
+			return nil
+		}
+		buf.WriteString("{\n")
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, _ := json.Marshal(node.Content[i].Value)
+			buf.WriteString(indent + "  ")
+			buf.Write(key)
+			buf.WriteString(": ")
+			if err := writeJSON(buf, node.Content[i+1], indent+"  "); err != nil {
+				return err
+			}
+			if i+2 < len(node.Content) {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(indent + "}")
+	case yaml.SequenceNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("[]")
+			return nil
+		}
+		buf.WriteString("[\n")
+		for i, item := range node.Content {
+			buf.WriteString(indent + "  ")
+			if err := writeJSON(buf, item, indent+"  "); err != nil {
+				return err
+			}
+			if i+1 < len(node.Content) {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(indent + "]")
+	default:
+		var value interface{}
+		if err := node.Decode(&value); err != nil {
+			return fmt.Errorf("failed to decode value at line %d: %w", node.Line, err)
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to encode value at line %d: %w", node.Line, err)
+		}
+		buf.Write(data)
+	}
+	return nil
+}
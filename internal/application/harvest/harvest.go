@@ -0,0 +1,176 @@
+// Package harvest turns recorded responses into examples of an OpenAPI
+// document, so the examples in the spec stay close to what the API returns.
+package harvest
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/application/explain"
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// DefaultExampleName names examples harvested from unnamed requests
+const DefaultExampleName = "harvested"
+
+// unsafeNameChars matches the characters replaced in example names
+var unsafeNameChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// ValidateFunc validates a response against the schema the spec declares for
+// the operation at path and method
+type ValidateFunc func(response *models.HTTPResponse, path, method string) (*models.SchemaValidationResult, error)
+
+// Example is a recorded response body to be written to the spec
+type Example struct {
+	Name        string `json:"name"`
+	Method      string `json:"method"`
+	Path        string `json:"path"`
+	Status      string `json:"status"`
+	ContentType string `json:"contentType"`
+	Body        string `json:"-"`
+}
+
+// Skipped is a recorded response that was not harvested
+type Skipped struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// Harvest holds the examples collected from recorded responses
+type Harvest struct {
+	Examples []Example `json:"examples"`
+	Skipped  []Skipped `json:"skipped,omitempty"`
+}
+
+// Harvester collects examples from recorded responses
+type Harvester struct {
+	explainer *explain.Explainer
+	validate  ValidateFunc
+}
+
+// NewHarvester creates a Harvester mapping responses to the operations of doc.
+// Responses are validated with validate, if any, before being harvested.
+func NewHarvester(doc *models.SwaggerDoc, validate ValidateFunc) *Harvester {
+	return &Harvester{
+		explainer: explain.NewExplainer(doc),
+		validate:  validate,
+	}
+}
+
+// Collect collects an example from every recorded JSON response that maps to a
+// spec operation and matches its schema. A later response for the same
+// operation, status and name replaces an earlier one.
+func (h *Harvester) Collect(results []models.TestResult) *Harvest {
+	harvest := &Harvest{Examples: []Example{}}
+	index := make(map[string]int)
+
+	for _, result := range results {
+		// Prefer the response before the snapshot transform
+		response := result.RawResponse
+		if response == nil {
+			response = result.Response
+		}
+		if result.Request == nil || response == nil {
+			continue
+		}
+
+		name := exampleName(result.Name)
+		skip := func(format string, args ...interface{}) {
+			harvest.Skipped = append(harvest.Skipped, Skipped{Name: result.Name, Reason: fmt.Sprintf(format, args...)})
+		}
+
+		contentType := mediaType(responseContentType(response))
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		if !isJSON(contentType) || !json.Valid([]byte(response.Body)) {
+			skip("response is not JSON")
+			continue
+		}
+
+		explanation := h.explainer.ExplainRequest(*result.Request, nil, nil)
+		if explanation.Operation == nil {
+			skip("no operation in the spec matches %s %s", explanation.Method, result.Request.URL)
+			continue
+		}
+		operation := explanation.Operation
+
+		if h.validate != nil {
+			validation, err := h.validate(response, operation.Path, operation.Method)
+			if err != nil {
+				skip("failed to validate response: %v", err)
+				continue
+			}
+			if !validation.Valid {
+				skip("response doesn't match the schema: %s", validationErrors(validation))
+				continue
+			}
+		}
+
+		example := Example{
+			Name:        name,
+			Method:      operation.Method,
+			Path:        operation.Path,
+			Status:      strconv.Itoa(response.StatusCode),
+			ContentType: contentType,
+			Body:        response.Body,
+		}
+		key := strings.Join([]string{example.Method, example.Path, example.Status, example.Name}, " ")
+		if i, ok := index[key]; ok {
+			harvest.Examples[i] = example
+			continue
+		}
+		index[key] = len(harvest.Examples)
+		harvest.Examples = append(harvest.Examples, example)
+	}
+
+	return harvest
+}
+
+// exampleName returns a name usable as an example key for a test name
+func exampleName(name string) string {
+	name = strings.Trim(unsafeNameChars.ReplaceAllString(name, "-"), "-")
+	if name == "" {
+		return DefaultExampleName
+	}
+	return name
+}
+
+// responseContentType returns the content type of a response
+func responseContentType(response *models.HTTPResponse) string {
+	if response.ContentType != "" {
+		return response.ContentType
+	}
+	for name, values := range response.Headers {
+		if strings.EqualFold(name, "Content-Type") && len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+// mediaType strips the parameters of a content type, such as its charset
+func mediaType(contentType string) string {
+	return strings.TrimSpace(strings.ToLower(strings.SplitN(contentType, ";", 2)[0]))
+}
+
+// isJSON reports whether a media type is JSON
+func isJSON(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// validationErrors joins the messages of a failed validation
+func validationErrors(result *models.SchemaValidationResult) string {
+	var messages []string
+	for _, err := range result.Errors {
+		if err.Path != "" {
+			messages = append(messages, err.Path+": "+err.Message)
+		} else {
+			messages = append(messages, err.Message)
+		}
+	}
+	return strings.Join(messages, "; ")
+}
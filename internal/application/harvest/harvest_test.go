@@ -0,0 +1,168 @@
+package harvest
+
+import (
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollect(t *testing.T) {
+	doc := &models.SwaggerDoc{
+		Paths: map[string]models.PathItem{
+			"/users/{id}": {Get: &models.Operation{OperationID: "getUser"}},
+		},
+	}
+	validate := func(response *models.HTTPResponse, path, method string) (*models.SchemaValidationResult, error) {
+		if response.StatusCode == 500 {
+			return &models.SchemaValidationResult{Errors: []models.ValidationError{{Path: "$.id", Message: "required"}}}, nil
+		}
+		return &models.SchemaValidationResult{Valid: true}, nil
+	}
+
+	results := []models.TestResult{
+		{
+			Name:     "get user",
+			Request:  &models.HTTPRequest{Method: "GET", URL: "https://example.com/users/1"},
+			Response: &models.HTTPResponse{StatusCode: 200, ContentType: "application/json; charset=utf-8", Body: `{"id": 1}`},
+		},
+		{
+			Name:     "get user",
+			Request:  &models.HTTPRequest{Method: "GET", URL: "https://example.com/users/2"},
+			Response: &models.HTTPResponse{StatusCode: 200, Body: `{"id": 2}`},
+		},
+		{
+			Name:     "broken",
+			Request:  &models.HTTPRequest{Method: "GET", URL: "https://example.com/users/3"},
+			Response: &models.HTTPResponse{StatusCode: 500, Body: `{}`},
+		},
+		{
+			Name:     "html",
+			Request:  &models.HTTPRequest{Method: "GET", URL: "https://example.com/users/4"},
+			Response: &models.HTTPResponse{StatusCode: 200, ContentType: "text/html", Body: "<p></p>"},
+		},
+		{
+			Name:     "orders",
+			Request:  &models.HTTPRequest{Method: "GET", URL: "https://example.com/orders"},
+			Response: &models.HTTPResponse{StatusCode: 200, Body: `[]`},
+		},
+	}
+
+	harvest := NewHarvester(doc, validate).Collect(results)
+
+	assert.Equal(t, []Example{{
+		Name:        "get-user",
+		Method:      "GET",
+		Path:        "/users/{id}",
+		Status:      "200",
+		ContentType: "application/json",
+		Body:        `{"id": 2}`,
+	}}, harvest.Examples)
+	assert.Equal(t, []Skipped{
+		{Name: "broken", Reason: "response doesn't match the schema: $.id: required"},
+		{Name: "html", Reason: "response is not JSON"},
+		{Name: "orders", Reason: "no operation in the spec matches GET https://example.com/orders"},
+	}, harvest.Skipped)
+}
+
+func TestApplyOpenAPI3YAML(t *testing.T) {
+	document := `openapi: 3.0.0
+info:
+  title: Users # keep this comment
+  version: "1"
+paths:
+  /users/{id}:
+    get:
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: object
+        "404":
+          $ref: '#/components/responses/NotFound'
+`
+	examples := []Example{
+		{Name: "get-user", Method: "GET", Path: "/users/{id}", Status: "200", ContentType: "application/json", Body: `{"id": 1, "name": "Ada", "code": "007"}`},
+		{Name: "missing", Method: "GET", Path: "/users/{id}", Status: "404", ContentType: "application/json", Body: `{}`},
+		{Name: "created", Method: "POST", Path: "/users/{id}", Status: "201", ContentType: "application/json", Body: `{}`},
+	}
+
+	output, skipped, err := Apply([]byte(document), examples)
+	require.NoError(t, err)
+
+	assert.Equal(t, `openapi: 3.0.0
+info:
+  title: Users # keep this comment
+  version: "1"
+paths:
+  /users/{id}:
+    get:
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: object
+              examples:
+                get-user:
+                  summary: Recorded response of get-user
+                  value:
+                    id: 1
+                    name: Ada
+                    code: "007"
+        "404":
+          $ref: '#/components/responses/NotFound'
+`, string(output))
+	assert.Equal(t, []Skipped{
+		{Name: "missing", Reason: "response 404 of GET /users/{id} is a reference"},
+		{Name: "created", Reason: "operation POST /users/{id} not found"},
+	}, skipped)
+}
+
+func TestApplySwagger2JSON(t *testing.T) {
+	document := `{
+  "swagger": "2.0",
+  "paths": {
+    "/users": {
+      "get": {
+        "responses": {
+          "200": {"description": "OK"}
+        }
+      }
+    }
+  }
+}`
+	examples := []Example{
+		{Name: "list", Method: "GET", Path: "/users", Status: "200", ContentType: "application/json", Body: `[{"id": 1}]`},
+	}
+
+	output, skipped, err := Apply([]byte(document), examples)
+	require.NoError(t, err)
+	assert.Empty(t, skipped)
+	assert.Equal(t, `{
+  "swagger": "2.0",
+  "paths": {
+    "/users": {
+      "get": {
+        "responses": {
+          "200": {
+            "description": "OK",
+            "examples": {
+              "application/json": [
+                {
+                  "id": 1
+                }
+              ]
+            }
+          }
+        }
+      }
+    }
+  }
+}
+`, string(output))
+}
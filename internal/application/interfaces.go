@@ -49,6 +49,13 @@ type HTTPExecutor interface {
 	ExecuteFile(ctx context.Context, file *models.HTTPFile, variables map[string]string) ([]*models.HTTPResponse, error)
 }
 
+// VariableResolver defines the interface for resolving variables scoped to an HTTP file
+type VariableResolver interface {
+	// ResolveVariables returns the variables that apply to requests in the given file,
+	// decrypting encrypted variable files with the key from the options
+	ResolveVariables(ctx context.Context, filePath string, options models.TestRunOptions) (map[string]string, error)
+}
+
 // SnapshotManager defines the interface for managing response snapshots
 type SnapshotManager interface {
 	// SaveSnapshot saves a response as a snapshot
@@ -77,6 +84,9 @@ type ConfigProvider interface {
 	
 	// GetStringSlice retrieves a string slice configuration value
 	GetStringSlice(key string) []string
+	
+	// Set overrides a configuration value, e.g. with a command line flag
+	Set(key string, value interface{})
 }
 
 // TestRunner defines the interface for running tests
@@ -94,6 +104,12 @@ type TestRunner interface {
 	FindTests(ctx context.Context, patterns []string, filter models.TestFilter) ([]*models.HTTPFile, error)
 }
 
+// EnvironmentComparer defines the interface for comparing two environments
+type EnvironmentComparer interface {
+	// CompareEnvironments runs tests against two environments and reports where their responses differ
+	CompareEnvironments(ctx context.Context, patterns []string, envA, envB models.Environment, options models.TestRunOptions) (*models.TestReport, error)
+}
+
 // TestReporter defines the interface for generating test reports
 type TestReporter interface {
 	// GenerateReport generates a report in the specified format
@@ -0,0 +1,297 @@
+// Package lint checks .http files for mistakes that parse but are likely to
+// fail or mislead when the requests run: duplicate and missing names,
+// variables that won't resolve, bodies without a Content-Type and URLs
+// without a scheme, along with the syntax errors of the parser.
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// Severities of diagnostics. A rule set to SeverityOff isn't reported.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+	SeverityOff     = "off"
+)
+
+// Rules checked by the linter
+const (
+	// RuleSyntax reports lines the parser can't read
+	RuleSyntax = "syntax"
+	// RuleInvalidHeader reports header lines that aren't "Name: value"
+	RuleInvalidHeader = "invalid-header"
+	// RuleDuplicateName reports requests named like an earlier request
+	RuleDuplicateName = "duplicate-name"
+	// RuleMissingName reports requests without "@name"
+	RuleMissingName = "missing-name"
+	// RuleUnresolvedVariable reports variables without a value and text
+	// that looks like a variable but isn't one, such as "{id}"
+	RuleUnresolvedVariable = "unresolved-variable"
+	// RuleMissingContentType reports requests with a body and no Content-Type
+	RuleMissingContentType = "missing-content-type"
+	// RuleMissingScheme reports URLs that don't start with a scheme such as
+	// "https://" and have no @host to be resolved against
+	RuleMissingScheme = "missing-scheme"
+)
+
+// DefaultSeverities are the severities of the rules unless configured
+var DefaultSeverities = map[string]string{
+	RuleSyntax:             SeverityError,
+	RuleInvalidHeader:      SeverityError,
+	RuleDuplicateName:      SeverityError,
+	RuleMissingName:        SeverityInfo,
+	RuleUnresolvedVariable: SeverityError,
+	RuleMissingContentType: SeverityWarning,
+	RuleMissingScheme:      SeverityWarning,
+}
+
+// placeholderPattern matches path parameters written as in the spec, such as
+// /users/{id}, once the variable references are removed
+var placeholderPattern = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_.-]*)\}`)
+
+// schemePattern matches the scheme of an absolute URL
+var schemePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9+.-]*://`)
+
+// Diagnostic is a problem found in a .http file
+type Diagnostic struct {
+	File            string `json:"file"`
+	models.Position `json:"position"`
+	Rule            string `json:"rule"`
+	Severity        string `json:"severity"`
+	Message         string `json:"message"`
+}
+
+// String formats the diagnostic as "file:line:column: severity: message (rule)"
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s:%s: %s: %s (%s)", d.File, d.Position, d.Severity, d.Message, d.Rule)
+}
+
+// File is a .http file to lint: its requests, or the errors it failed to
+// parse with, and the variables its requests can use
+type File struct {
+	Path        string
+	Requests    []models.HTTPRequest
+	ParseErrors models.ParseErrors
+	Variables   map[string]string
+}
+
+// Linter checks .http files with the severities configured for its rules
+type Linter struct {
+	severities map[string]string
+}
+
+// Option configures a Linter
+type Option func(*Linter)
+
+// WithSeverities overrides the severities of rules, see ParseSeverities
+func WithSeverities(severities map[string]string) Option {
+	return func(l *Linter) {
+		for rule, severity := range severities {
+			l.severities[rule] = severity
+		}
+	}
+}
+
+// NewLinter creates a Linter with the default severities
+func NewLinter(options ...Option) *Linter {
+	l := &Linter{severities: make(map[string]string, len(DefaultSeverities))}
+	for rule, severity := range DefaultSeverities {
+		l.severities[rule] = severity
+	}
+	for _, option := range options {
+		option(l)
+	}
+	return l
+}
+
+// ParseSeverities parses severities of rules written as "rule=severity",
+// e.g. "missing-name=off"
+func ParseSeverities(values []string) (map[string]string, error) {
+	severities := make(map[string]string, len(values))
+	for _, value := range values {
+		rule, severity, ok := strings.Cut(value, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid severity %q: expected rule=severity", value)
+		}
+		rule, severity = strings.TrimSpace(rule), strings.ToLower(strings.TrimSpace(severity))
+		if err := ValidateSeverity(rule, severity); err != nil {
+			return nil, err
+		}
+		severities[rule] = severity
+	}
+	return severities, nil
+}
+
+// ValidateSeverity checks that a rule exists and a severity is one of error,
+// warning, info and off
+func ValidateSeverity(rule, severity string) error {
+	if _, ok := DefaultSeverities[rule]; !ok {
+		return fmt.Errorf("unknown lint rule %q", rule)
+	}
+	switch severity {
+	case SeverityError, SeverityWarning, SeverityInfo, SeverityOff:
+		return nil
+	}
+	return fmt.Errorf("invalid severity %q of lint rule %s: expected error, warning, info or off", severity, rule)
+}
+
+// Lint checks files and returns their diagnostics, file by file in the order
+// of their lines. Request names must be unique across all the files.
+func (l *Linter) Lint(files []File) []Diagnostic {
+	var diagnostics []Diagnostic
+	names := make(map[string]string)
+	for _, file := range files {
+		start := len(diagnostics)
+		for _, err := range file.ParseErrors {
+			rule := RuleSyntax
+			if strings.HasPrefix(err.Message, "malformed header") {
+				rule = RuleInvalidHeader
+			}
+			diagnostics = l.add(diagnostics, file.Path, err.Position, rule, "%s", err.Message)
+		}
+
+		// Requests may reference the responses of named requests before them
+		earlier := make(map[string]bool)
+		for _, request := range file.Requests {
+			at := models.Position{Line: request.Line, Column: 1}
+			label := strings.ToUpper(request.Method) + " " + request.URL
+
+			if request.DerivedName || request.Name == "" {
+				diagnostics = l.add(diagnostics, file.Path, at, RuleMissingName, "request %s has no @name", label)
+			}
+			if first, ok := names[request.Name]; ok && request.Name != "" {
+				diagnostics = l.add(diagnostics, file.Path, at, RuleDuplicateName, "request name %q is already used at %s", request.Name, first)
+			} else if request.Name != "" {
+				names[request.Name] = fmt.Sprintf("%s:%d", file.Path, request.Line)
+			}
+
+			diagnostics = l.lintVariables(diagnostics, file, request, at, earlier)
+
+			if (strings.TrimSpace(request.Body) != "" || len(request.BodyIncludes) > 0) && !models.HasHeader(request.Headers, "Content-Type") {
+				diagnostics = l.add(diagnostics, file.Path, at, RuleMissingContentType, "request %s has a body but no Content-Type header", label)
+			}
+
+			if url := schemeURL(request, file.Variables); url != "" && !schemePattern.MatchString(url) {
+				diagnostics = l.add(diagnostics, file.Path, at, RuleMissingScheme, "URL %q has no scheme such as https:// and no @host to resolve it against", url)
+			}
+
+			if request.Name != "" {
+				earlier[request.Name] = true
+			}
+		}
+
+		added := diagnostics[start:]
+		sort.SliceStable(added, func(i, j int) bool {
+			if added[i].Line != added[j].Line {
+				return added[i].Line < added[j].Line
+			}
+			return added[i].Column < added[j].Column
+		})
+	}
+	return diagnostics
+}
+
+// lintVariables checks the variable references of a request and the text
+// that looks like a variable without being one. Namespaced references such
+// as {{env.HOST}} resolve through the file's variables, see models.VariableSet.
+func (l *Linter) lintVariables(diagnostics []Diagnostic, file File, request models.HTTPRequest, at models.Position, earlier map[string]bool) []Diagnostic {
+	seen := make(map[string]bool)
+	for _, text := range models.VariableTexts(&request) {
+		if unclosed := unclosedVariable(text); unclosed != "" {
+			diagnostics = l.add(diagnostics, file.Path, at, RuleUnresolvedVariable, "variable reference %q is not closed with }}", unclosed)
+		}
+		for _, reference := range models.FindVariableReferences(text) {
+			name := reference.Name
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+
+			response, isResponse := models.ResponseReference(name)
+			switch {
+			case name == "":
+				diagnostics = l.add(diagnostics, file.Path, at, RuleUnresolvedVariable, "empty variable reference %q", reference.Match)
+			case isResponse:
+				if !earlier[response] {
+					diagnostics = l.add(diagnostics, file.Path, at, RuleUnresolvedVariable, "%q references the response of %q, which is not a named request earlier in the file", name, response)
+				}
+			case models.IsPlainVariable(name):
+				if _, ok := file.Variables[name]; !ok {
+					diagnostics = l.add(diagnostics, file.Path, at, RuleUnresolvedVariable, "variable %q is not defined", name)
+				}
+			}
+		}
+	}
+
+	// Path parameters left as in the spec are sent as they are
+	urlText := request.URL
+	for _, reference := range models.FindVariableReferences(urlText) {
+		urlText = strings.Replace(urlText, reference.Match, "", 1)
+	}
+	for _, match := range placeholderPattern.FindAllStringSubmatch(urlText, -1) {
+		diagnostics = l.add(diagnostics, file.Path, at, RuleUnresolvedVariable, "{%s} in the URL is not a variable, write {{%s}}", match[1], match[1])
+	}
+	return diagnostics
+}
+
+// add records a diagnostic with the severity of its rule, unless it is off
+func (l *Linter) add(diagnostics []Diagnostic, file string, at models.Position, rule, format string, args ...interface{}) []Diagnostic {
+	severity := l.severities[rule]
+	if severity == SeverityOff {
+		return diagnostics
+	}
+	return append(diagnostics, Diagnostic{
+		File:     file,
+		Position: at,
+		Rule:     rule,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// schemeURL returns the URL of a request resolved against its @host with the
+// variables replaced, or "" when it can't be checked for a scheme: gRPC
+// requests and URLs starting with a variable without a value
+func schemeURL(request models.HTTPRequest, variables map[string]string) string {
+	if strings.EqualFold(request.Method, models.MethodGRPC) {
+		return ""
+	}
+	url := request.URL
+	if request.Host != "" && !schemePattern.MatchString(url) {
+		url = strings.TrimRight(request.Host, "/") + "/" + strings.TrimLeft(url, "/")
+	}
+	url = models.ReplaceVariables(url, variables)
+	if strings.HasPrefix(url, "{{") {
+		return ""
+	}
+	return url
+}
+
+// unclosedVariable returns the first "{{" of a text that isn't closed with
+// "}}" before the next one or the end of its line, from "{{" to the end of
+// that line, or "" when all are closed
+func unclosedVariable(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		for rest := line; ; {
+			open := strings.Index(rest, "{{")
+			if open < 0 {
+				break
+			}
+			rest = rest[open:]
+			end := strings.Index(rest[2:], "}}")
+			next := strings.Index(rest[2:], "{{")
+			if end < 0 || (next >= 0 && next < end) {
+				return rest
+			}
+			rest = rest[end+4:]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,136 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testFiles() []File {
+	return []File{
+		{
+			Path:      "users.http",
+			Variables: map[string]string{"baseUrl": "https://api.example.com", "token": "secret"},
+			Requests: []models.HTTPRequest{
+				{
+					Name:    "createUser",
+					Line:    3,
+					Method:  "POST",
+					URL:     "{{baseUrl}}/users",
+					Headers: map[string]string{"Authorization": "Bearer {{token}}"},
+					Body:    `{"name": "{{userName}}", "address": {"city": "Lisbon"}}`,
+				},
+				{
+					Name:        "GET /users/{id}",
+					DerivedName: true,
+					Line:        9,
+					Method:      "GET",
+					URL:         "{{baseUrl}}/users/{id}?tenant={{tenant",
+				},
+				{
+					Name:   "getUser",
+					Line:   12,
+					Method: "GET",
+					URL:    "/users/{{createUser.response.body.$.id}}",
+					Host:   "https://api.example.com",
+				},
+			},
+		},
+		{
+			Path: "orders.http",
+			Requests: []models.HTTPRequest{
+				{
+					Name:    "createUser",
+					Line:    1,
+					Method:  "POST",
+					URL:     "api.example.com/orders",
+					Headers: map[string]string{"content-type": "application/json"},
+					Body:    "{}",
+				},
+				{
+					Name:   "getOrder",
+					Line:   6,
+					Method: "GET",
+					URL:    "https://api.example.com/orders/{{getUser.response.body.$.order}}",
+				},
+			},
+		},
+		{
+			Path: "broken.http",
+			ParseErrors: models.ParseErrors{
+				{File: "broken.http", Position: models.Position{Line: 4, Column: 3}, Message: `invalid @priority directive "first": expected a number from 1`},
+				{File: "broken.http", Position: models.Position{Line: 2, Column: 1}, Message: `malformed header "X Trace": expected "Name: value", or a blank line before the body`},
+			},
+		},
+	}
+}
+
+func diagnosticStrings(diagnostics []Diagnostic) []string {
+	var result []string
+	for _, diagnostic := range diagnostics {
+		result = append(result, diagnostic.String())
+	}
+	return result
+}
+
+func TestLinter_Lint(t *testing.T) {
+	diagnostics := NewLinter().Lint(testFiles())
+
+	assert.Equal(t, []string{
+		`users.http:3:1: error: variable "userName" is not defined (unresolved-variable)`,
+		`users.http:3:1: warning: request POST {{baseUrl}}/users has a body but no Content-Type header (missing-content-type)`,
+		`users.http:9:1: info: request GET {{baseUrl}}/users/{id}?tenant={{tenant has no @name (missing-name)`,
+		`users.http:9:1: error: variable reference "{{tenant" is not closed with }} (unresolved-variable)`,
+		`users.http:9:1: error: {id} in the URL is not a variable, write {{id}} (unresolved-variable)`,
+		`orders.http:1:1: error: request name "createUser" is already used at users.http:3 (duplicate-name)`,
+		`orders.http:1:1: warning: URL "api.example.com/orders" has no scheme such as https:// and no @host to resolve it against (missing-scheme)`,
+		`orders.http:6:1: error: "getUser.response.body.$.order" references the response of "getUser", which is not a named request earlier in the file (unresolved-variable)`,
+		`broken.http:2:1: error: malformed header "X Trace": expected "Name: value", or a blank line before the body (invalid-header)`,
+		`broken.http:4:3: error: invalid @priority directive "first": expected a number from 1 (syntax)`,
+	}, diagnosticStrings(diagnostics))
+}
+
+func TestLinter_Severities(t *testing.T) {
+	severities, err := ParseSeverities([]string{"missing-name=off", "missing-scheme = Error", "unresolved-variable=warning"})
+	require.NoError(t, err)
+
+	diagnostics := NewLinter(WithSeverities(severities)).Lint(testFiles()[1:2])
+	assert.Equal(t, []string{
+		`orders.http:1:1: error: URL "api.example.com/orders" has no scheme such as https:// and no @host to resolve it against (missing-scheme)`,
+		`orders.http:6:1: warning: "getUser.response.body.$.order" references the response of "getUser", which is not a named request earlier in the file (unresolved-variable)`,
+	}, diagnosticStrings(diagnostics))
+
+	_, err = ParseSeverities([]string{"missing-names=off"})
+	assert.EqualError(t, err, `unknown lint rule "missing-names"`)
+	_, err = ParseSeverities([]string{"missing-name=fatal"})
+	assert.EqualError(t, err, `invalid severity "fatal" of lint rule missing-name: expected error, warning, info or off`)
+	_, err = ParseSeverities([]string{"missing-name"})
+	assert.EqualError(t, err, `invalid severity "missing-name": expected rule=severity`)
+}
+
+func TestLinter_TemplateHelpersAndNamespaces(t *testing.T) {
+	var variables models.VariableSet
+	variables.Set(models.VariableNamespaceEnv, map[string]string{"HOST": "https://api.example.com"})
+	variables.Set(models.VariableNamespaceFile, map[string]string{"items": "[]"})
+
+	files := []File{{
+		Path:      "items.http",
+		Variables: variables.Variables(),
+		Requests: []models.HTTPRequest{{
+			Name:    "createItems",
+			Line:    1,
+			Method:  "POST",
+			URL:     "{{env.HOST}}/items?by={{ env.USER }}",
+			Headers: map[string]string{"Content-Type": "application/json", "X-Trace": "{{file.TRACE}}"},
+			Body:    `[{{#each items}}{"id": {{this.id}}, "index": {{@index}}}{{#if @last}}{{else}},{{/if}}{{/each}}{{ }}]`,
+		}},
+	}}
+
+	assert.Equal(t, []string{
+		`items.http:1:1: error: variable "env.USER" is not defined (unresolved-variable)`,
+		`items.http:1:1: error: variable "file.TRACE" is not defined (unresolved-variable)`,
+		`items.http:1:1: error: empty variable reference "{{ }}" (unresolved-variable)`,
+	}, diagnosticStrings(NewLinter().Lint(files)))
+}
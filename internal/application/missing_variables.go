@@ -0,0 +1,97 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// promptedVariables holds the values typed in for missing variables, so each
+// one is asked for once per run; prompts of parallel tests are asked one at
+// a time
+type promptedVariables struct {
+	mu     sync.Mutex
+	values map[string]string
+}
+
+// fill returns the variables with the missing ones of the request asked for,
+// or as they are when nothing is missing or there is no prompt
+func (p *promptedVariables) fill(ctx context.Context, request *models.HTTPRequest, variables map[string]string, prompt func(name string, secret bool) (string, error)) (map[string]string, error) {
+	missing := models.MissingVariables(request, variables)
+	if len(missing) == 0 || prompt == nil {
+		return variables, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.values == nil {
+		p.values = make(map[string]string)
+	}
+
+	filled := make(map[string]string, len(variables)+len(missing))
+	for k, v := range variables {
+		filled[k] = v
+	}
+	tracker := models.VariableTrackerFromContext(ctx)
+	for _, name := range missing {
+		value, ok := p.values[name]
+		if !ok {
+			secret := models.IsSensitiveName(name)
+			var err error
+			value, err = prompt(name, secret)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read variable %s: %w", name, err)
+			}
+			p.values[name] = value
+
+			recorded := value
+			if secret {
+				recorded = models.MaskedValue
+			}
+			tracker.Record(request.Path, name, models.VariableOrigin{Source: models.VariableSourcePrompt, Value: recorded})
+		}
+		filled[name] = value
+	}
+	return filled, nil
+}
+
+// checkMissingVariables fails when requests use variables that have no value,
+// listing them with the requests using them, so runs without input stop
+// before sending anything. Runs with data rows are checked with the first row.
+func (s *TestRunnerService) checkMissingVariables(ctx context.Context, files []*models.HTTPFile, options models.TestRunOptions) error {
+	if options.DataRow == nil && len(options.DataRows) > 0 {
+		options.DataRow = &options.DataRows[0]
+	}
+
+	users := make(map[string][]string)
+	for _, file := range files {
+		for i := range file.Requests {
+			request := &file.Requests[i]
+			variables, err := s.requestVariables(ctx, request, options)
+			if err != nil {
+				return err
+			}
+			for _, name := range models.MissingVariables(request, variables) {
+				users[name] = append(users[name], request.Name)
+			}
+		}
+	}
+	if len(users) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(users))
+	for name := range users {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	missing := make([]string, len(names))
+	for i, name := range names {
+		missing[i] = fmt.Sprintf("%s (used by %s)", name, strings.Join(users[name], ", "))
+	}
+	return fmt.Errorf("missing variables, set them in an environment or variable file: %s", strings.Join(missing, "; "))
+}
@@ -0,0 +1,40 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromptedVariablesFill(t *testing.T) {
+	var asked []string
+	prompt := func(name string, secret bool) (string, error) {
+		asked = append(asked, name)
+		if secret {
+			return "s3cret", nil
+		}
+		return "acme", nil
+	}
+
+	var prompted promptedVariables
+	request := &models.HTTPRequest{URL: "{{baseUrl}}/tenants/{{TENANT}}", Body: `{"password": "{{PASSWORD}}"}`}
+	variables := map[string]string{"baseUrl": "https://api.example.com"}
+
+	filled, err := prompted.fill(context.Background(), request, variables, prompt)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"baseUrl": "https://api.example.com", "TENANT": "acme", "PASSWORD": "s3cret"}, filled)
+	assert.NotContains(t, variables, "TENANT")
+
+	// Each variable is asked for once per run
+	_, err = prompted.fill(context.Background(), request, variables, prompt)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"PASSWORD", "TENANT"}, asked)
+
+	// Without a prompt, the variables are left as they are
+	filled, err = (&promptedVariables{}).fill(context.Background(), request, variables, nil)
+	require.NoError(t, err)
+	assert.Equal(t, variables, filled)
+}
@@ -0,0 +1,228 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// linkNextPattern matches the rel="next" entry of a Link header
+var linkNextPattern = regexp.MustCompile(`<([^>]*)>[^,]*;\s*rel="?([^",]*\bnext\b[^",]*)"?`)
+
+// withPageSize returns the request with the page size of its pagination set
+// in the query string
+func withPageSize(request *models.HTTPRequest) *models.HTTPRequest {
+	if request.Paginate == nil || request.Paginate.SizeParam == "" {
+		return request
+	}
+	sized := *request
+	sized.URL = setQueryParam(request.URL, request.Paginate.SizeParam, strconv.Itoa(request.Paginate.Size))
+	if sized.QueryParams != nil {
+		sized.QueryParams = models.CloneQueryParams(request.QueryParams)
+		sized.QueryParams[request.Paginate.SizeParam] = []string{strconv.Itoa(request.Paginate.Size)}
+	}
+	return &sized
+}
+
+// followPages fetches the pages following the first response of a paginated
+// request until the last page or a safety limit, returning the aggregated pages
+// and an assertion result per page
+func (s *TestRunnerService) followPages(ctx context.Context, request *models.HTTPRequest, first *models.HTTPResponse, variables map[string]string) (*models.PaginationResult, []models.TestAssertionResult) {
+	pagination := *request.Paginate
+	result := &models.PaginationResult{}
+	var assertions []models.TestAssertionResult
+
+	requestURL := expandVariables(request.URL, variables)
+	pageURL := requestURL
+	response := first
+
+	for {
+		result.Pages++
+		assertion := models.TestAssertionResult{
+			Type:        "pagination",
+			Source:      fmt.Sprintf("page %d", result.Pages),
+			Expected:    "2xx",
+			Actual:      strconv.Itoa(response.StatusCode),
+			Description: fmt.Sprintf("page %d is fetched", result.Pages),
+		}
+
+		items, err := pageItems(response, pagination.ItemsField)
+		switch {
+		case response.StatusCode < 200 || response.StatusCode > 299:
+			assertion.Error = fmt.Sprintf("page %d returned status %d", result.Pages, response.StatusCode)
+		case err != nil:
+			assertion.Error = fmt.Sprintf("page %d: %v", result.Pages, err)
+		default:
+			assertion.Passed = true
+		}
+		assertions = append(assertions, assertion)
+		if !assertion.Passed {
+			return result, assertions
+		}
+		result.Items += items
+
+		next := nextPage(pagination, response, requestURL, pageURL, items)
+		if next == "" {
+			return result, assertions
+		}
+		if result.Items >= pagination.MaxItemsOrDefault() {
+			result.Truncated = fmt.Sprintf("max-items=%d", pagination.MaxItemsOrDefault())
+			return result, assertions
+		}
+		if result.Pages >= pagination.MaxPagesOrDefault() {
+			result.Truncated = fmt.Sprintf("max-pages=%d", pagination.MaxPagesOrDefault())
+			return result, assertions
+		}
+
+		page := *request
+		page.Method = http.MethodGet
+		page.URL = next
+		page.QueryParams = nil
+		page.Body = ""
+		pageURL = next
+
+		response, err = s.httpExecutor.Execute(ctx, &page, variables)
+		if err != nil {
+			assertions = append(assertions, models.TestAssertionResult{
+				Type:        "pagination",
+				Source:      fmt.Sprintf("page %d", result.Pages+1),
+				Expected:    "2xx",
+				Description: fmt.Sprintf("page %d is fetched", result.Pages+1),
+				Error:       err.Error(),
+			})
+			return result, assertions
+		}
+		result.Responses = append(result.Responses, response)
+	}
+}
+
+// nextPage returns the URL of the page after the current one, or "" on the last page
+func nextPage(pagination models.Pagination, response *models.HTTPResponse, requestURL, pageURL string, items int) string {
+	switch pagination.Mode {
+	case models.PaginateLink:
+		for _, link := range http.Header(response.Headers).Values("Link") {
+			if matches := linkNextPattern.FindStringSubmatch(link); matches != nil {
+				return resolveURL(pageURL, matches[1])
+			}
+		}
+	case models.PaginateNext:
+		next, err := models.ExtractJSONPath(string(response.Body), pagination.NextField)
+		if err != nil || next == "" || next == "null" {
+			return ""
+		}
+		if strings.Contains(next, "://") || strings.HasPrefix(next, "/") || strings.HasPrefix(next, "?") {
+			return resolveURL(pageURL, next)
+		}
+		// Anything else is a cursor sent back with the original query
+		param := pagination.CursorParam
+		if param == "" {
+			param = "cursor"
+		}
+		return setQueryParam(requestURL, param, next)
+	case models.PaginatePage:
+		if items == 0 || (pagination.Size > 0 && items < pagination.Size) {
+			return ""
+		}
+		page := 1
+		if current, err := strconv.Atoi(queryParam(pageURL, pagination.PageParam)); err == nil {
+			page = current
+		}
+		return setQueryParam(pageURL, pagination.PageParam, strconv.Itoa(page+1))
+	}
+	return ""
+}
+
+// pageItems counts the items of a page: the JSON array at the field, or the body
+func pageItems(response *models.HTTPResponse, field string) (int, error) {
+	body := string(response.Body)
+	if field != "" {
+		value, err := models.ExtractJSONPath(body, field)
+		if err != nil {
+			return 0, fmt.Errorf("no items at %s: %w", field, err)
+		}
+		body = value
+	}
+	var items []interface{}
+	if err := json.Unmarshal([]byte(body), &items); err != nil {
+		return 0, fmt.Errorf("items are not a JSON array")
+	}
+	return len(items), nil
+}
+
+// resolveURL resolves a link against the URL of the page it was found on
+func resolveURL(base, link string) string {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return link
+	}
+	resolved, err := baseURL.Parse(link)
+	if err != nil {
+		return link
+	}
+	return resolved.String()
+}
+
+// expandVariables replaces the {{name}} references of a text with their values
+func expandVariables(text string, variables map[string]string) string {
+	for name, value := range variables {
+		text = strings.ReplaceAll(text, "{{"+name+"}}", value)
+	}
+	return text
+}
+
+// queryParam returns the value of a query parameter of a URL, which may hold
+// {{variables}}
+func queryParam(rawURL, name string) string {
+	_, query, _ := strings.Cut(rawURL, "?")
+	query, _, _ = strings.Cut(query, "#")
+	for _, pair := range strings.Split(query, "&") {
+		key, value, _ := strings.Cut(pair, "=")
+		if key == name {
+			unescaped, err := url.QueryUnescape(value)
+			if err != nil {
+				return value
+			}
+			return unescaped
+		}
+	}
+	return ""
+}
+
+// setQueryParam sets a query parameter of a URL, which may hold {{variables}},
+// leaving the rest of the URL as written
+func setQueryParam(rawURL, name, value string) string {
+	rawURL, fragment, hasFragment := strings.Cut(rawURL, "#")
+	path, query, _ := strings.Cut(rawURL, "?")
+
+	pair := name + "=" + url.QueryEscape(value)
+	var pairs []string
+	replaced := false
+	if query != "" {
+		for _, existing := range strings.Split(query, "&") {
+			if key, _, _ := strings.Cut(existing, "="); key == name {
+				if !replaced {
+					pairs = append(pairs, pair)
+					replaced = true
+				}
+				continue
+			}
+			pairs = append(pairs, existing)
+		}
+	}
+	if !replaced {
+		pairs = append(pairs, pair)
+	}
+
+	result := path + "?" + strings.Join(pairs, "&")
+	if hasFragment {
+		result += "#" + fragment
+	}
+	return result
+}
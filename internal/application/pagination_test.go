@@ -0,0 +1,130 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pageExecutor serves canned responses keyed by request URL
+type pageExecutor struct {
+	pages    map[string]*models.HTTPResponse
+	requests []string
+}
+
+func (e *pageExecutor) Execute(ctx context.Context, request *models.HTTPRequest, variables map[string]string) (*models.HTTPResponse, error) {
+	e.requests = append(e.requests, request.URL)
+	response, ok := e.pages[request.URL]
+	if !ok {
+		return nil, fmt.Errorf("unexpected request %s", request.URL)
+	}
+	return response, nil
+}
+
+func (e *pageExecutor) ExecuteFile(ctx context.Context, file *models.HTTPFile, variables map[string]string) ([]*models.HTTPResponse, error) {
+	return nil, nil
+}
+
+func TestFollowPages(t *testing.T) {
+	tests := []struct {
+		name      string
+		paginate  models.Pagination
+		url       string
+		first     *models.HTTPResponse
+		pages     map[string]*models.HTTPResponse
+		wantPages int
+		wantItems int
+		truncated string
+		failed    bool
+	}{
+		{
+			name:     "link header",
+			paginate: models.Pagination{Mode: models.PaginateLink},
+			url:      "{{baseUrl}}/users",
+			first: &models.HTTPResponse{StatusCode: 200, Body: `[1, 2]`, Headers: map[string][]string{
+				"Link": {`<https://api.example.com/users?page=2>; rel="next", <https://api.example.com/users?page=3>; rel="last"`},
+			}},
+			pages: map[string]*models.HTTPResponse{
+				"https://api.example.com/users?page=2": {StatusCode: 200, Body: `[3]`},
+			},
+			wantPages: 2,
+			wantItems: 3,
+		},
+		{
+			name:     "next cursor",
+			paginate: models.Pagination{Mode: models.PaginateNext, NextField: "meta.next", ItemsField: "data"},
+			url:      "https://api.example.com/users?limit=2",
+			first:    &models.HTTPResponse{StatusCode: 200, Body: `{"data": [1, 2], "meta": {"next": "abc"}}`},
+			pages: map[string]*models.HTTPResponse{
+				"https://api.example.com/users?limit=2&cursor=abc": {StatusCode: 200, Body: `{"data": [3], "meta": {"next": null}}`},
+			},
+			wantPages: 2,
+			wantItems: 3,
+		},
+		{
+			name:     "page numbers stop on a short page",
+			paginate: models.Pagination{Mode: models.PaginatePage, PageParam: "page", SizeParam: "size", Size: 2},
+			url:      "https://api.example.com/users?size=2",
+			first:    &models.HTTPResponse{StatusCode: 200, Body: `[1, 2]`},
+			pages: map[string]*models.HTTPResponse{
+				"https://api.example.com/users?size=2&page=2": {StatusCode: 200, Body: `[3, 4]`},
+				"https://api.example.com/users?size=2&page=3": {StatusCode: 200, Body: `[5]`},
+			},
+			wantPages: 3,
+			wantItems: 5,
+		},
+		{
+			name:     "page limit",
+			paginate: models.Pagination{Mode: models.PaginatePage, PageParam: "page", MaxPages: 2},
+			url:      "https://api.example.com/users",
+			first:    &models.HTTPResponse{StatusCode: 200, Body: `[1]`},
+			pages: map[string]*models.HTTPResponse{
+				"https://api.example.com/users?page=2": {StatusCode: 200, Body: `[2]`},
+			},
+			wantPages: 2,
+			wantItems: 2,
+			truncated: "max-pages=2",
+		},
+		{
+			name:     "failing page",
+			paginate: models.Pagination{Mode: models.PaginatePage, PageParam: "page"},
+			url:      "https://api.example.com/users?page=1",
+			first:    &models.HTTPResponse{StatusCode: 200, Body: `[1]`},
+			pages: map[string]*models.HTTPResponse{
+				"https://api.example.com/users?page=2": {StatusCode: 500, Body: `{}`},
+			},
+			wantPages: 2,
+			wantItems: 1,
+			failed:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := &pageExecutor{pages: tt.pages}
+			runner := &TestRunnerService{httpExecutor: executor}
+			request := &models.HTTPRequest{Method: "GET", URL: tt.url, Paginate: &tt.paginate}
+
+			result, assertions := runner.followPages(context.Background(), request, tt.first, map[string]string{"baseUrl": "https://api.example.com"})
+			require.NotNil(t, result)
+			assert.Equal(t, tt.wantPages, result.Pages)
+			assert.Equal(t, tt.wantItems, result.Items)
+			assert.Equal(t, tt.truncated, result.Truncated)
+			assert.Len(t, result.Responses, len(executor.requests))
+
+			last := assertions[len(assertions)-1]
+			assert.Equal(t, !tt.failed, last.Passed, last.Error)
+		})
+	}
+}
+
+func TestSetQueryParam(t *testing.T) {
+	assert.Equal(t, "{{baseUrl}}/users?page=2", setQueryParam("{{baseUrl}}/users", "page", "2"))
+	assert.Equal(t, "/users?q={{term}}&page=3#top", setQueryParam("/users?q={{term}}&page=2#top", "page", "3"))
+	assert.Equal(t, "/users?cursor=a%2Bb", setQueryParam("/users", "cursor", "a+b"))
+	assert.Equal(t, "a+b", queryParam("/users?cursor=a%2Bb", "cursor"))
+}
@@ -12,6 +12,7 @@ import (
 	"strings"
 
 	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/edgardnogueira/swagger-to-http/internal/version"
 	"gopkg.in/yaml.v3"
 )
 
@@ -30,6 +31,7 @@ func (p *SwaggerParser) Parse(ctx context.Context, data []byte) (*models.Swagger
 	// Try JSON first
 	err := json.Unmarshal(data, &doc)
 	if err == nil {
+		doc.SpecHash = version.HashSpec(data)
 		return &doc, p.Validate(ctx, &doc)
 	}
 
@@ -39,6 +41,7 @@ func (p *SwaggerParser) Parse(ctx context.Context, data []byte) (*models.Swagger
 		return nil, fmt.Errorf("failed to parse document as JSON or YAML: %w", err)
 	}
 
+	doc.SpecHash = version.HashSpec(data)
 	return &doc, p.Validate(ctx, &doc)
 }
 
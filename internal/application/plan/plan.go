@@ -0,0 +1,363 @@
+// Package plan generates test plans for an operation of a Swagger/OpenAPI
+// document: sets of requests combining the values of its optional and enum
+// parameters, all of them or every pair of them, so parameter interactions
+// are covered without writing the requests by hand.
+package plan
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// Strategies combining the values of the parameters
+const (
+	// StrategyPairwise covers every pair of values of two parameters
+	StrategyPairwise = "pairwise"
+
+	// StrategyAll covers every combination of values
+	StrategyAll = "all"
+)
+
+// DefaultMaxCases is the default limit on the number of cases of a plan
+const DefaultMaxCases = 256
+
+// methods are the HTTP methods of operations, in the order they are listed
+var methods = []string{"GET", "PUT", "POST", "DELETE", "OPTIONS", "HEAD", "PATCH", "TRACE"}
+
+// Target is an operation of the document
+type Target struct {
+	Method    string
+	Path      string
+	PathItem  *models.PathItem
+	Operation *models.Operation
+}
+
+// Factor is a parameter whose values the plan combines. An empty value
+// leaves the parameter out of the request.
+type Factor struct {
+	Name   string
+	In     string // query or header
+	Values []string
+}
+
+// Case is a combination of values, one per factor, empty for the factors left
+// out of the request
+type Case []string
+
+// Plan is the set of cases of an operation
+type Plan struct {
+	Target  Target
+	Factors []Factor
+	Cases   []Case
+}
+
+// Options configures how a plan is built
+type Options struct {
+	Strategy string
+	MaxCases int
+}
+
+// FindOperation finds an operation by its operationId or as "METHOD /path"
+func FindOperation(doc *models.SwaggerDoc, selector string) (Target, error) {
+	method, path, byPath := strings.Cut(strings.TrimSpace(selector), " ")
+	paths := make([]string, 0, len(doc.Paths))
+	for p := range doc.Paths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	for _, p := range paths {
+		item := doc.Paths[p]
+		for _, m := range methods {
+			operation := item.Operation(m)
+			if operation == nil {
+				continue
+			}
+			matches := operation.OperationID == selector
+			if byPath {
+				matches = strings.EqualFold(method, m) && strings.TrimSpace(path) == p
+			}
+			if matches {
+				return Target{Method: m, Path: p, PathItem: &item, Operation: operation}, nil
+			}
+		}
+	}
+	return Target{}, fmt.Errorf("no operation %q in the spec: use an operationId or \"METHOD /path\"", selector)
+}
+
+// Build builds the plan of an operation
+func Build(target Target, options Options) (*Plan, error) {
+	maxCases := options.MaxCases
+	if maxCases <= 0 {
+		maxCases = DefaultMaxCases
+	}
+
+	plan := &Plan{Target: target, Factors: factors(target)}
+	switch options.Strategy {
+	case "", StrategyPairwise:
+		plan.Cases = pairwise(plan.Factors)
+	case StrategyAll:
+		total := 1
+		for _, factor := range plan.Factors {
+			total *= len(factor.Values)
+			if total > maxCases {
+				return nil, fmt.Errorf("%s %s has more than %d combinations: use the %s strategy or raise the limit", target.Method, target.Path, maxCases, StrategyPairwise)
+			}
+		}
+		plan.Cases = allCombinations(plan.Factors)
+	default:
+		return nil, fmt.Errorf("invalid strategy %q: use %s or %s", options.Strategy, StrategyPairwise, StrategyAll)
+	}
+
+	if len(plan.Cases) > maxCases {
+		return nil, fmt.Errorf("the plan of %s %s has %d cases, more than %d", target.Method, target.Path, len(plan.Cases), maxCases)
+	}
+	return plan, nil
+}
+
+// Apply returns the request of a case: the base request with the values of
+// the case in its query string and headers
+func (p *Plan) Apply(base models.HTTPRequest, index int) models.HTTPRequest {
+	c := p.Cases[index]
+	request := base
+	request.Headers = make(map[string]string, len(base.Headers))
+	for name, value := range base.Headers {
+		request.Headers[name] = value
+	}
+
+	var query []string
+	for i, factor := range p.Factors {
+		if c[i] == "" {
+			continue
+		}
+		switch factor.In {
+		case "header":
+			request.Headers[factor.Name] = c[i]
+		default:
+			query = append(query, url.QueryEscape(factor.Name)+"="+escapeQueryValue(c[i]))
+		}
+	}
+	if len(query) > 0 {
+		separator := "?"
+		if strings.Contains(request.URL, "?") {
+			separator = "&"
+		}
+		request.URL += separator + strings.Join(query, "&")
+	}
+
+	if base.Name != "" {
+		request.Name = fmt.Sprintf("%s_case%d", base.Name, index+1)
+	}
+	request.Comments = append(append([]string{}, base.Comments...), fmt.Sprintf("Plan case %d/%d: %s", index+1, len(p.Cases), p.Describe(index)))
+	return request
+}
+
+// Describe describes the values of a case, e.g. "status=sold, limit absent"
+func (p *Plan) Describe(index int) string {
+	if len(p.Factors) == 0 {
+		return "no parameters"
+	}
+	parts := make([]string, len(p.Factors))
+	for i, factor := range p.Factors {
+		if p.Cases[index][i] == "" {
+			parts[i] = factor.Name + " absent"
+		} else {
+			parts[i] = factor.Name + "=" + p.Cases[index][i]
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// factors returns the query and header parameters of an operation with the
+// values they are tested with, including leaving out the optional ones.
+// Operation parameters override those of the path.
+func factors(target Target) []Factor {
+	var parameters []models.Parameter
+	if target.PathItem != nil {
+		parameters = append(parameters, target.PathItem.Parameters...)
+	}
+	for _, parameter := range target.Operation.Parameters {
+		overridden := false
+		for i, existing := range parameters {
+			if existing.In == parameter.In && existing.Name == parameter.Name {
+				parameters[i], overridden = parameter, true
+			}
+		}
+		if !overridden {
+			parameters = append(parameters, parameter)
+		}
+	}
+
+	var result []Factor
+	for _, parameter := range parameters {
+		if parameter.In != "query" && parameter.In != "header" {
+			continue
+		}
+		values := parameterValues(parameter)
+		if !parameter.Required {
+			values = append([]string{""}, values...)
+		}
+		result = append(result, Factor{Name: parameter.Name, In: parameter.In, Values: values})
+	}
+	return result
+}
+
+// parameterValues returns the values a parameter is tested with: its enum
+// values, true and false for booleans, or else a single example
+func parameterValues(parameter models.Parameter) []string {
+	typ, enum, example := parameter.Type, parameter.Enum, parameter.Default
+	if schema := parameter.Schema; schema != nil {
+		if typ == "" {
+			typ = schema.Type
+		}
+		if len(enum) == 0 {
+			enum = schema.Enum
+		}
+		if schema.Example != nil {
+			example = schema.Example
+		} else if example == nil {
+			example = schema.Default
+		}
+	}
+	if len(enum) == 0 && typ == "array" {
+		if parameter.Items != nil {
+			enum = parameter.Items.Enum
+		} else if parameter.Schema != nil && parameter.Schema.Items != nil {
+			enum = parameter.Schema.Items.Enum
+		}
+	}
+
+	switch {
+	case len(enum) > 0:
+		values := make([]string, 0, len(enum))
+		for _, value := range enum {
+			values = append(values, fmt.Sprint(value))
+		}
+		return values
+	case typ == "boolean":
+		return []string{"true", "false"}
+	case example != nil:
+		return []string{fmt.Sprint(example)}
+	case typ == "integer" || typ == "number":
+		return []string{"1"}
+	default:
+		return []string{"{{" + parameter.Name + "}}"}
+	}
+}
+
+// allCombinations returns every combination of the values of the factors
+func allCombinations(factors []Factor) []Case {
+	cases := []Case{{}}
+	for _, factor := range factors {
+		next := make([]Case, 0, len(cases)*len(factor.Values))
+		for _, c := range cases {
+			for _, value := range factor.Values {
+				next = append(next, append(append(Case{}, c...), value))
+			}
+		}
+		cases = next
+	}
+	return cases
+}
+
+// pairwise returns cases covering every pair of values of two factors,
+// greedily: each case starts from the first pair not covered yet and picks,
+// for each other factor, the value covering the most new pairs
+func pairwise(factors []Factor) []Case {
+	if len(factors) < 2 {
+		return allCombinations(factors)
+	}
+
+	// covered[i][j] holds the pairs of values of factors i < j already covered
+	type pair struct{ a, b int }
+	covered := make(map[[2]int]map[pair]bool)
+	remaining := 0
+	for i := range factors {
+		for j := i + 1; j < len(factors); j++ {
+			covered[[2]int{i, j}] = make(map[pair]bool)
+			remaining += len(factors[i].Values) * len(factors[j].Values)
+		}
+	}
+
+	var cases []Case
+	for remaining > 0 {
+		// Start from the first pair not covered yet
+		chosen := make([]int, len(factors))
+		for k := range chosen {
+			chosen[k] = -1
+		}
+	first:
+		for i := range factors {
+			for j := i + 1; j < len(factors); j++ {
+				for a := range factors[i].Values {
+					for b := range factors[j].Values {
+						if !covered[[2]int{i, j}][pair{a, b}] {
+							chosen[i], chosen[j] = a, b
+							break first
+						}
+					}
+				}
+			}
+		}
+
+		// Fill the other factors with the values covering the most new pairs
+		for k := range factors {
+			if chosen[k] >= 0 {
+				continue
+			}
+			best, bestGain := 0, -1
+			for v := range factors[k].Values {
+				gain := 0
+				for other, value := range chosen {
+					if other == k || value < 0 {
+						continue
+					}
+					if other < k && !covered[[2]int{other, k}][pair{value, v}] {
+						gain++
+					}
+					if other > k && !covered[[2]int{k, other}][pair{v, value}] {
+						gain++
+					}
+				}
+				if gain > bestGain {
+					best, bestGain = v, gain
+				}
+			}
+			chosen[k] = best
+		}
+
+		c := make(Case, len(factors))
+		for i := range factors {
+			c[i] = factors[i].Values[chosen[i]]
+			for j := i + 1; j < len(factors); j++ {
+				if p := (pair{chosen[i], chosen[j]}); !covered[[2]int{i, j}][p] {
+					covered[[2]int{i, j}][p] = true
+					remaining--
+				}
+			}
+		}
+		cases = append(cases, c)
+	}
+	return cases
+}
+
+// escapeQueryValue escapes a query value, keeping {{variables}} as they are
+func escapeQueryValue(value string) string {
+	var b strings.Builder
+	for value != "" {
+		start := strings.Index(value, "{{")
+		end := strings.Index(value, "}}")
+		if start < 0 || end < start {
+			b.WriteString(url.QueryEscape(value))
+			break
+		}
+		b.WriteString(url.QueryEscape(value[:start]))
+		b.WriteString(value[start : end+2])
+		value = value[end+2:]
+	}
+	return b.String()
+}
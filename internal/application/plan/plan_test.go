@@ -0,0 +1,122 @@
+package plan
+
+import (
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testDoc() *models.SwaggerDoc {
+	return &models.SwaggerDoc{
+		Paths: map[string]models.PathItem{
+			"/pets": {
+				Parameters: []models.Parameter{{Name: "X-Tenant", In: "header", Required: true, Schema: &models.Schema{Type: "string", Example: "acme"}}},
+				Get: &models.Operation{
+					OperationID: "listPets",
+					Parameters: []models.Parameter{
+						{Name: "status", In: "query", Schema: &models.Schema{Type: "string", Enum: []interface{}{"available", "pending", "sold"}}},
+						{Name: "sort", In: "query", Required: true, Schema: &models.Schema{Type: "string", Enum: []interface{}{"name", "age"}}},
+						{Name: "includeArchived", In: "query", Type: "boolean"},
+						{Name: "limit", In: "query", Schema: &models.Schema{Type: "integer", Example: 20}},
+						{Name: "petId", In: "path", Required: true, Schema: &models.Schema{Type: "string"}},
+					},
+				},
+				Post: &models.Operation{OperationID: "addPet"},
+			},
+		},
+	}
+}
+
+func TestFindOperation(t *testing.T) {
+	doc := testDoc()
+
+	target, err := FindOperation(doc, "listPets")
+	require.NoError(t, err)
+	assert.Equal(t, "GET", target.Method)
+	assert.Equal(t, "/pets", target.Path)
+
+	target, err = FindOperation(doc, "post /pets")
+	require.NoError(t, err)
+	assert.Equal(t, "addPet", target.Operation.OperationID)
+
+	_, err = FindOperation(doc, "deletePet")
+	assert.Error(t, err)
+}
+
+func TestBuildPairwise(t *testing.T) {
+	target, err := FindOperation(testDoc(), "listPets")
+	require.NoError(t, err)
+
+	plan, err := Build(target, Options{})
+	require.NoError(t, err)
+
+	require.Len(t, plan.Factors, 5)
+	assert.Equal(t, Factor{Name: "X-Tenant", In: "header", Values: []string{"acme"}}, plan.Factors[0])
+	assert.Equal(t, Factor{Name: "status", In: "query", Values: []string{"", "available", "pending", "sold"}}, plan.Factors[1])
+	assert.Equal(t, Factor{Name: "sort", In: "query", Values: []string{"name", "age"}}, plan.Factors[2])
+	assert.Equal(t, Factor{Name: "includeArchived", In: "query", Values: []string{"", "true", "false"}}, plan.Factors[3])
+	assert.Equal(t, Factor{Name: "limit", In: "query", Values: []string{"", "20"}}, plan.Factors[4])
+
+	// Every pair of values of two factors is covered, with far fewer cases
+	// than the 48 combinations
+	for i := range plan.Factors {
+		for j := i + 1; j < len(plan.Factors); j++ {
+			for _, a := range plan.Factors[i].Values {
+				for _, b := range plan.Factors[j].Values {
+					found := false
+					for _, c := range plan.Cases {
+						found = found || (c[i] == a && c[j] == b)
+					}
+					assert.True(t, found, "%s=%q with %s=%q", plan.Factors[i].Name, a, plan.Factors[j].Name, b)
+				}
+			}
+		}
+	}
+	assert.Less(t, len(plan.Cases), 16)
+	assert.GreaterOrEqual(t, len(plan.Cases), 12)
+}
+
+func TestBuildAll(t *testing.T) {
+	target, err := FindOperation(testDoc(), "listPets")
+	require.NoError(t, err)
+
+	plan, err := Build(target, Options{Strategy: StrategyAll})
+	require.NoError(t, err)
+	assert.Len(t, plan.Cases, 48)
+
+	_, err = Build(target, Options{Strategy: StrategyAll, MaxCases: 20})
+	assert.ErrorContains(t, err, "more than 20 combinations")
+
+	_, err = Build(target, Options{Strategy: "random"})
+	assert.Error(t, err)
+}
+
+func TestApply(t *testing.T) {
+	plan := &Plan{
+		Factors: []Factor{
+			{Name: "X-Tenant", In: "header", Values: []string{"acme"}},
+			{Name: "status", In: "query", Values: []string{"", "sold out"}},
+			{Name: "owner", In: "query", Values: []string{"{{owner}}"}},
+		},
+		Cases: []Case{{"acme", "", "{{owner}}"}, {"acme", "sold out", "{{owner}}"}},
+	}
+	base := models.HTTPRequest{
+		Name:    "listPets",
+		Method:  "GET",
+		URL:     "{{baseUrl}}/pets",
+		Headers: map[string]string{"Accept": "application/json"},
+	}
+
+	request := plan.Apply(base, 1)
+	assert.Equal(t, "listPets_case2", request.Name)
+	assert.Equal(t, "{{baseUrl}}/pets?status=sold+out&owner={{owner}}", request.URL)
+	assert.Equal(t, map[string]string{"Accept": "application/json", "X-Tenant": "acme"}, request.Headers)
+	assert.Equal(t, []string{"Plan case 2/2: X-Tenant=acme, status=sold out, owner={{owner}}"}, request.Comments)
+
+	request = plan.Apply(base, 0)
+	assert.Equal(t, "{{baseUrl}}/pets?owner={{owner}}", request.URL)
+	assert.Contains(t, request.Comments[0], "status absent")
+	assert.Equal(t, map[string]string{"Accept": "application/json"}, base.Headers)
+}
@@ -0,0 +1,51 @@
+package application
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// rateLimiter spaces out the requests of operations with a rate limit, so that
+// runs, data rows and languages sending the same request don't exceed it
+type rateLimiter struct {
+	mu   sync.Mutex
+	next map[string]time.Time
+}
+
+// wait blocks until the request may be sent under its rate limit, or the
+// context is done
+func (l *rateLimiter) wait(ctx context.Context, request *models.HTTPRequest) error {
+	if request.RateLimit == nil || request.RateLimit.Interval() <= 0 {
+		return nil
+	}
+	key := request.Method + " " + request.Name
+
+	// Reserve the next free slot of the operation
+	l.mu.Lock()
+	if l.next == nil {
+		l.next = make(map[string]time.Time)
+	}
+	now := time.Now()
+	slot := l.next[key]
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next[key] = slot.Add(request.RateLimit.Interval())
+	l.mu.Unlock()
+
+	delay := time.Until(slot)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
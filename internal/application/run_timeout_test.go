@@ -0,0 +1,66 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunFiles_RunTimeout(t *testing.T) {
+	tests := []struct {
+		name      string
+		dependsOn []string
+	}{
+		{name: "sequential"},
+		{name: "with dependencies", dependsOn: []string{"slow"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := []*models.HTTPFile{
+				{
+					Filename: "users.http",
+					Requests: []models.HTTPRequest{
+						{Name: "fast", Method: "GET", URL: "https://api.example.com/health"},
+						{Name: "slow", Method: "GET", URL: "https://api.example.com/users"},
+						{Name: "next", Method: "GET", URL: "https://api.example.com/users/1", DependsOn: tt.dependsOn},
+					},
+				},
+				{
+					Filename: "orders.http",
+					Requests: []models.HTTPRequest{{Name: "orders", Method: "GET", URL: "https://api.example.com/orders"}},
+				},
+			}
+
+			// The slow request completes after the deadline
+			executor := &recordingExecutor{respond: func(request *models.HTTPRequest, variables map[string]string) (*models.HTTPResponse, error) {
+				if request.Name == "slow" {
+					time.Sleep(300 * time.Millisecond)
+				}
+				return &models.HTTPResponse{StatusCode: 200, Request: request}, nil
+			}}
+			runner := NewTestRunnerService(executor, noSnapshots{}, nil)
+
+			options := models.TestRunOptions{RunTimeout: 100 * time.Millisecond}.WithRunDeadline(time.Now())
+			results, err := runner.runFiles(context.Background(), files, options)
+			require.NoError(t, err)
+
+			// The tests not started by the deadline are skipped without being sent
+			assert.Equal(t, []string{"fast", "slow"}, executor.sent())
+			assert.Equal(t, map[string]models.TestStatus{
+				"fast":   models.TestStatusPassed,
+				"slow":   models.TestStatusPassed,
+				"next":   models.TestStatusSkipped,
+				"orders": models.TestStatusSkipped,
+			}, resultStatuses(results))
+			for _, result := range results[2:] {
+				assert.Equal(t, models.RunTimeoutReason, result.Error)
+			}
+			assert.Equal(t, 2, models.CountRunTimeouts(results))
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package scaffold
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// Exchange is a recorded request and its response
+type Exchange struct {
+	Method              string
+	URL                 string
+	RequestHeaders      map[string]string
+	RequestContentType  string
+	RequestBody         string
+	Status              int
+	ResponseContentType string
+	ResponseBody        string
+}
+
+// harFile is the subset of the HAR 1.2 format read by ParseHAR
+type harFile struct {
+	Log struct {
+		Entries []struct {
+			Request struct {
+				Method  string `json:"method"`
+				URL     string `json:"url"`
+				Headers []struct {
+					Name  string `json:"name"`
+					Value string `json:"value"`
+				} `json:"headers"`
+				PostData *struct {
+					MimeType string `json:"mimeType"`
+					Text     string `json:"text"`
+				} `json:"postData"`
+			} `json:"request"`
+			Response struct {
+				Status  int `json:"status"`
+				Content struct {
+					MimeType string `json:"mimeType"`
+					Text     string `json:"text"`
+					Encoding string `json:"encoding"`
+				} `json:"content"`
+			} `json:"response"`
+		} `json:"entries"`
+	} `json:"log"`
+}
+
+// IsHAR reports whether data looks like a HAR file
+func IsHAR(data []byte) bool {
+	var probe struct {
+		Log *struct {
+			Entries json.RawMessage `json:"entries"`
+		} `json:"log"`
+	}
+	return json.Unmarshal(data, &probe) == nil && probe.Log != nil && probe.Log.Entries != nil
+}
+
+// ParseHAR reads the exchanges of a HAR file, as exported by browsers and proxies
+func ParseHAR(data []byte) ([]Exchange, error) {
+	var har harFile
+	if err := json.Unmarshal(data, &har); err != nil {
+		return nil, fmt.Errorf("failed to parse HAR: %w", err)
+	}
+
+	var exchanges []Exchange
+	for _, entry := range har.Log.Entries {
+		exchange := Exchange{
+			Method:              strings.ToUpper(entry.Request.Method),
+			URL:                 entry.Request.URL,
+			RequestHeaders:      make(map[string]string),
+			Status:              entry.Response.Status,
+			ResponseContentType: entry.Response.Content.MimeType,
+			ResponseBody:        entry.Response.Content.Text,
+		}
+		for _, header := range entry.Request.Headers {
+			exchange.RequestHeaders[header.Name] = header.Value
+		}
+		if entry.Request.PostData != nil {
+			exchange.RequestContentType = entry.Request.PostData.MimeType
+			exchange.RequestBody = entry.Request.PostData.Text
+		}
+		if entry.Response.Content.Encoding == "base64" {
+			decoded, err := base64.StdEncoding.DecodeString(exchange.ResponseBody)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode response of %s %s: %w", exchange.Method, exchange.URL, err)
+			}
+			exchange.ResponseBody = string(decoded)
+		}
+		exchanges = append(exchanges, exchange)
+	}
+	return exchanges, nil
+}
+
+// FromResults returns the exchanges recorded in test results
+func FromResults(results []models.TestResult) []Exchange {
+	var exchanges []Exchange
+	for _, result := range results {
+		response := result.RawResponse
+		if response == nil {
+			response = result.Response
+		}
+		if result.Request == nil || response == nil {
+			continue
+		}
+
+		exchange := Exchange{
+			Method:         strings.ToUpper(result.Request.Method),
+			URL:            result.Request.URL,
+			RequestHeaders: result.Request.Headers,
+			RequestBody:    result.Request.Body,
+			Status:         response.StatusCode,
+			ResponseBody:   response.Body,
+		}
+		for name, value := range result.Request.Headers {
+			if strings.EqualFold(name, "Content-Type") {
+				exchange.RequestContentType = value
+			}
+		}
+		exchange.ResponseContentType = response.ContentType
+		for name, values := range response.Headers {
+			if exchange.ResponseContentType == "" && strings.EqualFold(name, "Content-Type") && len(values) > 0 {
+				exchange.ResponseContentType = values[0]
+			}
+		}
+		exchanges = append(exchanges, exchange)
+	}
+	return exchanges
+}
@@ -0,0 +1,312 @@
+// Package scaffold drafts an OpenAPI document from recorded traffic, as a
+// starting point for documenting services that have no spec.
+package scaffold
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// OpenAPIVersion is the version of the scaffolded documents
+const OpenAPIVersion = "3.0.3"
+
+// idSegmentPattern matches path segments that look like identifiers: numbers,
+// UUIDs and long hexadecimal strings such as object ids and hashes
+var idSegmentPattern = regexp.MustCompile(`^(\d+|[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}|[0-9a-fA-F]{16,})$`)
+
+// Document is a scaffolded OpenAPI 3 document
+type Document struct {
+	OpenAPI string                           `json:"openapi" yaml:"openapi"`
+	Info    models.Info                      `json:"info" yaml:"info"`
+	Servers []models.Server                  `json:"servers,omitempty" yaml:"servers,omitempty"`
+	Paths   map[string]map[string]*Operation `json:"paths" yaml:"paths"`
+}
+
+// Operation is a scaffolded operation
+type Operation struct {
+	OperationID string               `json:"operationId" yaml:"operationId"`
+	Parameters  []Parameter          `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	RequestBody *Body                `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses" yaml:"responses"`
+}
+
+// Parameter is a scaffolded path or query parameter
+type Parameter struct {
+	Name     string  `json:"name" yaml:"name"`
+	In       string  `json:"in" yaml:"in"`
+	Required bool    `json:"required" yaml:"required"`
+	Schema   *Schema `json:"schema" yaml:"schema"`
+}
+
+// Body is a scaffolded request body
+type Body struct {
+	Required bool                 `json:"required,omitempty" yaml:"required,omitempty"`
+	Content  map[string]MediaType `json:"content" yaml:"content"`
+}
+
+// Response is a scaffolded response
+type Response struct {
+	Description string               `json:"description" yaml:"description"`
+	Content     map[string]MediaType `json:"content,omitempty" yaml:"content,omitempty"`
+}
+
+// MediaType holds the schema of a body
+type MediaType struct {
+	Schema *Schema `json:"schema" yaml:"schema"`
+}
+
+// operationDraft collects the observations of one operation
+type operationDraft struct {
+	operation   *Operation
+	exchanges   int
+	bodies      int
+	queryCounts map[string]int
+	querySchema map[string]*Schema
+	queryOrder  []string
+}
+
+// Scaffold drafts an OpenAPI document from the exchanges. Path segments that
+// look like identifiers become path parameters, and the schemas of the JSON
+// bodies and query parameters are inferred from the observed values.
+func Scaffold(exchanges []Exchange, title string) (*Document, error) {
+	doc := &Document{
+		OpenAPI: OpenAPIVersion,
+		Info:    models.Info{Title: title, Version: "0.1.0"},
+		Paths:   make(map[string]map[string]*Operation),
+	}
+
+	drafts := make(map[string]*operationDraft)
+	servers := make(map[string]bool)
+
+	for _, exchange := range exchanges {
+		parsed, err := url.Parse(exchange.URL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid URL %q: %w", exchange.URL, err)
+		}
+		if parsed.Scheme != "" && parsed.Host != "" {
+			server := parsed.Scheme + "://" + parsed.Host
+			if !servers[server] {
+				servers[server] = true
+				doc.Servers = append(doc.Servers, models.Server{URL: server})
+			}
+		}
+
+		path, pathParams := templatePath(parsed.Path)
+		method := strings.ToLower(exchange.Method)
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = make(map[string]*Operation)
+		}
+
+		key := method + " " + path
+		draft := drafts[key]
+		if draft == nil {
+			draft = &operationDraft{
+				operation: &Operation{
+					OperationID: operationID(method, path),
+					Responses:   make(map[string]*Response),
+				},
+				queryCounts: make(map[string]int),
+				querySchema: make(map[string]*Schema),
+			}
+			for _, name := range pathParams {
+				draft.operation.Parameters = append(draft.operation.Parameters, Parameter{
+					Name:     name,
+					In:       "path",
+					Required: true,
+					Schema:   &Schema{Type: "string"},
+				})
+			}
+			drafts[key] = draft
+			doc.Paths[path][method] = draft.operation
+		}
+
+		draft.observe(exchange, parsed.Query())
+	}
+
+	for _, draft := range drafts {
+		draft.finish()
+	}
+
+	sort.Slice(doc.Servers, func(i, j int) bool { return doc.Servers[i].URL < doc.Servers[j].URL })
+	return doc, nil
+}
+
+// observe records an exchange of the operation
+func (d *operationDraft) observe(exchange Exchange, query url.Values) {
+	d.exchanges++
+
+	for name, values := range query {
+		if d.queryCounts[name] == 0 {
+			d.queryOrder = append(d.queryOrder, name)
+		}
+		d.queryCounts[name]++
+		for _, value := range values {
+			d.querySchema[name] = MergeSchemas(d.querySchema[name], queryValueSchema(value))
+		}
+	}
+
+	if strings.TrimSpace(exchange.RequestBody) != "" {
+		d.bodies++
+		if d.operation.RequestBody == nil {
+			d.operation.RequestBody = &Body{Content: make(map[string]MediaType)}
+		}
+		mergeContent(d.operation.RequestBody.Content, exchange.RequestContentType, exchange.RequestBody)
+	}
+
+	status := strconv.Itoa(exchange.Status)
+	response := d.operation.Responses[status]
+	if response == nil {
+		response = &Response{Description: http.StatusText(exchange.Status)}
+		if response.Description == "" {
+			response.Description = "Response " + status
+		}
+		d.operation.Responses[status] = response
+	}
+	if strings.TrimSpace(exchange.ResponseBody) != "" {
+		if response.Content == nil {
+			response.Content = make(map[string]MediaType)
+		}
+		mergeContent(response.Content, exchange.ResponseContentType, exchange.ResponseBody)
+	}
+}
+
+// finish adds the query parameters and completes the schemas once every
+// exchange has been observed
+func (d *operationDraft) finish() {
+	sort.Strings(d.queryOrder)
+	for _, name := range d.queryOrder {
+		d.operation.Parameters = append(d.operation.Parameters, Parameter{
+			Name:     name,
+			In:       "query",
+			Required: d.queryCounts[name] == d.exchanges,
+			Schema:   d.querySchema[name],
+		})
+	}
+	if d.operation.RequestBody != nil {
+		d.operation.RequestBody.Required = d.bodies == d.exchanges
+		for _, media := range d.operation.RequestBody.Content {
+			media.Schema.complete()
+		}
+	}
+	for _, response := range d.operation.Responses {
+		for _, media := range response.Content {
+			media.Schema.complete()
+		}
+	}
+}
+
+// mergeContent merges the schema of a body into the content of its media type
+func mergeContent(content map[string]MediaType, contentType, body string) {
+	media := strings.TrimSpace(strings.ToLower(strings.SplitN(contentType, ";", 2)[0]))
+
+	var value interface{}
+	isJSON := json.Unmarshal([]byte(body), &value) == nil
+	if media == "" {
+		media = "text/plain"
+		if isJSON {
+			media = "application/json"
+		}
+	}
+
+	schema := &Schema{Type: "string"}
+	if isJSON && (media == "application/json" || strings.HasSuffix(media, "+json")) {
+		schema = InferSchema(value)
+	}
+	content[media] = MediaType{Schema: MergeSchemas(content[media].Schema, schema)}
+}
+
+// queryValueSchema infers the schema of a query parameter value
+func queryValueSchema(value string) *Schema {
+	if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return &Schema{Type: "integer"}
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return &Schema{Type: "number"}
+	}
+	if value == "true" || value == "false" {
+		return &Schema{Type: "boolean"}
+	}
+	return &Schema{Type: "string"}
+}
+
+// templatePath replaces the identifier segments of a path with parameters
+// named after the preceding segment, e.g. /users/42 becomes /users/{userId}
+func templatePath(path string) (string, []string) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	var params []string
+	used := make(map[string]int)
+
+	for i, segment := range segments {
+		if !idSegmentPattern.MatchString(segment) {
+			continue
+		}
+		name := "id"
+		if i > 0 && !strings.HasPrefix(segments[i-1], "{") {
+			name = singular(segments[i-1]) + "Id"
+		}
+		used[name]++
+		if used[name] > 1 {
+			name = fmt.Sprintf("%s%d", name, used[name])
+		}
+		segments[i] = "{" + name + "}"
+		params = append(params, name)
+	}
+
+	return "/" + strings.Join(segments, "/"), params
+}
+
+// singular returns a naive singular, lower camel case form of a path segment
+func singular(segment string) string {
+	words := strings.FieldsFunc(segment, func(r rune) bool { return r == '-' || r == '_' || r == '.' })
+	if len(words) == 0 {
+		return "id"
+	}
+	name := strings.ToLower(words[0])
+	for _, word := range words[1:] {
+		name += capitalize(strings.ToLower(word))
+	}
+	switch {
+	case strings.HasSuffix(name, "ies"):
+		return strings.TrimSuffix(name, "ies") + "y"
+	case strings.HasSuffix(name, "ses"):
+		return strings.TrimSuffix(name, "es")
+	case strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss"):
+		return strings.TrimSuffix(name, "s")
+	}
+	return name
+}
+
+// operationID builds an operation id from the method and path, e.g.
+// getUsersByUserId for GET /users/{userId}
+func operationID(method, path string) string {
+	id := method
+	for _, segment := range strings.Split(strings.Trim(path, "/"), "/") {
+		if segment == "" {
+			continue
+		}
+		if strings.HasPrefix(segment, "{") {
+			id += "By" + capitalize(strings.Trim(segment, "{}"))
+			continue
+		}
+		for _, word := range strings.FieldsFunc(segment, func(r rune) bool { return r == '-' || r == '_' || r == '.' }) {
+			id += capitalize(strings.ToLower(word))
+		}
+	}
+	return id
+}
+
+// capitalize upper-cases the first letter of a word
+func capitalize(word string) string {
+	if word == "" {
+		return word
+	}
+	return strings.ToUpper(word[:1]) + word[1:]
+}
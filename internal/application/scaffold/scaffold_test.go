@@ -0,0 +1,116 @@
+package scaffold
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testHAR = `{
+  "log": {
+    "entries": [
+      {
+        "request": {"method": "GET", "url": "https://api.example.com/users/42", "headers": []},
+        "response": {"status": 200, "content": {"mimeType": "application/json; charset=utf-8", "text": "{\"id\": 42, \"email\": \"ada@example.com\", \"score\": 1}"}}
+      },
+      {
+        "request": {"method": "GET", "url": "https://api.example.com/users/7", "headers": []},
+        "response": {"status": 200, "content": {"mimeType": "application/json", "text": "eyJpZCI6IDcsICJlbWFpbCI6IG51bGwsICJzY29yZSI6IDEuNX0=", "encoding": "base64"}}
+      },
+      {
+        "request": {"method": "GET", "url": "https://api.example.com/users?page=2&active=true", "headers": []},
+        "response": {"status": 200, "content": {"mimeType": "application/json", "text": "[{\"id\": 1, \"tags\": [\"a\"]}, {\"id\": 2}]"}}
+      },
+      {
+        "request": {"method": "GET", "url": "https://api.example.com/users?page=3", "headers": []},
+        "response": {"status": 200, "content": {"mimeType": "application/json", "text": "[]"}}
+      },
+      {
+        "request": {
+          "method": "POST", "url": "https://api.example.com/users", "headers": [{"name": "Content-Type", "value": "application/json"}],
+          "postData": {"mimeType": "application/json", "text": "{\"email\": \"new@example.com\", \"createdAt\": \"2024-03-15T10:20:00Z\"}"}
+        },
+        "response": {"status": 201, "content": {"mimeType": "application/json", "text": "{\"id\": 43}"}}
+      },
+      {
+        "request": {"method": "DELETE", "url": "https://api.example.com/users/43", "headers": []},
+        "response": {"status": 204, "content": {"mimeType": "", "text": ""}}
+      }
+    ]
+  }
+}`
+
+func TestScaffoldFromHAR(t *testing.T) {
+	require.True(t, IsHAR([]byte(testHAR)))
+	require.False(t, IsHAR([]byte(`{"results": []}`)))
+
+	exchanges, err := ParseHAR([]byte(testHAR))
+	require.NoError(t, err)
+	require.Len(t, exchanges, 6)
+
+	doc, err := Scaffold(exchanges, "Users API")
+	require.NoError(t, err)
+
+	assert.Equal(t, "Users API", doc.Info.Title)
+	require.Len(t, doc.Servers, 1)
+	assert.Equal(t, "https://api.example.com", doc.Servers[0].URL)
+
+	getUser := doc.Paths["/users/{userId}"]["get"]
+	require.NotNil(t, getUser)
+	assert.Equal(t, "getUsersByUserId", getUser.OperationID)
+	assert.Equal(t, []Parameter{{Name: "userId", In: "path", Required: true, Schema: &Schema{Type: "string"}}}, getUser.Parameters)
+	assert.Equal(t, &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"id":    {Type: "integer"},
+			"email": {Type: "string", Format: "email", Nullable: true},
+			"score": {Type: "number"},
+		},
+		Required: []string{"email", "id", "score"},
+	}, getUser.Responses["200"].Content["application/json"].Schema)
+
+	listUsers := doc.Paths["/users"]["get"]
+	require.NotNil(t, listUsers)
+	assert.Equal(t, []Parameter{
+		{Name: "active", In: "query", Required: false, Schema: &Schema{Type: "boolean"}},
+		{Name: "page", In: "query", Required: true, Schema: &Schema{Type: "integer"}},
+	}, listUsers.Parameters)
+	assert.Equal(t, &Schema{
+		Type: "array",
+		Items: &Schema{
+			Type: "object",
+			Properties: map[string]*Schema{
+				"id":   {Type: "integer"},
+				"tags": {Type: "array", Items: &Schema{Type: "string"}},
+			},
+			Required: []string{"id"},
+		},
+	}, listUsers.Responses["200"].Content["application/json"].Schema)
+
+	createUser := doc.Paths["/users"]["post"]
+	require.NotNil(t, createUser)
+	require.NotNil(t, createUser.RequestBody)
+	assert.True(t, createUser.RequestBody.Required)
+	assert.Equal(t, "date-time", createUser.RequestBody.Content["application/json"].Schema.Properties["createdAt"].Format)
+	assert.Equal(t, "Created", createUser.Responses["201"].Description)
+
+	deleteUser := doc.Paths["/users/{userId}"]["delete"]
+	require.NotNil(t, deleteUser)
+	assert.Equal(t, "No Content", deleteUser.Responses["204"].Description)
+	assert.Nil(t, deleteUser.Responses["204"].Content)
+}
+
+func TestMergeSchemasWithUnrelatedTypes(t *testing.T) {
+	merged := MergeSchemas(InferSchema("a"), InferSchema(float64(1)))
+	assert.Equal(t, &Schema{}, merged)
+
+	// An open schema stays open
+	assert.Equal(t, &Schema{Nullable: true}, MergeSchemas(merged, InferSchema(nil)))
+}
+
+func TestTemplatePath(t *testing.T) {
+	path, params := templatePath("/categories/3/items/5f1d7a9b2c3e4f5a6b7c8d9e/item-versions/2")
+	assert.Equal(t, "/categories/{categoryId}/items/{itemId}/item-versions/{itemVersionId}", path)
+	assert.Equal(t, []string{"categoryId", "itemId", "itemVersionId"}, params)
+}
@@ -0,0 +1,169 @@
+package scaffold
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"time"
+)
+
+var (
+	uuidPattern  = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	emailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+	uriPattern   = regexp.MustCompile(`^https?://\S+$`)
+)
+
+// Schema is a JSON schema inferred from observed values
+type Schema struct {
+	Type       string             `json:"type,omitempty" yaml:"type,omitempty"`
+	Format     string             `json:"format,omitempty" yaml:"format,omitempty"`
+	Nullable   bool               `json:"nullable,omitempty" yaml:"nullable,omitempty"`
+	Properties map[string]*Schema `json:"properties,omitempty" yaml:"properties,omitempty"`
+	Required   []string           `json:"required,omitempty" yaml:"required,omitempty"`
+	Items      *Schema            `json:"items,omitempty" yaml:"items,omitempty"`
+}
+
+// InferSchema infers the schema of a value decoded from JSON. Every property of
+// an object is required until a value without it is merged in.
+func InferSchema(value interface{}) *Schema {
+	switch v := value.(type) {
+	case nil:
+		return &Schema{Nullable: true}
+	case bool:
+		return &Schema{Type: "boolean"}
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return &Schema{Type: "integer"}
+		}
+		return &Schema{Type: "number"}
+	case string:
+		return &Schema{Type: "string", Format: stringFormat(v)}
+	case []interface{}:
+		schema := &Schema{Type: "array"}
+		// The items of empty arrays stay unknown until merged with other values
+		for _, item := range v {
+			schema.Items = MergeSchemas(schema.Items, InferSchema(item))
+		}
+		return schema
+	case map[string]interface{}:
+		schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+		for name, property := range v {
+			schema.Properties[name] = InferSchema(property)
+			schema.Required = append(schema.Required, name)
+		}
+		sort.Strings(schema.Required)
+		return schema
+	}
+	return &Schema{}
+}
+
+// MergeSchemas combines two schemas observed for the same value, widening the
+// type where needed. Either schema may be nil.
+func MergeSchemas(a, b *Schema) *Schema {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+
+	merged := &Schema{Nullable: a.Nullable || b.Nullable}
+
+	// A null value only makes the other schema nullable
+	switch {
+	case a.Type == "":
+		merged.Type, merged.Format = b.Type, b.Format
+	case b.Type == "":
+		merged.Type, merged.Format = a.Type, a.Format
+	case a.Type == b.Type:
+		merged.Type = a.Type
+		if a.Format == b.Format {
+			merged.Format = a.Format
+		}
+	case isNumeric(a.Type) && isNumeric(b.Type):
+		merged.Type = "number"
+	default:
+		// Values of unrelated types leave the schema open
+		return &Schema{Nullable: merged.Nullable}
+	}
+	if (a.Type == "" && !a.Nullable) || (b.Type == "" && !b.Nullable) {
+		// An open schema stays open
+		return &Schema{Nullable: merged.Nullable}
+	}
+
+	switch merged.Type {
+	case "array":
+		merged.Items = MergeSchemas(a.Items, b.Items)
+	case "object":
+		merged.Properties = make(map[string]*Schema)
+		for name, property := range a.Properties {
+			merged.Properties[name] = property
+		}
+		for name, property := range b.Properties {
+			merged.Properties[name] = MergeSchemas(merged.Properties[name], property)
+		}
+		merged.Required = intersect(a, b)
+	}
+	return merged
+}
+
+// intersect returns the properties required by both object schemas, treating
+// a schema that is only a null value as requiring everything
+func intersect(a, b *Schema) []string {
+	if a.Type == "" {
+		return b.Required
+	}
+	if b.Type == "" {
+		return a.Required
+	}
+	inB := make(map[string]bool)
+	for _, name := range b.Required {
+		inB[name] = true
+	}
+	var required []string
+	for _, name := range a.Required {
+		if inB[name] {
+			required = append(required, name)
+		}
+	}
+	return required
+}
+
+// complete gives the arrays whose items were never observed an open items
+// schema, as OpenAPI requires one
+func (s *Schema) complete() {
+	if s == nil {
+		return
+	}
+	if s.Type == "array" && s.Items == nil {
+		s.Items = &Schema{}
+	}
+	s.Items.complete()
+	for _, property := range s.Properties {
+		property.complete()
+	}
+}
+
+// isNumeric reports whether a schema type is a number
+func isNumeric(schemaType string) bool {
+	return schemaType == "integer" || schemaType == "number"
+}
+
+// stringFormat detects the format of a string value
+func stringFormat(value string) string {
+	if _, err := time.Parse(time.RFC3339, value); err == nil {
+		return "date-time"
+	}
+	if _, err := time.Parse("2006-01-02", value); err == nil {
+		return "date"
+	}
+	switch {
+	case uuidPattern.MatchString(value):
+		return "uuid"
+	case emailPattern.MatchString(value):
+		return "email"
+	case uriPattern.MatchString(value):
+		return "uri"
+	}
+	return ""
+}
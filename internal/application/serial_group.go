@@ -0,0 +1,43 @@
+package application
+
+import (
+	"context"
+	"sync"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// serialGroups makes the requests of the same serial group run one at a
+// time, e.g. those sharing mutable fixtures, while other requests run in
+// parallel
+type serialGroups struct {
+	mu    sync.Mutex
+	slots map[string]chan struct{}
+}
+
+// acquire blocks until no other request of the request's serial group is
+// running, or the context is done, and returns the function letting the
+// next one run
+func (g *serialGroups) acquire(ctx context.Context, request *models.HTTPRequest) (func(), error) {
+	if request.SerialGroup == "" {
+		return func() {}, nil
+	}
+
+	g.mu.Lock()
+	if g.slots == nil {
+		g.slots = make(map[string]chan struct{})
+	}
+	slot, ok := g.slots[request.SerialGroup]
+	if !ok {
+		slot = make(chan struct{}, 1)
+		g.slots[request.SerialGroup] = slot
+	}
+	g.mu.Unlock()
+
+	select {
+	case slot <- struct{}{}:
+		return func() { <-slot }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
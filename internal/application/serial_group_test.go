@@ -0,0 +1,38 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSerialGroupsAcquire(t *testing.T) {
+	var groups serialGroups
+	inventory := &models.HTTPRequest{SerialGroup: "inventory"}
+
+	release, err := groups.acquire(context.Background(), inventory)
+	require.NoError(t, err)
+
+	// Other groups and ungrouped requests do not wait
+	other, err := groups.acquire(context.Background(), &models.HTTPRequest{SerialGroup: "orders"})
+	require.NoError(t, err)
+	other()
+	free, err := groups.acquire(context.Background(), &models.HTTPRequest{})
+	require.NoError(t, err)
+	free()
+
+	// The same group waits until released
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = groups.acquire(ctx, inventory)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	release()
+	release, err = groups.acquire(context.Background(), inventory)
+	require.NoError(t, err)
+	release()
+}
@@ -1,11 +1,18 @@
 package snapshot
 
 import (
+	"bytes"
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/edgardnogueira/swagger-to-http/internal/version"
 	"github.com/sergi/go-diff/diffmatchpatch"
 )
 
@@ -30,6 +37,53 @@ type ComparisonResult struct {
 	BodyMatch    bool
 }
 
+// CompareOptions configures how responses are compared
+type CompareOptions struct {
+	// IgnoreArrayOrder sorts JSON arrays canonically before comparison
+	IgnoreArrayOrder bool
+
+	// ArrayOrderKey sorts arrays of objects by this field instead of the whole element
+	ArrayOrderKey string
+
+	// Tolerances allow numeric fields matching a path pattern to differ slightly
+	Tolerances map[string]models.Tolerance
+
+	// Scope selects the parts of the responses compared, all when empty
+	Scope models.SnapshotScope
+
+	// IgnoreHeaders matches the headers left out of the comparison, the
+	// DefaultIgnoredHeaders when nil
+	IgnoreHeaders *models.HeaderMatcher
+}
+
+// CompareScoped compares two HTTP responses with a formatter, leaving out the
+// parts of the responses outside the scope
+func CompareScoped(formatter ResponseFormatter, expected, actual *models.HTTPResponse, scope models.SnapshotScope) (*ComparisonResult, error) {
+	if scope == models.SnapshotScopeAll {
+		return formatter.Compare(expected, actual)
+	}
+
+	// Parts left out are taken from the snapshot so that they match
+	scoped := *actual
+	if !scope.ComparesStatus() {
+		scoped.StatusCode = expected.StatusCode
+	}
+	if !scope.ComparesBody() {
+		scoped.Body = expected.Body
+	}
+
+	result, err := formatter.Compare(expected, &scoped)
+	if err != nil {
+		return nil, err
+	}
+	if !scope.ComparesHeaders() && !result.HeadersMatch {
+		result.HeadersMatch = true
+		result.Diff = strings.Replace(result.Diff, "Headers mismatch\n", "", 1)
+	}
+	result.Matches = result.StatusMatch && result.HeadersMatch && result.BodyMatch
+	return result, nil
+}
+
 // formatters is a map of content types to formatters
 var formatters = map[string]ResponseFormatter{
 	"json":    &JSONFormatter{},
@@ -72,8 +126,49 @@ func GetFormatter(contentType string) (ResponseFormatter, error) {
 	return formatter, nil
 }
 
+// GetFormatterWithOptions returns a formatter for the content type configured with comparison options
+func GetFormatterWithOptions(contentType string, options CompareOptions) (ResponseFormatter, error) {
+	formatter, err := GetFormatter(contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	base := BaseFormatter{IgnoreHeaders: options.IgnoreHeaders}
+	switch formatter.(type) {
+	case *JSONFormatter:
+		return &JSONFormatter{BaseFormatter: base, Options: options}, nil
+	case *XMLFormatter:
+		return &XMLFormatter{BaseFormatter: base}, nil
+	case *TextFormatter:
+		return &TextFormatter{BaseFormatter: base}, nil
+	case *HTMLFormatter:
+		return &HTMLFormatter{BaseFormatter: base}, nil
+	case *BinaryFormatter:
+		return &BinaryFormatter{BaseFormatter: base}, nil
+	case *DefaultFormatter:
+		return &DefaultFormatter{BaseFormatter: base}, nil
+	}
+
+	return formatter, nil
+}
+
+// defaultIgnoreHeaders matches the DefaultIgnoredHeaders
+var defaultIgnoreHeaders, _ = models.NewHeaderMatcher(models.DefaultIgnoredHeaders)
+
 // BaseFormatter provides common functionality for all formatters
-type BaseFormatter struct{}
+type BaseFormatter struct {
+	// IgnoreHeaders matches the headers left out of comparisons, the
+	// DefaultIgnoredHeaders when nil
+	IgnoreHeaders *models.HeaderMatcher
+}
+
+// ignoresHeader reports whether a header is left out of comparisons
+func (f *BaseFormatter) ignoresHeader(name string) bool {
+	if f.IgnoreHeaders == nil {
+		return defaultIgnoreHeaders.Matches(name)
+	}
+	return f.IgnoreHeaders.Matches(name)
+}
 
 // Format formats the response headers and metadata
 func (f *BaseFormatter) formatHeaders(response *models.HTTPResponse) string {
@@ -83,7 +178,7 @@ func (f *BaseFormatter) formatHeaders(response *models.HTTPResponse) string {
 	sb.WriteString(fmt.Sprintf("HTTP %d %s\n", response.StatusCode, response.Status))
 	
 	// Add headers
-	for key, values := range response.Headers {
+	for key, values := range snapshotHeaders(response) {
 		for _, value := range values {
 			sb.WriteString(fmt.Sprintf("%s: %s\n", key, value))
 		}
@@ -95,6 +190,112 @@ func (f *BaseFormatter) formatHeaders(response *models.HTTPResponse) string {
 	return sb.String()
 }
 
+// InitialStatusHeader is the pseudo-header recording the status of the 202
+// Accepted response that started a polled asynchronous operation
+const InitialStatusHeader = "Initial-Status"
+
+// OriginalEncodingHeader is the pseudo-header recording the content encoding
+// of a body that was decompressed before it was snapshotted
+const OriginalEncodingHeader = "Original-Encoding"
+
+// FinalURLHeader is the pseudo-header recording the URL of a response reached
+// through redirects
+const FinalURLHeader = "Final-URL"
+
+// snapshotHeaders returns the headers of a response as snapshotted, with the
+// initial status of an asynchronous operation, the original encoding of a
+// decompressed body and the final URL of a redirected request
+func snapshotHeaders(response *models.HTTPResponse) map[string][]string {
+	decompressed := response.ContentEncoding != "" && http.Header(response.Headers).Get("Content-Encoding") == ""
+	redirected := len(response.Redirects) > 0 && response.URL != ""
+	if response.InitialResponse == nil && !decompressed && !redirected {
+		return response.Headers
+	}
+	headers := make(map[string][]string, len(response.Headers)+3)
+	for key, values := range response.Headers {
+		headers[key] = values
+	}
+	if response.InitialResponse != nil {
+		headers[InitialStatusHeader] = []string{strconv.Itoa(response.InitialResponse.StatusCode)}
+	}
+	if decompressed {
+		headers[OriginalEncodingHeader] = []string{response.ContentEncoding}
+	}
+	if redirected {
+		headers[FinalURLHeader] = []string{response.URL}
+	}
+	return headers
+}
+
+// requestCommentPrefix starts the comment lines recording the resolved
+// request of a snapshot, between the version stamp and the status line
+const requestCommentPrefix = "# >"
+
+// FormatRequestComment formats the resolved request of a response as the
+// comment lines of a snapshot, empty when it isn't known
+func FormatRequestComment(response *models.HTTPResponse) string {
+	if response.ResolvedRequest == nil {
+		return ""
+	}
+	var sb strings.Builder
+	for _, line := range strings.Split(strings.TrimSuffix(response.ResolvedRequest.String(), "\n"), "\n") {
+		if line == "" {
+			sb.WriteString(requestCommentPrefix + "\n")
+			continue
+		}
+		sb.WriteString(requestCommentPrefix + " " + line + "\n")
+	}
+	return sb.String()
+}
+
+// parseRequestComment parses the comment lines of a resolved request, without
+// their prefix
+func parseRequestComment(lines []string) *models.ResolvedRequest {
+	if len(lines) == 0 {
+		return nil
+	}
+	method, url, _ := strings.Cut(lines[0], " ")
+	request := &models.ResolvedRequest{Method: method, URL: url}
+
+	i := 1
+	for ; i < len(lines) && lines[i] != ""; i++ {
+		name, value, found := strings.Cut(lines[i], ":")
+		if !found {
+			continue
+		}
+		if request.Headers == nil {
+			request.Headers = make(map[string][]string)
+		}
+		request.Headers[name] = append(request.Headers[name], strings.TrimSpace(value))
+	}
+	if i < len(lines) {
+		request.Body = strings.Join(lines[i+1:], "\n")
+	}
+	return request
+}
+
+// DescribeRequests describes the request that produced a response whose
+// snapshot didn't match, and the one recorded in the snapshot when it differs
+func DescribeRequests(snapshotted, current *models.ResolvedRequest) string {
+	if current == nil {
+		return ""
+	}
+	description := "Request:\n" + indentLines(current.String())
+	if snapshotted != nil && snapshotted.String() != current.String() {
+		description += "Snapshot request:\n" + indentLines(snapshotted.String())
+	}
+	return description
+}
+
+// indentLines indents each line of a text by two spaces
+func indentLines(text string) string {
+	var sb strings.Builder
+	for _, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		sb.WriteString("  " + line + "\n")
+	}
+	return sb.String()
+}
+
 // parseHeaders parses the response headers and metadata
 func (f *BaseFormatter) parseHeaders(content string) (*models.HTTPResponse, string, error) {
 	response := &models.HTTPResponse{
@@ -105,6 +306,21 @@ func (f *BaseFormatter) parseHeaders(content string) (*models.HTTPResponse, stri
 	lines := strings.Split(content, "\n")
 	lineIdx := 0
 
+	// Skip the version stamp comment if present
+	if lineIdx < len(lines) {
+		if _, ok := version.ParseStamp(lines[lineIdx]); ok {
+			lineIdx++
+		}
+	}
+
+	// Parse the resolved request recorded in comment lines
+	var requestLines []string
+	for lineIdx < len(lines) && strings.HasPrefix(lines[lineIdx], requestCommentPrefix) {
+		requestLines = append(requestLines, strings.TrimPrefix(strings.TrimPrefix(lines[lineIdx], requestCommentPrefix), " "))
+		lineIdx++
+	}
+	response.ResolvedRequest = parseRequestComment(requestLines)
+
 	// Parse the status line
 	if lineIdx < len(lines) {
 		statusLine := lines[lineIdx]
@@ -158,20 +374,13 @@ func (f *BaseFormatter) createDiff(expected, actual string) string {
 	return dmp.DiffPrettyText(diffs)
 }
 
-// compareHeaders compares two sets of headers, ignoring unimportant ones
+// compareHeaders compares two sets of headers, leaving out the ignored ones
+// and matching the values given as patterns
 func (f *BaseFormatter) compareHeaders(expected, actual map[string][]string) bool {
-	// Headers to ignore in comparison
-	ignoreHeaders := map[string]bool{
-		"Date":           true,
-		"Content-Length": true,
-		"Server":         true,
-		"Connection":     true,
-	}
-
 	// Check if all expected headers are in actual
 	for key, expectedValues := range expected {
 		// Skip ignored headers
-		if ignoreHeaders[key] {
+		if f.ignoresHeader(key) {
 			continue
 		}
 
@@ -180,8 +389,9 @@ func (f *BaseFormatter) compareHeaders(expected, actual map[string][]string) boo
 			return false
 		}
 
-		// Compare header values
-		if !equalStringSlices(expectedValues, actualValues) {
+		// Compare header values, which the snapshot may give as patterns
+		// such as "<<uuid>>"; an invalid pattern never matches
+		if matched, err := models.MatchHeaderValues(expectedValues, actualValues); err != nil || !matched {
 			return false
 		}
 	}
@@ -189,7 +399,7 @@ func (f *BaseFormatter) compareHeaders(expected, actual map[string][]string) boo
 	// Check if actual has additional headers not in expected
 	for key := range actual {
 		// Skip ignored headers
-		if ignoreHeaders[key] {
+		if f.ignoresHeader(key) {
 			continue
 		}
 
@@ -202,24 +412,10 @@ func (f *BaseFormatter) compareHeaders(expected, actual map[string][]string) boo
 	return true
 }
 
-// equalStringSlices compares two string slices for equality
-func equalStringSlices(a, b []string) bool {
-	if len(a) != len(b) {
-		return false
-	}
-
-	for i := range a {
-		if a[i] != b[i] {
-			return false
-		}
-	}
-
-	return true
-}
-
 // JSONFormatter formats JSON responses
 type JSONFormatter struct {
 	BaseFormatter
+	Options CompareOptions
 }
 
 // Format converts an HTTP response to a string representation
@@ -292,7 +488,7 @@ func (f *JSONFormatter) Compare(expected, actual *models.HTTPResponse) (*Compari
 	}
 
 	// Compare headers
-	result.HeadersMatch = f.compareHeaders(expected.Headers, actual.Headers)
+	result.HeadersMatch = f.compareHeaders(expected.Headers, snapshotHeaders(actual))
 	if !result.HeadersMatch {
 		result.Matches = false
 		result.Diff += "Headers mismatch\n"
@@ -307,6 +503,17 @@ func (f *JSONFormatter) Compare(expected, actual *models.HTTPResponse) (*Compari
 		actualErr := json.Unmarshal([]byte(actual.Body), &actualJSON)
 
 		if expectedErr == nil && actualErr == nil {
+			// Sort arrays in both bodies when their order doesn't matter
+			if f.Options.IgnoreArrayOrder {
+				expectedJSON = sortArrays(expectedJSON, f.Options.ArrayOrderKey)
+				actualJSON = sortArrays(actualJSON, f.Options.ArrayOrderKey)
+			}
+
+			// Treat numbers within tolerance as equal
+			if len(f.Options.Tolerances) > 0 {
+				actualJSON = applyTolerances(expectedJSON, actualJSON, "$", f.Options.Tolerances)
+			}
+
 			// Both are valid JSON, compare as objects
 			expectedBytes, _ := json.MarshalIndent(expectedJSON, "", "  ")
 			actualBytes, _ := json.MarshalIndent(actualJSON, "", "  ")
@@ -338,6 +545,107 @@ func (f *JSONFormatter) Compare(expected, actual *models.HTTPResponse) (*Compari
 	return result, nil
 }
 
+// sortArrays returns a copy of a JSON value with every array sorted canonically: arrays
+// of objects by the value of key when set, and otherwise by the element's JSON encoding
+func sortArrays(value interface{}, key string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			result[k] = sortArrays(item, key)
+		}
+		return result
+	case []interface{}:
+		type sortable struct {
+			key      string
+			encoding string
+			value    interface{}
+		}
+
+		items := make([]sortable, len(v))
+		for i, item := range v {
+			sorted := sortArrays(item, key)
+			encoding, _ := json.Marshal(sorted)
+			items[i] = sortable{key: arraySortKey(sorted, key), encoding: string(encoding), value: sorted}
+		}
+		sort.SliceStable(items, func(i, j int) bool {
+			if items[i].key != items[j].key {
+				return items[i].key < items[j].key
+			}
+			return items[i].encoding < items[j].encoding
+		})
+
+		result := make([]interface{}, len(items))
+		for i, item := range items {
+			result[i] = item.value
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+// applyTolerances returns a copy of actual where numbers within the tolerance of the
+// expected value at the same path are replaced by the expected value
+func applyTolerances(expected, actual interface{}, path string, tolerances map[string]models.Tolerance) interface{} {
+	switch a := actual.(type) {
+	case map[string]interface{}:
+		e, ok := expected.(map[string]interface{})
+		if !ok {
+			return actual
+		}
+		result := make(map[string]interface{}, len(a))
+		for k, v := range a {
+			result[k] = applyTolerances(e[k], v, path+"."+k, tolerances)
+		}
+		return result
+	case []interface{}:
+		e, ok := expected.([]interface{})
+		if !ok {
+			return actual
+		}
+		result := make([]interface{}, len(a))
+		for i, v := range a {
+			if i < len(e) {
+				result[i] = applyTolerances(e[i], v, fmt.Sprintf("%s[%d]", path, i), tolerances)
+			} else {
+				result[i] = v
+			}
+		}
+		return result
+	case float64:
+		e, ok := expected.(float64)
+		if !ok || e == a {
+			return actual
+		}
+		for pattern, tolerance := range tolerances {
+			if models.MatchFieldPath(pattern, path) && tolerance.Within(e, a) {
+				return e
+			}
+		}
+		return actual
+	default:
+		return actual
+	}
+}
+
+// arraySortKey returns the value of the key field of an object element, if present
+func arraySortKey(item interface{}, key string) string {
+	if key == "" {
+		return ""
+	}
+	object, ok := item.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	value, ok := object[key]
+	if !ok {
+		return ""
+	}
+	encoding, _ := json.Marshal(value)
+	return string(encoding)
+}
+
 // XMLFormatter formats XML responses
 type XMLFormatter struct {
 	BaseFormatter
@@ -384,25 +692,110 @@ func (f *XMLFormatter) Compare(expected, actual *models.HTTPResponse) (*Comparis
 	}
 
 	// Compare headers
-	result.HeadersMatch = f.compareHeaders(expected.Headers, actual.Headers)
+	result.HeadersMatch = f.compareHeaders(expected.Headers, snapshotHeaders(actual))
 	if !result.HeadersMatch {
 		result.Matches = false
 		result.Diff += "Headers mismatch\n"
 	}
 
-	// Compare bodies
-	if expected.Body == actual.Body {
+	// Compare bodies, ignoring indentation when both are well-formed
+	expectedBody, actualBody := expected.Body, actual.Body
+	if normalizedExpected, err := normalizeXML(expectedBody); err == nil {
+		if normalizedActual, err := normalizeXML(actualBody); err == nil {
+			expectedBody, actualBody = normalizedExpected, normalizedActual
+		}
+	}
+	if expectedBody == actualBody {
 		result.BodyMatch = true
 	} else {
 		result.BodyMatch = false
 		result.Matches = false
 		result.Diff += "XML body mismatch:\n"
-		result.Diff += f.createDiff(expected.Body, actual.Body)
+		result.Diff += f.createDiff(expectedBody, actualBody)
 	}
 
 	return result, nil
 }
 
+// normalizeXML re-indents an XML document, one element per line and dropping
+// the whitespace between elements, so documents differing only in formatting
+// compare equal. Prefixes are kept as written.
+func normalizeXML(body string) (string, error) {
+	decoder := xml.NewDecoder(strings.NewReader(body))
+	var tokens []xml.Token
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		if data, ok := token.(xml.CharData); ok && len(bytes.TrimSpace(data)) == 0 {
+			continue
+		}
+		tokens = append(tokens, xml.CopyToken(token))
+	}
+
+	var sb strings.Builder
+	depth := 0
+	for i := 0; i < len(tokens); i++ {
+		indent := strings.Repeat("  ", depth)
+		switch t := tokens[i].(type) {
+		case xml.StartElement:
+			sb.WriteString(indent + "<" + xmlName(t.Name))
+			for _, attr := range t.Attr {
+				var value strings.Builder
+				xml.EscapeText(&value, []byte(attr.Value))
+				sb.WriteString(" " + xmlName(attr.Name) + `="` + value.String() + `"`)
+			}
+			sb.WriteString(">")
+
+			// Keep elements holding only text on a single line
+			if i+1 < len(tokens) {
+				if _, ok := tokens[i+1].(xml.EndElement); ok {
+					sb.WriteString("</" + xmlName(t.Name) + ">\n")
+					i++
+					continue
+				}
+			}
+			if i+2 < len(tokens) {
+				data, isText := tokens[i+1].(xml.CharData)
+				if _, isEnd := tokens[i+2].(xml.EndElement); isText && isEnd {
+					xml.EscapeText(&sb, bytes.TrimSpace(data))
+					sb.WriteString("</" + xmlName(t.Name) + ">\n")
+					i += 2
+					continue
+				}
+			}
+			sb.WriteString("\n")
+			depth++
+		case xml.EndElement:
+			depth--
+			sb.WriteString(strings.Repeat("  ", depth) + "</" + xmlName(t.Name) + ">\n")
+		case xml.CharData:
+			sb.WriteString(indent)
+			xml.EscapeText(&sb, bytes.TrimSpace(t))
+			sb.WriteString("\n")
+		case xml.Comment:
+			sb.WriteString(indent + "<!--" + string(t) + "-->\n")
+		case xml.ProcInst:
+			sb.WriteString(indent + "<?" + t.Target + " " + string(t.Inst) + "?>\n")
+		case xml.Directive:
+			sb.WriteString(indent + "<!" + string(t) + ">\n")
+		}
+	}
+	return sb.String(), nil
+}
+
+// xmlName formats a name as written, with its prefix
+func xmlName(name xml.Name) string {
+	if name.Space != "" {
+		return name.Space + ":" + name.Local
+	}
+	return name.Local
+}
+
 // TextFormatter formats plain text responses
 type TextFormatter struct {
 	BaseFormatter
@@ -449,7 +842,7 @@ func (f *TextFormatter) Compare(expected, actual *models.HTTPResponse) (*Compari
 	}
 
 	// Compare headers
-	result.HeadersMatch = f.compareHeaders(expected.Headers, actual.Headers)
+	result.HeadersMatch = f.compareHeaders(expected.Headers, snapshotHeaders(actual))
 	if !result.HeadersMatch {
 		result.Matches = false
 		result.Diff += "Headers mismatch\n"
@@ -514,7 +907,7 @@ func (f *HTMLFormatter) Compare(expected, actual *models.HTTPResponse) (*Compari
 	}
 
 	// Compare headers
-	result.HeadersMatch = f.compareHeaders(expected.Headers, actual.Headers)
+	result.HeadersMatch = f.compareHeaders(expected.Headers, snapshotHeaders(actual))
 	if !result.HeadersMatch {
 		result.Matches = false
 		result.Diff += "Headers mismatch\n"
@@ -580,7 +973,7 @@ func (f *BinaryFormatter) Compare(expected, actual *models.HTTPResponse) (*Compa
 	}
 
 	// Compare headers
-	result.HeadersMatch = f.compareHeaders(expected.Headers, actual.Headers)
+	result.HeadersMatch = f.compareHeaders(expected.Headers, snapshotHeaders(actual))
 	if !result.HeadersMatch {
 		result.Matches = false
 		result.Diff += "Headers mismatch\n"
@@ -648,7 +1041,7 @@ func (f *DefaultFormatter) Compare(expected, actual *models.HTTPResponse) (*Comp
 	}
 
 	// Compare headers
-	result.HeadersMatch = f.compareHeaders(expected.Headers, actual.Headers)
+	result.HeadersMatch = f.compareHeaders(expected.Headers, snapshotHeaders(actual))
 	if !result.HeadersMatch {
 		result.Matches = false
 		result.Diff += "Headers mismatch\n"
@@ -0,0 +1,48 @@
+package snapshot
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// benchmarkJSONBody returns a JSON list of n users, with the name of the last
+// one changed when changed is set
+func benchmarkJSONBody(n int, changed bool) string {
+	users := make([]string, n)
+	for i := range users {
+		name := fmt.Sprintf("user %d", i)
+		if changed && i == n-1 {
+			name = "renamed"
+		}
+		users[i] = fmt.Sprintf(`{"id":%d,"name":%q,"active":true,"roles":["admin","user"],"address":{"city":"Lisbon","zip":"1000-001"}}`, i, name)
+	}
+	return `{"total":` + fmt.Sprint(n) + `,"users":[` + strings.Join(users, ",") + `]}`
+}
+
+func BenchmarkJSONFormatter_Compare(b *testing.B) {
+	headers := map[string][]string{"Content-Type": {"application/json"}}
+	expected := &models.HTTPResponse{StatusCode: 200, Headers: headers, Body: benchmarkJSONBody(500, false)}
+
+	for _, bench := range []struct {
+		name      string
+		formatter *JSONFormatter
+		actual    string
+	}{
+		{"equal", &JSONFormatter{}, benchmarkJSONBody(500, false)},
+		{"changed", &JSONFormatter{}, benchmarkJSONBody(500, true)},
+		{"ignore array order", &JSONFormatter{Options: CompareOptions{IgnoreArrayOrder: true, ArrayOrderKey: "id"}}, benchmarkJSONBody(500, false)},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			actual := &models.HTTPResponse{StatusCode: 200, Headers: headers, Body: bench.actual}
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := bench.formatter.Compare(expected, actual); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,304 @@
+package snapshot
+
+import (
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/edgardnogueira/swagger-to-http/internal/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestCommentRoundTrip(t *testing.T) {
+	request := models.NewResolvedRequest("POST", "https://api.example.com/users", map[string][]string{
+		"Authorization": {"Bearer secret"},
+		"Content-Type":  {"application/json"},
+	}, "{\n  \"name\": \"Ada\"\n}")
+	response := &models.HTTPResponse{
+		StatusCode:      201,
+		Status:          "Created",
+		Headers:         map[string][]string{"Content-Type": {"application/json"}},
+		Body:            `{"id":1}`,
+		ResolvedRequest: request,
+	}
+
+	comment := FormatRequestComment(response)
+	assert.Equal(t, "# > POST https://api.example.com/users\n"+
+		"# > Authorization: Bearer ***\n"+
+		"# > Content-Type: application/json\n"+
+		"# >\n"+
+		"# > {\n"+
+		"# >   \"name\": \"Ada\"\n"+
+		"# > }\n", comment)
+
+	formatter := &JSONFormatter{}
+	formatted, err := formatter.Format(response)
+	require.NoError(t, err)
+	parsed, err := formatter.Parse("# " + version.NewStamp("").String() + "\n" + comment + formatted)
+	require.NoError(t, err)
+	assert.Equal(t, request, parsed.ResolvedRequest)
+	assert.Equal(t, 201, parsed.StatusCode)
+
+	// Snapshots without a recorded request still parse
+	parsed, err = formatter.Parse(formatted)
+	require.NoError(t, err)
+	assert.Nil(t, parsed.ResolvedRequest)
+}
+
+func TestDescribeRequests(t *testing.T) {
+	current := models.NewResolvedRequest("GET", "https://api.example.com/users?page=2", nil, "")
+	assert.Equal(t, "Request:\n  GET https://api.example.com/users?page=2\n", DescribeRequests(current, current))
+
+	snapshotted := models.NewResolvedRequest("GET", "https://api.example.com/users?page=1", nil, "")
+	assert.Equal(t, "Request:\n  GET https://api.example.com/users?page=2\n"+
+		"Snapshot request:\n  GET https://api.example.com/users?page=1\n", DescribeRequests(snapshotted, current))
+
+	assert.Empty(t, DescribeRequests(snapshotted, nil))
+}
+
+func TestCompareScoped(t *testing.T) {
+	expected := &models.HTTPResponse{
+		StatusCode: 200,
+		Headers:    map[string][]string{"Content-Type": {"application/json"}, "X-Version": {"1"}},
+		Body:       `{"id":1}`,
+	}
+	actual := &models.HTTPResponse{
+		StatusCode: 200,
+		Headers:    map[string][]string{"Content-Type": {"application/json"}, "X-Version": {"2"}},
+		Body:       `{"id":1}`,
+	}
+	formatter := &JSONFormatter{}
+
+	result, err := CompareScoped(formatter, expected, actual, models.SnapshotScopeAll)
+	require.NoError(t, err)
+	assert.False(t, result.Matches)
+
+	result, err = CompareScoped(formatter, expected, actual, models.SnapshotScopeBodyOnly)
+	require.NoError(t, err)
+	assert.True(t, result.Matches)
+	assert.Empty(t, result.Diff)
+
+	actual.StatusCode = 500
+	actual.Body = `{"error":"boom"}`
+	result, err = CompareScoped(formatter, expected, actual, models.SnapshotScopeHeadersOnly)
+	require.NoError(t, err)
+	assert.False(t, result.Matches)
+	assert.Equal(t, "Headers mismatch\n", result.Diff)
+
+	result, err = CompareScoped(formatter, expected, actual, models.SnapshotScopeStatusOnly)
+	require.NoError(t, err)
+	assert.False(t, result.Matches)
+	assert.Equal(t, "Status code mismatch: expected 200, got 500\n", result.Diff)
+	assert.Equal(t, 500, actual.StatusCode)
+}
+
+func TestIgnoreHeaders(t *testing.T) {
+	expected := &models.HTTPResponse{
+		StatusCode: 200,
+		Headers:    map[string][]string{"Content-Type": {"text/plain"}, "Date": {"Mon"}, "X-Trace-Id": {"a"}},
+		Body:       "ok",
+	}
+	actual := &models.HTTPResponse{
+		StatusCode: 200,
+		Headers:    map[string][]string{"Content-Type": {"text/plain"}, "Date": {"Tue"}, "X-Trace-Id": {"b"}},
+		Body:       "ok",
+	}
+
+	formatter, err := GetFormatterWithOptions("text/plain", CompareOptions{})
+	require.NoError(t, err)
+	result, err := formatter.Compare(expected, actual)
+	require.NoError(t, err)
+	assert.False(t, result.Matches, "X-Trace-Id isn't ignored by default")
+
+	matcher, err := models.NewHeaderMatcher([]string{"Date", "x-trace-.*"})
+	require.NoError(t, err)
+	formatter, err = GetFormatterWithOptions("text/plain", CompareOptions{IgnoreHeaders: matcher})
+	require.NoError(t, err)
+	result, err = formatter.Compare(expected, actual)
+	require.NoError(t, err)
+	assert.True(t, result.Matches)
+
+	matcher, err = models.NewHeaderMatcher([]string{"X-Trace-Id"})
+	require.NoError(t, err)
+	formatter, err = GetFormatterWithOptions("text/plain", CompareOptions{IgnoreHeaders: matcher})
+	require.NoError(t, err)
+	result, err = formatter.Compare(expected, actual)
+	require.NoError(t, err)
+	assert.False(t, result.Matches, "Date is compared once left out of the patterns")
+}
+
+func TestCompareHeaderPatterns(t *testing.T) {
+	expected := &models.HTTPResponse{
+		StatusCode: 200,
+		Headers:    map[string][]string{"X-Request-Id": {"<<uuid>>"}, "Cache-Control": {`~max-age=\d+`}},
+		Body:       "ok",
+	}
+	actual := &models.HTTPResponse{
+		StatusCode: 200,
+		Headers:    map[string][]string{"X-Request-Id": {"123e4567-e89b-12d3-a456-426614174000"}, "Cache-Control": {"max-age=60"}},
+		Body:       "ok",
+	}
+	formatter := &TextFormatter{}
+
+	result, err := formatter.Compare(expected, actual)
+	require.NoError(t, err)
+	assert.True(t, result.Matches)
+
+	actual.Headers["X-Request-Id"] = []string{"req-1"}
+	result, err = formatter.Compare(expected, actual)
+	require.NoError(t, err)
+	assert.False(t, result.HeadersMatch)
+}
+
+func TestApplyTolerances(t *testing.T) {
+	expected := map[string]interface{}{
+		"total": 10.0,
+		"count": 3.0,
+		"items": []interface{}{
+			map[string]interface{}{"price": 1.0},
+			map[string]interface{}{"price": 2.0},
+		},
+	}
+	actual := map[string]interface{}{
+		"total": 10.004,
+		"count": 3.001,
+		"items": []interface{}{
+			map[string]interface{}{"price": 1.2},
+			map[string]interface{}{"price": 2.05},
+			map[string]interface{}{"price": 3.0},
+		},
+	}
+	tolerances := map[string]models.Tolerance{
+		"total":            {Absolute: 0.01},
+		"$.items[*].price": {Relative: 0.05},
+	}
+
+	// Numbers within the tolerance of their path take the expected value
+	assert.Equal(t, map[string]interface{}{
+		"total": 10.0,
+		"count": 3.001,
+		"items": []interface{}{
+			map[string]interface{}{"price": 1.2},
+			map[string]interface{}{"price": 2.0},
+			map[string]interface{}{"price": 3.0},
+		},
+	}, applyTolerances(expected, actual, "$", tolerances))
+
+	// Values of other types are left as they are
+	assert.Equal(t, "10", applyTolerances(10.0, "10", "$", tolerances))
+	assert.Equal(t, 10.004, applyTolerances("10", 10.004, "$.total", tolerances))
+}
+
+func TestCompareTolerances(t *testing.T) {
+	expected := &models.HTTPResponse{StatusCode: 200, Body: `{"total": 10.0, "count": 3}`}
+	actual := &models.HTTPResponse{StatusCode: 200, Body: `{"total": 10.004, "count": 3}`}
+
+	result, err := (&JSONFormatter{}).Compare(expected, actual)
+	require.NoError(t, err)
+	assert.False(t, result.BodyMatch)
+
+	formatter := &JSONFormatter{Options: CompareOptions{Tolerances: map[string]models.Tolerance{"$.total": {Absolute: 0.01}}}}
+	result, err = formatter.Compare(expected, actual)
+	require.NoError(t, err)
+	assert.True(t, result.BodyMatch)
+
+	// The snapshot managers pass tolerances through the formatter options
+	configured, err := GetFormatterWithOptions("application/json; charset=utf-8", CompareOptions{Tolerances: map[string]models.Tolerance{"total": {Relative: 0.001}}})
+	require.NoError(t, err)
+	result, err = configured.Compare(expected, actual)
+	require.NoError(t, err)
+	assert.True(t, result.Matches)
+
+	actual.Body = `{"total": 10.02, "count": 3}`
+	result, err = configured.Compare(expected, actual)
+	require.NoError(t, err)
+	assert.False(t, result.BodyMatch)
+}
+
+func TestSortArrays(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		key      string
+		expected interface{}
+	}{
+		{
+			name:     "scalars",
+			value:    []interface{}{3.0, 1.0, 2.0},
+			expected: []interface{}{1.0, 2.0, 3.0},
+		},
+		{
+			name:     "mixed types",
+			value:    []interface{}{"b", 2.0, true, nil, map[string]interface{}{"id": 1.0}, "a", []interface{}{1.0}},
+			expected: []interface{}{"a", "b", 2.0, []interface{}{1.0}, nil, true, map[string]interface{}{"id": 1.0}},
+		},
+		{
+			name: "nested arrays",
+			value: []interface{}{
+				[]interface{}{3.0, 2.0},
+				[]interface{}{1.0, 0.0},
+			},
+			expected: []interface{}{
+				[]interface{}{0.0, 1.0},
+				[]interface{}{2.0, 3.0},
+			},
+		},
+		{
+			name: "arrays in objects",
+			value: map[string]interface{}{
+				"tags":  []interface{}{"b", "a"},
+				"users": []interface{}{map[string]interface{}{"roles": []interface{}{"write", "read"}}},
+			},
+			expected: map[string]interface{}{
+				"tags":  []interface{}{"a", "b"},
+				"users": []interface{}{map[string]interface{}{"roles": []interface{}{"read", "write"}}},
+			},
+		},
+		{
+			name: "objects by key",
+			value: []interface{}{
+				map[string]interface{}{"id": 2.0, "name": "a"},
+				map[string]interface{}{"name": "c"},
+				map[string]interface{}{"id": 1.0, "name": "b"},
+			},
+			key: "id",
+			expected: []interface{}{
+				map[string]interface{}{"name": "c"},
+				map[string]interface{}{"id": 1.0, "name": "b"},
+				map[string]interface{}{"id": 2.0, "name": "a"},
+			},
+		},
+		{
+			name: "objects by encoding",
+			value: []interface{}{
+				map[string]interface{}{"id": 2.0, "name": "a"},
+				map[string]interface{}{"id": 1.0, "name": "b"},
+			},
+			expected: []interface{}{
+				map[string]interface{}{"id": 1.0, "name": "b"},
+				map[string]interface{}{"id": 2.0, "name": "a"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, sortArrays(tt.value, tt.key))
+		})
+	}
+}
+
+func TestCompareIgnoreArrayOrder(t *testing.T) {
+	expected := &models.HTTPResponse{StatusCode: 200, Body: `{"items": [{"id": 1, "tags": ["a", "b"]}, {"id": 2, "tags": []}]}`}
+	actual := &models.HTTPResponse{StatusCode: 200, Body: `{"items": [{"id": 2, "tags": []}, {"id": 1, "tags": ["b", "a"]}]}`}
+
+	result, err := (&JSONFormatter{}).Compare(expected, actual)
+	require.NoError(t, err)
+	assert.False(t, result.BodyMatch)
+
+	for _, options := range []CompareOptions{{IgnoreArrayOrder: true}, {IgnoreArrayOrder: true, ArrayOrderKey: "id"}} {
+		result, err = (&JSONFormatter{Options: options}).Compare(expected, actual)
+		require.NoError(t, err)
+		assert.True(t, result.BodyMatch, "%+v", options)
+	}
+}
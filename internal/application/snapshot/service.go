@@ -12,7 +12,7 @@ import (
 
 // Service provides high-level snapshot testing functionality
 type Service struct {
-	manager      *Manager
+	manager      Manager
 	options      models.SnapshotOptions
 	usedSnapshots map[string]bool
 	mu           sync.Mutex
@@ -20,7 +20,7 @@ type Service struct {
 }
 
 // NewService creates a new snapshot service
-func NewService(manager *Manager, options models.SnapshotOptions) *Service {
+func NewService(manager Manager, options models.SnapshotOptions) *Service {
 	return &Service{
 		manager:      manager,
 		options:      options,
@@ -53,14 +53,14 @@ func (s *Service) RunTest(ctx context.Context, response *models.HTTPResponse, pa
 	}
 	
 	// Compare with snapshot
-	diff, err := s.manager.CompareWithSnapshot(ctx, response, snapshotPath)
+	comparison, err := s.manager.CompareSnapshots(response, snapshotPath, response.ContentType)
 	if err != nil {
 		if s.options.UpdateMode == "all" || s.options.UpdateMode == "missing" {
 			// Create new snapshot
-			if createErr := s.manager.SaveSnapshot(ctx, response, snapshotPath); createErr != nil {
-				result.Error = fmt.Errorf("failed to create snapshot: %w", createErr)
+			if createErr := s.manager.SaveSnapshot(response, snapshotPath, response.ContentType); createErr != nil {
+				result.Error = fmt.Sprintf("failed to create snapshot: %v", createErr)
 				s.stats.Errors++
-				return result, result.Error
+				return result, fmt.Errorf("failed to create snapshot: %w", createErr)
 			}
 			
 			result.Passed = true
@@ -71,14 +71,19 @@ func (s *Service) RunTest(ctx context.Context, response *models.HTTPResponse, pa
 			return result, nil
 		}
 		
-		result.Error = fmt.Errorf("snapshot comparison failed: %w", err)
+		result.Error = fmt.Sprintf("snapshot comparison failed: %v", err)
 		s.stats.Errors++
-		return result, result.Error
+		return result, fmt.Errorf("snapshot comparison failed: %w", err)
 	}
 	
 	// Set result properties
-	result.Diff = diff
-	result.Passed = diff.Equal
+	result.Diff = &models.SnapshotDiff{
+		HasDiff:    !comparison.Matches,
+		DiffString: comparison.Diff,
+		StatusDiff: !comparison.StatusMatch,
+		Equal:      comparison.Matches,
+	}
+	result.Passed = comparison.Matches
 	
 	// Update stats
 	s.stats.Total++
@@ -89,10 +94,10 @@ func (s *Service) RunTest(ctx context.Context, response *models.HTTPResponse, pa
 		
 		if s.options.UpdateMode == "all" || s.options.UpdateMode == "failed" {
 			// Update snapshot
-			if updateErr := s.manager.SaveSnapshot(ctx, response, snapshotPath); updateErr != nil {
-				result.Error = fmt.Errorf("failed to update snapshot: %w", updateErr)
+			if updateErr := s.manager.SaveSnapshot(response, snapshotPath, response.ContentType); updateErr != nil {
+				result.Error = fmt.Sprintf("failed to update snapshot: %v", updateErr)
 				s.stats.Errors++
-				return result, result.Error
+				return result, fmt.Errorf("failed to update snapshot: %w", updateErr)
 			}
 			
 			result.Updated = true
@@ -108,7 +113,7 @@ func (s *Service) CleanupUnusedSnapshots(ctx context.Context, directory string)
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	
-	return s.manager.CleanupSnapshots(ctx, directory, s.usedSnapshots)
+	return s.manager.CleanupSnapshots(directory, s.usedSnapshots)
 }
 
 // GetStats returns the current test statistics
@@ -2,232 +2,233 @@ package snapshot
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
-	"time"
 
 	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-func TestService_RunTest(t *testing.T) {
-	// Create a temporary directory for snapshots
-	tempDir, err := os.MkdirTemp("", "snapshot-service-test")
-	require.NoError(t, err)
-	defer os.RemoveAll(tempDir)
-
-	// Create a manager and service
-	manager := NewManager(tempDir)
-	service := NewService(manager, models.SnapshotOptions{
-		BasePath:   tempDir,
-		UpdateMode: "all",
-	})
-
-	// Create a test response
-	response := &models.HTTPResponse{
-		StatusCode:    200,
-		Status:        "200 OK",
-		Headers:       map[string][]string{"Content-Type": {"application/json"}},
-		Body:          []byte(`{"name":"test","value":123}`),
-		ContentType:   "application/json",
-		ContentLength: 27,
-		Request: &models.HTTPRequest{
-			Method: "GET",
-			Path:   "/api/test",
-		},
-		Timestamp: time.Now(),
+// memoryManager keeps formatted snapshots in memory by path
+type memoryManager struct {
+	snapshots map[string]string
+}
+
+func newMemoryManager() *memoryManager {
+	return &memoryManager{snapshots: make(map[string]string)}
+}
+
+func (m *memoryManager) SaveSnapshot(response *models.HTTPResponse, path string, format string) error {
+	formatter, err := GetFormatter(format)
+	if err != nil {
+		return err
+	}
+	content, err := formatter.Format(response)
+	if err != nil {
+		return err
 	}
+	m.snapshots[path] = content
+	return nil
+}
 
-	// Run a test, should create a new snapshot
+func (m *memoryManager) LoadSnapshot(path string, format string) (*models.HTTPResponse, error) {
+	content, ok := m.snapshots[path]
+	if !ok {
+		return nil, fmt.Errorf("snapshot %s: %w", path, os.ErrNotExist)
+	}
+	formatter, err := GetFormatter(format)
+	if err != nil {
+		return nil, err
+	}
+	return formatter.Parse(content)
+}
+
+func (m *memoryManager) CompareSnapshots(current *models.HTTPResponse, snapshotPath string, format string) (*ComparisonResult, error) {
+	expected, err := m.LoadSnapshot(snapshotPath, format)
+	if err != nil {
+		return nil, err
+	}
+	formatter, err := GetFormatter(format)
+	if err != nil {
+		return nil, err
+	}
+	return formatter.Compare(expected, current)
+}
+
+func (m *memoryManager) GetSnapshotPath(httpFile string, requestName string, baseDir string) string {
+	return filepath.Join(baseDir, requestName+".snap")
+}
+
+func (m *memoryManager) ListSnapshots(snapshotsDir string) ([]string, error) {
+	var paths []string
+	for path := range m.snapshots {
+		if strings.HasPrefix(path, snapshotsDir+"/") {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+func (m *memoryManager) CleanupSnapshots(snapshotsDir string, activeSnapshots map[string]bool) error {
+	paths, _ := m.ListSnapshots(snapshotsDir)
+	for _, path := range paths {
+		if !activeSnapshots[path] {
+			delete(m.snapshots, path)
+		}
+	}
+	return nil
+}
+
+func jsonResponse(status int, method, path, body string) *models.HTTPResponse {
+	return &models.HTTPResponse{
+		StatusCode:  status,
+		Headers:     map[string][]string{"Content-Type": {"application/json"}},
+		Body:        body,
+		ContentType: "application/json",
+		Request:     &models.HTTPRequest{Method: method, Path: path},
+	}
+}
+
+func TestService_RunTest(t *testing.T) {
+	manager := newMemoryManager()
+	service := NewService(manager, models.SnapshotOptions{UpdateMode: "all"})
+	response := jsonResponse(200, "GET", "/api/test", `{"name":"test","value":123}`)
+
+	// The first run creates the snapshot
 	result, err := service.RunTest(context.Background(), response, "api/test.http")
 	require.NoError(t, err)
 	assert.True(t, result.Passed)
 	assert.True(t, result.Updated)
+	assert.Equal(t, "api/_api_test_GET.snap.json", result.SnapshotPath)
+	assert.Contains(t, manager.snapshots, "api/_api_test_GET.snap.json")
 
-	// Get stats
 	stats := service.GetStats()
 	assert.Equal(t, 1, stats.Total)
 	assert.Equal(t, 1, stats.Passed)
-	assert.Equal(t, 0, stats.Failed)
-	assert.Equal(t, 0, stats.Updated)
 	assert.Equal(t, 1, stats.Created)
 
-	// Run the test again, should pass and not update
+	// The same response matches it
 	result, err = service.RunTest(context.Background(), response, "api/test.http")
 	require.NoError(t, err)
 	assert.True(t, result.Passed)
 	assert.False(t, result.Updated)
+	assert.False(t, result.Diff.HasDiff)
 
-	// Get updated stats
-	stats = service.GetStats()
-	assert.Equal(t, 2, stats.Total)
-	assert.Equal(t, 2, stats.Passed)
-	assert.Equal(t, 0, stats.Failed)
-	assert.Equal(t, 0, stats.Updated)
-	assert.Equal(t, 1, stats.Created)
-
-	// Create a different response
-	differentResponse := &models.HTTPResponse{
-		StatusCode:    400,
-		Status:        "400 Bad Request",
-		Headers:       map[string][]string{"Content-Type": {"application/json"}},
-		Body:          []byte(`{"error":"invalid request"}`),
-		ContentType:   "application/json",
-		ContentLength: 29,
-		Request: &models.HTTPRequest{
-			Method: "GET",
-			Path:   "/api/test",
-		},
-		Timestamp: time.Now(),
-	}
-
-	// Run a test with different response, should fail but update
-	result, err = service.RunTest(context.Background(), differentResponse, "api/test.http")
+	// A different response fails and, in "all" mode, updates it
+	different := jsonResponse(400, "GET", "/api/test", `{"error":"invalid request"}`)
+	result, err = service.RunTest(context.Background(), different, "api/test.http")
 	require.NoError(t, err)
-	assert.True(t, result.Passed) // Passes because it updated
+	assert.False(t, result.Passed)
 	assert.True(t, result.Updated)
+	assert.True(t, result.Diff.StatusDiff)
 
-	// Get updated stats
 	stats = service.GetStats()
 	assert.Equal(t, 3, stats.Total)
-	assert.Equal(t, 3, stats.Passed)
-	assert.Equal(t, 0, stats.Failed)
+	assert.Equal(t, 2, stats.Passed)
+	assert.Equal(t, 1, stats.Failed)
 	assert.Equal(t, 1, stats.Updated)
 	assert.Equal(t, 1, stats.Created)
 
-	// Reset stats
+	// The updated snapshot matches the new response
+	result, err = service.RunTest(context.Background(), different, "api/test.http")
+	require.NoError(t, err)
+	assert.True(t, result.Passed)
+
 	service.ResetStats()
 	stats = service.GetStats()
-	assert.Equal(t, 0, stats.Total)
-	assert.Equal(t, 0, stats.Passed)
-	assert.Equal(t, 0, stats.Failed)
-	assert.Equal(t, 0, stats.Updated)
-	assert.Equal(t, 0, stats.Created)
+	assert.Zero(t, stats.Total)
+	assert.Zero(t, stats.Passed)
+	assert.Zero(t, stats.Failed)
+	assert.Zero(t, stats.Updated)
+	assert.Zero(t, stats.Created)
 }
 
-func TestService_CleanupUnusedSnapshots(t *testing.T) {
-	// Create a temporary directory for snapshots
-	tempDir, err := os.MkdirTemp("", "snapshot-service-test")
-	require.NoError(t, err)
-	defer os.RemoveAll(tempDir)
-
-	// Create a manager and service
-	manager := NewManager(tempDir)
-	service := NewService(manager, models.SnapshotOptions{
-		BasePath:   tempDir,
-		UpdateMode: "all",
-	})
-
-	// Create test responses
-	response1 := &models.HTTPResponse{
-		StatusCode:  200,
-		ContentType: "application/json",
-		Body:        []byte(`{"id":1}`),
-		Request: &models.HTTPRequest{
-			Method: "GET",
-			Path:   "/api/test/1",
-		},
+func TestService_RunTest_UpdateModes(t *testing.T) {
+	tests := []struct {
+		name        string
+		updateMode  string
+		existing    bool
+		wantPassed  bool
+		wantUpdated bool
+		wantErr     bool
+	}{
+		{name: "missing with none", updateMode: "none", wantErr: true},
+		{name: "missing with missing", updateMode: "missing", wantPassed: true, wantUpdated: true},
+		{name: "missing with failed", updateMode: "failed", wantErr: true},
+		{name: "different with none", updateMode: "none", existing: true},
+		{name: "different with missing", updateMode: "missing", existing: true},
+		{name: "different with failed", updateMode: "failed", existing: true, wantUpdated: true},
 	}
 
-	response2 := &models.HTTPResponse{
-		StatusCode:  200,
-		ContentType: "application/json",
-		Body:        []byte(`{"id":2}`),
-		Request: &models.HTTPRequest{
-			Method: "GET",
-			Path:   "/api/test/2",
-		},
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := newMemoryManager()
+			if tt.existing {
+				require.NoError(t, manager.SaveSnapshot(jsonResponse(200, "GET", "/users", `{"id":1}`), "api/_users_GET.snap.json", "application/json"))
+			}
+			service := NewService(manager, models.SnapshotOptions{UpdateMode: tt.updateMode})
+
+			result, err := service.RunTest(context.Background(), jsonResponse(200, "GET", "/users", `{"id":2}`), "api/users.http")
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Equal(t, 1, service.GetStats().Errors)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPassed, result.Passed)
+			assert.Equal(t, tt.wantUpdated, result.Updated)
+		})
 	}
+}
 
-	// Save snapshots directly using manager
-	err = manager.SaveSnapshot(context.Background(), response1, "api/test1.http")
-	require.NoError(t, err)
-	err = manager.SaveSnapshot(context.Background(), response2, "api/test2.http")
-	require.NoError(t, err)
+func TestService_CleanupUnusedSnapshots(t *testing.T) {
+	manager := newMemoryManager()
+	service := NewService(manager, models.SnapshotOptions{UpdateMode: "all"})
+	first := jsonResponse(200, "GET", "/api/test/1", `{"id":1}`)
+	second := jsonResponse(200, "GET", "/api/test/2", `{"id":2}`)
 
-	// Run a test for only the first response, marking it as used
-	_, err = service.RunTest(context.Background(), response1, "api/test1.http")
-	require.NoError(t, err)
+	require.NoError(t, manager.SaveSnapshot(first, "api/_api_test_1_GET.snap.json", first.ContentType))
+	require.NoError(t, manager.SaveSnapshot(second, "api/_api_test_2_GET.snap.json", second.ContentType))
 
-	// Cleanup unused snapshots
-	err = service.CleanupUnusedSnapshots(context.Background(), "api")
+	// Only the first snapshot is used by the run
+	_, err := service.RunTest(context.Background(), first, "api/test1.http")
 	require.NoError(t, err)
 
-	// List snapshots after cleanup
-	snapshots, err := manager.ListSnapshots(context.Background(), "api")
+	require.NoError(t, service.CleanupUnusedSnapshots(context.Background(), "api"))
+	snapshots, err := manager.ListSnapshots("api")
 	require.NoError(t, err)
-
-	// Verify only the used snapshot remains
-	assert.Len(t, snapshots, 1)
-	assert.Contains(t, snapshots, "api/test1_get.snap.json")
-	assert.NotContains(t, snapshots, "api/test2_get.snap.json")
+	assert.Equal(t, []string{"api/_api_test_1_GET.snap.json"}, snapshots)
 }
 
 func TestService_MultipleTests(t *testing.T) {
-	// Create a temporary directory for snapshots
-	tempDir, err := os.MkdirTemp("", "snapshot-service-test")
-	require.NoError(t, err)
-	defer os.RemoveAll(tempDir)
-
-	// Create a manager and service
-	manager := NewManager(tempDir)
-	service := NewService(manager, models.SnapshotOptions{
-		BasePath:   tempDir,
-		UpdateMode: "all",
-	})
+	manager := newMemoryManager()
+	service := NewService(manager, models.SnapshotOptions{UpdateMode: "all"})
 
-	// Create multiple test responses
 	responses := []*models.HTTPResponse{
-		{
-			StatusCode:  200,
-			ContentType: "application/json",
-			Body:        []byte(`{"id":1,"name":"item1"}`),
-			Request: &models.HTTPRequest{
-				Method: "GET",
-				Path:   "/api/items/1",
-			},
-		},
-		{
-			StatusCode:  201,
-			ContentType: "application/json",
-			Body:        []byte(`{"id":2,"name":"item2"}`),
-			Request: &models.HTTPRequest{
-				Method: "POST",
-				Path:   "/api/items",
-			},
-		},
-		{
-			StatusCode:  204,
-			ContentType: "",
-			Body:        []byte{},
-			Request: &models.HTTPRequest{
-				Method: "DELETE",
-				Path:   "/api/items/3",
-			},
-		},
+		jsonResponse(200, "GET", "/api/items/1", `{"id":1,"name":"item1"}`),
+		jsonResponse(201, "POST", "/api/items", `{"id":2,"name":"item2"}`),
+		{StatusCode: 204, Request: &models.HTTPRequest{Method: "DELETE", Path: "/api/items/3"}},
 	}
 
-	// Run tests for all responses
 	for i, response := range responses {
 		result, err := service.RunTest(context.Background(), response, "api/tests.http")
 		require.NoError(t, err)
 		assert.True(t, result.Passed)
 		assert.True(t, result.Updated)
 
-		// Get stats after each test
 		stats := service.GetStats()
 		assert.Equal(t, i+1, stats.Total)
 		assert.Equal(t, i+1, stats.Passed)
-		assert.Equal(t, 0, stats.Failed)
-		assert.Equal(t, 0, stats.Updated)
 		assert.Equal(t, i+1, stats.Created)
 	}
 
-	// List snapshots
-	snapshots, err := manager.ListSnapshots(context.Background(), "api")
+	snapshots, err := manager.ListSnapshots("api")
 	require.NoError(t, err)
-
-	// Verify we have three snapshots
 	assert.Len(t, snapshots, 3)
 }
@@ -0,0 +1,162 @@
+// Package snapstats summarizes a directory of stored snapshots: how many
+// there are, how much space they take, which are the largest, how old they
+// are and how they split by tag, to tell what to prune and spot runaway
+// response sizes early.
+package snapstats
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// UntaggedLabel is the tag of the snapshots stored outside tag directories
+const UntaggedLabel = "(untagged)"
+
+// File is a stored snapshot file
+type File struct {
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modified"`
+}
+
+// AgeBucket counts the snapshots last written within an age range
+type AgeBucket struct {
+	Label string `json:"label"`
+	Count int    `json:"count"`
+	Size  int64  `json:"size"`
+}
+
+// TagStats counts the snapshots of a tag
+type TagStats struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+	Size  int64  `json:"size"`
+}
+
+// Stats summarizes the snapshots of a directory
+type Stats struct {
+	Count     int         `json:"count"`
+	TotalSize int64       `json:"totalSize"`
+	Largest   []File      `json:"largest"`
+	Ages      []AgeBucket `json:"ages"`
+	Tags      []TagStats  `json:"tags"`
+}
+
+// ageBuckets are the upper bounds of the age ranges, the last one unbounded
+var ageBuckets = []struct {
+	label string
+	max   time.Duration
+}{
+	{"< 1 day", 24 * time.Hour},
+	{"1-7 days", 7 * 24 * time.Hour},
+	{"7-30 days", 30 * 24 * time.Hour},
+	{"30-90 days", 90 * 24 * time.Hour},
+	{"> 90 days", 0},
+}
+
+// Compute summarizes the snapshot files of a directory, listing the top
+// largest. The tag of a snapshot is the directory the test runner files it
+// under, the first below dir.
+func Compute(dir string, files []File, now time.Time, top int) *Stats {
+	stats := &Stats{Count: len(files)}
+	for _, bucket := range ageBuckets {
+		stats.Ages = append(stats.Ages, AgeBucket{Label: bucket.label})
+	}
+
+	tags := make(map[string]*TagStats)
+	for _, file := range files {
+		stats.TotalSize += file.Size
+
+		age := now.Sub(file.ModTime)
+		for i, bucket := range ageBuckets {
+			if bucket.max == 0 || age < bucket.max {
+				stats.Ages[i].Count++
+				stats.Ages[i].Size += file.Size
+				break
+			}
+		}
+
+		tag := tagOf(dir, file.Path)
+		if tags[tag] == nil {
+			tags[tag] = &TagStats{Tag: tag}
+		}
+		tags[tag].Count++
+		tags[tag].Size += file.Size
+	}
+
+	for _, tag := range tags {
+		stats.Tags = append(stats.Tags, *tag)
+	}
+	sort.Slice(stats.Tags, func(i, j int) bool {
+		if stats.Tags[i].Size != stats.Tags[j].Size {
+			return stats.Tags[i].Size > stats.Tags[j].Size
+		}
+		return stats.Tags[i].Tag < stats.Tags[j].Tag
+	})
+
+	stats.Largest = append([]File(nil), files...)
+	sort.SliceStable(stats.Largest, func(i, j int) bool {
+		return stats.Largest[i].Size > stats.Largest[j].Size
+	})
+	if len(stats.Largest) > top {
+		stats.Largest = stats.Largest[:top]
+	}
+	return stats
+}
+
+// tagOf returns the tag of a snapshot: the first directory below dir
+func tagOf(dir, path string) string {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return UntaggedLabel
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	if len(parts) < 2 || parts[0] == ".." {
+		return UntaggedLabel
+	}
+	return parts[0]
+}
+
+// Write prints the statistics as text
+func Write(w io.Writer, stats *Stats) {
+	fmt.Fprintf(w, "Snapshots:  %d\n", stats.Count)
+	fmt.Fprintf(w, "Total size: %s\n", FormatSize(stats.TotalSize))
+	if stats.Count == 0 {
+		return
+	}
+
+	fmt.Fprintln(w, "\nLargest:")
+	for _, file := range stats.Largest {
+		fmt.Fprintf(w, "  %10s  %s\n", FormatSize(file.Size), file.Path)
+	}
+
+	fmt.Fprintln(w, "\nAge:")
+	for _, bucket := range stats.Ages {
+		fmt.Fprintf(w, "  %-10s  %5d  %10s\n", bucket.Label, bucket.Count, FormatSize(bucket.Size))
+	}
+
+	fmt.Fprintln(w, "\nBy tag:")
+	for _, tag := range stats.Tags {
+		fmt.Fprintf(w, "  %-20s  %5d  %10s\n", tag.Tag, tag.Count, FormatSize(tag.Size))
+	}
+}
+
+// FormatSize formats a size in bytes with a binary unit, e.g. "1.5 KiB"
+func FormatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	value := float64(size)
+	for _, suffix := range []string{"KiB", "MiB", "GiB"} {
+		value /= unit
+		if value < unit || suffix == "GiB" {
+			return fmt.Sprintf("%.1f %s", value, suffix)
+		}
+	}
+	return fmt.Sprintf("%d B", size)
+}
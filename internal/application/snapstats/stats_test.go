@@ -0,0 +1,49 @@
+package snapstats
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompute(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	dir := ".snapshots"
+	files := []File{
+		{Path: filepath.Join(dir, "users", "GET_users.json"), Size: 2048, ModTime: now.Add(-time.Hour)},
+		{Path: filepath.Join(dir, "users", "POST_users.json"), Size: 512, ModTime: now.Add(-10 * 24 * time.Hour)},
+		{Path: filepath.Join(dir, "orders", "GET_orders.json"), Size: 4096, ModTime: now.Add(-200 * 24 * time.Hour)},
+		{Path: filepath.Join(dir, "GET_health.json"), Size: 64, ModTime: now.Add(-2 * 24 * time.Hour)},
+	}
+
+	stats := Compute(dir, files, now, 2)
+
+	assert.Equal(t, 4, stats.Count)
+	assert.Equal(t, int64(6720), stats.TotalSize)
+	assert.Equal(t, []File{files[2], files[0]}, stats.Largest)
+	assert.Equal(t, []AgeBucket{
+		{Label: "< 1 day", Count: 1, Size: 2048},
+		{Label: "1-7 days", Count: 1, Size: 64},
+		{Label: "7-30 days", Count: 1, Size: 512},
+		{Label: "30-90 days"},
+		{Label: "> 90 days", Count: 1, Size: 4096},
+	}, stats.Ages)
+	assert.Equal(t, []TagStats{
+		{Tag: "orders", Count: 1, Size: 4096},
+		{Tag: "users", Count: 2, Size: 2560},
+		{Tag: UntaggedLabel, Count: 1, Size: 64},
+	}, stats.Tags)
+
+	var out bytes.Buffer
+	Write(&out, stats)
+	assert.Contains(t, out.String(), "Total size: 6.6 KiB")
+}
+
+func TestFormatSize(t *testing.T) {
+	assert.Equal(t, "512 B", FormatSize(512))
+	assert.Equal(t, "1.5 KiB", FormatSize(1536))
+	assert.Equal(t, "3.0 MiB", FormatSize(3*1024*1024))
+}
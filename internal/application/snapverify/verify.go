@@ -0,0 +1,101 @@
+// Package snapverify checks the health of a snapshot directory without
+// sending any request: that each snapshot parses, carries valid metadata and
+// belongs to a request of the .http files. It is meant for pre-commit hooks
+// and CI, lighter than a full test run.
+package snapverify
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/version"
+)
+
+// Kinds of problems of a snapshot
+const (
+	KindCorrupt      = "corrupt"
+	KindIncompatible = "incompatible"
+	KindOrphan       = "orphan"
+)
+
+// Snapshot is a stored snapshot file, with the error reading it, if any
+type Snapshot struct {
+	Path    string
+	Content []byte
+	Err     error
+}
+
+// Problem is what is wrong with a snapshot
+type Problem struct {
+	Path    string `json:"path"`
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+// Report holds the problems of the snapshots checked
+type Report struct {
+	Checked  int       `json:"checked"`
+	Problems []Problem `json:"problems"`
+}
+
+var (
+	statusLinePattern = regexp.MustCompile(`^HTTP [1-5][0-9]{2}( |$)`)
+
+	// variantPattern matches the suffixes the runner adds to the snapshots of
+	// a request per language and data row
+	variantPattern = regexp.MustCompile(`(_lang_[^_/\\]+)?(_row_[^/\\]*)?(\.[^./\\]+)$`)
+)
+
+// Verify checks snapshots, expected holding the paths of the snapshots of the
+// requests of the .http files; nil expected skips the check for orphans
+func Verify(snapshots []Snapshot, expected map[string]bool) *Report {
+	report := &Report{Checked: len(snapshots), Problems: []Problem{}}
+	for _, snapshot := range snapshots {
+		if snapshot.Err != nil {
+			report.Problems = append(report.Problems, Problem{Path: snapshot.Path, Kind: KindCorrupt, Message: snapshot.Err.Error()})
+			continue
+		}
+		if problem := CheckContent(string(snapshot.Content)); problem != nil {
+			problem.Path = snapshot.Path
+			report.Problems = append(report.Problems, *problem)
+			continue
+		}
+		if expected != nil && !expected[snapshot.Path] && !expected[variantPattern.ReplaceAllString(snapshot.Path, "$3")] {
+			report.Problems = append(report.Problems, Problem{Path: snapshot.Path, Kind: KindOrphan, Message: "no request of the .http files maps to it"})
+		}
+	}
+	return report
+}
+
+// CheckContent checks the format and metadata of the content of a snapshot:
+// its version stamp, the status line and the headers ending with a blank line
+func CheckContent(content string) *Problem {
+	lines := strings.Split(content, "\n")
+	i := 0
+	for ; i < len(lines) && strings.HasPrefix(lines[i], "#"); i++ {
+		if !strings.Contains(lines[i], version.StampDirective) {
+			continue
+		}
+		stamp, ok := version.ParseStamp(lines[i])
+		if !ok {
+			return &Problem{Kind: KindCorrupt, Message: fmt.Sprintf("malformed version stamp %q", lines[i])}
+		}
+		if err := version.CheckCompatibility(stamp.Version); err != nil {
+			return &Problem{Kind: KindIncompatible, Message: err.Error()}
+		}
+	}
+
+	if i >= len(lines) || !statusLinePattern.MatchString(lines[i]) {
+		return &Problem{Kind: KindCorrupt, Message: "missing or malformed status line"}
+	}
+	for i++; i < len(lines) && lines[i] != ""; i++ {
+		if name, _, ok := strings.Cut(lines[i], ":"); !ok || strings.TrimSpace(name) == "" {
+			return &Problem{Kind: KindCorrupt, Message: fmt.Sprintf("malformed header line %d: %q", i+1, lines[i])}
+		}
+	}
+	if i >= len(lines) {
+		return &Problem{Kind: KindCorrupt, Message: "missing blank line after the headers"}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package snapverify
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckContent(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		kind    string
+	}{
+		{name: "valid", content: "# @generated-by swagger-to-http/dev\n# > GET https://api.example.com/users\nHTTP 200 OK\nContent-Type: application/json\n\n{}"},
+		{name: "no body", content: "HTTP 204 No Content\n\n"},
+		{name: "malformed stamp", content: "# @generated-by nothing\nHTTP 200 OK\n\n", kind: KindCorrupt},
+		{name: "no status line", content: "{\"id\": 1}", kind: KindCorrupt},
+		{name: "malformed header", content: "HTTP 200 OK\nnot a header\n\n", kind: KindCorrupt},
+		{name: "truncated", content: "HTTP 200 OK\nContent-Type: application/json", kind: KindCorrupt},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problem := CheckContent(tt.content)
+			if tt.kind == "" {
+				assert.Nil(t, problem)
+				return
+			}
+			if assert.NotNil(t, problem) {
+				assert.Equal(t, tt.kind, problem.Kind)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	valid := []byte("HTTP 200 OK\n\n{}")
+	report := Verify([]Snapshot{
+		{Path: "users/GET_users.json", Content: valid},
+		{Path: "users/GET_users_lang_pt-BR_row_2.json", Content: valid},
+		{Path: "GET_gone.json", Content: valid},
+		{Path: "GET_broken.json", Err: errors.New("missing body")},
+	}, map[string]bool{"users/GET_users.json": true, "GET_broken.json": true})
+
+	assert.Equal(t, 4, report.Checked)
+	assert.Equal(t, []Problem{
+		{Path: "GET_gone.json", Kind: KindOrphan, Message: "no request of the .http files maps to it"},
+		{Path: "GET_broken.json", Kind: KindCorrupt, Message: "missing body"},
+	}, report.Problems)
+}
@@ -0,0 +1,174 @@
+// Package soap helps testing XML APIs: it generates SOAP envelope templates
+// and .http requests from a description of the operations, and validates XML
+// responses against XSD schemas.
+package soap
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SOAP versions
+const (
+	Version11 = "1.1"
+	Version12 = "1.2"
+)
+
+// Envelope namespaces of the SOAP versions
+const (
+	EnvelopeNamespace11 = "http://schemas.xmlsoap.org/soap/envelope/"
+	EnvelopeNamespace12 = "http://www.w3.org/2003/05/soap-envelope"
+)
+
+// Service describes the operations of a SOAP endpoint, as read from an
+// operations file
+type Service struct {
+	Endpoint   string      `yaml:"endpoint"`
+	Namespace  string      `yaml:"namespace"`
+	Version    string      `yaml:"version,omitempty"` // Version11 when empty
+	Schema     string      `yaml:"schema,omitempty"`  // XSD the responses are validated against
+	Operations []Operation `yaml:"operations"`
+}
+
+// Operation is a SOAP operation and the parameters of its request element
+type Operation struct {
+	Name      string   `yaml:"name"`
+	Action    string   `yaml:"action,omitempty"`    // SOAPAction, <namespace>/<name> when empty
+	Namespace string   `yaml:"namespace,omitempty"` // The service's namespace when empty
+	Params    []string `yaml:"params,omitempty"`
+	Tag       string   `yaml:"tag,omitempty"`
+}
+
+// LoadService reads and checks an operations file
+func LoadService(path string) (*Service, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read operations file: %w", err)
+	}
+
+	var service Service
+	if err := yaml.Unmarshal(data, &service); err != nil {
+		return nil, fmt.Errorf("failed to parse operations file %s: %w", path, err)
+	}
+	if err := service.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &service, nil
+}
+
+// validate checks that the service describes callable operations
+func (s *Service) validate() error {
+	if s.Endpoint == "" {
+		return fmt.Errorf("endpoint is required")
+	}
+	if s.Version == "" {
+		s.Version = Version11
+	}
+	if s.Version != Version11 && s.Version != Version12 {
+		return fmt.Errorf("unsupported SOAP version %q: use %s or %s", s.Version, Version11, Version12)
+	}
+	if len(s.Operations) == 0 {
+		return fmt.Errorf("no operations declared")
+	}
+	for i, operation := range s.Operations {
+		if operation.Name == "" {
+			return fmt.Errorf("operation %d has no name", i+1)
+		}
+		if operation.Namespace == "" && s.Namespace == "" {
+			return fmt.Errorf("operation %s has no namespace", operation.Name)
+		}
+	}
+	return nil
+}
+
+// namespace returns the namespace of an operation's request element
+func (s *Service) namespace(operation Operation) string {
+	if operation.Namespace != "" {
+		return operation.Namespace
+	}
+	return s.Namespace
+}
+
+// Action returns the SOAPAction of an operation
+func (s *Service) Action(operation Operation) string {
+	if operation.Action != "" {
+		return operation.Action
+	}
+	return strings.TrimSuffix(s.namespace(operation), "/") + "/" + operation.Name
+}
+
+// Headers returns the headers of a request to an operation: SOAP 1.1 sends
+// the action in a SOAPAction header, SOAP 1.2 in the content type
+func (s *Service) Headers(operation Operation) [][2]string {
+	action := s.Action(operation)
+	if s.Version == Version12 {
+		return [][2]string{
+			{"Content-Type", fmt.Sprintf(`application/soap+xml; charset=utf-8; action="%s"`, action)},
+		}
+	}
+	return [][2]string{
+		{"Content-Type", "text/xml; charset=utf-8"},
+		{"SOAPAction", fmt.Sprintf("%q", action)},
+	}
+}
+
+// Envelope returns the envelope template of a request to an operation, with a
+// {{param}} variable per parameter
+func (s *Service) Envelope(operation Operation) string {
+	envelopeNamespace := EnvelopeNamespace11
+	if s.Version == Version12 {
+		envelopeNamespace = EnvelopeNamespace12
+	}
+
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="utf-8"?>` + "\n")
+	fmt.Fprintf(&sb, `<soap:Envelope xmlns:soap="%s" xmlns:ns="%s">`+"\n", envelopeNamespace, s.namespace(operation))
+	sb.WriteString("  <soap:Header/>\n")
+	sb.WriteString("  <soap:Body>\n")
+	if len(operation.Params) == 0 {
+		fmt.Fprintf(&sb, "    <ns:%s/>\n", operation.Name)
+	} else {
+		fmt.Fprintf(&sb, "    <ns:%s>\n", operation.Name)
+		for _, param := range operation.Params {
+			fmt.Fprintf(&sb, "      <ns:%s>{{%s}}</ns:%s>\n", param, param, param)
+		}
+		fmt.Fprintf(&sb, "    </ns:%s>\n", operation.Name)
+	}
+	sb.WriteString("  </soap:Body>\n")
+	sb.WriteString("</soap:Envelope>\n")
+	return sb.String()
+}
+
+// WriteHTTP writes a .http request per operation of the service. Requests are
+// named after their operation and validated against the service's schema.
+func (s *Service) WriteHTTP(w io.Writer) error {
+	for i, operation := range s.Operations {
+		var sb strings.Builder
+		if i > 0 {
+			sb.WriteString("\n###\n\n")
+		}
+		fmt.Fprintf(&sb, "# SOAP %s operation %s\n", s.Version, operation.Name)
+		fmt.Fprintf(&sb, "# @name %s\n", operation.Name)
+		if operation.Tag != "" {
+			fmt.Fprintf(&sb, "# @tag %s\n", operation.Tag)
+		}
+		if s.Schema != "" {
+			fmt.Fprintf(&sb, "# @xsd %s\n", s.Schema)
+		}
+		fmt.Fprintf(&sb, "POST %s\n", s.Endpoint)
+		for _, header := range s.Headers(operation) {
+			fmt.Fprintf(&sb, "%s: %s\n", header[0], header[1])
+		}
+		sb.WriteString("\n")
+		sb.WriteString(s.Envelope(operation))
+
+		if _, err := io.WriteString(w, sb.String()); err != nil {
+			return fmt.Errorf("failed to write operation %s: %w", operation.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package soap
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceWriteHTTP(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+endpoint: "{{baseUrl}}/soap/orders"
+namespace: http://example.com/orders
+schema: orders.xsd
+operations:
+  - name: GetOrder
+    params: [orderId]
+  - name: Ping
+    action: urn:ping
+`), 0644))
+
+	service, err := LoadService(path)
+	require.NoError(t, err)
+	assert.Equal(t, Version11, service.Version)
+
+	var sb strings.Builder
+	require.NoError(t, service.WriteHTTP(&sb))
+	out := sb.String()
+
+	assert.Contains(t, out, "# @name GetOrder\n# @xsd orders.xsd\nPOST {{baseUrl}}/soap/orders\n")
+	assert.Contains(t, out, "SOAPAction: \"http://example.com/orders/GetOrder\"\n")
+	assert.Contains(t, out, "<ns:orderId>{{orderId}}</ns:orderId>")
+	assert.Contains(t, out, "\n###\n\n")
+	assert.Contains(t, out, "SOAPAction: \"urn:ping\"\n")
+	assert.Contains(t, out, "<ns:Ping/>")
+}
+
+func TestServiceSOAP12(t *testing.T) {
+	service := &Service{Endpoint: "http://localhost/soap", Namespace: "urn:orders", Version: Version12, Operations: []Operation{{Name: "GetOrder"}}}
+	require.NoError(t, service.validate())
+
+	headers := service.Headers(service.Operations[0])
+	assert.Equal(t, [][2]string{{"Content-Type", `application/soap+xml; charset=utf-8; action="urn:orders/GetOrder"`}}, headers)
+	assert.Contains(t, service.Envelope(service.Operations[0]), EnvelopeNamespace12)
+}
+
+func TestServiceValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		service Service
+	}{
+		{"no endpoint", Service{Namespace: "urn:a", Operations: []Operation{{Name: "A"}}}},
+		{"bad version", Service{Endpoint: "http://x", Namespace: "urn:a", Version: "2.0", Operations: []Operation{{Name: "A"}}}},
+		{"no operations", Service{Endpoint: "http://x", Namespace: "urn:a"}},
+		{"no namespace", Service{Endpoint: "http://x", Operations: []Operation{{Name: "A"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, tt.service.validate())
+		})
+	}
+}
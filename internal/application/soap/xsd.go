@@ -0,0 +1,766 @@
+package soap
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// unbounded is the maxOccurs of particles without an upper limit
+const unbounded = -1
+
+// Schema is an XSD schema supporting the common subset used by SOAP services:
+// global and local elements, named and anonymous complex types with sequence,
+// all and choice groups, attributes, simple content and complex content
+// extensions, and simple types restricting built-in types with enumerations,
+// patterns and lengths. Elements are matched by local name; imports and
+// includes are not followed.
+type Schema struct {
+	elements     map[string]*element
+	complexTypes map[string]*complexType
+	simpleTypes  map[string]*simpleType
+}
+
+// element is an element declaration
+type element struct {
+	name     string
+	ref      string
+	typeName string
+	complex  *complexType
+	simple   *simpleType
+	nillable bool
+}
+
+// particle is an element, wildcard or nested group of a content model
+type particle struct {
+	element  *element
+	group    *group
+	wildcard bool
+	min, max int
+}
+
+// group is a sequence, all or choice content model
+type group struct {
+	kind      string
+	particles []particle
+}
+
+// complexType is a complex type declaration
+type complexType struct {
+	content    *group
+	attributes []attribute
+	base       string // Type extended by complex or simple content
+	simple     bool   // Whether the content is simple, i.e. text of the base type
+	mixed      bool
+}
+
+// attribute is an attribute declaration
+type attribute struct {
+	name     string
+	typeName string
+	simple   *simpleType
+	required bool
+}
+
+// simpleType is a simple type restricting a base type
+type simpleType struct {
+	base        string
+	enumeration []string
+	pattern     *regexp.Regexp
+	minLength   int
+	maxLength   int // -1 when unlimited
+}
+
+// node is an element of a parsed XML document
+type node struct {
+	name     xml.Name
+	attrs    []xml.Attr
+	children []*node
+	text     string
+}
+
+// attr returns the value of an attribute by local name
+func (n *node) attr(name string) (string, bool) {
+	for _, a := range n.attrs {
+		if a.Name.Local == name && a.Name.Space != "xmlns" {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+// parseTree parses an XML document into a tree of elements
+func parseTree(data []byte) (*node, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	var root *node
+	var stack []*node
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid XML: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			n := &node{name: t.Name, attrs: t.Attr}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			} else if root == nil {
+				root = n
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text += string(t)
+			}
+		}
+	}
+
+	if root == nil {
+		return nil, fmt.Errorf("invalid XML: no root element")
+	}
+	return root, nil
+}
+
+// LoadSchema reads an XSD file, or a WSDL file whose types hold schemas
+func LoadSchema(path string) (*Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema: %w", err)
+	}
+	schema, err := ParseSchema(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return schema, nil
+}
+
+// ParseSchema parses an XSD document, or a WSDL document whose types hold schemas
+func ParseSchema(data []byte) (*Schema, error) {
+	root, err := parseTree(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var schemaNodes []*node
+	switch root.name.Local {
+	case "schema":
+		schemaNodes = []*node{root}
+	case "definitions", "description":
+		// WSDL 1.1 definitions and WSDL 2.0 descriptions declare their schemas in types
+		for _, child := range root.children {
+			if child.name.Local != "types" {
+				continue
+			}
+			for _, schemaNode := range child.children {
+				if schemaNode.name.Local == "schema" {
+					schemaNodes = append(schemaNodes, schemaNode)
+				}
+			}
+		}
+	default:
+		return nil, fmt.Errorf("expected an XSD schema or a WSDL document, found <%s>", root.name.Local)
+	}
+	if len(schemaNodes) == 0 {
+		return nil, fmt.Errorf("the WSDL document declares no schemas")
+	}
+
+	schema := &Schema{
+		elements:     make(map[string]*element),
+		complexTypes: make(map[string]*complexType),
+		simpleTypes:  make(map[string]*simpleType),
+	}
+	for _, schemaNode := range schemaNodes {
+		for _, child := range schemaNode.children {
+			name, _ := child.attr("name")
+			switch child.name.Local {
+			case "element":
+				schema.elements[name] = parseElement(child)
+			case "complexType":
+				schema.complexTypes[name] = parseComplexType(child)
+			case "simpleType":
+				schema.simpleTypes[name] = parseSimpleType(child)
+			}
+		}
+	}
+	return schema, nil
+}
+
+// localName strips the namespace prefix of a qualified name
+func localName(qname string) string {
+	if i := strings.LastIndex(qname, ":"); i >= 0 {
+		return qname[i+1:]
+	}
+	return qname
+}
+
+// occurs returns the minOccurs and maxOccurs of a particle
+func occurs(n *node) (int, int) {
+	min, max := 1, 1
+	if value, ok := n.attr("minOccurs"); ok {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			min = parsed
+		}
+	}
+	if value, ok := n.attr("maxOccurs"); ok {
+		if value == "unbounded" {
+			max = unbounded
+		} else if parsed, err := strconv.Atoi(value); err == nil {
+			max = parsed
+		}
+	}
+	return min, max
+}
+
+// parseElement parses an element declaration
+func parseElement(n *node) *element {
+	el := &element{}
+	el.name, _ = n.attr("name")
+	if ref, ok := n.attr("ref"); ok {
+		el.ref = localName(ref)
+	}
+	if typeName, ok := n.attr("type"); ok {
+		el.typeName = localName(typeName)
+	}
+	if nillable, _ := n.attr("nillable"); nillable == "true" {
+		el.nillable = true
+	}
+	for _, child := range n.children {
+		switch child.name.Local {
+		case "complexType":
+			el.complex = parseComplexType(child)
+		case "simpleType":
+			el.simple = parseSimpleType(child)
+		}
+	}
+	return el
+}
+
+// parseComplexType parses a complex type declaration
+func parseComplexType(n *node) *complexType {
+	ct := &complexType{}
+	if mixed, _ := n.attr("mixed"); mixed == "true" {
+		ct.mixed = true
+	}
+	parseComplexContent(n, ct)
+	return ct
+}
+
+// parseComplexContent parses the groups and attributes of a complex type or
+// of the extension of its content
+func parseComplexContent(n *node, ct *complexType) {
+	for _, child := range n.children {
+		switch child.name.Local {
+		case "sequence", "all", "choice":
+			ct.content = parseGroup(child)
+		case "attribute":
+			ct.attributes = append(ct.attributes, parseAttribute(child))
+		case "simpleContent", "complexContent":
+			ct.simple = child.name.Local == "simpleContent"
+			for _, derivation := range child.children {
+				if derivation.name.Local != "extension" && derivation.name.Local != "restriction" {
+					continue
+				}
+				if base, ok := derivation.attr("base"); ok {
+					ct.base = localName(base)
+				}
+				parseComplexContent(derivation, ct)
+			}
+		}
+	}
+}
+
+// parseGroup parses a sequence, all or choice group
+func parseGroup(n *node) *group {
+	g := &group{kind: n.name.Local}
+	for _, child := range n.children {
+		min, max := occurs(child)
+		switch child.name.Local {
+		case "element":
+			g.particles = append(g.particles, particle{element: parseElement(child), min: min, max: max})
+		case "sequence", "all", "choice":
+			g.particles = append(g.particles, particle{group: parseGroup(child), min: min, max: max})
+		case "any":
+			g.particles = append(g.particles, particle{wildcard: true, min: min, max: max})
+		}
+	}
+	return g
+}
+
+// parseAttribute parses an attribute declaration
+func parseAttribute(n *node) attribute {
+	attr := attribute{}
+	attr.name, _ = n.attr("name")
+	if ref, ok := n.attr("ref"); ok && attr.name == "" {
+		attr.name = localName(ref)
+	}
+	if typeName, ok := n.attr("type"); ok {
+		attr.typeName = localName(typeName)
+	}
+	if use, _ := n.attr("use"); use == "required" {
+		attr.required = true
+	}
+	for _, child := range n.children {
+		if child.name.Local == "simpleType" {
+			attr.simple = parseSimpleType(child)
+		}
+	}
+	return attr
+}
+
+// parseSimpleType parses a simple type declaration; lists and unions are
+// accepted as strings
+func parseSimpleType(n *node) *simpleType {
+	st := &simpleType{base: "string", maxLength: -1}
+	for _, child := range n.children {
+		if child.name.Local != "restriction" {
+			continue
+		}
+		if base, ok := child.attr("base"); ok {
+			st.base = localName(base)
+		}
+		for _, facet := range child.children {
+			value, _ := facet.attr("value")
+			switch facet.name.Local {
+			case "enumeration":
+				st.enumeration = append(st.enumeration, value)
+			case "pattern":
+				// XSD patterns are implicitly anchored; unsupported syntax is ignored
+				if pattern, err := regexp.Compile("^(?:" + value + ")$"); err == nil {
+					st.pattern = pattern
+				}
+			case "length", "minLength", "maxLength":
+				length, err := strconv.Atoi(value)
+				if err != nil {
+					continue
+				}
+				if facet.name.Local != "maxLength" {
+					st.minLength = length
+				}
+				if facet.name.Local != "minLength" {
+					st.maxLength = length
+				}
+			}
+		}
+	}
+	return st
+}
+
+// Validate validates an XML document against the schema and returns the
+// problems found. The payload of a SOAP envelope is validated instead of the
+// envelope, and a SOAP fault is reported as a problem. An error is returned
+// when the document is not well-formed or its root element is not declared.
+func (s *Schema) Validate(document []byte) ([]string, error) {
+	root, err := parseTree(document)
+	if err != nil {
+		return nil, err
+	}
+
+	if root.name.Local == "Envelope" && (root.name.Space == EnvelopeNamespace11 || root.name.Space == EnvelopeNamespace12) {
+		root, err = envelopePayload(root)
+		if err != nil {
+			return nil, err
+		}
+		if root == nil {
+			return nil, nil
+		}
+		if root.name.Local == "Fault" {
+			return []string{"SOAP fault: " + faultReason(root)}, nil
+		}
+	}
+
+	el, ok := s.elements[root.name.Local]
+	if !ok {
+		return nil, fmt.Errorf("element <%s> is not declared in the schema", root.name.Local)
+	}
+
+	var problems []string
+	s.validateElement(root, el, "/"+root.name.Local, &problems)
+	return problems, nil
+}
+
+// envelopePayload returns the first element of the body of a SOAP envelope,
+// or nil for an empty body
+func envelopePayload(envelope *node) (*node, error) {
+	for _, child := range envelope.children {
+		if child.name.Local == "Body" {
+			if len(child.children) == 0 {
+				return nil, nil
+			}
+			return child.children[0], nil
+		}
+	}
+	return nil, fmt.Errorf("SOAP envelope has no body")
+}
+
+// faultReason returns the reason of a SOAP 1.1 or 1.2 fault
+func faultReason(fault *node) string {
+	for _, child := range fault.children {
+		switch child.name.Local {
+		case "faultstring":
+			return strings.TrimSpace(child.text)
+		case "Reason":
+			for _, text := range child.children {
+				return strings.TrimSpace(text.text)
+			}
+		}
+	}
+	return "no reason given"
+}
+
+// resolve returns the global element an element reference points to
+func (s *Schema) resolve(el *element) *element {
+	if el.ref != "" {
+		if target, ok := s.elements[el.ref]; ok {
+			return target
+		}
+	}
+	return el
+}
+
+// elementName returns the name an element declaration matches
+func (s *Schema) elementName(el *element) string {
+	if el.ref != "" {
+		return el.ref
+	}
+	return el.name
+}
+
+// validateElement validates an element of the document against its declaration
+func (s *Schema) validateElement(n *node, el *element, path string, problems *[]string) {
+	el = s.resolve(el)
+	if nilled, _ := n.attr("nil"); nilled == "true" {
+		if !el.nillable {
+			*problems = append(*problems, fmt.Sprintf("%s: element is nil but not nillable", path))
+		}
+		return
+	}
+
+	switch {
+	case el.complex != nil:
+		s.validateComplex(n, el.complex, path, problems)
+	case el.simple != nil:
+		s.validateText(n, path, problems)
+		s.validateSimple(strings.TrimSpace(n.text), el.simple, path, problems)
+	case el.typeName != "":
+		if ct, ok := s.complexTypes[el.typeName]; ok {
+			s.validateComplex(n, ct, path, problems)
+			return
+		}
+		s.validateText(n, path, problems)
+		s.validateValue(strings.TrimSpace(n.text), el.typeName, path, problems)
+	}
+}
+
+// validateText reports child elements of an element with simple content
+func (s *Schema) validateText(n *node, path string, problems *[]string) {
+	for _, child := range n.children {
+		*problems = append(*problems, fmt.Sprintf("%s: unexpected element <%s> in simple content", path, child.name.Local))
+	}
+}
+
+// validateComplex validates an element against a complex type
+func (s *Schema) validateComplex(n *node, ct *complexType, path string, problems *[]string) {
+	// Extensions inherit the content and attributes of their base type
+	var groups []*group
+	attributes := ct.attributes
+	simpleBase := ""
+	for base, seen := ct, map[*complexType]bool{}; base != nil && !seen[base]; {
+		seen[base] = true
+		if base.content != nil {
+			groups = append([]*group{base.content}, groups...)
+		}
+		if base != ct {
+			attributes = append(attributes, base.attributes...)
+		}
+		next, ok := s.complexTypes[base.base]
+		if !ok {
+			if base.simple {
+				simpleBase = base.base
+			}
+			break
+		}
+		base = next
+	}
+
+	s.validateAttributes(n, attributes, path, problems)
+
+	if simpleBase != "" {
+		s.validateText(n, path, problems)
+		s.validateValue(strings.TrimSpace(n.text), simpleBase, path, problems)
+		return
+	}
+	if !ct.mixed && strings.TrimSpace(n.text) != "" {
+		*problems = append(*problems, fmt.Sprintf("%s: unexpected text in element-only content", path))
+	}
+
+	index := 0
+	for _, g := range groups {
+		index = s.matchGroup(n.children, index, g, path, problems)
+	}
+	for _, child := range n.children[index:] {
+		*problems = append(*problems, fmt.Sprintf("%s: unexpected element <%s>", path, child.name.Local))
+	}
+}
+
+// validateAttributes checks the required attributes and the values of the
+// declared ones
+func (s *Schema) validateAttributes(n *node, attributes []attribute, path string, problems *[]string) {
+	for _, attr := range attributes {
+		value, ok := n.attr(attr.name)
+		if !ok {
+			if attr.required {
+				*problems = append(*problems, fmt.Sprintf("%s: missing required attribute %s", path, attr.name))
+			}
+			continue
+		}
+		attrPath := path + "/@" + attr.name
+		if attr.simple != nil {
+			s.validateSimple(value, attr.simple, attrPath, problems)
+		} else if attr.typeName != "" {
+			s.validateValue(value, attr.typeName, attrPath, problems)
+		}
+	}
+}
+
+// matchGroup matches the children from index against a group, validating the
+// matched elements, and returns the index of the first child left
+func (s *Schema) matchGroup(children []*node, index int, g *group, path string, problems *[]string) int {
+	switch g.kind {
+	case "all":
+		counts := make(map[int]int)
+	children:
+		for index < len(children) {
+			for i, p := range g.particles {
+				if p.element != nil && s.elementName(p.element) == children[index].name.Local {
+					counts[i]++
+					if counts[i] > 1 {
+						*problems = append(*problems, fmt.Sprintf("%s: element <%s> appears more than once", path, children[index].name.Local))
+					}
+					s.validateElement(children[index], p.element, path+"/"+children[index].name.Local, problems)
+					index++
+					continue children
+				}
+			}
+			break
+		}
+		for i, p := range g.particles {
+			if p.element != nil && p.min > 0 && counts[i] == 0 {
+				*problems = append(*problems, fmt.Sprintf("%s: missing element <%s>", path, s.elementName(p.element)))
+			}
+		}
+		return index
+
+	case "choice":
+		for _, p := range g.particles {
+			if index < len(children) && s.starts(p, children[index]) {
+				return s.matchParticle(children, index, p, path, problems)
+			}
+		}
+		var names []string
+		for _, p := range g.particles {
+			if p.element != nil {
+				names = append(names, "<"+s.elementName(p.element)+">")
+			}
+		}
+		if len(names) > 0 {
+			*problems = append(*problems, fmt.Sprintf("%s: expected one of %s", path, strings.Join(names, ", ")))
+		}
+		return index
+
+	default:
+		for _, p := range g.particles {
+			index = s.matchParticle(children, index, p, path, problems)
+		}
+		return index
+	}
+}
+
+// starts reports whether a child can be the first element matched by a particle
+func (s *Schema) starts(p particle, child *node) bool {
+	switch {
+	case p.wildcard:
+		return true
+	case p.element != nil:
+		return s.elementName(p.element) == child.name.Local
+	}
+	for _, nested := range p.group.particles {
+		if s.starts(nested, child) {
+			return true
+		}
+		if p.group.kind == "sequence" && nested.min > 0 {
+			return false
+		}
+	}
+	return false
+}
+
+// matchParticle matches the repetitions of a particle from index and returns
+// the index of the first child left
+func (s *Schema) matchParticle(children []*node, index int, p particle, path string, problems *[]string) int {
+	count := 0
+	for index < len(children) && (p.max == unbounded || count < p.max) && s.starts(p, children[index]) {
+		switch {
+		case p.group != nil:
+			next := s.matchGroup(children, index, p.group, path, problems)
+			if next == index {
+				return index
+			}
+			index = next
+		case p.element != nil:
+			s.validateElement(children[index], p.element, path+"/"+children[index].name.Local, problems)
+			index++
+		default:
+			index++
+		}
+		count++
+	}
+
+	if count < p.min {
+		switch {
+		case p.element != nil:
+			*problems = append(*problems, fmt.Sprintf("%s: missing element <%s>", path, s.elementName(p.element)))
+		case p.group != nil:
+			*problems = append(*problems, fmt.Sprintf("%s: missing content of a required %s", path, p.group.kind))
+		}
+	}
+	return index
+}
+
+// validateValue validates a text value against a named simple or built-in type
+func (s *Schema) validateValue(value, typeName, path string, problems *[]string) {
+	if st, ok := s.simpleTypes[typeName]; ok {
+		s.validateSimple(value, st, path, problems)
+		return
+	}
+	if err := checkBuiltin(typeName, value); err != nil {
+		*problems = append(*problems, fmt.Sprintf("%s: %v", path, err))
+	}
+}
+
+// validateSimple validates a text value against a simple type and its base
+func (s *Schema) validateSimple(value string, st *simpleType, path string, problems *[]string) {
+	before := len(*problems)
+	s.validateValue(value, st.base, path, problems)
+	if len(*problems) > before {
+		return
+	}
+
+	if len(st.enumeration) > 0 {
+		allowed := false
+		for _, option := range st.enumeration {
+			if value == option {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			*problems = append(*problems, fmt.Sprintf("%s: %q is not one of %s", path, value, strings.Join(st.enumeration, ", ")))
+		}
+	}
+	if st.pattern != nil && !st.pattern.MatchString(value) {
+		*problems = append(*problems, fmt.Sprintf("%s: %q does not match the pattern %s", path, value, st.pattern))
+	}
+	length := utf8.RuneCountInString(value)
+	if length < st.minLength || (st.maxLength >= 0 && length > st.maxLength) {
+		*problems = append(*problems, fmt.Sprintf("%s: length %d is outside the allowed range", path, length))
+	}
+}
+
+var (
+	integerPattern = regexp.MustCompile(`^[+-]?\d+$`)
+	decimalPattern = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)$`)
+)
+
+// integerRanges are the bounds of the bounded built-in integer types
+var integerRanges = map[string][2]int64{
+	"long":               {-1 << 63, 1<<63 - 1},
+	"int":                {-1 << 31, 1<<31 - 1},
+	"short":              {-1 << 15, 1<<15 - 1},
+	"byte":               {-1 << 7, 1<<7 - 1},
+	"unsignedInt":        {0, 1<<32 - 1},
+	"unsignedShort":      {0, 1<<16 - 1},
+	"unsignedByte":       {0, 1<<8 - 1},
+	"positiveInteger":    {1, 1<<63 - 1},
+	"nonNegativeInteger": {0, 1<<63 - 1},
+	"negativeInteger":    {-1 << 63, -1},
+	"nonPositiveInteger": {-1 << 63, 0},
+}
+
+// checkBuiltin checks the lexical form of a value of a built-in XSD type;
+// types it doesn't know accept any value
+func checkBuiltin(typeName, value string) error {
+	switch typeName {
+	case "boolean":
+		if value != "true" && value != "false" && value != "1" && value != "0" {
+			return fmt.Errorf("%q is not a valid boolean", value)
+		}
+	case "integer":
+		if !integerPattern.MatchString(value) {
+			return fmt.Errorf("%q is not a valid integer", value)
+		}
+	case "long", "int", "short", "byte", "unsignedInt", "unsignedShort", "unsignedByte",
+		"positiveInteger", "nonNegativeInteger", "negativeInteger", "nonPositiveInteger":
+		bounds := integerRanges[typeName]
+		number, err := strconv.ParseInt(strings.TrimPrefix(value, "+"), 10, 64)
+		if err != nil || number < bounds[0] || number > bounds[1] {
+			return fmt.Errorf("%q is not a valid %s", value, typeName)
+		}
+	case "unsignedLong":
+		if _, err := strconv.ParseUint(strings.TrimPrefix(value, "+"), 10, 64); err != nil {
+			return fmt.Errorf("%q is not a valid unsignedLong", value)
+		}
+	case "decimal":
+		if !decimalPattern.MatchString(value) {
+			return fmt.Errorf("%q is not a valid decimal", value)
+		}
+	case "float", "double":
+		if value != "INF" && value != "-INF" && value != "NaN" {
+			if _, err := strconv.ParseFloat(value, 64); err != nil {
+				return fmt.Errorf("%q is not a valid %s", value, typeName)
+			}
+		}
+	case "date":
+		if !parsesAs(value, "2006-01-02", "2006-01-02Z07:00") {
+			return fmt.Errorf("%q is not a valid date", value)
+		}
+	case "dateTime":
+		if !parsesAs(value, "2006-01-02T15:04:05.999999999", "2006-01-02T15:04:05.999999999Z07:00") {
+			return fmt.Errorf("%q is not a valid dateTime", value)
+		}
+	case "time":
+		if !parsesAs(value, "15:04:05.999999999", "15:04:05.999999999Z07:00") {
+			return fmt.Errorf("%q is not a valid time", value)
+		}
+	case "base64Binary":
+		if _, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(value), "")); err != nil {
+			return fmt.Errorf("%q is not valid base64Binary", value)
+		}
+	}
+	return nil
+}
+
+// parsesAs reports whether a value parses with one of the time layouts
+func parsesAs(value string, layouts ...string) bool {
+	for _, layout := range layouts {
+		if _, err := time.Parse(layout, value); err == nil {
+			return true
+		}
+	}
+	return false
+}
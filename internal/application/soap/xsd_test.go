@@ -0,0 +1,150 @@
+package soap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testXSD = `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:tns="http://example.com/orders"
+           targetNamespace="http://example.com/orders" elementFormDefault="qualified">
+  <xs:element name="GetOrderResponse">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="order" type="tns:Order"/>
+      </xs:sequence>
+    </xs:complexType>
+  </xs:element>
+  <xs:complexType name="Order">
+    <xs:sequence>
+      <xs:element name="id" type="xs:int"/>
+      <xs:element name="status" type="tns:Status"/>
+      <xs:element name="total" type="tns:Amount"/>
+      <xs:element name="placedAt" type="xs:dateTime" minOccurs="0"/>
+      <xs:element name="item" type="xs:string" minOccurs="0" maxOccurs="unbounded"/>
+    </xs:sequence>
+    <xs:attribute name="version" type="xs:int" use="required"/>
+  </xs:complexType>
+  <xs:complexType name="Amount">
+    <xs:simpleContent>
+      <xs:extension base="xs:decimal">
+        <xs:attribute name="currency" type="xs:string" use="required"/>
+      </xs:extension>
+    </xs:simpleContent>
+  </xs:complexType>
+  <xs:simpleType name="Status">
+    <xs:restriction base="xs:string">
+      <xs:enumeration value="OPEN"/>
+      <xs:enumeration value="SHIPPED"/>
+    </xs:restriction>
+  </xs:simpleType>
+</xs:schema>`
+
+func envelope(payload string) string {
+	return `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/" xmlns:o="http://example.com/orders">
+  <soap:Body>` + payload + `</soap:Body>
+</soap:Envelope>`
+}
+
+func TestSchemaValidate(t *testing.T) {
+	schema, err := ParseSchema([]byte(testXSD))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		document string
+		problems []string
+	}{
+		{
+			name: "valid envelope",
+			document: envelope(`<o:GetOrderResponse><o:order version="2">
+				<o:id>42</o:id><o:status>OPEN</o:status><o:total currency="EUR">12.50</o:total>
+				<o:placedAt>2024-03-15T10:20:00Z</o:placedAt><o:item>book</o:item><o:item>pen</o:item>
+			</o:order></o:GetOrderResponse>`),
+		},
+		{
+			name:     "valid document without envelope",
+			document: `<GetOrderResponse><order version="1"><id>1</id><status>SHIPPED</status><total currency="USD">3</total></order></GetOrderResponse>`,
+		},
+		{
+			name:     "invalid values",
+			document: `<GetOrderResponse><order version="x"><id>abc</id><status>LOST</status><total>1.2.3</total></order></GetOrderResponse>`,
+			problems: []string{
+				`/GetOrderResponse/order/@version: "x" is not a valid int`,
+				`/GetOrderResponse/order/id: "abc" is not a valid int`,
+				`/GetOrderResponse/order/status: "LOST" is not one of OPEN, SHIPPED`,
+				`/GetOrderResponse/order/total: missing required attribute currency`,
+				`/GetOrderResponse/order/total: "1.2.3" is not a valid decimal`,
+			},
+		},
+		{
+			name:     "missing and unexpected elements",
+			document: `<GetOrderResponse><order version="1"><id>1</id><total currency="EUR">1</total><note/></order></GetOrderResponse>`,
+			problems: []string{
+				`/GetOrderResponse/order: missing element <status>`,
+				`/GetOrderResponse/order: unexpected element <note>`,
+			},
+		},
+		{
+			name:     "fault",
+			document: envelope(`<soap:Fault><faultcode>soap:Server</faultcode><faultstring>Order not found</faultstring></soap:Fault>`),
+			problems: []string{"SOAP fault: Order not found"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems, err := schema.Validate([]byte(tt.document))
+			require.NoError(t, err)
+			assert.Equal(t, tt.problems, problems)
+		})
+	}
+
+	_, err = schema.Validate([]byte(`<Unknown/>`))
+	assert.Error(t, err)
+
+	_, err = schema.Validate([]byte(`<GetOrderResponse>`))
+	assert.Error(t, err)
+}
+
+func TestParseSchemaFromWSDL(t *testing.T) {
+	wsdl := `<definitions xmlns="http://schemas.xmlsoap.org/wsdl/" xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <types>
+    <xs:schema><xs:element name="Ping" type="xs:boolean"/></xs:schema>
+  </types>
+</definitions>`
+
+	schema, err := ParseSchema([]byte(wsdl))
+	require.NoError(t, err)
+
+	problems, err := schema.Validate([]byte(`<Ping>maybe</Ping>`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{`/Ping: "maybe" is not a valid boolean`}, problems)
+}
+
+func TestSchemaValidateChoice(t *testing.T) {
+	schema, err := ParseSchema([]byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="Result">
+    <xs:complexType>
+      <xs:choice>
+        <xs:element name="value" type="xs:int"/>
+        <xs:element name="error" type="xs:string"/>
+      </xs:choice>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`))
+	require.NoError(t, err)
+
+	problems, err := schema.Validate([]byte(`<Result><error>boom</error></Result>`))
+	require.NoError(t, err)
+	assert.Empty(t, problems)
+
+	problems, err = schema.Validate([]byte(`<Result><other/></Result>`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"/Result: expected one of <value>, <error>",
+		"/Result: unexpected element <other>",
+	}, problems)
+}
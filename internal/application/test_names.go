@@ -0,0 +1,44 @@
+package application
+
+import (
+	"fmt"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// DedupeTestNames tells apart the requests whose names derived from their
+// path collide, numbering the second and later ones, e.g. "GET /users (2)",
+// and returns a warning for each name changed. Names set with "@name" are
+// left alone: duplicates of those are reported by the dependency checks.
+func DedupeTestNames(files []*models.HTTPFile) []string {
+	count := make(map[string]int)
+	for _, file := range files {
+		for _, request := range file.Requests {
+			count[request.Name]++
+		}
+	}
+
+	var warnings []string
+	seen := make(map[string]int)
+	for _, file := range files {
+		for i := range file.Requests {
+			request := &file.Requests[i]
+			name := request.Name
+			seen[name]++
+			if count[name] < 2 || seen[name] < 2 || !request.DerivedName {
+				continue
+			}
+
+			// Find a free index, in case a request already has the numbered name
+			index := seen[name]
+			for count[fmt.Sprintf("%s (%d)", name, index)] > 0 {
+				index++
+			}
+			request.Name = fmt.Sprintf("%s (%d)", name, index)
+			count[request.Name]++
+			warnings = append(warnings, fmt.Sprintf("%s:%d: duplicate test name %q renamed to %q; set a name with # @name or change test.name_template",
+				file.Filename, request.Line, name, request.Name))
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,33 @@
+package application
+
+import (
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDedupeTestNames(t *testing.T) {
+	files := []*models.HTTPFile{
+		{Filename: "users.http", Requests: []models.HTTPRequest{
+			{Name: "GET /users", DerivedName: true, Line: 1},
+			{Name: "GET /users", DerivedName: true, Line: 5},
+			{Name: "createUser", Line: 9},
+		}},
+		{Filename: "admin.http", Requests: []models.HTTPRequest{
+			{Name: "GET /users", DerivedName: true, Line: 3},
+			{Name: "createUser", Line: 7},
+			{Name: "GET /users (2)", Line: 11},
+		}},
+	}
+
+	warnings := DedupeTestNames(files)
+
+	assert.Equal(t, "GET /users", files[0].Requests[0].Name)
+	assert.Equal(t, "GET /users (3)", files[0].Requests[1].Name)
+	assert.Equal(t, "GET /users (4)", files[1].Requests[0].Name)
+	assert.Equal(t, "createUser", files[1].Requests[1].Name)
+	assert.Equal(t, "GET /users (2)", files[1].Requests[2].Name)
+	assert.Len(t, warnings, 2)
+	assert.Contains(t, warnings[0], `users.http:5: duplicate test name "GET /users" renamed to "GET /users (3)"`)
+}
@@ -0,0 +1,60 @@
+package application
+
+import (
+	"sort"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// orderTests returns the files in the order their tests run in. For the
+// priority and random orders, each request of a file without chained
+// references becomes a file of its own so that tests of different files can
+// be interleaved; files with chained references keep their requests in order.
+func orderTests(files []*models.HTTPFile, order models.TestOrder, random *models.Random) []*models.HTTPFile {
+	if order == "" || order == models.TestOrderFile {
+		return files
+	}
+
+	var units []*models.HTTPFile
+	for _, file := range files {
+		if HasChainReferences(file) || len(file.Requests) < 2 {
+			units = append(units, file)
+			continue
+		}
+		for i := range file.Requests {
+			unit := *file
+			unit.Requests = file.Requests[i : i+1 : i+1]
+			units = append(units, &unit)
+		}
+	}
+
+	switch order {
+	case models.TestOrderPriority:
+		sort.SliceStable(units, func(i, j int) bool {
+			a, b := filePriority(units[i]), filePriority(units[j])
+			// Tests without a priority run last
+			if a == 0 || b == 0 {
+				return a != 0 && b == 0
+			}
+			return a < b
+		})
+	case models.TestOrderRandom:
+		for i := len(units) - 1; i > 0; i-- {
+			j := random.Intn(i + 1)
+			units[i], units[j] = units[j], units[i]
+		}
+	}
+	return units
+}
+
+// filePriority returns the highest priority, the lowest "@priority", of the
+// requests of a file, or 0 when none has one
+func filePriority(file *models.HTTPFile) int {
+	priority := 0
+	for _, request := range file.Requests {
+		if request.Priority > 0 && (priority == 0 || request.Priority < priority) {
+			priority = request.Priority
+		}
+	}
+	return priority
+}
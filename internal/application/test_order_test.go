@@ -0,0 +1,46 @@
+package application
+
+import (
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func orderedNames(files []*models.HTTPFile) []string {
+	var names []string
+	for _, file := range files {
+		for _, request := range file.Requests {
+			names = append(names, request.Name)
+		}
+	}
+	return names
+}
+
+func TestOrderTests(t *testing.T) {
+	files := []*models.HTTPFile{
+		{Filename: "users.http", Requests: []models.HTTPRequest{
+			{Name: "listUsers", URL: "/users"},
+			{Name: "health", URL: "/health", Priority: 1},
+		}},
+		{Filename: "orders.http", Requests: []models.HTTPRequest{
+			{Name: "createOrder", URL: "/orders"},
+			{Name: "getOrder", URL: "/orders/{{createOrder.response.body.$.id}}", Priority: 2},
+		}},
+		{Filename: "login.http", Requests: []models.HTTPRequest{
+			{Name: "login", URL: "/login", Priority: 2},
+		}},
+	}
+
+	assert.Equal(t, []string{"listUsers", "health", "createOrder", "getOrder", "login"},
+		orderedNames(orderTests(files, models.TestOrderFile, nil)))
+
+	// Chained files keep their order and run at the highest priority of their tests
+	assert.Equal(t, []string{"health", "createOrder", "getOrder", "login", "listUsers"},
+		orderedNames(orderTests(files, models.TestOrderPriority, nil)))
+
+	// The same seed gives the same order
+	first := orderedNames(orderTests(files, models.TestOrderRandom, models.NewRandom(42)))
+	assert.Equal(t, first, orderedNames(orderTests(files, models.TestOrderRandom, models.NewRandom(42))))
+	assert.ElementsMatch(t, []string{"listUsers", "health", "createOrder", "getOrder", "login"}, first)
+}
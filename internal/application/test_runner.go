@@ -2,29 +2,51 @@ package application
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/edgardnogueira/swagger-to-http/internal/application/transform"
 	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/edgardnogueira/swagger-to-http/internal/version"
 )
 
 // TestRunnerService implements the TestRunner interface
 type TestRunnerService struct {
-	httpExecutor    HTTPExecutor
-	snapshotManager SnapshotManager
-	fileWriter      FileWriter
+	httpExecutor     HTTPExecutor
+	snapshotManager  SnapshotManager
+	fileWriter       FileWriter
+	variableResolver VariableResolver
+	rateLimiter      rateLimiter
+	serialGroups     serialGroups
+	prompted         promptedVariables
+}
+
+// TestRunnerOption configures a TestRunnerService
+type TestRunnerOption func(*TestRunnerService)
+
+// WithVariableResolver sets the resolver for variables scoped to HTTP files
+func WithVariableResolver(resolver VariableResolver) TestRunnerOption {
+	return func(s *TestRunnerService) {
+		s.variableResolver = resolver
+	}
 }
 
 // NewTestRunnerService creates a new TestRunnerService
-func NewTestRunnerService(executor HTTPExecutor, snapshotManager SnapshotManager, fileWriter FileWriter) *TestRunnerService {
-	return &TestRunnerService{
+func NewTestRunnerService(executor HTTPExecutor, snapshotManager SnapshotManager, fileWriter FileWriter, options ...TestRunnerOption) *TestRunnerService {
+	s := &TestRunnerService{
 		httpExecutor:    executor,
 		snapshotManager: snapshotManager,
 		fileWriter:      fileWriter,
 	}
+	for _, option := range options {
+		option(s)
+	}
+	return s
 }
 
 // RunTests runs tests based on HTTP files and options
@@ -49,22 +71,67 @@ func (s *TestRunnerService) RunTests(ctx context.Context, patterns []string, opt
 		report.Environment[k] = v
 	}
 
-	// Set start time for the test run
-	report.Summary.StartTime = time.Now()
+	// Tell apart the tests whose derived names collide
+	report.Warnings = append(report.Warnings, DedupeTestNames(files)...)
 
-	// Run the tests (parallel or sequential)
-	if options.Parallel && options.MaxConcurrent > 0 {
-		results, err := s.runTestsParallel(ctx, files, options)
-		if err != nil {
-			return nil, err
+	// Draw all the randomness of the run from its seed, recorded so that the
+	// run can be replayed
+	seed := options.Seed
+	if seed == 0 {
+		seed = models.NewSeed()
+	}
+	ctx = models.ContextWithRandom(ctx, models.NewRandom(seed))
+	report.Environment[models.SeedEnvironmentKey] = strconv.FormatInt(seed, 10)
+
+	// Run only the requests marked "@only" when there are any, warning about
+	// it so that a focused suite isn't mistaken for a full run
+	if only := countOnly(files); only > 0 {
+		options.FocusOnly = true
+		report.Warnings = append(report.Warnings, fmt.Sprintf("%d requests are marked @only: the other tests are skipped", only))
+	}
+	if len(options.Focus) > 0 {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("focused on %s: the other tests are skipped", strings.Join(options.Focus, ", ")))
+	}
+
+	// Reorder the tests by priority or randomly, with the seed of the run
+	files = orderTests(files, options.Order, models.RandomFromContext(ctx))
+
+	// Track where the variables of the run come from
+	tracker := models.NewVariableTracker()
+	ctx = models.ContextWithVariableTracker(ctx, tracker)
+
+	// Check that the HTTP files were generated by a compatible version
+	for _, file := range files {
+		if err := version.CheckCompatibility(file.ToolVersion); err != nil {
+			if options.StrictVersion {
+				return nil, fmt.Errorf("%s: %w", file.Filename, err)
+			}
+			report.Warnings = append(report.Warnings, fmt.Sprintf("%s: %v", file.Filename, err))
 		}
-		report.Results = results
-	} else {
-		results, err := s.runTestsSequential(ctx, files, options)
-		if err != nil {
+	}
+
+	// Without input, stop before sending anything when variables are missing
+	if options.NoInput {
+		if err := s.checkMissingVariables(ctx, files, options); err != nil {
 			return nil, err
 		}
-		report.Results = results
+	}
+
+	// Say that the tests a previous run completed aren't run again
+	if resumed := options.Checkpoint.Len(); resumed > 0 {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("resuming from checkpoint: %d tests completed by the previous run are not run again", resumed))
+	}
+
+	// Set start time for the test run
+	report.Summary.StartTime = time.Now()
+	options = options.WithRunDeadline(report.Summary.StartTime)
+	if options.Progress != nil {
+		options.Progress.Start(s.countTests(files, options))
+	}
+
+	// Run the tests once, or once per language and data row
+	if err := s.runMatrix(ctx, files, options, report); err != nil {
+		return nil, err
 	}
 
 	// Set end time for the test run
@@ -74,6 +141,27 @@ func (s *TestRunnerService) RunTests(ctx context.Context, patterns []string, opt
 	// Calculate summary statistics
 	s.calculateSummary(&report.Summary, report.Results)
 
+	// Reflect the tests that didn't complete because the run timeout was reached
+	if timedOut := models.CountRunTimeouts(report.Results); timedOut > 0 {
+		report.Summary.RunTimedOut = true
+		report.Warnings = append(report.Warnings, fmt.Sprintf("run timeout of %s reached: %d tests did not complete", options.RunTimeout, timedOut))
+	}
+
+	// Tell that the memory limit slowed the run down or moved bodies to disk
+	if throttled, spilled := options.Memory.Throttled(), options.Memory.Spilled(); throttled > 0 || spilled > 0 {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("memory limit reached: %d requests waited for others to finish, %d bodies were spilled to %s", throttled, spilled, options.Memory.SpillDir()))
+	}
+	if err := options.Memory.Err(); err != nil {
+		report.Warnings = append(report.Warnings, err.Error())
+	}
+
+	// The tests not recorded will run again when resuming
+	if err := options.Checkpoint.Err(); err != nil {
+		report.Warnings = append(report.Warnings, err.Error())
+	}
+
+	report.Variables = tracker.Provenance()
+
 	return report, nil
 }
 
@@ -86,20 +174,293 @@ func (s *TestRunnerService) RunTest(ctx context.Context, request *models.HTTPReq
 		Name:     request.Name,
 		Request:  request,
 		FilePath: request.Path,
-		Tags:     []string{request.Tag},
+		Tags:     request.TagList(),
 		Status:   models.TestStatusSkipped,
 	}
 
+	// Don't start new requests once the run timeout was reached
+	if options.RunDeadlineExceeded() {
+		result.Error = models.RunTimeoutReason
+		return result, nil
+	}
+
+	// Skip the requests marked "@skip" and those left out by the focus
+	if reason, skip := options.SkipReason(request); skip {
+		result.Error = reason
+		return result, nil
+	}
+
+	// Take the result of the tests the run being resumed completed
+	if prior, ok := options.Checkpoint.Completed(models.CheckpointID(request, options)); ok {
+		prior.Request = request
+		prior.Resumed = true
+		return &prior, nil
+	}
+
+	// Request the language of the current run
+	if options.Language != "" {
+		request = withHeader(request, "Accept-Language", options.Language)
+	}
+
+	// Override the values of the query parameters given on the command line
+	if params, names := models.OverrideQueryParams(request.QueryParams, options.QueryParams); len(names) > 0 {
+		withParams := *request
+		withParams.QueryParams = params
+		request = &withParams
+	}
+
+	// Resolve relative URLs against the host given on the command line rather
+	// than the file's "@host"
+	if options.Host != "" {
+		withHost := *request
+		withHost.Host = options.Host
+		request = &withHost
+	}
+
+	// Point the request at the selected server
+	if options.BaseURL != "" {
+		rebased := *request
+		rebased.URL = rebaseURL(request.URL, options.BaseURL)
+		request = &rebased
+	}
+
+	// Request pages of the configured size
+	request = withPageSize(request)
+
+	// Apply the global array order option unless the request sets its own
+	if options.IgnoreArrayOrder && !request.IgnoreArrayOrder {
+		withOrder := *request
+		withOrder.IgnoreArrayOrder = true
+		withOrder.ArrayOrderKey = options.ArrayOrderKey
+		request = &withOrder
+	}
+
+	// Apply the global redirect policy unless the request sets its own
+	if options.Redirects != nil && request.Redirects == nil {
+		withRedirects := *request
+		withRedirects.Redirects = options.Redirects
+		request = &withRedirects
+	}
+
+	// Apply the global retry options unless the request sets its own
+	if options.Retries != nil && request.Retries == nil {
+		withRetries := *request
+		withRetries.Retries = options.Retries
+		request = &withRetries
+	}
+
+	// Apply the global response body limit unless the request sets its own
+	if options.MaxBodyBytes > 0 && request.MaxBodyBytes == 0 {
+		withLimit := *request
+		withLimit.MaxBodyBytes = options.MaxBodyBytes
+		request = &withLimit
+	}
+
+	// Apply the global ignored headers, which the request's own can override
+	if len(options.IgnoreHeaders) > 0 {
+		withIgnored := *request
+		withIgnored.IgnoreHeaders = models.MergeHeaderPatterns(options.IgnoreHeaders, request.IgnoreHeaders)
+		request = &withIgnored
+	}
+
+	// Resolve the variables that apply to the request's file
+	variables, err := s.requestVariables(ctx, request, options)
+	if err != nil {
+		result.Status = models.TestStatusError
+		result.Error = err.Error()
+		return result, nil
+	}
+
+	// Ask for the variables that have no value rather than sending them as
+	// written, e.g. a literal {{PASSWORD}}
+	variables, err = s.prompted.fill(ctx, request, variables, options.PromptVariable)
+	if err != nil {
+		result.Status = models.TestStatusError
+		result.Error = err.Error()
+		return result, nil
+	}
+
+	// Fail requests still using variables without a value when they are
+	// strict, before anything else; the executor checks them again once the
+	// callback URLs are set
+	ctx = models.ContextWithStrictVariables(ctx, options.StrictVars)
+	if err := models.CheckVariables(ctx, request, variables); err != nil {
+		result.Status = models.TestStatusError
+		result.Error = err.Error()
+		return result, nil
+	}
+
+	// Don't change data on shared environments unless allowed
+	if err := CheckMutation(request, variables, options.Guard); err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+
+	// Cancel the request when the run timeout is reached
+	execCtx := ctx
+	if !options.RunDeadline.IsZero() {
+		var cancel context.CancelFunc
+		execCtx, cancel = context.WithDeadline(ctx, options.RunDeadline)
+		defer cancel()
+	}
+
+	// Listen for the callbacks the server is expected to send, with their URLs
+	// set as variables for the request
+	var listener *callbackListener
+	if len(request.Callbacks) > 0 {
+		listener, err = startCallbackListener(options.CallbackAddr, options.CallbackURL)
+		if err != nil {
+			result.Status = models.TestStatusError
+			result.Error = err.Error()
+			return result, nil
+		}
+		defer listener.Close()
+
+		withCallbacks := make(map[string]string, len(variables)+len(request.Callbacks))
+		for k, v := range variables {
+			withCallbacks[k] = v
+		}
+		for _, callback := range request.Callbacks {
+			withCallbacks[models.CallbackVariable(callback.Name)] = listener.URL(callback.Name)
+		}
+		variables = withCallbacks
+	}
+
+	// Wait for the other requests of the serial group to finish
+	release, err := s.serialGroups.acquire(execCtx, request)
+	if err != nil {
+		result.Status = models.TestStatusError
+		result.Error = fmt.Sprintf("serial group wait cancelled: %v", err)
+		if options.RunDeadlineExceeded() {
+			result.Error = fmt.Sprintf("%s: %s", models.RunTimeoutReason, result.Error)
+		}
+		return result, nil
+	}
+	defer release()
+
+	// Wait for the other requests to finish while over the memory limit
+	if err := options.Memory.Acquire(execCtx); err != nil {
+		result.Status = models.TestStatusError
+		result.Error = fmt.Sprintf("memory limit wait cancelled: %v", err)
+		if options.RunDeadlineExceeded() {
+			result.Error = fmt.Sprintf("%s: %s", models.RunTimeoutReason, result.Error)
+		}
+		return result, nil
+	}
+	defer options.Memory.Release()
+
+	// Wait for a free slot when the operation has a rate limit
+	if err := s.rateLimiter.wait(execCtx, request); err != nil {
+		result.Status = models.TestStatusError
+		result.Error = fmt.Sprintf("rate limit wait cancelled: %v", err)
+		if options.RunDeadlineExceeded() {
+			result.Error = fmt.Sprintf("%s: %s", models.RunTimeoutReason, result.Error)
+		}
+		return result, nil
+	}
+
 	// Execute the request
-	response, err := s.httpExecutor.Execute(ctx, request, options.EnvironmentVars)
+	response, err := s.httpExecutor.Execute(execCtx, request, variables)
 	if err != nil {
 		result.Status = models.TestStatusError
 		result.Error = err.Error()
+		var timeoutErr *models.TimeoutError
+		if errors.As(err, &timeoutErr) {
+			result.TimeoutPhase = timeoutErr.Phase
+		}
+		var circuitErr *models.CircuitOpenError
+		if errors.As(err, &circuitErr) {
+			result.Status = models.TestStatusCircuitOpen
+		}
+		if options.RunDeadlineExceeded() {
+			result.Error = fmt.Sprintf("%s: request cancelled: %v", models.RunTimeoutReason, err)
+		}
 		return result, nil
 	}
 
 	result.Response = response
 	result.Duration = time.Since(startTime)
+	result.Retries = response.Retries
+
+	// Save the response to the files of the request's ">> path" lines
+	if len(request.Outputs) > 0 {
+		result.Outputs, err = models.WriteResponseOutputs(request, response, variables)
+		if err != nil {
+			result.Status = models.TestStatusError
+			result.Error = err.Error()
+			return result, nil
+		}
+	}
+
+	// Wait for the callbacks; a missing or incomplete callback fails the test
+	// once the response itself has been checked
+	if listener != nil {
+		var assertions []models.TestAssertionResult
+		result.Callbacks, assertions = awaitCallbacks(execCtx, listener, request.Callbacks)
+		result.AssertionResults = append(result.AssertionResults, assertions...)
+		defer failOnAssertions(result, assertions)
+	}
+
+	// Check the expected status; a mismatch fails the test once the rest of the
+	// response has been checked
+	if request.ExpectStatus != "" {
+		assertion := expectStatusAssertion(request.ExpectStatus, response)
+		result.AssertionResults = append(result.AssertionResults, assertion)
+		defer failOnAssertions(result, []models.TestAssertionResult{assertion})
+	}
+
+	// Check the content encoding the server used; a mismatch fails the test
+	// once the rest of the response has been checked
+	if request.ExpectEncoding != "" {
+		assertion := expectEncodingAssertion(request.ExpectEncoding, response)
+		result.AssertionResults = append(result.AssertionResults, assertion)
+		defer failOnAssertions(result, []models.TestAssertionResult{assertion})
+	}
+
+	// Check the expected headers; a mismatch fails the test once the rest of
+	// the response has been checked
+	if len(request.ExpectHeaders) > 0 {
+		assertions := expectHeaderAssertions(request.ExpectHeaders, response)
+		result.AssertionResults = append(result.AssertionResults, assertions...)
+		defer failOnAssertions(result, assertions)
+	}
+
+	// Check the budgets of the test's tags; violations fail the test once the
+	// rest of the response has been checked
+	if assertions := models.CheckBudgets(options.Budgets, result.Tags, response); len(assertions) > 0 {
+		result.AssertionResults = append(result.AssertionResults, assertions...)
+		defer failOnAssertions(result, assertions)
+	}
+
+	// Validate the XML response against the request's schema; problems fail
+	// the test once the rest of the response has been checked
+	if request.XSD != "" {
+		assertion := xsdAssertion(request, response)
+		result.AssertionResults = append(result.AssertionResults, assertion)
+		defer failOnAssertions(result, []models.TestAssertionResult{assertion})
+	}
+
+	// Fetch the following pages of a paginated list; a failing page fails the
+	// test once the first page has been checked
+	if request.Paginate != nil {
+		var assertions []models.TestAssertionResult
+		result.Pagination, assertions = s.followPages(execCtx, request, response, variables)
+		result.AssertionResults = append(result.AssertionResults, assertions...)
+		defer failOnAssertions(result, assertions)
+	}
+
+	// Normalize the response body before snapshot comparison and assertions
+	if request.SnapshotTransform != "" {
+		transformed, err := transformResponse(response, request.SnapshotTransform)
+		if err != nil {
+			result.Status = models.TestStatusError
+			result.Error = err.Error()
+			return result, nil
+		}
+		result.RawResponse = response
+		result.Response = transformed
+		response = transformed
+	}
 
 	// Generate snapshot path
 	snapshotPath := s.generateSnapshotPath(request, options)
@@ -124,7 +485,7 @@ func (s *TestRunnerService) RunTest(ctx context.Context, request *models.HTTPReq
 	}
 
 	// Try to load existing snapshot
-	snapshot, err := s.snapshotManager.LoadSnapshot(ctx, snapshotPath)
+	_, err = s.snapshotManager.LoadSnapshot(ctx, snapshotPath)
 	if err != nil {
 		// Snapshot doesn't exist
 		if options.UpdateSnapshots == "missing" {
@@ -142,12 +503,18 @@ func (s *TestRunnerService) RunTest(ctx context.Context, request *models.HTTPReq
 					}
 				}
 			}
+		} else if request.ExpectStatus != "" {
+			// The expected status is the test until a snapshot is recorded
+			result.Status = models.TestStatusPassed
+			result.SnapshotMissing = true
 		} else if options.FailOnMissing {
 			result.Status = models.TestStatusFailed
 			result.Error = "snapshot missing"
+			result.SnapshotMissing = true
 		} else {
 			result.Status = models.TestStatusPassed
 			result.Error = "snapshot missing, not failing due to configuration"
+			result.SnapshotMissing = true
 		}
 		return result, nil
 	}
@@ -199,22 +566,26 @@ func (s *TestRunnerService) RunTest(ctx context.Context, request *models.HTTPReq
 func (s *TestRunnerService) RunTestFile(ctx context.Context, file *models.HTTPFile, options models.TestRunOptions) ([]*models.TestResult, error) {
 	var results []*models.TestResult
 
+	// Responses of earlier requests in the file, for chained references
+	chain := NewResponseChain()
+
 	for _, request := range file.Requests {
 		// Check if the test meets the filter criteria
-		if !s.matchesFilter(request, options.Filter) {
+		if !s.matchesFilter(&request, options.Filter) {
 			continue
 		}
 
 		// Set the file path in the request
 		request.Path = file.Filename
 
-		// Run the test
-		result, err := s.RunTest(ctx, &request, options)
+		// Run the test with references to earlier responses resolved
+		result, err := s.runChainedTest(ctx, chain, &request, options)
 		if err != nil {
 			return nil, err
 		}
 
 		results = append(results, result)
+		reportProgress(ctx, options, result)
 
 		// Stop on failure if configured
 		if options.StopOnFailure && (result.Status == models.TestStatusFailed || result.Status == models.TestStatusError) {
@@ -320,17 +691,86 @@ func (s *TestRunnerService) runTestsParallel(ctx context.Context, files []*model
 	// Create work queue
 	workQueue := make(chan workItem, totalRequests)
 
-	// Fill work queue
+	// Requests of the same serial group form a single work item, so they run
+	// in order in one worker while the other workers carry on. Serial groups
+	// are queued first, as they take the longest.
+	var items []workItem
+	serial := make(map[string]int)
+	var serialItems []workItem
 	for _, file := range files {
+		// Files with chained requests must run in order, so they form a single work item
+		if HasChainReferences(file) {
+			items = append(items, workItem{
+				file:       file,
+				requestIdx: wholeFile,
+			})
+			continue
+		}
+
 		for i := range file.Requests {
-			workQueue <- workItem{
+			if group := file.Requests[i].SerialGroup; group != "" {
+				index, ok := serial[group]
+				if !ok {
+					index = len(serialItems)
+					serial[group] = index
+					serialItems = append(serialItems, workItem{requestIdx: serialGroup})
+				}
+				serialItems[index].serial = append(serialItems[index].serial, workItem{file: file, requestIdx: i})
+				continue
+			}
+			items = append(items, workItem{
 				file:        file,
 				requestIdx:  i,
-			}
+			})
 		}
 	}
+	for _, item := range append(serialItems, items...) {
+		workQueue <- item
+	}
 	close(workQueue)
 
+	// runRequest runs a request of a file and sends its result, reporting
+	// whether the worker carries on
+	runRequest := func(file *models.HTTPFile, requestIdx int) bool {
+		req := file.Requests[requestIdx]
+
+		// Check if the test meets the filter criteria
+		if !s.matchesFilter(&req, options.Filter) {
+			return true
+		}
+
+		// Set the file path in the request
+		req.Path = file.Filename
+
+		// Clone the options to avoid race conditions
+		localOpts := options
+
+		// Run the test
+		result, err := s.RunTest(ctx, &req, localOpts)
+		if err != nil {
+			select {
+			case errChan <- err:
+				// Error sent
+			default:
+				// Another error was already sent
+			}
+			return false
+		}
+
+		reportProgress(ctx, options, result)
+
+		// Send result
+		select {
+		case resultChan <- result:
+			// Result sent
+		case <-ctx.Done():
+			return false
+		}
+
+		// Stop on failure if configured
+		return !options.StopOnFailure || (result.Status != models.TestStatusFailed && result.Status != models.TestStatusError)
+	}
+
 	// Start workers
 	for i := 0; i < numWorkers; i++ {
 		wg.Add(1)
@@ -346,42 +786,38 @@ func (s *TestRunnerService) runTestsParallel(ctx context.Context, files []*model
 				}
 
 				file := work.file
-				req := file.Requests[work.requestIdx]
 
-				// Check if the test meets the filter criteria
-				if !s.matchesFilter(&req, options.Filter) {
-					continue
-				}
-
-				// Set the file path in the request
-				req.Path = file.Filename
-
-				// Clone the options to avoid race conditions
-				localOpts := options
-
-				// Run the test
-				result, err := s.RunTest(ctx, &req, localOpts)
-				if err != nil {
-					select {
-					case errChan <- err:
-						// Error sent
-						return
-					default:
-						// Another error was already sent, just return
+				// Run chained files sequentially within this worker
+				if work.requestIdx == wholeFile {
+					fileResults, err := s.RunTestFile(ctx, file, options)
+					if err != nil {
+						select {
+						case errChan <- err:
+						default:
+						}
 						return
 					}
+					for _, result := range fileResults {
+						select {
+						case resultChan <- result:
+						case <-ctx.Done():
+							return
+						}
+					}
+					continue
 				}
 
-				// Send result
-				select {
-				case resultChan <- result:
-					// Result sent
-				case <-ctx.Done():
-					return
+				// Run the requests of a serial group in order
+				if work.requestIdx == serialGroup {
+					for _, request := range work.serial {
+						if !runRequest(request.file, request.requestIdx) {
+							return
+						}
+					}
+					continue
 				}
 
-				// Stop on failure if configured
-				if options.StopOnFailure && (result.Status == models.TestStatusFailed || result.Status == models.TestStatusError) {
+				if !runRequest(file, work.requestIdx) {
 					return
 				}
 			}
@@ -411,20 +847,560 @@ func (s *TestRunnerService) runTestsParallel(ctx context.Context, files []*model
 	}
 }
 
+// runFiles runs the tests of the files in dependency order, in parallel or sequentially
+func (s *TestRunnerService) runFiles(ctx context.Context, files []*models.HTTPFile, options models.TestRunOptions) ([]models.TestResult, error) {
+	var results []models.TestResult
+	var err error
+	switch {
+	case HasDependencies(files):
+		results, err = s.runTestsWithDependencies(ctx, files, options)
+	case options.Parallel && options.MaxConcurrent > 0:
+		results, err = s.runTestsParallel(ctx, files, options)
+	default:
+		results, err = s.runTestsSequential(ctx, files, options)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Mark the known failures, which don't fail the build, by their test names
+	for i := range results {
+		models.ApplyQuarantine(&results[i], options.Quarantine)
+	}
+	return results, nil
+}
+
+// runMatrix runs the tests once per configured language, collecting the status of
+// each test per language into the report's language matrix
+func (s *TestRunnerService) runMatrix(ctx context.Context, files []*models.HTTPFile, options models.TestRunOptions, report *models.TestReport) error {
+	if len(options.Languages) == 0 {
+		return s.runVariants(ctx, files, options, report)
+	}
+
+	report.Languages = options.Languages
+	matrix := make(map[string]*models.LanguageMatrixRow)
+	var order []string
+
+	for _, language := range options.Languages {
+		langOptions := options
+		langOptions.Language = language
+
+		langReport := &models.TestReport{}
+		if err := s.runVariants(ctx, files, langOptions, langReport); err != nil {
+			return fmt.Errorf("language %s: %w", language, err)
+		}
+
+		// Identify the language in the test names and fill in the matrix, a
+		// row per test: files may have requests of the same name
+		for i := range langReport.Results {
+			result := &langReport.Results[i]
+			key := result.FilePath + "#" + result.Name
+			row, ok := matrix[key]
+			if !ok {
+				row = &models.LanguageMatrixRow{Test: result.Name, File: result.FilePath, Statuses: make(map[string]models.TestStatus)}
+				matrix[key] = row
+				order = append(order, key)
+			}
+			row.Statuses[language] = result.Status
+
+			result.Name = fmt.Sprintf("%s [%s]", result.Name, language)
+			result.Language = language
+		}
+		report.Results = append(report.Results, langReport.Results...)
+
+		for _, row := range langReport.DataRows {
+			row.ID = fmt.Sprintf("%s %s", language, row.ID)
+			report.DataRows = append(report.DataRows, row)
+		}
+	}
+
+	for _, key := range order {
+		report.LanguageMatrix = append(report.LanguageMatrix, *matrix[key])
+	}
+
+	return nil
+}
+
+// runVariants runs the tests once, or once per data row when data rows are configured
+func (s *TestRunnerService) runVariants(ctx context.Context, files []*models.HTTPFile, options models.TestRunOptions, report *models.TestReport) error {
+	if len(options.DataRows) > 0 {
+		return s.runDataRows(ctx, files, options, report)
+	}
+
+	results, err := s.runFiles(ctx, files, options)
+	if err != nil {
+		return err
+	}
+	report.Results = results
+	return nil
+}
+
+// runDataRows runs the tests once per data row, injecting the row's columns as
+// variables and summarizing the results of each row in the report
+func (s *TestRunnerService) runDataRows(ctx context.Context, files []*models.HTTPFile, options models.TestRunOptions, report *models.TestReport) error {
+	for i := range options.DataRows {
+		row := &options.DataRows[i]
+		rowOptions := options
+		rowOptions.DataRow = row
+
+		results, err := s.runFiles(ctx, files, rowOptions)
+		if err != nil {
+			return fmt.Errorf("data row %s: %w", row.ID, err)
+		}
+
+		// Identify the row in the test names
+		for j := range results {
+			results[j].Name = fmt.Sprintf("%s [%s]", results[j].Name, row.ID)
+			results[j].DataRow = row.ID
+		}
+		report.Results = append(report.Results, results...)
+
+		summary := models.DataRowSummary{ID: row.ID}
+		s.calculateSummary(&summary.Summary, results)
+		report.DataRows = append(report.DataRows, summary)
+
+		// Stop on failure if configured
+		if options.StopOnFailure && (summary.Summary.FailedTests > 0 || summary.Summary.ErrorTests > 0) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// runChainedTest resolves response references in a request, runs it and records its response in the chain
+func (s *TestRunnerService) runChainedTest(ctx context.Context, chain *ResponseChain, request *models.HTTPRequest, options models.TestRunOptions) (*models.TestResult, error) {
+	resolved, chainedVars, err := chain.Resolve(request)
+	if err != nil {
+		return &models.TestResult{
+			Name:     request.Name,
+			Request:  request,
+			FilePath: request.Path,
+			Tags:     request.TagList(),
+			Status:   models.TestStatusError,
+			Error:    err.Error(),
+		}, nil
+	}
+
+	result, err := s.RunTest(ctx, resolved, options)
+	if err != nil {
+		return nil, err
+	}
+	if len(chainedVars) > 0 {
+		result.ChainedVars = chainedVars
+		recordChainedVars(ctx, request, chainedVars)
+	}
+	// Later requests reference the response as received
+	if result.RawResponse != nil {
+		chain.Add(request.Name, result.RawResponse)
+	} else {
+		chain.Add(request.Name, result.Response)
+	}
+
+	return result, nil
+}
+
+// recordChainedVars records the provenance of the response references
+// resolved in a request, e.g. "login.response.body.$.token" comes from the
+// response of login
+func recordChainedVars(ctx context.Context, request *models.HTTPRequest, chainedVars map[string]string) {
+	tracker := models.VariableTrackerFromContext(ctx)
+	for ref, value := range chainedVars {
+		step, extractor, _ := strings.Cut(ref, ".response.")
+		tracker.Record(request.Path, ref, models.VariableOrigin{
+			Source:    models.VariableSourceChained,
+			Step:      step,
+			Extractor: extractor,
+			Value:     value,
+		})
+	}
+}
+
+// runTestsWithDependencies runs the tests of each file in topological order of
+// their "@depends-on" declarations, see runFileWithDependencies. Dependencies
+// and response references resolve within a file, where names are unique.
+func (s *TestRunnerService) runTestsWithDependencies(ctx context.Context, files []*models.HTTPFile, options models.TestRunOptions) ([]models.TestResult, error) {
+	var ordered []models.TestResult
+	for _, file := range files {
+		results, stopped, err := s.runFileWithDependencies(ctx, file, options)
+		if err != nil {
+			return nil, err
+		}
+		ordered = append(ordered, results...)
+		if stopped {
+			break
+		}
+	}
+	return ordered, nil
+}
+
+// runFileWithDependencies runs the tests of a file in topological order with a
+// response chain of its own. Independent requests of the same level run in parallel
+// when enabled, and dependents of a request that didn't pass fail without being
+// executed. It reports whether the run stopped on a failure.
+func (s *TestRunnerService) runFileWithDependencies(ctx context.Context, file *models.HTTPFile, options models.TestRunOptions) ([]models.TestResult, bool, error) {
+	// Collect all requests so filtered requests can still pull in their dependencies
+	var requests []*models.HTTPRequest
+	var selected []bool
+	for i := range file.Requests {
+		request := file.Requests[i]
+		request.Path = file.Filename
+		requests = append(requests, &request)
+		selected = append(selected, s.matchesFilter(&request, options.Filter))
+	}
+
+	graph, err := NewDependencyGraph(requests)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid request dependencies in %s: %w", file.Filename, err)
+	}
+	levels, err := graph.Levels()
+	if err != nil {
+		return nil, false, err
+	}
+
+	// Mark the dependencies of selected requests as needed
+	needed := make([]bool, len(requests))
+	var markNeeded func(i int)
+	markNeeded = func(i int) {
+		if needed[i] {
+			return
+		}
+		needed[i] = true
+		for _, dep := range graph.Dependencies(i) {
+			markNeeded(dep)
+		}
+	}
+	for i := range requests {
+		if selected[i] {
+			markNeeded(i)
+		}
+	}
+
+	// Limit concurrency within a level
+	concurrency := 1
+	if options.Parallel && options.MaxConcurrent > 0 {
+		concurrency = options.MaxConcurrent
+	}
+
+	chain := NewResponseChain()
+	results := make([]*models.TestResult, len(requests))
+	stopped := false
+
+	for _, level := range levels {
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		var runErr error
+		sem := make(chan struct{}, concurrency)
+
+		for _, i := range level {
+			if !needed[i] {
+				continue
+			}
+
+			// Don't start new requests once the run timeout was reached
+			if options.RunDeadlineExceeded() {
+				request := graph.Request(i)
+				results[i] = &models.TestResult{
+					Name:     request.Name,
+					Request:  request,
+					FilePath: request.Path,
+					Tags:     request.TagList(),
+					Status:   models.TestStatusSkipped,
+					Error:    models.RunTimeoutReason,
+				}
+				if selected[i] {
+					reportProgress(ctx, options, results[i])
+				}
+				continue
+			}
+
+			// Fail fast when a dependency didn't pass
+			if dep := failedDependency(graph, results, i); dep != "" {
+				request := graph.Request(i)
+				results[i] = &models.TestResult{
+					Name:     request.Name,
+					Request:  request,
+					FilePath: request.Path,
+					Tags:     request.TagList(),
+					Status:   models.TestStatusFailed,
+					Error:    fmt.Sprintf("not run: dependency %q did not pass", dep),
+				}
+				if selected[i] {
+					reportProgress(ctx, options, results[i])
+				}
+				continue
+			}
+
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(i int) {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				result, err := s.runChainedTest(ctx, chain, graph.Request(i), options)
+
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					if runErr == nil {
+						runErr = err
+					}
+					return
+				}
+				results[i] = result
+				if selected[i] {
+					reportProgress(ctx, options, result)
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		if runErr != nil {
+			return nil, false, runErr
+		}
+
+		// Stop on failure if configured
+		if options.StopOnFailure && levelFailed(results, level) {
+			stopped = true
+			break
+		}
+	}
+
+	// Return results in file order
+	var ordered []models.TestResult
+	for i, result := range results {
+		if result != nil && selected[i] {
+			ordered = append(ordered, *result)
+		}
+	}
+
+	return ordered, stopped, nil
+}
+
+// countTests returns the number of tests a run will execute, counting each
+// language and data row variant separately
+func (s *TestRunnerService) countTests(files []*models.HTTPFile, options models.TestRunOptions) int {
+	total := 0
+	for _, file := range files {
+		for i := range file.Requests {
+			if s.matchesFilter(&file.Requests[i], options.Filter) {
+				total++
+			}
+		}
+	}
+	if len(options.Languages) > 0 {
+		total *= len(options.Languages)
+	}
+	if len(options.DataRows) > 0 {
+		total *= len(options.DataRows)
+	}
+	return total
+}
+
+// reportProgress notifies the run's progress listener, if any, about a completed test,
+// records it in the run's checkpoint unless it didn't run or was cancelled, and
+// spills its bodies to disk when over the memory limit
+func reportProgress(ctx context.Context, options models.TestRunOptions, result *models.TestResult) {
+	if options.Progress != nil {
+		options.Progress.Completed(*result)
+	}
+	if options.Checkpoint != nil && result.Request != nil && !result.Resumed && result.Status != models.TestStatusSkipped && ctx.Err() == nil {
+		options.Checkpoint.Record(models.CheckpointID(result.Request, options), *result)
+	}
+	options.Memory.Spill(result)
+}
+
+// failedDependency returns the name of the first dependency of a request that didn't pass
+func failedDependency(graph *DependencyGraph, results []*models.TestResult, index int) string {
+	for _, dep := range graph.Dependencies(index) {
+		if results[dep] == nil || results[dep].Status != models.TestStatusPassed {
+			return graph.Request(dep).Name
+		}
+	}
+	return ""
+}
+
+// levelFailed checks if any test of a dependency level failed
+func levelFailed(results []*models.TestResult, level []int) bool {
+	for _, i := range level {
+		if results[i] != nil && (results[i].Status == models.TestStatusFailed || results[i].Status == models.TestStatusError) {
+			return true
+		}
+	}
+	return false
+}
+
+// withHeader returns a copy of the request with the header set, replacing existing values
+func withHeader(request *models.HTTPRequest, name, value string) *models.HTTPRequest {
+	result := *request
+	result.Headers = make(map[string]string, len(request.Headers)+1)
+	for header, headerValue := range request.Headers {
+		if !strings.EqualFold(header, name) {
+			result.Headers[header] = headerValue
+		}
+	}
+	result.Headers[name] = value
+	return &result
+}
+
+// expectStatusAssertion checks the status of a response against the status
+// expected with "@expect-status"
+func expectStatusAssertion(expected string, response *models.HTTPResponse) models.TestAssertionResult {
+	assertion := models.TestAssertionResult{
+		Type:        "status",
+		Source:      "status",
+		Expected:    expected,
+		Actual:      response.StatusCode,
+		Description: fmt.Sprintf("status is %s", expected),
+		Passed:      models.StatusMatches(expected, response.StatusCode),
+	}
+	if !assertion.Passed {
+		assertion.Error = fmt.Sprintf("expected status %s, got %d", expected, response.StatusCode)
+	}
+	return assertion
+}
+
+// expectEncodingAssertion checks the content encoding of a response against
+// the encoding expected with "@expect-encoding"
+func expectEncodingAssertion(expected string, response *models.HTTPResponse) models.TestAssertionResult {
+	actual := response.Encoding()
+	if actual == "" {
+		actual = models.EncodingIdentity
+	}
+	assertion := models.TestAssertionResult{
+		Type:        "encoding",
+		Source:      "header",
+		Path:        "Content-Encoding",
+		Expected:    expected,
+		Actual:      actual,
+		Description: fmt.Sprintf("content encoding is %s", expected),
+		Passed:      models.EncodingMatches(expected, actual),
+	}
+	if !assertion.Passed {
+		assertion.Error = fmt.Sprintf("expected %s content encoding, got %s", expected, actual)
+	}
+	return assertion
+}
+
+// expectHeaderAssertions checks the headers of a response against those
+// expected with "@expect-header"
+func expectHeaderAssertions(expected []models.ExpectedHeader, response *models.HTTPResponse) []models.TestAssertionResult {
+	assertions := make([]models.TestAssertionResult, 0, len(expected))
+	for _, header := range expected {
+		actual, matched := header.Matches(response.Headers)
+		assertion := models.TestAssertionResult{
+			Type:        "header",
+			Source:      "header",
+			Path:        header.Name,
+			Expected:    header.Value,
+			Actual:      actual,
+			Description: fmt.Sprintf("header %s", header),
+			Passed:      matched,
+		}
+		if !matched {
+			assertion.Error = fmt.Sprintf("expected header %s, got %q", header, actual)
+		}
+		assertions = append(assertions, assertion)
+	}
+	return assertions
+}
+
+// failOnAssertions fails a test that otherwise passed when one of the
+// assertions failed
+func failOnAssertions(result *models.TestResult, assertions []models.TestAssertionResult) {
+	if result.Status != models.TestStatusPassed {
+		return
+	}
+	for _, assertion := range assertions {
+		if !assertion.Passed {
+			result.Status = models.TestStatusFailed
+			result.Error = assertion.Error
+			return
+		}
+	}
+}
+
+// transformResponse returns a copy of the response with the transform applied to its body
+func transformResponse(response *models.HTTPResponse, expr string) (*models.HTTPResponse, error) {
+	body, err := transform.Apply(expr, []byte(response.Body))
+	if err != nil {
+		return nil, fmt.Errorf("snapshot transform failed: %w", err)
+	}
+
+	transformed := *response
+	transformed.Body = string(body)
+	return &transformed, nil
+}
+
+// requestVariables merges the environment variables with the variables scoped to
+// the request's file, the current data row and the command line, in increasing
+// order of precedence, see models.VariablePrecedence
+func (s *TestRunnerService) requestVariables(ctx context.Context, request *models.HTTPRequest, options models.TestRunOptions) (map[string]string, error) {
+	var scoped map[string]string
+	if s.variableResolver != nil && request.Path != "" {
+		var err error
+		scoped, err = s.variableResolver.ResolveVariables(ctx, request.Path, options)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve variables: %w", err)
+		}
+	}
+	// Record where the variables come from, later sources overriding earlier ones
+	tracker := models.VariableTrackerFromContext(ctx)
+	if options.BaseURL != "" {
+		tracker.Record(request.Path, BaseURLVariable, models.VariableOrigin{Source: models.VariableSourceBaseURL, Value: strings.TrimSuffix(options.BaseURL, "/")})
+	}
+	tracker.RecordAll(request.Path, models.VariableSourceEnvironment, options.EnvironmentVars)
+	tracker.RecordAll(request.Path, models.VariableSourceFile, scoped)
+	if options.DataRow != nil {
+		for name, value := range options.DataRow.Values {
+			tracker.Record(request.Path, name, models.VariableOrigin{Source: models.VariableSourceDataRow, Step: options.DataRow.ID, Value: value})
+		}
+	}
+	tracker.RecordAll(request.Path, models.VariableSourceCLI, options.Variables)
+
+	// Bare names take the value of the source of highest precedence, and
+	// namespaced ones such as {{env.TOKEN}} the value of their source
+	var set models.VariableSet
+	set.Set(models.VariableNamespaceEnv, options.EnvironmentVars)
+	set.Set(models.VariableNamespaceFile, scoped)
+	if options.DataRow != nil {
+		set.Set(models.VariableNamespaceData, options.DataRow.Values)
+	}
+	set.Set(models.VariableNamespaceCLI, options.Variables)
+	variables := set.Variables()
+
+	// HTTP files using "{{baseUrl}}" follow the selected server, unless they set it
+	if _, ok := variables[BaseURLVariable]; !ok && options.BaseURL != "" {
+		variables[BaseURLVariable] = strings.TrimSuffix(options.BaseURL, "/")
+	}
+
+	return variables, nil
+}
+
 // calculateSummary calculates the summary statistics for a test run
 func (s *TestRunnerService) calculateSummary(summary *models.TestSummary, results []models.TestResult) {
 	summary.TotalTests = len(results)
 	
 	for _, result := range results {
-		switch result.Status {
-		case models.TestStatusPassed:
+		if result.Quarantined {
+			summary.QuarantinedTests++
+		}
+
+		switch {
+		case result.QuarantinedFailure():
+			// Known failures are counted apart so they don't fail the run
+			summary.QuarantinedFailures++
+		case result.Status == models.TestStatusPassed:
 			summary.PassedTests++
-		case models.TestStatusFailed:
+		case result.Status == models.TestStatusFailed:
 			summary.FailedTests++
-		case models.TestStatusSkipped:
+		case result.Status == models.TestStatusSkipped:
 			summary.SkippedTests++
-		case models.TestStatusError:
+		case result.Status == models.TestStatusError:
 			summary.ErrorTests++
+		case result.Status == models.TestStatusCircuitOpen:
+			summary.ErrorTests++
+			summary.CircuitOpenTests++
 		}
 
 		if result.SnapshotResult != nil {
@@ -437,12 +1413,23 @@ func (s *TestRunnerService) calculateSummary(summary *models.TestSummary, result
 			}
 		}
 	}
+
+	summary.AddBreakdowns(results, models.DefaultSlowestRequests)
 }
 
 // generateSnapshotPath generates a path for storing a snapshot
 func (s *TestRunnerService) generateSnapshotPath(request *models.HTTPRequest, options models.TestRunOptions) string {
 	// Use the configured snapshot directory or default
-	snapshotDir := options.Filter.Paths[0]
+	snapshotDir := ""
+	if len(options.Filter.Paths) > 0 {
+		snapshotDir = options.Filter.Paths[0]
+	}
+	return SnapshotPath(snapshotDir, request, options)
+}
+
+// SnapshotPath returns the path of the snapshot of a request in a snapshot
+// directory, ".snapshots" when empty, for the language and data row of the options
+func SnapshotPath(snapshotDir string, request *models.HTTPRequest, options models.TestRunOptions) string {
 	if snapshotDir == "" {
 		snapshotDir = ".snapshots"
 	}
@@ -457,28 +1444,33 @@ func (s *TestRunnerService) generateSnapshotPath(request *models.HTTPRequest, op
 	filename = strings.Replace(filename, " ", "_", -1)
 	filename = strings.TrimSuffix(filename, "_")
 
-	// Include the tag in the path if available
-	if request.Tag != "" {
-		return filepath.Join(snapshotDir, request.Tag, filename+".json")
+	// Keep a separate snapshot per language and data row
+	if options.Language != "" {
+		filename += "_lang_" + options.Language
+	}
+	if options.DataRow != nil {
+		filename += "_row_" + strings.NewReplacer("/", "_", " ", "_", ".", "_", "=", "_", ":", "").Replace(options.DataRow.ID)
+	}
+
+	// Include the first tag in the path if available
+	if tag := request.PrimaryTag(); tag != "" {
+		return filepath.Join(snapshotDir, filepath.FromSlash(tag), filename+".json")
 	}
 
 	return filepath.Join(snapshotDir, filename+".json")
 }
 
+// MatchesFilter checks if a request matches the filter criteria, for runners
+// built on this one
+func (s *TestRunnerService) MatchesFilter(request *models.HTTPRequest, filter models.TestFilter) bool {
+	return s.matchesFilter(request, filter)
+}
+
 // matchesFilter checks if a request matches the filter criteria
 func (s *TestRunnerService) matchesFilter(request *models.HTTPRequest, filter models.TestFilter) bool {
-	// Filter by tag
-	if len(filter.Tags) > 0 {
-		tagMatch := false
-		for _, tag := range filter.Tags {
-			if request.Tag == tag {
-				tagMatch = true
-				break
-			}
-		}
-		if !tagMatch {
-			return false
-		}
+	// Filter by tag expressions, such as "smoke and not slow"
+	if !models.MatchTagFilter(filter.Tags, request.TagList()) {
+		return false
 	}
 
 	// Filter by path
@@ -523,6 +1515,11 @@ func (s *TestRunnerService) matchesFilter(request *models.HTTPRequest, filter mo
 		}
 	}
 
+	// Filter by the scopes granted to a role, such as "read:pets"
+	if !models.MatchScopes(filter.Scopes, request.Scopes) {
+		return false
+	}
+
 	return true
 }
 
@@ -552,8 +1549,16 @@ func (s *TestRunnerService) parseHTTPFile(ctx context.Context, filePath string)
 	}, nil
 }
 
+// wholeFile is the request index of a work item that runs an entire file in order
+const wholeFile = -1
+
+// serialGroup is the request index of a work item that runs the requests of
+// a serial group in order
+const serialGroup = -2
+
 // workItem represents a unit of work for parallel processing
 type workItem struct {
 	file       *models.HTTPFile
 	requestIdx int
+	serial     []workItem // Requests of a serial group
 }
@@ -0,0 +1,198 @@
+package application
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingExecutor answers requests with the responses of respond, 200 OK
+// without it, and records the requests sent; like the HTTP executor, it fails
+// requests using variables without a value when they are strict
+type recordingExecutor struct {
+	mu       sync.Mutex
+	requests []*models.HTTPRequest
+	respond  func(request *models.HTTPRequest, variables map[string]string) (*models.HTTPResponse, error)
+}
+
+func (e *recordingExecutor) Execute(ctx context.Context, request *models.HTTPRequest, variables map[string]string) (*models.HTTPResponse, error) {
+	if err := models.CheckVariables(ctx, request, variables); err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	e.requests = append(e.requests, request)
+	e.mu.Unlock()
+	if e.respond != nil {
+		return e.respond(request, variables)
+	}
+	return &models.HTTPResponse{StatusCode: 200, Request: request}, nil
+}
+
+func (e *recordingExecutor) ExecuteFile(ctx context.Context, file *models.HTTPFile, variables map[string]string) ([]*models.HTTPResponse, error) {
+	return nil, nil
+}
+
+// sent returns the names of the requests sent, in order
+func (e *recordingExecutor) sent() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	names := make([]string, 0, len(e.requests))
+	for _, request := range e.requests {
+		names = append(names, request.Name)
+	}
+	return names
+}
+
+// noSnapshots is a snapshot manager without any snapshot
+type noSnapshots struct{}
+
+func (noSnapshots) SaveSnapshot(ctx context.Context, response *models.HTTPResponse, path string) error {
+	return nil
+}
+
+func (noSnapshots) LoadSnapshot(ctx context.Context, path string) (*models.HTTPResponse, error) {
+	return nil, os.ErrNotExist
+}
+
+func (noSnapshots) CompareWithSnapshot(ctx context.Context, response *models.HTTPResponse, snapshotPath string) (*models.SnapshotDiff, error) {
+	return nil, os.ErrNotExist
+}
+
+// resultStatuses returns the statuses of results by test name
+func resultStatuses(results []models.TestResult) map[string]models.TestStatus {
+	statuses := make(map[string]models.TestStatus, len(results))
+	for _, result := range results {
+		statuses[result.Name] = result.Status
+	}
+	return statuses
+}
+
+func TestRunTest_StrictVars(t *testing.T) {
+	tests := []struct {
+		name       string
+		strict     bool
+		variables  map[string]string
+		wantStatus models.TestStatus
+		wantError  string
+		wantSent   []string
+	}{
+		{name: "strict with a missing variable", strict: true, wantStatus: models.TestStatusError, wantError: "unresolved variables: id", wantSent: []string{}},
+		{name: "strict with all variables", strict: true, variables: map[string]string{"id": "1"}, wantStatus: models.TestStatusPassed, wantSent: []string{"getUser"}},
+		{name: "not strict", strict: false, wantStatus: models.TestStatusPassed, wantSent: []string{"getUser"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := &recordingExecutor{}
+			runner := NewTestRunnerService(executor, noSnapshots{}, nil)
+			request := &models.HTTPRequest{Name: "getUser", Method: "GET", URL: "https://api.example.com/users/{{id}}", ExpectStatus: "200"}
+
+			result, err := runner.RunTest(context.Background(), request, models.TestRunOptions{StrictVars: tt.strict, Variables: tt.variables})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, result.Status)
+			assert.Equal(t, tt.wantError, result.Error)
+			assert.Equal(t, tt.wantSent, executor.sent())
+		})
+	}
+}
+
+func TestRunDataRows(t *testing.T) {
+	files := []*models.HTTPFile{{
+		Filename: "users.http",
+		Requests: []models.HTTPRequest{{
+			Name:         "getUser",
+			Method:       "GET",
+			URL:          "https://api.example.com/users/{{email}}?role={{role}}",
+			ExpectStatus: "200",
+		}},
+	}}
+	executor := &recordingExecutor{respond: func(request *models.HTTPRequest, variables map[string]string) (*models.HTTPResponse, error) {
+		status := 200
+		if variables["role"] == "guest" {
+			status = 403
+		}
+		return &models.HTTPResponse{StatusCode: status, Request: request}, nil
+	}}
+	runner := NewTestRunnerService(executor, noSnapshots{}, nil)
+
+	// The second row, parsed from a ragged CSV line, has no role
+	options := models.TestRunOptions{
+		StrictVars: true,
+		DataRows: []models.DataRow{
+			{ID: "row 1", Values: map[string]string{"email": "a@example.com", "role": "admin"}},
+			{ID: "row 2", Values: map[string]string{"email": "b@example.com"}},
+			{ID: "row 3", Values: map[string]string{"email": "c@example.com", "role": "guest"}},
+		},
+	}
+	report := &models.TestReport{}
+	require.NoError(t, runner.runDataRows(context.Background(), files, options, report))
+
+	assert.Equal(t, map[string]models.TestStatus{
+		"getUser [row 1]": models.TestStatusPassed,
+		"getUser [row 2]": models.TestStatusError,
+		"getUser [row 3]": models.TestStatusFailed,
+	}, resultStatuses(report.Results))
+	assert.Equal(t, "unresolved variables: role", report.Results[1].Error)
+	assert.Equal(t, "row 3", report.Results[2].DataRow)
+
+	require.Len(t, report.DataRows, 3)
+	assert.Equal(t, 1, report.DataRows[0].Summary.PassedTests)
+	assert.Equal(t, 1, report.DataRows[1].Summary.ErrorTests)
+	assert.Equal(t, 1, report.DataRows[2].Summary.FailedTests)
+
+	// Rows after a failing one don't run when stopping on failure
+	options.StopOnFailure = true
+	report = &models.TestReport{}
+	require.NoError(t, runner.runDataRows(context.Background(), files, options, report))
+	assert.Len(t, report.DataRows, 2)
+}
+
+func TestRunMatrix(t *testing.T) {
+	file := func(filename string) *models.HTTPFile {
+		return &models.HTTPFile{
+			Filename: filename,
+			Requests: []models.HTTPRequest{{
+				Name:         "greeting",
+				Method:       "GET",
+				URL:          "https://api.example.com/" + filename,
+				Headers:      map[string]string{"accept-language": "en"},
+				ExpectStatus: "200",
+			}},
+		}
+	}
+	executor := &recordingExecutor{respond: func(request *models.HTTPRequest, variables map[string]string) (*models.HTTPResponse, error) {
+		status := 200
+		if request.Path == "guest.http" && request.Headers["Accept-Language"] == "pt-BR" {
+			status = 404
+		}
+		return &models.HTTPResponse{StatusCode: status, Request: request}, nil
+	}}
+	runner := NewTestRunnerService(executor, noSnapshots{}, nil)
+
+	report := &models.TestReport{}
+	options := models.TestRunOptions{Languages: []string{"en", "pt-BR"}}
+	require.NoError(t, runner.runMatrix(context.Background(), []*models.HTTPFile{file("admin.http"), file("guest.http")}, options, report))
+
+	// Each request is sent with the language of the run in place of its own
+	require.Len(t, executor.requests, 4)
+	for i, language := range []string{"en", "en", "pt-BR", "pt-BR"} {
+		assert.Equal(t, map[string]string{"Accept-Language": language}, executor.requests[i].Headers)
+	}
+
+	// Tests of the same name in different files have rows of their own
+	assert.Equal(t, []models.LanguageMatrixRow{
+		{Test: "greeting", File: "admin.http", Statuses: map[string]models.TestStatus{"en": models.TestStatusPassed, "pt-BR": models.TestStatusPassed}},
+		{Test: "greeting", File: "guest.http", Statuses: map[string]models.TestStatus{"en": models.TestStatusPassed, "pt-BR": models.TestStatusFailed}},
+	}, report.LanguageMatrix)
+
+	var names []string
+	for _, result := range report.Results {
+		names = append(names, result.FilePath+" "+result.Name)
+	}
+	assert.Equal(t, []string{"admin.http greeting [en]", "guest.http greeting [en]", "admin.http greeting [pt-BR]", "guest.http greeting [pt-BR]"}, names)
+}
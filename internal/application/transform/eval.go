@@ -0,0 +1,402 @@
+package transform
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// node is an expression that maps an input value to zero or more outputs
+type node interface {
+	eval(input interface{}) ([]interface{}, error)
+}
+
+// pathNode is an expression that can also produce the paths of its outputs,
+// which makes it usable as an argument to del
+type pathNode interface {
+	node
+	paths(input interface{}, prefix []interface{}) ([][]interface{}, error)
+}
+
+// identityNode returns its input (".")
+type identityNode struct{}
+
+func (n *identityNode) eval(input interface{}) ([]interface{}, error) {
+	return []interface{}{input}, nil
+}
+
+func (n *identityNode) paths(input interface{}, prefix []interface{}) ([][]interface{}, error) {
+	return [][]interface{}{prefix}, nil
+}
+
+// fieldNode accesses an object field (".name")
+type fieldNode struct {
+	name string
+}
+
+func (n *fieldNode) eval(input interface{}) ([]interface{}, error) {
+	switch v := input.(type) {
+	case nil:
+		return []interface{}{nil}, nil
+	case map[string]interface{}:
+		return []interface{}{v[n.name]}, nil
+	default:
+		return nil, fmt.Errorf("cannot index %s with %q", typeName(input), n.name)
+	}
+}
+
+func (n *fieldNode) paths(input interface{}, prefix []interface{}) ([][]interface{}, error) {
+	if _, err := n.eval(input); err != nil {
+		return nil, err
+	}
+	return [][]interface{}{appendPath(prefix, n.name)}, nil
+}
+
+// indexNode accesses an array element (".[n]"), counting from the end when negative
+type indexNode struct {
+	index int
+}
+
+func (n *indexNode) eval(input interface{}) ([]interface{}, error) {
+	switch v := input.(type) {
+	case nil:
+		return []interface{}{nil}, nil
+	case []interface{}:
+		i := n.resolve(len(v))
+		if i < 0 || i >= len(v) {
+			return []interface{}{nil}, nil
+		}
+		return []interface{}{v[i]}, nil
+	default:
+		return nil, fmt.Errorf("cannot index %s with number", typeName(input))
+	}
+}
+
+func (n *indexNode) paths(input interface{}, prefix []interface{}) ([][]interface{}, error) {
+	v, ok := input.([]interface{})
+	if !ok {
+		if input == nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("cannot index %s with number", typeName(input))
+	}
+	return [][]interface{}{appendPath(prefix, n.resolve(len(v)))}, nil
+}
+
+// resolve converts a negative index to a position from the start
+func (n *indexNode) resolve(length int) int {
+	if n.index < 0 {
+		return length + n.index
+	}
+	return n.index
+}
+
+// iterateNode outputs each element of an array or value of an object (".[]")
+type iterateNode struct{}
+
+func (n *iterateNode) eval(input interface{}) ([]interface{}, error) {
+	switch v := input.(type) {
+	case []interface{}:
+		return v, nil
+	case map[string]interface{}:
+		var values []interface{}
+		for _, key := range sortedKeys(v) {
+			values = append(values, v[key])
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("cannot iterate over %s", typeName(input))
+	}
+}
+
+func (n *iterateNode) paths(input interface{}, prefix []interface{}) ([][]interface{}, error) {
+	var result [][]interface{}
+	switch v := input.(type) {
+	case []interface{}:
+		for i := range v {
+			result = append(result, appendPath(prefix, i))
+		}
+	case map[string]interface{}:
+		for _, key := range sortedKeys(v) {
+			result = append(result, appendPath(prefix, key))
+		}
+	default:
+		return nil, fmt.Errorf("cannot iterate over %s", typeName(input))
+	}
+	return result, nil
+}
+
+// pipeNode feeds every output of the left expression into the right one ("a | b")
+type pipeNode struct {
+	left  node
+	right node
+}
+
+func (n *pipeNode) eval(input interface{}) ([]interface{}, error) {
+	lefts, err := n.left.eval(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var outputs []interface{}
+	for _, value := range lefts {
+		rights, err := n.right.eval(value)
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, rights...)
+	}
+	return outputs, nil
+}
+
+func (n *pipeNode) paths(input interface{}, prefix []interface{}) ([][]interface{}, error) {
+	left, lok := n.left.(pathNode)
+	right, rok := n.right.(pathNode)
+	if !lok || !rok {
+		return nil, fmt.Errorf("invalid path expression")
+	}
+
+	leftPaths, err := left.paths(input, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var result [][]interface{}
+	for _, path := range leftPaths {
+		value := getPath(input, path[len(prefix):])
+		rightPaths, err := right.paths(value, path)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, rightPaths...)
+	}
+	return result, nil
+}
+
+// commaNode concatenates the outputs of two expressions ("a, b")
+type commaNode struct {
+	left  node
+	right node
+}
+
+func (n *commaNode) eval(input interface{}) ([]interface{}, error) {
+	lefts, err := n.left.eval(input)
+	if err != nil {
+		return nil, err
+	}
+	rights, err := n.right.eval(input)
+	if err != nil {
+		return nil, err
+	}
+	return append(lefts, rights...), nil
+}
+
+func (n *commaNode) paths(input interface{}, prefix []interface{}) ([][]interface{}, error) {
+	left, lok := n.left.(pathNode)
+	right, rok := n.right.(pathNode)
+	if !lok || !rok {
+		return nil, fmt.Errorf("invalid path expression")
+	}
+
+	leftPaths, err := left.paths(input, prefix)
+	if err != nil {
+		return nil, err
+	}
+	rightPaths, err := right.paths(input, prefix)
+	if err != nil {
+		return nil, err
+	}
+	return append(leftPaths, rightPaths...), nil
+}
+
+// arrayNode collects the outputs of an expression into an array ("[a]")
+type arrayNode struct {
+	inner node
+}
+
+func (n *arrayNode) eval(input interface{}) ([]interface{}, error) {
+	if n.inner == nil {
+		return []interface{}{[]interface{}{}}, nil
+	}
+
+	values, err := n.inner.eval(input)
+	if err != nil {
+		return nil, err
+	}
+	if values == nil {
+		values = []interface{}{}
+	}
+	return []interface{}{values}, nil
+}
+
+// literalNode returns a constant value
+type literalNode struct {
+	value interface{}
+}
+
+func (n *literalNode) eval(input interface{}) ([]interface{}, error) {
+	return []interface{}{n.value}, nil
+}
+
+// compareNode compares the outputs of two expressions ("a == b")
+type compareNode struct {
+	op    string
+	left  node
+	right node
+}
+
+func (n *compareNode) eval(input interface{}) ([]interface{}, error) {
+	lefts, err := n.left.eval(input)
+	if err != nil {
+		return nil, err
+	}
+	rights, err := n.right.eval(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var outputs []interface{}
+	for _, r := range rights {
+		for _, l := range lefts {
+			c := compareValues(l, r)
+			var result bool
+			switch n.op {
+			case "==":
+				result = c == 0
+			case "!=":
+				result = c != 0
+			case "<":
+				result = c < 0
+			case "<=":
+				result = c <= 0
+			case ">":
+				result = c > 0
+			case ">=":
+				result = c >= 0
+			}
+			outputs = append(outputs, result)
+		}
+	}
+	return outputs, nil
+}
+
+// callNode calls a builtin function
+type callNode struct {
+	name string
+	args []node
+}
+
+func (n *callNode) eval(input interface{}) ([]interface{}, error) {
+	return functions[n.name].fn(input, n.args)
+}
+
+func (n *callNode) paths(input interface{}, prefix []interface{}) ([][]interface{}, error) {
+	// select filters paths the same way it filters values
+	if n.name != "select" {
+		return nil, fmt.Errorf("invalid path expression with %s", n.name)
+	}
+
+	keep, err := truthy(n.args[0], input)
+	if err != nil || !keep {
+		return nil, err
+	}
+	return [][]interface{}{prefix}, nil
+}
+
+// appendPath returns a copy of the path with an element appended
+func appendPath(path []interface{}, element interface{}) []interface{} {
+	result := make([]interface{}, len(path), len(path)+1)
+	copy(result, path)
+	return append(result, element)
+}
+
+// getPath returns the value at a path
+func getPath(value interface{}, path []interface{}) interface{} {
+	for _, element := range path {
+		switch key := element.(type) {
+		case string:
+			object, _ := value.(map[string]interface{})
+			value = object[key]
+		case int:
+			array, _ := value.([]interface{})
+			if key < 0 || key >= len(array) {
+				return nil
+			}
+			value = array[key]
+		}
+	}
+	return value
+}
+
+// deletePath returns a copy of the value with the element at the path removed
+func deletePath(value interface{}, path []interface{}) interface{} {
+	if len(path) == 0 {
+		return nil
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		key, ok := path[0].(string)
+		if !ok {
+			return value
+		}
+		if _, exists := v[key]; !exists {
+			return value
+		}
+		result := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			result[k] = item
+		}
+		if len(path) == 1 {
+			delete(result, key)
+		} else {
+			result[key] = deletePath(v[key], path[1:])
+		}
+		return result
+	case []interface{}:
+		index, ok := path[0].(int)
+		if !ok || index < 0 || index >= len(v) {
+			return value
+		}
+		result := make([]interface{}, 0, len(v))
+		if len(path) == 1 {
+			result = append(result, v[:index]...)
+			return append(result, v[index+1:]...)
+		}
+		result = append(result, v...)
+		result[index] = deletePath(v[index], path[1:])
+		return result
+	default:
+		return value
+	}
+}
+
+// sortedKeys returns the keys of an object in sorted order
+func sortedKeys(object map[string]interface{}) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// typeName returns the jq name of a value's type
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number, float64, int:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
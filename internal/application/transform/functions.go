@@ -0,0 +1,401 @@
+package transform
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+)
+
+// function is a builtin function with a fixed number of arguments
+type function struct {
+	arity int
+	fn    func(input interface{}, args []node) ([]interface{}, error)
+}
+
+// functions lists the supported builtins
+var functions map[string]function
+
+func init() {
+	functions = map[string]function{
+		"length":    {0, fnLength},
+		"keys":      {0, fnKeys},
+		"sort":      {0, fnSort},
+		"sort_by":   {1, fnSortBy},
+		"reverse":   {0, fnReverse},
+		"unique":    {0, fnUnique},
+		"unique_by": {1, fnUniqueBy},
+		"map":       {1, fnMap},
+		"select":    {1, fnSelect},
+		"del":       {1, fnDel},
+		"first":     {0, fnFirst},
+		"last":      {0, fnLast},
+		"not":       {0, fnNot},
+		"tostring":  {0, fnToString},
+	}
+}
+
+func fnLength(input interface{}, args []node) ([]interface{}, error) {
+	switch v := input.(type) {
+	case nil:
+		return []interface{}{0}, nil
+	case string:
+		return []interface{}{utf8.RuneCountInString(v)}, nil
+	case []interface{}:
+		return []interface{}{len(v)}, nil
+	case map[string]interface{}:
+		return []interface{}{len(v)}, nil
+	default:
+		return nil, fmt.Errorf("%s has no length", typeName(input))
+	}
+}
+
+func fnKeys(input interface{}, args []node) ([]interface{}, error) {
+	switch v := input.(type) {
+	case map[string]interface{}:
+		keys := []interface{}{}
+		for _, key := range sortedKeys(v) {
+			keys = append(keys, key)
+		}
+		return []interface{}{keys}, nil
+	case []interface{}:
+		keys := []interface{}{}
+		for i := range v {
+			keys = append(keys, i)
+		}
+		return []interface{}{keys}, nil
+	default:
+		return nil, fmt.Errorf("%s has no keys", typeName(input))
+	}
+}
+
+func fnSort(input interface{}, args []node) ([]interface{}, error) {
+	return sortByKey(input, nil)
+}
+
+func fnSortBy(input interface{}, args []node) ([]interface{}, error) {
+	return sortByKey(input, args[0])
+}
+
+func fnReverse(input interface{}, args []node) ([]interface{}, error) {
+	array, err := toArray(input, "reverse")
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]interface{}, len(array))
+	for i, value := range array {
+		result[len(array)-1-i] = value
+	}
+	return []interface{}{result}, nil
+}
+
+func fnUnique(input interface{}, args []node) ([]interface{}, error) {
+	return uniqueByKey(input, nil)
+}
+
+func fnUniqueBy(input interface{}, args []node) ([]interface{}, error) {
+	return uniqueByKey(input, args[0])
+}
+
+func fnMap(input interface{}, args []node) ([]interface{}, error) {
+	values, err := (&iterateNode{}).eval(input)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []interface{}{}
+	for _, value := range values {
+		outputs, err := args[0].eval(value)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, outputs...)
+	}
+	return []interface{}{result}, nil
+}
+
+func fnSelect(input interface{}, args []node) ([]interface{}, error) {
+	keep, err := truthy(args[0], input)
+	if err != nil || !keep {
+		return nil, err
+	}
+	return []interface{}{input}, nil
+}
+
+func fnDel(input interface{}, args []node) ([]interface{}, error) {
+	path, ok := args[0].(pathNode)
+	if !ok {
+		return nil, fmt.Errorf("invalid path expression in del")
+	}
+
+	paths, err := path.paths(input, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Delete from the last path so removing array elements doesn't shift the others
+	sort.SliceStable(paths, func(i, j int) bool {
+		return comparePaths(paths[i], paths[j]) > 0
+	})
+
+	result := input
+	for _, p := range paths {
+		result = deletePath(result, p)
+	}
+	return []interface{}{result}, nil
+}
+
+func fnFirst(input interface{}, args []node) ([]interface{}, error) {
+	return (&indexNode{index: 0}).eval(input)
+}
+
+func fnLast(input interface{}, args []node) ([]interface{}, error) {
+	return (&indexNode{index: -1}).eval(input)
+}
+
+func fnNot(input interface{}, args []node) ([]interface{}, error) {
+	return []interface{}{!isTruthy(input)}, nil
+}
+
+func fnToString(input interface{}, args []node) ([]interface{}, error) {
+	if s, ok := input.(string); ok {
+		return []interface{}{s}, nil
+	}
+
+	data, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{string(data)}, nil
+}
+
+// sortByKey sorts an array by the value of the key expression, or by the elements themselves
+func sortByKey(input interface{}, key node) ([]interface{}, error) {
+	array, err := toArray(input, "sort")
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := elementKeys(array, key)
+	if err != nil {
+		return nil, err
+	}
+
+	indexes := make([]int, len(array))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return compareValues(keys[indexes[i]], keys[indexes[j]]) < 0
+	})
+
+	result := make([]interface{}, len(array))
+	for i, index := range indexes {
+		result[i] = array[index]
+	}
+	return []interface{}{result}, nil
+}
+
+// uniqueByKey sorts an array and keeps the first element for each distinct key
+func uniqueByKey(input interface{}, key node) ([]interface{}, error) {
+	sorted, err := sortByKey(input, key)
+	if err != nil {
+		return nil, err
+	}
+
+	array := sorted[0].([]interface{})
+	keys, err := elementKeys(array, key)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []interface{}{}
+	for i, value := range array {
+		if i > 0 && compareValues(keys[i-1], keys[i]) == 0 {
+			continue
+		}
+		result = append(result, value)
+	}
+	return []interface{}{result}, nil
+}
+
+// elementKeys evaluates the key expression for every element of an array
+func elementKeys(array []interface{}, key node) ([]interface{}, error) {
+	keys := make([]interface{}, len(array))
+	for i, value := range array {
+		if key == nil {
+			keys[i] = value
+			continue
+		}
+
+		outputs, err := key.eval(value)
+		if err != nil {
+			return nil, err
+		}
+
+		// Multiple outputs compare as an array
+		if len(outputs) == 1 {
+			keys[i] = outputs[0]
+		} else {
+			keys[i] = outputs
+		}
+	}
+	return keys, nil
+}
+
+// toArray checks that the input is an array
+func toArray(input interface{}, name string) ([]interface{}, error) {
+	array, ok := input.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("cannot %s %s, an array is required", name, typeName(input))
+	}
+	return array, nil
+}
+
+// truthy evaluates a condition and reports whether any output is truthy
+func truthy(condition node, input interface{}) (bool, error) {
+	outputs, err := condition.eval(input)
+	if err != nil {
+		return false, err
+	}
+	for _, output := range outputs {
+		if isTruthy(output) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// isTruthy follows jq semantics where only false and null are falsy
+func isTruthy(value interface{}) bool {
+	if value == nil {
+		return false
+	}
+	if b, ok := value.(bool); ok {
+		return b
+	}
+	return true
+}
+
+// compareValues orders values like jq: null < false < true < numbers < strings < arrays < objects
+func compareValues(a, b interface{}) int {
+	ra, rb := typeRank(a), typeRank(b)
+	if ra != rb {
+		return ra - rb
+	}
+
+	switch va := a.(type) {
+	case bool:
+		vb := b.(bool)
+		if va == vb {
+			return 0
+		}
+		if !va {
+			return -1
+		}
+		return 1
+	case string:
+		vb := b.(string)
+		if va < vb {
+			return -1
+		}
+		if va > vb {
+			return 1
+		}
+		return 0
+	case []interface{}:
+		vb := b.([]interface{})
+		for i := 0; i < len(va) && i < len(vb); i++ {
+			if c := compareValues(va[i], vb[i]); c != 0 {
+				return c
+			}
+		}
+		return len(va) - len(vb)
+	case map[string]interface{}:
+		vb := b.(map[string]interface{})
+
+		// Compare the key sets first, then the values key by key
+		ka, kb := keyArray(va), keyArray(vb)
+		if c := compareValues(ka, kb); c != 0 {
+			return c
+		}
+		for _, key := range sortedKeys(va) {
+			if c := compareValues(va[key], vb[key]); c != 0 {
+				return c
+			}
+		}
+		return 0
+	}
+
+	if ra == 3 {
+		fa, fb := toFloat(a), toFloat(b)
+		if fa < fb {
+			return -1
+		}
+		if fa > fb {
+			return 1
+		}
+	}
+	return 0
+}
+
+// comparePaths orders paths element by element
+func comparePaths(a, b []interface{}) int {
+	return compareValues(pathArray(a), pathArray(b))
+}
+
+// pathArray converts a path to comparable values
+func pathArray(path []interface{}) []interface{} {
+	result := make([]interface{}, len(path))
+	for i, element := range path {
+		result[i] = element
+	}
+	return result
+}
+
+// keyArray returns the sorted keys of an object as an array
+func keyArray(object map[string]interface{}) []interface{} {
+	var keys []interface{}
+	for _, key := range sortedKeys(object) {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// typeRank returns the position of a value's type in the jq ordering
+func typeRank(value interface{}) int {
+	switch v := value.(type) {
+	case nil:
+		return 0
+	case bool:
+		if v {
+			return 2
+		}
+		return 1
+	case json.Number, float64, int:
+		return 3
+	case string:
+		return 4
+	case []interface{}:
+		return 5
+	default:
+		return 6
+	}
+}
+
+// toFloat converts a number to float64
+func toFloat(value interface{}) float64 {
+	switch v := value.(type) {
+	case json.Number:
+		f, _ := strconv.ParseFloat(string(v), 64)
+		return f
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,251 @@
+package transform
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// parser builds the syntax tree of an expression. The grammar is
+//
+//	pipe    = comma { "|" comma }
+//	comma   = compare { "," compare }
+//	compare = term [ op term ]
+//	term    = primary { "." ident | "[" [ index ] "]" }
+//	primary = "." [ ident ] | "[" [ pipe ] "]" | "(" pipe ")" | literal | ident [ "(" pipe { ";" pipe } ")" ]
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// parse parses a complete expression
+func parse(expr string) (node, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	n, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+	}
+
+	return n, nil
+}
+
+// peek returns the current token
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+// next consumes the current token
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// expect consumes a token of the given kind or fails
+func (p *parser) expect(kind tokenKind, text string) error {
+	tok := p.next()
+	if tok.kind != kind {
+		return fmt.Errorf("expected %q at position %d", text, tok.pos)
+	}
+	return nil
+}
+
+// parsePipe parses expressions separated by "|"
+func (p *parser) parsePipe() (node, error) {
+	left, err := p.parseComma()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokenPipe {
+		p.next()
+		right, err := p.parseComma()
+		if err != nil {
+			return nil, err
+		}
+		left = &pipeNode{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+// parseComma parses expressions separated by ","
+func (p *parser) parseComma() (node, error) {
+	left, err := p.parseCompare()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokenComma {
+		p.next()
+		right, err := p.parseCompare()
+		if err != nil {
+			return nil, err
+		}
+		left = &commaNode{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+// parseCompare parses an optional comparison between two terms
+func (p *parser) parseCompare() (node, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.kind == tokenOp {
+		p.next()
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		return &compareNode{op: tok.text, left: left, right: right}, nil
+	}
+
+	return left, nil
+}
+
+// parseTerm parses a primary expression followed by field and index accesses
+func (p *parser) parseTerm() (node, error) {
+	n, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		switch p.peek().kind {
+		case tokenDot:
+			p.next()
+			if p.peek().kind == tokenLBracket {
+				continue
+			}
+			tok := p.next()
+			if tok.kind != tokenIdent && tok.kind != tokenString {
+				return nil, fmt.Errorf("expected field name at position %d", tok.pos)
+			}
+			n = &pipeNode{left: n, right: &fieldNode{name: tok.text}}
+		case tokenLBracket:
+			suffix, err := p.parseBracket()
+			if err != nil {
+				return nil, err
+			}
+			n = &pipeNode{left: n, right: suffix}
+		default:
+			return n, nil
+		}
+	}
+}
+
+// parseBracket parses "[]", "[n]" or "[\"key\"]" after a term
+func (p *parser) parseBracket() (node, error) {
+	p.next()
+
+	tok := p.next()
+	switch tok.kind {
+	case tokenRBracket:
+		return &iterateNode{}, nil
+	case tokenNumber:
+		var index int
+		if _, err := fmt.Sscanf(tok.text, "%d", &index); err != nil {
+			return nil, fmt.Errorf("invalid index %q at position %d", tok.text, tok.pos)
+		}
+		return &indexNode{index: index}, p.expect(tokenRBracket, "]")
+	case tokenString:
+		return &fieldNode{name: tok.text}, p.expect(tokenRBracket, "]")
+	default:
+		return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+	}
+}
+
+// parsePrimary parses the start of a term
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+
+	switch tok.kind {
+	case tokenDot:
+		// ".foo" accesses a field, "." alone is the identity
+		if next := p.peek(); next.kind == tokenIdent || next.kind == tokenString {
+			p.next()
+			return &fieldNode{name: next.text}, nil
+		}
+		return &identityNode{}, nil
+	case tokenLBracket:
+		// Array construction
+		if p.peek().kind == tokenRBracket {
+			p.next()
+			return &arrayNode{}, nil
+		}
+		inner, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		return &arrayNode{inner: inner}, p.expect(tokenRBracket, "]")
+	case tokenLParen:
+		inner, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		return inner, p.expect(tokenRParen, ")")
+	case tokenString:
+		return &literalNode{value: tok.text}, nil
+	case tokenNumber:
+		return &literalNode{value: json.Number(tok.text)}, nil
+	case tokenIdent:
+		switch tok.text {
+		case "true":
+			return &literalNode{value: true}, nil
+		case "false":
+			return &literalNode{value: false}, nil
+		case "null":
+			return &literalNode{value: nil}, nil
+		}
+		return p.parseCall(tok)
+	default:
+		return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+	}
+}
+
+// parseCall parses a function call with optional arguments
+func (p *parser) parseCall(name token) (node, error) {
+	call := &callNode{name: name.text}
+
+	if p.peek().kind == tokenLParen {
+		p.next()
+		for {
+			arg, err := p.parsePipe()
+			if err != nil {
+				return nil, err
+			}
+			call.args = append(call.args, arg)
+
+			if p.peek().kind != tokenSemicolon {
+				break
+			}
+			p.next()
+		}
+		if err := p.expect(tokenRParen, ")"); err != nil {
+			return nil, err
+		}
+	}
+
+	fn, ok := functions[call.name]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %q at position %d", call.name, name.pos)
+	}
+	if len(call.args) != fn.arity {
+		return nil, fmt.Errorf("function %s expects %d argument(s), got %d", call.name, fn.arity, len(call.args))
+	}
+
+	return call, nil
+}
@@ -0,0 +1,70 @@
+// Package transform implements a small jq-like language for normalizing JSON
+// response bodies before snapshot comparison and assertions.
+//
+// Supported syntax: ".", ".field", ".[n]", ".[]", "|", ",", "[...]", comparisons
+// (==, !=, <, <=, >, >=), literals and the builtins length, keys, sort, sort_by,
+// reverse, unique, unique_by, map, select, del, first, last, not and tostring.
+package transform
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Query is a compiled transform expression
+type Query struct {
+	expr string
+	root node
+}
+
+// Compile parses a transform expression
+func Compile(expr string) (*Query, error) {
+	root, err := parse(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transform %q: %w", expr, err)
+	}
+	return &Query{expr: expr, root: root}, nil
+}
+
+// Run applies the query to a decoded JSON value and returns all outputs
+func (q *Query) Run(input interface{}) ([]interface{}, error) {
+	outputs, err := q.root.eval(input)
+	if err != nil {
+		return nil, fmt.Errorf("transform %q failed: %w", q.expr, err)
+	}
+	return outputs, nil
+}
+
+// Apply runs the query on a JSON document and returns the transformed JSON.
+// A query producing several outputs returns them as an array.
+func (q *Query) Apply(data []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var input interface{}
+	if err := decoder.Decode(&input); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON body: %w", err)
+	}
+
+	outputs, err := q.Run(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var result interface{} = outputs
+	if len(outputs) == 1 {
+		result = outputs[0]
+	}
+
+	return json.MarshalIndent(result, "", "  ")
+}
+
+// Apply compiles an expression and applies it to a JSON document
+func Apply(expr string, data []byte) ([]byte, error) {
+	q, err := Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	return q.Apply(data)
+}
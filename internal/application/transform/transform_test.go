@@ -0,0 +1,70 @@
+package transform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApply(t *testing.T) {
+	body := `{
+		"data": [
+			{"id": 3, "name": "c", "updatedAt": "2024-01-03", "tags": ["x"]},
+			{"id": 1, "name": "a", "updatedAt": "2024-01-01", "tags": []},
+			{"id": 2, "name": "b", "updatedAt": "2024-01-02", "tags": ["y", "z"]}
+		],
+		"meta": {"requestId": "abc", "total": 3}
+	}`
+
+	tests := []struct {
+		name     string
+		expr     string
+		expected string
+	}{
+		{"identity field", ".meta.total", `3`},
+		{"index", ".data[0].name", `"c"`},
+		{"negative index", ".data[-1].id", `2`},
+		{"sort_by", ".data | sort_by(.id) | map(.id)", `[1, 2, 3]`},
+		{"iterate into array", "[.data[].name] | sort | reverse", `["c", "b", "a"]`},
+		{"select", ".data | map(select(.id >= 2)) | length", `2`},
+		{"del field", "del(.meta.requestId) | .meta", `{"total": 3}`},
+		{"del in every element", ".data | del(.[].updatedAt) | sort_by(.id) | first", `{"id": 1, "name": "a", "tags": []}`},
+		{"keys", ".meta | keys", `["requestId", "total"]`},
+		{"unsupported object construction", "[.data[] | {}]", ""},
+		{"multiple outputs", ".meta.total, .data[1].id", `[3, 1]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Apply(tt.expr, []byte(body))
+			if tt.expected == "" {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(result))
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, expr := range []string{"", ".foo |", "sort_by", "unknown(.a)", ".[", `"open`} {
+		_, err := Compile(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestUniqueAndDelete(t *testing.T) {
+	result, err := Apply(`unique`, []byte(`[3, 1, 3, "a", null, 1]`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `[null, 1, 3, "a"]`, string(result))
+
+	result, err = Apply(`del(.[0], .[2])`, []byte(`[1, 2, 3, 4]`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `[2, 4]`, string(result))
+
+	result, err = Apply(`unique_by(.k) | map(.v)`, []byte(`[{"k": 2, "v": "b"}, {"k": 1, "v": "a"}, {"k": 2, "v": "c"}]`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `["a", "b"]`, string(result))
+}
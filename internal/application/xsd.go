@@ -0,0 +1,45 @@
+package application
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/application/soap"
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// xsdAssertion validates an XML response against the XSD or WSDL file of its
+// request, which is relative to the request's .http file
+func xsdAssertion(request *models.HTTPRequest, response *models.HTTPResponse) models.TestAssertionResult {
+	assertion := models.TestAssertionResult{
+		Type:        "xsd",
+		Source:      "body",
+		Expected:    request.XSD,
+		Description: fmt.Sprintf("body is valid against %s", request.XSD),
+	}
+
+	path := request.XSD
+	if !filepath.IsAbs(path) && request.Path != "" {
+		path = filepath.Join(filepath.Dir(request.Path), path)
+	}
+
+	schema, err := soap.LoadSchema(path)
+	if err != nil {
+		assertion.Error = err.Error()
+		return assertion
+	}
+
+	problems, err := schema.Validate([]byte(response.Body))
+	switch {
+	case err != nil:
+		assertion.Error = err.Error()
+	case len(problems) > 0:
+		assertion.Actual = problems
+		assertion.Error = strings.Join(problems, "; ")
+	default:
+		assertion.Actual = "valid"
+		assertion.Passed = true
+	}
+	return assertion
+}
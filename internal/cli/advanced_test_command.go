@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -28,50 +29,24 @@ func AddAdvancedTestCommands(
 		Long:  `Execute HTTP requests and validate responses against OpenAPI/Swagger schema definitions`,
 		Args:  cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// Get flags from parent test command
-			updateMode, _ := cmd.Flags().GetString("update")
-			ignoreHeaders, _ := cmd.Flags().GetString("ignore-headers")
-			snapshotDir, _ := cmd.Flags().GetString("snapshot-dir")
-			failOnMissing, _ := cmd.Flags().GetBool("fail-on-missing")
-			cleanup, _ := cmd.Flags().GetBool("cleanup")
-			timeoutStr, _ := cmd.Flags().GetString("timeout")
-			parallel, _ := cmd.Flags().GetBool("parallel")
-			maxConcurrent, _ := cmd.Flags().GetInt("max-concurrent")
-			stopOnFailure, _ := cmd.Flags().GetBool("stop-on-failure")
+			// Get flags from parent test command, the others are read by
+			// createTestRunOptions
 			tags, _ := cmd.Flags().GetStringSlice("tags")
-			methods, _ := cmd.Flags().GetStringSlice("methods")
-			paths, _ := cmd.Flags().GetStringSlice("paths")
-			names, _ := cmd.Flags().GetStringSlice("names")
-			reportFormat, _ := cmd.Flags().GetString("report-format")
 			reportOutput, _ := cmd.Flags().GetString("report-output")
-			detailed, _ := cmd.Flags().GetBool("detailed")
+
+			// Check the tag filter expressions before running anything
+			if err := checkTagFilters(tags); err != nil {
+				return err
+			}
 
 			// Get schema validation specific flags
 			swaggerFile, _ := cmd.Flags().GetString("swagger-file")
-			ignoreProps, _ := cmd.Flags().GetString("ignore-props")
-			ignoreAddProps, _ := cmd.Flags().GetBool("ignore-add-props")
-			ignoreFormats, _ := cmd.Flags().GetBool("ignore-formats")
-			ignorePatterns, _ := cmd.Flags().GetBool("ignore-patterns")
-			reqPropsOnly, _ := cmd.Flags().GetBool("req-props-only")
-			ignoreNullable, _ := cmd.Flags().GetBool("ignore-nullable")
-
-			// Parse ignore properties
-			var ignoredProps []string
-			if ignoreProps != "" {
-				ignoredProps = strings.Split(ignoreProps, ",")
-				for i := range ignoredProps {
-					ignoredProps[i] = strings.TrimSpace(ignoredProps[i])
-				}
-			}
+			failOn, _ := cmd.Flags().GetString("fail-on")
 
-			// Create validation options
-			validationOptions := models.ValidationOptions{
-				IgnoreAdditionalProperties: ignoreAddProps,
-				IgnoreFormats:              ignoreFormats,
-				IgnorePatterns:             ignorePatterns,
-				RequiredPropertiesOnly:     reqPropsOnly,
-				IgnoreNullable:             ignoreNullable,
-				IgnoredProperties:          ignoredProps,
+			// Parse the failure classes that make the run fail
+			policy, err := parseFailOn(failOn)
+			if err != nil {
+				return err
 			}
 
 			// Create common test options using the same code from test_command.go
@@ -82,18 +57,18 @@ func AddAdvancedTestCommands(
 
 			// Add schema validation options
 			options.ValidateSchema = true
-			options.ValidationOptions = validationOptions
+			options.ValidationOptions = validationOptionsFromFlags(cmd)
 
 			// Load the Swagger file
 			if swaggerFile == "" {
-				return fmt.Errorf("swagger file is required for schema validation")
+				return newExitError(ExitConfigError, fmt.Errorf("swagger file is required for schema validation"))
 			}
 
 			// Parse the Swagger file
 			fmt.Printf("Loading Swagger file: %s\n", swaggerFile)
 			swaggerDoc, err := loadSwaggerDoc(context.Background(), swaggerFile)
 			if err != nil {
-				return fmt.Errorf("failed to load Swagger file: %w", err)
+				return newExitError(ExitSpecError, fmt.Errorf("failed to load Swagger file: %w", err))
 			}
 
 			// Run tests with schema validation
@@ -124,17 +99,8 @@ func AddAdvancedTestCommands(
 				fmt.Printf("Report saved to %s\n", reportOutput)
 			}
 
-			// Return non-zero exit code if any tests failed
-			if report.Summary.FailedTests > 0 || report.Summary.ErrorTests > 0 {
-				return fmt.Errorf(
-					"tests failed: %d failed, %d errors, %d schema validation failures",
-					report.Summary.FailedTests,
-					report.Summary.ErrorTests,
-					report.Summary.SchemaFailed,
-				)
-			}
-
-			return nil
+			// Return a non-zero exit code if any of the --fail-on classes were found
+			return policy.check(report)
 		},
 	}
 
@@ -173,7 +139,7 @@ func AddAdvancedTestCommands(
 			if validateSchema && swaggerFile != "" {
 				swaggerDoc, err := loadSwaggerDoc(context.Background(), swaggerFile)
 				if err != nil {
-					return fmt.Errorf("failed to load Swagger file: %w", err)
+					return newExitError(ExitSpecError, fmt.Errorf("failed to load Swagger file: %w", err))
 				}
 				options.SwaggerDoc = swaggerDoc
 			}
@@ -205,9 +171,9 @@ func AddAdvancedTestCommands(
 
 			// Return non-zero exit code if any sequences failed
 			if report.Summary.SequencesFailed > 0 {
-				return fmt.Errorf("sequences failed: %d of %d", 
-					report.Summary.SequencesFailed, 
-					report.Summary.SequencesTotal)
+				return newExitError(ExitTestFailures, fmt.Errorf("sequences failed: %d of %d",
+					report.Summary.SequencesFailed,
+					report.Summary.SequencesTotal))
 			}
 
 			return nil
@@ -216,12 +182,9 @@ func AddAdvancedTestCommands(
 
 	// Add flags to validate command
 	validateCmd.Flags().String("swagger-file", "", "Path to Swagger/OpenAPI file")
-	validateCmd.Flags().String("ignore-props", "", "Comma-separated properties to ignore in validation")
-	validateCmd.Flags().Bool("ignore-add-props", false, "Ignore additional properties not in schema")
-	validateCmd.Flags().Bool("ignore-formats", false, "Ignore format validation (e.g., date, email)")
-	validateCmd.Flags().Bool("ignore-patterns", false, "Ignore pattern validation")
-	validateCmd.Flags().Bool("req-props-only", false, "Validate only required properties")
-	validateCmd.Flags().Bool("ignore-nullable", false, "Ignore nullable field validation")
+	addValidationFlags(validateCmd)
+	validateCmd.Flags().String("run-timeout", "", "Overall time budget for the run, e.g. 10m; unstarted tests are skipped")
+	validateCmd.Flags().String("fail-on", DefaultFailOn+","+FailOnSchema, "Failure classes that cause a non-zero exit: failed, error, schema, missing-snapshot, deprecated")
 	validateCmd.MarkFlagRequired("swagger-file")
 
 	// Add flags to sequence command
@@ -231,10 +194,12 @@ func AddAdvancedTestCommands(
 	sequenceCmd.Flags().Bool("fail-fast", false, "Stop sequence on first failure")
 	sequenceCmd.Flags().Bool("validate-schema", false, "Validate responses against schema")
 	sequenceCmd.Flags().String("swagger-file", "", "Path to Swagger/OpenAPI file")
+	sequenceCmd.Flags().String("run-timeout", "", "Overall time budget for the run, e.g. 10m; unstarted steps are skipped")
+
+	validateCmd.AddCommand(setupValidateSnapshotsCmd())
 
 	// Add commands to test command
-	testCmd, _ := rootCmd.Commands()
-	for _, cmd := range testCmd {
+	for _, cmd := range rootCmd.Commands() {
 		if cmd.Use == "test [file-patterns]" {
 			cmd.AddCommand(validateCmd)
 			cmd.AddCommand(sequenceCmd)
@@ -243,12 +208,59 @@ func AddAdvancedTestCommands(
 	}
 }
 
+// addValidationFlags adds the flags configuring schema validation to a command
+func addValidationFlags(cmd *cobra.Command) {
+	cmd.Flags().String("ignore-props", "", "Comma-separated properties to ignore in validation")
+	cmd.Flags().Bool("ignore-add-props", false, "Ignore additional properties not in schema")
+	cmd.Flags().Bool("ignore-formats", false, "Ignore format validation (e.g., date, email)")
+	cmd.Flags().Bool("ignore-patterns", false, "Ignore pattern validation")
+	cmd.Flags().Bool("req-props-only", false, "Validate only required properties")
+	cmd.Flags().Bool("ignore-nullable", false, "Ignore nullable field validation")
+	cmd.Flags().Bool("require-write-only", false, "Report writeOnly properties missing from responses")
+	cmd.Flags().Bool("format-warnings", false, "Report strings invalid for their format as warnings instead of errors")
+	cmd.Flags().Bool("warnings-as-errors", false, "Fail responses on schema warnings too")
+}
+
+// validationOptionsFromFlags reads the schema validation options of a command
+func validationOptionsFromFlags(cmd *cobra.Command) models.ValidationOptions {
+	ignoreProps, _ := cmd.Flags().GetString("ignore-props")
+	ignoreAddProps, _ := cmd.Flags().GetBool("ignore-add-props")
+	ignoreFormats, _ := cmd.Flags().GetBool("ignore-formats")
+	ignorePatterns, _ := cmd.Flags().GetBool("ignore-patterns")
+	reqPropsOnly, _ := cmd.Flags().GetBool("req-props-only")
+	ignoreNullable, _ := cmd.Flags().GetBool("ignore-nullable")
+	requireWriteOnly, _ := cmd.Flags().GetBool("require-write-only")
+	formatWarnings, _ := cmd.Flags().GetBool("format-warnings")
+	warningsAsErrors, _ := cmd.Flags().GetBool("warnings-as-errors")
+
+	// Parse ignore properties
+	var ignoredProps []string
+	if ignoreProps != "" {
+		ignoredProps = strings.Split(ignoreProps, ",")
+		for i := range ignoredProps {
+			ignoredProps[i] = strings.TrimSpace(ignoredProps[i])
+		}
+	}
+
+	return models.ValidationOptions{
+		IgnoreAdditionalProperties: ignoreAddProps,
+		IgnoreFormats:              ignoreFormats,
+		IgnorePatterns:             ignorePatterns,
+		RequiredPropertiesOnly:     reqPropsOnly,
+		IgnoreNullable:             ignoreNullable,
+		RequireWriteOnly:           requireWriteOnly,
+		FormatWarnings:             formatWarnings,
+		WarningsAsErrors:           warningsAsErrors,
+		IgnoredProperties:          ignoredProps,
+	}
+}
+
 // Helper function to load a Swagger document
 func loadSwaggerDoc(ctx context.Context, filePath string) (*models.SwaggerDoc, error) {
 	// We'll need to implement or use a Swagger parser here
 	// For now, return a placeholder
 	return &models.SwaggerDoc{
-		Paths: make(map[string]*models.PathItem),
+		Paths: make(map[string]models.PathItem),
 	}, nil
 }
 
@@ -273,13 +285,10 @@ func createTestRunOptions(cmd *cobra.Command) (models.TestRunOptions, error) {
 	watch, _ := cmd.Flags().GetBool("watch")
 	watchInterval, _ := cmd.Flags().GetInt("watch-interval")
 
-	// Parse ignore headers
-	ignoreHeadersList := []string{"Date", "Set-Cookie"}
-	if ignoreHeaders != "" {
-		ignoreHeadersList = strings.Split(ignoreHeaders, ",")
-		for i := range ignoreHeadersList {
-			ignoreHeadersList[i] = strings.TrimSpace(ignoreHeadersList[i])
-		}
+	// Parse the header patterns ignored on top of the configured ones
+	ignoreHeadersList, err := models.ParseHeaderPatterns(ignoreHeaders)
+	if err != nil {
+		return models.TestRunOptions{}, newExitError(ExitConfigError, fmt.Errorf("invalid --ignore-headers: %w", err))
 	}
 
 	// Create test filter
@@ -297,9 +306,20 @@ func createTestRunOptions(cmd *cobra.Command) (models.TestRunOptions, error) {
 		}
 	}
 
+	// Parse the timeout of each request
+	timeout := 30 * time.Second
+	if timeoutStr != "" {
+		parsedTimeout, err := time.ParseDuration(timeoutStr)
+		if err != nil {
+			return models.TestRunOptions{}, newExitError(ExitConfigError, fmt.Errorf("invalid timeout format: %w", err))
+		}
+		timeout = parsedTimeout
+	}
+
 	// Create test run options
 	options := models.TestRunOptions{
 		UpdateSnapshots: updateMode,
+		Timeout:         timeout,
 		FailOnMissing:   failOnMissing,
 		IgnoreHeaders:   ignoreHeadersList,
 		Parallel:        parallel,
@@ -321,5 +341,22 @@ func createTestRunOptions(cmd *cobra.Command) (models.TestRunOptions, error) {
 		},
 	}
 
+	// Set the variables given on the command line, failing requests that
+	// still use variables without a value unless --strict-vars=false
+	options.Variables, err = commandLineVariables(cmd)
+	if err != nil {
+		return options, err
+	}
+	options.StrictVars, _ = cmd.Flags().GetBool("strict-vars")
+
+	// Parse the overall time budget of the run
+	if runTimeoutStr, _ := cmd.Flags().GetString("run-timeout"); runTimeoutStr != "" {
+		runTimeout, err := time.ParseDuration(runTimeoutStr)
+		if err != nil {
+			return options, newExitError(ExitConfigError, fmt.Errorf("invalid run timeout format: %w", err))
+		}
+		options.RunTimeout = runTimeout
+	}
+
 	return options, nil
 }
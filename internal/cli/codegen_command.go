@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/application/codegen"
+	"github.com/edgardnogueira/swagger-to-http/internal/application/parser"
+	"github.com/spf13/cobra"
+)
+
+// setupCodegenCmd sets up the codegen command and its subcommands
+func setupCodegenCmd() *cobra.Command {
+	codegenCmd := &cobra.Command{
+		Use:   "codegen",
+		Short: "Generate code from Swagger/OpenAPI documents",
+	}
+
+	codegenCmd.AddCommand(setupCodegenModelsCmd())
+
+	return codegenCmd
+}
+
+// setupCodegenModelsCmd creates the command generating Go types from the spec schemas
+func setupCodegenModelsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "models [swagger-file]",
+		Short: "Generate Go structs from the schemas of a spec",
+		Long: `Generate Go types for the component schemas (or Swagger 2 definitions) of a
+Swagger/OpenAPI document, with json tags matching the property names, so assertion
+helpers and programs embedding the tool can decode responses into typed models
+described by the same spec the tests are generated from.
+
+Object schemas become structs; optional properties are tagged omitempty and optional
+objects are pointers. Inline objects become types named after their parent and
+property, allOf references are embedded and oneOf/anyOf decode as interface{}.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pkg, _ := cmd.Flags().GetString("package")
+			output, _ := cmd.Flags().GetString("output")
+
+			doc, err := parser.NewSwaggerParser().ParseFile(context.Background(), args[0])
+			if err != nil {
+				return newExitError(ExitSpecError, fmt.Errorf("failed to parse spec: %w", err))
+			}
+
+			source, err := codegen.GenerateModels(doc, codegen.ModelsOptions{Package: pkg})
+			if err != nil {
+				return newExitError(ExitSpecError, err)
+			}
+
+			if output == "" {
+				_, err = os.Stdout.Write(source)
+				return err
+			}
+			if err := os.WriteFile(output, source, 0644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", output, err)
+			}
+			fmt.Printf("Generated models into %s\n", output)
+			return nil
+		},
+	}
+
+	cmd.Flags().String("package", codegen.DefaultPackage, "Package name of the generated code")
+	cmd.Flags().StringP("output", "o", "", "File to write the code to (default: standard output)")
+
+	return cmd
+}
@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/application"
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/spf13/cobra"
+)
+
+// setupCompareEnvsCmd creates the command comparing the responses of two environments
+func setupCompareEnvsCmd(configProvider application.ConfigProvider, testRunner application.TestRunner, testReporter application.TestReporter) *cobra.Command {
+	compareCmd := &cobra.Command{
+		Use:   "compare-envs [file-patterns]",
+		Short: "Compare the responses of two environments",
+		Long: `Execute the same HTTP requests against two environments and report the behavioral
+differences between their responses, instead of comparing against stored snapshots.
+
+Environments are names from the "environments" section of the configuration file
+or base URLs. The same normalization rules as snapshot comparison apply.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			envAName, _ := cmd.Flags().GetString("env-a")
+			envBName, _ := cmd.Flags().GetString("env-b")
+			ignoreHeaders, _ := cmd.Flags().GetString("ignore-headers")
+			timeoutStr, _ := cmd.Flags().GetString("timeout")
+			stopOnFailure, _ := cmd.Flags().GetBool("stop-on-failure")
+			tags, _ := cmd.Flags().GetStringSlice("tags")
+			methods, _ := cmd.Flags().GetStringSlice("methods")
+			names, _ := cmd.Flags().GetStringSlice("names")
+			reportFormat, _ := cmd.Flags().GetString("report-format")
+			reportOutput, _ := cmd.Flags().GetString("report-output")
+			detailed, _ := cmd.Flags().GetBool("detailed")
+			inlineMaxBytes, _ := cmd.Flags().GetInt("inline-max-bytes")
+			ignoreArrayOrder, _ := cmd.Flags().GetBool("ignore-array-order")
+			arrayOrderKey, _ := cmd.Flags().GetString("array-order-key")
+			varsPassphrase, _ := cmd.Flags().GetString("vars-passphrase")
+			varsKeyFile, _ := cmd.Flags().GetString("vars-key-file")
+			strictVars, _ := cmd.Flags().GetBool("strict-vars")
+
+			// Check the tag filter expressions before running anything
+			if err := checkTagFilters(tags); err != nil {
+				return err
+			}
+
+			comparer, ok := testRunner.(application.EnvironmentComparer)
+			if !ok {
+				return fmt.Errorf("the test runner does not support environment comparison")
+			}
+
+			envA, err := resolveEnvironment(configProvider, envAName)
+			if err != nil {
+				return newExitError(ExitConfigError, err)
+			}
+			envB, err := resolveEnvironment(configProvider, envBName)
+			if err != nil {
+				return newExitError(ExitConfigError, err)
+			}
+
+			timeout, err := time.ParseDuration(timeoutStr)
+			if err != nil {
+				return newExitError(ExitConfigError, fmt.Errorf("invalid timeout format: %w", err))
+			}
+
+			ignoreHeadersList, err := models.ParseHeaderPatterns(ignoreHeaders)
+			if err != nil {
+				return newExitError(ExitConfigError, fmt.Errorf("invalid --ignore-headers: %w", err))
+			}
+			headerRules, err := models.ParseHeaderIgnoreRules(
+				configProvider.GetStringSlice("snapshots.ignore_headers"),
+				configProvider.GetStringMap("snapshots.ignore_headers_by_tag"))
+			if err != nil {
+				return newExitError(ExitConfigError, fmt.Errorf("invalid snapshots.ignore_headers: %w", err))
+			}
+
+			options := models.TestRunOptions{
+				IgnoreHeaders:    ignoreHeadersList,
+				HeaderRules:      &headerRules,
+				IgnoreArrayOrder: ignoreArrayOrder || arrayOrderKey != "",
+				ArrayOrderKey:    arrayOrderKey,
+				Timeout:          timeout,
+				StopOnFailure:    stopOnFailure,
+				Filter: models.TestFilter{
+					Tags:    tags,
+					Methods: methods,
+					Names:   names,
+				},
+				EnvironmentVars: extractEnvironmentVars(),
+				ReportOptions: models.TestReportOptions{
+					Format:           reportFormat,
+					OutputPath:       reportOutput,
+					IncludeRequests:  detailed,
+					IncludeResponses: detailed,
+					ColorOutput:      true,
+					Detailed:         detailed,
+					InlineMaxBytes:   inlineMaxBytes,
+				},
+				VarsPassphrase: varsPassphrase,
+				VarsKeyFile:    varsKeyFile,
+				Guard:          mutationGuard(cmd, configProvider),
+				StrictVars:     strictVars,
+			}
+
+			report, err := comparer.CompareEnvironments(context.Background(), args, envA, envB, options)
+			if err != nil {
+				return fmt.Errorf("failed to compare environments: %w", err)
+			}
+
+			// Print report to console
+			consoleOptions := options.ReportOptions
+			consoleOptions.Format = "console"
+			if err := testReporter.PrintReport(context.Background(), report, consoleOptions, os.Stdout); err != nil {
+				return fmt.Errorf("failed to print report: %w", err)
+			}
+
+			// Generate report file if output path specified
+			if reportOutput != "" {
+				if err := testReporter.SaveReport(context.Background(), report, options.ReportOptions); err != nil {
+					return fmt.Errorf("failed to save report: %w", err)
+				}
+				fmt.Printf("Report saved to %s\n", reportOutput)
+			}
+
+			if report.Summary.FailedTests > 0 || report.Summary.ErrorTests > 0 {
+				return newExitError(ExitTestFailures, fmt.Errorf("environments differ: %d different, %d errors", report.Summary.FailedTests, report.Summary.ErrorTests))
+			}
+
+			return nil
+		},
+	}
+
+	compareCmd.Flags().String("env-a", "", "First environment: a name from the configuration or a base URL")
+	compareCmd.Flags().String("env-b", "", "Second environment: a name from the configuration or a base URL")
+	compareCmd.Flags().String("ignore-headers", "X-Request-Id,ETag", "Comma-separated header patterns to ignore on top of snapshots.ignore_headers, \"!Name\" to compare one again")
+	compareCmd.Flags().String("timeout", "30s", "HTTP request timeout")
+	compareCmd.Flags().Bool("stop-on-failure", false, "Stop after the first difference")
+	compareCmd.Flags().StringSlice("tags", []string{}, tagsFlagUsage)
+	compareCmd.Flags().StringSlice("methods", []string{}, "Filter tests by HTTP methods")
+	compareCmd.Flags().StringSlice("names", []string{}, "Filter tests by test names")
+	compareCmd.Flags().String("report-format", "console", "Report format: console, json, html, junit")
+	compareCmd.Flags().String("report-output", "", "Path to write report file")
+	compareCmd.Flags().Bool("detailed", false, "Include detailed information in report")
+	compareCmd.Flags().Int("inline-max-bytes", 0, "Save bodies larger than this as files next to JSON and HTML reports (0 inlines all)")
+	compareCmd.Flags().Bool("ignore-array-order", false, "Compare JSON arrays regardless of element order")
+	compareCmd.Flags().String("array-order-key", "", "Sort arrays of objects by this field when ignoring array order")
+	compareCmd.Flags().String("vars-passphrase", "", "Passphrase for encrypted variable files (or SWAGGER_TO_HTTP_VARS_PASSPHRASE)")
+	compareCmd.Flags().String("vars-key-file", "", "Key file for encrypted variable files (or SWAGGER_TO_HTTP_VARS_KEY_FILE)")
+	compareCmd.Flags().Bool("strict-vars", true, "Fail requests using variables without a value rather than sending the {{placeholders}} as written")
+	addGuardFlags(compareCmd, configProvider)
+	compareCmd.MarkFlagRequired("env-a")
+	compareCmd.MarkFlagRequired("env-b")
+
+	return compareCmd
+}
+
+// resolveEnvironment looks up a named environment in the configuration, or treats
+// the value as the base URL of an unnamed environment
+func resolveEnvironment(configProvider application.ConfigProvider, name string) (models.Environment, error) {
+	if baseURL := configProvider.GetString("environments." + strings.ToLower(name)); baseURL != "" {
+		return models.Environment{Name: name, BaseURL: baseURL}, nil
+	}
+
+	if parsed, err := url.Parse(name); err == nil && parsed.Scheme != "" && parsed.Host != "" {
+		return models.Environment{Name: parsed.Host, BaseURL: name}, nil
+	}
+
+	return models.Environment{}, fmt.Errorf("unknown environment %q: add it to the environments section of the configuration or pass a base URL", name)
+}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// setupCompletionCmd creates the 'completion' command
+func setupCompletionCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "completion [bash|zsh|fish|powershell]",
+		Short: "Generate shell completion scripts",
+		Long: `Generate a completion script for the specified shell.
+
+Bash:
+  $ source <(swagger-to-http completion bash)
+  # Load completions for every session (Linux):
+  $ swagger-to-http completion bash > /etc/bash_completion.d/swagger-to-http
+
+Zsh:
+  $ swagger-to-http completion zsh > "${fpath[1]}/_swagger-to-http"
+
+Fish:
+  $ swagger-to-http completion fish > ~/.config/fish/completions/swagger-to-http.fish
+
+PowerShell:
+  PS> swagger-to-http completion powershell | Out-String | Invoke-Expression`,
+		ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+		Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		DisableFlagsInUseLine: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root := cmd.Root()
+			out := cmd.OutOrStdout()
+
+			switch args[0] {
+			case "bash":
+				return root.GenBashCompletionV2(out, true)
+			case "zsh":
+				return root.GenZshCompletion(out)
+			case "fish":
+				return root.GenFishCompletion(out, true)
+			case "powershell":
+				return root.GenPowerShellCompletionWithDesc(out)
+			default:
+				return fmt.Errorf("unsupported shell: %s", args[0])
+			}
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/application"
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/control"
+)
+
+// controlledTestRunner runs tests under a control API controller, so the runs
+// can be followed, paused and aborted through the API
+type controlledTestRunner struct {
+	application.TestRunner
+	controller *control.Controller
+}
+
+// RunTests runs the tests through the controller
+func (r *controlledTestRunner) RunTests(ctx context.Context, patterns []string, options models.TestRunOptions) (*models.TestReport, error) {
+	return r.controller.Run(ctx, options, func(ctx context.Context, options models.TestRunOptions) (*models.TestReport, error) {
+		return r.TestRunner.RunTests(ctx, patterns, options)
+	})
+}
+
+// startControlServer serves the control API on addr until the context is
+// cancelled. It returns the test runner wrapped by the controller, or the
+// runner unchanged and a nil controller when addr is empty.
+func startControlServer(ctx context.Context, addr string, testRunner application.TestRunner) (application.TestRunner, *control.Controller, error) {
+	if addr == "" {
+		return testRunner, nil, nil
+	}
+
+	listener, err := control.Listen(addr)
+	if err != nil {
+		return nil, nil, newExitError(ExitConfigError, err)
+	}
+
+	controller := control.NewController()
+	go func() {
+		if err := controller.Serve(ctx, listener); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}()
+	fmt.Printf("Control API listening on %s\n", addr)
+
+	return &controlledTestRunner{TestRunner: testRunner, controller: controller}, controller, nil
+}
+
+// forwardReruns calls rerun for every rerun requested through the control API
+// until the context is cancelled. It does nothing without a controller.
+func forwardReruns(ctx context.Context, controller *control.Controller, rerun func()) {
+	if controller == nil {
+		return
+	}
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-controller.Reruns():
+				rerun()
+			}
+		}
+	}()
+}
@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/application/harvest"
+	"github.com/edgardnogueira/swagger-to-http/internal/application/parser"
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/validator"
+	"github.com/spf13/cobra"
+)
+
+// setupExamplesCmd sets up the examples command and its subcommands
+func setupExamplesCmd() *cobra.Command {
+	examplesCmd := &cobra.Command{
+		Use:   "examples",
+		Short: "Manage the examples of Swagger/OpenAPI documents",
+	}
+
+	examplesCmd.AddCommand(setupExamplesHarvestCmd())
+
+	return examplesCmd
+}
+
+// setupExamplesHarvestCmd creates the command writing recorded responses into spec examples
+func setupExamplesHarvestCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "harvest",
+		Short: "Update spec examples from recorded responses",
+		Long: `Read the responses recorded in JSON test reports (test --report-format json), validate
+them against the schemas of the spec and write them into the examples of the matching
+responses, keeping the examples of the spec realistic.
+
+The updated document is written to --output, or back to the spec with --write.
+Responses that are not JSON, don't map to a declared response or don't match its
+schema are skipped and listed.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			specFile, _ := cmd.Flags().GetString("spec")
+			reportFiles, _ := cmd.Flags().GetStringSlice("report")
+			write, _ := cmd.Flags().GetBool("write")
+			output, _ := cmd.Flags().GetString("output")
+			skipValidation, _ := cmd.Flags().GetBool("skip-validation")
+
+			if write && output != "" {
+				return newExitError(ExitConfigError, fmt.Errorf("--write and --output cannot be used together"))
+			}
+			if !write && output == "" {
+				ext := filepath.Ext(specFile)
+				output = strings.TrimSuffix(specFile, ext) + ".examples" + ext
+			}
+			if write {
+				output = specFile
+			}
+
+			ctx := context.Background()
+
+			document, err := os.ReadFile(specFile)
+			if err != nil {
+				return newExitError(ExitConfigError, fmt.Errorf("failed to read spec: %w", err))
+			}
+			doc, err := parser.NewSwaggerParser().ParseFile(ctx, specFile)
+			if err != nil {
+				return newExitError(ExitSpecError, fmt.Errorf("failed to parse spec: %w", err))
+			}
+
+			var results []models.TestResult
+			for _, reportFile := range reportFiles {
+				reportResults, err := loadRecordedResults(reportFile)
+				if err != nil {
+					return newExitError(ExitConfigError, err)
+				}
+				results = append(results, reportResults...)
+			}
+
+			var validate harvest.ValidateFunc
+			if !skipValidation {
+				schemaValidator := validator.NewSchemaValidatorService()
+				validate = func(response *models.HTTPResponse, path, method string) (*models.SchemaValidationResult, error) {
+					return schemaValidator.ValidateResponseWithSwagger(ctx, response, doc, path, method, models.ValidationOptions{})
+				}
+			}
+
+			harvested := harvest.NewHarvester(doc, validate).Collect(results)
+			updated, skipped, err := harvest.Apply(document, harvested.Examples)
+			if err != nil {
+				return newExitError(ExitSpecError, err)
+			}
+			applied := len(harvested.Examples) - len(skipped)
+			skipped = append(harvested.Skipped, skipped...)
+
+			if err := os.WriteFile(output, updated, 0644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", output, err)
+			}
+
+			fmt.Printf("Harvested %d examples into %s\n", applied, output)
+			for _, s := range skipped {
+				fmt.Printf("  skipped %s: %s\n", s.Name, s.Reason)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().String("spec", "", "Swagger/OpenAPI file to update (required)")
+	cmd.Flags().StringSlice("report", nil, "JSON test report with recorded responses (repeatable, required)")
+	cmd.Flags().Bool("write", false, "Update the spec file in place")
+	cmd.Flags().StringP("output", "o", "", "File to write the updated spec to (default: <spec>.examples.<ext>)")
+	cmd.Flags().Bool("skip-validation", false, "Harvest responses without validating them against the schema")
+	cmd.MarkFlagRequired("spec")
+	cmd.MarkFlagRequired("report")
+
+	return cmd
+}
+
+// loadRecordedResults reads the results of a JSON test report, including the
+// steps of its sequences
+func loadRecordedResults(path string) ([]models.TestResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report: %w", err)
+	}
+
+	var report models.TestReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse report %s: %w", path, err)
+	}
+
+	results := report.Results
+	for _, sequence := range report.Sequences {
+		for _, step := range sequence.StepResults {
+			if step.Response == nil || step.Response.Request == nil {
+				continue
+			}
+			results = append(results, models.TestResult{
+				Name:     step.Name,
+				Request:  step.Response.Request,
+				Response: step.Response,
+				Status:   step.Status,
+			})
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// Exit codes returned by swagger-to-http. They are part of the command line
+// interface that CI pipelines depend on and must not be renumbered.
+const (
+	// ExitOK means the command completed and no failure class was triggered
+	ExitOK = 0
+	// ExitUnexpectedError is used for any error not covered by a specific code
+	ExitUnexpectedError = 1
+	// ExitTestFailures means one of the --fail-on failure classes was found
+	ExitTestFailures = 2
+	// ExitConfigError means invalid flags, configuration or variables
+	ExitConfigError = 3
+	// ExitSpecError means the Swagger/OpenAPI document could not be loaded
+	ExitSpecError = 4
+)
+
+// ExitError carries the process exit code for an error returned by a command
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+// Error implements the error interface
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// newExitError wraps err so the process exits with the given code
+func newExitError(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ExitError{Code: code, Err: err}
+}
+
+// ExitCode returns the process exit code for an error returned by Execute
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	return ExitUnexpectedError
+}
+
+// Failure classes accepted by --fail-on
+const (
+	FailOnFailed          = "failed"
+	FailOnError           = "error"
+	FailOnSchema          = "schema"
+	FailOnMissingSnapshot = "missing-snapshot"
+	FailOnDeprecated      = "deprecated"
+)
+
+// DefaultFailOn is the failure policy used when --fail-on is not given
+const DefaultFailOn = FailOnFailed + "," + FailOnError
+
+// failureClasses lists the failure classes in the order they are reported
+var failureClasses = []string{
+	FailOnFailed,
+	FailOnError,
+	FailOnSchema,
+	FailOnMissingSnapshot,
+	FailOnDeprecated,
+}
+
+// failurePolicy is the set of failure classes that make a run fail
+type failurePolicy map[string]bool
+
+// parseFailOn parses a comma-separated list of failure classes.
+// "none" or an empty value disables failing on results entirely.
+func parseFailOn(value string) (failurePolicy, error) {
+	policy := make(failurePolicy)
+	for _, class := range strings.Split(value, ",") {
+		class = strings.ToLower(strings.TrimSpace(class))
+		if class == "" || class == "none" {
+			continue
+		}
+		if !isFailureClass(class) {
+			return nil, newExitError(ExitConfigError, fmt.Errorf(
+				"invalid --fail-on value %q: expected a comma-separated list of %s",
+				class, strings.Join(failureClasses, ", ")))
+		}
+		policy[class] = true
+	}
+	return policy, nil
+}
+
+// isFailureClass reports whether class is a known failure class
+func isFailureClass(class string) bool {
+	for _, known := range failureClasses {
+		if class == known {
+			return true
+		}
+	}
+	return false
+}
+
+// countFailures counts the results of a report per failure class
+func countFailures(report *models.TestReport) map[string]int {
+	counts := make(map[string]int)
+	for _, result := range report.Results {
+		// Quarantined tests are known failures that don't fail the build
+		if result.Quarantined {
+			continue
+		}
+
+		switch result.Status {
+		case models.TestStatusFailed:
+			counts[FailOnFailed]++
+		case models.TestStatusError, models.TestStatusCircuitOpen:
+			counts[FailOnError]++
+		}
+		if result.SchemaResult != nil && !result.SchemaResult.Valid {
+			counts[FailOnSchema]++
+		}
+		if result.SnapshotMissing {
+			counts[FailOnMissingSnapshot]++
+		}
+		if result.Request != nil && result.Request.Deprecated {
+			counts[FailOnDeprecated]++
+		}
+	}
+	return counts
+}
+
+// check returns an ExitError with ExitTestFailures when the report contains
+// any result in one of the policy's failure classes
+func (p failurePolicy) check(report *models.TestReport) error {
+	if report == nil {
+		return nil
+	}
+
+	counts := countFailures(report)
+	var triggered []string
+	for _, class := range failureClasses {
+		if p[class] && counts[class] > 0 {
+			triggered = append(triggered, fmt.Sprintf("%d %s", counts[class], class))
+		}
+	}
+	if len(triggered) == 0 {
+		return nil
+	}
+
+	return newExitError(ExitTestFailures, fmt.Errorf("tests failed: %s", strings.Join(triggered, ", ")))
+}
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "no error", err: nil, want: ExitOK},
+		{name: "plain error", err: errors.New("boom"), want: ExitUnexpectedError},
+		{name: "exit error", err: newExitError(ExitSpecError, errors.New("bad spec")), want: ExitSpecError},
+		{name: "wrapped exit error", err: fmt.Errorf("loading: %w", newExitError(ExitConfigError, errors.New("bad flag"))), want: ExitConfigError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ExitCode(tt.err))
+		})
+	}
+}
+
+func TestParseFailOn(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    failurePolicy
+		wantErr string
+	}{
+		{name: "default", value: DefaultFailOn, want: failurePolicy{FailOnFailed: true, FailOnError: true}},
+		{name: "spaces and case", value: " Schema , DEPRECATED", want: failurePolicy{FailOnSchema: true, FailOnDeprecated: true}},
+		{name: "none", value: "none", want: failurePolicy{}},
+		{name: "empty", value: "", want: failurePolicy{}},
+		{name: "unknown class", value: "failed,flaky", wantErr: `invalid --fail-on value "flaky"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := parseFailOn(tt.value)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				assert.Equal(t, ExitConfigError, ExitCode(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, policy)
+		})
+	}
+}
+
+func TestFailurePolicy_Check(t *testing.T) {
+	passed := models.TestResult{Name: "passed", Status: models.TestStatusPassed}
+	failed := models.TestResult{Name: "failed", Status: models.TestStatusFailed}
+	errored := models.TestResult{Name: "errored", Status: models.TestStatusError}
+	circuitOpen := models.TestResult{Name: "circuitOpen", Status: models.TestStatusCircuitOpen}
+	quarantined := models.TestResult{Name: "quarantined", Status: models.TestStatusFailed, Quarantined: true}
+	invalidSchema := models.TestResult{Name: "invalidSchema", Status: models.TestStatusPassed, SchemaResult: &models.SchemaValidationResult{Valid: false}}
+	missingSnapshot := models.TestResult{Name: "missingSnapshot", Status: models.TestStatusPassed, SnapshotMissing: true}
+	deprecated := models.TestResult{Name: "deprecated", Status: models.TestStatusPassed, Request: &models.HTTPRequest{Deprecated: true}}
+
+	tests := []struct {
+		name      string
+		failOn    string
+		results   []models.TestResult
+		wantCode  int
+		wantError string
+	}{
+		{name: "all passed", failOn: DefaultFailOn, results: []models.TestResult{passed}, wantCode: ExitOK},
+		{name: "failed", failOn: DefaultFailOn, results: []models.TestResult{passed, failed}, wantCode: ExitTestFailures, wantError: "tests failed: 1 failed"},
+		{name: "errors and open circuits", failOn: DefaultFailOn, results: []models.TestResult{errored, circuitOpen}, wantCode: ExitTestFailures, wantError: "tests failed: 2 error"},
+		{name: "classes in order", failOn: "error,failed", results: []models.TestResult{errored, failed, failed}, wantCode: ExitTestFailures, wantError: "tests failed: 2 failed, 1 error"},
+		{name: "quarantined failures", failOn: DefaultFailOn, results: []models.TestResult{quarantined}, wantCode: ExitOK},
+		{name: "class not in the policy", failOn: FailOnError, results: []models.TestResult{failed}, wantCode: ExitOK},
+		{name: "none", failOn: "none", results: []models.TestResult{failed, errored}, wantCode: ExitOK},
+		{name: "schema ignored by default", failOn: DefaultFailOn, results: []models.TestResult{invalidSchema}, wantCode: ExitOK},
+		{name: "schema", failOn: FailOnSchema, results: []models.TestResult{invalidSchema}, wantCode: ExitTestFailures, wantError: "tests failed: 1 schema"},
+		{name: "missing snapshot", failOn: FailOnMissingSnapshot, results: []models.TestResult{missingSnapshot}, wantCode: ExitTestFailures, wantError: "tests failed: 1 missing-snapshot"},
+		{name: "deprecated", failOn: FailOnDeprecated, results: []models.TestResult{deprecated, passed}, wantCode: ExitTestFailures, wantError: "tests failed: 1 deprecated"},
+		{name: "failed with an invalid schema", failOn: "failed,schema", results: []models.TestResult{{Status: models.TestStatusFailed, SchemaResult: &models.SchemaValidationResult{}}}, wantCode: ExitTestFailures, wantError: "tests failed: 1 failed, 1 schema"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := parseFailOn(tt.failOn)
+			require.NoError(t, err)
+
+			err = policy.check(&models.TestReport{Results: tt.results})
+			assert.Equal(t, tt.wantCode, ExitCode(err))
+			if tt.wantError != "" {
+				assert.EqualError(t, err, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestFailurePolicy_CheckNilReport(t *testing.T) {
+	policy, err := parseFailOn(DefaultFailOn)
+	require.NoError(t, err)
+	assert.NoError(t, policy.check(nil))
+}
@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/application/explain"
+	"github.com/edgardnogueira/swagger-to-http/internal/application/parser"
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/extractor"
+	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/http"
+	"github.com/spf13/cobra"
+)
+
+// setupExplainCmd creates the command printing per-request diagnostics for HTTP files
+func setupExplainCmd(httpParser *http.Parser) *cobra.Command {
+	explainCmd := &cobra.Command{
+		Use:   "explain [files]",
+		Short: "Explain how the requests of HTTP files will be executed",
+		Long: `Print per-request diagnostics for HTTP files without executing them: the resolved URL,
+the variables that are referenced and missing, and, with --spec, the operation each
+request maps to and what the spec expects of its responses.
+
+Use --json for machine-readable output intended for editor plugins.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			specFile, _ := cmd.Flags().GetString("spec")
+			jsonOutput, _ := cmd.Flags().GetBool("json")
+			varsPassphrase, _ := cmd.Flags().GetString("vars-passphrase")
+			varsKeyFile, _ := cmd.Flags().GetString("vars-key-file")
+
+			ctx := context.Background()
+
+			var doc *models.SwaggerDoc
+			if specFile != "" {
+				if _, err := os.Stat(specFile); err != nil {
+					return newExitError(ExitConfigError, fmt.Errorf("spec file not found: %s", specFile))
+				}
+				var err error
+				doc, err = parser.NewSwaggerParser().ParseFile(ctx, specFile)
+				if err != nil {
+					return newExitError(ExitSpecError, fmt.Errorf("failed to parse spec: %w", err))
+				}
+			}
+
+			explainer := explain.NewExplainer(doc)
+			scopeService := extractor.NewVariableScopeService()
+			variableOptions := models.TestRunOptions{
+				VarsPassphrase: varsPassphrase,
+				VarsKeyFile:    varsKeyFile,
+			}
+
+			var explanations []*explain.FileExplanation
+			for _, file := range args {
+				httpFile, err := httpParser.ParseFile(file)
+				if err != nil {
+					return fmt.Errorf("failed to parse %s: %w", file, err)
+				}
+
+				variables, err := fileVariables(ctx, scopeService, file, variableOptions)
+				if err != nil {
+					return newExitError(ExitConfigError, fmt.Errorf("failed to resolve variables for %s: %w", file, err))
+				}
+
+				explanations = append(explanations, explainer.ExplainFile(file, httpFile.Requests, variables))
+			}
+
+			if jsonOutput {
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(explanations)
+			}
+
+			writeExplanations(cmd.OutOrStdout(), explanations)
+			return nil
+		},
+	}
+
+	explainCmd.Flags().String("spec", "", "Swagger/OpenAPI file to map the requests to")
+	explainCmd.Flags().Bool("json", false, "Print the diagnostics as JSON")
+	explainCmd.Flags().String("vars-passphrase", "", "Passphrase for encrypted variable files (or SWAGGER_TO_HTTP_VARS_PASSPHRASE)")
+	explainCmd.Flags().String("vars-key-file", "", "Key file for encrypted variable files (or SWAGGER_TO_HTTP_VARS_KEY_FILE)")
+
+	return explainCmd
+}
+
+// writeExplanations prints the explanations in a human-readable form
+func writeExplanations(w io.Writer, explanations []*explain.FileExplanation) {
+	for _, file := range explanations {
+		fmt.Fprintf(w, "%s\n", file.File)
+		for _, request := range file.Requests {
+			name := request.Name
+			if name == "" {
+				name = request.Method + " " + request.URL
+			}
+			fmt.Fprintf(w, "\n  %s (line %d)\n", name, request.Line)
+			fmt.Fprintf(w, "    URL:       %s %s\n", request.Method, request.ResolvedURL)
+			if len(request.Variables) > 0 {
+				fmt.Fprintf(w, "    Variables: %s\n", strings.Join(request.Variables, ", "))
+			}
+			if operation := request.Operation; operation != nil {
+				fmt.Fprintf(w, "    Operation: %s %s", operation.Method, operation.Path)
+				if operation.OperationID != "" {
+					fmt.Fprintf(w, " (%s)", operation.OperationID)
+				}
+				fmt.Fprintln(w)
+				if len(operation.RequiredParameters) > 0 {
+					fmt.Fprintf(w, "    Required:  %s\n", strings.Join(operation.RequiredParameters, ", "))
+				}
+				for _, response := range operation.Responses {
+					fmt.Fprintf(w, "    Response:  %s", response.Status)
+					if response.Schema != "" {
+						fmt.Fprintf(w, " %s", response.Schema)
+					}
+					if response.Description != "" {
+						fmt.Fprintf(w, " - %s", response.Description)
+					}
+					fmt.Fprintln(w)
+				}
+			}
+			for _, diagnostic := range request.Diagnostics {
+				fmt.Fprintf(w, "    %s: %s\n", diagnostic.Severity, diagnostic.Message)
+			}
+		}
+		fmt.Fprintln(w)
+	}
+}
@@ -0,0 +1,315 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/application"
+	"github.com/edgardnogueira/swagger-to-http/internal/application/snapshot"
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/extractor"
+	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/http"
+	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/k6"
+	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/sequencer"
+	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/snapstore"
+	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/vscode"
+	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/wiremock"
+	"github.com/spf13/cobra"
+)
+
+// setupExportCmd sets up the export command and its subcommands
+func setupExportCmd(configProvider application.ConfigProvider) *cobra.Command {
+	exportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export HTTP files and settings for other tools",
+	}
+
+	exportCmd.AddCommand(setupExportVSCodeCmd(configProvider))
+	exportCmd.AddCommand(setupExportWireMockCmd(configProvider))
+	exportCmd.AddCommand(setupExportK6Cmd(configProvider))
+
+	return exportCmd
+}
+
+// setupExportVSCodeCmd creates the command writing VS Code REST Client settings
+func setupExportVSCodeCmd(configProvider application.ConfigProvider) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "vscode",
+		Short: "Write VS Code REST Client environments from the environment profiles",
+		Long: `Write the rest-client.environmentVariables setting of .vscode/settings.json so the
+HTTP files can be run with the VS Code REST Client extension using the same variables
+as the CLI.
+
+Every entry of the "environments" section of the configuration becomes a REST Client
+environment setting {{baseUrl}}, and the sidecar variables (variables.json, .http-env)
+of the HTTP file directory become shared variables. Other settings in the file are kept.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir, _ := cmd.Flags().GetString("dir")
+			output, _ := cmd.Flags().GetString("output")
+			varsPassphrase, _ := cmd.Flags().GetString("vars-passphrase")
+			varsKeyFile, _ := cmd.Flags().GetString("vars-key-file")
+
+			profiles := make(map[string]string)
+			for name, baseURL := range configProvider.GetStringMap("environments") {
+				profiles[name] = fmt.Sprint(baseURL)
+			}
+
+			// The sidecar variables of the directory and the directories above it
+			shared, err := extractor.NewVariableScopeService().ResolveVariables(
+				context.Background(),
+				filepath.Join(dir, extractor.VariablesFileName),
+				models.TestRunOptions{VarsPassphrase: varsPassphrase, VarsKeyFile: varsKeyFile},
+			)
+			if err != nil {
+				return newExitError(ExitConfigError, fmt.Errorf("failed to resolve variables: %w", err))
+			}
+
+			if len(profiles) == 0 && len(shared) == 0 {
+				return newExitError(ExitConfigError, fmt.Errorf("nothing to export: add an environments section to the configuration or variable files to %s", dir))
+			}
+
+			environments := vscode.NewEnvironments(profiles, shared, application.BaseURLVariable)
+			if err := vscode.WriteSettings(output, environments); err != nil {
+				return err
+			}
+
+			fmt.Printf("Wrote %d REST Client environments to %s\n", len(profiles), output)
+			return nil
+		},
+	}
+
+	defaultDir := configProvider.GetString("output.directory")
+	if defaultDir == "" {
+		defaultDir = "http-requests"
+	}
+	cmd.Flags().String("dir", defaultDir, "Directory of the HTTP files whose variable files are shared")
+	cmd.Flags().StringP("output", "o", vscode.DefaultSettingsPath, "Settings file to write")
+	cmd.Flags().String("vars-passphrase", "", "Passphrase for encrypted variable files (or SWAGGER_TO_HTTP_VARS_PASSPHRASE)")
+	cmd.Flags().String("vars-key-file", "", "Key file for encrypted variable files (or SWAGGER_TO_HTTP_VARS_KEY_FILE)")
+
+	return cmd
+}
+
+// setupExportWireMockCmd creates the command writing WireMock stubs from recorded
+// responses or snapshots
+func setupExportWireMockCmd(configProvider application.ConfigProvider) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "wiremock",
+		Short: "Export recorded responses or snapshots as WireMock stub mappings",
+		Long: `Write a WireMock stub mapping per request into <output>/mappings, with the response
+bodies in <output>/__files, so a stub server mirrors the state of the tests, e.g. for
+frontend development:
+
+  docker run -p 8080:8080 -v $PWD/wiremock:/home/wiremock wiremock/wiremock
+
+The responses come from JSON test reports given with --report (test --report-format
+json), or else from the snapshots of the requests of the HTTP files in --dir.
+Variables in URLs match any value; request bodies are matched unless they use
+variables. Requests repeating the method, URL and body of an earlier one are skipped.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			reportFiles, _ := cmd.Flags().GetStringSlice("report")
+			dir, _ := cmd.Flags().GetString("dir")
+			snapshotDir, _ := cmd.Flags().GetString("snapshot-dir")
+			output, _ := cmd.Flags().GetString("output")
+
+			var exchanges []wiremock.Exchange
+			if len(reportFiles) > 0 {
+				for _, reportFile := range reportFiles {
+					results, err := loadRecordedResults(reportFile)
+					if err != nil {
+						return newExitError(ExitConfigError, err)
+					}
+					exchanges = append(exchanges, wiremock.FromResults(results)...)
+				}
+			} else {
+				found, err := snapshotExchanges(dir, snapshotDir)
+				if err != nil {
+					return newExitError(ExitConfigError, err)
+				}
+				exchanges = found
+			}
+
+			if len(exchanges) == 0 {
+				return newExitError(ExitConfigError, fmt.Errorf("no recorded responses or snapshots found"))
+			}
+
+			written, err := wiremock.Write(output, exchanges)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Wrote %d WireMock stubs to %s\n", written, output)
+			return nil
+		},
+	}
+
+	defaultDir := configProvider.GetString("output.directory")
+	if defaultDir == "" {
+		defaultDir = "http-requests"
+	}
+	cmd.Flags().StringSlice("report", nil, "JSON test report with recorded responses (repeatable)")
+	cmd.Flags().String("dir", defaultDir, "Directory of the HTTP files whose snapshots are exported")
+	cmd.Flags().String("snapshot-dir", ".snapshots", "Directory of the snapshots")
+	cmd.Flags().StringP("output", "o", "wiremock", "WireMock root directory to write the stubs to")
+
+	return cmd
+}
+
+// setupExportK6Cmd creates the command writing a k6 load test script from HTTP
+// files and sequences
+func setupExportK6Cmd(configProvider application.ConfigProvider) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "k6",
+		Short: "Export HTTP files and test sequences as a k6 load test script",
+		Long: `Write a k6 script sending the requests of the HTTP files in --dir, then the steps of the
+sequences given with --sequence, on every iteration, so load tests reuse the functional
+test collections:
+
+  k6 run load-test.js
+
+Each HTTP file and sequence becomes a k6 group. Expected statuses and headers
+(# @expect-status, # @expect-header) and the assertions of sequence steps become
+checks, and the variables sequence steps extract are set for the following steps.
+Assertions k6 can't evaluate, such as approx, are left out with a comment.
+
+The sidecar variables of --dir (variables.json, .http-env) and the base URL of --env
+are the defaults of the script's variables, which k6 run -e name=value overrides.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir, _ := cmd.Flags().GetString("dir")
+			sequenceFiles, _ := cmd.Flags().GetStringSlice("sequence")
+			output, _ := cmd.Flags().GetString("output")
+			envName, _ := cmd.Flags().GetString("env")
+			vus, _ := cmd.Flags().GetInt("vus")
+			iterations, _ := cmd.Flags().GetInt("iterations")
+			duration, _ := cmd.Flags().GetString("duration")
+			varsPassphrase, _ := cmd.Flags().GetString("vars-passphrase")
+			varsKeyFile, _ := cmd.Flags().GetString("vars-key-file")
+
+			ctx := context.Background()
+			variables, err := extractor.NewVariableScopeService().ResolveVariables(
+				ctx,
+				filepath.Join(dir, extractor.VariablesFileName),
+				models.TestRunOptions{VarsPassphrase: varsPassphrase, VarsKeyFile: varsKeyFile},
+			)
+			if err != nil {
+				return newExitError(ExitConfigError, fmt.Errorf("failed to resolve variables: %w", err))
+			}
+			if envName != "" {
+				env, err := resolveEnvironment(configProvider, envName)
+				if err != nil {
+					return newExitError(ExitConfigError, err)
+				}
+				variables[application.BaseURLVariable] = strings.TrimSuffix(env.BaseURL, "/")
+			}
+
+			groups, err := httpFileGroups(dir)
+			if err != nil {
+				return newExitError(ExitConfigError, err)
+			}
+			for _, sequenceFile := range sequenceFiles {
+				sequence, err := (&sequencer.SequenceRunnerService{}).ParseSequenceFile(ctx, sequenceFile)
+				if err != nil {
+					return newExitError(ExitConfigError, err)
+				}
+				groups = append(groups, k6.FromSequence(sequence))
+			}
+
+			if len(groups) == 0 {
+				return newExitError(ExitConfigError, fmt.Errorf("nothing to export: no HTTP files in %s and no sequences given", dir))
+			}
+
+			options := k6.Options{VUs: vus, Iterations: iterations, Duration: duration, Variables: variables, Source: dir}
+			if err := k6.Write(output, groups, options); err != nil {
+				return err
+			}
+
+			fmt.Printf("Wrote a k6 script running %d groups to %s\n", len(groups), output)
+			return nil
+		},
+	}
+
+	defaultDir := configProvider.GetString("output.directory")
+	if defaultDir == "" {
+		defaultDir = "http-requests"
+	}
+	cmd.Flags().String("dir", defaultDir, "Directory of the HTTP files to export")
+	cmd.Flags().StringSlice("sequence", nil, "Test sequence file to export after the HTTP files (repeatable)")
+	cmd.Flags().StringP("output", "o", "load-test.js", "k6 script to write")
+	cmd.Flags().String("env", "", "Environment whose base URL the script defaults to: a name from the configuration or a base URL")
+	cmd.Flags().Int("vus", 1, "Number of virtual users")
+	cmd.Flags().Int("iterations", 0, "Total number of iterations, one per virtual user by default")
+	cmd.Flags().String("duration", "", "Run for this long, e.g. 30s or 5m, instead of a number of iterations")
+	cmd.Flags().String("vars-passphrase", "", "Passphrase for encrypted variable files (or SWAGGER_TO_HTTP_VARS_PASSPHRASE)")
+	cmd.Flags().String("vars-key-file", "", "Key file for encrypted variable files (or SWAGGER_TO_HTTP_VARS_KEY_FILE)")
+
+	return cmd
+}
+
+// httpFileGroups returns a k6 group per HTTP file in a directory
+func httpFileGroups(dir string) ([]k6.Group, error) {
+	files, err := http.NewParser().ParseDirectory(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []k6.Group
+	for _, file := range files {
+		group := k6.Group{Name: filepath.Base(file.Filename)}
+		for _, request := range file.Requests {
+			group.Requests = append(group.Requests, k6.FromHTTPRequest(request))
+		}
+		if len(group.Requests) > 0 {
+			groups = append(groups, group)
+		}
+	}
+	return groups, nil
+}
+
+// snapshotExchanges pairs the requests of the HTTP files in a directory with
+// their snapshots, skipping requests that have none
+func snapshotExchanges(dir, snapshotDir string) ([]wiremock.Exchange, error) {
+	files, err := http.NewParser().ParseDirectory(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	store := snapstore.NewCompressed(snapstore.Local{}, snapstore.CompressionNone)
+	var exchanges []wiremock.Exchange
+	for _, file := range files {
+		for _, request := range file.Requests {
+			request.Path = file.Filename
+			path := application.SnapshotPath(snapshotDir, &request, models.TestRunOptions{})
+			content, err := store.Read(path)
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err == nil {
+				content, err = snapstore.LoadBody(store, path, content)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to read snapshot of %s: %w", request.Name, err)
+			}
+
+			response, err := (&snapshot.DefaultFormatter{}).Parse(string(content))
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse snapshot of %s: %w", request.Name, err)
+			}
+			exchanges = append(exchanges, wiremock.Exchange{
+				Name:         request.Name,
+				Method:       request.Method,
+				URL:          request.URL,
+				Body:         request.Body,
+				Status:       response.StatusCode,
+				Headers:      response.Headers,
+				ResponseBody: string(response.Body),
+			})
+		}
+	}
+	return exchanges, nil
+}
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/http"
+	"github.com/spf13/cobra"
+)
+
+// setupFmtCmd creates the command formatting HTTP files
+func setupFmtCmd(httpParser *http.Parser) *cobra.Command {
+	fmtCmd := &cobra.Command{
+		Use:   "fmt [files or directories]",
+		Short: "Format HTTP files",
+		Long: `Rewrite HTTP files in a normalized layout: requests separated by "###" lines
+between single blank lines, comments before directives written in a fixed order,
+canonical header names and JSON bodies indented with two spaces.
+
+Directories are searched recursively for .http files; without arguments the
+current directory is formatted. The names of the files that changed are printed.
+
+Use --check in CI to fail, without writing anything, when a file isn't formatted.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			check, _ := cmd.Flags().GetBool("check")
+
+			if len(args) == 0 {
+				args = []string{"."}
+			}
+			files, err := findHTTPFiles(args)
+			if err != nil {
+				return newExitError(ExitConfigError, err)
+			}
+			return formatHTTPFiles(cmd.OutOrStdout(), httpParser, files, check)
+		},
+	}
+
+	fmtCmd.Flags().Bool("check", false, "Fail when a file isn't formatted instead of writing it")
+
+	return fmtCmd
+}
+
+// formatHTTPFiles formats HTTP files and prints the names of those that
+// changed, writing them back, or failing when check is set and any changed
+func formatHTTPFiles(w io.Writer, httpParser *http.Parser, files []string, check bool) error {
+	writer := httpParser.Writer()
+
+	unformatted := 0
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		httpFile, err := httpParser.Parse(bytes.NewReader(content), file)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+		var formatted bytes.Buffer
+		if err := writer.Format(&formatted, httpFile); err != nil {
+			return fmt.Errorf("failed to format %s: %w", file, err)
+		}
+		if bytes.Equal(content, formatted.Bytes()) {
+			continue
+		}
+
+		unformatted++
+		fmt.Fprintln(w, file)
+		if check {
+			continue
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		if err := os.WriteFile(file, formatted.Bytes(), info.Mode().Perm()); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file, err)
+		}
+	}
+
+	if check && unformatted > 0 {
+		return newExitError(ExitTestFailures, fmt.Errorf("%d of %d HTTP files are not formatted, run swagger-to-http fmt", unformatted, len(files)))
+	}
+	return nil
+}
+
+// findHTTPFiles returns the files given and the .http files found
+// recursively in the directories given, skipping hidden directories, in the
+// order of their paths
+func findHTTPFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, root := range paths {
+		info, err := os.Stat(root)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find HTTP files: %w", err)
+		}
+		if !info.IsDir() {
+			files = append(files, root)
+			continue
+		}
+
+		var found []string
+		err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() {
+				if path != root && strings.HasPrefix(entry.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if filepath.Ext(path) == ".http" {
+				found = append(found, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list HTTP files in %s: %w", root, err)
+		}
+		sort.Strings(found)
+		files = append(files, found...)
+	}
+	return files, nil
+}
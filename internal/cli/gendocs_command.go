@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/version"
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+)
+
+// setupGenDocsCmd creates the hidden 'gendocs' command
+func setupGenDocsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:    "gendocs",
+		Short:  "Generate command reference documentation",
+		Long:   `Generate markdown pages or man pages for every command in the CLI tree`,
+		Hidden: true,
+		Args:   cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, _ := cmd.Flags().GetString("format")
+			outputDir, _ := cmd.Flags().GetString("output")
+
+			// Create output directory
+			if err := os.MkdirAll(outputDir, 0755); err != nil {
+				return fmt.Errorf("failed to create output directory: %w", err)
+			}
+
+			// Leave the generation date out of the pages so they only change
+			// with the commands; man pages honor SOURCE_DATE_EPOCH
+			root := cmd.Root()
+			root.DisableAutoGenTag = true
+
+			var err error
+			switch format {
+			case "markdown", "md":
+				err = doc.GenMarkdownTree(root, outputDir)
+			case "man":
+				err = doc.GenManTree(root, &doc.GenManHeader{
+					Section: "1",
+					Source:  "swagger-to-http " + version.Version,
+					Manual:  "User Commands",
+				}, outputDir)
+			default:
+				return fmt.Errorf("unsupported docs format: %s (use markdown or man)", format)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to generate documentation: %w", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Documentation written to %s\n", outputDir)
+			return nil
+		},
+	}
+
+	cmd.Flags().String("format", "markdown", "Documentation format: markdown, man")
+	cmd.Flags().StringP("output", "o", "docs/cli", "Directory to write documentation to")
+
+	return cmd
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/edgardnogueira/swagger-to-http/internal/application/generator"
@@ -12,6 +13,8 @@ import (
 	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
 	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/config"
 	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/fs"
+	"github.com/edgardnogueira/swagger-to-http/internal/version"
+	"github.com/edgardnogueira/swagger-to-http/pkg/plugin"
 	"github.com/spf13/cobra"
 )
 
@@ -25,19 +28,32 @@ var (
 	includeAuth  bool
 	authHeader   string
 	authToken    string
+	multiTag     string
+	sharedDir    string
+	bodyMode     string
+	readOnly     bool
+	securityFilter      []string
+	skipUnauthenticated bool
+	writeManifest       bool
+	forceGenerate       bool
+	outputFormat        string
+	formatOptions       []string
+	serverIndex  int
+	serverURL    string
+	serverVars   []string
 )
 
-// generateCmd represents the generate command
-var generateCmd = &cobra.Command{
-	Use:   "generate",
-	Short: "Generate HTTP files from a Swagger/OpenAPI document",
-	Long: `Generate HTTP request files from a Swagger/OpenAPI document.
+// setupGenerateCmd creates the 'generate' command
+func setupGenerateCmd() *cobra.Command {
+	generateCmd := &cobra.Command{
+		Use:        "generate",
+		Aliases:    []string{"gen"},
+		SuggestFor: []string{"convert"},
+		Short:      "Generate HTTP files from a Swagger/OpenAPI document",
+		Long: `Generate HTTP request files from a Swagger/OpenAPI document.
 This command parses the document and creates .http files organized by tags.`,
-	RunE: runGenerate,
-}
-
-func init() {
-	rootCmd.AddCommand(generateCmd)
+		RunE:       runGenerate,
+	}
 
 	// Required flags
 	generateCmd.Flags().StringVarP(&inputFile, "file", "f", "", "Swagger/OpenAPI file to process (required if url not provided)")
@@ -52,12 +68,61 @@ func init() {
 	generateCmd.Flags().BoolVar(&includeAuth, "auth", cp.GetBool("generator.include_auth"), "Include authentication header in requests")
 	generateCmd.Flags().StringVar(&authHeader, "auth-header", cp.GetString("generator.auth_header"), "Authentication header name")
 	generateCmd.Flags().StringVar(&authToken, "auth-token", cp.GetString("generator.auth_token"), "Authentication token value")
+	generateCmd.Flags().StringVar(&multiTag, "multi-tag", cp.GetString("generator.multi_tag"), "Placement of operations with several tags: first, duplicate or shared")
+	generateCmd.Flags().StringVar(&sharedDir, "shared-dir", cp.GetString("generator.shared_dir"), "Directory of operations shared by several tags with --multi-tag shared")
+	generateCmd.Flags().StringVar(&bodyMode, "body-mode", cp.GetString("generator.body_mode"), "Verbosity of request bodies: required-only, minimal or full")
+	generateCmd.Flags().BoolVar(&readOnly, "include-read-only", cp.GetBool("generator.include_read_only"), "Include readOnly properties in request bodies")
+	generateCmd.Flags().StringSliceVar(&securityFilter, "security-filter", cp.GetStringSlice("generator.security_filter"), "Only generate operations using these security schemes, by name or type (apiKey, http, oauth2...); !scheme leaves them out, none matches public operations")
+	generateCmd.Flags().BoolVar(&skipUnauthenticated, "skip-unauthenticated", cp.GetBool("generator.skip_unauthenticated"), "Leave out operations that can be called without credentials")
+	generateCmd.Flags().BoolVar(&writeManifest, "manifest", cp.GetBool("generator.manifest"), "Write collection.json listing the generated requests")
+	generateCmd.Flags().StringVar(&outputFormat, "format", cp.GetString("generator.format"), "Output format: http, or the format of a generator plugin")
+	generateCmd.Flags().StringSliceVar(&formatOptions, "format-opt", []string{}, "Option passed to the generator plugin as name=value (repeatable)")
+	generateCmd.Flags().BoolVar(&forceGenerate, "force", false, "Generate even when the spec and options haven't changed since the last generation")
+	generateCmd.Flags().IntVar(&serverIndex, "server-index", 0, "Index of the server in the document's servers to send requests to")
+	generateCmd.Flags().StringVar(&serverURL, "server-url", "", "URL of the server in the document's servers to send requests to, templated or resolved")
+	generateCmd.Flags().StringSliceVar(&serverVars, "server-var", []string{}, "Value of a server variable as name=value (repeatable)")
+
+	return generateCmd
 }
 
 func runGenerate(cmd *cobra.Command, args []string) error {
 	// Validate input parameters
 	if inputFile == "" && inputURL == "" {
-		return fmt.Errorf("either --file or --url must be provided")
+		return newExitError(ExitConfigError, fmt.Errorf("either --file or --url must be provided"))
+	}
+
+	switch multiTag {
+	case "", generator.MultiTagFirst, generator.MultiTagDuplicate, generator.MultiTagShared:
+	default:
+		return newExitError(ExitConfigError, fmt.Errorf("invalid --multi-tag %q: use first, duplicate or shared", multiTag))
+	}
+
+	switch bodyMode {
+	case "", generator.BodyModeRequiredOnly, generator.BodyModeMinimal, generator.BodyModeFull:
+	default:
+		return newExitError(ExitConfigError, fmt.Errorf("invalid --body-mode %q: use required-only, minimal or full", bodyMode))
+	}
+
+	serverVariables, err := models.ParseServerVariables(serverVars)
+	if err != nil {
+		return newExitError(ExitConfigError, err)
+	}
+
+	// Formats other than .http files are generated by plugins
+	var formatGenerator plugin.Generator
+	if outputFormat != "" && outputFormat != "http" {
+		var ok bool
+		if formatGenerator, ok = plugin.DefaultRegistry.Generator(outputFormat); !ok {
+			return newExitError(ExitConfigError, fmt.Errorf("invalid --format %q: use http or one of the generator plugins (%s)", outputFormat, strings.Join(plugin.DefaultRegistry.Generators(), ", ")))
+		}
+	}
+	pluginOptions := make(map[string]string, len(formatOptions))
+	for _, option := range formatOptions {
+		name, value, ok := strings.Cut(option, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return newExitError(ExitConfigError, fmt.Errorf("invalid --format-opt %q: use name=value", option))
+		}
+		pluginOptions[strings.TrimSpace(name)] = value
 	}
 
 	// Create context with timeout
@@ -68,9 +133,23 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 	swaggerParser := parser.NewSwaggerParser()
 
 	// Parse document
-	var swaggerDoc, err = parseDocument(ctx, swaggerParser, inputFile, inputURL)
+	swaggerDoc, err := parseDocument(ctx, swaggerParser, inputFile, inputURL)
 	if err != nil {
-		return err
+		return newExitError(ExitSpecError, err)
+	}
+
+	// Skip the generation when the output was generated from the same spec
+	// and options by the same version
+	checksum := version.GenerationChecksum(swaggerDoc.SpecHash, generationOptions(serverVariables))
+	if !forceGenerate {
+		previous, err := version.ReadChecksum(outputDir)
+		if err != nil {
+			return err
+		}
+		if previous == checksum {
+			log.Printf("Nothing changed since %s was generated, skipping (use --force to regenerate)\n", outputDir)
+			return nil
+		}
 	}
 
 	// Create generator with options
@@ -79,18 +158,47 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 		generator.WithDefaultTag(defaultTag),
 		generator.WithIndentJSON(indentJSON),
 		generator.WithAuth(includeAuth, authHeader, authToken),
+		generator.WithMultiTag(multiTag),
+		generator.WithSharedDir(sharedDir),
+		generator.WithBodyMode(bodyMode),
+		generator.WithReadOnly(readOnly),
+		generator.WithSecurityFilter(securityFilter),
+		generator.WithSkipUnauthenticated(skipUnauthenticated),
+		generator.WithManifest(writeManifest),
+		generator.WithServer(models.ServerSelector{Index: serverIndex, URL: serverURL, Variables: serverVariables}),
 	)
 
 	// Generate HTTP requests
 	log.Println("Generating HTTP requests...")
 	collection, err := httpGenerator.Generate(ctx, swaggerDoc)
 	if err != nil {
-		return fmt.Errorf("failed to generate HTTP requests: %w", err)
+		return newExitError(ExitConfigError, fmt.Errorf("failed to generate HTTP requests: %w", err))
 	}
 
 	// Set the output directory
 	collection.RootDir = outputDir
 
+	if formatGenerator != nil {
+		log.Printf("Generating %s files with plugin...\n", outputFormat)
+		files, err := formatGenerator.Generate(ctx, &plugin.GenerateInput{
+			Spec:     swaggerDoc,
+			Requests: plugin.CollectionRequests(collection),
+			Options:  pluginOptions,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to generate %s files: %w", outputFormat, err)
+		}
+		log.Printf("Writing %s files to directory: %s\n", outputFormat, outputDir)
+		if err := plugin.WriteFiles(outputDir, files); err != nil {
+			return err
+		}
+		if err := version.WriteChecksum(outputDir, checksum); err != nil {
+			return err
+		}
+		log.Printf("Successfully generated %d %s files!\n", len(files), outputFormat)
+		return nil
+	}
+
 	// Create file writer
 	fileWriter := fs.NewFileWriter()
 
@@ -99,11 +207,44 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 	if err := fileWriter.WriteCollection(ctx, collection); err != nil {
 		return fmt.Errorf("failed to write HTTP files: %w", err)
 	}
+	if err := version.WriteChecksum(outputDir, checksum); err != nil {
+		return err
+	}
 
 	log.Println("Successfully generated HTTP files!")
 	return nil
 }
 
+// generationOptions lists the options of the generate command that change
+// the generated files, as "name=value"
+func generationOptions(serverVariables map[string]string) []string {
+	options := []string{
+		"base-url=" + baseURL,
+		"default-tag=" + defaultTag,
+		fmt.Sprintf("indent-json=%t", indentJSON),
+		fmt.Sprintf("auth=%t", includeAuth),
+		"auth-header=" + authHeader,
+		"auth-token=" + authToken,
+		"multi-tag=" + multiTag,
+		"shared-dir=" + sharedDir,
+		"body-mode=" + bodyMode,
+		fmt.Sprintf("include-read-only=%t", readOnly),
+		"security-filter=" + strings.Join(securityFilter, ","),
+		fmt.Sprintf("skip-unauthenticated=%t", skipUnauthenticated),
+		fmt.Sprintf("manifest=%t", writeManifest),
+		fmt.Sprintf("server-index=%d", serverIndex),
+		"server-url=" + serverURL,
+		"format=" + outputFormat,
+	}
+	for name, value := range serverVariables {
+		options = append(options, "server-var="+name+"="+value)
+	}
+	for _, option := range formatOptions {
+		options = append(options, "format-opt="+option)
+	}
+	return options
+}
+
 // parseDocument parses a Swagger/OpenAPI document from a file or URL
 func parseDocument(ctx context.Context, swaggerParser *parser.SwaggerParser, filePath, url string) (*models.SwaggerDoc, error) {
 	if filePath != "" {
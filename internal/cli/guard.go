@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/application"
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/spf13/cobra"
+)
+
+// addGuardFlags adds the flags that allow requests changing data to run
+// against shared environments
+func addGuardFlags(cmd *cobra.Command, configProvider application.ConfigProvider) {
+	cmd.Flags().Bool("allow-mutations", configProvider.GetBool("test.allow_mutations"), "Run requests other than GET and HEAD against non-local hosts")
+	cmd.Flags().StringSlice("allow-delete", []string{}, "Host DELETE requests may run against without confirmation, wildcards allowed (repeatable)")
+}
+
+// mutationGuard builds the guard from the flags added by addGuardFlags and the
+// configured DELETE allowlist. DELETE requests outside the allowlist are
+// confirmed interactively when the input is a terminal.
+func mutationGuard(cmd *cobra.Command, configProvider application.ConfigProvider) models.MutationGuard {
+	allowMutations, _ := cmd.Flags().GetBool("allow-mutations")
+	allowDelete, _ := cmd.Flags().GetStringSlice("allow-delete")
+
+	guard := models.MutationGuard{
+		AllowMutations:  allowMutations,
+		DeleteAllowlist: append(allowDelete, configProvider.GetStringSlice("test.delete_allowlist")...),
+	}
+	if isTerminal(os.Stdin) {
+		guard.Confirm = confirmDelete()
+	}
+	return guard
+}
+
+// confirmDelete returns a function asking on the terminal whether a DELETE
+// request may run. Prompts of parallel tests are asked one at a time.
+func confirmDelete() func(request *models.HTTPRequest, url string) bool {
+	return func(request *models.HTTPRequest, url string) bool {
+		terminalMu.Lock()
+		defer terminalMu.Unlock()
+
+		name := request.Name
+		if name == "" {
+			name = request.Method + " " + request.URL
+		}
+		fmt.Fprintf(os.Stderr, "%s will send DELETE %s. Run it? [y/N] ", name, url)
+		answer, err := terminalInput.ReadString('\n')
+		if err != nil {
+			return false
+		}
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		return answer == "y" || answer == "yes"
+	}
+}
+
+// isTerminal reports whether the file is an interactive terminal
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
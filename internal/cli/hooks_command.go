@@ -14,9 +14,10 @@ import (
 // setupHooksCmd sets up the hooks command and its subcommands
 func setupHooksCmd() *cobra.Command {
 	hooksCmd := &cobra.Command{
-		Use:   "hooks",
-		Short: "Manage Git hooks integration",
-		Long:  `Install, update, and manage Git hooks for automatic HTTP file generation`,
+		Use:     "hooks",
+		Aliases: []string{"hook"},
+		Short:   "Manage Git hooks integration",
+		Long:    `Install, update, and manage Git hooks for automatic HTTP file generation`,
 	}
 
 	// Add subcommands
@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/application"
+	"github.com/edgardnogueira/swagger-to-http/internal/application/lint"
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/extractor"
+	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/http"
+	"github.com/spf13/cobra"
+)
+
+// setupLintCmd creates the command checking HTTP files for likely mistakes
+func setupLintCmd(configProvider application.ConfigProvider, httpParser *http.Parser) *cobra.Command {
+	lintCmd := &cobra.Command{
+		Use:   "lint [files or directories]",
+		Short: "Check HTTP files for likely mistakes",
+		Long: `Check HTTP files without executing them for syntax errors, invalid headers,
+duplicate request names, requests without @name, variables that won't resolve,
+bodies without a Content-Type header and URLs without a scheme.
+
+Directories are searched recursively for .http files; without arguments the
+current directory is checked. The severity of each rule can be set with
+lint.severity in the configuration or --severity rule=severity, where the
+severity is error, warning, info or off. The command fails when an error is found.
+
+Use --json for machine-readable output.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			severityFlags, _ := cmd.Flags().GetStringSlice("severity")
+			jsonOutput, _ := cmd.Flags().GetBool("json")
+			varsPassphrase, _ := cmd.Flags().GetString("vars-passphrase")
+			varsKeyFile, _ := cmd.Flags().GetString("vars-key-file")
+
+			// Configured severities, overridden by the flags
+			var values []string
+			for rule, severity := range configProvider.GetStringMap("lint.severity") {
+				values = append(values, fmt.Sprintf("%s=%v", rule, severity))
+			}
+			sort.Strings(values)
+			severities, err := lint.ParseSeverities(append(values, severityFlags...))
+			if err != nil {
+				return newExitError(ExitConfigError, fmt.Errorf("invalid lint severity: %w", err))
+			}
+
+			if len(args) == 0 {
+				args = []string{"."}
+			}
+			paths, err := findHTTPFiles(args)
+			if err != nil {
+				return newExitError(ExitConfigError, err)
+			}
+
+			ctx := context.Background()
+			scopeService := extractor.NewVariableScopeService()
+			variableOptions := models.TestRunOptions{
+				VarsPassphrase: varsPassphrase,
+				VarsKeyFile:    varsKeyFile,
+			}
+
+			files := make([]lint.File, 0, len(paths))
+			for _, path := range paths {
+				file := lint.File{Path: path}
+				httpFile, err := httpParser.ParseFile(path)
+				var parseErrors models.ParseErrors
+				if errors.As(err, &parseErrors) {
+					file.ParseErrors = parseErrors
+				} else if err != nil {
+					return fmt.Errorf("failed to parse %s: %w", path, err)
+				} else {
+					file.Requests = httpFile.Requests
+				}
+
+				file.Variables, err = fileVariables(ctx, scopeService, path, variableOptions)
+				if err != nil {
+					return newExitError(ExitConfigError, fmt.Errorf("failed to resolve variables for %s: %w", path, err))
+				}
+				files = append(files, file)
+			}
+
+			diagnostics := lint.NewLinter(lint.WithSeverities(severities)).Lint(files)
+			if jsonOutput {
+				if diagnostics == nil {
+					diagnostics = []lint.Diagnostic{}
+				}
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+				if err := encoder.Encode(diagnostics); err != nil {
+					return fmt.Errorf("failed to write diagnostics: %w", err)
+				}
+			} else {
+				writeDiagnostics(cmd.OutOrStdout(), diagnostics, len(files))
+			}
+
+			for _, diagnostic := range diagnostics {
+				if diagnostic.Severity == lint.SeverityError {
+					return newExitError(ExitTestFailures, fmt.Errorf("lint found errors in HTTP files"))
+				}
+			}
+			return nil
+		},
+	}
+
+	lintCmd.Flags().StringSlice("severity", []string{}, "Severity of a rule as rule=severity, e.g. missing-name=off (repeatable)")
+	lintCmd.Flags().Bool("json", false, "Print the diagnostics as JSON")
+	lintCmd.Flags().String("vars-passphrase", "", "Passphrase for encrypted variable files (or SWAGGER_TO_HTTP_VARS_PASSPHRASE)")
+	lintCmd.Flags().String("vars-key-file", "", "Key file for encrypted variable files (or SWAGGER_TO_HTTP_VARS_KEY_FILE)")
+
+	return lintCmd
+}
+
+// fileVariables returns the variables the requests of an HTTP file can use,
+// by bare and namespaced names, with the precedence of the test runner:
+// variable files override HTTP_ environment variables
+func fileVariables(ctx context.Context, scopeService *extractor.VariableScopeService, path string, options models.TestRunOptions) (map[string]string, error) {
+	scoped, err := scopeService.ResolveVariables(ctx, path, options)
+	if err != nil {
+		return nil, err
+	}
+	var set models.VariableSet
+	set.Set(models.VariableNamespaceEnv, extractEnvironmentVars())
+	set.Set(models.VariableNamespaceFile, scoped)
+	return set.Variables(), nil
+}
+
+// writeDiagnostics prints the diagnostics one per line, as compilers do, and
+// a summary of them
+func writeDiagnostics(w io.Writer, diagnostics []lint.Diagnostic, files int) {
+	count := make(map[string]int)
+	for _, diagnostic := range diagnostics {
+		fmt.Fprintln(w, diagnostic)
+		count[diagnostic.Severity]++
+	}
+	fmt.Fprintf(w, "%d errors, %d warnings, %d notes in %d HTTP files\n",
+		count[lint.SeverityError], count[lint.SeverityWarning], count[lint.SeverityInfo], files)
+}
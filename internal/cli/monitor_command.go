@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/application"
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/monitor"
+	"github.com/spf13/cobra"
+)
+
+// setupMonitorCmd creates the command running tests on a schedule as a synthetic monitor
+func setupMonitorCmd(configProvider application.ConfigProvider, testRunner application.TestRunner) *cobra.Command {
+	monitorCmd := &cobra.Command{
+		Use:   "monitor [file-patterns]",
+		Short: "Run HTTP tests on a schedule and alert on failures",
+		Long: `Run HTTP tests on a schedule, keeping the health of each test between runs and
+notifying webhooks when tests start failing or recover.
+
+The schedule is "@every <duration>", a duration such as "5m", @hourly, @daily or a
+five field cron expression such as "*/10 * * * *". Slack incoming webhook URLs
+receive Slack-formatted messages; other webhooks receive a JSON list of events.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			scheduleSpec, _ := cmd.Flags().GetString("schedule")
+			stateFile, _ := cmd.Flags().GetString("state-file")
+			webhooks, _ := cmd.Flags().GetStringSlice("webhook")
+			slackWebhooks, _ := cmd.Flags().GetStringSlice("slack-webhook")
+			failureThreshold, _ := cmd.Flags().GetInt("failure-threshold")
+			ignoreHeaders, _ := cmd.Flags().GetString("ignore-headers")
+			snapshotDir, _ := cmd.Flags().GetString("snapshot-dir")
+			failOnMissing, _ := cmd.Flags().GetBool("fail-on-missing")
+			timeoutStr, _ := cmd.Flags().GetString("timeout")
+			tags, _ := cmd.Flags().GetStringSlice("tags")
+			methods, _ := cmd.Flags().GetStringSlice("methods")
+			names, _ := cmd.Flags().GetStringSlice("names")
+			controlAddr, _ := cmd.Flags().GetString("control-addr")
+			strictVars, _ := cmd.Flags().GetBool("strict-vars")
+
+			// Check the tag filter expressions before running anything
+			if err := checkTagFilters(tags); err != nil {
+				return err
+			}
+
+			schedule, err := monitor.ParseSchedule(scheduleSpec)
+			if err != nil {
+				return err
+			}
+
+			timeout, err := time.ParseDuration(timeoutStr)
+			if err != nil {
+				return fmt.Errorf("invalid timeout format: %w", err)
+			}
+
+			ignoreHeadersList, err := models.ParseHeaderPatterns(ignoreHeaders)
+			if err != nil {
+				return fmt.Errorf("invalid --ignore-headers: %w", err)
+			}
+
+			// Collect the webhooks from the flags and the configuration
+			var hooks []monitor.Webhook
+			for _, url := range append(webhooks, configProvider.GetStringSlice("monitor.webhooks")...) {
+				hooks = append(hooks, monitor.NewWebhook(url))
+			}
+			for _, url := range append(slackWebhooks, configProvider.GetStringSlice("monitor.slack_webhooks")...) {
+				hooks = append(hooks, monitor.Webhook{URL: url, Format: monitor.FormatSlack})
+			}
+
+			options := models.TestRunOptions{
+				UpdateSnapshots: "none",
+				FailOnMissing:   failOnMissing,
+				IgnoreHeaders:   ignoreHeadersList,
+				Timeout:         timeout,
+				Filter: models.TestFilter{
+					Tags:    tags,
+					Methods: methods,
+					Paths:   []string{snapshotDir},
+					Names:   names,
+				},
+				EnvironmentVars: extractEnvironmentVars(),
+				StrictVars:      strictVars,
+			}
+
+			// Run until interrupted
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			runner, controller, err := startControlServer(ctx, controlAddr, testRunner)
+			if err != nil {
+				return err
+			}
+
+			monitorOptions := []monitor.MonitorOption{
+				monitor.WithStateFile(stateFile),
+				monitor.WithFailureThreshold(failureThreshold),
+				monitor.WithAlerter(monitor.NewAlerter(hooks, 10*time.Second)),
+			}
+			if controller != nil {
+				monitorOptions = append(monitorOptions, monitor.WithTrigger(controller.Reruns()))
+			}
+			monitorService := monitor.NewMonitorService(runner, schedule, monitorOptions...)
+
+			fmt.Printf("Monitoring %s on schedule %q with %d webhooks. Press Ctrl+C to stop.\n",
+				strings.Join(args, ", "), scheduleSpec, len(hooks))
+			return monitorService.Run(ctx, args, options)
+		},
+	}
+
+	monitorCmd.Flags().String("schedule", configProvider.GetString("monitor.schedule"), "Run schedule: @every <duration>, a duration, @hourly, @daily or a cron expression")
+	monitorCmd.Flags().String("state-file", configProvider.GetString("monitor.state_file"), "File keeping the health of each test between runs")
+	monitorCmd.Flags().StringSlice("webhook", []string{}, "Webhook URL notified when tests start failing or recover")
+	monitorCmd.Flags().StringSlice("slack-webhook", []string{}, "Slack incoming webhook URL notified when tests start failing or recover")
+	monitorCmd.Flags().Int("failure-threshold", configProvider.GetInt("monitor.failure_threshold"), "Consecutive failures before a test is reported as failing")
+	monitorCmd.Flags().String("ignore-headers", "", "Comma-separated header patterns to ignore on top of snapshots.ignore_headers, \"!Name\" to compare one again")
+	monitorCmd.Flags().String("snapshot-dir", ".snapshots", "Directory for snapshot storage")
+	monitorCmd.Flags().Bool("fail-on-missing", false, "Fail when snapshot is missing")
+	monitorCmd.Flags().String("timeout", "30s", "HTTP request timeout")
+	monitorCmd.Flags().StringSlice("tags", []string{}, tagsFlagUsage)
+	monitorCmd.Flags().StringSlice("methods", []string{}, "Filter tests by HTTP methods")
+	monitorCmd.Flags().StringSlice("names", []string{}, "Filter tests by test names")
+	monitorCmd.Flags().String("control-addr", "", "Serve the control API on a local address or unix:<socket>")
+	monitorCmd.Flags().Bool("strict-vars", true, "Fail requests using variables without a value rather than sending the {{placeholders}} as written")
+
+	return monitorCmd
+}
@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/application/generator"
+	"github.com/edgardnogueira/swagger-to-http/internal/application/parser"
+	"github.com/edgardnogueira/swagger-to-http/internal/application/plan"
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/fs"
+	"github.com/edgardnogueira/swagger-to-http/internal/version"
+	"github.com/spf13/cobra"
+)
+
+// planFilenamePattern matches the characters replaced in plan file names
+var planFilenamePattern = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// setupPlanCmd creates the command generating a test plan for an operation
+func setupPlanCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plan [swagger-file]",
+		Short: "Generate requests combining the parameter values of an operation",
+		Long: `Generate a .http file with the requests of a test plan for an operation, chosen by
+operationId or as "METHOD /path": the generated request of the operation sent with
+combinations of its query and header parameters, so parameter interactions are
+covered without writing dozens of requests by hand.
+
+Enum parameters are tested with each of their values, booleans with true and false
+and other parameters with their example. Optional parameters are also left out.
+The pairwise strategy covers every pair of values of two parameters in few requests;
+the all strategy sends every combination.`,
+		Example: `  swagger-to-http plan api.yaml --operation listPets
+  swagger-to-http plan api.yaml --operation "GET /pets" --strategy all -o http-requests/plans`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			operation, _ := cmd.Flags().GetString("operation")
+			strategy, _ := cmd.Flags().GetString("strategy")
+			maxCases, _ := cmd.Flags().GetInt("max-cases")
+			output, _ := cmd.Flags().GetString("output")
+			base, _ := cmd.Flags().GetString("base-url")
+
+			ctx := context.Background()
+			doc, err := parser.NewSwaggerParser().ParseFile(ctx, args[0])
+			if err != nil {
+				return newExitError(ExitSpecError, fmt.Errorf("failed to parse spec: %w", err))
+			}
+
+			target, err := plan.FindOperation(doc, operation)
+			if err != nil {
+				return newExitError(ExitConfigError, err)
+			}
+			testPlan, err := plan.Build(target, plan.Options{Strategy: strategy, MaxCases: maxCases})
+			if err != nil {
+				return newExitError(ExitConfigError, err)
+			}
+
+			// Start from the request generate writes for the operation
+			collection, err := generator.NewHTTPGenerator(generator.WithBaseURL(base)).Generate(ctx, doc)
+			if err != nil {
+				return fmt.Errorf("failed to generate HTTP requests: %w", err)
+			}
+			request, ok := findGeneratedRequest(collection, target)
+			if !ok {
+				return fmt.Errorf("no request was generated for %s %s", target.Method, target.Path)
+			}
+
+			requests := make([]models.HTTPRequest, len(testPlan.Cases))
+			for i := range testPlan.Cases {
+				requests[i] = testPlan.Apply(request, i)
+			}
+
+			name := target.Operation.OperationID
+			if name == "" {
+				name = strings.ToLower(target.Method) + "_" + target.Path
+			}
+			file := &models.HTTPFile{
+				Filename:    strings.Trim(planFilenamePattern.ReplaceAllString(name, "_"), "_") + ".plan.http",
+				Requests:    requests,
+				ToolVersion: version.Version,
+				SpecHash:    doc.SpecHash,
+			}
+			if err := os.MkdirAll(output, 0755); err != nil {
+				return fmt.Errorf("failed to create directory %s: %w", output, err)
+			}
+			if err := fs.NewFileWriter().WriteFile(ctx, file, output); err != nil {
+				return err
+			}
+
+			fmt.Printf("Wrote %d requests covering %d parameters of %s %s to %s\n",
+				len(requests), len(testPlan.Factors), target.Method, target.Path, filepath.Join(output, file.Filename))
+			return nil
+		},
+	}
+
+	cmd.Flags().String("operation", "", "Operation to plan: an operationId or \"METHOD /path\" (required)")
+	cmd.Flags().String("strategy", plan.StrategyPairwise, "Combinations to generate: pairwise or all")
+	cmd.Flags().Int("max-cases", plan.DefaultMaxCases, "Fail rather than generate more requests than this")
+	cmd.Flags().StringP("output", "o", "plans", "Directory to write the plan file to")
+	cmd.Flags().StringP("base-url", "b", "", "Base URL for requests (overrides the one in the Swagger doc)")
+	cmd.MarkFlagRequired("operation")
+
+	return cmd
+}
+
+// findGeneratedRequest finds the request generated for an operation
+func findGeneratedRequest(collection *models.HTTPCollection, target plan.Target) (models.HTTPRequest, bool) {
+	files := collection.RootFiles
+	for _, dir := range collection.Directories {
+		files = append(files, dir.Files...)
+	}
+	for _, file := range files {
+		for _, request := range file.Requests {
+			if strings.EqualFold(request.Method, target.Method) && request.Path == target.Path {
+				return request, true
+			}
+		}
+	}
+	return models.HTTPRequest{}, false
+}
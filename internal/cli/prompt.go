@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// terminalInput reads the answers typed on the terminal, shared by all the
+// prompts of a run so they don't read ahead of each other
+var terminalInput = bufio.NewReader(os.Stdin)
+
+// terminalMu makes the prompts of parallel tests ask one at a time
+var terminalMu sync.Mutex
+
+// promptVariable asks on the terminal for the value of a missing variable,
+// without echoing secrets where stty is available
+func promptVariable(name string, secret bool) (string, error) {
+	terminalMu.Lock()
+	defer terminalMu.Unlock()
+
+	fmt.Fprintf(os.Stderr, "Value of %s: ", name)
+	if secret && stty("-echo") == nil {
+		defer func() {
+			_ = stty("echo")
+			fmt.Fprintln(os.Stderr)
+		}()
+	}
+
+	value, err := terminalInput.ReadString('\n')
+	if err != nil && value == "" {
+		return "", err
+	}
+	return strings.TrimRight(value, "\r\n"), nil
+}
+
+// stty changes the settings of the terminal of the standard input
+func stty(args ...string) error {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	return cmd.Run()
+}
@@ -8,39 +8,102 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var rootCmd = &cobra.Command{
-	Use:     "swagger-to-http",
-	Short:   "Convert Swagger/OpenAPI docs to HTTP request files",
-	Long:    `A tool to convert Swagger/OpenAPI documentation into organized HTTP request files with snapshot testing capabilities.`,
-	Version: version.Version,
-}
-
-// Execute runs the root command
-func Execute(
-	configProvider application.ConfigProvider, 
+// NewRootCmd builds the root command with the full command tree attached
+func NewRootCmd(
+	configProvider application.ConfigProvider,
 	httpParser *http.Parser,
 	httpExecutor application.HTTPExecutor,
 	testRunner application.TestRunner,
 	testReporter application.TestReporter,
 	advancedTestRunner *test.AdvancedTestRunnerService,
 	fileWriter application.FileWriter,
-) error {
+) *cobra.Command {
+	rootCmd := &cobra.Command{
+		Use:     "swagger-to-http",
+		Short:   "Convert Swagger/OpenAPI docs to HTTP request files",
+		Long:    `A tool to convert Swagger/OpenAPI documentation into organized HTTP request files with snapshot testing capabilities.`,
+		Version: version.Version,
+
+		// Suggest the closest command on typos
+		SuggestionsMinimumDistance: 2,
+
+		// The completion command is provided explicitly below
+		CompletionOptions: cobra.CompletionOptions{
+			DisableDefaultCmd: true,
+		},
+	}
+
+	// Invalid flags are configuration errors
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return newExitError(ExitConfigError, err)
+	})
+
+	// Add general commands
+	rootCmd.AddCommand(setupVersionCmd())
+	rootCmd.AddCommand(setupGenerateCmd())
+	rootCmd.AddCommand(setupPlanCmd())
+
 	// Add snapshot commands
-	InitSnapshotCommands(rootCmd, configProvider, httpExecutor)
-	
+	AddSnapshotCommands(rootCmd, configProvider)
+
 	// Add test commands
 	AddTestCommands(rootCmd, configProvider, testRunner, testReporter)
-	
+
 	// Add advanced test commands
 	AddAdvancedTestCommands(rootCmd, configProvider, advancedTestRunner, testReporter)
-	
+
+	// Add environment comparison command
+	rootCmd.AddCommand(setupCompareEnvsCmd(configProvider, testRunner, testReporter))
+
+	// Add monitoring command
+	rootCmd.AddCommand(setupMonitorCmd(configProvider, testRunner))
+
 	// Add hooks commands
 	rootCmd.AddCommand(setupHooksCmd())
 
-	return rootCmd.Execute()
+	// Add variable file commands
+	rootCmd.AddCommand(setupVarsCmd())
+
+	// Add editor integration commands
+	rootCmd.AddCommand(setupExplainCmd(httpParser))
+	rootCmd.AddCommand(setupExportCmd(configProvider))
+	rootCmd.AddCommand(setupFmtCmd(httpParser))
+	rootCmd.AddCommand(setupLintCmd(configProvider, httpParser))
+
+	// Add spec maintenance commands
+	rootCmd.AddCommand(setupExamplesCmd())
+	rootCmd.AddCommand(setupScaffoldCmd())
+	rootCmd.AddCommand(setupCodegenCmd())
+
+	// Add XML API commands
+	rootCmd.AddCommand(setupSoapCmd())
+
+	// Add completion and documentation commands
+	rootCmd.AddCommand(setupCompletionCmd())
+	rootCmd.AddCommand(setupGenDocsCmd())
+
+	return rootCmd
 }
 
-func init() {
-	// Add flags, subcommands, etc. here
-	rootCmd.AddCommand(versionCmd)
+// Execute runs the root command
+func Execute(
+	configProvider application.ConfigProvider,
+	httpParser *http.Parser,
+	httpExecutor application.HTTPExecutor,
+	testRunner application.TestRunner,
+	testReporter application.TestReporter,
+	advancedTestRunner *test.AdvancedTestRunnerService,
+	fileWriter application.FileWriter,
+) error {
+	rootCmd := NewRootCmd(
+		configProvider,
+		httpParser,
+		httpExecutor,
+		testRunner,
+		testReporter,
+		advancedTestRunner,
+		fileWriter,
+	)
+
+	return rootCmd.Execute()
 }
@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/application/scaffold"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// setupScaffoldCmd creates the command drafting a spec from recorded traffic
+func setupScaffoldCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "scaffold [recordings]",
+		Short: "Draft an OpenAPI document from recorded traffic",
+		Long: `Draft an OpenAPI 3 document from recorded requests and responses: HAR files exported
+by browsers and proxies (.har) or JSON test reports (test --report-format json).
+
+Paths, methods, path and query parameters and the schemas of JSON bodies are inferred
+from the recordings. Path segments that look like identifiers (numbers, UUIDs, long
+hexadecimal strings) become path parameters. The result is a starting point to review
+and complete, not a finished spec.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			output, _ := cmd.Flags().GetString("output")
+			title, _ := cmd.Flags().GetString("title")
+
+			var exchanges []scaffold.Exchange
+			for _, file := range args {
+				data, err := os.ReadFile(file)
+				if err != nil {
+					return newExitError(ExitConfigError, fmt.Errorf("failed to read %s: %w", file, err))
+				}
+
+				if scaffold.IsHAR(data) {
+					fileExchanges, err := scaffold.ParseHAR(data)
+					if err != nil {
+						return newExitError(ExitConfigError, fmt.Errorf("%s: %w", file, err))
+					}
+					exchanges = append(exchanges, fileExchanges...)
+					continue
+				}
+
+				results, err := loadRecordedResults(file)
+				if err != nil {
+					return newExitError(ExitConfigError, err)
+				}
+				exchanges = append(exchanges, scaffold.FromResults(results)...)
+			}
+
+			if len(exchanges) == 0 {
+				return newExitError(ExitConfigError, fmt.Errorf("no recorded requests found"))
+			}
+
+			doc, err := scaffold.Scaffold(exchanges, title)
+			if err != nil {
+				return err
+			}
+
+			var data []byte
+			if strings.EqualFold(filepath.Ext(output), ".json") {
+				data, err = json.MarshalIndent(doc, "", "  ")
+				data = append(data, '\n')
+			} else {
+				data, err = yaml.Marshal(doc)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to encode document: %w", err)
+			}
+
+			if err := os.WriteFile(output, data, 0644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", output, err)
+			}
+
+			fmt.Printf("Scaffolded %d paths from %d requests into %s\n", len(doc.Paths), len(exchanges), output)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("output", "o", "openapi.yaml", "File to write the document to (.json for JSON, YAML otherwise)")
+	cmd.Flags().String("title", "Scaffolded API", "Title of the document")
+
+	return cmd
+}
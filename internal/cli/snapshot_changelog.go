@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/application/changelog"
+	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/snapstore"
+)
+
+// gitRevision reads the files of a git revision, decompressing them
+type gitRevision struct {
+	ref string
+}
+
+// Read returns the content of a file, relative to the current directory, at
+// the revision
+func (g gitRevision) Read(path string) ([]byte, error) {
+	data, err := git("show", g.ref+":./"+filepath.ToSlash(path))
+	if err != nil {
+		return nil, err
+	}
+	return snapstore.Decompress(data)
+}
+
+// git runs a git command, returning its output or an error with what it
+// printed
+func git(args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], message)
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return out, nil
+}
+
+// snapshotChangelog writes the changelog of the responses of the snapshots of
+// a directory between the git ref since and the ref until, or the working
+// tree when until is empty
+func snapshotChangelog(w io.Writer, snapshotDir, since, until, format string) error {
+	if format != "markdown" && format != "json" {
+		return newExitError(ExitConfigError, fmt.Errorf("invalid format %q: use markdown or json", format))
+	}
+
+	args := []string{"diff", "--name-status", "--no-renames", "--relative", since}
+	if until != "" {
+		args = append(args, until)
+	}
+	out, err := git(append(args, "--", snapshotDir)...)
+	if err != nil {
+		return fmt.Errorf("failed to diff snapshots since %s: %w", since, err)
+	}
+
+	var current snapstore.Reader = snapstore.NewCompressed(snapstore.Local{}, snapstore.CompressionNone)
+	if until != "" {
+		current = gitRevision{ref: until}
+	}
+	old := gitRevision{ref: since}
+
+	var changes []*changelog.Change
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		status, path, ok := strings.Cut(scanner.Text(), "\t")
+		if !ok || isSnapshotBlob(path) {
+			continue
+		}
+
+		var before, after *changelog.Snapshot
+		if status != "A" {
+			if before, err = readChangelogSnapshot(old, path); err != nil {
+				return err
+			}
+		}
+		if status != "D" {
+			if after, err = readChangelogSnapshot(current, path); err != nil {
+				return err
+			}
+		}
+		if change := changelog.Compare(path, before, after); change != nil {
+			changes = append(changes, change)
+		}
+	}
+
+	if format == "json" {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(changes)
+	}
+	title := "API changes since " + since
+	if until != "" {
+		title += " up to " + until
+	}
+	changelog.Write(w, title, changes)
+	return nil
+}
+
+// readChangelogSnapshot reads a snapshot with the body it references
+func readChangelogSnapshot(reader snapstore.Reader, path string) (*changelog.Snapshot, error) {
+	data, err := reader.Read(path)
+	if err == nil {
+		data, err = snapstore.LoadBody(reader, path, data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot %s: %w", path, err)
+	}
+	return changelog.Parse(string(data)), nil
+}
+
+// isSnapshotBlob reports whether a path is a deduplicated body, compared
+// through the snapshots referencing it
+func isSnapshotBlob(path string) bool {
+	for _, part := range strings.Split(filepath.ToSlash(path), "/") {
+		if part == snapstore.BlobDir {
+			return true
+		}
+	}
+	return false
+}
@@ -2,7 +2,9 @@ package cli
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
@@ -10,8 +12,11 @@ import (
 
 	"github.com/edgardnogueira/swagger-to-http/internal/application"
 	"github.com/edgardnogueira/swagger-to-http/internal/application/snapshot"
+	"github.com/edgardnogueira/swagger-to-http/internal/application/snapstats"
 	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
 	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/http"
+	infsnapshot "github.com/edgardnogueira/swagger-to-http/internal/infrastructure/snapshot"
+	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/snapstore"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
@@ -20,9 +25,10 @@ import (
 func AddSnapshotCommands(rootCmd *cobra.Command, configProvider application.ConfigProvider) {
 	// Base snapshot command
 	snapshotCmd := &cobra.Command{
-		Use:   "snapshot",
-		Short: "Snapshot testing commands",
-		Long:  "Commands for working with HTTP response snapshots",
+		Use:     "snapshot",
+		Aliases: []string{"snap", "snapshots"},
+		Short:   "Snapshot testing commands",
+		Long:    "Commands for working with HTTP response snapshots",
 	}
 	
 	// Snapshot test command
@@ -62,17 +68,18 @@ func AddSnapshotCommands(rootCmd *cobra.Command, configProvider application.Conf
 				pattern = args[0]
 			}
 			
-			return runSnapshotTests(cmd, pattern, options, failOnMissing, cleanup, timeout)
+			return runSnapshotTests(cmd, pattern, options, failOnMissing, cleanup, timeout, mutationGuard(cmd, configProvider))
 		},
 	}
 	
 	// Add flags to test command
 	testCmd.Flags().String("update", "none", "Update mode: none, all, failed, missing")
-	testCmd.Flags().String("ignore-headers", "Date,Set-Cookie", "Comma-separated headers to ignore in comparison")
+	testCmd.Flags().String("ignore-headers", "", "Comma-separated header patterns to ignore on top of snapshots.ignore_headers, \"!Name\" to compare one again")
 	testCmd.Flags().String("snapshot-dir", ".snapshots", "Directory for snapshot storage")
 	testCmd.Flags().Bool("fail-on-missing", false, "Fail when snapshot is missing")
 	testCmd.Flags().Bool("cleanup", false, "Remove unused snapshots after testing")
 	testCmd.Flags().Duration("timeout", 30*time.Second, "HTTP request timeout")
+	addGuardFlags(testCmd, configProvider)
 	
 	// Snapshot update command
 	updateCmd := &cobra.Command{
@@ -97,13 +104,14 @@ func AddSnapshotCommands(rootCmd *cobra.Command, configProvider application.Conf
 				pattern = args[0]
 			}
 			
-			return runSnapshotTests(cmd, pattern, options, false, false, timeout)
+			return runSnapshotTests(cmd, pattern, options, false, false, timeout, mutationGuard(cmd, configProvider))
 		},
 	}
 	
 	// Add flags to update command
 	updateCmd.Flags().String("snapshot-dir", ".snapshots", "Directory for snapshot storage")
 	updateCmd.Flags().Duration("timeout", 30*time.Second, "HTTP request timeout")
+	addGuardFlags(updateCmd, configProvider)
 	
 	// Snapshot list command
 	listCmd := &cobra.Command{
@@ -149,20 +157,116 @@ func AddSnapshotCommands(rootCmd *cobra.Command, configProvider application.Conf
 	// Add flags to cleanup command
 	cleanupCmd.Flags().String("snapshot-dir", ".snapshots", "Directory for snapshot storage")
 	
+	// Snapshot migrate command
+	migrateCmd := &cobra.Command{
+		Use:   "migrate [directory]",
+		Short: "Migrate snapshots",
+		Long:  "Rewrite stored snapshots with the configured compression, compressing or decompressing them",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			snapshotDir, _ := cmd.Flags().GetString("snapshot-dir")
+			compressionFlag, _ := cmd.Flags().GetString("compression")
+			
+			compression, err := snapstore.ParseCompression(compressionFlag)
+			if err != nil {
+				return newExitError(ExitConfigError, err)
+			}
+			
+			// Determine directory
+			dir := snapshotDir
+			if len(args) > 0 {
+				dir = filepath.Join(snapshotDir, args[0])
+			}
+			
+			return migrateSnapshots(configProvider.GetString("snapshots.store"), dir, compression)
+		},
+	}
+	
+	// Add flags to migrate command
+	migrateCmd.Flags().String("snapshot-dir", ".snapshots", "Directory for snapshot storage")
+	migrateCmd.Flags().String("compression", configProvider.GetString("snapshots.compression"), "Compression to rewrite snapshots with: none or gzip")
+	
+	// Snapshot stats command
+	statsCmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show snapshot statistics",
+		Long:  "Report the count, total size, largest snapshots, age distribution and per-tag breakdown of the stored snapshots",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			snapshotDir, _ := cmd.Flags().GetString("snapshot-dir")
+			top, _ := cmd.Flags().GetInt("top")
+			format, _ := cmd.Flags().GetString("format")
+			
+			return showSnapshotStats(snapshotDir, top, format)
+		},
+	}
+	
+	// Add flags to stats command
+	statsCmd.Flags().String("snapshot-dir", ".snapshots", "Directory for snapshot storage")
+	statsCmd.Flags().Int("top", 10, "Number of largest snapshots to list")
+	statsCmd.Flags().String("format", "text", "Output format: text or json")
+	
+	// Snapshot verify command
+	verifyCmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify stored snapshots",
+		Long:  "Check that all snapshot files parse, carry valid metadata and map to requests of the .http files, failing on corrupt or orphaned snapshots; meant for pre-commit hooks and CI",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			snapshotDir, _ := cmd.Flags().GetString("snapshot-dir")
+			httpDir, _ := cmd.Flags().GetString("dir")
+			jsonOutput, _ := cmd.Flags().GetBool("json")
+			
+			return verifySnapshots(cmd.OutOrStdout(), snapshotDir, httpDir, jsonOutput)
+		},
+	}
+	
+	// Add flags to verify command
+	verifyCmd.Flags().String("snapshot-dir", ".snapshots", "Directory for snapshot storage")
+	verifyCmd.Flags().String("dir", ".", "Directory of the .http files snapshots must map to, empty to skip the check for orphans")
+	verifyCmd.Flags().Bool("json", false, "Print the problems as JSON")
+	
+	// Snapshot changelog command
+	changelogCmd := &cobra.Command{
+		Use:   "changelog",
+		Short: "Describe API behavior changes",
+		Long:  "Describe which endpoints' responses changed between git refs, from the git history of the snapshot directory",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			snapshotDir, _ := cmd.Flags().GetString("snapshot-dir")
+			since, _ := cmd.Flags().GetString("since")
+			until, _ := cmd.Flags().GetString("until")
+			format, _ := cmd.Flags().GetString("format")
+			
+			return snapshotChangelog(os.Stdout, snapshotDir, since, until, format)
+		},
+	}
+	
+	// Add flags to changelog command
+	changelogCmd.Flags().String("snapshot-dir", ".snapshots", "Directory for snapshot storage")
+	changelogCmd.Flags().String("since", "", "Git ref to describe the changes since, e.g. the last release tag")
+	changelogCmd.Flags().String("until", "", "Git ref to describe the changes up to (default the working tree)")
+	changelogCmd.Flags().String("format", "markdown", "Output format: markdown or json")
+	changelogCmd.MarkFlagRequired("since")
+	
 	// Add commands to snapshot command
 	snapshotCmd.AddCommand(testCmd)
 	snapshotCmd.AddCommand(updateCmd)
 	snapshotCmd.AddCommand(listCmd)
 	snapshotCmd.AddCommand(cleanupCmd)
+	snapshotCmd.AddCommand(migrateCmd)
+	snapshotCmd.AddCommand(statsCmd)
+	snapshotCmd.AddCommand(changelogCmd)
+	snapshotCmd.AddCommand(verifyCmd)
 	
 	// Add snapshot command to root
 	rootCmd.AddCommand(snapshotCmd)
 }
 
 // runSnapshotTests runs snapshot tests for the given file pattern
-func runSnapshotTests(cmd *cobra.Command, pattern string, options models.SnapshotOptions, failOnMissing, cleanup bool, timeout time.Duration) error {
+func runSnapshotTests(cmd *cobra.Command, pattern string, options models.SnapshotOptions, failOnMissing, cleanup bool, timeout time.Duration, guard models.MutationGuard) error {
 	// Create snapshot manager and service
-	manager := snapshot.NewManager(options.BasePath)
+	manager := infsnapshot.NewSnapshotManager()
 	service := snapshot.NewService(manager, options)
 	
 	// Create HTTP parser
@@ -202,6 +306,12 @@ func runSnapshotTests(cmd *cobra.Command, pattern string, options models.Snapsho
 		for i, request := range httpFile.Requests {
 			fmt.Printf("  Request %d: %s %s\n", i+1, request.Method, request.Path)
 			
+			// Don't change data on shared environments unless allowed
+			if err := application.CheckMutation(&request, env, guard); err != nil {
+				fmt.Printf("    %s Skipped: %s\n", color.YellowString("-"), err)
+				continue
+			}
+
 			// Execute request
 			response, err := executor.Execute(context.Background(), &request, nil)
 			if err != nil {
@@ -228,7 +338,7 @@ func runSnapshotTests(cmd *cobra.Command, pattern string, options models.Snapsho
 					RequestPath:   request.Path,
 					RequestMethod: request.Method,
 					Passed:        false,
-					Error:         err,
+					Error:         err.Error(),
 				})
 				continue
 			}
@@ -243,45 +353,52 @@ func runSnapshotTests(cmd *cobra.Command, pattern string, options models.Snapsho
 				fmt.Printf("    %s Snapshot comparison failed\n", color.RedString("✗"))
 				
 				// Print diff details
-				if result.Diff != nil && result.Diff.StatusDiff != nil && !result.Diff.StatusDiff.Equal {
+				if result.Diff != nil && result.Diff.StatusDiffExt != nil && !result.Diff.StatusDiffExt.Equal {
 					fmt.Printf("      Status code: expected %d, got %d\n", 
-						result.Diff.StatusDiff.Expected, 
-						result.Diff.StatusDiff.Actual)
+						result.Diff.StatusDiffExt.Expected, 
+						result.Diff.StatusDiffExt.Actual)
 				}
 				
-				if result.Diff != nil && result.Diff.HeaderDiff != nil && !result.Diff.HeaderDiff.Equal {
+				if result.Diff != nil && result.Diff.HeaderDiffExt != nil && !result.Diff.HeaderDiffExt.Equal {
 					fmt.Println("      Headers differ:")
-					if len(result.Diff.HeaderDiff.MissingHeaders) > 0 {
+					if len(result.Diff.HeaderDiffExt.MissingHeaders) > 0 {
 						fmt.Println("        Missing headers:")
-						for h := range result.Diff.HeaderDiff.MissingHeaders {
+						for h := range result.Diff.HeaderDiffExt.MissingHeaders {
 							fmt.Printf("          - %s\n", h)
 						}
 					}
-					if len(result.Diff.HeaderDiff.ExtraHeaders) > 0 {
+					if len(result.Diff.HeaderDiffExt.ExtraHeaders) > 0 {
 						fmt.Println("        Extra headers:")
-						for h := range result.Diff.HeaderDiff.ExtraHeaders {
+						for h := range result.Diff.HeaderDiffExt.ExtraHeaders {
 							fmt.Printf("          + %s\n", h)
 						}
 					}
 				}
 				
-				if result.Diff != nil && result.Diff.BodyDiff != nil && !result.Diff.BodyDiff.Equal {
+				diffContent := ""
+				if result.Diff != nil {
+					diffContent = result.Diff.DiffString
+				}
+				if result.Diff != nil && result.Diff.BodyDiffExt != nil && !result.Diff.BodyDiffExt.Equal {
 					fmt.Printf("      Body content differs (expected %d bytes, got %d bytes)\n", 
-						result.Diff.BodyDiff.ExpectedSize, 
-						result.Diff.BodyDiff.ActualSize)
+						result.Diff.BodyDiffExt.ExpectedSize, 
+						result.Diff.BodyDiffExt.ActualSize)
+					if result.Diff.BodyDiffExt.DiffContent != "" {
+						diffContent = result.Diff.BodyDiffExt.DiffContent
+					}
+				}
 					
-					// Print diff preview if available
-					if result.Diff.BodyDiff.DiffContent != "" {
-						fmt.Println("      Diff preview:")
-						lines := strings.Split(result.Diff.BodyDiff.DiffContent, "\n")
-						maxLines := 10
-						if len(lines) > maxLines {
-							lines = lines[:maxLines]
-							fmt.Printf("        %s\n        ...(truncated)...\n", 
-								strings.Join(lines, "\n        "))
-						} else {
-							fmt.Printf("        %s\n", strings.Join(lines, "\n        "))
-						}
+				// Print diff preview if available
+				if diffContent != "" {
+					fmt.Println("      Diff preview:")
+					lines := strings.Split(diffContent, "\n")
+					maxLines := 10
+					if len(lines) > maxLines {
+						lines = lines[:maxLines]
+						fmt.Printf("        %s\n        ...(truncated)...\n", 
+							strings.Join(lines, "\n        "))
+					} else {
+						fmt.Printf("        %s\n", strings.Join(lines, "\n        "))
 					}
 				}
 			}
@@ -306,19 +423,36 @@ func runSnapshotTests(cmd *cobra.Command, pattern string, options models.Snapsho
 	
 	// Return error if any tests failed
 	if stats.Failed > 0 {
-		return fmt.Errorf("%d of %d tests failed", stats.Failed, stats.Total)
+		return newExitError(ExitTestFailures, fmt.Errorf("%d of %d tests failed", stats.Failed, stats.Total))
 	}
 	
 	return nil
 }
 
+// listSnapshotFiles returns the snapshots of a directory of the base path,
+// relative to the base path
+func listSnapshotFiles(basePath, directory string) ([]string, error) {
+	manager := infsnapshot.NewSnapshotManager()
+	paths, err := manager.ListSnapshots(filepath.Join(basePath, directory))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	
+	snapshots := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if rel, err := filepath.Rel(basePath, path); err == nil {
+			path = rel
+		}
+		snapshots = append(snapshots, path)
+	}
+	return snapshots, nil
+}
+
 // listSnapshots lists the snapshots in a directory
 func listSnapshots(cmd *cobra.Command, basePath, directory string) error {
-	manager := snapshot.NewManager(basePath)
-	
-	snapshots, err := manager.ListSnapshots(context.Background(), directory)
+	snapshots, err := listSnapshotFiles(basePath, directory)
 	if err != nil {
-		return fmt.Errorf("failed to list snapshots: %w", err)
+		return err
 	}
 	
 	if len(snapshots) == 0 {
@@ -336,20 +470,18 @@ func listSnapshots(cmd *cobra.Command, basePath, directory string) error {
 
 // cleanupSnapshots removes orphaned snapshots
 func cleanupSnapshots(cmd *cobra.Command, basePath, directory string) error {
-	manager := snapshot.NewManager(basePath)
-	
 	// Create HTTP parser to find valid HTTP files
 	parser := http.NewParser()
 	
 	// List all snapshots
-	snapshots, err := manager.ListSnapshots(context.Background(), directory)
+	snapshots, err := listSnapshotFiles(basePath, directory)
 	if err != nil {
-		return fmt.Errorf("failed to list snapshots: %w", err)
+		return err
 	}
 	
 	if len(snapshots) == 0 {
 		fmt.Println("No snapshots found")
-		return nil
+		return collectSnapshotBodies(basePath)
 	}
 	
 	fmt.Printf("Found %d snapshots\n", len(snapshots))
@@ -396,7 +528,7 @@ func cleanupSnapshots(cmd *cobra.Command, basePath, directory string) error {
 	
 	if len(orphaned) == 0 {
 		fmt.Println("No orphaned snapshots found")
-		return nil
+		return collectSnapshotBodies(basePath)
 	}
 	
 	fmt.Printf("Found %d orphaned snapshots:\n", len(orphaned))
@@ -426,6 +558,77 @@ func cleanupSnapshots(cmd *cobra.Command, basePath, directory string) error {
 	}
 	
 	fmt.Printf("Deleted %d orphaned snapshots\n", deleted)
+	return collectSnapshotBodies(basePath)
+}
+
+// migrateSnapshots rewrites the snapshots of a directory with a compression
+func migrateSnapshots(location, dir string, compression snapstore.Compression) error {
+	store, err := snapstore.Open(location)
+	if err != nil {
+		return newExitError(ExitConfigError, err)
+	}
+	
+	migrated, err := snapstore.Migrate(store, dir, compression)
+	for _, path := range migrated {
+		fmt.Printf("  %s\n", path)
+	}
+	if err != nil {
+		return err
+	}
+	
+	fmt.Printf("Migrated %d snapshots to %s compression\n", len(migrated), compression)
+	return nil
+}
+
+// showSnapshotStats prints the statistics of the snapshots stored in a
+// directory, leaving out the deduplicated bodies they share
+func showSnapshotStats(snapshotDir string, top int, format string) error {
+	if format != "text" && format != "json" {
+		return newExitError(ExitConfigError, fmt.Errorf("invalid format %q: use text or json", format))
+	}
+	
+	var files []snapstats.File
+	err := filepath.WalkDir(snapshotDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if entry.Name() == snapstore.BlobDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		files = append(files, snapstats.File{Path: path, Size: info.Size(), ModTime: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list snapshots in %s: %w", snapshotDir, err)
+	}
+	
+	stats := snapstats.Compute(snapshotDir, files, time.Now(), top)
+	if format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(stats)
+	}
+	snapstats.Write(os.Stdout, stats)
+	return nil
+}
+
+// collectSnapshotBodies removes the deduplicated bodies no snapshot references
+// any more
+func collectSnapshotBodies(basePath string) error {
+	removed, err := snapstore.CollectBlobs(snapstore.NewCompressed(snapstore.Local{}, snapstore.CompressionNone), basePath)
+	if err != nil {
+		return err
+	}
+	if len(removed) > 0 {
+		fmt.Printf("Deleted %d unreferenced snapshot bodies\n", len(removed))
+	}
 	return nil
 }
 
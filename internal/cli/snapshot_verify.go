@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/application"
+	"github.com/edgardnogueira/swagger-to-http/internal/application/snapverify"
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/http"
+	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/snapstore"
+)
+
+// verifySnapshots checks the snapshots of a directory, and that they belong
+// to the requests of the .http files of httpDir unless it is empty, failing
+// on any problem
+func verifySnapshots(w io.Writer, snapshotDir, httpDir string, jsonOutput bool) error {
+	store := snapstore.NewCompressed(snapstore.Local{}, snapstore.CompressionNone)
+
+	var snapshots []snapverify.Snapshot
+	err := filepath.WalkDir(snapshotDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if entry.Name() == snapstore.BlobDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		data, err := store.Read(path)
+		if err == nil {
+			data, err = snapstore.LoadBody(store, path, data)
+		}
+		snapshots = append(snapshots, snapverify.Snapshot{Path: path, Content: data, Err: err})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list snapshots in %s: %w", snapshotDir, err)
+	}
+
+	var expected map[string]bool
+	if httpDir != "" {
+		files, err := http.NewParser().ParseDirectory(httpDir)
+		if err != nil {
+			return fmt.Errorf("failed to parse HTTP files: %w", err)
+		}
+		expected = make(map[string]bool)
+		for _, file := range files {
+			for _, request := range file.Requests {
+				expected[application.SnapshotPath(snapshotDir, &request, models.TestRunOptions{})] = true
+			}
+		}
+	}
+
+	report := snapverify.Verify(snapshots, expected)
+	if jsonOutput {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		for _, problem := range report.Problems {
+			fmt.Fprintf(w, "%s %s\n  %s\n", strings.ToUpper(problem.Kind), problem.Path, problem.Message)
+		}
+		fmt.Fprintf(w, "\n%d snapshots checked, %d problems\n", report.Checked, len(report.Problems))
+	}
+
+	if len(report.Problems) > 0 {
+		return newExitError(ExitTestFailures, fmt.Errorf("%d of %d snapshots are corrupt, incompatible or orphaned", len(report.Problems), report.Checked))
+	}
+	return nil
+}
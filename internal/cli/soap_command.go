@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/application/soap"
+	"github.com/spf13/cobra"
+)
+
+// setupSoapCmd sets up the soap command and its subcommands
+func setupSoapCmd() *cobra.Command {
+	soapCmd := &cobra.Command{
+		Use:   "soap",
+		Short: "Generate and validate requests to SOAP/XML services",
+	}
+
+	soapCmd.AddCommand(setupSoapGenerateCmd())
+	soapCmd.AddCommand(setupSoapValidateCmd())
+
+	return soapCmd
+}
+
+// setupSoapGenerateCmd creates the command generating .http requests from SOAP operations
+func setupSoapGenerateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "generate [operations-file]",
+		Short: "Generate SOAP requests from an operations file",
+		Long: `Generate a .http file with a request per operation of a SOAP service described in a
+YAML operations file:
+
+  endpoint: "{{baseUrl}}/soap/orders"
+  namespace: http://example.com/orders
+  version: "1.1"          # or "1.2"
+  schema: orders.xsd      # optional XSD or WSDL the responses are validated against
+  operations:
+    - name: GetOrder
+      action: http://example.com/orders/GetOrder   # default: <namespace>/<name>
+      params: [orderId]
+
+Each request posts an envelope template with a {{param}} variable per parameter and
+the SOAPAction header (SOAP 1.1) or action content type parameter (SOAP 1.2).`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			output, _ := cmd.Flags().GetString("output")
+			if output == "" {
+				output = strings.TrimSuffix(args[0], filepath.Ext(args[0])) + ".http"
+			}
+
+			service, err := soap.LoadService(args[0])
+			if err != nil {
+				return newExitError(ExitConfigError, err)
+			}
+
+			file, err := os.Create(output)
+			if err != nil {
+				return fmt.Errorf("failed to create %s: %w", output, err)
+			}
+			defer file.Close()
+
+			if err := service.WriteHTTP(file); err != nil {
+				return err
+			}
+
+			fmt.Printf("Generated %d SOAP requests into %s\n", len(service.Operations), output)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("output", "o", "", "File to write the requests to (default: <operations-file>.http)")
+
+	return cmd
+}
+
+// setupSoapValidateCmd creates the command validating XML documents against an XSD
+func setupSoapValidateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate [xml-files]",
+		Short: "Validate XML documents or SOAP envelopes against an XSD",
+		Long: `Validate XML documents against an XSD, or the schemas in the types of a WSDL. The
+payload of SOAP envelopes is validated and SOAP faults are reported as problems.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			schemaFile, _ := cmd.Flags().GetString("schema")
+
+			schema, err := soap.LoadSchema(schemaFile)
+			if err != nil {
+				return newExitError(ExitSpecError, err)
+			}
+
+			invalid := 0
+			for _, file := range args {
+				data, err := os.ReadFile(file)
+				if err != nil {
+					return newExitError(ExitConfigError, fmt.Errorf("failed to read %s: %w", file, err))
+				}
+
+				problems, err := schema.Validate(data)
+				if err != nil {
+					problems = []string{err.Error()}
+				}
+				if len(problems) == 0 {
+					fmt.Printf("%s: valid\n", file)
+					continue
+				}
+				invalid++
+				fmt.Printf("%s: %d problems\n", file, len(problems))
+				for _, problem := range problems {
+					fmt.Printf("  %s\n", problem)
+				}
+			}
+
+			if invalid > 0 {
+				return newExitError(ExitTestFailures, fmt.Errorf("%d of %d documents are invalid", invalid, len(args)))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().String("schema", "", "XSD or WSDL file to validate against (required)")
+	cmd.MarkFlagRequired("schema")
+
+	return cmd
+}
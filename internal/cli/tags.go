@@ -0,0 +1,20 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// tagsFlagUsage is the usage of the --tags flags filtering tests
+const tagsFlagUsage = `Filter tests by tag expressions, e.g. "smoke and not slow"; tests matching any are run`
+
+// checkTagFilters checks the tag expressions of a --tags flag
+func checkTagFilters(tags []string) error {
+	for _, tag := range tags {
+		if _, err := models.ParseTagExpression(tag); err != nil {
+			return newExitError(ExitConfigError, fmt.Errorf("invalid --tags: %w", err))
+		}
+	}
+	return nil
+}
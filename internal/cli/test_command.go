@@ -4,13 +4,16 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/edgardnogueira/swagger-to-http/internal/application"
+	"github.com/edgardnogueira/swagger-to-http/internal/application/parser"
 	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/control"
+	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/extractor"
 	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/reporter"
+	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/snapstore"
 	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/watcher"
 	"github.com/spf13/cobra"
 )
@@ -31,8 +34,8 @@ func AddTestCommands(rootCmd *cobra.Command, configProvider application.ConfigPr
 			ignoreHeaders, _ := cmd.Flags().GetString("ignore-headers")
 			snapshotDir, _ := cmd.Flags().GetString("snapshot-dir")
 			failOnMissing, _ := cmd.Flags().GetBool("fail-on-missing")
-			cleanup, _ := cmd.Flags().GetBool("cleanup")
 			timeoutStr, _ := cmd.Flags().GetString("timeout")
+			runTimeoutStr, _ := cmd.Flags().GetString("run-timeout")
 			parallel, _ := cmd.Flags().GetBool("parallel")
 			maxConcurrent, _ := cmd.Flags().GetInt("max-concurrent")
 			stopOnFailure, _ := cmd.Flags().GetBool("stop-on-failure")
@@ -40,29 +43,86 @@ func AddTestCommands(rootCmd *cobra.Command, configProvider application.ConfigPr
 			methods, _ := cmd.Flags().GetStringSlice("methods")
 			paths, _ := cmd.Flags().GetStringSlice("paths")
 			names, _ := cmd.Flags().GetStringSlice("names")
+			scopes, _ := cmd.Flags().GetStringSlice("scopes")
 			reportFormat, _ := cmd.Flags().GetString("report-format")
 			reportOutput, _ := cmd.Flags().GetString("report-output")
 			detailed, _ := cmd.Flags().GetBool("detailed")
+			inlineMaxBytes, _ := cmd.Flags().GetInt("inline-max-bytes")
+			followRedirects, _ := cmd.Flags().GetBool("follow-redirects")
+			maxRedirects, _ := cmd.Flags().GetInt("max-redirects")
+			retries, _ := cmd.Flags().GetInt("retries")
+			seed, _ := cmd.Flags().GetInt64("seed")
+			orderFlag, _ := cmd.Flags().GetString("order")
+			focus, _ := cmd.Flags().GetStringSlice("focus")
 			watch, _ := cmd.Flags().GetBool("watch")
 			watchInterval, _ := cmd.Flags().GetInt("watch-interval")
+			watchHistory, _ := cmd.Flags().GetInt("watch-history")
+			tui, _ := cmd.Flags().GetBool("tui")
+			strictVersion := configProvider.GetBool("snapshots.strict_version")
+			varsPassphrase, _ := cmd.Flags().GetString("vars-passphrase")
+			varsKeyFile, _ := cmd.Flags().GetString("vars-key-file")
+			dataFile, _ := cmd.Flags().GetString("data")
+			ignoreArrayOrder, _ := cmd.Flags().GetBool("ignore-array-order")
+			arrayOrderKey, _ := cmd.Flags().GetString("array-order-key")
+			languages, _ := cmd.Flags().GetStringSlice("languages")
+			notifyWebhooks, _ := cmd.Flags().GetStringSlice("notify-webhook")
+			notifyFormat, _ := cmd.Flags().GetString("notify-format")
+			notifyReportURL, _ := cmd.Flags().GetString("notify-report-url")
+			githubAnnotations, _ := cmd.Flags().GetBool("github-annotations")
+			githubCheck, _ := cmd.Flags().GetBool("github-check")
+			githubCheckName, _ := cmd.Flags().GetString("github-check-name")
+			junitGroupBy, _ := cmd.Flags().GetString("junit-group-by")
+			failOn, _ := cmd.Flags().GetString("fail-on")
+			quiet, _ := cmd.Flags().GetBool("quiet")
+			controlAddr, _ := cmd.Flags().GetString("control-addr")
+			specFile, _ := cmd.Flags().GetString("spec")
+			serverIndex, _ := cmd.Flags().GetInt("server-index")
+			serverURL, _ := cmd.Flags().GetString("server-url")
+			serverVars, _ := cmd.Flags().GetStringSlice("server-var")
+			callbackAddr, _ := cmd.Flags().GetString("callback-addr")
+			callbackURL, _ := cmd.Flags().GetString("callback-url")
+
+			// Check the tag filter expressions before running anything
+			if err := checkTagFilters(tags); err != nil {
+				return err
+			}
 
 			// Parse timeout
 			timeout := 30 * time.Second
 			if timeoutStr != "" {
 				parsedTimeout, err := time.ParseDuration(timeoutStr)
 				if err != nil {
-					return fmt.Errorf("invalid timeout format: %w", err)
+					return newExitError(ExitConfigError, fmt.Errorf("invalid timeout format: %w", err))
 				}
 				timeout = parsedTimeout
 			}
 
-			// Parse ignore headers
-			ignoreHeadersList := []string{"Date", "Set-Cookie"}
-			if ignoreHeaders != "" {
-				ignoreHeadersList = strings.Split(ignoreHeaders, ",")
-				for i := range ignoreHeadersList {
-					ignoreHeadersList[i] = strings.TrimSpace(ignoreHeadersList[i])
+			// Parse the overall time budget of the run
+			var runTimeout time.Duration
+			if runTimeoutStr != "" {
+				parsedTimeout, err := time.ParseDuration(runTimeoutStr)
+				if err != nil {
+					return newExitError(ExitConfigError, fmt.Errorf("invalid run timeout format: %w", err))
 				}
+				runTimeout = parsedTimeout
+			}
+
+			// Parse the failure classes that make the run fail
+			policy, err := parseFailOn(failOn)
+			if err != nil {
+				return err
+			}
+
+			// Parse the header patterns ignored on top of the configured ones
+			ignoreHeadersList, err := models.ParseHeaderPatterns(ignoreHeaders)
+			if err != nil {
+				return newExitError(ExitConfigError, fmt.Errorf("invalid --ignore-headers: %w", err))
+			}
+
+			// Parse the order tests run in
+			order, err := models.ParseTestOrder(orderFlag)
+			if err != nil {
+				return newExitError(ExitConfigError, err)
 			}
 
 			// Create test filter
@@ -71,19 +131,22 @@ func AddTestCommands(rootCmd *cobra.Command, configProvider application.ConfigPr
 				Methods: methods,
 				Paths:   paths,
 				Names:   names,
+				Scopes:  scopes,
 			}
 
 			// Create test run options
 			options := models.TestRunOptions{
-				UpdateSnapshots: updateMode,
-				FailOnMissing:   failOnMissing,
-				IgnoreHeaders:   ignoreHeadersList,
-				Timeout:         timeout,
-				Parallel:        parallel,
-				MaxConcurrent:   maxConcurrent,
-				StopOnFailure:   stopOnFailure,
-				Filter:          filter,
-				EnvironmentVars: extractEnvironmentVars(),
+				UpdateSnapshots:  updateMode,
+				FailOnMissing:    failOnMissing,
+				IgnoreHeaders:    ignoreHeadersList,
+				IgnoreArrayOrder: ignoreArrayOrder || arrayOrderKey != "",
+				ArrayOrderKey:    arrayOrderKey,
+				Timeout:          timeout,
+				Parallel:         parallel,
+				MaxConcurrent:    maxConcurrent,
+				StopOnFailure:    stopOnFailure,
+				Filter:           filter,
+				EnvironmentVars:  extractEnvironmentVars(),
 				ReportOptions: models.TestReportOptions{
 					Format:           reportFormat,
 					OutputPath:       reportOutput,
@@ -91,9 +154,153 @@ func AddTestCommands(rootCmd *cobra.Command, configProvider application.ConfigPr
 					IncludeResponses: detailed,
 					ColorOutput:      true,
 					Detailed:         detailed,
+					InlineMaxBytes:   inlineMaxBytes,
+					JUnitGroupBy:     junitGroupBy,
 				},
 				ContinuousMode:  watch,
 				WatchIntervalMs: watchInterval,
+				WatchHistory:    watchHistory,
+				StrictVersion:   strictVersion,
+				VarsPassphrase:  varsPassphrase,
+				VarsKeyFile:     varsKeyFile,
+				Languages:       languages,
+				RunTimeout:      runTimeout,
+				CallbackAddr:    callbackAddr,
+				CallbackURL:     callbackURL,
+				Guard:           mutationGuard(cmd, configProvider),
+				Seed:            seed,
+				Order:           order,
+				Focus:           focus,
+			}
+
+			// Ask for missing variables on the terminal, or fail listing them
+			// all with --no-input; requests still using variables without a
+			// value fail unless --strict-vars=false
+			options.NoInput, _ = cmd.Flags().GetBool("no-input")
+			options.StrictVars, _ = cmd.Flags().GetBool("strict-vars")
+			if !options.NoInput && isTerminal(os.Stdin) {
+				options.PromptVariable = promptVariable
+			}
+
+			// Override the redirect policy of the executor when asked to
+			if cmd.Flags().Changed("follow-redirects") || cmd.Flags().Changed("max-redirects") {
+				if maxRedirects < 1 {
+					return newExitError(ExitConfigError, fmt.Errorf("--max-redirects must be at least 1"))
+				}
+				options.Redirects = &models.RedirectPolicy{Follow: followRedirects, Max: maxRedirects}
+			}
+
+			// Override the retries of the executor when asked to
+			if cmd.Flags().Changed("retries") {
+				if retries < 0 {
+					return newExitError(ExitConfigError, fmt.Errorf("--retries must not be negative"))
+				}
+				options.Retries = &models.RetryOptions{Max: retries}
+			}
+
+			// Check the configured budgets of tagged tests as assertions
+			budgets, err := models.ParseBudgets(configProvider.GetStringMap("test.budgets"))
+			if err != nil {
+				return newExitError(ExitConfigError, err)
+			}
+			options.Budgets = budgets
+
+			// Run the known-failing tests without failing the build
+			quarantineFile, _ := cmd.Flags().GetString("quarantine")
+			if !cmd.Flags().Changed("quarantine") {
+				quarantineFile = configProvider.GetString("test.quarantine_file")
+			}
+			if quarantineFile != "" {
+				options.Quarantine, err = models.LoadQuarantine(quarantineFile)
+				if err != nil {
+					return newExitError(ExitConfigError, err)
+				}
+			}
+
+			// Cap the response bodies read, and keep the run under the memory limit
+			if cmd.Flags().Changed("max-body-bytes") {
+				maxBodyBytes, _ := cmd.Flags().GetString("max-body-bytes")
+				options.MaxBodyBytes, err = models.ParseByteSize(maxBodyBytes)
+				if err != nil {
+					return newExitError(ExitConfigError, fmt.Errorf("--max-body-bytes: %w", err))
+				}
+			}
+			memoryLimit, _ := cmd.Flags().GetString("memory-limit")
+			if !cmd.Flags().Changed("memory-limit") {
+				memoryLimit = configProvider.GetString("test.memory_limit")
+			}
+			if memoryLimit != "" {
+				limit, err := models.ParseByteSize(memoryLimit)
+				if err != nil || limit == 0 {
+					return newExitError(ExitConfigError, fmt.Errorf("invalid memory limit %q", memoryLimit))
+				}
+				spillDir, _ := cmd.Flags().GetString("spill-dir")
+				if !cmd.Flags().Changed("spill-dir") {
+					spillDir = configProvider.GetString("test.spill_dir")
+				}
+				options.Memory = models.NewMemoryMonitor(limit, spillDir, nil)
+			}
+
+			// Record the completed tests, skipping those of the run being resumed
+			checkpointFile, _ := cmd.Flags().GetString("checkpoint")
+			resume, _ := cmd.Flags().GetBool("resume")
+			if resume && checkpointFile == "" {
+				return newExitError(ExitConfigError, fmt.Errorf("--resume requires --checkpoint"))
+			}
+			if checkpointFile != "" {
+				options.Checkpoint, err = models.OpenCheckpoint(checkpointFile, resume)
+				if err != nil {
+					return newExitError(ExitConfigError, err)
+				}
+				defer options.Checkpoint.Close()
+			}
+	
+			// Set the variables given on the command line, which take precedence
+			// over the other sources
+			options.Variables, err = commandLineVariables(cmd)
+			if err != nil {
+				return err
+			}
+
+			// Override the query parameters of the requests that have them
+			queryOverrides, _ := cmd.Flags().GetStringSlice("query")
+			options.QueryParams, err = models.ParseQueryOverrides(queryOverrides)
+			if err != nil {
+				return newExitError(ExitConfigError, err)
+			}
+
+			// Resolve relative request URLs against the given host rather than
+			// the files' "@host"
+			options.Host, _ = cmd.Flags().GetString("host")
+			if !cmd.Flags().Changed("host") {
+				options.Host = configProvider.GetString("test.host")
+			}
+
+			// Point the requests at the selected server of the spec
+			if specFile != "" {
+				serverVariables, err := models.ParseServerVariables(serverVars)
+				if err != nil {
+					return newExitError(ExitConfigError, err)
+				}
+				doc, err := parser.NewSwaggerParser().ParseFile(context.Background(), specFile)
+				if err != nil {
+					return newExitError(ExitSpecError, fmt.Errorf("failed to parse spec: %w", err))
+				}
+				options.BaseURL, err = doc.ServerURL(models.ServerSelector{Index: serverIndex, URL: serverURL, Variables: serverVariables})
+				if err != nil {
+					return newExitError(ExitConfigError, err)
+				}
+			} else if cmd.Flags().Changed("server-index") || serverURL != "" || len(serverVars) > 0 {
+				return newExitError(ExitConfigError, fmt.Errorf("--server-index, --server-url and --server-var require --spec"))
+			}
+
+			// Load data rows for data-driven runs
+			if dataFile != "" {
+				rows, err := extractor.LoadDataFile(dataFile)
+				if err != nil {
+					return newExitError(ExitConfigError, err)
+				}
+				options.DataRows = rows
 			}
 
 			// Add snapshot directory to filter paths if provided
@@ -105,11 +312,29 @@ func AddTestCommands(rootCmd *cobra.Command, configProvider application.ConfigPr
 
 			// Run in watch mode if specified
 			if watch {
-				return handleWatchMode(context.Background(), args, options, testRunner, testReporter)
+				ctx := context.Background()
+				runner, controller, err := startControlServer(ctx, controlAddr, testRunner)
+				if err != nil {
+					return err
+				}
+				if tui {
+					return handleDashboardMode(ctx, args, options, runner, testReporter, controller)
+				}
+				return handleWatchMode(ctx, args, options, runner, testReporter, controller)
+			}
+
+			// Show a progress bar instead of the full results in quiet mode
+			var progressBar *reporter.ProgressBar
+			if quiet {
+				progressBar = reporter.NewProgressBar(os.Stderr, true)
+				options.Progress = progressBar
 			}
 
 			// Run tests
 			report, err := testRunner.RunTests(context.Background(), args, options)
+			if progressBar != nil {
+				progressBar.Finish()
+			}
 			if err != nil {
 				return fmt.Errorf("failed to run tests: %w", err)
 			}
@@ -117,6 +342,7 @@ func AddTestCommands(rootCmd *cobra.Command, configProvider application.ConfigPr
 			// Print report to console
 			consoleOptions := options.ReportOptions
 			consoleOptions.Format = "console"
+			consoleOptions.FailuresOnly = quiet
 			err = testReporter.PrintReport(context.Background(), report, consoleOptions, os.Stdout)
 			if err != nil {
 				return fmt.Errorf("failed to print report: %w", err)
@@ -131,47 +357,133 @@ func AddTestCommands(rootCmd *cobra.Command, configProvider application.ConfigPr
 				fmt.Printf("Report saved to %s\n", reportOutput)
 			}
 
-			// Return non-zero exit code if any tests failed
-			if report.Summary.FailedTests > 0 || report.Summary.ErrorTests > 0 {
-				return fmt.Errorf("tests failed: %d failed, %d errors", report.Summary.FailedTests, report.Summary.ErrorTests)
+			// Annotate the failing requests in GitHub Actions
+			if githubAnnotations {
+				annotationOptions := options.ReportOptions
+				annotationOptions.Format = "github"
+				if err := testReporter.PrintReport(context.Background(), report, annotationOptions, os.Stdout); err != nil {
+					return fmt.Errorf("failed to print annotations: %w", err)
+				}
 			}
 
-			return nil
+			notifyOptions := reporter.NotifyOptions{
+				ReportURL: notifyReportURL,
+				RunURL:    ciRunURL(),
+			}
+
+			// Post the summary to the notification webhooks
+			if len(notifyWebhooks) > 0 {
+				if err := sendNotifications(context.Background(), report, notifyWebhooks, notifyFormat, notifyOptions); err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+				}
+			}
+
+			// Publish the results as a GitHub Check Run
+			if githubCheck {
+				notifier, err := reporter.NewGitHubCheckNotifierFromEnv(githubCheckName, 30*time.Second)
+				if err == nil {
+					err = notifier.Notify(context.Background(), report, notifyOptions)
+				}
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+				}
+			}
+
+			// Return a non-zero exit code if any of the --fail-on classes were found
+			return policy.check(report)
 		},
 	}
 
 	// Add flags to test command
 	testCmd.Flags().String("update", "none", "Update mode: none, all, failed, missing")
-	testCmd.Flags().String("ignore-headers", "Date,Set-Cookie", "Comma-separated headers to ignore in comparison")
+	testCmd.Flags().String("ignore-headers", "", "Comma-separated header patterns to ignore on top of snapshots.ignore_headers, \"!Name\" to compare one again")
 	testCmd.Flags().String("snapshot-dir", ".snapshots", "Directory for snapshot storage")
+	testCmd.PersistentFlags().String("snapshot-store", "", "Store snapshots in s3://bucket/prefix or gs://bucket/prefix rather than on disk, overriding snapshots.store")
+	testCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		applyStrictVersion(cmd, configProvider)
+		return applySnapshotStore(cmd, configProvider)
+	}
 	testCmd.Flags().Bool("fail-on-missing", false, "Fail when snapshot is missing")
 	testCmd.Flags().Bool("cleanup", false, "Remove unused snapshots after testing")
 	testCmd.Flags().String("timeout", "30s", "HTTP request timeout")
+	testCmd.Flags().String("run-timeout", "", "Overall time budget for the run, e.g. 10m; unstarted tests are skipped")
 	testCmd.Flags().Bool("parallel", false, "Run tests in parallel")
 	testCmd.Flags().Int("max-concurrent", 5, "Maximum number of concurrent tests")
 	testCmd.Flags().Bool("stop-on-failure", false, "Stop testing after first failure")
-	testCmd.Flags().StringSlice("tags", []string{}, "Filter tests by tags")
+	testCmd.Flags().Bool("follow-redirects", true, "Follow redirects, recording each one in the response")
+	testCmd.Flags().Int("max-redirects", models.DefaultMaxRedirects, "Redirects followed before a request fails")
+	testCmd.Flags().Int64("seed", 0, "Seed of the run's randomness, such as retry jitter; replays a run given the seed of its report")
+	testCmd.Flags().String("order", string(models.TestOrderFile), "Order tests run in: file, priority (by # @priority, lowest first) or random (shuffled with --seed)")
+	testCmd.Flags().Int("retries", 0, "Retry idempotent requests answered with 429, 502, 503 or 504 up to this many times")
+	testCmd.PersistentFlags().StringSlice("var", []string{}, "Value of a variable as name=value (repeatable), taking precedence over the other sources; also {{cli.name}}")
+	testCmd.Flags().StringSlice("query", []string{}, "Value of a query parameter as name=value (repeatable), overriding it in the requests that have it")
+	testCmd.PersistentFlags().Bool("strict-vars", true, "Fail requests using variables without a value rather than sending the {{placeholders}} as written")
+	testCmd.Flags().Bool("no-input", false, "Never ask for missing variables: fail before running, listing them all")
+	testCmd.Flags().String("host", "", "Base URL relative request URLs are resolved against, overriding the files' @host")
+	testCmd.Flags().String("quarantine", "", "File listing known-failing tests that run without failing the build, one name or pattern per line")
+	testCmd.Flags().String("max-body-bytes", "0", "Largest response body read, e.g. 50MB; larger ones fail the request (0 for no limit)")
+	testCmd.Flags().String("memory-limit", "", "Resident memory, e.g. 2GB, above which requests run one at a time and bodies are spilled to disk")
+	testCmd.Flags().String("spill-dir", "", "Directory bodies are spilled to above --memory-limit (default a temporary directory)")
+	testCmd.Flags().String("checkpoint", "", "File recording the completed tests as the run goes, to resume it after a crash or cancellation")
+	testCmd.Flags().Bool("resume", false, "Skip the tests recorded by --checkpoint, merging their results into the report")
+	testCmd.Flags().String("fail-on", DefaultFailOn, "Failure classes that cause a non-zero exit: failed, error, schema, missing-snapshot, deprecated")
+	testCmd.Flags().StringSlice("tags", []string{}, tagsFlagUsage)
 	testCmd.Flags().StringSlice("methods", []string{}, "Filter tests by HTTP methods")
 	testCmd.Flags().StringSlice("paths", []string{}, "Filter tests by request paths")
 	testCmd.Flags().StringSlice("names", []string{}, "Filter tests by test names")
-	testCmd.Flags().String("report-format", "console", "Report format: console, json, html, junit")
+	testCmd.Flags().StringSlice("scopes", []string{}, "Run only the tests whose @scopes are all among these OAuth scopes, e.g. read:pets")
+	testCmd.Flags().StringSlice("focus", []string{}, "Run only the tests with these names or patterns, reporting the others as skipped")
+	testCmd.Flags().String("report-format", "console", "Report format: console, json, html, junit, github, or the format of a reporter plugin")
 	testCmd.Flags().String("report-output", "", "Path to write report file")
+	testCmd.Flags().String("junit-group-by", "file", "Group JUnit test suites by: file, tag, none")
 	testCmd.Flags().Bool("detailed", false, "Include detailed information in report")
+	testCmd.Flags().Int("inline-max-bytes", 0, "Save bodies larger than this as files next to JSON and HTML reports (0 inlines all)")
+	testCmd.Flags().BoolP("quiet", "q", false, "Show a progress bar and print only failures and the summary")
 	testCmd.Flags().Bool("watch", false, "Run in continuous (watch) mode")
 	testCmd.Flags().Int("watch-interval", 1000, "Interval between watch checks in milliseconds")
+	testCmd.Flags().Int("watch-history", watcher.DefaultHistorySize, "Runs kept per request for the status and latency trend in watch mode")
+	testCmd.Flags().Bool("tui", false, "Show an interactive dashboard in watch mode")
+	testCmd.Flags().String("control-addr", "", "Serve the control API in watch mode on a local address or unix:<socket>")
+	testCmd.Flags().Bool("strict-version", false, "Fail on snapshots and HTTP files generated by an incompatible major version, overriding snapshots.strict_version")
+	testCmd.Flags().String("vars-passphrase", "", "Passphrase for encrypted variable files (or SWAGGER_TO_HTTP_VARS_PASSPHRASE)")
+	testCmd.Flags().String("vars-key-file", "", "Key file for encrypted variable files (or SWAGGER_TO_HTTP_VARS_KEY_FILE)")
+	testCmd.Flags().Bool("ignore-array-order", false, "Compare JSON arrays regardless of element order")
+	testCmd.Flags().String("array-order-key", "", "Sort arrays of objects by this field when ignoring array order")
+	testCmd.Flags().StringSlice("languages", []string{}, "Run each test once per Accept-Language value, e.g. en,pt-BR,es")
+	testCmd.Flags().StringSlice("notify-webhook", []string{}, "Webhook URL to post the run summary to")
+	testCmd.Flags().String("notify-format", "auto", "Notification payload format: auto, generic, slack")
+	testCmd.Flags().String("notify-report-url", "", "URL of the published HTML report to link from notifications")
+	testCmd.Flags().Bool("github-annotations", reporter.IsGitHubActions(), "Print GitHub Actions annotations for failing requests (default true in GitHub Actions)")
+	testCmd.Flags().Bool("github-check", false, "Publish the results as a GitHub Check Run using GITHUB_TOKEN")
+	testCmd.Flags().String("github-check-name", "swagger-to-http", "Name of the GitHub Check Run")
+	testCmd.Flags().String("spec", "", "Swagger/OpenAPI file whose server the requests are sent to")
+	testCmd.Flags().Int("server-index", 0, "Index of the server in the spec's servers to send requests to")
+	testCmd.Flags().String("server-url", "", "URL of the server in the spec's servers to send requests to, templated or resolved")
+	testCmd.Flags().StringSlice("server-var", []string{}, "Value of a server variable as name=value (repeatable)")
+	testCmd.Flags().String("callback-addr", application.DefaultCallbackAddr, "Address the listener for @callback requests binds to")
+	testCmd.Flags().String("callback-url", "", "Public base URL of the callback listener, e.g. a tunnel to it")
+	testCmd.Flags().String("data", "", "CSV or JSON data file; each test runs once per row with the columns as variables")
+	addGuardFlags(testCmd, configProvider)
 
 	// List command
 	listCmd := &cobra.Command{
-		Use:   "list [file-patterns]",
-		Short: "List available HTTP tests",
-		Long:  `Find and list HTTP tests in the specified files`,
-		Args:  cobra.MinimumNArgs(1),
+		Use:     "list [file-patterns]",
+		Aliases: []string{"ls"},
+		Short:   "List available HTTP tests",
+		Long:    `Find and list HTTP tests in the specified files`,
+		Args:    cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Get flags
 			tags, _ := cmd.Flags().GetStringSlice("tags")
 			methods, _ := cmd.Flags().GetStringSlice("methods")
 			paths, _ := cmd.Flags().GetStringSlice("paths")
 			names, _ := cmd.Flags().GetStringSlice("names")
+			scopes, _ := cmd.Flags().GetStringSlice("scopes")
+
+			// Check the tag filter expressions before running anything
+			if err := checkTagFilters(tags); err != nil {
+				return err
+			}
 
 			// Create test filter
 			filter := models.TestFilter{
@@ -179,6 +491,7 @@ func AddTestCommands(rootCmd *cobra.Command, configProvider application.ConfigPr
 				Methods: methods,
 				Paths:   paths,
 				Names:   names,
+				Scopes:  scopes,
 			}
 
 			// Find tests matching the filter
@@ -187,20 +500,27 @@ func AddTestCommands(rootCmd *cobra.Command, configProvider application.ConfigPr
 				return fmt.Errorf("failed to find tests: %w", err)
 			}
 
+			// Tell apart the tests whose derived names collide
+			for _, warning := range application.DedupeTestNames(files) {
+				fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+			}
+
 			// Print test information
 			fmt.Printf("Found %d files with tests:\n\n", len(files))
 			for _, file := range files {
 				fmt.Printf("File: %s\n", file.Filename)
 				fmt.Printf("  Tests: %d\n", len(file.Requests))
 
-				// Group tests by tag
+				// Group tests by tag, listing those with several tags under each
 				testsByTag := make(map[string][]models.HTTPRequest)
 				for _, req := range file.Requests {
-					tag := req.Tag
-					if tag == "" {
-						tag = "default"
+					tags := req.TagList()
+					if len(tags) == 0 {
+						tags = []string{"default"}
+					}
+					for _, tag := range tags {
+						testsByTag[tag] = append(testsByTag[tag], req)
 					}
-					testsByTag[tag] = append(testsByTag[tag], req)
 				}
 
 				// Print tests by tag
@@ -218,10 +538,11 @@ func AddTestCommands(rootCmd *cobra.Command, configProvider application.ConfigPr
 	}
 
 	// Add flags to list command
-	listCmd.Flags().StringSlice("tags", []string{}, "Filter tests by tags")
+	listCmd.Flags().StringSlice("tags", []string{}, tagsFlagUsage)
 	listCmd.Flags().StringSlice("methods", []string{}, "Filter tests by HTTP methods")
 	listCmd.Flags().StringSlice("paths", []string{}, "Filter tests by request paths")
 	listCmd.Flags().StringSlice("names", []string{}, "Filter tests by test names")
+	listCmd.Flags().StringSlice("scopes", []string{}, "List only the tests whose @scopes are all among these OAuth scopes, e.g. read:pets")
 
 	// Add test commands to root command
 	rootCmd.AddCommand(testCmd)
@@ -230,7 +551,7 @@ func AddTestCommands(rootCmd *cobra.Command, configProvider application.ConfigPr
 
 // handleWatchMode runs tests in watch mode
 func handleWatchMode(ctx context.Context, patterns []string, options models.TestRunOptions, 
-	testRunner application.TestRunner, testReporter application.TestReporter) error {
+	testRunner application.TestRunner, testReporter application.TestReporter, controller *control.Controller) error {
 	
 	// Create a watcher service
 	watcherService := watcher.NewTestWatcherService(testRunner, testReporter)
@@ -240,6 +561,11 @@ func handleWatchMode(ctx context.Context, patterns []string, options models.Test
 		return fmt.Errorf("failed to start watcher: %w", err)
 	}
 
+	// Re-run the tests when requested through the control API
+	forwardReruns(ctx, controller, func() {
+		watcherService.Trigger(ctx, patterns, options)
+	})
+
 	fmt.Println("Watching for changes. Press Ctrl+C to stop...")
 
 	// Wait for interrupt signal
@@ -251,6 +577,49 @@ func handleWatchMode(ctx context.Context, patterns []string, options models.Test
 	return nil
 }
 
+// handleDashboardMode runs tests in watch mode with the interactive dashboard
+func handleDashboardMode(ctx context.Context, patterns []string, options models.TestRunOptions,
+	testRunner application.TestRunner, testReporter application.TestReporter, controller *control.Controller) error {
+
+	// Create a watcher service and attach the dashboard to it
+	watcherService := watcher.NewTestWatcherService(testRunner, testReporter)
+	dashboard := watcher.NewDashboard(watcherService, testRunner, patterns, options)
+
+	// Re-run the tests when requested through the control API
+	forwardReruns(ctx, controller, func() {
+		watcherService.Trigger(ctx, patterns, options)
+	})
+
+	return dashboard.Run(ctx)
+}
+
+// sendNotifications posts the run summary to every webhook
+func sendNotifications(ctx context.Context, report *models.TestReport, webhooks []string, format string, options reporter.NotifyOptions) error {
+	var errs []string
+	for _, webhook := range webhooks {
+		notifier, err := reporter.NewNotifier(webhook, format, 10*time.Second)
+		if err != nil {
+			return err
+		}
+		if err := notifier.Notify(ctx, report, options); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to send notifications: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// ciRunURL returns the URL of the current CI run when running in GitHub Actions
+func ciRunURL() string {
+	server, repository, runID := os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_REPOSITORY"), os.Getenv("GITHUB_RUN_ID")
+	if server == "" || repository == "" || runID == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s/actions/runs/%s", server, repository, runID)
+}
+
 // extractEnvironmentVars extracts environment variables with HTTP_ prefix
 func extractEnvironmentVars() map[string]string {
 	vars := make(map[string]string)
@@ -272,3 +641,37 @@ func extractEnvironmentVars() map[string]string {
 	
 	return vars
 }
+
+// applySnapshotStore makes --snapshot-store, when given, the store snapshots
+// are read from and written to
+func applySnapshotStore(cmd *cobra.Command, configProvider application.ConfigProvider) error {
+	if !cmd.Flags().Changed("snapshot-store") {
+		return nil
+	}
+	location, _ := cmd.Flags().GetString("snapshot-store")
+	if _, err := snapstore.Open(location); err != nil {
+		return newExitError(ExitConfigError, err)
+	}
+	configProvider.Set("snapshots.store", location)
+	return nil
+}
+
+// applyStrictVersion makes --strict-version, when given, whether snapshots and
+// HTTP files from an incompatible major version fail the run
+func applyStrictVersion(cmd *cobra.Command, configProvider application.ConfigProvider) {
+	if !cmd.Flags().Changed("strict-version") {
+		return
+	}
+	strict, _ := cmd.Flags().GetBool("strict-version")
+	configProvider.Set("snapshots.strict_version", strict)
+}
+
+// commandLineVariables parses the variables given with --var
+func commandLineVariables(cmd *cobra.Command) (map[string]string, error) {
+	assignments, _ := cmd.Flags().GetStringSlice("var")
+	variables, err := models.ParseVariables(assignments)
+	if err != nil {
+		return nil, newExitError(ExitConfigError, err)
+	}
+	return variables, nil
+}
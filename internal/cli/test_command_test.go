@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/config"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyStrictVersion(t *testing.T) {
+	tests := []struct {
+		name string
+		env  string
+		args []string
+		want bool
+	}{
+		{name: "default", want: false},
+		{name: "environment", env: "true", want: true},
+		{name: "flag", args: []string{"--strict-version"}, want: true},
+		{name: "flag overrides environment", env: "true", args: []string{"--strict-version=false"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("STH_SNAPSHOTS_STRICT_VERSION", tt.env)
+			configProvider := config.NewConfigProvider()
+
+			cmd := &cobra.Command{Use: "test"}
+			cmd.Flags().Bool("strict-version", false, "")
+			require.NoError(t, cmd.Flags().Parse(tt.args))
+
+			applyStrictVersion(cmd, configProvider)
+			assert.Equal(t, tt.want, configProvider.GetBool("snapshots.strict_version"))
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"sort"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/application/contract"
+	"github.com/edgardnogueira/swagger-to-http/internal/application/parser"
+	"github.com/edgardnogueira/swagger-to-http/internal/application/snapshot"
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/snapstore"
+	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/validator"
+	"github.com/spf13/cobra"
+)
+
+// setupValidateSnapshotsCmd creates the command checking stored snapshots against a spec
+func setupValidateSnapshotsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snapshots",
+		Short: "Validate stored snapshots against a Swagger/OpenAPI file, offline",
+		Long: `Replay the responses stored in snapshots through the schema validator without sending
+any request, listing the recorded behaviors an updated spec no longer allows: requests
+no operation matches, statuses the operation doesn't declare and bodies that don't
+match the schema of the response.
+
+Snapshots that don't record their request, written before requests were recorded, and
+responses that are not JSON are skipped and listed.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			swaggerFile, _ := cmd.Flags().GetString("swagger-file")
+			snapshotDir, _ := cmd.Flags().GetString("snapshot-dir")
+			jsonOutput, _ := cmd.Flags().GetBool("json")
+
+			if swaggerFile == "" {
+				return newExitError(ExitConfigError, fmt.Errorf("swagger file is required for schema validation"))
+			}
+
+			ctx := context.Background()
+			doc, err := parser.NewSwaggerParser().ParseFile(ctx, swaggerFile)
+			if err != nil {
+				return newExitError(ExitSpecError, fmt.Errorf("failed to parse spec: %w", err))
+			}
+
+			snapshots, err := loadStoredSnapshots(snapshotDir)
+			if err != nil {
+				return newExitError(ExitConfigError, err)
+			}
+
+			options := validationOptionsFromFlags(cmd)
+			schemaValidator := validator.NewSchemaValidatorService()
+			validate := func(response *models.HTTPResponse, path, method string) (*models.SchemaValidationResult, error) {
+				return schemaValidator.ValidateResponseWithSwagger(ctx, response, doc, path, method, options)
+			}
+			report := contract.NewChecker(doc, validate).Check(snapshots)
+
+			if jsonOutput {
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+				if err := encoder.Encode(report); err != nil {
+					return err
+				}
+			} else {
+				writeContractReport(cmd.OutOrStdout(), report)
+			}
+
+			if report.Failed > 0 {
+				return newExitError(ExitTestFailures, fmt.Errorf("%d of %d snapshots violate %s", report.Failed, len(report.Results), swaggerFile))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().String("swagger-file", "", "Path to the Swagger/OpenAPI file to check the snapshots against (required)")
+	cmd.Flags().String("snapshot-dir", ".snapshots", "Directory for snapshot storage")
+	cmd.Flags().Bool("json", false, "Print the results as JSON")
+	addValidationFlags(cmd)
+
+	return cmd
+}
+
+// loadStoredSnapshots reads the snapshots stored under a directory, in the
+// order of their paths
+func loadStoredSnapshots(dir string) ([]contract.Snapshot, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() && filepath.Ext(path) == ".snap" {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots in %s: %w", dir, err)
+	}
+	sort.Strings(paths)
+
+	// The JSON formatter keeps bodies that aren't JSON as they are
+	formatter, err := snapshot.GetFormatter("application/json")
+	if err != nil {
+		return nil, err
+	}
+
+	store := snapstore.NewCompressed(snapstore.Local{}, snapstore.CompressionNone)
+	snapshots := make([]contract.Snapshot, 0, len(paths))
+	for _, path := range paths {
+		data, err := store.Read(path)
+		if err == nil {
+			data, err = snapstore.LoadBody(store, path, data)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read snapshot %s: %w", path, err)
+		}
+		response, err := formatter.Parse(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse snapshot %s: %w", path, err)
+		}
+		snapshots = append(snapshots, contract.Snapshot{Path: path, Response: response})
+	}
+	return snapshots, nil
+}
+
+// writeContractReport prints the snapshots that failed or were skipped, and
+// the totals
+func writeContractReport(w io.Writer, report *contract.Report) {
+	for _, result := range report.Results {
+		if result.Outcome == contract.OutcomePassed {
+			continue
+		}
+		fmt.Fprintf(w, "%s %s\n", outcomeLabel(result.Outcome), result.Snapshot)
+		if result.Method != "" {
+			fmt.Fprintf(w, "  Operation: %s %s -> %d\n", result.Method, result.Path, result.Status)
+		}
+		fmt.Fprintf(w, "  %s\n", result.Reason)
+		for _, validationError := range result.Errors {
+			fmt.Fprintf(w, "    - %s\n", describeValidationError(validationError))
+		}
+		for _, warning := range result.Warnings {
+			fmt.Fprintf(w, "    - warning: %s\n", describeValidationError(warning))
+		}
+	}
+	fmt.Fprintf(w, "\n%d snapshots: %d passed, %d failed, %d skipped\n", len(report.Results), report.Passed, report.Failed, report.Skipped)
+}
+
+// outcomeLabel returns the label printed before a snapshot with an outcome
+func outcomeLabel(outcome string) string {
+	if outcome == contract.OutcomeFailed {
+		return "FAIL"
+	}
+	return "SKIP"
+}
+
+// describeValidationError describes a schema validation error
+func describeValidationError(validationError models.ValidationError) string {
+	if validationError.Path == "" {
+		return validationError.Message
+	}
+	return validationError.Path + ": " + validationError.Message
+}
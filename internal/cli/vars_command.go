@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/secrets"
+	"github.com/spf13/cobra"
+)
+
+// setupVarsCmd sets up the vars command and its subcommands
+func setupVarsCmd() *cobra.Command {
+	varsCmd := &cobra.Command{
+		Use:   "vars",
+		Short: "Manage variable files",
+		Long: `Encrypt and decrypt variable files (variables.json, .http-env) so they can be committed safely.
+
+The passphrase is read from --passphrase, --key-file, or the SWAGGER_TO_HTTP_VARS_PASSPHRASE
+and SWAGGER_TO_HTTP_VARS_KEY_FILE environment variables. Encrypted files are decrypted
+transparently when running tests.`,
+	}
+
+	// Add subcommands
+	varsCmd.AddCommand(setupVarsCryptCmd("encrypt", "Encrypt a variable file", true))
+	varsCmd.AddCommand(setupVarsCryptCmd("decrypt", "Decrypt a variable file", false))
+
+	return varsCmd
+}
+
+// setupVarsCryptCmd creates the 'vars encrypt' and 'vars decrypt' commands
+func setupVarsCryptCmd(name, short string, encrypt bool) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   name + " [file]",
+		Short: short,
+		Long:  short + ". The file is rewritten in place unless --output is given.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			passphrase, _ := cmd.Flags().GetString("passphrase")
+			keyFile, _ := cmd.Flags().GetString("key-file")
+			output, _ := cmd.Flags().GetString("output")
+
+			cipher, err := secrets.ResolveCipher(passphrase, keyFile)
+			if err != nil {
+				return err
+			}
+			if cipher == nil {
+				return fmt.Errorf("no key provided: use --passphrase, --key-file or %s", secrets.PassphraseEnv)
+			}
+
+			return cryptVariableFile(cipher, args[0], output, encrypt)
+		},
+	}
+
+	cmd.Flags().String("passphrase", "", "Passphrase used to derive the encryption key")
+	cmd.Flags().String("key-file", "", "File containing the encryption key")
+	cmd.Flags().StringP("output", "o", "", "Output file (default: overwrite the input file)")
+
+	return cmd
+}
+
+// cryptVariableFile encrypts or decrypts a variable file
+func cryptVariableFile(cipher *secrets.Cipher, path, output string, encrypt bool) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var result []byte
+	if encrypt {
+		if secrets.IsEncrypted(data) {
+			return fmt.Errorf("%s is already encrypted", path)
+		}
+		result, err = cipher.Encrypt(data)
+	} else {
+		result, err = cipher.Decrypt(data)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to process %s: %w", path, err)
+	}
+
+	if output == "" {
+		output = path
+	}
+
+	// Keep decrypted secrets readable only by the owner
+	if err := os.WriteFile(output, result, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", output, err)
+	}
+
+	action := "Decrypted"
+	if encrypt {
+		action = "Encrypted"
+	}
+	fmt.Printf("%s %s\n", action, output)
+
+	return nil
+}
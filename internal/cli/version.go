@@ -7,11 +7,14 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var versionCmd = &cobra.Command{
-	Use:   "version",
-	Short: "Print the version information",
-	Long:  `Display the version, build date, and other information about the swagger-to-http tool.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println(version.Info())
-	},
+// setupVersionCmd creates the 'version' command
+func setupVersionCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "version",
+		Short: "Print the version information",
+		Long:  `Display the version, build date, and other information about the swagger-to-http tool.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Println(version.Info())
+		},
+	}
 }
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// bodyIncludePattern matches a body line read from a file: "< path", or
+// "<@ path" to replace the variables of the file. The space tells it apart
+// from an XML body.
+var bodyIncludePattern = regexp.MustCompile(`^<(@?)[ \t]+(\S.*?)\s*$`)
+
+// BodyInclude is a line of a request body replaced with the content of a
+// file when the request is sent, so large payloads don't have to be inlined
+// and may be shared by requests
+type BodyInclude struct {
+	// File to read, relative to the .http file
+	Path string `json:"path"`
+
+	// Whether the variables of the file are replaced, from "<@"
+	Variables bool `json:"variables,omitempty"`
+
+	// Line of the include in the .http file, starting at 1
+	Line int `json:"line,omitempty"`
+}
+
+// ParseBodyInclude parses a body line including a file, e.g.
+// "< ./payloads/user.json" or "<@ ./payloads/user.json"
+func ParseBodyInclude(line string) (BodyInclude, bool) {
+	matches := bodyIncludePattern.FindStringSubmatch(line)
+	if matches == nil {
+		return BodyInclude{}, false
+	}
+	return BodyInclude{Path: matches[2], Variables: matches[1] == "@"}, true
+}
+
+// String returns the include as written in a body
+func (b BodyInclude) String() string {
+	if b.Variables {
+		return "<@ " + b.Path
+	}
+	return "< " + b.Path
+}
+
+// Resolve returns the path of the included file, resolving a relative path
+// against the directory of the .http file
+func (b BodyInclude) Resolve(httpFile string) string {
+	if filepath.IsAbs(b.Path) || httpFile == "" {
+		return b.Path
+	}
+	return filepath.Join(filepath.Dir(httpFile), b.Path)
+}
+
+// ExpandBodyIncludes replaces the include lines of a body with the content of
+// their files, read relative to the .http file. The content of "<@" includes
+// is passed to substitute to replace its variables; that of "<" includes is
+// sent as is.
+func ExpandBodyIncludes(body, httpFile string, substitute func(string) (string, error)) (string, error) {
+	if !strings.Contains(body, "<") {
+		return body, nil
+	}
+
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		include, ok := ParseBodyInclude(strings.TrimSuffix(line, "\r"))
+		if !ok {
+			continue
+		}
+		data, err := os.ReadFile(include.Resolve(httpFile))
+		if err != nil {
+			return "", fmt.Errorf("failed to read request body from %s: %w", include.Path, err)
+		}
+		content := string(data)
+		if include.Variables {
+			if content, err = substitute(content); err != nil {
+				return "", fmt.Errorf("failed to render request body from %s: %w", include.Path, err)
+			}
+		}
+		lines[i] = content
+	}
+	return strings.Join(lines, "\n"), nil
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBodyInclude(t *testing.T) {
+	include, ok := ParseBodyInclude("< ./payloads/user.json")
+	require.True(t, ok)
+	assert.Equal(t, BodyInclude{Path: "./payloads/user.json"}, include)
+	assert.Equal(t, "< ./payloads/user.json", include.String())
+
+	include, ok = ParseBodyInclude("<@ payloads/user.json ")
+	require.True(t, ok)
+	assert.Equal(t, BodyInclude{Path: "payloads/user.json", Variables: true}, include)
+	assert.Equal(t, "<@ payloads/user.json", include.String())
+
+	for _, line := range []string{"<user><name>Ada</name></user>", "<", "< ", "<@", "# < file.json"} {
+		_, ok := ParseBodyInclude(line)
+		assert.False(t, ok, line)
+	}
+}
+
+func TestExpandBodyIncludes(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "payloads"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "payloads", "user.json"), []byte(`{"name": "{{name}}"}`), 0644))
+	httpFile := filepath.Join(dir, "users.http")
+	substitute := func(text string) (string, error) {
+		return strings.ReplaceAll(text, "{{name}}", "Ada"), nil
+	}
+
+	body, err := ExpandBodyIncludes("< ./payloads/user.json", httpFile, substitute)
+	require.NoError(t, err)
+	assert.Equal(t, `{"name": "{{name}}"}`, body)
+
+	body, err = ExpandBodyIncludes("<@ payloads/user.json", httpFile, substitute)
+	require.NoError(t, err)
+	assert.Equal(t, `{"name": "Ada"}`, body)
+
+	body, err = ExpandBodyIncludes("--boundary\n< ./payloads/user.json\n--boundary--", httpFile, substitute)
+	require.NoError(t, err)
+	assert.Equal(t, "--boundary\n{\"name\": \"{{name}}\"}\n--boundary--", body)
+
+	_, err = ExpandBodyIncludes("< missing.json", httpFile, substitute)
+	assert.ErrorContains(t, err, "failed to read request body from missing.json")
+}
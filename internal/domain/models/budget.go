@@ -0,0 +1,214 @@
+package models
+
+import (
+	"fmt"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// BudgetAssertionType is the type of the assertions checking budgets
+const BudgetAssertionType = "budget"
+
+// BudgetAllTags is the tag of budgets applying to every test
+const BudgetAllTags = "*"
+
+// Budget limits the responses of the tests with a tag. Budgets are checked as
+// assertions of every test they apply to.
+type Budget struct {
+	Tag             string   `json:"tag"`
+	MaxBodyBytes    int64    `json:"maxBodyBytes,omitempty"`    // Largest body allowed, 0 for any size
+	ContentEncoding string   `json:"contentEncoding,omitempty"` // Content-Encoding the server must use, e.g. gzip
+	ContentTypes    []string `json:"contentTypes,omitempty"`    // Media types allowed, any when empty
+}
+
+// BudgetViolation is a budget a test exceeded
+type BudgetViolation struct {
+	Test     string `json:"test"`
+	FilePath string `json:"filePath,omitempty"`
+	Error    string `json:"error"`
+}
+
+// ParseBudgets reads budgets from configuration, keyed by tag:
+//
+//	catalog:
+//	  max_body_size: 200KB
+//	  content_encoding: gzip
+//	  content_type: [application/json]
+func ParseBudgets(config map[string]interface{}) ([]Budget, error) {
+	tags := make([]string, 0, len(config))
+	for tag := range config {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	var budgets []Budget
+	for _, tag := range tags {
+		settings, ok := config[tag].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("budget %s: expected a map of limits", tag)
+		}
+
+		budget := Budget{Tag: tag}
+		for key, value := range settings {
+			switch key {
+			case "max_body_size":
+				size, err := ParseByteSize(fmt.Sprint(value))
+				if err != nil {
+					return nil, fmt.Errorf("budget %s: %w", tag, err)
+				}
+				budget.MaxBodyBytes = size
+			case "content_encoding":
+				budget.ContentEncoding = strings.ToLower(fmt.Sprint(value))
+			case "content_type":
+				switch v := value.(type) {
+				case []interface{}:
+					for _, contentType := range v {
+						budget.ContentTypes = append(budget.ContentTypes, fmt.Sprint(contentType))
+					}
+				default:
+					budget.ContentTypes = append(budget.ContentTypes, fmt.Sprint(v))
+				}
+			default:
+				return nil, fmt.Errorf("budget %s: unknown limit %q", tag, key)
+			}
+		}
+		budgets = append(budgets, budget)
+	}
+	return budgets, nil
+}
+
+// ParseByteSize parses a size such as 512, 200KB or 1.5MB. Units are powers
+// of 1024.
+func ParseByteSize(value string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(value))
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		bytes  int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}} {
+		if strings.HasSuffix(s, unit.suffix) {
+			s = strings.TrimSpace(strings.TrimSuffix(s, unit.suffix))
+			multiplier = unit.bytes
+			break
+		}
+	}
+
+	number, err := strconv.ParseFloat(s, 64)
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf("invalid size %q", value)
+	}
+	return int64(number * float64(multiplier)), nil
+}
+
+// FormatByteSize formats a number of bytes with the largest unit it has
+func FormatByteSize(bytes int64) string {
+	switch {
+	case bytes >= 1<<30:
+		return strconv.FormatFloat(float64(bytes)/(1<<30), 'f', -1, 64) + "GB"
+	case bytes >= 1<<20:
+		return strconv.FormatFloat(float64(bytes)/(1<<20), 'f', -1, 64) + "MB"
+	case bytes >= 1<<10:
+		return strconv.FormatFloat(float64(bytes)/(1<<10), 'f', -1, 64) + "KB"
+	}
+	return strconv.FormatInt(bytes, 10) + "B"
+}
+
+// Applies reports whether the budget applies to a test with the given tags
+func (b Budget) Applies(tags []string) bool {
+	if b.Tag == BudgetAllTags {
+		return true
+	}
+	for _, tag := range tags {
+		// Configuration keys are case-insensitive
+		if strings.EqualFold(tag, b.Tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check returns an assertion per limit of the budget, checked against a response
+func (b Budget) Check(response *HTTPResponse) []TestAssertionResult {
+	var assertions []TestAssertionResult
+
+	if b.MaxBodyBytes > 0 {
+		size := int64(len(response.Body))
+		assertion := TestAssertionResult{
+			Type:        BudgetAssertionType,
+			Source:      "body",
+			Expected:    b.MaxBodyBytes,
+			Actual:      size,
+			Description: fmt.Sprintf("%s budget: body is at most %s", b.Tag, FormatByteSize(b.MaxBodyBytes)),
+			Passed:      size <= b.MaxBodyBytes,
+		}
+		if !assertion.Passed {
+			assertion.Error = fmt.Sprintf("%s budget: body is %s, over %s", b.Tag, FormatByteSize(size), FormatByteSize(b.MaxBodyBytes))
+		}
+		assertions = append(assertions, assertion)
+	}
+
+	if b.ContentEncoding != "" {
+		encoding := response.Encoding()
+		assertion := TestAssertionResult{
+			Type:        BudgetAssertionType,
+			Source:      "header",
+			Path:        "Content-Encoding",
+			Expected:    b.ContentEncoding,
+			Actual:      encoding,
+			Description: fmt.Sprintf("%s budget: body is %s encoded", b.Tag, b.ContentEncoding),
+			Passed:      strings.EqualFold(encoding, b.ContentEncoding),
+		}
+		if !assertion.Passed {
+			if encoding == "" {
+				encoding = "none"
+			}
+			assertion.Error = fmt.Sprintf("%s budget: expected %s content encoding, got %s", b.Tag, b.ContentEncoding, encoding)
+		}
+		assertions = append(assertions, assertion)
+	}
+
+	if len(b.ContentTypes) > 0 {
+		contentType := response.ContentType
+		if contentType == "" {
+			for name, values := range response.Headers {
+				if strings.EqualFold(name, "Content-Type") && len(values) > 0 {
+					contentType = values[0]
+				}
+			}
+		}
+		mediaType, _, _ := mime.ParseMediaType(contentType)
+		assertion := TestAssertionResult{
+			Type:        BudgetAssertionType,
+			Source:      "header",
+			Path:        "Content-Type",
+			Expected:    b.ContentTypes,
+			Actual:      mediaType,
+			Description: fmt.Sprintf("%s budget: content type is %s", b.Tag, strings.Join(b.ContentTypes, " or ")),
+		}
+		for _, allowed := range b.ContentTypes {
+			if strings.EqualFold(mediaType, allowed) {
+				assertion.Passed = true
+			}
+		}
+		if !assertion.Passed {
+			assertion.Error = fmt.Sprintf("%s budget: content type %q is not %s", b.Tag, mediaType, strings.Join(b.ContentTypes, " or "))
+		}
+		assertions = append(assertions, assertion)
+	}
+
+	return assertions
+}
+
+// CheckBudgets checks a response against the budgets applying to a test with
+// the given tags
+func CheckBudgets(budgets []Budget, tags []string, response *HTTPResponse) []TestAssertionResult {
+	var assertions []TestAssertionResult
+	for _, budget := range budgets {
+		if budget.Applies(tags) {
+			assertions = append(assertions, budget.Check(response)...)
+		}
+	}
+	return assertions
+}
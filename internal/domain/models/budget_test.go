@@ -0,0 +1,102 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected int64
+		wantErr  bool
+	}{
+		{"512", 512, false},
+		{"200KB", 200 << 10, false},
+		{"1.5mb", 3 << 19, false},
+		{"2 GB", 2 << 30, false},
+		{"10B", 10, false},
+		{"big", 0, true},
+		{"-1KB", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			size, err := ParseByteSize(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, size)
+		})
+	}
+}
+
+func TestParseBudgets(t *testing.T) {
+	budgets, err := ParseBudgets(map[string]interface{}{
+		"catalog": map[string]interface{}{
+			"max_body_size":    "200KB",
+			"content_encoding": "GZIP",
+			"content_type":     []interface{}{"application/json"},
+		},
+		"*": map[string]interface{}{"max_body_size": 1048576},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []Budget{
+		{Tag: "*", MaxBodyBytes: 1 << 20},
+		{Tag: "catalog", MaxBodyBytes: 200 << 10, ContentEncoding: "gzip", ContentTypes: []string{"application/json"}},
+	}, budgets)
+
+	_, err = ParseBudgets(map[string]interface{}{"catalog": map[string]interface{}{"max_time": "1s"}})
+	assert.Error(t, err)
+	_, err = ParseBudgets(map[string]interface{}{"catalog": "200KB"})
+	assert.Error(t, err)
+}
+
+func TestCheckBudgets(t *testing.T) {
+	budgets := []Budget{
+		{Tag: "catalog", MaxBodyBytes: 1 << 10, ContentEncoding: "gzip", ContentTypes: []string{"application/json"}},
+		{Tag: "users", MaxBodyBytes: 10},
+	}
+	response := &HTTPResponse{
+		Headers: map[string][]string{"Content-Type": {"application/json; charset=utf-8"}},
+		Body:    strings.Repeat("x", 2048),
+	}
+
+	assertions := CheckBudgets(budgets, []string{"Catalog"}, response)
+	require.Len(t, assertions, 3)
+	assert.False(t, assertions[0].Passed)
+	assert.Equal(t, "catalog budget: body is 2KB, over 1KB", assertions[0].Error)
+	assert.False(t, assertions[1].Passed)
+	assert.Equal(t, "catalog budget: expected gzip content encoding, got none", assertions[1].Error)
+	assert.True(t, assertions[2].Passed)
+
+	response.ContentEncoding = "gzip"
+	response.Body = "{}"
+	for _, assertion := range CheckBudgets(budgets, []string{"catalog"}, response) {
+		assert.True(t, assertion.Passed, assertion.Description)
+	}
+
+	assert.Empty(t, CheckBudgets(budgets, []string{"orders"}, response))
+}
+
+func TestAddBreakdownsBudgetViolations(t *testing.T) {
+	var summary TestSummary
+	summary.AddBreakdowns([]TestResult{
+		{
+			Name:   "listProducts",
+			Status: TestStatusFailed,
+			AssertionResults: []TestAssertionResult{
+				{Type: "status", Passed: false, Error: "expected status 200, got 500"},
+				{Type: BudgetAssertionType, Passed: false, Error: "catalog budget: body is 2KB, over 1KB"},
+				{Type: BudgetAssertionType, Passed: true},
+			},
+		},
+	}, DefaultSlowestRequests)
+
+	assert.Equal(t, []BudgetViolation{{Test: "listProducts", Error: "catalog budget: body is 2KB, over 1KB"}}, summary.BudgetViolations)
+}
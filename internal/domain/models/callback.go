@@ -0,0 +1,90 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultCallbackTimeout is how long the test runner waits for a callback
+const DefaultCallbackTimeout = 30 * time.Second
+
+// CallbackExpectation is a callback the server is expected to send after a
+// request, declared with "# @callback <name> [METHOD] [timeout=<duration>] [fields=<a,b>]"
+type CallbackExpectation struct {
+	Name    string        `json:"name"`
+	Method  string        `json:"method,omitempty"`  // Expected method, any when empty
+	Timeout time.Duration `json:"timeout,omitempty"` // DefaultCallbackTimeout when zero
+	Fields  []string      `json:"fields,omitempty"`  // Top-level JSON fields the payload must have
+}
+
+// ReceivedCallback is a callback received by the test runner's listener
+type ReceivedCallback struct {
+	Name       string              `json:"name"`
+	Method     string              `json:"method"`
+	Path       string              `json:"path"`
+	Headers    map[string][]string `json:"headers,omitempty"`
+	Body       string              `json:"body,omitempty"`
+	ReceivedAt time.Time           `json:"receivedAt"`
+}
+
+// CallbackVariable returns the variable set to the listener URL of a callback,
+// e.g. "callback.onEvent" used as "{{callback.onEvent}}"
+func CallbackVariable(name string) string {
+	return "callback." + name
+}
+
+// ParseCallbackDirective parses the argument of a "@callback" directive
+func ParseCallbackDirective(value string) (CallbackExpectation, error) {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return CallbackExpectation{}, fmt.Errorf("callback directive needs a name")
+	}
+
+	callback := CallbackExpectation{Name: fields[0]}
+	for _, field := range fields[1:] {
+		switch {
+		case strings.HasPrefix(field, "timeout="):
+			timeout, err := time.ParseDuration(strings.TrimPrefix(field, "timeout="))
+			if err != nil || timeout <= 0 {
+				return CallbackExpectation{}, fmt.Errorf("invalid callback timeout %q", field)
+			}
+			callback.Timeout = timeout
+		case strings.HasPrefix(field, "fields="):
+			for _, name := range strings.Split(strings.TrimPrefix(field, "fields="), ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					callback.Fields = append(callback.Fields, name)
+				}
+			}
+		case callback.Method == "" && !strings.Contains(field, "="):
+			callback.Method = strings.ToUpper(field)
+		default:
+			return CallbackExpectation{}, fmt.Errorf("invalid callback option %q", field)
+		}
+	}
+
+	return callback, nil
+}
+
+// TimeoutOrDefault returns the callback's timeout, or DefaultCallbackTimeout
+func (c CallbackExpectation) TimeoutOrDefault() time.Duration {
+	if c.Timeout > 0 {
+		return c.Timeout
+	}
+	return DefaultCallbackTimeout
+}
+
+// String formats the callback as the argument of a "@callback" directive
+func (c CallbackExpectation) String() string {
+	parts := []string{c.Name}
+	if c.Method != "" {
+		parts = append(parts, c.Method)
+	}
+	if c.Timeout > 0 {
+		parts = append(parts, "timeout="+c.Timeout.String())
+	}
+	if len(c.Fields) > 0 {
+		parts = append(parts, "fields="+strings.Join(c.Fields, ","))
+	}
+	return strings.Join(parts, " ")
+}
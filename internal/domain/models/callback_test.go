@@ -0,0 +1,40 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCallbackDirective(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    CallbackExpectation
+		wantErr bool
+	}{
+		{value: "onEvent", want: CallbackExpectation{Name: "onEvent"}},
+		{value: "onEvent post timeout=5s fields=id,status", want: CallbackExpectation{Name: "onEvent", Method: "POST", Timeout: 5 * time.Second, Fields: []string{"id", "status"}}},
+		{value: "", wantErr: true},
+		{value: "onEvent timeout=soon", wantErr: true},
+		{value: "onEvent POST PUT", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseCallbackDirective(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			// The formatted callback parses back to the same callback
+			again, err := ParseCallbackDirective(got.String())
+			require.NoError(t, err)
+			assert.Equal(t, got, again)
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package models
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// Checkpoint records the tests a run completed, one JSON line per result as
+// they complete, so that a run stopped by a crash or a cancellation can be
+// resumed without running them again
+type Checkpoint struct {
+	mu        sync.Mutex
+	file      *os.File
+	completed map[string]TestResult
+	err       error
+}
+
+// checkpointEntry is a line of a checkpoint file
+type checkpointEntry struct {
+	ID     string     `json:"id"`
+	Result TestResult `json:"result"`
+}
+
+// CheckpointID identifies a test of a run: its file, its name, and the
+// language and data row of the current run, if any
+func CheckpointID(request *HTTPRequest, options TestRunOptions) string {
+	id := request.Path + "#" + request.Name
+	if options.Language != "" {
+		id += "@lang=" + options.Language
+	}
+	if options.DataRow != nil {
+		id += "@row=" + options.DataRow.ID
+	}
+	return id
+}
+
+// OpenCheckpoint opens a checkpoint file, keeping the tests it recorded when
+// resuming a run and starting it over otherwise
+func OpenCheckpoint(filename string, resume bool) (*Checkpoint, error) {
+	checkpoint := &Checkpoint{completed: make(map[string]TestResult)}
+	if resume {
+		file, err := os.Open(filename)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to open checkpoint: %w", err)
+		}
+		if err == nil {
+			err = checkpoint.load(file)
+			file.Close()
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", filename, err)
+			}
+		}
+	}
+
+	// Rewrite the recorded tests, dropping a line cut short by a crash that
+	// the next ones would be appended to
+	file, err := os.Create(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checkpoint: %w", err)
+	}
+	checkpoint.file = file
+	for id, result := range checkpoint.completed {
+		if err := checkpoint.Record(id, result); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	return checkpoint, nil
+}
+
+// load reads the results of a checkpoint, ignoring a last line cut short by
+// a crash
+func (c *Checkpoint) load(r io.Reader) error {
+	reader := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("failed to read checkpoint: %w", err)
+		}
+		if len(data) > 0 {
+			var entry checkpointEntry
+			if jsonErr := json.Unmarshal(data, &entry); jsonErr != nil {
+				if err == io.EOF {
+					return nil
+				}
+				return fmt.Errorf("line %d: %w", line, jsonErr)
+			}
+			c.completed[entry.ID] = entry.Result
+		}
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
+
+// Completed returns the result a test completed with, if the checkpoint
+// recorded it
+func (c *Checkpoint) Completed(id string) (TestResult, bool) {
+	if c == nil {
+		return TestResult{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	result, ok := c.completed[id]
+	return result, ok
+}
+
+// Len returns the number of tests the checkpoint recorded
+func (c *Checkpoint) Len() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.completed)
+}
+
+// Record appends the result of a completed test to the checkpoint, safe to
+// call from several goroutines
+func (c *Checkpoint) Record(id string, result TestResult) error {
+	data, err := json.Marshal(checkpointEntry{ID: id, Result: result})
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint of %s: %w", id, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.completed[id] = result
+	if _, err := c.file.Write(append(data, '\n')); err != nil {
+		if c.err == nil {
+			c.err = fmt.Errorf("failed to write checkpoint: %w", err)
+		}
+		return c.err
+	}
+	return nil
+}
+
+// Err returns the first error writing the checkpoint, if any
+func (c *Checkpoint) Err() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Close closes the checkpoint file
+func (c *Checkpoint) Close() error {
+	if c == nil || c.file == nil {
+		return nil
+	}
+	return c.file.Close()
+}
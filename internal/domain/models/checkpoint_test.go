@@ -0,0 +1,57 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckpointID(t *testing.T) {
+	request := &HTTPRequest{Path: "users.http", Name: "getUser"}
+
+	assert.Equal(t, "users.http#getUser", CheckpointID(request, TestRunOptions{}))
+	assert.Equal(t, "users.http#getUser@lang=pt-BR@row=2", CheckpointID(request, TestRunOptions{Language: "pt-BR", DataRow: &DataRow{ID: "2"}}))
+}
+
+func TestCheckpointResume(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "run.checkpoint")
+
+	checkpoint, err := OpenCheckpoint(filename, false)
+	require.NoError(t, err)
+	require.NoError(t, checkpoint.Record("users.http#getUser", TestResult{Name: "getUser", Status: TestStatusPassed}))
+	require.NoError(t, checkpoint.Record("users.http#listUsers", TestResult{Name: "listUsers", Status: TestStatusFailed}))
+	require.NoError(t, checkpoint.Close())
+
+	// A crash can cut the last line short
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_APPEND, 0644)
+	require.NoError(t, err)
+	_, err = file.WriteString(`{"id":"users.http#deleteUser","res`)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	resumed, err := OpenCheckpoint(filename, true)
+	require.NoError(t, err)
+	defer resumed.Close()
+
+	assert.Equal(t, 2, resumed.Len())
+	result, ok := resumed.Completed("users.http#listUsers")
+	assert.True(t, ok)
+	assert.Equal(t, TestStatusFailed, result.Status)
+	_, ok = resumed.Completed("users.http#deleteUser")
+	assert.False(t, ok)
+
+	// The tests completed after resuming are recorded after the others
+	require.NoError(t, resumed.Record("users.http#deleteUser", TestResult{Name: "deleteUser", Status: TestStatusPassed}))
+	again, err := OpenCheckpoint(filename, true)
+	require.NoError(t, err)
+	assert.Equal(t, 3, again.Len())
+	require.NoError(t, again.Close())
+
+	restarted, err := OpenCheckpoint(filename, false)
+	require.NoError(t, err)
+	defer restarted.Close()
+	assert.Equal(t, 0, restarted.Len())
+}
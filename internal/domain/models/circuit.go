@@ -0,0 +1,35 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// CircuitBreakerOptions configures the per-host circuit breaker of the
+// executor: once a host fails Threshold requests in a row with connection
+// errors, the following requests to it fail fast until CoolDown has passed.
+// A zero threshold turns the breaker off.
+type CircuitBreakerOptions struct {
+	Threshold int           `json:"threshold"`          // Consecutive connection errors that open the circuit
+	CoolDown  time.Duration `json:"coolDown,omitempty"` // Time an open circuit fails requests before one is let through again
+}
+
+// DefaultCircuitBreakerOptions opens the circuit of a host after 5
+// connection errors in a row for 30 seconds
+func DefaultCircuitBreakerOptions() CircuitBreakerOptions {
+	return CircuitBreakerOptions{Threshold: 5, CoolDown: 30 * time.Second}
+}
+
+// CircuitOpenError is a request that wasn't sent because the circuit of its
+// host is open
+type CircuitOpenError struct {
+	Host     string
+	Failures int       // Consecutive connection errors that opened the circuit
+	Until    time.Time // End of the cool-down, when a request is let through again
+}
+
+// Error describes the host and why its circuit is open
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit open for %s after %d consecutive connection errors, retrying after %s",
+		e.Host, e.Failures, e.Until.Format(time.RFC3339))
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// LocationVariable is set to the Location header of a step's response when
+// its cleanup request is registered
+const LocationVariable = "location"
+
+// CleanupRequest is a request deleting a resource created by a sequence step
+type CleanupRequest struct {
+	Step   string `json:"step"`
+	Method string `json:"method"`
+	URL    string `json:"url"`
+}
+
+// CleanupResult is the outcome of a cleanup request
+type CleanupResult struct {
+	CleanupRequest
+	Status     TestStatus    `json:"status"`
+	StatusCode int           `json:"statusCode,omitempty"`
+	Duration   time.Duration `json:"duration,omitempty"`
+	Error      string        `json:"error,omitempty"`
+}
+
+// ParseCleanup parses a registerCleanup value, "[METHOD] <url>", where the
+// method defaults to DELETE
+func ParseCleanup(value string) (method, url string, err error) {
+	fields := strings.Fields(value)
+	switch len(fields) {
+	case 1:
+		return http.MethodDelete, fields[0], nil
+	case 2:
+		return strings.ToUpper(fields[0]), fields[1], nil
+	}
+	return "", "", fmt.Errorf("invalid cleanup %q: use [METHOD] <url>, e.g. DELETE {{location}}", value)
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Content encodings
+const (
+	EncodingGzip     = "gzip"
+	EncodingDeflate  = "deflate"
+	EncodingBrotli   = "br"
+	EncodingIdentity = "identity"
+)
+
+// CompressionOptions configures how the executor negotiates and decodes
+// compressed response bodies
+type CompressionOptions struct {
+	AcceptEncoding string `json:"acceptEncoding,omitempty"` // Accept-Encoding of requests without one, none when empty
+	Decompress     bool   `json:"decompress"`               // Decode gzip and deflate bodies before they are checked
+}
+
+// DefaultCompressionOptions asks for gzip or deflate bodies and decodes them
+func DefaultCompressionOptions() CompressionOptions {
+	return CompressionOptions{AcceptEncoding: "gzip, deflate", Decompress: true}
+}
+
+// ParseExpectedEncoding parses the argument of an "@expect-encoding"
+// directive: a content encoding such as "gzip", or "identity" for bodies sent
+// without one
+func ParseExpectedEncoding(value string) (string, error) {
+	encoding := strings.ToLower(strings.TrimSpace(value))
+	if encoding == "" || strings.ContainsAny(encoding, " ,;") {
+		return "", fmt.Errorf("invalid expected encoding %q: use a single encoding such as gzip, br or identity", value)
+	}
+	if encoding == "none" {
+		encoding = EncodingIdentity
+	}
+	return encoding, nil
+}
+
+// EncodingMatches reports whether the content encoding a server used matches
+// an expected encoding, where "identity" stands for none
+func EncodingMatches(expected, actual string) bool {
+	if actual == "" {
+		actual = EncodingIdentity
+	}
+	return strings.EqualFold(expected, actual)
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExpectedEncoding(t *testing.T) {
+	encoding, err := ParseExpectedEncoding(" GZIP ")
+	require.NoError(t, err)
+	assert.Equal(t, EncodingGzip, encoding)
+
+	encoding, err = ParseExpectedEncoding("none")
+	require.NoError(t, err)
+	assert.Equal(t, EncodingIdentity, encoding)
+
+	_, err = ParseExpectedEncoding("gzip, br")
+	assert.Error(t, err)
+	_, err = ParseExpectedEncoding("")
+	assert.Error(t, err)
+}
+
+func TestEncodingMatches(t *testing.T) {
+	assert.True(t, EncodingMatches("gzip", "GZIP"))
+	assert.True(t, EncodingMatches(EncodingIdentity, ""))
+	assert.False(t, EncodingMatches("br", "gzip"))
+	assert.False(t, EncodingMatches("gzip", ""))
+}
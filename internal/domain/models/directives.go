@@ -0,0 +1,113 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DirectiveLines returns the directive lines of a request, such as
+// "# @name createUser", as written before its request line. The name is left
+// out when it was derived rather than set with "@name".
+func (r *HTTPRequest) DirectiveLines() []string {
+	var lines []string
+	add := func(format string, args ...interface{}) {
+		lines = append(lines, strings.TrimSpace(fmt.Sprintf(format, args...)))
+	}
+
+	if r.Name != "" && !r.DerivedName {
+		add("# @name %s", r.Name)
+	}
+	if r.Tag != "" {
+		add("# @tag %s", r.Tag)
+	}
+	if len(r.DependsOn) > 0 {
+		add("# @depends-on %s", strings.Join(r.DependsOn, ", "))
+	}
+	if len(r.Scopes) > 0 {
+		add("# @scopes %s", strings.Join(r.Scopes, " "))
+	}
+	if r.Deprecated {
+		add("# @deprecated")
+	}
+	if r.Safe {
+		add("# @safe")
+	}
+	if r.SerialGroup != "" {
+		add("# @serial-group %s", r.SerialGroup)
+	}
+	if r.Skip {
+		add("# @skip %s", r.SkipReason)
+	}
+	if r.Only {
+		add("# @only")
+	}
+	if r.Quarantined {
+		add("# @quarantine %s", r.QuarantineReason)
+	}
+	if r.Priority > 0 {
+		add("# @priority %d", r.Priority)
+	}
+	if r.RateLimit != nil {
+		add("# @rate-limit %s", r.RateLimit)
+	}
+	for _, callback := range r.Callbacks {
+		add("# @callback %s", callback)
+	}
+	if r.Poll != nil {
+		add("# @poll %s", r.Poll)
+	}
+	if r.Redirects != nil {
+		add("# @redirects %s", r.Redirects)
+	}
+	if r.Paginate != nil {
+		add("# @paginate %s", r.Paginate)
+	}
+	if r.ExpectStatus != "" {
+		add("# @expect-status %s", r.ExpectStatus)
+	}
+	if r.XSD != "" {
+		add("# @xsd %s", r.XSD)
+	}
+	if r.ExpectEncoding != "" {
+		add("# @expect-encoding %s", r.ExpectEncoding)
+	}
+	for _, header := range r.ExpectHeaders {
+		add("# @expect-header %s", header)
+	}
+	if r.SnapshotCompare != SnapshotScopeAll {
+		add("# @snapshot-compare %s", r.SnapshotCompare)
+	}
+	if len(r.IgnoreHeaders) > 0 {
+		add("# @ignore-headers %s", strings.Join(r.IgnoreHeaders, ", "))
+	}
+	if r.SnapshotTransform != "" {
+		add("# @snapshot-transform %s", r.SnapshotTransform)
+	}
+	if r.IgnoreArrayOrder {
+		add("# @ignore-array-order %s", r.ArrayOrderKey)
+	}
+	paths := make([]string, 0, len(r.Tolerances))
+	for path := range r.Tolerances {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		add("# @approx %s %s", path, r.Tolerances[path])
+	}
+	return lines
+}
+
+// DirectiveKind returns the kind of a directive line, e.g. "name" for
+// "# @name createUser", or "" when it isn't one
+func DirectiveKind(line string) string {
+	text := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "#"))
+	if !strings.HasPrefix(text, "@") {
+		return ""
+	}
+	fields := strings.Fields(text[1:])
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPRequestDirectiveLines(t *testing.T) {
+	request := &HTTPRequest{
+		Name:          "createUser",
+		Tag:           "users,admin",
+		DependsOn:     []string{"login", "setup"},
+		Scopes:        []string{"read:users", "write:users"},
+		Skip:          true,
+		Priority:      2,
+		Tolerances:    map[string]Tolerance{"$.total": {Absolute: 0.01}, "$.avg": {Relative: 0.1}},
+		ExpectHeaders: []ExpectedHeader{{Name: "X-Request-Id", Value: "<<uuid>>"}},
+	}
+
+	assert.Equal(t, []string{
+		"# @name createUser",
+		"# @tag users,admin",
+		"# @depends-on login, setup",
+		"# @scopes read:users write:users",
+		"# @skip",
+		"# @priority 2",
+		"# @expect-header X-Request-Id: <<uuid>>",
+		"# @approx $.avg rel=0.1",
+		"# @approx $.total abs=0.01",
+	}, request.DirectiveLines())
+
+	request = &HTTPRequest{Name: "GET /users", DerivedName: true}
+	assert.Empty(t, request.DirectiveLines())
+}
+
+func TestDirectiveKind(t *testing.T) {
+	assert.Equal(t, "name", DirectiveKind("# @name createUser"))
+	assert.Equal(t, "tag", DirectiveKind("@tag users"))
+	assert.Equal(t, "deprecated", DirectiveKind("  #  @deprecated"))
+	assert.Equal(t, "", DirectiveKind("# a comment"))
+	assert.Equal(t, "", DirectiveKind("# @"))
+}
@@ -0,0 +1,7 @@
+package models
+
+// Environment is a named deployment of the API that tests can run against
+type Environment struct {
+	Name    string `json:"name"`
+	BaseURL string `json:"baseUrl"`
+}
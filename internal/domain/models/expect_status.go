@@ -0,0 +1,29 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// expectedStatusPattern matches a status code, e.g. "201", or a class, e.g. "2xx"
+var expectedStatusPattern = regexp.MustCompile(`^[1-5](\d\d|xx)$`)
+
+// ParseExpectedStatus parses the argument of an "@expect-status" directive: a
+// status code such as "201" or a class such as "2xx"
+func ParseExpectedStatus(value string) (string, error) {
+	status := strings.ToLower(strings.TrimSpace(value))
+	if !expectedStatusPattern.MatchString(status) {
+		return "", fmt.Errorf("invalid expected status %q: use a code such as 201 or a class such as 2xx", value)
+	}
+	return status, nil
+}
+
+// StatusMatches reports whether a status code matches an expected status code or class
+func StatusMatches(expected string, code int) bool {
+	if strings.HasSuffix(expected, "xx") {
+		return strconv.Itoa(code/100) == expected[:1]
+	}
+	return strconv.Itoa(code) == expected
+}
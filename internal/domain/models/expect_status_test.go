@@ -0,0 +1,44 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExpectedStatus(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+		wantErr  bool
+	}{
+		{value: "201", expected: "201"},
+		{value: " 2XX ", expected: "2xx"},
+		{value: "404", expected: "404"},
+		{value: "", wantErr: true},
+		{value: "20", wantErr: true},
+		{value: "600", wantErr: true},
+		{value: "2x1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			status, err := ParseExpectedStatus(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, status)
+		})
+	}
+}
+
+func TestStatusMatches(t *testing.T) {
+	assert.True(t, StatusMatches("201", 201))
+	assert.False(t, StatusMatches("201", 200))
+	assert.True(t, StatusMatches("2xx", 204))
+	assert.False(t, StatusMatches("2xx", 301))
+	assert.True(t, StatusMatches("5xx", 503))
+}
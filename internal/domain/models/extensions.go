@@ -0,0 +1,242 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ExtensionPrefix starts the keys of vendor extensions, e.g. "x-internal"
+const ExtensionPrefix = "x-"
+
+// Extensions holds the vendor extensions of a spec object, keyed by their full
+// name including the "x-" prefix. Values are decoded as plain JSON values.
+type Extensions map[string]interface{}
+
+// Has reports whether the extension is set
+func (e Extensions) Has(name string) bool {
+	_, ok := e[name]
+	return ok
+}
+
+// Bool reports whether the extension is set to true, as a boolean or a string
+func (e Extensions) Bool(name string) bool {
+	switch v := e[name].(type) {
+	case bool:
+		return v
+	case string:
+		return strings.EqualFold(v, "true")
+	}
+	return false
+}
+
+// Merge returns the extensions of e overridden by those of other, e.g. the
+// extensions of a path item overridden by those of one of its operations
+func (e Extensions) Merge(other Extensions) Extensions {
+	if len(e) == 0 {
+		return other
+	}
+	if len(other) == 0 {
+		return e
+	}
+	merged := make(Extensions, len(e)+len(other))
+	for name, value := range e {
+		merged[name] = value
+	}
+	for name, value := range other {
+		merged[name] = value
+	}
+	return merged
+}
+
+// extensionsFromJSON returns the vendor extensions of a JSON object
+func extensionsFromJSON(data []byte) (Extensions, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	var extensions Extensions
+	for key, raw := range fields {
+		if !strings.HasPrefix(key, ExtensionPrefix) {
+			continue
+		}
+		var value interface{}
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil, err
+		}
+		if extensions == nil {
+			extensions = make(Extensions)
+		}
+		extensions[key] = value
+	}
+	return extensions, nil
+}
+
+// extensionsFromYAML returns the vendor extensions of a YAML mapping
+func extensionsFromYAML(node *yaml.Node) (Extensions, error) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	var extensions Extensions
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i].Value
+		if !strings.HasPrefix(key, ExtensionPrefix) {
+			continue
+		}
+		var value interface{}
+		if err := node.Content[i+1].Decode(&value); err != nil {
+			return nil, err
+		}
+		if extensions == nil {
+			extensions = make(Extensions)
+		}
+		extensions[key] = value
+	}
+	return extensions, nil
+}
+
+// The spec objects below keep their vendor extensions when decoded. Each decodes
+// itself through a local type without the methods, then collects the "x-" keys.
+
+// UnmarshalJSON decodes the document and keeps its vendor extensions
+func (d *SwaggerDoc) UnmarshalJSON(data []byte) error {
+	type plain SwaggerDoc
+	if err := json.Unmarshal(data, (*plain)(d)); err != nil {
+		return err
+	}
+	extensions, err := extensionsFromJSON(data)
+	d.Extensions = extensions
+	return err
+}
+
+// UnmarshalYAML decodes the document and keeps its vendor extensions
+func (d *SwaggerDoc) UnmarshalYAML(node *yaml.Node) error {
+	type plain SwaggerDoc
+	if err := node.Decode((*plain)(d)); err != nil {
+		return err
+	}
+	extensions, err := extensionsFromYAML(node)
+	d.Extensions = extensions
+	return err
+}
+
+// UnmarshalJSON decodes the path item and keeps its vendor extensions
+func (p *PathItem) UnmarshalJSON(data []byte) error {
+	type plain PathItem
+	if err := json.Unmarshal(data, (*plain)(p)); err != nil {
+		return err
+	}
+	extensions, err := extensionsFromJSON(data)
+	p.Extensions = extensions
+	return err
+}
+
+// UnmarshalYAML decodes the path item and keeps its vendor extensions
+func (p *PathItem) UnmarshalYAML(node *yaml.Node) error {
+	type plain PathItem
+	if err := node.Decode((*plain)(p)); err != nil {
+		return err
+	}
+	extensions, err := extensionsFromYAML(node)
+	p.Extensions = extensions
+	return err
+}
+
+// UnmarshalJSON decodes the operation and keeps its vendor extensions
+func (o *Operation) UnmarshalJSON(data []byte) error {
+	type plain Operation
+	if err := json.Unmarshal(data, (*plain)(o)); err != nil {
+		return err
+	}
+	extensions, err := extensionsFromJSON(data)
+	o.Extensions = extensions
+	return err
+}
+
+// UnmarshalYAML decodes the operation and keeps its vendor extensions
+func (o *Operation) UnmarshalYAML(node *yaml.Node) error {
+	type plain Operation
+	if err := node.Decode((*plain)(o)); err != nil {
+		return err
+	}
+	extensions, err := extensionsFromYAML(node)
+	o.Extensions = extensions
+	return err
+}
+
+// UnmarshalJSON decodes the parameter and keeps its vendor extensions
+func (p *Parameter) UnmarshalJSON(data []byte) error {
+	type plain Parameter
+	if err := json.Unmarshal(data, (*plain)(p)); err != nil {
+		return err
+	}
+	extensions, err := extensionsFromJSON(data)
+	p.Extensions = extensions
+	return err
+}
+
+// UnmarshalYAML decodes the parameter and keeps its vendor extensions
+func (p *Parameter) UnmarshalYAML(node *yaml.Node) error {
+	type plain Parameter
+	if err := node.Decode((*plain)(p)); err != nil {
+		return err
+	}
+	extensions, err := extensionsFromYAML(node)
+	p.Extensions = extensions
+	return err
+}
+
+// UnmarshalJSON decodes the schema and keeps its vendor extensions. A type
+// listing "null", as OpenAPI 3.1 allows, makes the schema nullable.
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	type plain Schema
+	decoded := struct {
+		*plain
+		Type interface{} `json:"type,omitempty"`
+	}{plain: (*plain)(s)}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	s.Type, s.Nullable = splitSchemaType(decoded.Type, s.Nullable)
+	extensions, err := extensionsFromJSON(data)
+	s.Extensions = extensions
+	return err
+}
+
+// MarshalJSON encodes the schema along with its vendor extensions
+func (s Schema) MarshalJSON() ([]byte, error) {
+	type plain Schema
+	data, err := json.Marshal(plain(s))
+	if err != nil || len(s.Extensions) == 0 {
+		return data, err
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for name, value := range s.Extensions {
+		fields[name] = value
+	}
+	return json.Marshal(fields)
+}
+
+// UnmarshalYAML decodes the schema and keeps its vendor extensions. A type
+// listing "null", as OpenAPI 3.1 allows, makes the schema nullable.
+func (s *Schema) UnmarshalYAML(node *yaml.Node) error {
+	type plain Schema
+	node, nullable := scalarTypeNode(node)
+	if err := node.Decode((*plain)(s)); err != nil {
+		return err
+	}
+	s.Nullable = s.Nullable || nullable
+	extensions, err := extensionsFromYAML(node)
+	s.Extensions = extensions
+	return err
+}
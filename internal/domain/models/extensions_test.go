@@ -0,0 +1,127 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const extensionsYAML = `openapi: 3.0.0
+x-owner: payments
+info:
+  title: Extensions
+  version: "1"
+paths:
+  /users:
+    x-internal: false
+    get:
+      operationId: listUsers
+      x-internal: true
+      x-rate-limit:
+        requests: 10
+        period: 1m
+      parameters:
+        - name: page
+          in: query
+          x-example-values: [1, 2]
+      responses:
+        "200":
+          description: OK
+    post:
+      operationId: createUser
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                email:
+                  type: string
+                  x-faker: internet.email
+      responses:
+        "201":
+          description: Created
+`
+
+func TestExtensionsAreKept(t *testing.T) {
+	var fromYAML SwaggerDoc
+	require.NoError(t, yaml.Unmarshal([]byte(extensionsYAML), &fromYAML))
+
+	// Decode the same document from JSON
+	var generic interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(extensionsYAML), &generic))
+	data, err := json.Marshal(generic)
+	require.NoError(t, err)
+	var fromJSON SwaggerDoc
+	require.NoError(t, json.Unmarshal(data, &fromJSON))
+
+	for name, doc := range map[string]SwaggerDoc{"yaml": fromYAML, "json": fromJSON} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, "payments", doc.Extensions["x-owner"])
+			assert.Equal(t, "Extensions", doc.Info.Title)
+
+			users := doc.Paths["/users"]
+			assert.False(t, users.Extensions.Bool("x-internal"))
+			require.NotNil(t, users.Get)
+			assert.Equal(t, "listUsers", users.Get.OperationID)
+			assert.True(t, users.Get.Extensions.Bool("x-internal"))
+			assert.True(t, users.Get.Extensions.Has("x-rate-limit"))
+			assert.True(t, users.Extensions.Merge(users.Get.Extensions).Bool("x-internal"))
+			require.Len(t, users.Get.Parameters, 1)
+			assert.Len(t, users.Get.Parameters[0].Extensions["x-example-values"], 2)
+
+			email := users.Post.RequestBody.Content["application/json"].Schema.Properties["email"]
+			assert.Equal(t, "string", email.Type)
+			assert.Equal(t, "internet.email", email.Extensions["x-faker"])
+			assert.Nil(t, users.Post.Extensions)
+		})
+	}
+}
+
+func TestParseRateLimit(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    RateLimit
+		wantErr bool
+	}{
+		{value: "10/s", want: RateLimit{Requests: 10, Per: time.Second}},
+		{value: "100 / minute", want: RateLimit{Requests: 100, Per: time.Minute}},
+		{value: "5/30s", want: RateLimit{Requests: 5, Per: 30 * time.Second}},
+		{value: "10", wantErr: true},
+		{value: "0/s", wantErr: true},
+		{value: "10/fortnight", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseRateLimit(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			// The formatted limit parses back to the same limit
+			again, err := ParseRateLimit(got.String())
+			require.NoError(t, err)
+			assert.Equal(t, got, again)
+		})
+	}
+
+	assert.Equal(t, 6*time.Second, RateLimit{Requests: 10, Per: time.Minute}.Interval())
+}
+
+func TestSchemaExtensionsAreEncoded(t *testing.T) {
+	data, err := json.Marshal(&Schema{Type: "string", Extensions: Extensions{"x-format": "iban"}})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type": "string", "x-format": "iban"}`, string(data))
+
+	var decoded Schema
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, "iban", decoded.Extensions["x-format"])
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// MethodGRPC is the request method of gRPC calls in .http files, e.g.
+// "GRPC https://gateway.example.com/acme.users.v1.UserService/GetUser"
+// through a transcoding gateway or "GRPC grpc://localhost:50051/..." natively
+const MethodGRPC = "GRPC"
+
+// GRPCStatusHeader is the response header holding the gRPC status code of a call
+const GRPCStatusHeader = "Grpc-Status"
+
+// grpcPathPattern matches the "/<package>.<Service>/<Method>" path of a gRPC call
+var grpcPathPattern = regexp.MustCompile(`/([A-Za-z_][\w.]*)/([A-Za-z_]\w*)$`)
+
+// grpcCodes are the gRPC status codes by the names JSON error bodies use
+var grpcCodes = map[string]int{
+	"ok":                  0,
+	"canceled":            1,
+	"cancelled":           1,
+	"unknown":             2,
+	"invalid_argument":    3,
+	"deadline_exceeded":   4,
+	"not_found":           5,
+	"already_exists":      6,
+	"permission_denied":   7,
+	"resource_exhausted":  8,
+	"failed_precondition": 9,
+	"aborted":             10,
+	"out_of_range":        11,
+	"unimplemented":       12,
+	"internal":            13,
+	"unavailable":         14,
+	"data_loss":           15,
+	"unauthenticated":     16,
+}
+
+// ParseGRPCTarget returns the fully qualified service and the method a gRPC
+// call URL points at
+func ParseGRPCTarget(rawURL string) (service, method string, err error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid gRPC URL %q: %w", rawURL, err)
+	}
+	matches := grpcPathPattern.FindStringSubmatch(parsed.Path)
+	if matches == nil || !strings.Contains(matches[1], ".") {
+		return "", "", fmt.Errorf("invalid gRPC URL %q: the path must be /<package>.<Service>/<Method>", rawURL)
+	}
+	return matches[1], matches[2], nil
+}
+
+// IsNativeGRPCURL reports whether a gRPC call URL is called natively, over
+// plaintext with grpc:// or TLS with grpcs://, rather than through a gateway
+func IsNativeGRPCURL(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	return parsed.Scheme == "grpc" || parsed.Scheme == "grpcs"
+}
+
+// GRPCCode returns the gRPC status code of a status name such as "NOT_FOUND"
+// or "not_found"
+func GRPCCode(name string) (int, bool) {
+	code, ok := grpcCodes[strings.ToLower(name)]
+	return code, ok
+}
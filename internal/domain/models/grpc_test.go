@@ -0,0 +1,41 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGRPCTarget(t *testing.T) {
+	service, method, err := ParseGRPCTarget("https://gateway.example.com/acme.users.v1.UserService/GetUser")
+	assert.NoError(t, err)
+	assert.Equal(t, "acme.users.v1.UserService", service)
+	assert.Equal(t, "GetUser", method)
+
+	service, method, err = ParseGRPCTarget("grpc://localhost:50051/grpc.health.v1.Health/Check")
+	assert.NoError(t, err)
+	assert.Equal(t, "grpc.health.v1.Health", service)
+	assert.Equal(t, "Check", method)
+
+	_, _, err = ParseGRPCTarget("https://gateway.example.com/users/42")
+	assert.Error(t, err)
+
+	_, _, err = ParseGRPCTarget("https://gateway.example.com/UserService/GetUser")
+	assert.Error(t, err)
+}
+
+func TestIsNativeGRPCURL(t *testing.T) {
+	assert.True(t, IsNativeGRPCURL("grpc://localhost:50051/grpc.health.v1.Health/Check"))
+	assert.True(t, IsNativeGRPCURL("grpcs://api.example.com/grpc.health.v1.Health/Check"))
+	assert.False(t, IsNativeGRPCURL("https://gateway.example.com/grpc.health.v1.Health/Check"))
+	assert.False(t, IsNativeGRPCURL("{{host}}/grpc.health.v1.Health/Check"))
+}
+
+func TestGRPCCode(t *testing.T) {
+	code, ok := GRPCCode("NOT_FOUND")
+	assert.True(t, ok)
+	assert.Equal(t, 5, code)
+
+	_, ok = GRPCCode("teapot")
+	assert.False(t, ok)
+}
@@ -0,0 +1,14 @@
+package models
+
+// MutationGuard decides which requests may run against shared environments.
+// Requests other than GET, HEAD and OPTIONS only run against non-local hosts
+// when mutations are allowed or the request is marked "@safe"; DELETE requests
+// also need their host in the allowlist or an explicit confirmation.
+type MutationGuard struct {
+	AllowMutations  bool     `json:"allowMutations,omitempty"`
+	DeleteAllowlist []string `json:"deleteAllowlist,omitempty"` // Hosts DELETE requests may run against, e.g. staging.example.com or *.test.example.com
+
+	// Confirm asks whether a DELETE request to the URL may run; DELETE requests
+	// outside the allowlist are blocked when nil
+	Confirm func(request *HTTPRequest, url string) bool `json:"-"`
+}
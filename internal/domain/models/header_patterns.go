@@ -0,0 +1,127 @@
+package models
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Header value patterns stand in for the value of a header that varies on
+// every request, in snapshots and header assertions, so that it's still
+// checked structurally instead of being ignored
+const (
+	AnyHeaderValue    = "<<any>>"    // Any value, as long as the header is present
+	UUIDHeaderValue   = "<<uuid>>"   // A UUID
+	NumberHeaderValue = "<<number>>" // A number
+	DateHeaderValue   = "<<date>>"   // An HTTP date, such as that of Last-Modified
+
+	// HeaderRegexPrefix starts a regular expression the value must contain a
+	// match of, e.g. "~max-age=\d+"
+	HeaderRegexPrefix = "~"
+)
+
+var uuidHeaderValue = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// IsHeaderValuePattern reports whether an expected header value is a pattern
+// rather than a literal value
+func IsHeaderValuePattern(value string) bool {
+	value = strings.TrimSpace(value)
+	return strings.HasPrefix(value, HeaderRegexPrefix) ||
+		(strings.HasPrefix(value, "<<") && strings.HasSuffix(value, ">>"))
+}
+
+// MatchHeaderValue reports whether a header value matches an expected one,
+// either a literal value or a pattern
+func MatchHeaderValue(expected, actual string) (bool, error) {
+	expected = strings.TrimSpace(expected)
+	if !IsHeaderValuePattern(expected) {
+		return expected == strings.TrimSpace(actual), nil
+	}
+
+	if strings.HasPrefix(expected, HeaderRegexPrefix) {
+		pattern, err := regexp.Compile(strings.TrimPrefix(expected, HeaderRegexPrefix))
+		if err != nil {
+			return false, fmt.Errorf("invalid header value pattern %q: %w", expected, err)
+		}
+		return pattern.MatchString(actual), nil
+	}
+
+	actual = strings.TrimSpace(actual)
+	switch strings.ToLower(expected) {
+	case AnyHeaderValue:
+		return true, nil
+	case UUIDHeaderValue:
+		return uuidHeaderValue.MatchString(actual), nil
+	case NumberHeaderValue:
+		_, err := strconv.ParseFloat(actual, 64)
+		return err == nil, nil
+	case DateHeaderValue:
+		_, err := http.ParseTime(actual)
+		return err == nil, nil
+	}
+	return false, fmt.Errorf("unknown header value pattern %q: use %s, %s, %s, %s or %s<regexp>",
+		expected, AnyHeaderValue, UUIDHeaderValue, NumberHeaderValue, DateHeaderValue, HeaderRegexPrefix)
+}
+
+// MatchHeaderValues reports whether the values of a header match the
+// expected ones, value by value
+func MatchHeaderValues(expected, actual []string) (bool, error) {
+	if len(expected) != len(actual) {
+		return false, nil
+	}
+	for i := range expected {
+		matched, err := MatchHeaderValue(expected[i], actual[i])
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// ExpectedHeader is a header a response must have, with a literal value or a
+// pattern, from "# @expect-header"
+type ExpectedHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// ParseExpectedHeader parses a header expectation such as "ETag: <<any>>"
+func ParseExpectedHeader(value string) (ExpectedHeader, error) {
+	name, expected, ok := strings.Cut(value, ":")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return ExpectedHeader{}, fmt.Errorf("invalid expected header %q: use <name>: <value>", value)
+	}
+	header := ExpectedHeader{Name: name, Value: strings.TrimSpace(expected)}
+	if _, err := MatchHeaderValue(header.Value, ""); err != nil {
+		return ExpectedHeader{}, err
+	}
+	return header, nil
+}
+
+// String returns the expectation as written in "# @expect-header"
+func (h ExpectedHeader) String() string {
+	return h.Name + ": " + h.Value
+}
+
+// Matches reports whether the response headers satisfy the expectation; the
+// header must be present even if any value is accepted
+func (h ExpectedHeader) Matches(headers map[string][]string) (actual string, matched bool) {
+	values := http.Header(headers).Values(h.Name)
+	if len(values) == 0 {
+		for name, named := range headers {
+			if strings.EqualFold(name, h.Name) {
+				values = named
+				break
+			}
+		}
+	}
+	for _, value := range values {
+		if ok, _ := MatchHeaderValue(h.Value, value); ok {
+			return value, true
+		}
+	}
+	return strings.Join(values, ", "), false
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchHeaderValue(t *testing.T) {
+	tests := []struct {
+		expected string
+		actual   string
+		matched  bool
+	}{
+		{"application/json", "application/json", true},
+		{"application/json", "text/plain", false},
+		{"<<any>>", "W/\"abc\"", true},
+		{"<<uuid>>", "123e4567-e89b-12d3-a456-426614174000", true},
+		{"<<uuid>>", "req-1", false},
+		{"<<number>>", "42", true},
+		{"<<number>>", "many", false},
+		{"<<date>>", "Mon, 02 Jan 2006 15:04:05 GMT", true},
+		{"<<date>>", "yesterday", false},
+		{`~max-age=\d+`, "public, max-age=60", true},
+		{`~max-age=\d+`, "no-store", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected+" "+tt.actual, func(t *testing.T) {
+			matched, err := MatchHeaderValue(tt.expected, tt.actual)
+			require.NoError(t, err)
+			assert.Equal(t, tt.matched, matched)
+		})
+	}
+
+	_, err := MatchHeaderValue("<<ulid>>", "x")
+	assert.Error(t, err)
+	_, err = MatchHeaderValue("~max-age=(", "x")
+	assert.Error(t, err)
+}
+
+func TestMatchHeaderValues(t *testing.T) {
+	matched, err := MatchHeaderValues([]string{"<<any>>", "b"}, []string{"a", "b"})
+	require.NoError(t, err)
+	assert.True(t, matched)
+
+	matched, err = MatchHeaderValues([]string{"<<any>>"}, []string{"a", "b"})
+	require.NoError(t, err)
+	assert.False(t, matched)
+}
+
+func TestExpectedHeader(t *testing.T) {
+	header, err := ParseExpectedHeader(`Cache-Control: ~max-age=\d+`)
+	require.NoError(t, err)
+	assert.Equal(t, ExpectedHeader{Name: "Cache-Control", Value: `~max-age=\d+`}, header)
+	assert.Equal(t, `Cache-Control: ~max-age=\d+`, header.String())
+
+	actual, matched := header.Matches(map[string][]string{"cache-control": {"public, max-age=60"}})
+	assert.True(t, matched)
+	assert.Equal(t, "public, max-age=60", actual)
+
+	header, err = ParseExpectedHeader("ETag: <<any>>")
+	require.NoError(t, err)
+	_, matched = header.Matches(map[string][]string{"Date": {"today"}})
+	assert.False(t, matched, "a header accepting any value must still be present")
+
+	_, err = ParseExpectedHeader("<<any>>")
+	assert.Error(t, err)
+	_, err = ParseExpectedHeader("ETag: <<anything>>")
+	assert.Error(t, err)
+}
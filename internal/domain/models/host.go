@@ -0,0 +1,24 @@
+package models
+
+import "strings"
+
+// IsRelativeURL reports whether a request URL is a path to resolve against a
+// host rather than an absolute URL or one starting with a variable such as
+// "{{baseUrl}}"
+func IsRelativeURL(rawURL string) bool {
+	return !strings.Contains(rawURL, "://") && !strings.HasPrefix(rawURL, "{{")
+}
+
+// ResolveURL resolves a relative request URL such as "/users?page=2" against a
+// host such as "https://api.example.com/v1", keeping the path of the host;
+// absolute URLs and URLs without a host are returned as they are
+func ResolveURL(rawURL, host string) string {
+	if host == "" || !IsRelativeURL(rawURL) {
+		return rawURL
+	}
+	host = strings.TrimSuffix(host, "/")
+	if rawURL == "" || strings.HasPrefix(rawURL, "?") || strings.HasPrefix(rawURL, "#") {
+		return host + rawURL
+	}
+	return host + "/" + strings.TrimPrefix(rawURL, "/")
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		host string
+		want string
+	}{
+		{url: "/users", host: "https://api.example.com", want: "https://api.example.com/users"},
+		{url: "users?page=2", host: "https://api.example.com/", want: "https://api.example.com/users?page=2"},
+		{url: "/users", host: "https://api.example.com/v1", want: "https://api.example.com/v1/users"},
+		{url: "?q=1", host: "https://api.example.com", want: "https://api.example.com?q=1"},
+		{url: "https://other.example.com/users", host: "https://api.example.com", want: "https://other.example.com/users"},
+		{url: "{{baseUrl}}/users", host: "https://api.example.com", want: "{{baseUrl}}/users"},
+		{url: "/users", host: "", want: "/users"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ResolveURL(tt.url, tt.host), tt.url)
+	}
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"time"
 )
 
@@ -12,16 +13,151 @@ type HTTPRequest struct {
 	Headers map[string]string `json:"headers,omitempty"`
 	Body    string            `json:"body,omitempty"`
 	Auth    *AuthDetails      `json:"auth,omitempty"`
+
+	// Path, relative to the report, of the attachment holding a body too large
+	// to inline in a saved report
+	BodyFile string `json:"bodyFile,omitempty"`
 	
 	// Fields for file format compatibility
 	Name     string    `json:"name,omitempty"`
 	Path     string    `json:"path,omitempty"`
 	Tag      string    `json:"tag,omitempty"`
 	Comments []string  `json:"comments,omitempty"`
+	SpecHash string    `json:"specHash,omitempty"`
+
+	// Line of the request line in the .http file, starting at 1
+	Line int `json:"line,omitempty"`
+
+	// Last line of the request in the .http file, its last header or body
+	// line, and the first line of its body, 0 without a body
+	EndLine  int `json:"endLine,omitempty"`
+	BodyLine int `json:"bodyLine,omitempty"`
+
+	// Lines of the headers in the .http file by name, the last one of a
+	// repeated header
+	HeaderLines map[string]int `json:"-"`
+
+	// Body lines replaced with the content of a file when the request is
+	// sent, from "< path" or "<@ path"
+	BodyIncludes []BodyInclude `json:"bodyIncludes,omitempty"`
+
+	// Files the response body is saved to when the request runs, from
+	// ">> path" lines after the request
+	Outputs []ResponseOutput `json:"outputs,omitempty"`
+
+	// Lines of the .http file the request was parsed from, from the separator
+	// before it up to the next one, so it can be written back as it was
+	Source []string `json:"-"`
+
+	// Base URL relative request URLs are resolved against, from the file's
+	// "@host" directive or "@baseUrl" variable
+	Host string `json:"host,omitempty"`
+
+	// Whether the name was derived with the naming template rather than set
+	// with "@name", so it may be changed to tell duplicates apart
+	DerivedName bool `json:"-"`
+
+	// Names of requests that must run before this one
+	DependsOn []string `json:"dependsOn,omitempty"`
+
+	// OAuth scopes the operation requires, from "# @scopes", so runs can be
+	// limited to the requests a role may make
+	Scopes []string `json:"scopes,omitempty"`
+
+	// jq-like expression applied to the response body before snapshot comparison
+	SnapshotTransform string `json:"snapshotTransform,omitempty"`
+
+	// Compare JSON arrays regardless of order, optionally sorting objects by a key
+	IgnoreArrayOrder bool   `json:"ignoreArrayOrder,omitempty"`
+	ArrayOrderKey    string `json:"arrayOrderKey,omitempty"`
+
+	// Numeric tolerances for snapshot comparison keyed by JSON field path
+	Tolerances map[string]Tolerance `json:"tolerances,omitempty"`
+
+	// Whether the operation is marked as deprecated in the spec
+	Deprecated bool `json:"deprecated,omitempty"`
+
+	// Whether the request may run against shared environments even if it
+	// changes data, marked with "# @safe"
+	Safe bool `json:"safe,omitempty"`
+
+	// Requests allowed per period for the operation, from the spec's x-rate-limit
+	RateLimit *RateLimit `json:"rateLimit,omitempty"`
+
+	// Requests of the same serial group never run at the same time, from
+	// "# @serial-group"
+	SerialGroup string `json:"serialGroup,omitempty"`
+
+	// Priority of the request with --order priority, from "# @priority":
+	// lower values run first, 0 for none
+	Priority int `json:"priority,omitempty"`
+
+	// Whether the request is a known failure that doesn't fail the build,
+	// from "# @quarantine [reason]"
+	Quarantined      bool   `json:"quarantined,omitempty"`
+	QuarantineReason string `json:"quarantineReason,omitempty"`
+
+	// Whether the request is skipped, from "# @skip [reason]", or focused,
+	// from "# @only", so the requests without it are skipped
+	Skip       bool   `json:"skip,omitempty"`
+	SkipReason string `json:"skipReason,omitempty"`
+	Only       bool   `json:"only,omitempty"`
+
+	// Callbacks the server is expected to send after the request
+	Callbacks []CallbackExpectation `json:"callbacks,omitempty"`
+
+	// How a 202 Accepted response is polled until the operation completes,
+	// the executor's default when nil
+	Poll *PollOptions `json:"poll,omitempty"`
+
+	// Whether and how far redirects are followed, the executor's default when nil
+	Redirects *RedirectPolicy `json:"redirects,omitempty"`
+
+	// How idempotent requests answered with a transient error status are
+	// retried, the executor's default when nil
+	Retries *RetryOptions `json:"retries,omitempty"`
+
+	// Largest response body read, the executor's default when 0
+	MaxBodyBytes int64 `json:"maxBodyBytes,omitempty"`
+
+	// How to fetch the following pages of a list endpoint when testing
+	Paginate *Pagination `json:"paginate,omitempty"`
+
+	// Variables extracted from the response and assertions evaluated on it
+	// when the run enables them
+	Variables  []VariableExtraction `json:"variables,omitempty"`
+	Assertions []TestAssertion      `json:"assertions,omitempty"`
+
+	// Status code, e.g. "201", or class, e.g. "2xx", the response must have,
+	// from "# @expect-status"
+	ExpectStatus string `json:"expectStatus,omitempty"`
+
+	// XSD or WSDL file the XML response is validated against, relative to the
+	// .http file, from "# @xsd"
+	XSD string `json:"xsd,omitempty"`
+
+	// Content encoding the server must send the body with, e.g. "gzip", or
+	// "identity" for none, from "# @expect-encoding"
+	ExpectEncoding string `json:"expectEncoding,omitempty"`
+
+	// Headers the response must have, with values that may be patterns such
+	// as "<<uuid>>", from "# @expect-header"
+	ExpectHeaders []ExpectedHeader `json:"expectHeaders,omitempty"`
+
+	// Parts of the response compared with its snapshot, all when empty, from
+	// "# @snapshot-compare"
+	SnapshotCompare SnapshotScope `json:"snapshotCompare,omitempty"`
+
+	// Header patterns ignored when comparing the response, on top of the
+	// configured ones, or compared again with "!", from "# @ignore-headers"
+	IgnoreHeaders []string `json:"ignoreHeaders,omitempty"`
 	
 	// Additional fields for variable handling
 	FormValues  map[string]string `json:"formValues,omitempty"`
-	QueryParams map[string]string `json:"queryParams,omitempty"`
+
+	// Parameters of the URL's query string, re-encoded when the request is
+	// sent so variables in their values are escaped
+	QueryParams map[string][]string `json:"queryParams,omitempty"`
 }
 
 // AuthDetails represents authentication details for an HTTP request
@@ -44,6 +180,11 @@ type HTTPResponse struct {
 	// Body contains the response body
 	Body string `json:"body,omitempty"`
 
+	// BodyFile is the path, relative to the report, of the attachment holding
+	// a body too large to inline in a saved report, or the absolute path of a
+	// body spilled to disk by a MemoryMonitor
+	BodyFile string `json:"bodyFile,omitempty"`
+
 	// For extended response information
 	ContentType    string        `json:"contentType,omitempty"`
 	ContentLength  int64         `json:"contentLength,omitempty"`
@@ -53,6 +194,41 @@ type HTTPResponse struct {
 	Timestamp      time.Time     `json:"timestamp,omitempty"`
 	ReceivedAt     time.Time     `json:"receivedAt,omitempty"`
 	Protocol       string        `json:"protocol,omitempty"`
+
+	// ContentEncoding is the encoding the body was sent with, also when the
+	// client decompressed it and dropped the Content-Encoding header
+	ContentEncoding string `json:"contentEncoding,omitempty"`
+
+	// For asynchronous operations, the 202 Accepted response that started the
+	// operation and the number of polls it took to complete
+	InitialResponse *HTTPResponse `json:"initialResponse,omitempty"`
+	PollAttempts    int           `json:"pollAttempts,omitempty"`
+
+	// URL of the final response and the redirects followed to reach it
+	URL       string        `json:"url,omitempty"`
+	Redirects []RedirectHop `json:"redirects,omitempty"`
+
+	// Retries is the number of times the request was retried before this
+	// response, after transient error statuses such as 503
+	Retries int `json:"retries,omitempty"`
+
+	// ResolvedRequest is the request as sent, after variable substitution,
+	// with its secrets masked
+	ResolvedRequest *ResolvedRequest `json:"resolvedRequest,omitempty"`
+}
+
+// Encoding returns the content encoding the server used for the body, empty
+// when it wasn't encoded
+func (r *HTTPResponse) Encoding() string {
+	if r.ContentEncoding != "" {
+		return r.ContentEncoding
+	}
+	for name, values := range r.Headers {
+		if strings.EqualFold(name, "Content-Encoding") && len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
 }
 
 // HTTPHeader represents an HTTP header
@@ -76,7 +252,19 @@ type HTTPFileRequest struct {
 // HTTPFile represents a collection of HTTP requests to be written to a .http file
 type HTTPFile struct {
 	Filename string
-	Requests []HTTPFileRequest
+	Requests []HTTPRequest
+
+	// Version stamp of the tool and spec that generated the file
+	ToolVersion string
+	SpecHash    string
+
+	// Requests of this file's tag that are defined in the shared file, a path
+	// relative to this file
+	SharedFile     string
+	SharedRequests []string
+
+	// Lines of a parsed file without requests, written back as they were
+	Trailing []string
 }
 
 // HTTPDirectory represents a directory containing HTTP files
@@ -91,6 +279,10 @@ type HTTPCollection struct {
 	RootDir      string
 	Directories  []HTTPDirectory
 	RootFiles    []HTTPFile
+
+	// Manifest lists the requests of the collection, written along with it
+	// when set
+	Manifest *CollectionManifest
 }
 
 // GetHeaderValue gets a header value by name
@@ -135,12 +327,35 @@ func (r *HTTPFileRequest) SetHeaderValue(name, value string) {
 // Clone creates a deep copy of an HTTPRequest
 func (r *HTTPRequest) Clone() *HTTPRequest {
 	clone := &HTTPRequest{
-		Method: r.Method,
-		URL:    r.URL,
-		Body:   r.Body,
-		Name:   r.Name,
-		Path:   r.Path,
-		Tag:    r.Tag,
+		Method:   r.Method,
+		URL:      r.URL,
+		Body:     r.Body,
+		Name:     r.Name,
+		Path:     r.Path,
+		Tag:      r.Tag,
+		SpecHash: r.SpecHash,
+
+		SnapshotTransform: r.SnapshotTransform,
+		IgnoreArrayOrder:  r.IgnoreArrayOrder,
+		ArrayOrderKey:     r.ArrayOrderKey,
+	}
+
+	// Copy tolerances
+	if r.Tolerances != nil {
+		clone.Tolerances = make(map[string]Tolerance, len(r.Tolerances))
+		for k, v := range r.Tolerances {
+			clone.Tolerances[k] = v
+		}
+	}
+
+	// Copy dependencies
+	if r.DependsOn != nil {
+		clone.DependsOn = append([]string(nil), r.DependsOn...)
+	}
+
+	// Copy scopes
+	if r.Scopes != nil {
+		clone.Scopes = append([]string(nil), r.Scopes...)
 	}
 	
 	// Copy headers
@@ -174,12 +389,7 @@ func (r *HTTPRequest) Clone() *HTTPRequest {
 	}
 	
 	// Copy query params
-	if r.QueryParams != nil {
-		clone.QueryParams = make(map[string]string)
-		for k, v := range r.QueryParams {
-			clone.QueryParams[k] = v
-		}
-	}
+	clone.QueryParams = CloneQueryParams(r.QueryParams)
 	
 	return clone
 }
@@ -0,0 +1,159 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultIgnoredHeaders are the headers left out of response comparisons
+// unless configured otherwise, since they vary between identical responses
+var DefaultIgnoredHeaders = []string{"Date", "Content-Length", "Server", "Connection", "Set-Cookie"}
+
+// HeaderIgnoreRules configures the headers left out of response comparisons:
+// patterns for all requests, extended per tag. A pattern is a header name or
+// a regular expression matching whole names, such as "X-Trace-.*", ignoring
+// case. A pattern starting with "!" compares a header ignored so far again.
+type HeaderIgnoreRules struct {
+	Default []string            `json:"default,omitempty"`
+	ByTag   map[string][]string `json:"byTag,omitempty"`
+}
+
+// DefaultHeaderIgnoreRules ignores the DefaultIgnoredHeaders of all requests
+func DefaultHeaderIgnoreRules() HeaderIgnoreRules {
+	return HeaderIgnoreRules{Default: append([]string(nil), DefaultIgnoredHeaders...)}
+}
+
+// ParseHeaderIgnoreRules reads the configured patterns for all requests and
+// the patterns per tag, given as lists or comma-separated strings, and checks
+// they compile
+func ParseHeaderIgnoreRules(defaults []string, byTag map[string]interface{}) (HeaderIgnoreRules, error) {
+	rules := HeaderIgnoreRules{Default: defaults, ByTag: make(map[string][]string, len(byTag))}
+	all := append([]string(nil), defaults...)
+	for tag, value := range byTag {
+		switch patterns := value.(type) {
+		case []interface{}:
+			for _, pattern := range patterns {
+				rules.ByTag[tag] = append(rules.ByTag[tag], fmt.Sprint(pattern))
+			}
+		case []string:
+			rules.ByTag[tag] = patterns
+		case string:
+			rules.ByTag[tag] = strings.Split(patterns, ",")
+		default:
+			return HeaderIgnoreRules{}, fmt.Errorf("invalid ignored headers of tag %s: expected a list of header patterns", tag)
+		}
+		all = append(all, rules.ByTag[tag]...)
+	}
+
+	if _, err := NewHeaderMatcher(MergeHeaderPatterns(nil, all)); err != nil {
+		return HeaderIgnoreRules{}, err
+	}
+	return rules, nil
+}
+
+// Resolve returns the patterns of the headers ignored for a request with the
+// given tags and patterns of its own: the defaults, then those of its tags in
+// name order, then its own, each able to override the ones before
+func (r HeaderIgnoreRules) Resolve(tags []string, request []string) []string {
+	patterns := MergeHeaderPatterns(nil, r.Default)
+
+	sorted := append([]string(nil), tags...)
+	sort.Strings(sorted)
+	for _, tag := range sorted {
+		for name, tagPatterns := range r.ByTag {
+			if tag != "" && strings.EqualFold(name, tag) {
+				patterns = MergeHeaderPatterns(patterns, tagPatterns)
+			}
+		}
+	}
+
+	return MergeHeaderPatterns(patterns, request)
+}
+
+// MergeHeaderPatterns adds patterns to a list, where "!<pattern>" removes a
+// pattern added before instead
+func MergeHeaderPatterns(patterns []string, overrides []string) []string {
+	merged := append([]string(nil), patterns...)
+	for _, pattern := range overrides {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		removed := strings.HasPrefix(pattern, "!")
+		pattern = strings.TrimSpace(strings.TrimPrefix(pattern, "!"))
+
+		kept := merged[:0]
+		for _, existing := range merged {
+			if !strings.EqualFold(existing, pattern) {
+				kept = append(kept, existing)
+			}
+		}
+		merged = kept
+		if !removed {
+			merged = append(merged, pattern)
+		}
+	}
+	return merged
+}
+
+// ParseHeaderPatterns splits a comma-separated list of header patterns, such
+// as the argument of an "@ignore-headers" directive
+func ParseHeaderPatterns(value string) ([]string, error) {
+	var patterns []string
+	for _, pattern := range strings.Split(value, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if _, err := compileHeaderPattern(strings.TrimPrefix(pattern, "!")); err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, pattern)
+	}
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("no header patterns in %q", value)
+	}
+	return patterns, nil
+}
+
+// HeaderMatcher matches header names against ignore patterns
+type HeaderMatcher struct {
+	patterns []*regexp.Regexp
+}
+
+// NewHeaderMatcher compiles header patterns, see HeaderIgnoreRules
+func NewHeaderMatcher(patterns []string) (*HeaderMatcher, error) {
+	matcher := &HeaderMatcher{}
+	for _, pattern := range patterns {
+		compiled, err := compileHeaderPattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		matcher.patterns = append(matcher.patterns, compiled)
+	}
+	return matcher, nil
+}
+
+// compileHeaderPattern compiles a pattern matching whole header names, ignoring case
+func compileHeaderPattern(pattern string) (*regexp.Regexp, error) {
+	compiled, err := regexp.Compile("(?i)^(?:" + pattern + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid header pattern %q: %w", pattern, err)
+	}
+	return compiled, nil
+}
+
+// Matches reports whether a header name matches one of the patterns
+func (m *HeaderMatcher) Matches(name string) bool {
+	if m == nil {
+		return false
+	}
+	for _, pattern := range m.patterns {
+		if pattern.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
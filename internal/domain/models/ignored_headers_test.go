@@ -0,0 +1,63 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeaderIgnoreRulesResolve(t *testing.T) {
+	rules := HeaderIgnoreRules{
+		Default: []string{"Date", "Content-Length"},
+		ByTag: map[string][]string{
+			"Tracing": {"X-Trace-.*"},
+			"cache":   {"!Date", "Age"},
+		},
+	}
+
+	assert.Equal(t, []string{"Date", "Content-Length"}, rules.Resolve([]string{""}, nil))
+	assert.Equal(t, []string{"Date", "Content-Length", "X-Trace-.*"}, rules.Resolve([]string{"tracing"}, nil))
+	assert.Equal(t, []string{"Content-Length", "Age", "ETag"}, rules.Resolve([]string{"cache"}, []string{"ETag"}))
+	assert.Equal(t, []string{"Date"}, rules.Resolve(nil, []string{"!content-length"}))
+}
+
+func TestHeaderMatcher(t *testing.T) {
+	matcher, err := NewHeaderMatcher([]string{"Date", "X-Trace-.*"})
+	require.NoError(t, err)
+
+	assert.True(t, matcher.Matches("date"))
+	assert.True(t, matcher.Matches("X-Trace-Id"))
+	assert.False(t, matcher.Matches("X-Date"))
+	assert.False(t, matcher.Matches("Content-Type"))
+
+	var none *HeaderMatcher
+	assert.False(t, none.Matches("Date"))
+
+	_, err = NewHeaderMatcher([]string{"X-(["})
+	assert.Error(t, err)
+}
+
+func TestParseHeaderPatterns(t *testing.T) {
+	patterns, err := ParseHeaderPatterns("X-Trace-.*, !Date")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"X-Trace-.*", "!Date"}, patterns)
+
+	_, err = ParseHeaderPatterns(" , ")
+	assert.Error(t, err)
+}
+
+func TestParseHeaderIgnoreRules(t *testing.T) {
+	rules, err := ParseHeaderIgnoreRules([]string{"Date"}, map[string]interface{}{
+		"tracing": []interface{}{"X-Trace-.*"},
+		"cache":   "Age, !Date",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"X-Trace-.*"}, rules.ByTag["tracing"])
+	assert.Equal(t, []string{"Content-Length", "Age"}, HeaderIgnoreRules{Default: []string{"Content-Length"}, ByTag: rules.ByTag}.Resolve([]string{"cache"}, nil))
+
+	_, err = ParseHeaderIgnoreRules([]string{"X-(["}, nil)
+	assert.Error(t, err)
+	_, err = ParseHeaderIgnoreRules(nil, map[string]interface{}{"cache": 3})
+	assert.Error(t, err)
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// CollectionManifestFile is the name of the manifest written at the root of
+// generated collections
+const CollectionManifestFile = "collection.json"
+
+// CollectionManifest lists the requests of a generated collection, so that
+// tools can find and select them without parsing the .http files
+type CollectionManifest struct {
+	ToolVersion string            `json:"toolVersion"`
+	SpecHash    string            `json:"specHash,omitempty"`
+	Requests    []ManifestRequest `json:"requests"`
+}
+
+// ManifestRequest describes a generated request. Security lists the
+// alternative requirements of the operation as in the spec, each naming the
+// schemes it needs with their scopes; it's empty for public operations.
+type ManifestRequest struct {
+	File        string                `json:"file"`
+	Name        string                `json:"name"`
+	OperationID string                `json:"operationId,omitempty"`
+	Method      string                `json:"method"`
+	Path        string                `json:"path"`
+	Tags        []string              `json:"tags,omitempty"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+}
+
+// ReadCollectionManifest reads the manifest of a generated collection
+func ReadCollectionManifest(path string) (*CollectionManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read collection manifest: %w", err)
+	}
+	var manifest CollectionManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse collection manifest %s: %w", path, err)
+	}
+	return &manifest, nil
+}
+
+// Select returns the requests having one of the tags and one of the
+// methods; empty lists select every request
+func (m *CollectionManifest) Select(tags, methods []string) []ManifestRequest {
+	var selected []ManifestRequest
+	for _, request := range m.Requests {
+		if len(methods) > 0 && !containsFold(methods, request.Method) {
+			continue
+		}
+		if len(tags) > 0 && !anyContainedFold(tags, request.Tags) {
+			continue
+		}
+		selected = append(selected, request)
+	}
+	return selected
+}
+
+// containsFold reports whether a list holds a value, ignoring case
+func containsFold(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// anyContainedFold reports whether a list holds one of the values, ignoring case
+func anyContainedFold(list, values []string) bool {
+	for _, value := range values {
+		if containsFold(list, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadCollectionManifest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), CollectionManifestFile)
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"toolVersion": "1.4.0",
+		"requests": [
+			{"file": "users/users.http", "name": "listUsers", "operationId": "listUsers", "method": "GET", "path": "/users", "tags": ["users"], "security": [{"keyAuth": []}]},
+			{"file": "users/users.http", "name": "createUser", "method": "POST", "path": "/users", "tags": ["users", "admin"]},
+			{"file": "default.http", "name": "health", "method": "GET", "path": "/health"}
+		]
+	}`), 0644))
+
+	manifest, err := ReadCollectionManifest(path)
+	require.NoError(t, err)
+	assert.Equal(t, []map[string][]string{{"keyAuth": {}}}, manifest.Requests[0].Security)
+
+	names := func(requests []ManifestRequest) []string {
+		var result []string
+		for _, request := range requests {
+			result = append(result, request.Name)
+		}
+		return result
+	}
+	assert.Equal(t, []string{"listUsers", "createUser", "health"}, names(manifest.Select(nil, nil)))
+	assert.Equal(t, []string{"createUser"}, names(manifest.Select([]string{"Admin"}, nil)))
+	assert.Equal(t, []string{"listUsers", "health"}, names(manifest.Select(nil, []string{"get"})))
+
+	_, err = ReadCollectionManifest(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
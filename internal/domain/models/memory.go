@@ -0,0 +1,201 @@
+package models
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// memoryPollInterval is how often a throttled request checks the memory usage
+const memoryPollInterval = 50 * time.Millisecond
+
+// spillNamePattern matches the characters replaced in the names of spilled bodies
+var spillNamePattern = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
+// MemoryMonitor keeps a run under a memory budget: while the resident memory
+// of the process is above its limit, requests run one at a time and the
+// bodies of completed tests are spilled to disk
+type MemoryMonitor struct {
+	limit    int64
+	spillDir string
+	usage    func() int64
+
+	mu        sync.Mutex
+	inFlight  int
+	throttled int
+	spilled   int
+	err       error
+}
+
+// NewMemoryMonitor creates a monitor of a memory limit in bytes, spilling
+// bodies to spillDir, or the temporary directory when empty. usage returns
+// the memory used, ProcessRSS when nil.
+func NewMemoryMonitor(limit int64, spillDir string, usage func() int64) *MemoryMonitor {
+	if spillDir == "" {
+		spillDir = filepath.Join(os.TempDir(), "swagger-to-http-spill")
+	}
+	if usage == nil {
+		usage = ProcessRSS
+	}
+	return &MemoryMonitor{limit: limit, spillDir: spillDir, usage: usage}
+}
+
+// ProcessRSS returns the resident memory of the process, or the memory
+// obtained by the Go runtime where it can't be read
+func ProcessRSS() int64 {
+	if file, err := os.Open("/proc/self/status"); err == nil {
+		defer file.Close()
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			fields := bytes.Fields(scanner.Bytes())
+			if len(fields) >= 2 && string(fields[0]) == "VmRSS:" {
+				if kb, err := strconv.ParseInt(string(fields[1]), 10, 64); err == nil {
+					return kb << 10
+				}
+			}
+		}
+	}
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return int64(stats.Sys)
+}
+
+// overLimit reports whether the memory used is above the limit
+func (m *MemoryMonitor) overLimit() bool {
+	return m.usage() > m.limit
+}
+
+// Acquire waits until a request may start: right away under the limit,
+// otherwise once no other request is running
+func (m *MemoryMonitor) Acquire(ctx context.Context) error {
+	if m == nil {
+		return nil
+	}
+	waited := false
+	for {
+		m.mu.Lock()
+		if m.inFlight == 0 || !m.overLimit() {
+			m.inFlight++
+			if waited {
+				m.throttled++
+			}
+			m.mu.Unlock()
+			return nil
+		}
+		m.mu.Unlock()
+
+		if !waited {
+			// Give back the memory of the bodies spilled or released so far
+			runtime.GC()
+			waited = true
+		}
+		timer := time.NewTimer(memoryPollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Release marks a request started with Acquire as done
+func (m *MemoryMonitor) Release() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inFlight--
+}
+
+// Spill writes the response bodies of a completed test to disk when above
+// the limit, keeping the path of their file in BodyFile. Bodies that can't be
+// written stay in memory, see Err.
+func (m *MemoryMonitor) Spill(result *TestResult) {
+	if m == nil || !m.overLimit() {
+		return
+	}
+	if err := m.spill(result); err != nil {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if m.err == nil {
+			m.err = err
+		}
+	}
+}
+
+// spill writes the response bodies of a test to disk, replacing its responses
+// by copies referencing their file, as later requests may reference them
+func (m *MemoryMonitor) spill(result *TestResult) error {
+	for i, response := range []**HTTPResponse{&result.Response, &result.RawResponse} {
+		if *response == nil || (*response).Body == "" {
+			continue
+		}
+		if err := os.MkdirAll(m.spillDir, 0755); err != nil {
+			return fmt.Errorf("failed to create spill directory: %w", err)
+		}
+
+		m.mu.Lock()
+		m.spilled++
+		name := fmt.Sprintf("%d-%s-%d.body", m.spilled, spillNamePattern.ReplaceAllString(result.Name, "-"), i)
+		m.mu.Unlock()
+
+		path, err := filepath.Abs(filepath.Join(m.spillDir, name))
+		if err != nil {
+			return fmt.Errorf("failed to spill body of %s: %w", result.Name, err)
+		}
+		if err := os.WriteFile(path, []byte((*response).Body), 0644); err != nil {
+			return fmt.Errorf("failed to spill body of %s: %w", result.Name, err)
+		}
+		spilled := **response
+		spilled.Body = ""
+		spilled.BodyFile = path
+		*response = &spilled
+	}
+	return nil
+}
+
+// Throttled returns the number of requests that waited for the memory used
+// to drop
+func (m *MemoryMonitor) Throttled() int {
+	if m == nil {
+		return 0
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.throttled
+}
+
+// Spilled returns the number of bodies written to disk
+func (m *MemoryMonitor) Spilled() int {
+	if m == nil {
+		return 0
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.spilled
+}
+
+// Err returns the first error spilling a body to disk, if any
+func (m *MemoryMonitor) Err() error {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.err
+}
+
+// SpillDir returns the directory bodies are spilled to
+func (m *MemoryMonitor) SpillDir() string {
+	return m.spillDir
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryMonitorAcquire(t *testing.T) {
+	usage := int64(200)
+	monitor := NewMemoryMonitor(100, t.TempDir(), func() int64 { return usage })
+
+	// Over the limit, the first request still runs but the next one waits
+	require.NoError(t, monitor.Acquire(context.Background()))
+	ctx, cancel := context.WithTimeout(context.Background(), 3*memoryPollInterval)
+	defer cancel()
+	assert.ErrorIs(t, monitor.Acquire(ctx), context.DeadlineExceeded)
+
+	done := make(chan error)
+	go func() { done <- monitor.Acquire(context.Background()) }()
+	time.Sleep(memoryPollInterval)
+	monitor.Release()
+	require.NoError(t, <-done)
+	assert.Equal(t, 1, monitor.Throttled())
+	monitor.Release()
+
+	// Under the limit, requests run concurrently
+	usage = 50
+	require.NoError(t, monitor.Acquire(context.Background()))
+	require.NoError(t, monitor.Acquire(context.Background()))
+}
+
+func TestMemoryMonitorSpill(t *testing.T) {
+	usage := int64(50)
+	monitor := NewMemoryMonitor(100, t.TempDir(), func() int64 { return usage })
+	response := &HTTPResponse{Body: `{"users": []}`}
+	result := &TestResult{Name: "get users", Response: response}
+
+	monitor.Spill(result)
+	assert.Equal(t, `{"users": []}`, result.Response.Body)
+
+	usage = 200
+	monitor.Spill(result)
+	require.NoError(t, monitor.Err())
+	assert.Empty(t, result.Response.Body)
+	assert.Equal(t, `{"users": []}`, response.Body, "the response referenced elsewhere is left untouched")
+	assert.Equal(t, 1, monitor.Spilled())
+	data, err := os.ReadFile(result.Response.BodyFile)
+	require.NoError(t, err)
+	assert.Equal(t, `{"users": []}`, string(data))
+
+	var nilMonitor *MemoryMonitor
+	nilMonitor.Spill(result)
+	assert.NoError(t, nilMonitor.Acquire(context.Background()))
+}
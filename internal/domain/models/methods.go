@@ -0,0 +1,51 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// StandardMethods are the HTTP methods of OpenAPI operations, which requests
+// may always use
+var StandardMethods = []string{"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS", "HEAD", "TRACE"}
+
+// methodTokenPattern matches the characters an HTTP method may have (RFC 9110 tokens)
+var methodTokenPattern = regexp.MustCompile("^[!#$%&'*+.^_`|~0-9A-Za-z-]+$")
+
+// ParseExtraMethods parses the custom methods requests may use besides the
+// standard ones, e.g. "PURGE" or "LINK", upper-casing them
+func ParseExtraMethods(methods []string) ([]string, error) {
+	var extra []string
+	for _, method := range methods {
+		method = strings.ToUpper(strings.TrimSpace(method))
+		if method == "" {
+			continue
+		}
+		if !methodTokenPattern.MatchString(method) {
+			return nil, fmt.Errorf("invalid HTTP method %q", method)
+		}
+		extra = append(extra, method)
+	}
+	return extra, nil
+}
+
+// IsAllowedMethod reports whether a request may use a method, a standard one,
+// GRPC or one of the extra methods
+func IsAllowedMethod(method string, extra []string) bool {
+	method = strings.ToUpper(method)
+	if method == MethodGRPC {
+		return true
+	}
+	for _, allowed := range StandardMethods {
+		if method == allowed {
+			return true
+		}
+	}
+	for _, allowed := range extra {
+		if method == allowed {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseExtraMethods(t *testing.T) {
+	extra, err := ParseExtraMethods([]string{"purge", " LINK ", ""})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"PURGE", "LINK"}, extra)
+
+	_, err = ParseExtraMethods([]string{"NOT A METHOD"})
+	assert.Error(t, err)
+}
+
+func TestIsAllowedMethod(t *testing.T) {
+	tests := []struct {
+		method string
+		extra  []string
+		want   bool
+	}{
+		{method: "GET", want: true},
+		{method: "trace", want: true},
+		{method: "GRPC", want: true},
+		{method: "PURGE", want: false},
+		{method: "PURGE", extra: []string{"PURGE"}, want: true},
+		{method: "purge", extra: []string{"PURGE"}, want: true},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, IsAllowedMethod(tt.method, tt.extra), tt.method)
+	}
+}
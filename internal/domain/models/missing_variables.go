@@ -0,0 +1,156 @@
+package models
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// variableReferencePattern matches {{references}} with the spaces inside their
+// braces, including empty ones such as {{}}
+var variableReferencePattern = regexp.MustCompile(`\{\{\s*([^{}]*?)\s*\}\}`)
+
+// responseReferencePattern matches references to the responses of earlier
+// requests such as {{login.response.body.$.token}}
+var responseReferencePattern = regexp.MustCompile(`^([A-Za-z0-9_-]+)\.response\.(body|headers|status)\b`)
+
+// VariableReference is a {{reference}} in the text of a request
+type VariableReference struct {
+	Match string // The reference as written, e.g. "{{ baseUrl }}"
+	Name  string // The name it references, e.g. "baseUrl", or "" for {{}}
+}
+
+// MissingVariables returns the names of the {{variables}} a request uses that
+// have no value, sorted; references to earlier responses, callback URLs and
+// body template helpers are resolved elsewhere and never missing
+func MissingVariables(request *HTTPRequest, variables map[string]string) []string {
+	seen := make(map[string]bool)
+	var missing []string
+	for _, text := range VariableTexts(request) {
+		for _, match := range urlVariablePattern.FindAllStringSubmatch(text, -1) {
+			name := match[1]
+			if _, ok := variables[name]; ok || seen[name] || !IsPlainVariable(name) {
+				continue
+			}
+			seen[name] = true
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// UnresolvedVariablesError reports the variables without a value a request
+// uses when variables are strict
+type UnresolvedVariablesError struct {
+	Names []string
+}
+
+func (e *UnresolvedVariablesError) Error() string {
+	return "unresolved variables: " + strings.Join(e.Names, ", ")
+}
+
+// strictVariablesKey is the context key of whether the variables of a run are strict
+type strictVariablesKey struct{}
+
+// ContextWithStrictVariables returns a context in which requests using
+// variables without a value fail rather than being sent as written
+func ContextWithStrictVariables(ctx context.Context, strict bool) context.Context {
+	return context.WithValue(ctx, strictVariablesKey{}, strict)
+}
+
+// StrictVariablesFromContext reports whether the variables of the run a
+// context belongs to are strict, false outside of a run
+func StrictVariablesFromContext(ctx context.Context) bool {
+	strict, _ := ctx.Value(strictVariablesKey{}).(bool)
+	return strict
+}
+
+// CheckVariables returns an *UnresolvedVariablesError when the variables of
+// the context are strict and the request uses variables without a value
+func CheckVariables(ctx context.Context, request *HTTPRequest, variables map[string]string) error {
+	if !StrictVariablesFromContext(ctx) {
+		return nil
+	}
+	if missing := MissingVariables(request, variables); len(missing) > 0 {
+		return &UnresolvedVariablesError{Names: missing}
+	}
+	return nil
+}
+
+// VariableTexts returns the texts of a request that may reference variables:
+// its URL, host, headers in the order of their names, query values and body
+func VariableTexts(request *HTTPRequest) []string {
+	texts := []string{request.URL, request.Host}
+	for _, name := range SortedHeaderNames(request.Headers) {
+		texts = append(texts, request.Headers[name])
+	}
+	names := make([]string, 0, len(request.QueryParams))
+	for name := range request.QueryParams {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		texts = append(texts, request.QueryParams[name]...)
+	}
+	return append(texts, request.Body)
+}
+
+// FindVariableReferences returns the {{references}} of a text in order
+func FindVariableReferences(text string) []VariableReference {
+	var references []VariableReference
+	for _, match := range variableReferencePattern.FindAllStringSubmatch(text, -1) {
+		references = append(references, VariableReference{Match: match[0], Name: match[1]})
+	}
+	return references
+}
+
+// ReplaceVariables replaces the {{references}} of a text to variables with a
+// value; the others are left as they are
+func ReplaceVariables(text string, variables map[string]string) string {
+	return variableReferencePattern.ReplaceAllStringFunc(text, func(match string) string {
+		if value, ok := variables[variableReferencePattern.FindStringSubmatch(match)[1]]; ok {
+			return value
+		}
+		return match
+	})
+}
+
+// ResponseReference returns the name of the request whose response a
+// reference such as "login.response.body.$.token" reads
+func ResponseReference(name string) (string, bool) {
+	if match := responseReferencePattern.FindStringSubmatch(name); match != nil {
+		return match[1], true
+	}
+	return "", false
+}
+
+// IsPlainVariable reports whether a {{reference}} names a variable rather than
+// a response, a callback URL or a body template helper such as {{#each}}
+func IsPlainVariable(name string) bool {
+	if name == "" || strings.ContainsAny(name[:1], "#/@") || name == "this" || name == "else" || strings.HasPrefix(name, "this.") {
+		return false
+	}
+	return !strings.Contains(name, ".response.") && !strings.HasPrefix(name, "callback.")
+}
+
+// HasHeader reports whether headers contain name, ignoring case
+func HasHeader(headers map[string]string, name string) bool {
+	for header := range headers {
+		if strings.EqualFold(header, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// SortedHeaderNames returns the header names in sorted order
+func SortedHeaderNames(headers map[string]string) []string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
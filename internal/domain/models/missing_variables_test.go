@@ -0,0 +1,20 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMissingVariables(t *testing.T) {
+	request := &HTTPRequest{
+		URL:     "{{baseUrl}}/users/{{login.response.body.$.id}}?q={{term}}",
+		Headers: map[string]string{"Authorization": "Bearer {{TOKEN}}"},
+		Body:    `{"password": "{{PASSWORD}}", "callback": "{{callback.done}}", "items": [{{#each ids}}{{this}}{{/each}}], "token": "{{TOKEN}}"}`,
+	}
+
+	missing := MissingVariables(request, map[string]string{"baseUrl": "https://api.example.com", "ids": "1, 2"})
+	assert.Equal(t, []string{"PASSWORD", "TOKEN", "term"}, missing)
+
+	assert.Empty(t, MissingVariables(request, map[string]string{"baseUrl": "", "term": "a", "TOKEN": "t", "PASSWORD": "p"}))
+}
@@ -0,0 +1,152 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Pagination modes
+const (
+	// PaginateLink follows the rel="next" URL of the Link header
+	PaginateLink = "link"
+
+	// PaginateNext follows the next page URL, or cursor, in a body field
+	PaginateNext = "next"
+
+	// PaginatePage increments a page number query parameter
+	PaginatePage = "page"
+)
+
+// Safety limits applied when a pagination sets none
+const (
+	DefaultMaxPages = 100
+	DefaultMaxItems = 10000
+)
+
+// Pagination describes how to fetch every page of a list endpoint, declared with
+// "# @paginate link|next=<path>|page=<param> [size=<param>:<n>] [cursor=<param>]
+// [items=<path>] [max-pages=<n>] [max-items=<n>]"
+type Pagination struct {
+	Mode        string `json:"mode"`
+	NextField   string `json:"nextField,omitempty"`   // Body field with the next URL or cursor, for PaginateNext
+	CursorParam string `json:"cursorParam,omitempty"` // Query parameter a next cursor is sent in, "cursor" when empty
+	PageParam   string `json:"pageParam,omitempty"`   // Page number query parameter, for PaginatePage
+	SizeParam   string `json:"sizeParam,omitempty"`   // Page size query parameter
+	Size        int    `json:"size,omitempty"`        // Page size sent in SizeParam
+	ItemsField  string `json:"itemsField,omitempty"`  // Body field with the items, the body itself when empty
+	MaxPages    int    `json:"maxPages,omitempty"`    // DefaultMaxPages when zero
+	MaxItems    int    `json:"maxItems,omitempty"`    // DefaultMaxItems when zero
+}
+
+// PaginationResult aggregates the pages fetched for a request
+type PaginationResult struct {
+	Pages     int    `json:"pages"`
+	Items     int    `json:"items"`
+	Truncated string `json:"truncated,omitempty"` // Safety limit that stopped the pagination, if any
+
+	// Responses of the pages after the first one, in order
+	Responses []*HTTPResponse `json:"-"`
+}
+
+// ParsePaginateDirective parses the argument of a "@paginate" directive
+func ParsePaginateDirective(value string) (Pagination, error) {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return Pagination{}, fmt.Errorf("paginate directive needs a mode: link, next=<path> or page=<param>")
+	}
+
+	var pagination Pagination
+	switch mode := fields[0]; {
+	case mode == PaginateLink:
+		pagination.Mode = PaginateLink
+	case strings.HasPrefix(mode, "next=") && len(mode) > len("next="):
+		pagination.Mode = PaginateNext
+		pagination.NextField = strings.TrimPrefix(mode, "next=")
+	case strings.HasPrefix(mode, "page=") && len(mode) > len("page="):
+		pagination.Mode = PaginatePage
+		pagination.PageParam = strings.TrimPrefix(mode, "page=")
+	default:
+		return Pagination{}, fmt.Errorf("invalid pagination mode %q", mode)
+	}
+
+	for _, field := range fields[1:] {
+		name, option, ok := strings.Cut(field, "=")
+		if !ok || option == "" {
+			return Pagination{}, fmt.Errorf("invalid pagination option %q", field)
+		}
+		switch name {
+		case "size":
+			param, size, ok := strings.Cut(option, ":")
+			count, err := strconv.Atoi(size)
+			if !ok || param == "" || err != nil || count < 1 {
+				return Pagination{}, fmt.Errorf("invalid page size %q: use size=<param>:<n>", field)
+			}
+			pagination.SizeParam = param
+			pagination.Size = count
+		case "cursor":
+			pagination.CursorParam = option
+		case "items":
+			pagination.ItemsField = option
+		case "max-pages", "max-items":
+			limit, err := strconv.Atoi(option)
+			if err != nil || limit < 1 {
+				return Pagination{}, fmt.Errorf("invalid pagination limit %q", field)
+			}
+			if name == "max-pages" {
+				pagination.MaxPages = limit
+			} else {
+				pagination.MaxItems = limit
+			}
+		default:
+			return Pagination{}, fmt.Errorf("invalid pagination option %q", field)
+		}
+	}
+
+	return pagination, nil
+}
+
+// MaxPagesOrDefault returns the page limit, or DefaultMaxPages
+func (p Pagination) MaxPagesOrDefault() int {
+	if p.MaxPages > 0 {
+		return p.MaxPages
+	}
+	return DefaultMaxPages
+}
+
+// MaxItemsOrDefault returns the item limit, or DefaultMaxItems
+func (p Pagination) MaxItemsOrDefault() int {
+	if p.MaxItems > 0 {
+		return p.MaxItems
+	}
+	return DefaultMaxItems
+}
+
+// String formats the pagination as the argument of a "@paginate" directive
+func (p Pagination) String() string {
+	var parts []string
+	switch p.Mode {
+	case PaginateNext:
+		parts = append(parts, "next="+p.NextField)
+	case PaginatePage:
+		parts = append(parts, "page="+p.PageParam)
+	default:
+		parts = append(parts, p.Mode)
+	}
+	if p.SizeParam != "" {
+		parts = append(parts, fmt.Sprintf("size=%s:%d", p.SizeParam, p.Size))
+	}
+	if p.CursorParam != "" {
+		parts = append(parts, "cursor="+p.CursorParam)
+	}
+	if p.ItemsField != "" {
+		parts = append(parts, "items="+p.ItemsField)
+	}
+	if p.MaxPages > 0 {
+		parts = append(parts, fmt.Sprintf("max-pages=%d", p.MaxPages))
+	}
+	if p.MaxItems > 0 {
+		parts = append(parts, fmt.Sprintf("max-items=%d", p.MaxItems))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePaginateDirective(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Pagination
+		wantErr bool
+	}{
+		{value: "link", want: Pagination{Mode: PaginateLink}},
+		{value: "next=meta.next cursor=after items=data max-items=500", want: Pagination{Mode: PaginateNext, NextField: "meta.next", CursorParam: "after", ItemsField: "data", MaxItems: 500}},
+		{value: "page=page size=per_page:50 max-pages=10", want: Pagination{Mode: PaginatePage, PageParam: "page", SizeParam: "per_page", Size: 50, MaxPages: 10}},
+		{value: "", wantErr: true},
+		{value: "offset", wantErr: true},
+		{value: "page=page size=50", wantErr: true},
+		{value: "link max-pages=0", wantErr: true},
+		{value: "link sort=name", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParsePaginateDirective(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			// The formatted pagination parses back to the same pagination
+			again, err := ParsePaginateDirective(got.String())
+			require.NoError(t, err)
+			assert.Equal(t, got, again)
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PollOptions configures how the executor follows asynchronous operations: a
+// 202 Accepted response with a Location or Operation-Location header is polled
+// until the operation completes
+type PollOptions struct {
+	Enabled  bool          `json:"enabled"`
+	Interval time.Duration `json:"interval,omitempty"` // Time between polls unless the server sends Retry-After
+	Timeout  time.Duration `json:"timeout,omitempty"`  // Time the operation has to complete
+}
+
+// Merge returns the options with the unset interval and timeout taken from defaults
+func (p PollOptions) Merge(defaults PollOptions) PollOptions {
+	if p.Interval <= 0 {
+		p.Interval = defaults.Interval
+	}
+	if p.Timeout <= 0 {
+		p.Timeout = defaults.Timeout
+	}
+	return p
+}
+
+// String formats the options as the argument of a "@poll" directive
+func (p PollOptions) String() string {
+	if !p.Enabled {
+		return "off"
+	}
+	var parts []string
+	if p.Interval > 0 {
+		parts = append(parts, "interval="+p.Interval.String())
+	}
+	if p.Timeout > 0 {
+		parts = append(parts, "timeout="+p.Timeout.String())
+	}
+	return strings.Join(parts, " ")
+}
+
+// DefaultPollOptions polls every second for up to a minute
+func DefaultPollOptions() PollOptions {
+	return PollOptions{Enabled: true, Interval: time.Second, Timeout: time.Minute}
+}
+
+// ParsePollDirective parses the argument of a "@poll" directive,
+// "[off] [interval=<duration>] [timeout=<duration>]". Options left out are
+// zero and taken from the executor's defaults.
+func ParsePollDirective(value string) (PollOptions, error) {
+	options := PollOptions{Enabled: true}
+
+	for _, field := range strings.Fields(value) {
+		switch {
+		case field == "off":
+			options.Enabled = false
+		case strings.HasPrefix(field, "interval="):
+			interval, err := time.ParseDuration(strings.TrimPrefix(field, "interval="))
+			if err != nil || interval <= 0 {
+				return PollOptions{}, fmt.Errorf("invalid poll interval %q", field)
+			}
+			options.Interval = interval
+		case strings.HasPrefix(field, "timeout="):
+			timeout, err := time.ParseDuration(strings.TrimPrefix(field, "timeout="))
+			if err != nil || timeout <= 0 {
+				return PollOptions{}, fmt.Errorf("invalid poll timeout %q", field)
+			}
+			options.Timeout = timeout
+		default:
+			return PollOptions{}, fmt.Errorf("invalid poll option %q", field)
+		}
+	}
+
+	return options, nil
+}
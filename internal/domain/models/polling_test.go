@@ -0,0 +1,51 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePollDirective(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    PollOptions
+		wantErr bool
+	}{
+		{value: "", want: PollOptions{Enabled: true}},
+		{value: "off", want: PollOptions{}},
+		{value: "interval=500ms timeout=2m", want: PollOptions{Enabled: true, Interval: 500 * time.Millisecond, Timeout: 2 * time.Minute}},
+		{value: "interval=soon", wantErr: true},
+		{value: "timeout=-1s", wantErr: true},
+		{value: "forever", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParsePollDirective(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			// The formatted options parse back to the same options
+			again, err := ParsePollDirective(got.String())
+			require.NoError(t, err)
+			assert.Equal(t, got, again)
+		})
+	}
+}
+
+func TestPollOptionsMerge(t *testing.T) {
+	defaults := DefaultPollOptions()
+
+	merged := PollOptions{Enabled: true, Timeout: 5 * time.Minute}.Merge(defaults)
+	assert.Equal(t, PollOptions{Enabled: true, Interval: time.Second, Timeout: 5 * time.Minute}, merged)
+
+	// Disabling polling is kept
+	assert.False(t, PollOptions{}.Merge(defaults).Enabled)
+}
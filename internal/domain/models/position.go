@@ -0,0 +1,43 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Position is a place in a source file, with lines and columns starting at 1
+type Position struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// String formats the position as "line:column"
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// ParseError is an error at a position of a file, formatted as compilers do
+// so that editors and CI can point at it
+type ParseError struct {
+	File     string `json:"file"`
+	Position `json:"position"`
+	Message  string `json:"message"`
+}
+
+// Error formats the error as "file:line:column: message"
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s:%s: %s", e.File, e.Position, e.Message)
+}
+
+// ParseErrors are the errors of a file, all reported at once rather than
+// stopping at the first one
+type ParseErrors []*ParseError
+
+// Error lists the errors, one per line
+func (e ParseErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseErrors(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", ParseErrors{
+		{File: "users.http", Position: Position{Line: 3, Column: 1}, Message: `malformed header "{"`},
+		{File: "users.http", Position: Position{Line: 9, Column: 3}, Message: "invalid @priority directive"},
+	})
+
+	assert.Equal(t, "wrapped: users.http:3:1: malformed header \"{\"\nusers.http:9:3: invalid @priority directive", err.Error())
+
+	var parseErrors ParseErrors
+	if assert.True(t, errors.As(err, &parseErrors)) {
+		assert.Equal(t, 9, parseErrors[1].Line)
+	}
+}
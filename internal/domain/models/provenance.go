@@ -0,0 +1,129 @@
+package models
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// Sources of variables recorded in their provenance
+const (
+	VariableSourceBaseURL     = "base-url"    // Selected server of the spec
+	VariableSourceEnvironment = "environment" // Environment variables of the run
+	VariableSourceFile        = "file"        // Variable files scoped to the .http file
+	VariableSourceDataRow     = "data-row"    // Row of the data file
+	VariableSourceSequence    = "sequence"    // Variables declared by the sequence
+	VariableSourceExtracted   = "extracted"   // Extracted from the response of a step
+	VariableSourceChained     = "chained"     // Referenced from the response of an earlier request
+	VariableSourcePrompt      = "prompt"      // Typed in when the run asked for a missing variable
+	VariableSourceCLI         = "cli"         // Given on the command line with --var
+)
+
+// VariableOrigin is one value a variable took and where it came from
+type VariableOrigin struct {
+	Source    string `json:"source"`              // One of the VariableSource constants
+	Step      string `json:"step,omitempty"`      // Step or request whose response produced the value, or the data row
+	Extractor string `json:"extractor,omitempty"` // How the value was read, e.g. "body $.token"
+	Raw       string `json:"raw,omitempty"`       // Value read before the extractor's regexp applied
+	Value     string `json:"value"`
+}
+
+// VariableProvenance is the final value of a variable and the origin chain of
+// the values it took, oldest first, the last one producing the final value
+type VariableProvenance struct {
+	Scope   string           `json:"scope"` // Sequence or .http file the variable belongs to
+	Name    string           `json:"name"`
+	Value   string           `json:"value"`
+	Origins []VariableOrigin `json:"origins"`
+}
+
+// Origin returns the origin of the final value
+func (p VariableProvenance) Origin() VariableOrigin {
+	if len(p.Origins) == 0 {
+		return VariableOrigin{Value: p.Value}
+	}
+	return p.Origins[len(p.Origins)-1]
+}
+
+// VariableTracker records the provenance of the variables of a run. It is safe
+// for concurrent use; a nil tracker records nothing.
+type VariableTracker struct {
+	mu        sync.Mutex
+	variables map[[2]string]*VariableProvenance
+}
+
+// NewVariableTracker creates an empty tracker
+func NewVariableTracker() *VariableTracker {
+	return &VariableTracker{variables: make(map[[2]string]*VariableProvenance)}
+}
+
+// Record records a value a variable of a scope took. A value recorded again
+// from an origin already in the chain, as every request of a file resolves
+// the same variables, doesn't extend it.
+func (t *VariableTracker) Record(scope, name string, origin VariableOrigin) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := [2]string{scope, name}
+	variable, ok := t.variables[key]
+	if !ok {
+		variable = &VariableProvenance{Scope: scope, Name: name}
+		t.variables[key] = variable
+	}
+	for _, recorded := range variable.Origins {
+		if recorded == origin {
+			return
+		}
+	}
+	variable.Origins = append(variable.Origins, origin)
+	variable.Value = origin.Value
+}
+
+// RecordAll records the values of variables coming from the same source
+func (t *VariableTracker) RecordAll(scope, source string, values map[string]string) {
+	for name, value := range values {
+		t.Record(scope, name, VariableOrigin{Source: source, Value: value})
+	}
+}
+
+// Provenance returns the recorded variables sorted by scope and name
+func (t *VariableTracker) Provenance() []VariableProvenance {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	provenance := make([]VariableProvenance, 0, len(t.variables))
+	for _, variable := range t.variables {
+		copied := *variable
+		copied.Origins = append([]VariableOrigin(nil), variable.Origins...)
+		provenance = append(provenance, copied)
+	}
+	sort.Slice(provenance, func(i, j int) bool {
+		if provenance[i].Scope != provenance[j].Scope {
+			return provenance[i].Scope < provenance[j].Scope
+		}
+		return provenance[i].Name < provenance[j].Name
+	})
+	return provenance
+}
+
+// trackerKey is the context key of the variable tracker of a run
+type trackerKey struct{}
+
+// ContextWithVariableTracker returns a context carrying the variable tracker
+// of a run
+func ContextWithVariableTracker(ctx context.Context, tracker *VariableTracker) context.Context {
+	return context.WithValue(ctx, trackerKey{}, tracker)
+}
+
+// VariableTrackerFromContext returns the variable tracker of the run a context
+// belongs to, nil outside of a run
+func VariableTrackerFromContext(ctx context.Context) *VariableTracker {
+	tracker, _ := ctx.Value(trackerKey{}).(*VariableTracker)
+	return tracker
+}
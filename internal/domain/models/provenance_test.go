@@ -0,0 +1,41 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVariableTracker(t *testing.T) {
+	tracker := NewVariableTracker()
+	tracker.RecordAll("checkout", VariableSourceEnvironment, map[string]string{"token": "env-token", "host": "localhost"})
+	tracker.Record("checkout", "token", VariableOrigin{
+		Source:    VariableSourceExtracted,
+		Step:      "login",
+		Extractor: "header Authorization",
+		Raw:       "Bearer abc",
+		Value:     "abc",
+	})
+	tracker.RecordAll("checkout", VariableSourceEnvironment, map[string]string{"token": "env-token", "host": "localhost"})
+
+	provenance := tracker.Provenance()
+	require.Len(t, provenance, 2)
+	assert.Equal(t, "host", provenance[0].Name)
+	assert.Len(t, provenance[0].Origins, 1, "the same value from the same origin isn't repeated")
+
+	token := provenance[1]
+	assert.Equal(t, "abc", token.Value)
+	require.Len(t, token.Origins, 2)
+	assert.Equal(t, []string{VariableSourceEnvironment, VariableSourceExtracted}, []string{token.Origins[0].Source, token.Origins[1].Source})
+	assert.Equal(t, "login", token.Origin().Step)
+}
+
+func TestVariableTrackerFromContext(t *testing.T) {
+	assert.Nil(t, VariableTrackerFromContext(context.Background()))
+	VariableTrackerFromContext(context.Background()).Record("file.http", "id", VariableOrigin{Value: "1"})
+
+	tracker := NewVariableTracker()
+	assert.Same(t, tracker, VariableTrackerFromContext(ContextWithVariableTracker(context.Background(), tracker)))
+}
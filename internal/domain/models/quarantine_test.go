@@ -0,0 +1,54 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQuarantine(t *testing.T) {
+	quarantine, err := ParseQuarantine(strings.NewReader(`# Known failures
+createOrder   JIRA-123
+
+orders/*	JIRA-456 flaky payment sandbox
+listUsers
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []QuarantineEntry{
+		{Pattern: "createOrder", Reason: "JIRA-123"},
+		{Pattern: "orders/*", Reason: "JIRA-456 flaky payment sandbox"},
+		{Pattern: "listUsers"},
+	}, quarantine.Entries)
+
+	entry, ok := quarantine.Match("orders/refund")
+	assert.True(t, ok)
+	assert.Equal(t, "JIRA-456 flaky payment sandbox", entry.Reason)
+	_, ok = quarantine.Match("getOrder")
+	assert.False(t, ok)
+
+	_, err = ParseQuarantine(strings.NewReader("orders/[ JIRA-1\n"))
+	assert.ErrorContains(t, err, "line 1")
+}
+
+func TestApplyQuarantine(t *testing.T) {
+	quarantine := &Quarantine{Entries: []QuarantineEntry{{Pattern: "createOrder", Reason: "JIRA-123"}}}
+
+	listed := TestResult{Name: "createOrder", Status: TestStatusFailed}
+	ApplyQuarantine(&listed, quarantine)
+	assert.True(t, listed.Quarantined)
+	assert.Equal(t, "JIRA-123", listed.QuarantineReason)
+	assert.True(t, listed.QuarantinedFailure())
+
+	annotated := TestResult{Name: "getOrder", Status: TestStatusPassed, Request: &HTTPRequest{Quarantined: true, QuarantineReason: "JIRA-9"}}
+	ApplyQuarantine(&annotated, nil)
+	assert.True(t, annotated.Quarantined)
+	assert.Equal(t, "JIRA-9", annotated.QuarantineReason)
+	assert.False(t, annotated.QuarantinedFailure())
+
+	other := TestResult{Name: "listUsers", Status: TestStatusFailed}
+	ApplyQuarantine(&other, quarantine)
+	assert.False(t, other.Quarantined)
+	assert.False(t, other.QuarantinedFailure())
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// ParseQueryParams parses the query string of a request URL into its
+// parameters, keeping variables such as "{{userId}}" as they are; it returns
+// nil for URLs without a query
+func ParseQueryParams(rawURL string) map[string][]string {
+	rawURL, _, _ = strings.Cut(rawURL, "#")
+	_, query, ok := strings.Cut(rawURL, "?")
+	if !ok || query == "" {
+		return nil
+	}
+
+	params := make(map[string][]string)
+	for _, pair := range strings.Split(query, "&") {
+		if pair == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(pair, "=")
+		params[unescapeQuery(name)] = append(params[unescapeQuery(name)], unescapeQuery(value))
+	}
+	return params
+}
+
+// unescapeQuery decodes a query name or value, keeping it as written when it
+// is not validly escaped, e.g. "100%"
+func unescapeQuery(s string) string {
+	if unescaped, err := url.QueryUnescape(s); err == nil {
+		return unescaped
+	}
+	return s
+}
+
+// EncodeQuery replaces the query string of a URL with the given parameters,
+// sorted by name, escaping their names and values; URLs are returned as they
+// are when there are no parameters
+func EncodeQuery(rawURL string, params map[string][]string) string {
+	if len(params) == 0 {
+		return rawURL
+	}
+	rawURL, fragment, hasFragment := strings.Cut(rawURL, "#")
+	rawURL, _, _ = strings.Cut(rawURL, "?")
+	rawURL += "?" + url.Values(params).Encode()
+	if hasFragment {
+		rawURL += "#" + fragment
+	}
+	return rawURL
+}
+
+// CloneQueryParams copies query parameters so they can be changed without
+// changing the request they come from
+func CloneQueryParams(params map[string][]string) map[string][]string {
+	if params == nil {
+		return nil
+	}
+	clone := make(map[string][]string, len(params))
+	for name, values := range params {
+		clone[name] = append([]string(nil), values...)
+	}
+	return clone
+}
+
+// ParseQueryOverrides parses "name=value" assignments overriding the value of
+// query parameters, e.g. from the command line
+func ParseQueryOverrides(assignments []string) (map[string]string, error) {
+	overrides := make(map[string]string, len(assignments))
+	for _, assignment := range assignments {
+		name, value, ok := strings.Cut(assignment, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid query parameter %q: use name=value", assignment)
+		}
+		overrides[strings.TrimSpace(name)] = value
+	}
+	return overrides, nil
+}
+
+// OverrideQueryParams returns the parameters with the values of the overridden
+// ones replaced, leaving out the parameters the request doesn't have, and the
+// names of the overridden parameters
+func OverrideQueryParams(params map[string][]string, overrides map[string]string) (map[string][]string, []string) {
+	var names []string
+	for name := range overrides {
+		if _, ok := params[name]; ok {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return params, nil
+	}
+	sort.Strings(names)
+
+	params = CloneQueryParams(params)
+	for _, name := range names {
+		params[name] = []string{overrides[name]}
+	}
+	return params, names
+}
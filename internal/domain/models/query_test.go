@@ -0,0 +1,64 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQueryParams(t *testing.T) {
+	params := ParseQueryParams("https://api.example.com/users?status=active&id=1&id={{userId}}&q=a%20b#top")
+	assert.Equal(t, map[string][]string{
+		"status": {"active"},
+		"id":     {"1", "{{userId}}"},
+		"q":      {"a b"},
+	}, params)
+
+	assert.Nil(t, ParseQueryParams("https://api.example.com/users"))
+	assert.Equal(t, map[string][]string{"discount": {"100%"}}, ParseQueryParams("/prices?discount=100%"))
+}
+
+func TestEncodeQuery(t *testing.T) {
+	tests := []struct {
+		name   string
+		url    string
+		params map[string][]string
+		want   string
+	}{
+		{
+			name:   "escapes values",
+			url:    "https://api.example.com/search?q=old",
+			params: map[string][]string{"q": {"a&b c"}},
+			want:   "https://api.example.com/search?q=a%26b+c",
+		},
+		{
+			name:   "replaces the query",
+			url:    "https://api.example.com/users?size=10#top",
+			params: map[string][]string{"status": {"active"}, "id": {"1", "2"}},
+			want:   "https://api.example.com/users?id=1&id=2&status=active#top",
+		},
+		{
+			name: "no parameters",
+			url:  "https://api.example.com/users?b=1&a=2",
+			want: "https://api.example.com/users?b=1&a=2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, EncodeQuery(tt.url, tt.params))
+		})
+	}
+}
+
+func TestOverrideQueryParams(t *testing.T) {
+	params := map[string][]string{"status": {"active"}, "page": {"1"}}
+
+	overridden, names := OverrideQueryParams(params, map[string]string{"status": "archived", "sort": "name"})
+	assert.Equal(t, []string{"status"}, names)
+	assert.Equal(t, map[string][]string{"status": {"archived"}, "page": {"1"}}, overridden)
+	assert.Equal(t, []string{"active"}, params["status"])
+
+	_, err := ParseQueryOverrides([]string{"status"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// SeedEnvironmentKey is the report environment key recording the seed of a run
+const SeedEnvironmentKey = "seed"
+
+// Random is the source of randomness of a test run, such as retry jitter.
+// Runs with the same seed draw the same numbers, so a failing run can be
+// replayed. It is safe for concurrent use, but the numbers tests running in
+// parallel draw depend on the order they draw them in.
+type Random struct {
+	seed int64
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// NewRandom creates a source of randomness from a seed
+func NewRandom(seed int64) *Random {
+	return &Random{seed: seed, rand: rand.New(rand.NewSource(seed))}
+}
+
+// NewSeed picks a seed for runs that weren't given one
+func NewSeed() int64 {
+	return time.Now().UnixNano()
+}
+
+// Seed returns the seed the source was created with
+func (r *Random) Seed() int64 {
+	return r.seed
+}
+
+// Float64 returns a number in [0, 1)
+func (r *Random) Float64() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rand.Float64()
+}
+
+// Intn returns a number in [0, n)
+func (r *Random) Intn(n int) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rand.Intn(n)
+}
+
+// randomKey is the context key of the source of randomness of a run
+type randomKey struct{}
+
+// unseeded is the source of randomness used outside of a run
+var unseeded = NewRandom(NewSeed())
+
+// ContextWithRandom returns a context carrying the source of randomness of a run
+func ContextWithRandom(ctx context.Context, random *Random) context.Context {
+	return context.WithValue(ctx, randomKey{}, random)
+}
+
+// RandomFromContext returns the source of randomness of the run a context
+// belongs to, or a randomly seeded one outside of a run
+func RandomFromContext(ctx context.Context) *Random {
+	if random, ok := ctx.Value(randomKey{}).(*Random); ok {
+		return random
+	}
+	return unseeded
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRandomIsReproducible(t *testing.T) {
+	a, b := NewRandom(42), NewRandom(42)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, a.Float64(), b.Float64())
+		assert.Equal(t, a.Intn(100), b.Intn(100))
+	}
+	assert.Equal(t, int64(42), a.Seed())
+}
+
+func TestRandomFromContext(t *testing.T) {
+	random := NewRandom(7)
+	assert.Same(t, random, RandomFromContext(ContextWithRandom(context.Background(), random)))
+	assert.NotNil(t, RandomFromContext(context.Background()))
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimit is the number of requests allowed per period, e.g. 10 per second
+type RateLimit struct {
+	Requests int           `json:"requests"`
+	Per      time.Duration `json:"per"`
+}
+
+// ParseRateLimit parses a rate limit written as "<requests>/<period>", where the
+// period is a unit (s, m, h, or second, minute, hour) or a duration such as 30s
+func ParseRateLimit(value string) (RateLimit, error) {
+	requests, period, ok := strings.Cut(strings.ReplaceAll(value, " ", ""), "/")
+	if !ok {
+		return RateLimit{}, fmt.Errorf("invalid rate limit %q: use <requests>/<period>, e.g. 10/s", value)
+	}
+
+	count, err := strconv.Atoi(requests)
+	if err != nil || count <= 0 {
+		return RateLimit{}, fmt.Errorf("invalid rate limit %q: the number of requests must be a positive integer", value)
+	}
+
+	per, err := parseRatePeriod(period)
+	if err != nil {
+		return RateLimit{}, fmt.Errorf("invalid rate limit %q: %w", value, err)
+	}
+
+	return RateLimit{Requests: count, Per: per}, nil
+}
+
+// parseRatePeriod parses the period of a rate limit
+func parseRatePeriod(period string) (time.Duration, error) {
+	switch strings.ToLower(period) {
+	case "s", "sec", "second":
+		return time.Second, nil
+	case "m", "min", "minute":
+		return time.Minute, nil
+	case "h", "hour":
+		return time.Hour, nil
+	}
+
+	per, err := time.ParseDuration(period)
+	if err != nil || per <= 0 {
+		return 0, fmt.Errorf("invalid period %q", period)
+	}
+	return per, nil
+}
+
+// Interval returns the time between two requests spread evenly over the period
+func (r RateLimit) Interval() time.Duration {
+	if r.Requests <= 0 {
+		return 0
+	}
+	return r.Per / time.Duration(r.Requests)
+}
+
+// String formats the rate limit as parsed by ParseRateLimit, e.g. "10/s"
+func (r RateLimit) String() string {
+	switch r.Per {
+	case time.Second:
+		return fmt.Sprintf("%d/s", r.Requests)
+	case time.Minute:
+		return fmt.Sprintf("%d/m", r.Requests)
+	case time.Hour:
+		return fmt.Sprintf("%d/h", r.Requests)
+	}
+	return fmt.Sprintf("%d/%s", r.Requests, r.Per)
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxRedirects is the number of redirects followed unless configured
+const DefaultMaxRedirects = 10
+
+// RedirectPolicy configures whether and how far redirects are followed
+type RedirectPolicy struct {
+	Follow bool `json:"follow"`
+	Max    int  `json:"max,omitempty"` // Redirects followed before the request fails, DefaultMaxRedirects when 0
+}
+
+// RedirectHop is a redirect response followed on the way to the final response
+type RedirectHop struct {
+	StatusCode int    `json:"statusCode"`
+	URL        string `json:"url"`      // URL that answered with the redirect
+	Location   string `json:"location"` // Location header of the redirect
+}
+
+// DefaultRedirectPolicy follows up to DefaultMaxRedirects redirects
+func DefaultRedirectPolicy() RedirectPolicy {
+	return RedirectPolicy{Follow: true, Max: DefaultMaxRedirects}
+}
+
+// MaxRedirects returns the number of redirects the policy follows
+func (p RedirectPolicy) MaxRedirects() int {
+	if !p.Follow {
+		return 0
+	}
+	if p.Max <= 0 {
+		return DefaultMaxRedirects
+	}
+	return p.Max
+}
+
+// String formats the policy as the argument of a "@redirects" directive
+func (p RedirectPolicy) String() string {
+	if !p.Follow {
+		return "off"
+	}
+	return "max=" + strconv.Itoa(p.MaxRedirects())
+}
+
+// ParseRedirectDirective parses the argument of a "@redirects" directive,
+// "off" or "max=<n>"
+func ParseRedirectDirective(value string) (RedirectPolicy, error) {
+	value = strings.TrimSpace(value)
+	if value == "off" {
+		return RedirectPolicy{}, nil
+	}
+	if strings.HasPrefix(value, "max=") {
+		max, err := strconv.Atoi(strings.TrimPrefix(value, "max="))
+		if err == nil && max > 0 {
+			return RedirectPolicy{Follow: true, Max: max}, nil
+		}
+	}
+	return RedirectPolicy{}, fmt.Errorf("invalid redirects %q: use off or max=<n>", value)
+}
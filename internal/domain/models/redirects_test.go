@@ -0,0 +1,28 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRedirectDirective(t *testing.T) {
+	policy, err := ParseRedirectDirective("off")
+	require.NoError(t, err)
+	assert.False(t, policy.Follow)
+	assert.Equal(t, 0, policy.MaxRedirects())
+	assert.Equal(t, "off", policy.String())
+
+	policy, err = ParseRedirectDirective("max=3")
+	require.NoError(t, err)
+	assert.Equal(t, RedirectPolicy{Follow: true, Max: 3}, policy)
+	assert.Equal(t, "max=3", policy.String())
+
+	for _, value := range []string{"", "on", "max=0", "max=x"} {
+		_, err := ParseRedirectDirective(value)
+		assert.Error(t, err, value)
+	}
+
+	assert.Equal(t, DefaultMaxRedirects, RedirectPolicy{Follow: true}.MaxRedirects())
+}
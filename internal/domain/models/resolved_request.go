@@ -0,0 +1,130 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// MaskedValue replaces the secrets of a resolved request
+const MaskedValue = "***"
+
+// sensitiveHeaders are headers whose values are always masked
+var sensitiveHeaders = map[string]bool{
+	"authorization":       true,
+	"proxy-authorization": true,
+	"cookie":              true,
+	"set-cookie":          true,
+}
+
+// sensitiveNameParts mark header and query parameter names holding secrets,
+// such as X-Api-Key or access_token
+var sensitiveNameParts = []string{"token", "secret", "password", "apikey", "api-key", "api_key", "credential", "signature"}
+
+// ResolvedRequest is a request as it was sent, after variable substitution,
+// with its secrets masked
+type ResolvedRequest struct {
+	Method  string              `json:"method"`
+	URL     string              `json:"url"`
+	Headers map[string][]string `json:"headers,omitempty"`
+	Body    string              `json:"body,omitempty"`
+}
+
+// NewResolvedRequest records a request as sent, masking the values of
+// sensitive headers and query parameters
+func NewResolvedRequest(method, rawURL string, headers map[string][]string, body string) *ResolvedRequest {
+	resolved := &ResolvedRequest{Method: method, URL: maskURL(rawURL), Body: body}
+	if len(headers) > 0 {
+		resolved.Headers = make(map[string][]string, len(headers))
+		for name, values := range headers {
+			masked := make([]string, len(values))
+			for i, value := range values {
+				masked[i] = MaskHeader(name, value)
+			}
+			resolved.Headers[name] = masked
+		}
+	}
+	return resolved
+}
+
+// IsSensitiveName reports whether a header or query parameter name suggests
+// its value is a secret
+func IsSensitiveName(name string) bool {
+	name = strings.ToLower(name)
+	if sensitiveHeaders[name] {
+		return true
+	}
+	for _, part := range sensitiveNameParts {
+		if strings.Contains(name, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// MaskHeader returns the value of a header with secrets masked, keeping the
+// scheme of credentials such as "Bearer ***"
+func MaskHeader(name, value string) string {
+	if !IsSensitiveName(name) {
+		return value
+	}
+	if scheme, _, found := strings.Cut(value, " "); found && strings.HasSuffix(strings.ToLower(name), "authorization") {
+		return scheme + " " + MaskedValue
+	}
+	return MaskedValue
+}
+
+// maskURL masks the values of sensitive query parameters and the password
+// of a URL, leaving the rest of it as written
+func maskURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	masked := rawURL
+	if password, hasPassword := parsed.User.Password(); hasPassword && password != "" {
+		masked = strings.Replace(masked, ":"+password+"@", ":"+MaskedValue+"@", 1)
+	}
+
+	base, query, found := strings.Cut(masked, "?")
+	if !found {
+		return masked
+	}
+	query, fragment, hasFragment := strings.Cut(query, "#")
+	params := strings.Split(query, "&")
+	for i, param := range params {
+		name, _, hasValue := strings.Cut(param, "=")
+		if unescaped, err := url.QueryUnescape(name); err == nil && hasValue && IsSensitiveName(unescaped) {
+			params[i] = name + "=" + MaskedValue
+		}
+	}
+	masked = base + "?" + strings.Join(params, "&")
+	if hasFragment {
+		masked += "#" + fragment
+	}
+	return masked
+}
+
+// String formats the request like in a .http file: the request line, the
+// headers in name order and the body after an empty line
+func (r *ResolvedRequest) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %s\n", r.Method, r.URL)
+
+	names := make([]string, 0, len(r.Headers))
+	for name := range r.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range r.Headers[name] {
+			fmt.Fprintf(&sb, "%s: %s\n", name, value)
+		}
+	}
+
+	if r.Body != "" {
+		fmt.Fprintf(&sb, "\n%s\n", r.Body)
+	}
+	return sb.String()
+}
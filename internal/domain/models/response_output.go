@@ -0,0 +1,114 @@
+package models
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// responseOutputPattern matches a line saving the response of a request to a
+// file: ">> path", or ">>! path" to overwrite the file
+var responseOutputPattern = regexp.MustCompile(`^>>(!?)[ \t]+(\S.*?)\s*$`)
+
+// responseOutputVariable matches a variable of an output path, e.g. {{status}}
+var responseOutputVariable = regexp.MustCompile(`{{\s*([^{}\s]+)\s*}}`)
+
+// ResponseOutput is a file the response body of a request is saved to when
+// it runs, from a ">> path" line after the request
+type ResponseOutput struct {
+	// File to write, relative to the .http file, which may hold {{name}},
+	// {{method}} and {{status}} and the variables of the request
+	Path string `json:"path"`
+
+	// Whether an existing file is replaced, from ">>!". Otherwise a number is
+	// added to the name, e.g. "response-1.json".
+	Overwrite bool `json:"overwrite,omitempty"`
+
+	// Line of the output in the .http file, starting at 1
+	Line int `json:"line,omitempty"`
+}
+
+// ParseResponseOutput parses a line saving the response to a file, e.g.
+// ">> ./out/response.json" or ">>! ./out/{{name}}-{{status}}.json"
+func ParseResponseOutput(line string) (ResponseOutput, bool) {
+	matches := responseOutputPattern.FindStringSubmatch(line)
+	if matches == nil {
+		return ResponseOutput{}, false
+	}
+	return ResponseOutput{Path: matches[2], Overwrite: matches[1] == "!"}, true
+}
+
+// String returns the output as written after a request
+func (o ResponseOutput) String() string {
+	if o.Overwrite {
+		return ">>! " + o.Path
+	}
+	return ">> " + o.Path
+}
+
+// Resolve returns the path of the output file of a response, replacing the
+// variables of the path and resolving a relative path against the directory
+// of the .http file. Variables without a value are left as written.
+func (o ResponseOutput) Resolve(request *HTTPRequest, response *HTTPResponse, variables map[string]string) string {
+	values := map[string]string{
+		"name":   spillNamePattern.ReplaceAllString(request.Name, "-"),
+		"method": strings.ToUpper(request.Method),
+	}
+	if response != nil {
+		values["status"] = strconv.Itoa(response.StatusCode)
+	}
+	path := responseOutputVariable.ReplaceAllStringFunc(o.Path, func(match string) string {
+		name := responseOutputVariable.FindStringSubmatch(match)[1]
+		if value, ok := values[name]; ok {
+			return value
+		}
+		if value, ok := variables[name]; ok {
+			return value
+		}
+		return match
+	})
+
+	if !filepath.IsAbs(path) && request.Path != "" {
+		path = filepath.Join(filepath.Dir(request.Path), path)
+	}
+	return path
+}
+
+// WriteResponseOutputs saves the response body of a request to the files of
+// its outputs, creating their directories, and returns the paths written
+func WriteResponseOutputs(request *HTTPRequest, response *HTTPResponse, variables map[string]string) ([]string, error) {
+	var written []string
+	for _, output := range request.Outputs {
+		path := output.Resolve(request, response, variables)
+		if !output.Overwrite {
+			path = freePath(path)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return written, fmt.Errorf("failed to create output directory: %w", err)
+		}
+		if err := os.WriteFile(path, []byte(response.Body), 0644); err != nil {
+			return written, fmt.Errorf("failed to save response to %s: %w", path, err)
+		}
+		written = append(written, path)
+	}
+	return written, nil
+}
+
+// freePath returns path, or the first free path with a number added to its
+// name when a file exists, e.g. "response-1.json"
+func freePath(path string) string {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return path
+	}
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s-%d%s", base, i, ext)
+		if _, err := os.Stat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseResponseOutput(t *testing.T) {
+	output, ok := ParseResponseOutput(">> ./out/response.json")
+	require.True(t, ok)
+	assert.Equal(t, ResponseOutput{Path: "./out/response.json"}, output)
+	assert.Equal(t, ">> ./out/response.json", output.String())
+
+	output, ok = ParseResponseOutput(">>! out/{{name}}-{{status}}.json")
+	require.True(t, ok)
+	assert.Equal(t, ResponseOutput{Path: "out/{{name}}-{{status}}.json", Overwrite: true}, output)
+	assert.Equal(t, ">>! out/{{name}}-{{status}}.json", output.String())
+
+	for _, line := range []string{">>", ">> ", ">>out.json", "> out.json", "# >> out.json"} {
+		_, ok := ParseResponseOutput(line)
+		assert.False(t, ok, line)
+	}
+}
+
+func TestWriteResponseOutputs(t *testing.T) {
+	dir := t.TempDir()
+	request := &HTTPRequest{
+		Name:   "get user",
+		Method: "get",
+		Path:   filepath.Join(dir, "users.http"),
+		Outputs: []ResponseOutput{
+			{Path: "out/{{name}}-{{status}}.json"},
+			{Path: "out/{{env}}/{{method}}-{{unknown}}.json", Overwrite: true},
+		},
+	}
+	response := &HTTPResponse{StatusCode: 200, Body: `{"id": 1}`}
+	variables := map[string]string{"env": "staging"}
+
+	written, err := WriteResponseOutputs(request, response, variables)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "out", "get-user-200.json"),
+		filepath.Join(dir, "out", "staging", "GET-{{unknown}}.json"),
+	}, written)
+
+	// Existing files are kept unless the output overwrites them
+	written, err = WriteResponseOutputs(request, response, variables)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "out", "get-user-200-1.json"),
+		filepath.Join(dir, "out", "staging", "GET-{{unknown}}.json"),
+	}, written)
+
+	data, err := os.ReadFile(written[0])
+	require.NoError(t, err)
+	assert.Equal(t, `{"id": 1}`, string(data))
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// RetryOptions configures automatic retries of idempotent requests answered
+// with a transient error status. Retries are off unless Max is positive.
+type RetryOptions struct {
+	Max            int           `json:"max"`                      // Retries after the first attempt, 0 for none
+	InitialBackoff time.Duration `json:"initialBackoff,omitempty"` // Delay before the first retry, doubled for each following one
+	MaxBackoff     time.Duration `json:"maxBackoff,omitempty"`     // Longest delay between attempts, Retry-After included
+}
+
+// RetryableStatusCodes are the statuses of responses worth retrying
+var RetryableStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// DefaultRetryOptions doesn't retry, but backs off from half a second up to
+// 30 seconds once retries are enabled
+func DefaultRetryOptions() RetryOptions {
+	return RetryOptions{InitialBackoff: 500 * time.Millisecond, MaxBackoff: 30 * time.Second}
+}
+
+// Merge returns the options with the unset backoffs taken from defaults
+func (o RetryOptions) Merge(defaults RetryOptions) RetryOptions {
+	if o.InitialBackoff <= 0 {
+		o.InitialBackoff = defaults.InitialBackoff
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = defaults.MaxBackoff
+	}
+	return o
+}
+
+// Backoff returns the delay before a retry, counted from 0, given a random
+// number in [0, 1): the exponential backoff capped at MaxBackoff, of which
+// the upper half is jittered so that clients retrying together spread out
+func (o RetryOptions) Backoff(retry int, random float64) time.Duration {
+	backoff := o.InitialBackoff
+	for i := 0; i < retry && (o.MaxBackoff <= 0 || backoff < o.MaxBackoff); i++ {
+		backoff *= 2
+	}
+	if o.MaxBackoff > 0 && backoff > o.MaxBackoff {
+		backoff = o.MaxBackoff
+	}
+	return backoff/2 + time.Duration(random*float64(backoff/2))
+}
+
+// Cap limits a delay, such as one asked for by Retry-After, to MaxBackoff
+func (o RetryOptions) Cap(delay time.Duration) time.Duration {
+	if o.MaxBackoff > 0 && delay > o.MaxBackoff {
+		return o.MaxBackoff
+	}
+	return delay
+}
+
+// IsRetryableStatus reports whether a response status is worth retrying
+func IsRetryableStatus(statusCode int) bool {
+	for _, code := range RetryableStatusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// IsIdempotentMethod reports whether repeating a request with the method has
+// the same effect as sending it once, which makes it safe to retry
+func IsIdempotentMethod(method string) bool {
+	switch strings.ToUpper(method) {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryOptionsBackoff(t *testing.T) {
+	options := RetryOptions{Max: 5, InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+
+	assert.Equal(t, 50*time.Millisecond, options.Backoff(0, 0))
+	assert.Equal(t, 100*time.Millisecond, options.Backoff(0, 0.9999999999).Round(time.Millisecond))
+	assert.Equal(t, 200*time.Millisecond, options.Backoff(2, 0))
+	assert.Equal(t, 500*time.Millisecond, options.Backoff(10, 0))
+	assert.Equal(t, time.Second, options.Backoff(100, 0.9999999999).Round(time.Millisecond))
+
+	assert.Equal(t, time.Second, options.Cap(time.Hour))
+	assert.Equal(t, 2*time.Millisecond, options.Cap(2*time.Millisecond))
+}
+
+func TestRetryOptionsMerge(t *testing.T) {
+	merged := RetryOptions{Max: 2}.Merge(DefaultRetryOptions())
+	assert.Equal(t, RetryOptions{Max: 2, InitialBackoff: 500 * time.Millisecond, MaxBackoff: 30 * time.Second}, merged)
+}
+
+func TestRetryable(t *testing.T) {
+	assert.True(t, IsRetryableStatus(503))
+	assert.True(t, IsRetryableStatus(429))
+	assert.False(t, IsRetryableStatus(500))
+
+	assert.True(t, IsIdempotentMethod("get"))
+	assert.True(t, IsIdempotentMethod("PUT"))
+	assert.False(t, IsIdempotentMethod("POST"))
+	assert.False(t, IsIdempotentMethod("PATCH"))
+}
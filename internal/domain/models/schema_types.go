@@ -0,0 +1,58 @@
+package models
+
+import "gopkg.in/yaml.v3"
+
+// SchemaTypeNull is the type of null values, listed along another type by
+// nullable schemas of OpenAPI 3.1
+const SchemaTypeNull = "null"
+
+// splitSchemaType returns the type of a schema decoded as a string, or as a
+// list of types, and whether it's nullable. Only the first type other than
+// "null" of a list is kept.
+func splitSchemaType(value interface{}, nullable bool) (string, bool) {
+	switch value := value.(type) {
+	case string:
+		return value, nullable
+	case []interface{}:
+		schemaType := ""
+		for _, item := range value {
+			name, _ := item.(string)
+			if name == SchemaTypeNull {
+				nullable = true
+			} else if schemaType == "" {
+				schemaType = name
+			}
+		}
+		return schemaType, nullable
+	}
+	return "", nullable
+}
+
+// scalarTypeNode returns a schema mapping whose type is a list of types as a
+// copy with the first type other than "null", and whether the list has "null"
+func scalarTypeNode(node *yaml.Node) (*yaml.Node, bool) {
+	mapping := node
+	if mapping.Kind == yaml.AliasNode {
+		mapping = mapping.Alias
+	}
+	if mapping.Kind != yaml.MappingNode {
+		return node, false
+	}
+
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != "type" || mapping.Content[i+1].Kind != yaml.SequenceNode {
+			continue
+		}
+		var types []interface{}
+		if err := mapping.Content[i+1].Decode(&types); err != nil {
+			return node, false
+		}
+		schemaType, nullable := splitSchemaType(types, false)
+
+		copied := *mapping
+		copied.Content = append([]*yaml.Node(nil), mapping.Content...)
+		copied.Content[i+1] = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: schemaType}
+		return &copied, nullable
+	}
+	return node, false
+}
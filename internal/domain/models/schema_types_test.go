@@ -0,0 +1,29 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestSchemaTypeList(t *testing.T) {
+	var fromJSON Schema
+	require.NoError(t, json.Unmarshal([]byte(`{"type": ["null", "string"], "x-faker": "name.firstName"}`), &fromJSON))
+	assert.Equal(t, "string", fromJSON.Type)
+	assert.True(t, fromJSON.Nullable)
+	assert.Equal(t, "name.firstName", fromJSON.Extensions["x-faker"])
+
+	var fromYAML Schema
+	require.NoError(t, yaml.Unmarshal([]byte("type: object\nproperties:\n  tags:\n    type: [array, 'null']\n    items: {type: string}\n"), &fromYAML))
+	assert.False(t, fromYAML.Nullable)
+	require.Contains(t, fromYAML.Properties, "tags")
+	assert.Equal(t, "array", fromYAML.Properties["tags"].Type)
+	assert.True(t, fromYAML.Properties["tags"].Nullable)
+
+	var nullable Schema
+	require.NoError(t, yaml.Unmarshal([]byte("type: integer\nnullable: true\n"), &nullable))
+	assert.Equal(t, Schema{Type: "integer", Nullable: true}, nullable)
+}
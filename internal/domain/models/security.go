@@ -0,0 +1,98 @@
+package models
+
+import (
+	"sort"
+	"strings"
+)
+
+// OperationSecurity returns the security requirements of an operation: its
+// own, or the document's when it declares none. An operation declaring an
+// empty list opts out of the document's requirements.
+func (d *SwaggerDoc) OperationSecurity(operation *Operation) []map[string][]string {
+	if operation.Security != nil {
+		return operation.Security
+	}
+	return d.Security
+}
+
+// SecuritySchemeNamed returns the security scheme of the document with a
+// name, from the components of OpenAPI 3.0 or the securityDefinitions of
+// Swagger 2.0
+func (d *SwaggerDoc) SecuritySchemeNamed(name string) (SecurityScheme, bool) {
+	if d.Components != nil {
+		if scheme, ok := d.Components.SecuritySchemes[name]; ok {
+			return scheme, true
+		}
+	}
+	scheme, ok := d.SecurityDefinitions[name]
+	return scheme, ok
+}
+
+// AllowsAnonymous reports whether requests may meet security requirements
+// without credentials: there are none, or one of them is empty
+func AllowsAnonymous(requirements []map[string][]string) bool {
+	if len(requirements) == 0 {
+		return true
+	}
+	for _, requirement := range requirements {
+		if len(requirement) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// SecuritySchemeNames returns the names of the schemes security requirements
+// use, sorted
+func SecuritySchemeNames(requirements []map[string][]string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, requirement := range requirements {
+		for name := range requirement {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SecurityScopes returns the scopes security requirements list, such as the
+// OAuth scopes of an operation, sorted and without duplicates
+func SecurityScopes(requirements []map[string][]string) []string {
+	seen := make(map[string]bool)
+	var scopes []string
+	for _, requirement := range requirements {
+		for _, list := range requirement {
+			for _, scope := range list {
+				if scope != "" && !seen[scope] {
+					seen[scope] = true
+					scopes = append(scopes, scope)
+				}
+			}
+		}
+	}
+	sort.Strings(scopes)
+	return scopes
+}
+
+// MatchScopes reports whether a request requiring scopes may be made with a
+// token granted the given ones: they hold all the scopes it requires, or no
+// scopes are given
+func MatchScopes(granted, required []string) bool {
+	if len(granted) == 0 {
+		return true
+	}
+	held := make(map[string]bool, len(granted))
+	for _, scope := range granted {
+		held[strings.TrimSpace(scope)] = true
+	}
+	for _, scope := range required {
+		if !held[scope] {
+			return false
+		}
+	}
+	return true
+}
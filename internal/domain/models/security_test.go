@@ -0,0 +1,55 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestOperationSecurity(t *testing.T) {
+	var doc SwaggerDoc
+	err := yaml.Unmarshal([]byte(`
+swagger: "2.0"
+security:
+  - apiKey: []
+securityDefinitions:
+  apiKey: {type: apiKey, name: X-API-Key, in: header}
+paths:
+  /users:
+    get: {}
+    post:
+      security:
+        - oauth: [write]
+          apiKey: []
+  /health:
+    get:
+      security: []
+`), &doc)
+	assert.NoError(t, err)
+
+	users := doc.Paths["/users"]
+	assert.Equal(t, []string{"apiKey"}, SecuritySchemeNames(doc.OperationSecurity(users.Get)))
+	assert.Equal(t, []string{"apiKey", "oauth"}, SecuritySchemeNames(doc.OperationSecurity(users.Post)))
+	assert.False(t, AllowsAnonymous(doc.OperationSecurity(users.Get)))
+	assert.True(t, AllowsAnonymous(doc.OperationSecurity(doc.Paths["/health"].Get)))
+	assert.True(t, AllowsAnonymous([]map[string][]string{{"apiKey": nil}, {}}))
+
+	scheme, ok := doc.SecuritySchemeNamed("apiKey")
+	assert.True(t, ok)
+	assert.Equal(t, "X-API-Key", scheme.Name)
+}
+
+func TestSecurityScopes(t *testing.T) {
+	requirements := []map[string][]string{
+		{"oauth": {"write:pets", "read:pets"}, "apiKey": {}},
+		{"openId": {"read:pets", "admin"}},
+	}
+	assert.Equal(t, []string{"admin", "read:pets", "write:pets"}, SecurityScopes(requirements))
+	assert.Empty(t, SecurityScopes([]map[string][]string{{"apiKey": nil}}))
+
+	assert.True(t, MatchScopes(nil, []string{"write:pets"}))
+	assert.True(t, MatchScopes([]string{"read:pets", "write:pets"}, []string{"write:pets"}))
+	assert.True(t, MatchScopes([]string{"read:pets"}, nil))
+	assert.False(t, MatchScopes([]string{"read:pets"}, []string{"read:pets", "write:pets"}))
+}
@@ -0,0 +1,125 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// serverVariablePattern matches the "{name}" placeholders of a server URL
+var serverVariablePattern = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// ServerSelector chooses one of the servers of a document and the values of its variables
+type ServerSelector struct {
+	Index     int               // Index of the server in the document's servers
+	URL       string            // URL of the server, templated or resolved; takes precedence over Index
+	Variables map[string]string // Values of the server variables, defaults are used for the others
+}
+
+// ResolveURL substitutes the variables of the server URL with the values given,
+// or with their defaults. Values must be one of the variable's enum when it has one.
+func (s Server) ResolveURL(values map[string]string) (string, error) {
+	for name := range values {
+		if _, ok := s.Variables[name]; !ok {
+			return "", fmt.Errorf("server %s has no variable %q", s.URL, name)
+		}
+	}
+
+	var resolveErr error
+	resolved := serverVariablePattern.ReplaceAllStringFunc(s.URL, func(placeholder string) string {
+		name := strings.Trim(placeholder, "{}")
+		variable, ok := s.Variables[name]
+		if !ok {
+			if resolveErr == nil {
+				resolveErr = fmt.Errorf("server %s uses undefined variable %q", s.URL, name)
+			}
+			return placeholder
+		}
+
+		value, ok := values[name]
+		if !ok {
+			value = variable.Default
+		}
+		if len(variable.Enum) > 0 && !containsString(variable.Enum, value) && resolveErr == nil {
+			resolveErr = fmt.Errorf("invalid value %q for server variable %q: must be one of %s", value, name, strings.Join(variable.Enum, ", "))
+		}
+		return value
+	})
+	if resolveErr != nil {
+		return "", resolveErr
+	}
+
+	return resolved, nil
+}
+
+// ServerURL returns the resolved URL of the server chosen by the selector. Swagger
+// 2.0 documents have a single server built from their host, base path and schemes.
+func (d *SwaggerDoc) ServerURL(selector ServerSelector) (string, error) {
+	servers := d.Servers
+	if len(servers) == 0 && d.Host != "" {
+		scheme := "https"
+		if len(d.Schemes) > 0 {
+			scheme = d.Schemes[0]
+		}
+		servers = []Server{{URL: fmt.Sprintf("%s://%s%s", scheme, d.Host, d.BasePath)}}
+	}
+	if len(servers) == 0 {
+		if selector.URL != "" || selector.Index != 0 || len(selector.Variables) > 0 {
+			return "", fmt.Errorf("the document defines no servers")
+		}
+		return "", nil
+	}
+
+	if selector.URL != "" {
+		for _, server := range servers {
+			if server.URL == selector.URL {
+				return server.ResolveURL(selector.Variables)
+			}
+		}
+		// Also match the resolved URL of servers using their defaults
+		want := strings.TrimSuffix(selector.URL, "/")
+		for _, server := range servers {
+			if resolved, err := server.ResolveURL(selector.Variables); err == nil && strings.TrimSuffix(resolved, "/") == want {
+				return resolved, nil
+			}
+		}
+		return "", fmt.Errorf("no server matches %s, available servers: %s", selector.URL, serverList(servers))
+	}
+
+	if selector.Index < 0 || selector.Index >= len(servers) {
+		return "", fmt.Errorf("server index %d out of range, available servers: %s", selector.Index, serverList(servers))
+	}
+	return servers[selector.Index].ResolveURL(selector.Variables)
+}
+
+// ParseServerVariables parses "name=value" server variable assignments
+func ParseServerVariables(assignments []string) (map[string]string, error) {
+	variables := make(map[string]string, len(assignments))
+	for _, assignment := range assignments {
+		name, value, ok := strings.Cut(assignment, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid server variable %q: use name=value", assignment)
+		}
+		variables[strings.TrimSpace(name)] = value
+	}
+	return variables, nil
+}
+
+// serverList lists the servers with their indexes for error messages
+func serverList(servers []Server) string {
+	list := make([]string, len(servers))
+	for i, server := range servers {
+		list[i] = fmt.Sprintf("[%d] %s", i, server.URL)
+	}
+	return strings.Join(list, ", ")
+}
+
+// containsString reports whether a slice contains a value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
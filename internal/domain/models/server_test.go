@@ -0,0 +1,64 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerURL(t *testing.T) {
+	doc := &SwaggerDoc{
+		Servers: []Server{
+			{
+				URL: "https://{region}.api.example.com/{basePath}",
+				Variables: map[string]ServerVariable{
+					"region":   {Default: "eu", Enum: []string{"eu", "us"}},
+					"basePath": {Default: "v1"},
+				},
+			},
+			{URL: "http://localhost:8080"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		selector ServerSelector
+		want     string
+		wantErr  string
+	}{
+		{name: "defaults", want: "https://eu.api.example.com/v1"},
+		{name: "variables", selector: ServerSelector{Variables: map[string]string{"region": "us", "basePath": "v2"}}, want: "https://us.api.example.com/v2"},
+		{name: "value outside enum", selector: ServerSelector{Variables: map[string]string{"region": "ap"}}, wantErr: `invalid value "ap" for server variable "region": must be one of eu, us`},
+		{name: "unknown variable", selector: ServerSelector{Variables: map[string]string{"zone": "a"}}, wantErr: `has no variable "zone"`},
+		{name: "index", selector: ServerSelector{Index: 1}, want: "http://localhost:8080"},
+		{name: "index out of range", selector: ServerSelector{Index: 2}, wantErr: "server index 2 out of range"},
+		{name: "templated url", selector: ServerSelector{URL: "https://{region}.api.example.com/{basePath}", Variables: map[string]string{"region": "us"}}, want: "https://us.api.example.com/v1"},
+		{name: "resolved url", selector: ServerSelector{URL: "http://localhost:8080/"}, want: "http://localhost:8080"},
+		{name: "unmatched url", selector: ServerSelector{URL: "https://other.example.com"}, wantErr: "no server matches https://other.example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := doc.ServerURL(tt.selector)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestServerURLFromSwagger2Host(t *testing.T) {
+	doc := &SwaggerDoc{Host: "api.example.com", BasePath: "/v1", Schemes: []string{"http"}}
+	got, err := doc.ServerURL(ServerSelector{})
+	require.NoError(t, err)
+	assert.Equal(t, "http://api.example.com/v1", got)
+
+	got, err = (&SwaggerDoc{}).ServerURL(ServerSelector{})
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
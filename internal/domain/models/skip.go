@@ -0,0 +1,37 @@
+package models
+
+import "path"
+
+// Reasons of the tests skipped by directives and focus
+const (
+	// SkipReasonDefault is the reason of tests marked "@skip" without one
+	SkipReasonDefault = "skipped by @skip"
+
+	// SkipReasonNotFocused is the reason of the tests left out when others
+	// are focused with "@only" or --focus
+	SkipReasonNotFocused = "not focused"
+)
+
+// SkipReason returns why a test doesn't run, if it doesn't: its request is
+// marked "@skip", or other tests are focused and it isn't one of them
+func (o TestRunOptions) SkipReason(request *HTTPRequest) (string, bool) {
+	if request.Skip {
+		if request.SkipReason == "" {
+			return SkipReasonDefault, true
+		}
+		return request.SkipReason, true
+	}
+
+	if len(o.Focus) > 0 || o.FocusOnly {
+		if request.Only && o.FocusOnly {
+			return "", false
+		}
+		for _, pattern := range o.Focus {
+			if matched, _ := path.Match(pattern, request.Name); matched {
+				return "", false
+			}
+		}
+		return SkipReasonNotFocused, true
+	}
+	return "", false
+}
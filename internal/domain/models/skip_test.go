@@ -0,0 +1,35 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSkipReason(t *testing.T) {
+	tests := []struct {
+		name    string
+		options TestRunOptions
+		request HTTPRequest
+		reason  string
+		skip    bool
+	}{
+		{name: "runs", request: HTTPRequest{Name: "listUsers"}},
+		{name: "skip with reason", request: HTTPRequest{Name: "listUsers", Skip: true, SkipReason: "JIRA-7 endpoint disabled"}, reason: "JIRA-7 endpoint disabled", skip: true},
+		{name: "skip without reason", request: HTTPRequest{Name: "listUsers", Skip: true}, reason: SkipReasonDefault, skip: true},
+		{name: "skip wins over only", options: TestRunOptions{FocusOnly: true}, request: HTTPRequest{Name: "listUsers", Skip: true, Only: true}, reason: SkipReasonDefault, skip: true},
+		{name: "only", options: TestRunOptions{FocusOnly: true}, request: HTTPRequest{Name: "listUsers", Only: true}},
+		{name: "not only", options: TestRunOptions{FocusOnly: true}, request: HTTPRequest{Name: "getUser"}, reason: SkipReasonNotFocused, skip: true},
+		{name: "focused by name", options: TestRunOptions{Focus: []string{"list*"}}, request: HTTPRequest{Name: "listUsers"}},
+		{name: "not focused by name", options: TestRunOptions{Focus: []string{"list*"}}, request: HTTPRequest{Name: "getUser"}, reason: SkipReasonNotFocused, skip: true},
+		{name: "focused by name or only", options: TestRunOptions{Focus: []string{"getUser"}, FocusOnly: true}, request: HTTPRequest{Name: "getUser"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, skip := tt.options.SkipReason(&tt.request)
+			assert.Equal(t, tt.skip, skip)
+			assert.Equal(t, tt.reason, reason)
+		})
+	}
+}
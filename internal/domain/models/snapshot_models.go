@@ -91,6 +91,8 @@ type SnapshotMetadata struct {
 	StatusCode     int                 `json:"statusCode"`
 	Headers        map[string][]string `json:"headers"`
 	CreatedAt      time.Time           `json:"createdAt"`
+	ToolVersion    string              `json:"toolVersion,omitempty"`
+	SpecHash       string              `json:"specHash,omitempty"`
 }
 
 // SnapshotOptions defines options for saving and comparing snapshots
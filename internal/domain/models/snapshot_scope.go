@@ -0,0 +1,44 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SnapshotScope selects the parts of a response compared with its snapshot
+type SnapshotScope string
+
+// Snapshot comparison scopes
+const (
+	SnapshotScopeAll         SnapshotScope = ""
+	SnapshotScopeBodyOnly    SnapshotScope = "body-only"
+	SnapshotScopeHeadersOnly SnapshotScope = "headers-only"
+	SnapshotScopeStatusOnly  SnapshotScope = "status-only"
+)
+
+// ParseSnapshotScope parses the argument of a "@snapshot-compare" directive:
+// body-only, headers-only, status-only, or all
+func ParseSnapshotScope(value string) (SnapshotScope, error) {
+	switch scope := SnapshotScope(strings.ToLower(strings.TrimSpace(value))); scope {
+	case "all":
+		return SnapshotScopeAll, nil
+	case SnapshotScopeBodyOnly, SnapshotScopeHeadersOnly, SnapshotScopeStatusOnly:
+		return scope, nil
+	}
+	return SnapshotScopeAll, fmt.Errorf("invalid snapshot comparison scope %q: use body-only, headers-only, status-only or all", value)
+}
+
+// ComparesStatus reports whether the status code is compared
+func (s SnapshotScope) ComparesStatus() bool {
+	return s == SnapshotScopeAll || s == SnapshotScopeStatusOnly
+}
+
+// ComparesHeaders reports whether the headers are compared
+func (s SnapshotScope) ComparesHeaders() bool {
+	return s == SnapshotScopeAll || s == SnapshotScopeHeadersOnly
+}
+
+// ComparesBody reports whether the body is compared
+func (s SnapshotScope) ComparesBody() bool {
+	return s == SnapshotScopeAll || s == SnapshotScopeBodyOnly
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSnapshotScope(t *testing.T) {
+	scope, err := ParseSnapshotScope("Body-Only")
+	require.NoError(t, err)
+	assert.Equal(t, SnapshotScopeBodyOnly, scope)
+	assert.True(t, scope.ComparesBody())
+	assert.False(t, scope.ComparesHeaders())
+	assert.False(t, scope.ComparesStatus())
+
+	scope, err = ParseSnapshotScope("all")
+	require.NoError(t, err)
+	assert.True(t, scope.ComparesStatus() && scope.ComparesHeaders() && scope.ComparesBody())
+
+	_, err = ParseSnapshotScope("body")
+	assert.Error(t, err)
+}
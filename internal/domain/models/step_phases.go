@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// Phases of a sequence step, in the order they run
+const (
+	StepPhaseWaitBefore = "wait-before"
+	StepPhaseRequest    = "request"
+	StepPhaseValidation = "validation"
+	StepPhaseAssertions = "assertions"
+	StepPhaseExtraction = "extraction"
+	StepPhaseWaitAfter  = "wait-after"
+)
+
+// StepPhase is the time a sequence step spent in one of its phases
+type StepPhase struct {
+	Name     string        `json:"name"`
+	Offset   time.Duration `json:"offset"` // Since the start of the sequence
+	Duration time.Duration `json:"duration"`
+}
+
+// AddPhase records a phase of the step that started at start and ends now,
+// placed on the timeline of the sequence that started at sequenceStart
+func (r *TestSequenceStepResult) AddPhase(name string, sequenceStart, start time.Time) {
+	r.Phases = append(r.Phases, StepPhase{
+		Name:     name,
+		Offset:   start.Sub(sequenceStart),
+		Duration: time.Since(start),
+	})
+}
+
+// PhaseDuration returns the time the step spent in a phase, 0 if it didn't
+// go through it
+func (r TestSequenceStepResult) PhaseDuration(name string) time.Duration {
+	var total time.Duration
+	for _, phase := range r.Phases {
+		if phase.Name == name {
+			total += phase.Duration
+		}
+	}
+	return total
+}
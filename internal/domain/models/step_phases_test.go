@@ -0,0 +1,20 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStepPhases(t *testing.T) {
+	sequenceStart := time.Now().Add(-time.Second)
+	var step TestSequenceStepResult
+	step.AddPhase(StepPhaseRequest, sequenceStart, time.Now().Add(-100*time.Millisecond))
+
+	require.Len(t, step.Phases, 1)
+	assert.InDelta(t, float64(900*time.Millisecond), float64(step.Phases[0].Offset), float64(50*time.Millisecond))
+	assert.GreaterOrEqual(t, step.PhaseDuration(StepPhaseRequest), 100*time.Millisecond)
+	assert.Zero(t, step.PhaseDuration(StepPhaseExtraction))
+}
@@ -0,0 +1,145 @@
+package models
+
+import (
+	"sort"
+	"strings"
+)
+
+// DefaultSlowestRequests is the number of slowest requests kept in a summary
+const DefaultSlowestRequests = 5
+
+// UntaggedGroup is the tag under which results without a tag are counted
+const UntaggedGroup = "untagged"
+
+// StatusCounts counts the results of a group of tests by status
+type StatusCounts struct {
+	Total   int `json:"total"`
+	Passed  int `json:"passed"`
+	Failed  int `json:"failed"`
+	Skipped int `json:"skipped"`
+	Errors  int `json:"errors"`
+}
+
+// Add counts a result with the given status
+func (c *StatusCounts) Add(status TestStatus) {
+	c.Total++
+	switch status {
+	case TestStatusPassed:
+		c.Passed++
+	case TestStatusFailed:
+		c.Failed++
+	case TestStatusSkipped:
+		c.Skipped++
+	case TestStatusError, TestStatusCircuitOpen:
+		c.Errors++
+	}
+}
+
+// SlowRequest describes one of the slowest requests of a run
+type SlowRequest struct {
+	Name       string     `json:"name"`
+	Method     string     `json:"method,omitempty"`
+	URL        string     `json:"url,omitempty"`
+	FilePath   string     `json:"filePath,omitempty"`
+	DurationMs int64      `json:"durationMs"`
+	Status     TestStatus `json:"status"`
+}
+
+// AddBreakdowns fills in the per-tag, per-method and per-status-code breakdowns
+// of the summary, keeps the slowest requests of the results and lists their
+// budget violations
+func (s *TestSummary) AddBreakdowns(results []TestResult, slowest int) {
+	s.ByTag = make(map[string]StatusCounts)
+	s.ByMethod = make(map[string]StatusCounts)
+	s.StatusCodes = make(map[int]int)
+	s.Slowest = nil
+	s.BudgetViolations = nil
+
+	for _, result := range results {
+		tags := result.Tags
+		if len(tags) == 0 || (len(tags) == 1 && tags[0] == "") {
+			tags = []string{UntaggedGroup}
+		}
+		for _, tag := range tags {
+			if tag == "" {
+				continue
+			}
+			counts := s.ByTag[tag]
+			counts.Add(result.Status)
+			s.ByTag[tag] = counts
+		}
+
+		if result.Request != nil && result.Request.Method != "" {
+			method := strings.ToUpper(result.Request.Method)
+			counts := s.ByMethod[method]
+			counts.Add(result.Status)
+			s.ByMethod[method] = counts
+		}
+
+		if result.Response != nil && result.Response.StatusCode > 0 {
+			s.StatusCodes[result.Response.StatusCode]++
+		}
+
+		for _, assertion := range result.AssertionResults {
+			if assertion.Type == BudgetAssertionType && !assertion.Passed {
+				s.BudgetViolations = append(s.BudgetViolations, BudgetViolation{
+					Test:     result.Name,
+					FilePath: result.FilePath,
+					Error:    assertion.Error,
+				})
+			}
+		}
+	}
+
+	// Keep the slowest requests, breaking ties by name for a stable order
+	var timed []TestResult
+	for _, result := range results {
+		if result.Duration > 0 {
+			timed = append(timed, result)
+		}
+	}
+	sort.SliceStable(timed, func(i, j int) bool {
+		if timed[i].Duration != timed[j].Duration {
+			return timed[i].Duration > timed[j].Duration
+		}
+		return timed[i].Name < timed[j].Name
+	})
+	if len(timed) > slowest {
+		timed = timed[:slowest]
+	}
+	for _, result := range timed {
+		slow := SlowRequest{
+			Name:       result.Name,
+			FilePath:   result.FilePath,
+			DurationMs: result.Duration.Milliseconds(),
+			Status:     result.Status,
+		}
+		if result.Request != nil {
+			slow.Method = result.Request.Method
+			slow.URL = result.Request.URL
+		}
+		s.Slowest = append(s.Slowest, slow)
+	}
+}
+
+// SortedStatusCodes returns the status codes of the histogram in ascending order
+func (s *TestSummary) SortedStatusCodes() []int {
+	codes := make([]int, 0, len(s.StatusCodes))
+	for code := range s.StatusCodes {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	return codes
+}
+
+// CountRunTimeouts returns the number of results that were skipped or cancelled
+// because the run timeout was reached
+func CountRunTimeouts(results []TestResult) int {
+	count := 0
+	for _, result := range results {
+		if strings.HasPrefix(result.Error, RunTimeoutReason) {
+			count++
+		}
+	}
+	return count
+}
@@ -1,5 +1,7 @@
 package models
 
+import "strings"
+
 // SwaggerDoc represents a Swagger/OpenAPI document
 type SwaggerDoc struct {
 	Version     string                 `json:"openapi,omitempty" yaml:"openapi,omitempty"`
@@ -16,6 +18,14 @@ type SwaggerDoc struct {
 	Parameters  map[string]Parameter   `json:"parameters,omitempty" yaml:"parameters,omitempty"`
 	Tags        []Tag                  `json:"tags,omitempty" yaml:"tags,omitempty"`
 	Servers     []Server               `json:"servers,omitempty" yaml:"servers,omitempty"`
+	Security    []map[string][]string  `json:"security,omitempty" yaml:"security,omitempty"`
+	SecurityDefinitions map[string]SecurityScheme `json:"securityDefinitions,omitempty" yaml:"securityDefinitions,omitempty"`
+
+	// SpecHash is the content hash of the raw document, set by the parser
+	SpecHash string `json:"-" yaml:"-"`
+
+	// Extensions holds the document's "x-" vendor extensions
+	Extensions Extensions `json:"-" yaml:"-"`
 }
 
 // Info represents the metadata of a Swagger/OpenAPI document
@@ -55,6 +65,7 @@ type PathItem struct {
 	Patch      *Operation `json:"patch,omitempty" yaml:"patch,omitempty"`
 	Trace      *Operation `json:"trace,omitempty" yaml:"trace,omitempty"`
 	Parameters []Parameter `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	Extensions Extensions  `json:"-" yaml:"-"`
 }
 
 // Operation represents an operation in a Swagger/OpenAPI path
@@ -70,6 +81,8 @@ type Operation struct {
 	Responses   map[string]Response    `json:"responses" yaml:"responses"`
 	Security    []map[string][]string  `json:"security,omitempty" yaml:"security,omitempty"`
 	Deprecated  bool                   `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+	Callbacks   map[string]Callback    `json:"callbacks,omitempty" yaml:"callbacks,omitempty"`
+	Extensions  Extensions             `json:"-" yaml:"-"`
 }
 
 // Parameter represents a parameter in a Swagger/OpenAPI operation
@@ -98,6 +111,7 @@ type Parameter struct {
 	Enum            []interface{} `json:"enum,omitempty" yaml:"enum,omitempty"`
 	MultipleOf      *float64    `json:"multipleOf,omitempty" yaml:"multipleOf,omitempty"`
 	Content         map[string]MediaType `json:"content,omitempty" yaml:"content,omitempty"`
+	Extensions      Extensions  `json:"-" yaml:"-"`
 }
 
 // RequestBody represents a request body in OpenAPI 3.0
@@ -145,8 +159,21 @@ type Schema struct {
 	OneOf                []*Schema              `json:"oneOf,omitempty" yaml:"oneOf,omitempty"`
 	AnyOf                []*Schema              `json:"anyOf,omitempty" yaml:"anyOf,omitempty"`
 	Not                  *Schema                `json:"not,omitempty" yaml:"not,omitempty"`
+	Discriminator        *Discriminator         `json:"discriminator,omitempty" yaml:"discriminator,omitempty"`
+	ReadOnly             bool                   `json:"readOnly,omitempty" yaml:"readOnly,omitempty"`
+	WriteOnly            bool                   `json:"writeOnly,omitempty" yaml:"writeOnly,omitempty"`
+	Nullable             bool                   `json:"nullable,omitempty" yaml:"nullable,omitempty"`
+	Deprecated           bool                   `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
 	AdditionalItems      *Schema                `json:"additionalItems,omitempty" yaml:"additionalItems,omitempty"`
 	Example              interface{}            `json:"example,omitempty" yaml:"example,omitempty"`
+	Extensions           Extensions             `json:"-" yaml:"-"`
+}
+
+// Discriminator selects the schema of a polymorphic value, among those of
+// oneOf or anyOf, by the value of one of its properties
+type Discriminator struct {
+	PropertyName string            `json:"propertyName" yaml:"propertyName"`
+	Mapping      map[string]string `json:"mapping,omitempty" yaml:"mapping,omitempty"`
 }
 
 // Items represents items in a Schema
@@ -271,3 +298,26 @@ type ServerVariable struct {
 	Default     string   `json:"default" yaml:"default"`
 	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
 }
+
+// Operation returns the operation of the path item for an HTTP method, or nil
+func (p *PathItem) Operation(method string) *Operation {
+	switch strings.ToUpper(method) {
+	case "GET":
+		return p.Get
+	case "PUT":
+		return p.Put
+	case "POST":
+		return p.Post
+	case "DELETE":
+		return p.Delete
+	case "OPTIONS":
+		return p.Options
+	case "HEAD":
+		return p.Head
+	case "PATCH":
+		return p.Patch
+	case "TRACE":
+		return p.Trace
+	}
+	return nil
+}
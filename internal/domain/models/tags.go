@@ -0,0 +1,230 @@
+package models
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"unicode"
+)
+
+// SplitTags splits the value of a "@tag" directive, a comma-separated list
+// of tags such as "users,admin,smoke", dropping empty and repeated tags
+func SplitTags(value string) []string {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, tag := range strings.Split(value, ",") {
+		tag = strings.Trim(strings.TrimSpace(tag), "/")
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// TagList returns the tags of the request. Tags may be hierarchical, e.g.
+// "payments/refunds".
+func (r *HTTPRequest) TagList() []string {
+	return SplitTags(r.Tag)
+}
+
+// PrimaryTag returns the first tag of the request, which groups its
+// snapshots, or "" when it has none
+func (r *HTTPRequest) PrimaryTag() string {
+	if tags := r.TagList(); len(tags) > 0 {
+		return tags[0]
+	}
+	return ""
+}
+
+// TagMatches reports whether a tag matches a pattern of a tag expression:
+// the same tag, one nested under it ("payments" matches "payments/refunds")
+// or one matching it as a glob ("payments/*"), regardless of case
+func TagMatches(pattern, tag string) bool {
+	pattern, tag = strings.ToLower(pattern), strings.ToLower(tag)
+	if pattern == tag || strings.HasPrefix(tag, pattern+"/") {
+		return true
+	}
+	matched, _ := path.Match(pattern, tag)
+	return matched
+}
+
+// TagExpression is a boolean expression over the tags of a request, such as
+// "smoke and not slow"
+type TagExpression interface {
+	Match(tags []string) bool
+	String() string
+}
+
+type tagTerm string
+
+func (t tagTerm) Match(tags []string) bool {
+	for _, tag := range tags {
+		if TagMatches(string(t), tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t tagTerm) String() string { return string(t) }
+
+type tagNot struct{ operand TagExpression }
+
+func (n tagNot) Match(tags []string) bool { return !n.operand.Match(tags) }
+func (n tagNot) String() string           { return "not " + n.operand.String() }
+
+type tagBinary struct {
+	and         bool
+	left, right TagExpression
+}
+
+func (b tagBinary) Match(tags []string) bool {
+	if b.and {
+		return b.left.Match(tags) && b.right.Match(tags)
+	}
+	return b.left.Match(tags) || b.right.Match(tags)
+}
+
+func (b tagBinary) String() string {
+	operator := "or"
+	if b.and {
+		operator = "and"
+	}
+	return fmt.Sprintf("(%s %s %s)", b.left, operator, b.right)
+}
+
+// ParseTagExpression parses a tag expression: tags combined with and, or,
+// not and parentheses, e.g. "smoke and not (slow or payments/refunds)".
+// "and" binds tighter than "or".
+func ParseTagExpression(value string) (TagExpression, error) {
+	p := &tagParser{tokens: tokenizeTags(value)}
+	if len(p.tokens) == 0 {
+		return nil, fmt.Errorf("empty tag expression")
+	}
+	expression, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("invalid tag expression %q: %w", value, err)
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("invalid tag expression %q: unexpected %q", value, p.tokens[p.pos])
+	}
+	return expression, nil
+}
+
+// MatchTagFilter reports whether tags match any of the tag expressions of
+// a filter, or any tags at all when the filter has none. Invalid
+// expressions match nothing.
+func MatchTagFilter(expressions []string, tags []string) bool {
+	if len(expressions) == 0 {
+		return true
+	}
+	for _, value := range expressions {
+		expression, err := ParseTagExpression(value)
+		if err == nil && expression.Match(tags) {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenizeTags splits a tag expression into parentheses and words
+func tokenizeTags(value string) []string {
+	var tokens []string
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			tokens = append(tokens, word.String())
+			word.Reset()
+		}
+	}
+	for _, r := range value {
+		switch {
+		case r == '(' || r == ')':
+			flush()
+			tokens = append(tokens, string(r))
+		case unicode.IsSpace(r):
+			flush()
+		default:
+			word.WriteRune(r)
+		}
+	}
+	flush()
+	return tokens
+}
+
+// tagParser is a recursive descent parser of tag expressions
+type tagParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *tagParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *tagParser) parseOr() (TagExpression, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for strings.EqualFold(p.peek(), "or") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = tagBinary{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *tagParser) parseAnd() (TagExpression, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for strings.EqualFold(p.peek(), "and") {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = tagBinary{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *tagParser) parseUnary() (TagExpression, error) {
+	token := p.peek()
+	switch {
+	case token == "":
+		return nil, fmt.Errorf("unexpected end of expression")
+	case strings.EqualFold(token, "not"):
+		p.pos++
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return tagNot{operand: operand}, nil
+	case token == "(":
+		p.pos++
+		expression, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		p.pos++
+		return expression, nil
+	case token == ")" || strings.EqualFold(token, "and") || strings.EqualFold(token, "or"):
+		return nil, fmt.Errorf("unexpected %q", token)
+	}
+	p.pos++
+	return tagTerm(token), nil
+}
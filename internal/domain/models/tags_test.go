@@ -0,0 +1,58 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitTags(t *testing.T) {
+	assert.Equal(t, []string{"users", "admin", "payments/refunds"}, SplitTags(" users, admin,,payments/refunds/,users"))
+	assert.Nil(t, SplitTags(""))
+
+	request := HTTPRequest{Tag: "users,smoke"}
+	assert.Equal(t, []string{"users", "smoke"}, request.TagList())
+	assert.Equal(t, "users", request.PrimaryTag())
+}
+
+func TestTagExpression(t *testing.T) {
+	tests := []struct {
+		expression string
+		tags       []string
+		want       bool
+	}{
+		{expression: "smoke", tags: []string{"users", "smoke"}, want: true},
+		{expression: "Smoke", tags: []string{"smoke"}, want: true},
+		{expression: "smoke", tags: []string{"smoke-tests"}},
+		{expression: "smoke and not slow", tags: []string{"smoke"}, want: true},
+		{expression: "smoke and not slow", tags: []string{"smoke", "slow"}},
+		{expression: "smoke or admin and slow", tags: []string{"smoke"}, want: true},
+		{expression: "(smoke or admin) and slow", tags: []string{"smoke"}},
+		{expression: "not (users or orders)", tags: []string{"payments"}, want: true},
+		{expression: "payments", tags: []string{"payments/refunds"}, want: true},
+		{expression: "payments/refunds", tags: []string{"payments"}},
+		{expression: "payments/*", tags: []string{"payments/refunds"}, want: true},
+		{expression: "not smoke", tags: nil, want: true},
+	}
+
+	for _, tt := range tests {
+		expression, err := ParseTagExpression(tt.expression)
+		require.NoError(t, err, tt.expression)
+		assert.Equal(t, tt.want, expression.Match(tt.tags), "%s on %v", tt.expression, tt.tags)
+	}
+}
+
+func TestParseTagExpressionErrors(t *testing.T) {
+	for _, expression := range []string{"", "smoke and", "(smoke", "smoke slow", "and smoke", "smoke)"} {
+		_, err := ParseTagExpression(expression)
+		assert.Error(t, err, expression)
+	}
+}
+
+func TestMatchTagFilter(t *testing.T) {
+	assert.True(t, MatchTagFilter(nil, nil))
+	assert.True(t, MatchTagFilter([]string{"users", "smoke and not slow"}, []string{"smoke"}))
+	assert.False(t, MatchTagFilter([]string{"users", "smoke and not slow"}, []string{"smoke", "slow"}))
+	assert.False(t, MatchTagFilter([]string{"smoke and"}, []string{"smoke"}))
+}
@@ -31,6 +31,10 @@ type TestStep struct {
 	SchemaValidate  bool                 `json:"schemaValidate,omitempty"`
 	Assertions      []TestAssertion      `json:"assertions,omitempty"`
 	ExpectedResult  *TestSequenceStepResult `json:"expectedResult,omitempty"`
+
+	// Request deleting the resource the step creates, run when the sequence
+	// ends, e.g. "DELETE {{location}}"
+	RegisterCleanup string `json:"registerCleanup,omitempty"`
 }
 
 // TestSequenceResult represents the result of running a test sequence
@@ -43,8 +47,18 @@ type TestSequenceResult struct {
 	StartTime      time.Time               `json:"startTime"`
 	EndTime        time.Time               `json:"endTime"`
 	Error          string                  `json:"error,omitempty"`
+
+	// Outcomes of the cleanup requests registered by the steps, in the order they ran
+	CleanupResults []CleanupResult `json:"cleanupResults,omitempty"`
 }
 
+// Metadata keys of the test results of sequence steps, naming the sequence and
+// the step
+const (
+	SequenceMetaKey = "sequence"
+	StepMetaKey     = "step"
+)
+
 // TestSequenceStepResult represents the result of a single step in a test sequence
 type TestSequenceStepResult struct {
 	Name            string              `json:"name"`
@@ -56,6 +70,16 @@ type TestSequenceStepResult struct {
 	ValidationError string              `json:"validationError,omitempty"`
 	SchemaResult    *SchemaValidationResult `json:"schemaResult,omitempty"`
 	AssertionResults []TestAssertionResult  `json:"assertionResults,omitempty"`
+
+	// Request as sent, with the sequence variables replaced
+	Request *HTTPRequest `json:"request,omitempty"`
+
+	// Pauses before and after the step, shown on the sequence timeline
+	WaitBefore time.Duration `json:"waitBefore,omitempty"`
+	WaitAfter  time.Duration `json:"waitAfter,omitempty"`
+
+	// Time spent in each phase of the step, for the waterfall of the sequence
+	Phases []StepPhase `json:"phases,omitempty"`
 }
 
 // VariableExtraction defines how to extract a variable from an HTTP response
@@ -77,6 +101,7 @@ type TestAssertion struct {
 	Values      []string    `json:"values,omitempty"`
 	Not         bool        `json:"not,omitempty"`
 	IgnoreCase  bool        `json:"ignoreCase,omitempty"`
+	Tolerance   *Tolerance  `json:"tolerance,omitempty"` // Numeric tolerance for "approx" and "equals"
 }
 
 // TestAssertionResult represents the result of a test assertion
@@ -107,6 +132,30 @@ type TestReport struct {
 	Environment map[string]string `json:"environment"`
 	CreatedAt   time.Time       `json:"createdAt"`
 	Sequences   []TestSequenceResult `json:"sequences,omitempty"`
+	Warnings    []string        `json:"warnings,omitempty"`
+	DataRows    []DataRowSummary `json:"dataRows,omitempty"`
+	Languages   []string         `json:"languages,omitempty"`
+	LanguageMatrix []LanguageMatrixRow `json:"languageMatrix,omitempty"`
+	Variables   []VariableProvenance `json:"variables,omitempty"` // Final values of the variables and where they came from
+}
+
+// LanguageMatrixRow contains the status of a test for each Accept-Language value
+type LanguageMatrixRow struct {
+	Test     string                `json:"test"`
+	File     string                `json:"file,omitempty"`
+	Statuses map[string]TestStatus `json:"statuses"`
+}
+
+// DataRow is a row of a data file whose columns are injected as variables
+type DataRow struct {
+	ID     string            `json:"id"`
+	Values map[string]string `json:"values"`
+}
+
+// DataRowSummary contains the summary statistics of the tests run for a data row
+type DataRowSummary struct {
+	ID      string      `json:"id"`
+	Summary TestSummary `json:"summary"`
 }
 
 // TestSummary contains the summary statistics for a test run
@@ -127,6 +176,20 @@ type TestSummary struct {
 	SequencesTotal   int      `json:"sequencesTotal,omitempty"`
 	SequencesPassed  int      `json:"sequencesPassed,omitempty"`
 	SequencesFailed  int      `json:"sequencesFailed,omitempty"`
+	CleanupsTotal    int      `json:"cleanupsTotal,omitempty"`
+	CleanupsFailed   int      `json:"cleanupsFailed,omitempty"`
+	RunTimedOut      bool     `json:"runTimedOut,omitempty"` // The run timeout was reached before all tests ran
+	CircuitOpenTests int      `json:"circuitOpenTests,omitempty"` // Errors that fast-failed on an open circuit breaker
+	QuarantinedTests int      `json:"quarantinedTests,omitempty"` // Quarantined tests, whatever their status
+	QuarantinedFailures int   `json:"quarantinedFailures,omitempty"` // Quarantined tests that failed, not counted as failures or errors
+
+	// Breakdowns of the results, see AddBreakdowns
+	ByTag       map[string]StatusCounts `json:"byTag,omitempty"`
+	ByMethod    map[string]StatusCounts `json:"byMethod,omitempty"`
+	StatusCodes map[int]int             `json:"statusCodes,omitempty"`
+	Slowest     []SlowRequest           `json:"slowest,omitempty"`
+
+	BudgetViolations []BudgetViolation `json:"budgetViolations,omitempty"`
 }
 
 // TestResult represents the result of a single test (HTTP request)
@@ -135,6 +198,7 @@ type TestResult struct {
 	FilePath        string             `json:"filePath"`
 	Request         *HTTPRequest       `json:"request"`
 	Response        *HTTPResponse      `json:"response"`
+	RawResponse     *HTTPResponse      `json:"rawResponse,omitempty"` // Response before the snapshot transform
 	SnapshotResult  *SnapshotResult    `json:"snapshotResult,omitempty"`
 	SchemaResult    *SchemaValidationResult `json:"schemaResult,omitempty"`
 	Duration        time.Duration      `json:"duration"`
@@ -143,7 +207,19 @@ type TestResult struct {
 	Tags            []string           `json:"tags"`
 	MetaData        map[string]string  `json:"metaData,omitempty"`
 	ExtractedVars   map[string]string  `json:"extractedVars,omitempty"`
+	ChainedVars     map[string]string  `json:"chainedVars,omitempty"`
+	DataRow         string             `json:"dataRow,omitempty"`
+	Language        string             `json:"language,omitempty"`
+	SnapshotMissing bool               `json:"snapshotMissing,omitempty"`
 	AssertionResults []TestAssertionResult `json:"assertionResults,omitempty"`
+	Callbacks       []ReceivedCallback `json:"callbacks,omitempty"` // Callbacks received after the request
+	Pagination      *PaginationResult  `json:"pagination,omitempty"` // Pages fetched for a paginated request
+	TimeoutPhase    string             `json:"timeoutPhase,omitempty"` // Timeout that expired, see TimeoutError
+	Retries         int                `json:"retries,omitempty"`      // Times the request was retried after transient error statuses
+	Quarantined     bool               `json:"quarantined,omitempty"`  // Known failure that doesn't fail the build, see ApplyQuarantine
+	QuarantineReason string            `json:"quarantineReason,omitempty"`
+	Resumed         bool               `json:"resumed,omitempty"`      // Completed by a previous run and taken from its checkpoint, see Checkpoint
+	Outputs         []string           `json:"outputs,omitempty"`      // Files the response body was saved to, see ResponseOutput
 }
 
 // TestStatus represents the status of a test
@@ -154,6 +230,10 @@ const (
 	TestStatusFailed  TestStatus = "failed"
 	TestStatusSkipped TestStatus = "skipped"
 	TestStatusError   TestStatus = "error"
+
+	// TestStatusCircuitOpen is a request that wasn't sent because its host's
+	// circuit breaker was open, counted as an error
+	TestStatusCircuitOpen TestStatus = "circuit-open"
 )
 
 // TestFilter defines criteria for filtering tests
@@ -163,6 +243,7 @@ type TestFilter struct {
 	Methods     []string          // Filter by HTTP methods
 	StatusCodes []int             // Filter by response status codes
 	Names       []string          // Filter by test names
+	Scopes      []string          // Filter by the OAuth scopes a role is granted
 	Metadata    map[string]string // Filter by metadata
 }
 
@@ -176,13 +257,44 @@ type TestReportOptions struct {
 	Detailed          bool    // Include detailed information
 	IncludeExtracted  bool    // Include extracted variables in report
 	IncludeAssertions bool    // Include assertion results in report
+	JUnitGroupBy      string  // Group JUnit test suites by file, tag or none
+	FailuresOnly      bool    // Only list failed and errored tests in console output
+	InlineMaxBytes    int     // Bodies larger than this are saved as attachments of JSON and HTML reports; 0 inlines all
+}
+
+// RunTimeoutReason is the error of tests skipped because the run timeout was reached
+const RunTimeoutReason = "run timeout"
+
+// RunDeadlineExceeded reports whether the run's deadline, if any, has passed
+func (o TestRunOptions) RunDeadlineExceeded() bool {
+	return !o.RunDeadline.IsZero() && !time.Now().Before(o.RunDeadline)
+}
+
+// WithRunDeadline returns the options with RunDeadline set from RunTimeout,
+// counting from start, unless a deadline is already set
+func (o TestRunOptions) WithRunDeadline(start time.Time) TestRunOptions {
+	if o.RunTimeout > 0 && o.RunDeadline.IsZero() {
+		o.RunDeadline = start.Add(o.RunTimeout)
+	}
+	return o
+}
+
+// ProgressListener is notified about the progress of a test run
+type ProgressListener interface {
+	// Start is called once with the number of tests the run is expected to execute
+	Start(total int)
+	// Completed is called after each test, possibly from several goroutines
+	Completed(result TestResult)
 }
 
 // TestRunOptions defines options for running tests
 type TestRunOptions struct {
 	UpdateSnapshots      string          // Update mode for snapshots (none, all, failed, missing)
 	FailOnMissing        bool            // Fail when snapshot is missing
-	IgnoreHeaders        []string        // Headers to ignore in comparison
+	IgnoreHeaders        []string        // Header patterns to ignore in comparison, on top of the configured ones
+	HeaderRules          *HeaderIgnoreRules // Configured header patterns to ignore, the defaults when nil
+	IgnoreArrayOrder     bool            // Compare JSON arrays regardless of order
+	ArrayOrderKey        string          // Field to sort arrays of objects by when ignoring order
 	Timeout              time.Duration   // HTTP request timeout
 	Parallel             bool            // Run tests in parallel
 	MaxConcurrent        int             // Maximum number of concurrent tests
@@ -190,13 +302,46 @@ type TestRunOptions struct {
 	Filter               TestFilter      // Test filter criteria
 	ReportOptions        TestReportOptions // Report generation options
 	EnvironmentVars      map[string]string // Environment variables for tests
+	Variables            map[string]string // Variables given on the command line, taking precedence over the other sources
 	ContinuousMode       bool            // Run in continuous (watch) mode
 	WatchPaths           []string        // Paths to watch for changes
 	WatchIntervalMs      int             // Interval between watch checks in milliseconds
+	WatchHistory         int             // Runs kept per request for the watch mode trend, 0 for the default
+	StrictVersion        bool            // Fail on artifacts generated by an incompatible major version
+	VarsPassphrase       string          // Passphrase for encrypted variable files
+	VarsKeyFile          string          // Key file for encrypted variable files
+	DataRows             []DataRow       // Data rows to run each test with
+	DataRow              *DataRow        // Data row of the current run
+	Languages            []string        // Accept-Language values to run each test with
+	Language             string          // Accept-Language value of the current run
+	Progress             ProgressListener // Notified as tests complete, e.g. to draw a progress bar
+	RunTimeout           time.Duration   // Overall time budget for the run, 0 for none
+	RunDeadline          time.Time       // Deadline of the run derived from RunTimeout
+	BaseURL              string          // Base URL requests are pointed at, e.g. the selected server of the spec
+	Host                 string          // Base URL relative request URLs are resolved against, overriding the files' "@host"
+	QueryParams          map[string]string // Values overriding the query parameters of the requests that have them
+	PromptVariable       func(name string, secret bool) (string, error) // Asks for the value of a missing variable, without echo when secret; missing variables are sent as written when nil
+	NoInput              bool            // Fail before running when variables are missing rather than asking for them
+	StrictVars           bool            // Fail the requests still using variables without a value rather than sending them as written
+	CallbackAddr         string          // Address the callback listener binds to, e.g. 127.0.0.1:0
+	CallbackURL          string          // Public base URL of the callback listener, when reached through a proxy or tunnel
+	Guard                MutationGuard   // Which requests that change data may run
+	Budgets              []Budget        // Limits on the responses of tagged tests, checked as assertions
+	Redirects            *RedirectPolicy // Redirect policy of requests without their own, the executor's default when nil
+	Retries              *RetryOptions   // Retries of requests without their own, the executor's default when nil
+	Seed                 int64           // Seed of the run's randomness, 0 to pick one; recorded in the report to replay the run
+	Order                TestOrder       // Order tests run in, file order when empty
+	Quarantine           *Quarantine     // Known-failing tests that don't fail the build
+	Focus                []string        // Names or patterns of the tests to run, the others are skipped
+	FocusOnly            bool            // Run only the requests marked "@only", set when any is
+	Checkpoint           *Checkpoint     // Records the completed tests, and holds those of the run being resumed
+	Memory               *MemoryMonitor  // Throttles requests and spills bodies to disk above a memory limit
+	MaxBodyBytes         int64           // Largest response body read, the executor's default when 0
 	
 	// Advanced testing features (issue #13)
 	ValidateSchema       bool            // Validate responses against OpenAPI schema
 	ValidationOptions    ValidationOptions // Options for schema validation
+	SwaggerDoc           *SwaggerDoc     // Document responses are validated against
 	SequentialRun        bool            // Run tests in sequence with dependencies
 	ExtractVariables     bool            // Extract variables from responses for use in subsequent tests
 	VariableFormat       string          // Format for variable substitution (default: ${varname})
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+)
+
+// DefaultNameTemplate names the requests without "@name" by their method and
+// the slug of their path, e.g. "GET _users_id"
+const DefaultNameTemplate = "{{method}} {{slug}}"
+
+// TestNameFields are the values of the placeholders of a naming template
+type TestNameFields struct {
+	Method string // {{method}}, e.g. GET
+	Path   string // {{path}}, the path of the URL, e.g. /users/{{id}}
+	Slug   string // {{slug}}, the path made into a name, e.g. _users_id
+	Tag    string // {{tag}}, the tags of the request separated by commas
+	File   string // {{file}}, the name of the file without its extension
+}
+
+// emptyGroupPattern matches the brackets left empty by placeholders without
+// a value, with the space before them
+var emptyGroupPattern = regexp.MustCompile(`\s*(\[\s*\]|\(\s*\))`)
+
+// FormatTestName names a request with a naming template such as
+// "{{method}} {{path}} [{{tag}}]". Brackets and spaces left over by empty
+// placeholders are removed.
+func FormatTestName(template string, fields TestNameFields) string {
+	if template == "" {
+		template = DefaultNameTemplate
+	}
+	name := strings.NewReplacer(
+		"{{method}}", fields.Method,
+		"{{path}}", fields.Path,
+		"{{slug}}", fields.Slug,
+		"{{tag}}", fields.Tag,
+		"{{file}}", fields.File,
+	).Replace(template)
+	name = emptyGroupPattern.ReplaceAllString(name, "")
+	return strings.Join(strings.Fields(name), " ")
+}
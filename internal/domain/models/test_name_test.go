@@ -0,0 +1,26 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatTestName(t *testing.T) {
+	fields := TestNameFields{Method: "GET", Path: "/users/{{id}}", Slug: "_users_id", Tag: "users,admin", File: "users"}
+
+	tests := []struct {
+		template string
+		fields   TestNameFields
+		want     string
+	}{
+		{template: "", fields: fields, want: "GET _users_id"},
+		{template: "{{method}} {{path}} [{{tag}}]", fields: fields, want: "GET /users/{{id}} [users,admin]"},
+		{template: "{{method}} {{path}} [{{tag}}]", fields: TestNameFields{Method: "GET", Path: "/health"}, want: "GET /health"},
+		{template: "{{file}}: {{method}} {{path}} ({{tag}})", fields: TestNameFields{Method: "POST", Path: "/orders", File: "orders"}, want: "orders: POST /orders"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, FormatTestName(tt.template, tt.fields), tt.template)
+	}
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TestOrder is the order tests run in
+type TestOrder string
+
+// Test orders
+const (
+	// TestOrderFile runs tests in the order of their files
+	TestOrderFile TestOrder = "file"
+
+	// TestOrderPriority runs tests by their "@priority", lowest first, then
+	// the tests without one in file order
+	TestOrderPriority TestOrder = "priority"
+
+	// TestOrderRandom shuffles tests with the seed of the run, to detect tests
+	// depending on others
+	TestOrderRandom TestOrder = "random"
+)
+
+// ParseTestOrder parses the argument of --order: file, priority or random
+func ParseTestOrder(value string) (TestOrder, error) {
+	switch order := TestOrder(strings.ToLower(strings.TrimSpace(value))); order {
+	case "":
+		return TestOrderFile, nil
+	case TestOrderFile, TestOrderPriority, TestOrderRandom:
+		return order, nil
+	}
+	return TestOrderFile, fmt.Errorf("invalid test order %q: use file, priority or random", value)
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTestOrder(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    TestOrder
+		wantErr bool
+	}{
+		{value: "", want: TestOrderFile},
+		{value: "file", want: TestOrderFile},
+		{value: "Priority", want: TestOrderPriority},
+		{value: " random ", want: TestOrderRandom},
+		{value: "alphabetical", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		order, err := ParseTestOrder(tt.value)
+		if tt.wantErr {
+			assert.Error(t, err, tt.value)
+			continue
+		}
+		assert.NoError(t, err, tt.value)
+		assert.Equal(t, tt.want, order, tt.value)
+	}
+}
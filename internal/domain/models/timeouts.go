@@ -0,0 +1,67 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Timeout phases of a request
+const (
+	TimeoutPhaseConnect        = "connect"
+	TimeoutPhaseTLSHandshake   = "tls-handshake"
+	TimeoutPhaseResponseHeader = "response-header"
+	TimeoutPhaseBody           = "body"
+	TimeoutPhaseRequest        = "request"
+)
+
+// Timeouts bounds the phases of a request. Zero leaves a phase unbounded,
+// except by the overall request timeout.
+type Timeouts struct {
+	Connect        time.Duration `json:"connect,omitempty"`        // Establishing the TCP connection, DNS lookup included
+	TLSHandshake   time.Duration `json:"tlsHandshake,omitempty"`   // TLS handshake of HTTPS connections
+	ResponseHeader time.Duration `json:"responseHeader,omitempty"` // Waiting for the response headers once the request is sent
+	Request        time.Duration `json:"request,omitempty"`        // Whole request, from connecting to reading the body
+}
+
+// Limit returns the timeout of a phase
+func (t Timeouts) Limit(phase string) time.Duration {
+	switch phase {
+	case TimeoutPhaseConnect:
+		return t.Connect
+	case TimeoutPhaseTLSHandshake:
+		return t.TLSHandshake
+	case TimeoutPhaseResponseHeader:
+		return t.ResponseHeader
+	case TimeoutPhaseRequest:
+		return t.Request
+	}
+	return 0
+}
+
+// TimeoutError is a request that timed out. Phase is the timeout that expired,
+// During the phase the request was in, which differ when the overall request
+// timeout expires.
+type TimeoutError struct {
+	Phase  string
+	During string
+	Limit  time.Duration
+	Err    error
+}
+
+// Error describes the timeout and the phase it expired in
+func (e *TimeoutError) Error() string {
+	message := fmt.Sprintf("%s timeout", e.Phase)
+	if e.Limit > 0 {
+		message += fmt.Sprintf(" of %s", e.Limit)
+	}
+	message += " exceeded"
+	if e.During != "" && e.During != e.Phase {
+		message += fmt.Sprintf(" during %s", e.During)
+	}
+	return fmt.Sprintf("%s: %v", message, e.Err)
+}
+
+// Unwrap returns the error of the HTTP client
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeoutError(t *testing.T) {
+	cause := errors.New("net/http: timeout awaiting response headers")
+
+	err := &TimeoutError{Phase: TimeoutPhaseResponseHeader, During: TimeoutPhaseResponseHeader, Limit: 5 * time.Second, Err: cause}
+	assert.Equal(t, "response-header timeout of 5s exceeded: net/http: timeout awaiting response headers", err.Error())
+	assert.ErrorIs(t, err, cause)
+
+	err = &TimeoutError{Phase: TimeoutPhaseRequest, During: TimeoutPhaseBody, Limit: 30 * time.Second, Err: cause}
+	assert.Equal(t, "request timeout of 30s exceeded during body: net/http: timeout awaiting response headers", err.Error())
+}
+
+func TestTimeoutsLimit(t *testing.T) {
+	timeouts := Timeouts{Connect: time.Second, TLSHandshake: 2 * time.Second, ResponseHeader: 3 * time.Second, Request: 4 * time.Second}
+	assert.Equal(t, time.Second, timeouts.Limit(TimeoutPhaseConnect))
+	assert.Equal(t, 2*time.Second, timeouts.Limit(TimeoutPhaseTLSHandshake))
+	assert.Equal(t, 3*time.Second, timeouts.Limit(TimeoutPhaseResponseHeader))
+	assert.Equal(t, 4*time.Second, timeouts.Limit(TimeoutPhaseRequest))
+	assert.Zero(t, timeouts.Limit(TimeoutPhaseBody))
+}
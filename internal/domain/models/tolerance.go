@@ -0,0 +1,89 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultTolerance is used by approx comparisons without an explicit tolerance
+var DefaultTolerance = Tolerance{Absolute: 1e-9}
+
+// Tolerance defines how far two numbers may differ and still be considered equal
+type Tolerance struct {
+	Absolute float64 `json:"absolute,omitempty" yaml:"absolute,omitempty"`
+	Relative float64 `json:"relative,omitempty" yaml:"relative,omitempty"`
+}
+
+// Within reports whether actual is within the tolerance of expected
+func (t Tolerance) Within(expected, actual float64) bool {
+	diff := math.Abs(expected - actual)
+	if diff <= t.Absolute {
+		return true
+	}
+	return t.Relative > 0 && diff <= t.Relative*math.Max(math.Abs(expected), math.Abs(actual))
+}
+
+// String returns the tolerance in the format accepted by ParseTolerance
+func (t Tolerance) String() string {
+	var parts []string
+	if t.Absolute != 0 {
+		parts = append(parts, "abs="+strconv.FormatFloat(t.Absolute, 'g', -1, 64))
+	}
+	if t.Relative != 0 {
+		parts = append(parts, "rel="+strconv.FormatFloat(t.Relative, 'g', -1, 64))
+	}
+	return strings.Join(parts, " ")
+}
+
+// ParseTolerance parses a tolerance such as "0.01", "abs=0.01", "rel=0.001" or
+// "abs=0.01 rel=0.001". A bare number is an absolute tolerance.
+func ParseTolerance(spec string) (Tolerance, error) {
+	var t Tolerance
+
+	for _, part := range strings.FieldsFunc(spec, func(r rune) bool { return r == ' ' || r == ',' }) {
+		name, value := "abs", part
+		if i := strings.Index(part, "="); i >= 0 {
+			name, value = part[:i], part[i+1:]
+		}
+
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil || number < 0 {
+			return t, fmt.Errorf("invalid tolerance %q", part)
+		}
+
+		switch name {
+		case "abs":
+			t.Absolute = number
+		case "rel":
+			t.Relative = number
+		default:
+			return t, fmt.Errorf("unknown tolerance %q, expected abs or rel", name)
+		}
+	}
+
+	if t.Absolute == 0 && t.Relative == 0 {
+		return DefaultTolerance, nil
+	}
+	return t, nil
+}
+
+// MatchFieldPath checks if a concrete JSON path such as "$.items[2].price" matches a
+// pattern where "[*]" matches any index and "*" matches any field name. Patterns
+// may omit the leading "$".
+func MatchFieldPath(pattern, path string) bool {
+	if !strings.HasPrefix(pattern, "$") {
+		pattern = "$." + strings.TrimPrefix(pattern, ".")
+	}
+	if pattern == path {
+		return true
+	}
+
+	expr := regexp.QuoteMeta(pattern)
+	expr = strings.ReplaceAll(expr, `\[\*\]`, `\[\d+\]`)
+	expr = strings.ReplaceAll(expr, `\*`, `[^.\[]+`)
+	matched, err := regexp.MatchString("^"+expr+"$", path)
+	return err == nil && matched
+}
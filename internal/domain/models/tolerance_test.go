@@ -0,0 +1,95 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTolerance(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected Tolerance
+		err      string
+	}{
+		{spec: "0.01", expected: Tolerance{Absolute: 0.01}},
+		{spec: "abs=0.5", expected: Tolerance{Absolute: 0.5}},
+		{spec: "rel=0.001", expected: Tolerance{Relative: 0.001}},
+		{spec: "abs=0.01 rel=0.001", expected: Tolerance{Absolute: 0.01, Relative: 0.001}},
+		{spec: "abs=0.01,rel=0.001", expected: Tolerance{Absolute: 0.01, Relative: 0.001}},
+		{spec: "", expected: DefaultTolerance},
+		{spec: "abs=0", expected: DefaultTolerance},
+		{spec: "abs=-1", err: `invalid tolerance "abs=-1"`},
+		{spec: "rel=small", err: `invalid tolerance "rel=small"`},
+		{spec: "pct=1", err: `unknown tolerance "pct", expected abs or rel`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			tolerance, err := ParseTolerance(tt.spec)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, tolerance)
+		})
+	}
+}
+
+func TestTolerance_Within(t *testing.T) {
+	tests := []struct {
+		name      string
+		tolerance Tolerance
+		expected  float64
+		actual    float64
+		within    bool
+	}{
+		{name: "equal", tolerance: DefaultTolerance, expected: 1.5, actual: 1.5, within: true},
+		{name: "absolute", tolerance: Tolerance{Absolute: 0.01}, expected: 10, actual: 10.01, within: true},
+		{name: "beyond absolute", tolerance: Tolerance{Absolute: 0.01}, expected: 10, actual: 10.02, within: false},
+		{name: "relative", tolerance: Tolerance{Relative: 0.01}, expected: 1000, actual: 1009, within: true},
+		{name: "relative to the larger", tolerance: Tolerance{Relative: 0.1}, expected: -100, actual: -110, within: true},
+		{name: "beyond relative", tolerance: Tolerance{Relative: 0.01}, expected: 1000, actual: 1011, within: false},
+		{name: "either", tolerance: Tolerance{Absolute: 0.5, Relative: 0.001}, expected: 1, actual: 1.4, within: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.within, tt.tolerance.Within(tt.expected, tt.actual))
+		})
+	}
+}
+
+func TestTolerance_String(t *testing.T) {
+	tolerance := Tolerance{Absolute: 0.01, Relative: 0.001}
+	assert.Equal(t, "abs=0.01 rel=0.001", tolerance.String())
+
+	parsed, err := ParseTolerance(tolerance.String())
+	require.NoError(t, err)
+	assert.Equal(t, tolerance, parsed)
+}
+
+func TestMatchFieldPath(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		matches bool
+	}{
+		{pattern: "$.total", path: "$.total", matches: true},
+		{pattern: "total", path: "$.total", matches: true},
+		{pattern: ".total", path: "$.total", matches: true},
+		{pattern: "$.items[*].price", path: "$.items[2].price", matches: true},
+		{pattern: "$.*.price", path: "$.item.price", matches: true},
+		{pattern: "$.items[*].price", path: "$.items[2].tax", matches: false},
+		{pattern: "$.total", path: "$.totals", matches: false},
+		{pattern: "$.*.price", path: "$.items[0].price", matches: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.matches, MatchFieldPath(tt.pattern, tt.path))
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// urlVariablePattern matches the {{variable}} references of a request URL
+var urlVariablePattern = regexp.MustCompile(`\{\{([^{}\s]+)\}\}`)
+
+// ExpandURL substitutes the variables of a request URL, escaping their values
+// for where they appear, see EscapeURLValue; unknown variables are left as
+// they are
+func ExpandURL(rawURL string, variables map[string]string) string {
+	var expanded strings.Builder
+	last := 0
+	for _, loc := range urlVariablePattern.FindAllStringSubmatchIndex(rawURL, -1) {
+		value, ok := variables[rawURL[loc[2]:loc[3]]]
+		if !ok {
+			continue
+		}
+		expanded.WriteString(rawURL[last:loc[0]])
+		expanded.WriteString(EscapeURLValue(rawURL, loc[0], value))
+		last = loc[1]
+	}
+	expanded.WriteString(rawURL[last:])
+	return expanded.String()
+}
+
+// EscapeURLValue escapes the value of a variable at an offset of a request URL
+// for where it appears: as it is in the scheme and host or when the URL starts
+// with variables, e.g. "{{baseUrl}}{{apiPrefix}}", as a path segment in the
+// path, and as a query value in the query string
+func EscapeURLValue(rawURL string, offset int, value string) string {
+	pathStart := 0
+	if i := strings.Index(rawURL, "://"); i >= 0 {
+		pathStart = len(rawURL)
+		if j := strings.IndexAny(rawURL[i+3:], "/?#"); j >= 0 {
+			pathStart = i + 3 + j
+		}
+	} else {
+		for {
+			loc := urlVariablePattern.FindStringIndex(rawURL[pathStart:])
+			if loc == nil || loc[0] != 0 {
+				break
+			}
+			pathStart += loc[1]
+		}
+	}
+	queryStart := len(rawURL)
+	if i := strings.IndexAny(rawURL[pathStart:], "?#"); i >= 0 {
+		queryStart = pathStart + i
+	}
+
+	switch {
+	case offset < pathStart:
+		return value
+	case offset < queryStart:
+		return url.PathEscape(value)
+	default:
+		return url.QueryEscape(value)
+	}
+}
+
+// ValidateURL checks that a request URL, its variables substituted, is an
+// absolute HTTP(S) URL that can be sent
+func ValidateURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid request URL %q: %w; check the values of the variables it uses", rawURL, err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("invalid request URL %q: it must start with http:// or https://; set \"@host\" or --host for relative URLs", rawURL)
+	}
+	if parsed.Host == "" {
+		return fmt.Errorf("invalid request URL %q: it has no host", rawURL)
+	}
+	if strings.ContainsAny(parsed.Host, " \t") {
+		return fmt.Errorf("invalid request URL %q: the host has spaces; check the values of the variables it uses", rawURL)
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+package models
+
+import "testing"
+
+func BenchmarkExpandURL(b *testing.B) {
+	variables := map[string]string{"host": "https://api.example.com", "userId": "42", "q": "a b&c"}
+	url := "{{host}}/users/{{userId}}/orders?q={{q}}&page=1&missing={{missing}}"
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ExpandURL(url, variables)
+	}
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandURL(t *testing.T) {
+	variables := map[string]string{
+		"baseUrl": "https://api.example.com/v1",
+		"prefix":  "/admin",
+		"host":    "api.example.com",
+		"name":    "Jane Doe/Jr",
+		"term":    "a&b c",
+	}
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{url: "{{baseUrl}}/users/{{name}}", want: "https://api.example.com/v1/users/Jane%20Doe%2FJr"},
+		{url: "{{baseUrl}}{{prefix}}/users", want: "https://api.example.com/v1/admin/users"},
+		{url: "https://{{host}}/search?q={{term}}", want: "https://api.example.com/search?q=a%26b+c"},
+		{url: "/users/{{name}}?q={{term}}", want: "/users/Jane%20Doe%2FJr?q=a%26b+c"},
+		{url: "{{baseUrl}}/users/{{unknown}}", want: "https://api.example.com/v1/users/{{unknown}}"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ExpandURL(tt.url, variables), tt.url)
+	}
+}
+
+func TestValidateURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr string
+	}{
+		{url: "https://api.example.com/users?q=a%20b"},
+		{url: "/users", wantErr: "must start with http:// or https://"},
+		{url: "{{baseUrl}}/users", wantErr: "must start with http:// or https://"},
+		{url: "https:///users", wantErr: "has no host"},
+		{url: "https://api.example.com:port/users", wantErr: "check the values of the variables"},
+	}
+
+	for _, tt := range tests {
+		err := ValidateURL(tt.url)
+		if tt.wantErr == "" {
+			assert.NoError(t, err, tt.url)
+			continue
+		}
+		assert.ErrorContains(t, err, tt.wantErr, tt.url)
+	}
+}
@@ -3,6 +3,7 @@ package models
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -146,3 +147,72 @@ func ConvertMapToStringMap(m map[string]interface{}) map[string]string {
 	}
 	return result
 }
+
+// ExtractJSONPath extracts a value from a JSON document using a simple path
+// such as "$.data.items[0].id". A path of "$" returns the whole document.
+func ExtractJSONPath(data string, path string) (string, error) {
+	var current interface{}
+	if err := json.Unmarshal([]byte(data), &current); err != nil {
+		return "", fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	// Normalize the path into segments, turning "[0]" into ".[0]"
+	path = strings.TrimPrefix(strings.TrimSpace(path), "$")
+	path = strings.ReplaceAll(path, "[", ".[")
+
+	for _, segment := range strings.Split(path, ".") {
+		if segment == "" {
+			continue
+		}
+
+		// Handle array indexing
+		if strings.HasPrefix(segment, "[") && strings.HasSuffix(segment, "]") {
+			var index int
+			if _, err := fmt.Sscanf(segment, "[%d]", &index); err != nil {
+				return "", fmt.Errorf("invalid array index: %s", segment)
+			}
+
+			arr, ok := current.([]interface{})
+			if !ok {
+				return "", fmt.Errorf("expected array but got: %T", current)
+			}
+			if index < 0 || index >= len(arr) {
+				return "", fmt.Errorf("array index out of bounds: %d", index)
+			}
+
+			current = arr[index]
+			continue
+		}
+
+		// Handle object property
+		obj, ok := current.(map[string]interface{})
+		if !ok {
+			return "", fmt.Errorf("expected object but got: %T", current)
+		}
+
+		value, ok := obj[segment]
+		if !ok {
+			return "", fmt.Errorf("property not found: %s", segment)
+		}
+
+		current = value
+	}
+
+	// Convert the value to a string
+	switch v := current.(type) {
+	case string:
+		return v, nil
+	case nil:
+		return "null", nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case map[string]interface{}, []interface{}:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(encoded), nil
+	default:
+		return fmt.Sprintf("%v", v), nil
+	}
+}
@@ -4,6 +4,7 @@ package models
 type SchemaValidationResult struct {
 	Valid          bool              `json:"valid"`
 	Errors         []ValidationError `json:"errors,omitempty"`
+	Warnings       []ValidationError `json:"warnings,omitempty"`
 	SchemaPath     string            `json:"schemaPath,omitempty"`
 	ResponseStatus int               `json:"responseStatus"`
 	ContentType    string            `json:"contentType"`
@@ -24,5 +25,8 @@ type ValidationOptions struct {
 	IgnorePatterns             bool      `json:"ignorePatterns"`
 	RequiredPropertiesOnly     bool      `json:"requiredPropertiesOnly"`
 	IgnoreNullable             bool      `json:"ignoreNullable"`
+	RequireWriteOnly           bool      `json:"requireWriteOnly"`
+	FormatWarnings             bool      `json:"formatWarnings"`
+	WarningsAsErrors           bool      `json:"warningsAsErrors"`
 	IgnoredProperties          []string  `json:"ignoredProperties,omitempty"`
 }
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Namespaces of variables, referenced as e.g. {{env.TOKEN}} to read a source
+// whatever the others hold
+const (
+	VariableNamespaceEnv       = "env"       // Environment variables of the run
+	VariableNamespaceFile      = "file"      // Variable files scoped to the .http file
+	VariableNamespaceData      = "data"      // Row of the data file
+	VariableNamespaceSequence  = "sequence"  // Variables declared by the sequence
+	VariableNamespaceExtracted = "extracted" // Extracted from the responses of earlier steps
+	VariableNamespaceCLI       = "cli"       // Given with --var
+)
+
+// VariablePrecedence lists the namespaces from the lowest to the highest
+// precedence: a bare {{name}} takes the value of the last one defining it
+var VariablePrecedence = []string{
+	VariableNamespaceEnv,
+	VariableNamespaceFile,
+	VariableNamespaceData,
+	VariableNamespaceSequence,
+	VariableNamespaceExtracted,
+	VariableNamespaceCLI,
+}
+
+// VariableSet holds the variables of a request or sequence by namespace. The
+// zero value is an empty set.
+type VariableSet struct {
+	namespaces map[string]map[string]string
+}
+
+// Set replaces the variables of a namespace
+func (s *VariableSet) Set(namespace string, values map[string]string) {
+	if s.namespaces == nil {
+		s.namespaces = make(map[string]map[string]string)
+	}
+	s.namespaces[namespace] = nil
+	s.Add(namespace, values)
+}
+
+// Add adds variables to a namespace, replacing those with the same names
+func (s *VariableSet) Add(namespace string, values map[string]string) {
+	if len(values) == 0 {
+		return
+	}
+	if s.namespaces == nil {
+		s.namespaces = make(map[string]map[string]string)
+	}
+	if s.namespaces[namespace] == nil {
+		s.namespaces[namespace] = make(map[string]string, len(values))
+	}
+	for name, value := range values {
+		s.namespaces[namespace][name] = value
+	}
+}
+
+// Bare returns the variables by their bare names, each taking the value of
+// the namespace of highest precedence defining it
+func (s *VariableSet) Bare() map[string]string {
+	bare := make(map[string]string)
+	for _, namespace := range VariablePrecedence {
+		for name, value := range s.namespaces[namespace] {
+			bare[name] = value
+		}
+	}
+	return bare
+}
+
+// Variables returns the variables by their bare names, see Bare, and by their
+// namespaced names such as "env.TOKEN", for substitution
+func (s *VariableSet) Variables() map[string]string {
+	variables := s.Bare()
+	for _, namespace := range VariablePrecedence {
+		for name, value := range s.namespaces[namespace] {
+			variables[namespace+"."+name] = value
+		}
+	}
+	return variables
+}
+
+// ParseVariables parses "name=value" assignments of variables, e.g. from --var
+func ParseVariables(assignments []string) (map[string]string, error) {
+	variables := make(map[string]string, len(assignments))
+	for _, assignment := range assignments {
+		name, value, ok := strings.Cut(assignment, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid variable %q: use name=value", assignment)
+		}
+		variables[strings.TrimSpace(name)] = value
+	}
+	return variables, nil
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVariableSet(t *testing.T) {
+	var set VariableSet
+	set.Set(VariableNamespaceCLI, map[string]string{"baseUrl": "http://localhost:8080"})
+	set.Set(VariableNamespaceEnv, map[string]string{"TOKEN": "env-token", "baseUrl": "https://staging.example.com"})
+	set.Set(VariableNamespaceFile, map[string]string{"TOKEN": "file-token"})
+	set.Add(VariableNamespaceExtracted, map[string]string{"userId": "1"})
+	set.Add(VariableNamespaceExtracted, map[string]string{"userId": "2"})
+
+	assert.Equal(t, map[string]string{
+		"TOKEN":   "file-token",
+		"baseUrl": "http://localhost:8080",
+		"userId":  "2",
+	}, set.Bare())
+
+	variables := set.Variables()
+	assert.Equal(t, "file-token", variables["TOKEN"])
+	assert.Equal(t, "env-token", variables["env.TOKEN"])
+	assert.Equal(t, "file-token", variables["file.TOKEN"])
+	assert.Equal(t, "https://staging.example.com", variables["env.baseUrl"])
+	assert.Equal(t, "http://localhost:8080", variables["cli.baseUrl"])
+	assert.Equal(t, "2", variables["extracted.userId"])
+
+	set.Set(VariableNamespaceFile, nil)
+	assert.Equal(t, "env-token", set.Bare()["TOKEN"])
+}
+
+func TestParseVariables(t *testing.T) {
+	variables, err := ParseVariables([]string{"baseUrl=http://localhost:8080", "filter=a=b"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"baseUrl": "http://localhost:8080", "filter": "a=b"}, variables)
+
+	_, err = ParseVariables([]string{"=value"})
+	assert.Error(t, err)
+}
@@ -54,11 +54,11 @@ func (s *AssertionEvaluatorService) EvaluateAssertion(
 	actualValue, err := s.getValueFromResponse(response, assertion.Source, assertion.Path)
 	if err != nil {
 		return &models.TestAssertionResult{
-			Type:      assertion.Type,
-			Source:    assertion.Source,
-			Path:      assertion.Path,
-			Succeeded: false,
-			Message:   fmt.Sprintf("Error extracting value: %s", err),
+			Type:   assertion.Type,
+			Source: assertion.Source,
+			Path:   assertion.Path,
+			Passed: false,
+			Error:  fmt.Sprintf("Error extracting value: %s", err),
 		}, nil
 	}
 	
@@ -79,15 +79,46 @@ func (s *AssertionEvaluatorService) EvaluateAssertion(
 		if assertion.IgnoreCase {
 			equals = strings.EqualFold(strings.ToLower(actualValue), strings.ToLower(expected))
 		}
-		result.Succeeded = (equals != assertion.Not)
-		if !result.Succeeded {
+		// Compare numbers with the tolerance if one is set
+		if !equals && assertion.Tolerance != nil {
+			equals = approxEqual(expected, actualValue, *assertion.Tolerance)
+		}
+		// Match header values given as patterns, such as "<<uuid>>"; the
+		// header must be present even if any value is accepted
+		if strings.HasSuffix(strings.ToLower(assertion.Source), "header") && models.IsHeaderValuePattern(expected) {
+			matched, err := models.MatchHeaderValue(expected, actualValue)
+			if err != nil {
+				return nil, err
+			}
+			equals = matched && actualValue != ""
+		}
+		result.Passed = (equals != assertion.Not)
+		if !result.Passed {
 			if assertion.Not {
-				result.Message = fmt.Sprintf("Expected value to not equal '%s'", expected)
+				result.Error = fmt.Sprintf("Expected value to not equal '%s'", expected)
 			} else {
-				result.Message = fmt.Sprintf("Expected '%s', got '%s'", expected, actualValue)
+				result.Error = fmt.Sprintf("Expected '%s', got '%s'", expected, actualValue)
 			}
 		}
 		
+	case "approx":
+		tolerance := models.DefaultTolerance
+		if assertion.Tolerance != nil {
+			tolerance = *assertion.Tolerance
+		}
+		if _, err := strconv.ParseFloat(assertion.Value, 64); err != nil {
+			return nil, fmt.Errorf("invalid number for comparison: %w", err)
+		}
+		result.Expected = assertion.Value
+		result.Passed = (approxEqual(assertion.Value, actualValue, tolerance) != assertion.Not)
+		if !result.Passed {
+			if assertion.Not {
+				result.Error = fmt.Sprintf("Expected value to not be within %s of %s", tolerance, assertion.Value)
+			} else {
+				result.Error = fmt.Sprintf("Expected %s (%s), got '%s'", assertion.Value, tolerance, actualValue)
+			}
+		}
+
 	case "contains":
 		expected := assertion.Value
 		result.Expected = expected
@@ -95,12 +126,12 @@ func (s *AssertionEvaluatorService) EvaluateAssertion(
 		if assertion.IgnoreCase {
 			contains = strings.Contains(strings.ToLower(actualValue), strings.ToLower(expected))
 		}
-		result.Succeeded = (contains != assertion.Not)
-		if !result.Succeeded {
+		result.Passed = (contains != assertion.Not)
+		if !result.Passed {
 			if assertion.Not {
-				result.Message = fmt.Sprintf("Expected value to not contain '%s'", expected)
+				result.Error = fmt.Sprintf("Expected value to not contain '%s'", expected)
 			} else {
-				result.Message = fmt.Sprintf("Expected to contain '%s', got '%s'", expected, actualValue)
+				result.Error = fmt.Sprintf("Expected to contain '%s', got '%s'", expected, actualValue)
 			}
 		}
 		
@@ -111,31 +142,31 @@ func (s *AssertionEvaluatorService) EvaluateAssertion(
 		if err != nil {
 			return nil, fmt.Errorf("invalid regex pattern: %w", err)
 		}
-		result.Succeeded = (matched != assertion.Not)
-		if !result.Succeeded {
+		result.Passed = (matched != assertion.Not)
+		if !result.Passed {
 			if assertion.Not {
-				result.Message = fmt.Sprintf("Expected value to not match pattern '%s'", pattern)
+				result.Error = fmt.Sprintf("Expected value to not match pattern '%s'", pattern)
 			} else {
-				result.Message = fmt.Sprintf("Expected to match pattern '%s', got '%s'", pattern, actualValue)
+				result.Error = fmt.Sprintf("Expected to match pattern '%s', got '%s'", pattern, actualValue)
 			}
 		}
 		
 	case "exists":
 		// For "exists", we just check if the value was successfully extracted
-		result.Succeeded = (actualValue != "" != assertion.Not)
-		if !result.Succeeded {
+		result.Passed = (actualValue != "" != assertion.Not)
+		if !result.Passed {
 			if assertion.Not {
-				result.Message = "Expected value to not exist"
+				result.Error = "Expected value to not exist"
 			} else {
-				result.Message = "Expected value to exist"
+				result.Error = "Expected value to exist"
 			}
 		}
 		
 	case "notexists":
 		// "notexists" is the opposite of "exists"
-		result.Succeeded = (actualValue == "")
-		if !result.Succeeded {
-			result.Message = fmt.Sprintf("Expected value to not exist, got '%s'", actualValue)
+		result.Passed = (actualValue == "")
+		if !result.Passed {
+			result.Error = fmt.Sprintf("Expected value to not exist, got '%s'", actualValue)
 		}
 		
 	case "in":
@@ -159,12 +190,12 @@ func (s *AssertionEvaluatorService) EvaluateAssertion(
 		// Join the expected values for display
 		expectedStr := strings.Join(expectedValues, ", ")
 		result.Expected = expectedStr
-		result.Succeeded = (found != assertion.Not)
-		if !result.Succeeded {
+		result.Passed = (found != assertion.Not)
+		if !result.Passed {
 			if assertion.Not {
-				result.Message = fmt.Sprintf("Expected value to not be one of [%s]", expectedStr)
+				result.Error = fmt.Sprintf("Expected value to not be one of [%s]", expectedStr)
 			} else {
-				result.Message = fmt.Sprintf("Expected value to be one of [%s], got '%s'", expectedStr, actualValue)
+				result.Error = fmt.Sprintf("Expected value to be one of [%s], got '%s'", expectedStr, actualValue)
 			}
 		}
 		
@@ -178,12 +209,12 @@ func (s *AssertionEvaluatorService) EvaluateAssertion(
 			return nil, fmt.Errorf("value is not a number: %w", err)
 		}
 		result.Expected = assertion.Value
-		result.Succeeded = ((actual < expected) != assertion.Not)
-		if !result.Succeeded {
+		result.Passed = ((actual < expected) != assertion.Not)
+		if !result.Passed {
 			if assertion.Not {
-				result.Message = fmt.Sprintf("Expected value to not be less than %v", expected)
+				result.Error = fmt.Sprintf("Expected value to not be less than %v", expected)
 			} else {
-				result.Message = fmt.Sprintf("Expected value to be less than %v, got %v", expected, actual)
+				result.Error = fmt.Sprintf("Expected value to be less than %v, got %v", expected, actual)
 			}
 		}
 		
@@ -197,23 +228,23 @@ func (s *AssertionEvaluatorService) EvaluateAssertion(
 			return nil, fmt.Errorf("value is not a number: %w", err)
 		}
 		result.Expected = assertion.Value
-		result.Succeeded = ((actual > expected) != assertion.Not)
-		if !result.Succeeded {
+		result.Passed = ((actual > expected) != assertion.Not)
+		if !result.Passed {
 			if assertion.Not {
-				result.Message = fmt.Sprintf("Expected value to not be greater than %v", expected)
+				result.Error = fmt.Sprintf("Expected value to not be greater than %v", expected)
 			} else {
-				result.Message = fmt.Sprintf("Expected value to be greater than %v, got %v", expected, actual)
+				result.Error = fmt.Sprintf("Expected value to be greater than %v, got %v", expected, actual)
 			}
 		}
 		
 	case "null", "nil":
 		isNull := (actualValue == "null" || actualValue == "")
-		result.Succeeded = (isNull != assertion.Not)
-		if !result.Succeeded {
+		result.Passed = (isNull != assertion.Not)
+		if !result.Passed {
 			if assertion.Not {
-				result.Message = "Expected value to not be null"
+				result.Error = "Expected value to not be null"
 			} else {
-				result.Message = fmt.Sprintf("Expected null, got '%s'", actualValue)
+				result.Error = fmt.Sprintf("Expected null, got '%s'", actualValue)
 			}
 		}
 		
@@ -224,12 +255,33 @@ func (s *AssertionEvaluatorService) EvaluateAssertion(
 	return result, nil
 }
 
+// approxEqual checks if two numeric strings are equal within the tolerance
+func approxEqual(expected, actual string, tolerance models.Tolerance) bool {
+	e, err := strconv.ParseFloat(strings.TrimSpace(expected), 64)
+	if err != nil {
+		return false
+	}
+	a, err := strconv.ParseFloat(strings.TrimSpace(actual), 64)
+	if err != nil {
+		return false
+	}
+	return tolerance.Within(e, a)
+}
+
 // getValueFromResponse retrieves a value from the response based on source and path
 func (s *AssertionEvaluatorService) getValueFromResponse(
 	response *models.HTTPResponse,
 	source string,
 	path string,
 ) (string, error) {
+	// "initial.<source>" reads the 202 Accepted response of a polled asynchronous operation
+	if lower := strings.ToLower(source); strings.HasPrefix(lower, "initial.") {
+		if response.InitialResponse == nil {
+			return "", fmt.Errorf("no initial response: the request did not start an asynchronous operation")
+		}
+		return s.getValueFromResponse(response.InitialResponse, strings.TrimPrefix(lower, "initial."), path)
+	}
+
 	switch strings.ToLower(source) {
 	case "body":
 		// For body, use JSON path extraction if path is provided
@@ -262,12 +314,49 @@ func (s *AssertionEvaluatorService) getValueFromResponse(
 		
 	case "contenttype":
 		return response.ContentType, nil
+
+	case "url":
+		return response.URL, nil
+
+	case "redirects":
+		return strconv.Itoa(len(response.Redirects)), nil
+
+	case "redirect":
+		return redirectValue(response.Redirects, path)
 		
 	default:
 		return "", fmt.Errorf("unsupported assertion source: %s", source)
 	}
 }
 
+// redirectValue returns a field of a redirect followed to reach a response,
+// from a path such as "0.location": the index of the hop, negative counting
+// from the last one, and "status", "location" or "url"
+func redirectValue(hops []models.RedirectHop, path string) (string, error) {
+	indexPart, field, ok := strings.Cut(path, ".")
+	index, err := strconv.Atoi(indexPart)
+	if !ok || err != nil {
+		return "", fmt.Errorf("invalid redirect path %q: use <index>.status, <index>.location or <index>.url", path)
+	}
+	if index < 0 {
+		index += len(hops)
+	}
+	if index < 0 || index >= len(hops) {
+		return "", fmt.Errorf("no redirect %s: %d redirects were followed", indexPart, len(hops))
+	}
+
+	hop := hops[index]
+	switch strings.ToLower(field) {
+	case "status":
+		return strconv.Itoa(hop.StatusCode), nil
+	case "location":
+		return hop.Location, nil
+	case "url":
+		return hop.URL, nil
+	}
+	return "", fmt.Errorf("invalid redirect field %q: use status, location or url", field)
+}
+
 // parseBody tries to parse the response body as JSON
 func (s *AssertionEvaluatorService) parseBody(body []byte) (interface{}, error) {
 	var parsed interface{}
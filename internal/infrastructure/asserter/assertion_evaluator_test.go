@@ -0,0 +1,75 @@
+package asserter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluateAssertion_Tolerance(t *testing.T) {
+	response := &models.HTTPResponse{StatusCode: 200, Body: "10.004"}
+	tolerance := &models.Tolerance{Absolute: 0.01}
+
+	tests := []struct {
+		name      string
+		assertion models.TestAssertion
+		passed    bool
+		error     string
+	}{
+		{
+			name:      "approx within tolerance",
+			assertion: models.TestAssertion{Type: "approx", Source: "body", Value: "10", Tolerance: tolerance},
+			passed:    true,
+		},
+		{
+			name:      "approx beyond tolerance",
+			assertion: models.TestAssertion{Type: "approx", Source: "body", Value: "10.1", Tolerance: tolerance},
+			error:     "Expected 10.1 (abs=0.01), got '10.004'",
+		},
+		{
+			name:      "approx with the default tolerance",
+			assertion: models.TestAssertion{Type: "approx", Source: "body", Value: "10"},
+			error:     "Expected 10 (abs=1e-09), got '10.004'",
+		},
+		{
+			name:      "not approx",
+			assertion: models.TestAssertion{Type: "approx", Source: "body", Value: "10", Not: true, Tolerance: tolerance},
+			error:     "Expected value to not be within abs=0.01 of 10",
+		},
+		{
+			name:      "equals within tolerance",
+			assertion: models.TestAssertion{Type: "equals", Source: "body", Value: "10", Tolerance: tolerance},
+			passed:    true,
+		},
+		{
+			name:      "equals without tolerance",
+			assertion: models.TestAssertion{Type: "equals", Source: "body", Value: "10"},
+			error:     "Expected '10', got '10.004'",
+		},
+	}
+
+	evaluator := NewAssertionEvaluatorService()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := evaluator.EvaluateAssertion(context.Background(), response, tt.assertion)
+			require.NoError(t, err)
+			assert.Equal(t, tt.passed, result.Passed)
+			assert.Equal(t, tt.error, result.Error)
+		})
+	}
+
+	_, err := evaluator.EvaluateAssertion(context.Background(), response, models.TestAssertion{Type: "approx", Source: "body", Value: "ten"})
+	assert.Error(t, err)
+}
+
+func TestApproxEqual(t *testing.T) {
+	tolerance := models.Tolerance{Relative: 0.01}
+
+	assert.True(t, approxEqual("100", " 100.5 ", tolerance))
+	assert.False(t, approxEqual("100", "102", tolerance))
+	assert.False(t, approxEqual("100", "n/a", tolerance))
+	assert.False(t, approxEqual("", "100", tolerance))
+}
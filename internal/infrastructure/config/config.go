@@ -47,8 +47,59 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("generator.include_auth", false)
 	v.SetDefault("generator.auth_header", "Authorization")
 	v.SetDefault("generator.default_tag", "default")
+	v.SetDefault("generator.multi_tag", "first")
+	v.SetDefault("generator.shared_dir", "shared")
+	v.SetDefault("generator.body_mode", "full")
+	v.SetDefault("generator.include_read_only", false)
+	v.SetDefault("generator.security_filter", []string{})
+	v.SetDefault("generator.skip_unauthenticated", false)
+	v.SetDefault("generator.manifest", true)
+	v.SetDefault("generator.format", "http")
+	v.SetDefault("plugins.generators", map[string]string{})
+	v.SetDefault("plugins.reporters", map[string]string{})
+	v.SetDefault("plugins.go", []string{})
 	v.SetDefault("snapshots.directory", "snapshots")
 	v.SetDefault("snapshots.update_on_difference", false)
+	v.SetDefault("snapshots.strict_version", false)
+	v.SetDefault("snapshots.store", "")
+	v.SetDefault("snapshots.dedupe", false)
+	v.SetDefault("snapshots.compression", "none")
+	v.SetDefault("snapshots.ignore_array_order", false)
+	v.SetDefault("snapshots.array_order_key", "")
+	v.SetDefault("snapshots.tolerances", []string{})
+	v.SetDefault("snapshots.ignore_headers", []string{"Date", "Content-Length", "Server", "Connection", "Set-Cookie"})
+	v.SetDefault("snapshots.ignore_headers_by_tag", map[string][]string{})
+	v.SetDefault("test.allow_mutations", false)
+	v.SetDefault("test.delete_allowlist", []string{})
+	v.SetDefault("test.budgets", map[string]interface{}{})
+	v.SetDefault("test.quarantine_file", "")
+	v.SetDefault("test.memory_limit", "")
+	v.SetDefault("test.spill_dir", "")
+	v.SetDefault("test.name_template", "{{method}} {{slug}}")
+	v.SetDefault("test.host", "")
+	v.SetDefault("executor.poll.enabled", true)
+	v.SetDefault("executor.poll.interval", "1s")
+	v.SetDefault("executor.poll.timeout", "1m")
+	v.SetDefault("executor.accept_encoding", "gzip, deflate")
+	v.SetDefault("executor.timeouts.connect", "30s")
+	v.SetDefault("executor.timeouts.tls_handshake", "10s")
+	v.SetDefault("executor.timeouts.response_header", "0s")
+	v.SetDefault("executor.timeouts.request", "30s")
+	v.SetDefault("executor.decompress", true)
+	v.SetDefault("executor.extra_methods", []string{})
+	v.SetDefault("executor.max_body_bytes", "0")
+	v.SetDefault("executor.grpc.descriptor_sets", []string{})
+	v.SetDefault("executor.retry.max", 0)
+	v.SetDefault("executor.retry.initial_backoff", "500ms")
+	v.SetDefault("executor.retry.max_backoff", "30s")
+	v.SetDefault("executor.circuit_breaker.threshold", 5)
+	v.SetDefault("executor.circuit_breaker.cool_down", "30s")
+	v.SetDefault("monitor.schedule", "@every 5m")
+	v.SetDefault("monitor.state_file", ".swagger-to-http/monitor-state.json")
+	v.SetDefault("monitor.failure_threshold", 1)
+	v.SetDefault("monitor.webhooks", []string{})
+	v.SetDefault("monitor.slack_webhooks", []string{})
+	v.SetDefault("lint.severity", map[string]string{})
 }
 
 // GetString retrieves a string configuration value
@@ -0,0 +1,232 @@
+// Package control lets editors and scripts query and steer long-running watch
+// and monitor sessions through a small local HTTP API.
+package control
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// Controller states
+const (
+	StateIdle    = "idle"
+	StateRunning = "running"
+	StatePaused  = "paused"
+)
+
+// RunFunc runs the tests of one run
+type RunFunc func(ctx context.Context, options models.TestRunOptions) (*models.TestReport, error)
+
+// Progress describes the progress of the current run
+type Progress struct {
+	Total     int                 `json:"total"`
+	Completed models.StatusCounts `json:"completed"`
+}
+
+// RunInfo describes a finished run
+type RunInfo struct {
+	StartedAt  time.Time           `json:"startedAt"`
+	FinishedAt time.Time           `json:"finishedAt"`
+	Aborted    bool                `json:"aborted,omitempty"`
+	Error      string              `json:"error,omitempty"`
+	Summary    *models.TestSummary `json:"summary,omitempty"`
+}
+
+// Status is the state of the controller returned by the API
+type Status struct {
+	State     string     `json:"state"`
+	Paused    bool       `json:"paused"`
+	Runs      int        `json:"runs"`
+	StartedAt *time.Time `json:"startedAt,omitempty"`
+	Progress  *Progress  `json:"progress,omitempty"`
+	LastRun   *RunInfo   `json:"lastRun,omitempty"`
+}
+
+// Controller tracks the runs of a watch or monitor session and lets them be
+// paused, aborted and re-run
+type Controller struct {
+	mu        sync.Mutex
+	running   bool
+	paused    bool
+	resumed   chan struct{}
+	cancel    context.CancelFunc
+	aborted   bool
+	runs      int
+	startedAt time.Time
+	progress  Progress
+	lastRun   *RunInfo
+	reruns    chan struct{}
+}
+
+// NewController creates a new Controller
+func NewController() *Controller {
+	return &Controller{
+		reruns: make(chan struct{}, 1),
+	}
+}
+
+// Run runs the tests under the controller: it waits while the controller is
+// paused, tracks the progress of the run and cancels it when aborted
+func (c *Controller) Run(ctx context.Context, options models.TestRunOptions, run RunFunc) (*models.TestReport, error) {
+	if err := c.waitUntilResumed(ctx); err != nil {
+		return nil, err
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	c.mu.Lock()
+	c.running = true
+	c.cancel = cancel
+	c.aborted = false
+	c.runs++
+	c.startedAt = time.Now()
+	c.progress = Progress{}
+	c.mu.Unlock()
+
+	options.Progress = &progressListener{controller: c, next: options.Progress}
+	report, err := run(runCtx, options)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	info := &RunInfo{
+		StartedAt:  c.startedAt,
+		FinishedAt: time.Now(),
+		Aborted:    c.aborted,
+	}
+	if err != nil {
+		info.Error = err.Error()
+	}
+	if report != nil {
+		summary := report.Summary
+		info.Summary = &summary
+	}
+	c.lastRun = info
+	c.running = false
+	c.cancel = nil
+
+	return report, err
+}
+
+// Status returns the current state of the controller
+func (c *Controller) Status() Status {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	status := Status{
+		State:   StateIdle,
+		Paused:  c.paused,
+		Runs:    c.runs,
+		LastRun: c.lastRun,
+	}
+	switch {
+	case c.running:
+		status.State = StateRunning
+		startedAt := c.startedAt
+		status.StartedAt = &startedAt
+		progress := c.progress
+		status.Progress = &progress
+	case c.paused:
+		status.State = StatePaused
+	}
+	return status
+}
+
+// Abort cancels the run in progress. It returns false when no run is in progress.
+func (c *Controller) Abort() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.running || c.cancel == nil {
+		return false
+	}
+	c.aborted = true
+	c.cancel()
+	return true
+}
+
+// Pause stops new runs from starting until Resume is called. The run in
+// progress, if any, is completed.
+func (c *Controller) Pause() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.paused {
+		c.paused = true
+		c.resumed = make(chan struct{})
+	}
+}
+
+// Resume lets paused runs start again
+func (c *Controller) Resume() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.paused {
+		c.paused = false
+		close(c.resumed)
+	}
+}
+
+// Rerun requests a new run. Requests made while a rerun is pending are coalesced.
+func (c *Controller) Rerun() {
+	select {
+	case c.reruns <- struct{}{}:
+	default:
+	}
+}
+
+// Reruns returns the channel receiving the requested reruns
+func (c *Controller) Reruns() <-chan struct{} {
+	return c.reruns
+}
+
+// waitUntilResumed blocks while the controller is paused
+func (c *Controller) waitUntilResumed(ctx context.Context) error {
+	c.mu.Lock()
+	if !c.paused {
+		c.mu.Unlock()
+		return nil
+	}
+	resumed := c.resumed
+	c.mu.Unlock()
+
+	select {
+	case <-resumed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// progressListener records the progress of a run and forwards it to the
+// listener configured in the run options, if any
+type progressListener struct {
+	controller *Controller
+	next       models.ProgressListener
+}
+
+// Start implements models.ProgressListener
+func (l *progressListener) Start(total int) {
+	l.controller.mu.Lock()
+	l.controller.progress.Total = total
+	l.controller.mu.Unlock()
+
+	if l.next != nil {
+		l.next.Start(total)
+	}
+}
+
+// Completed implements models.ProgressListener
+func (l *progressListener) Completed(result models.TestResult) {
+	l.controller.mu.Lock()
+	l.controller.progress.Completed.Add(result.Status)
+	l.controller.mu.Unlock()
+
+	if l.next != nil {
+		l.next.Completed(result)
+	}
+}
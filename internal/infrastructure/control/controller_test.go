@@ -0,0 +1,117 @@
+package control
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getStatus(t *testing.T, server *httptest.Server) Status {
+	t.Helper()
+	resp, err := http.Get(server.URL + "/status")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var status Status
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&status))
+	return status
+}
+
+func post(t *testing.T, server *httptest.Server, path string) int {
+	t.Helper()
+	resp, err := http.Post(server.URL+path, "application/json", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestControllerProgressAndAbort(t *testing.T) {
+	controller := NewController()
+	server := httptest.NewServer(controller.Handler())
+	defer server.Close()
+
+	assert.Equal(t, StateIdle, getStatus(t, server).State)
+	assert.Equal(t, http.StatusConflict, post(t, server, "/abort"))
+
+	started := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		_, err := controller.Run(context.Background(), models.TestRunOptions{}, func(ctx context.Context, options models.TestRunOptions) (*models.TestReport, error) {
+			options.Progress.Start(3)
+			options.Progress.Completed(models.TestResult{Status: models.TestStatusPassed})
+			options.Progress.Completed(models.TestResult{Status: models.TestStatusFailed})
+			close(started)
+			<-ctx.Done()
+			return &models.TestReport{Summary: models.TestSummary{TotalTests: 2}}, ctx.Err()
+		})
+		done <- err
+	}()
+	<-started
+
+	status := getStatus(t, server)
+	assert.Equal(t, StateRunning, status.State)
+	assert.Equal(t, 1, status.Runs)
+	require.NotNil(t, status.Progress)
+	assert.Equal(t, 3, status.Progress.Total)
+	assert.Equal(t, models.StatusCounts{Total: 2, Passed: 1, Failed: 1}, status.Progress.Completed)
+
+	assert.Equal(t, http.StatusOK, post(t, server, "/abort"))
+	assert.ErrorIs(t, <-done, context.Canceled)
+
+	status = getStatus(t, server)
+	assert.Equal(t, StateIdle, status.State)
+	require.NotNil(t, status.LastRun)
+	assert.True(t, status.LastRun.Aborted)
+	assert.Equal(t, 2, status.LastRun.Summary.TotalTests)
+}
+
+func TestControllerPauseAndRerun(t *testing.T) {
+	controller := NewController()
+	server := httptest.NewServer(controller.Handler())
+	defer server.Close()
+
+	assert.Equal(t, http.StatusOK, post(t, server, "/pause"))
+	assert.Equal(t, StatePaused, getStatus(t, server).State)
+
+	// Runs wait until the controller is resumed
+	ran := make(chan struct{})
+	go controller.Run(context.Background(), models.TestRunOptions{}, func(ctx context.Context, options models.TestRunOptions) (*models.TestReport, error) {
+		close(ran)
+		return &models.TestReport{}, nil
+	})
+	select {
+	case <-ran:
+		t.Fatal("run started while paused")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	assert.Equal(t, http.StatusOK, post(t, server, "/resume"))
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Fatal("run didn't start after resuming")
+	}
+
+	// Reruns are coalesced until received
+	assert.Equal(t, http.StatusOK, post(t, server, "/rerun"))
+	assert.Equal(t, http.StatusOK, post(t, server, "/rerun"))
+	<-controller.Reruns()
+	select {
+	case <-controller.Reruns():
+		t.Fatal("reruns were not coalesced")
+	default:
+	}
+
+	resp, err := http.Get(server.URL + "/rerun")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
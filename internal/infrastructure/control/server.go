@@ -0,0 +1,123 @@
+package control
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// unixPrefix selects a unix socket instead of a TCP address
+const unixPrefix = "unix:"
+
+// Listen listens on a TCP address such as "127.0.0.1:7777" or, with a "unix:"
+// prefix, on a unix socket such as "unix:/tmp/swagger-to-http.sock"
+func Listen(addr string) (net.Listener, error) {
+	if path, ok := strings.CutPrefix(addr, unixPrefix); ok {
+		// Remove a socket left behind by a previous session
+		if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+			os.Remove(path)
+		}
+		listener, err := net.Listen("unix", path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+		}
+		return listener, nil
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	return listener, nil
+}
+
+// Serve serves the control API on the listener until the context is cancelled
+func (c *Controller) Serve(ctx context.Context, listener net.Listener) error {
+	server := &http.Server{
+		Handler:           c.Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("control server failed: %w", err)
+	}
+	return nil
+}
+
+// Handler returns the HTTP handler of the control API:
+//
+//	GET  /status  current state, progress of the run in progress and the last run
+//	POST /abort   cancel the run in progress
+//	POST /rerun   start a new run
+//	POST /pause   stop new runs from starting
+//	POST /resume  let runs start again
+func (c *Controller) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, "use GET")
+			return
+		}
+		writeJSON(w, http.StatusOK, c.Status())
+	})
+	mux.HandleFunc("/abort", c.action(func() error {
+		if !c.Abort() {
+			return errors.New("no run in progress")
+		}
+		return nil
+	}))
+	mux.HandleFunc("/rerun", c.action(func() error {
+		c.Rerun()
+		return nil
+	}))
+	mux.HandleFunc("/pause", c.action(func() error {
+		c.Pause()
+		return nil
+	}))
+	mux.HandleFunc("/resume", c.action(func() error {
+		c.Resume()
+		return nil
+	}))
+	return mux
+}
+
+// action handles a POST request running fn and responds with the new status,
+// or with 409 Conflict when fn fails
+func (c *Controller) action(fn func() error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, "use POST")
+			return
+		}
+		if err := fn(); err != nil {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, c.Status())
+	}
+}
+
+// writeJSON writes value as a JSON response
+func writeJSON(w http.ResponseWriter, code int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(value)
+}
+
+// writeError writes a JSON error response
+func writeError(w http.ResponseWriter, code int, message string) {
+	writeJSON(w, code, map[string]string{"error": message})
+}
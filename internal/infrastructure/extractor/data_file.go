@@ -0,0 +1,102 @@
+package extractor
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// LoadDataFile loads the rows of a CSV or JSON data file for data-driven test runs.
+// CSV files use the first line as column names; JSON files contain an array of
+// flat objects. Rows are identified by their "id" or "name" column when present.
+func LoadDataFile(path string) ([]models.DataRow, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data file: %w", err)
+	}
+
+	var records []map[string]string
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		records, err = parseJSONData(data)
+	} else {
+		records, err = parseCSVData(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse data file %s: %w", path, err)
+	}
+
+	rows := make([]models.DataRow, 0, len(records))
+	for i, values := range records {
+		rows = append(rows, models.DataRow{
+			ID:     dataRowID(i, values),
+			Values: values,
+		})
+	}
+
+	return rows, nil
+}
+
+// parseCSVData parses CSV records keyed by the header columns. Columns missing
+// from a row are left out of its record and extra ones are ignored.
+func parseCSVData(data []byte) ([]map[string]string, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	lines, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, nil
+	}
+
+	header := lines[0]
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+
+	records := make([]map[string]string, 0, len(lines)-1)
+	for _, line := range lines[1:] {
+		record := make(map[string]string, len(header))
+		for i, column := range header {
+			if i < len(line) {
+				record[column] = line[i]
+			}
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// parseJSONData parses an array of flat JSON objects
+func parseJSONData(data []byte) ([]map[string]string, error) {
+	var raw []map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	records := make([]map[string]string, 0, len(raw))
+	for _, object := range raw {
+		records = append(records, stringifyJSONValues(object))
+	}
+
+	return records, nil
+}
+
+// dataRowID returns the identifier of a data row
+func dataRowID(index int, values map[string]string) string {
+	for _, column := range []string{"id", "name"} {
+		if value := values[column]; value != "" {
+			return fmt.Sprintf("%s=%s", column, value)
+		}
+	}
+	return fmt.Sprintf("row %d", index+1)
+}
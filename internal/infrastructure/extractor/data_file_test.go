@@ -0,0 +1,104 @@
+package extractor
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadDataFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		expected []models.DataRow
+		err      string
+	}{
+		{
+			name:    "csv",
+			file:    "users.csv",
+			content: " id , name\n1, Ada\n2, Grace\n",
+			expected: []models.DataRow{
+				{ID: "id=1", Values: map[string]string{"id": "1", "name": "Ada"}},
+				{ID: "id=2", Values: map[string]string{"id": "2", "name": "Grace"}},
+			},
+		},
+		{
+			name:    "csv quoting",
+			file:    "users.csv",
+			content: "name,bio\n\"Lovelace, Ada\",\"said \"\"hello\"\"\nand left\"\n",
+			expected: []models.DataRow{
+				{ID: "name=Lovelace, Ada", Values: map[string]string{"name": "Lovelace, Ada", "bio": "said \"hello\"\nand left"}},
+			},
+		},
+		{
+			name:    "csv ragged rows",
+			file:    "users.csv",
+			content: "email,role,team\na@example.com,admin\nb@example.com,user,core,extra\n",
+			expected: []models.DataRow{
+				{ID: "row 1", Values: map[string]string{"email": "a@example.com", "role": "admin"}},
+				{ID: "row 2", Values: map[string]string{"email": "b@example.com", "role": "user", "team": "core"}},
+			},
+		},
+		{
+			name:     "csv header only",
+			file:     "users.csv",
+			content:  "id,name\n",
+			expected: []models.DataRow{},
+		},
+		{
+			name:     "empty csv",
+			file:     "users.csv",
+			content:  "",
+			expected: []models.DataRow{},
+		},
+		{
+			name:    "csv unterminated quote",
+			file:    "users.csv",
+			content: "id,name\n1,\"Ada\n",
+			err:     "failed to parse data file",
+		},
+		{
+			name:    "json",
+			file:    "users.JSON",
+			content: `[{"name": "Ada", "age": 36, "admin": true, "tags": ["a", "b"]}, {"id": 7, "name": ""}]`,
+			expected: []models.DataRow{
+				{ID: "name=Ada", Values: map[string]string{"name": "Ada", "age": "36", "admin": "true", "tags": `["a", "b"]`}},
+				{ID: "id=7", Values: map[string]string{"id": "7", "name": ""}},
+			},
+		},
+		{
+			name:    "json object",
+			file:    "users.json",
+			content: `{"name": "Ada"}`,
+			err:     "failed to parse data file",
+		},
+		{
+			name:    "json array of values",
+			file:    "users.json",
+			content: `[1, 2]`,
+			err:     "failed to parse data file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			writeFile(t, path, tt.content)
+
+			rows, err := LoadDataFile(path)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, rows)
+		})
+	}
+
+	_, err := LoadDataFile(filepath.Join(t.TempDir(), "missing.csv"))
+	assert.ErrorContains(t, err, "failed to read data file")
+}
@@ -11,6 +11,7 @@ import (
 	"strings"
 
 	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/secrets"
 )
 
 // VariableExtractorService implements the VariableExtractor interface
@@ -79,7 +80,7 @@ func (s *VariableExtractorService) ReplaceVariablesInRequest(
 	}
 	
 	// Make a copy of the request to avoid modifying the original
-	requestCopy := *request
+	requestCopy := request.Clone()
 	
 	// Replace in URL
 	requestCopy.URL = s.ReplaceVariables(requestCopy.URL, variables, format)
@@ -88,24 +89,16 @@ func (s *VariableExtractorService) ReplaceVariablesInRequest(
 	requestCopy.Path = s.ReplaceVariables(requestCopy.Path, variables, format)
 	
 	// Replace in headers
-	for name, values := range requestCopy.Headers {
-		for i, value := range values {
-			requestCopy.Headers[name][i] = s.ReplaceVariables(value, variables, format)
-		}
+	for name, value := range requestCopy.Headers {
+		requestCopy.Headers[name] = s.ReplaceVariables(value, variables, format)
 	}
 	
 	// Replace in body
-	if len(requestCopy.Body) > 0 {
-		bodyStr := string(requestCopy.Body)
-		bodyStr = s.ReplaceVariables(bodyStr, variables, format)
-		requestCopy.Body = []byte(bodyStr)
-	}
+	requestCopy.Body = s.ReplaceVariables(requestCopy.Body, variables, format)
 	
 	// Replace in form values
-	for name, values := range requestCopy.FormValues {
-		for i, value := range values {
-			requestCopy.FormValues[name][i] = s.ReplaceVariables(value, variables, format)
-		}
+	for name, value := range requestCopy.FormValues {
+		requestCopy.FormValues[name] = s.ReplaceVariables(value, variables, format)
 	}
 	
 	// Replace in query parameters
@@ -115,7 +108,7 @@ func (s *VariableExtractorService) ReplaceVariablesInRequest(
 		}
 	}
 	
-	return &requestCopy, nil
+	return requestCopy, nil
 }
 
 // SaveVariables saves variables to a file
@@ -154,6 +147,17 @@ func (s *VariableExtractorService) LoadVariables(
 	if err != nil {
 		return nil, fmt.Errorf("failed to read variables file: %w", err)
 	}
+
+	// Decrypt encrypted files with the key from the environment
+	if secrets.IsEncrypted(data) {
+		cipher, err := secrets.ResolveCipher("", "")
+		if err != nil {
+			return nil, err
+		}
+		if data, err = secrets.DecryptIfNeeded(data, cipher); err != nil {
+			return nil, fmt.Errorf("failed to decrypt variables file: %w", err)
+		}
+	}
 	
 	var variables map[string]string
 	if err := json.Unmarshal(data, &variables); err != nil {
@@ -181,7 +185,7 @@ func (s *VariableExtractorService) extractValue(response *models.HTTPResponse, e
 func (s *VariableExtractorService) ExtractFromBody(response *models.HTTPResponse, extraction models.VariableExtraction) (string, error) {
 	// Check if a JSON path is specified
 	if extraction.Path != "" {
-		return s.extractFromJsonPath(response.Body, extraction.Path)
+		return s.extractFromJsonPath([]byte(response.Body), extraction.Path)
 	}
 	
 	// Check if a regular expression is specified
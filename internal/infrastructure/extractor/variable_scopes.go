@@ -0,0 +1,192 @@
+package extractor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/secrets"
+)
+
+const (
+	// VariablesFileName is the JSON sidecar file with variables for a directory
+	VariablesFileName = "variables.json"
+
+	// EnvFileName is the KEY=VALUE sidecar file with variables for a directory
+	EnvFileName = ".http-env"
+)
+
+// VariableScopeService resolves the variables that apply to an HTTP file from
+// sidecar files placed in its directory and the directories above it
+type VariableScopeService struct {
+	mu    sync.Mutex
+	cache map[string]map[string]string
+}
+
+// NewVariableScopeService creates a new VariableScopeService
+func NewVariableScopeService() *VariableScopeService {
+	return &VariableScopeService{
+		cache: make(map[string]map[string]string),
+	}
+}
+
+// ResolveVariables returns the sidecar variables for an HTTP file. Files in
+// nearer directories take precedence over files further up the tree. Encrypted
+// files are decrypted with the passphrase or key file from the options.
+func (s *VariableScopeService) ResolveVariables(ctx context.Context, filePath string, options models.TestRunOptions) (map[string]string, error) {
+	cipher, err := secrets.ResolveCipher(options.VarsPassphrase, options.VarsKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := filepath.Abs(filepath.Dir(filePath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve directory of %s: %w", filePath, err)
+	}
+
+	// Collect the directories from the file up to the root
+	var dirs []string
+	for {
+		dirs = append(dirs, dir)
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	// Apply the outermost scope first so nearer scopes override it
+	variables := make(map[string]string)
+	for i := len(dirs) - 1; i >= 0; i-- {
+		dirVars, err := s.dirVariables(dirs[i], cipher)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range dirVars {
+			variables[k] = v
+		}
+	}
+
+	return variables, nil
+}
+
+// dirVariables loads the sidecar files of a single directory, caching the result
+func (s *VariableScopeService) dirVariables(dir string, cipher *secrets.Cipher) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if vars, ok := s.cache[dir]; ok {
+		return vars, nil
+	}
+
+	// .http-env overrides variables.json in the same directory
+	vars := make(map[string]string)
+	for _, name := range []string{VariablesFileName, EnvFileName} {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+
+		fileVars, err := LoadVariableFile(path, cipher)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range fileVars {
+			vars[k] = v
+		}
+	}
+
+	s.cache[dir] = vars
+	return vars, nil
+}
+
+// LoadVariableFile loads a sidecar variables file, decrypting it with the cipher
+// if needed. JSON files contain a flat object; other files contain KEY=VALUE lines.
+func LoadVariableFile(path string, cipher *secrets.Cipher) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read variables file: %w", err)
+	}
+
+	data, err = secrets.DecryptIfNeeded(data, cipher)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load variables file %s: %w", path, err)
+	}
+
+	var vars map[string]string
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		vars, err = parseJSONVariables(data)
+	} else {
+		vars, err = parseEnvVariables(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse variables file %s: %w", path, err)
+	}
+
+	return vars, nil
+}
+
+// parseJSONVariables parses a flat JSON object, keeping non-string values as JSON
+func parseJSONVariables(data []byte) (map[string]string, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	return stringifyJSONValues(raw), nil
+}
+
+// stringifyJSONValues converts JSON values to strings, keeping non-string values as JSON
+func stringifyJSONValues(raw map[string]json.RawMessage) map[string]string {
+	vars := make(map[string]string, len(raw))
+	for k, v := range raw {
+		var s string
+		if err := json.Unmarshal(v, &s); err == nil {
+			vars[k] = s
+		} else {
+			vars[k] = string(bytes.TrimSpace(v))
+		}
+	}
+	return vars
+}
+
+// parseEnvVariables parses KEY=VALUE lines, ignoring blank lines and # comments
+func parseEnvVariables(data []byte) (map[string]string, error) {
+	vars := make(map[string]string)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", lineNum)
+		}
+
+		// Strip matching quotes around the value
+		value := strings.TrimSpace(parts[1])
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		vars[strings.TrimSpace(parts[0])] = value
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return vars, nil
+}
@@ -0,0 +1,98 @@
+package extractor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestResolveVariables_NestedDirectories(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, VariablesFileName), `{"baseUrl": "https://root.example.com", "token": "root", "retries": 3, "debug": false}`)
+	writeFile(t, filepath.Join(root, EnvFileName), "# Overrides variables.json of the same directory\ntoken=root-env\n")
+	writeFile(t, filepath.Join(root, "api", VariablesFileName), `{"baseUrl": "https://api.example.com", "tenant": "acme"}`)
+	writeFile(t, filepath.Join(root, "api", "users", EnvFileName), "export baseUrl=\"https://users.example.com\"\n")
+	writeFile(t, filepath.Join(root, "admin", VariablesFileName), `{"tenant": "admin"}`)
+
+	service := NewVariableScopeService()
+	ctx := context.Background()
+
+	tests := []struct {
+		file     string
+		expected map[string]string
+	}{
+		{
+			file: filepath.Join(root, "root.http"),
+			expected: map[string]string{
+				"baseUrl": "https://root.example.com", "token": "root-env", "retries": "3", "debug": "false",
+			},
+		},
+		{
+			file: filepath.Join(root, "api", "orders.http"),
+			expected: map[string]string{
+				"baseUrl": "https://api.example.com", "token": "root-env", "retries": "3", "debug": "false", "tenant": "acme",
+			},
+		},
+		{
+			file: filepath.Join(root, "api", "users", "users.http"),
+			expected: map[string]string{
+				"baseUrl": "https://users.example.com", "token": "root-env", "retries": "3", "debug": "false", "tenant": "acme",
+			},
+		},
+		{
+			file: filepath.Join(root, "admin", "admin.http"),
+			expected: map[string]string{
+				"baseUrl": "https://root.example.com", "token": "root-env", "retries": "3", "debug": "false", "tenant": "admin",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(filepath.Base(tt.file), func(t *testing.T) {
+			variables, err := service.ResolveVariables(ctx, tt.file, models.TestRunOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, variables)
+		})
+	}
+}
+
+func TestResolveVariables_EnvironmentPrecedence(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, EnvFileName), "token=file\n")
+	file := filepath.Join(root, "users.http")
+
+	scoped, err := NewVariableScopeService().ResolveVariables(context.Background(), file, models.TestRunOptions{})
+	require.NoError(t, err)
+
+	// As in the test runner, variable files override the environment and
+	// are overridden by --var
+	var set models.VariableSet
+	set.Set(models.VariableNamespaceEnv, map[string]string{"token": "env", "user": "env"})
+	set.Set(models.VariableNamespaceFile, scoped)
+	assert.Equal(t, map[string]string{
+		"token": "file", "user": "env",
+		"env.token": "env", "env.user": "env", "file.token": "file",
+	}, set.Variables())
+
+	set.Set(models.VariableNamespaceCLI, map[string]string{"token": "cli"})
+	assert.Equal(t, "cli", set.Bare()["token"])
+}
+
+func TestResolveVariables_InvalidFile(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "api", EnvFileName), "token\n")
+
+	_, err := NewVariableScopeService().ResolveVariables(context.Background(), filepath.Join(root, "api", "users.http"), models.TestRunOptions{})
+	assert.ErrorContains(t, err, "line 1: expected KEY=VALUE")
+}
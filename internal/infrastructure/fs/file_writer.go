@@ -2,12 +2,15 @@ package fs
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/edgardnogueira/swagger-to-http/internal/version"
 )
 
 // FileWriter implements the FileWriter interface
@@ -52,6 +55,18 @@ func (w *FileWriter) WriteCollection(ctx context.Context, collection *models.HTT
 		}
 	}
 
+	// List the requests for the tools selecting them
+	if collection.Manifest != nil {
+		data, err := json.MarshalIndent(collection.Manifest, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode collection manifest: %w", err)
+		}
+		manifestPath := filepath.Join(collection.RootDir, models.CollectionManifestFile)
+		if err := os.WriteFile(manifestPath, append(data, '\n'), 0644); err != nil {
+			return fmt.Errorf("failed to write collection manifest %s: %w", manifestPath, err)
+		}
+	}
+
 	return nil
 }
 
@@ -68,6 +83,31 @@ func (w *FileWriter) WriteFile(ctx context.Context, file *models.HTTPFile, dirPa
 	}
 	defer f.Close()
 
+	// Write the version stamp of the generating tool and spec
+	if file.ToolVersion != "" {
+		stamp := version.Stamp{Version: file.ToolVersion, SpecHash: file.SpecHash}
+		if _, err := f.WriteString(fmt.Sprintf("# %s\n\n", stamp)); err != nil {
+			return fmt.Errorf("failed to write version stamp to file %s: %w", filePath, err)
+		}
+	}
+
+	// List the requests of this tag that are defined in the shared file
+	if len(file.SharedRequests) > 0 {
+		if _, err := f.WriteString(fmt.Sprintf("# Shared requests defined in %s:\n", file.SharedFile)); err != nil {
+			return fmt.Errorf("failed to write shared requests to file %s: %w", filePath, err)
+		}
+		for _, name := range file.SharedRequests {
+			if _, err := f.WriteString(fmt.Sprintf("#   %s\n", name)); err != nil {
+				return fmt.Errorf("failed to write shared requests to file %s: %w", filePath, err)
+			}
+		}
+		if len(file.Requests) > 0 {
+			if _, err := f.WriteString("\n"); err != nil {
+				return fmt.Errorf("failed to write shared requests to file %s: %w", filePath, err)
+			}
+		}
+	}
+
 	for i, request := range file.Requests {
 		if i > 0 {
 			// Add a separator between requests
@@ -103,14 +143,150 @@ func (w *FileWriter) writeRequest(f *os.File, request *models.HTTPRequest) error
 		}
 	}
 
+	// OAuth scopes the operation requires, for running the requests of a role
+	if len(request.Scopes) > 0 {
+		if _, err := f.WriteString(fmt.Sprintf("# @scopes %s\n", strings.Join(request.Scopes, " "))); err != nil {
+			return err
+		}
+	}
+
+	// Mark deprecated operations so the test runner can report them
+	if request.Deprecated {
+		if _, err := f.WriteString("# @deprecated\n"); err != nil {
+			return err
+		}
+	}
+
+	// Requests that may change data on shared environments
+	if request.Safe {
+		if _, err := f.WriteString("# @safe\n"); err != nil {
+			return err
+		}
+	}
+
+	// Requests that must not run at the same time as the others of their group
+	if request.SerialGroup != "" {
+		if _, err := f.WriteString(fmt.Sprintf("# @serial-group %s\n", request.SerialGroup)); err != nil {
+			return err
+		}
+	}
+
+	// Skipped and focused requests
+	if request.Skip {
+		if _, err := f.WriteString(strings.TrimSpace("# @skip "+request.SkipReason) + "\n"); err != nil {
+			return err
+		}
+	}
+	if request.Only {
+		if _, err := f.WriteString("# @only\n"); err != nil {
+			return err
+		}
+	}
+
+	// Known failures that don't fail the build
+	if request.Quarantined {
+		if _, err := f.WriteString(strings.TrimSpace("# @quarantine "+request.QuarantineReason) + "\n"); err != nil {
+			return err
+		}
+	}
+
+	// Tests run first with --order priority
+	if request.Priority > 0 {
+		if _, err := f.WriteString(fmt.Sprintf("# @priority %d\n", request.Priority)); err != nil {
+			return err
+		}
+	}
+
+	// Throttle the operation when testing, from the spec's x-rate-limit
+	if request.RateLimit != nil {
+		if _, err := f.WriteString(fmt.Sprintf("# @rate-limit %s\n", request.RateLimit)); err != nil {
+			return err
+		}
+	}
+
+	// Callbacks the test runner listens for after sending the request
+	for _, callback := range request.Callbacks {
+		if _, err := f.WriteString(fmt.Sprintf("# @callback %s\n", callback)); err != nil {
+			return err
+		}
+	}
+
+	// Polling of the asynchronous operation started by the request
+	if request.Poll != nil {
+		if _, err := f.WriteString(strings.TrimSpace(fmt.Sprintf("# @poll %s", request.Poll)) + "\n"); err != nil {
+			return err
+		}
+	}
+
+	// Redirects the executor follows
+	if request.Redirects != nil {
+		if _, err := f.WriteString(fmt.Sprintf("# @redirects %s\n", request.Redirects)); err != nil {
+			return err
+		}
+	}
+
+	// Pagination the test runner follows to fetch every page
+	if request.Paginate != nil {
+		if _, err := f.WriteString(fmt.Sprintf("# @paginate %s\n", request.Paginate)); err != nil {
+			return err
+		}
+	}
+
+	// Status the test runner expects, independently of snapshots
+	if request.ExpectStatus != "" {
+		if _, err := f.WriteString(fmt.Sprintf("# @expect-status %s\n", request.ExpectStatus)); err != nil {
+			return err
+		}
+	}
+
+	// Schema the test runner validates the XML response against
+	if request.XSD != "" {
+		if _, err := f.WriteString(fmt.Sprintf("# @xsd %s\n", request.XSD)); err != nil {
+			return err
+		}
+	}
+
+	// Content encoding the test runner expects the server to use
+	if request.ExpectEncoding != "" {
+		if _, err := f.WriteString(fmt.Sprintf("# @expect-encoding %s\n", request.ExpectEncoding)); err != nil {
+			return err
+		}
+	}
+
+	// Headers the test runner expects, possibly as patterns
+	for _, header := range request.ExpectHeaders {
+		if _, err := f.WriteString(fmt.Sprintf("# @expect-header %s\n", header)); err != nil {
+			return err
+		}
+	}
+
+	// Parts of the response compared with its snapshot
+	if request.SnapshotCompare != models.SnapshotScopeAll {
+		if _, err := f.WriteString(fmt.Sprintf("# @snapshot-compare %s\n", request.SnapshotCompare)); err != nil {
+			return err
+		}
+	}
+
+	// Headers left out of the comparison of the response, or compared again
+	if len(request.IgnoreHeaders) > 0 {
+		if _, err := f.WriteString(fmt.Sprintf("# @ignore-headers %s\n", strings.Join(request.IgnoreHeaders, ", "))); err != nil {
+			return err
+		}
+	}
+
 	// Write request line
 	if _, err := f.WriteString(fmt.Sprintf("%s %s\n", request.Method, request.URL)); err != nil {
 		return err
 	}
 
-	// Write headers
-	for _, header := range request.Headers {
-		if _, err := f.WriteString(fmt.Sprintf("%s: %s\n", header.Name, header.Value)); err != nil {
+	// Write headers, sorted by name
+	names := make([]string, 0, len(request.Headers))
+	for name := range request.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, err := f.WriteString(fmt.Sprintf("%s: %s\n", name, request.Headers[name])); err != nil {
 			return err
 		}
 	}
@@ -128,5 +304,17 @@ func (w *FileWriter) writeRequest(f *os.File, request *models.HTTPRequest) error
 		}
 	}
 
+	// Files the response is saved to, after the body
+	if len(request.Outputs) > 0 {
+		if _, err := f.WriteString("\n"); err != nil {
+			return err
+		}
+		for _, output := range request.Outputs {
+			if _, err := f.WriteString(output.String() + "\n"); err != nil {
+				return err
+			}
+		}
+	}
+
 	return nil
 }
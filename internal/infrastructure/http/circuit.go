@@ -0,0 +1,78 @@
+package http
+
+import (
+	"sync"
+	"time"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// circuitBreaker fails requests fast to hosts that keep failing with
+// connection errors, so a host that went down doesn't cost every remaining
+// request of a run its timeout
+type circuitBreaker struct {
+	options models.CircuitBreakerOptions
+	now     func() time.Time
+
+	mu    sync.Mutex
+	hosts map[string]*circuit
+}
+
+// circuit is the state of the breaker for one host
+type circuit struct {
+	failures  int       // Consecutive connection errors
+	openUntil time.Time // End of the cool-down of an open circuit
+}
+
+// newCircuitBreaker creates a breaker with all circuits closed
+func newCircuitBreaker(options models.CircuitBreakerOptions) *circuitBreaker {
+	return &circuitBreaker{options: options, now: time.Now, hosts: make(map[string]*circuit)}
+}
+
+// allow returns a CircuitOpenError when the circuit of a host is open. Once
+// the cool-down has passed, a single request is let through to probe the
+// host and the circuit stays open for the others until it succeeds.
+func (b *circuitBreaker) allow(host string) error {
+	if b.options.Threshold <= 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state := b.hosts[host]
+	if state == nil || state.failures < b.options.Threshold {
+		return nil
+	}
+	now := b.now()
+	if now.Before(state.openUntil) {
+		return &models.CircuitOpenError{Host: host, Failures: state.failures, Until: state.openUntil}
+	}
+	state.openUntil = now.Add(b.options.CoolDown)
+	return nil
+}
+
+// record counts the outcome of a request to a host: a connection error
+// opens the circuit once the threshold is reached, anything else closes it
+func (b *circuitBreaker) record(host string, connectionErr bool) {
+	if b.options.Threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !connectionErr {
+		delete(b.hosts, host)
+		return
+	}
+	state := b.hosts[host]
+	if state == nil {
+		state = &circuit{}
+		b.hosts[host] = state
+	}
+	state.failures++
+	if state.failures == b.options.Threshold {
+		state.openUntil = b.now().Add(b.options.CoolDown)
+	}
+}
@@ -0,0 +1,50 @@
+package http
+
+import (
+	"testing"
+	"time"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	breaker := newCircuitBreaker(models.CircuitBreakerOptions{Threshold: 2, CoolDown: time.Minute})
+	breaker.now = func() time.Time { return now }
+
+	// Errors below the threshold, or interrupted by a success, keep it closed
+	breaker.record("api.example.com", true)
+	breaker.record("api.example.com", false)
+	breaker.record("api.example.com", true)
+	assert.NoError(t, breaker.allow("api.example.com"))
+
+	breaker.record("api.example.com", true)
+	err := breaker.allow("api.example.com")
+	var circuitErr *models.CircuitOpenError
+	require.ErrorAs(t, err, &circuitErr)
+	assert.Equal(t, &models.CircuitOpenError{Host: "api.example.com", Failures: 2, Until: now.Add(time.Minute)}, circuitErr)
+	assert.NoError(t, breaker.allow("other.example.com"))
+
+	// After the cool-down one request probes the host
+	now = now.Add(time.Minute)
+	assert.NoError(t, breaker.allow("api.example.com"))
+	assert.Error(t, breaker.allow("api.example.com"))
+
+	// A failed probe keeps it open, a successful one closes it
+	breaker.record("api.example.com", true)
+	now = now.Add(time.Minute)
+	assert.NoError(t, breaker.allow("api.example.com"))
+	breaker.record("api.example.com", false)
+	assert.NoError(t, breaker.allow("api.example.com"))
+	assert.NoError(t, breaker.allow("api.example.com"))
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	breaker := newCircuitBreaker(models.CircuitBreakerOptions{})
+	for i := 0; i < 10; i++ {
+		breaker.record("api.example.com", true)
+	}
+	assert.NoError(t, breaker.allow("api.example.com"))
+}
@@ -0,0 +1,69 @@
+package http
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// errUnsupportedEncoding is returned for content encodings the executor can't decode
+var errUnsupportedEncoding = errors.New("unsupported content encoding")
+
+// decodeBody decodes a response body sent with a content encoding, when
+// decompression is enabled, and returns it with the encoding the server used.
+// Decoded responses lose their Content-Encoding and Content-Length headers, as
+// with Go's transparent decompression. Bodies in encodings that can't be
+// decoded, such as br, are kept as received.
+func (e *Executor) decodeBody(header http.Header, body []byte, limit int64) ([]byte, string, error) {
+	encoding := strings.ToLower(strings.TrimSpace(header.Get("Content-Encoding")))
+	if encoding == "" || encoding == models.EncodingIdentity || !e.compression.Decompress {
+		return body, encoding, nil
+	}
+
+	decoded, err := decompress(encoding, body, limit)
+	if errors.Is(err, errUnsupportedEncoding) {
+		return body, encoding, nil
+	}
+	if err != nil {
+		return nil, encoding, fmt.Errorf("failed to decompress %s response body: %w", encoding, err)
+	}
+
+	header.Del("Content-Encoding")
+	header.Del("Content-Length")
+	return decoded, encoding, nil
+}
+
+// decompress decodes a body in a content encoding, stopping once it is
+// larger than limit bytes unless limit is 0
+func decompress(encoding string, body []byte, limit int64) ([]byte, error) {
+	var reader io.ReadCloser
+	switch encoding {
+	case models.EncodingGzip, "x-gzip":
+		gzipReader, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		reader = gzipReader
+	case models.EncodingDeflate:
+		// "deflate" should be zlib-wrapped, but some servers send raw deflate
+		zlibReader, err := zlib.NewReader(bytes.NewReader(body))
+		if err != nil {
+			reader = flate.NewReader(bytes.NewReader(body))
+		} else {
+			reader = zlibReader
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", errUnsupportedEncoding, encoding)
+	}
+	defer reader.Close()
+
+	return readBody(reader, limit)
+}
@@ -3,12 +3,16 @@ package http
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/edgardnogueira/swagger-to-http/internal/application/bodytemplate"
 	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
 )
 
@@ -16,18 +20,105 @@ import (
 type Executor struct {
 	client      *http.Client
 	environment map[string]string
+	poll        models.PollOptions
+	compression models.CompressionOptions
+	redirects   models.RedirectPolicy
+	timeouts    models.Timeouts
+	retries     models.RetryOptions
+	breaker     *circuitBreaker
+	methods     []string
+	maxBody     int64
+
+	grpcDescriptors *grpcDescriptors
 }
 
-// NewExecutor creates a new HTTP executor with the given options
-func NewExecutor(timeout time.Duration, environment map[string]string) *Executor {
-	client := &http.Client{
-		Timeout: timeout,
-	}
+// ExecutorOption configures an Executor
+type ExecutorOption interface {
+	applyExecutor(*Executor)
+}
+
+// executorOption is an ExecutorOption setting fields of the executor
+type executorOption func(*Executor)
 
-	return &Executor{
-		client:      client,
+func (o executorOption) applyExecutor(e *Executor) { o(e) }
+
+// WithPolling sets how 202 Accepted responses of asynchronous operations are
+// polled, unless a request sets its own options
+func WithPolling(options models.PollOptions) ExecutorOption {
+	return executorOption(func(e *Executor) {
+		e.poll = options
+	})
+}
+
+// WithCompression sets the Accept-Encoding sent by requests without one and
+// whether compressed bodies are decoded
+func WithCompression(options models.CompressionOptions) ExecutorOption {
+	return executorOption(func(e *Executor) {
+		e.compression = options
+	})
+}
+
+// WithRedirects sets whether and how far redirects are followed, unless a
+// request sets its own policy
+func WithRedirects(policy models.RedirectPolicy) ExecutorOption {
+	return executorOption(func(e *Executor) {
+		e.redirects = policy
+	})
+}
+
+// WithTimeouts sets the connect, TLS handshake and response header timeouts
+// of requests, and their overall timeout unless it is zero
+func WithTimeouts(timeouts models.Timeouts) ExecutorOption {
+	return executorOption(func(e *Executor) {
+		if timeouts.Request <= 0 {
+			timeouts.Request = e.timeouts.Request
+		}
+		e.timeouts = timeouts
+	})
+}
+
+// WithRetries sets how idempotent requests answered with a transient error
+// status are retried, unless a request sets its own options
+func WithRetries(options models.RetryOptions) ExecutorOption {
+	return executorOption(func(e *Executor) {
+		e.retries = options
+	})
+}
+
+// WithCircuitBreaker sets after how many consecutive connection errors the
+// requests to a host fail fast, and for how long
+func WithCircuitBreaker(options models.CircuitBreakerOptions) ExecutorOption {
+	return executorOption(func(e *Executor) {
+		e.breaker = newCircuitBreaker(options)
+	})
+}
+
+// WithMaxBodyBytes sets the largest response body read, 0 for no limit,
+// unless a request sets its own
+func WithMaxBodyBytes(limit int64) ExecutorOption {
+	return executorOption(func(e *Executor) {
+		e.maxBody = limit
+	})
+}
+
+// NewExecutor creates a new HTTP executor whose requests time out after the
+// given duration, with the given options
+func NewExecutor(timeout time.Duration, environment map[string]string, opts ...ExecutorOption) *Executor {
+	executor := &Executor{
 		environment: environment,
+		poll:        models.DefaultPollOptions(),
+		compression: models.DefaultCompressionOptions(),
+		redirects:   models.DefaultRedirectPolicy(),
+		timeouts:    models.Timeouts{Request: timeout},
+		retries:     models.DefaultRetryOptions(),
+		breaker:     newCircuitBreaker(models.DefaultCircuitBreakerOptions()),
+	}
+	for _, opt := range opts {
+		opt.applyExecutor(executor)
 	}
+	executor.client = newClient(executor.timeouts)
+
+	return executor
 }
 
 // Execute executes an HTTP request and returns the response
@@ -35,19 +126,86 @@ func (e *Executor) Execute(ctx context.Context, request *models.HTTPRequest, var
 	// Process variables - combine environment variables with request variables
 	vars := e.combineVariables(variables)
 
-	// Process request parts with variable substitution
-	url := e.processVariables(request.URL, vars)
-	body := e.processVariables(request.Body, vars)
+	// Don't send the {{placeholders}} of variables without a value as
+	// written when the variables of the run are strict
+	if err := models.CheckVariables(ctx, request, vars); err != nil {
+		return nil, err
+	}
+
+	// Process request parts with variable substitution, escaping the values in
+	// the URL, and resolve relative URLs against the host of the file
+	url := models.ResolveURL(models.ExpandURL(request.URL, vars), e.processVariables(request.Host, vars))
+
+	// Structured query parameters replace the query of the URL, so the values
+	// of their variables are escaped
+	if len(request.QueryParams) > 0 {
+		params := make(map[string][]string, len(request.QueryParams))
+		for name, values := range request.QueryParams {
+			for _, value := range values {
+				params[name] = append(params[name], e.processVariables(value, vars))
+			}
+		}
+		url = models.EncodeQuery(url, params)
+	}
+	// Native gRPC calls go to grpc:// and grpcs:// URLs rather than a gateway
+	nativeGRPC := strings.EqualFold(request.Method, models.MethodGRPC) && models.IsNativeGRPCURL(url)
+	if !nativeGRPC {
+		if err := models.ValidateURL(url); err != nil {
+			return nil, err
+		}
+	}
+	body, err := bodytemplate.Render(request.Body, vars)
+	if err != nil {
+		return nil, err
+	}
+	body = e.processVariables(body, vars)
+	body, err = models.ExpandBodyIncludes(body, request.Path, func(text string) (string, error) {
+		text, err := bodytemplate.Render(text, vars)
+		if err != nil {
+			return "", err
+		}
+		return e.processVariables(text, vars), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Requests without a method are sent as GET, as net/http does
+	if request.Method != "" && !models.IsAllowedMethod(request.Method, e.methods) {
+		return nil, fmt.Errorf("unsupported HTTP method %q, add it to executor.extra_methods to allow it", request.Method)
+	}
+
+	if nativeGRPC {
+		return e.executeGRPC(ctx, request, url, body, vars)
+	}
+
+	// Other gRPC calls are posted as JSON to a transcoding gateway
+	method := request.Method
+	grpc := strings.EqualFold(method, models.MethodGRPC)
+	if grpc {
+		if _, _, err := models.ParseGRPCTarget(url); err != nil {
+			return nil, err
+		}
+		method = http.MethodPost
+	}
 
 	// Create the HTTP request
-	req, err := http.NewRequestWithContext(ctx, request.Method, url, bytes.NewBufferString(body))
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewBufferString(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
 	}
 
 	// Add headers
-	for _, header := range request.Headers {
-		req.Header.Add(header.Name, e.processVariables(header.Value, vars))
+	for name, value := range request.Headers {
+		req.Header.Add(name, e.processVariables(value, vars))
+	}
+
+	if e.compression.AcceptEncoding != "" && req.Header.Get("Accept-Encoding") == "" {
+		req.Header.Set("Accept-Encoding", e.compression.AcceptEncoding)
+	}
+
+	if grpc && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
 	}
 
 	// For form submissions, ensure the right content-type if not explicitly set
@@ -57,43 +215,247 @@ func (e *Executor) Execute(ctx context.Context, request *models.HTTPRequest, var
 		}
 	}
 
+	// Follow redirects as the request or the executor says, recording each one
+	policy := e.redirects
+	if request.Redirects != nil {
+		policy = *request.Redirects
+	}
+	var hops []models.RedirectHop
+	client := *e.client
+	client.CheckRedirect = checkRedirect(policy, &hops)
+
+	// Follow the phases of the request to tell which one timed out
+	tracker := newPhaseTracker()
+	req = tracker.withTrace(req)
+
+	// Retry as the request or the executor says
+	retries := e.retries
+	if request.Retries != nil {
+		retries = request.Retries.Merge(e.retries)
+	}
+
+	// Fail fast while the host keeps refusing connections
+	if err := e.breaker.allow(req.URL.Host); err != nil {
+		return nil, err
+	}
+
 	// Execute the request
 	startTime := time.Now()
-	resp, err := e.client.Do(req)
+	resp, retried, err := e.do(ctx, &client, req, tracker, retries)
 	duration := time.Since(startTime)
+	e.breaker.record(req.URL.Host, err != nil && ctx.Err() == nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute HTTP request: %w", err)
+		return nil, fmt.Errorf("failed to execute HTTP request: %w", e.timeoutError(ctx, err, tracker, duration))
 	}
 	defer resp.Body.Close()
 
-	// Read the response body
-	respBody, err := io.ReadAll(resp.Body)
+	// Read the response body, up to the limit
+	maxBody := e.maxBody
+	if request.MaxBodyBytes > 0 {
+		maxBody = request.MaxBodyBytes
+	}
+	respBody, err := readBody(resp.Body, maxBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, fmt.Errorf("failed to read response body: %w", e.timeoutError(ctx, err, tracker, time.Since(startTime)))
+	}
+	respBody, encoding, err := e.decodeBody(resp.Header, respBody, maxBody)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create the response object
 	response := &models.HTTPResponse{
-		StatusCode:    resp.StatusCode,
-		Status:        resp.Status,
-		Headers:       make(map[string][]string),
-		Body:          respBody,
-		ContentType:   resp.Header.Get("Content-Type"),
-		ContentLength: resp.ContentLength,
-		Duration:      duration,
-		Request:       request,
-		RequestID:     fmt.Sprintf("%s-%s", request.Method, request.Path),
-		Timestamp:     time.Now(),
+		StatusCode:      resp.StatusCode,
+		Status:          resp.Status,
+		Headers:         make(map[string][]string),
+		Body:            string(respBody),
+		ContentType:     resp.Header.Get("Content-Type"),
+		ContentLength:   resp.ContentLength,
+		Duration:        duration,
+		ContentEncoding: encoding,
+		URL:             resp.Request.URL.String(),
+		Redirects:       hops,
+		Retries:         retried,
+		ResolvedRequest: models.NewResolvedRequest(req.Method, req.URL.String(), req.Header, body),
+		Request:         request,
+		RequestID:       fmt.Sprintf("%s-%s", request.Method, request.Path),
+		Timestamp:       time.Now(),
 	}
 
 	// Copy headers
 	for name, values := range resp.Header {
 		response.Headers[name] = values
 	}
+	if grpc {
+		setGRPCStatus(response, resp.Trailer)
+	}
+
+	// Follow asynchronous operations until they complete
+	poll := e.poll
+	if request.Poll != nil {
+		poll = request.Poll.Merge(e.poll)
+	}
+	if poll.Enabled && response.StatusCode == http.StatusAccepted {
+		if statusURL := operationLocation(req.URL, resp.Header); statusURL != "" {
+			return e.pollOperation(ctx, request, req.Header, response, statusURL, poll)
+		}
+	}
 
 	return response, nil
 }
 
+// readBody reads a response body, failing once it is larger than limit
+// bytes rather than holding it all, unless limit is 0. Decompressed bodies
+// are held to the same limit.
+func readBody(body io.Reader, limit int64) ([]byte, error) {
+	if limit <= 0 {
+		return io.ReadAll(body)
+	}
+	data, err := io.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("response body is larger than the limit of %s, see --max-body-bytes", models.FormatByteSize(limit))
+	}
+	return data, nil
+}
+
+// pollOperation polls the status URL of an asynchronous operation until it
+// completes and returns the final response, which keeps the initial one
+func (e *Executor) pollOperation(ctx context.Context, request *models.HTTPRequest, headers http.Header, initial *models.HTTPResponse, statusURL string, options models.PollOptions) (*models.HTTPResponse, error) {
+	deadline := time.Now().Add(options.Timeout)
+	wait := retryAfter(initial.Headers, options.Interval)
+
+	for attempt := 1; ; attempt++ {
+		if time.Now().Add(wait).After(deadline) {
+			return nil, fmt.Errorf("asynchronous operation did not complete within %s after %d polls of %s", options.Timeout, attempt-1, statusURL)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("failed to poll %s: %w", statusURL, ctx.Err())
+		case <-timer.C:
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, statusURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create poll request: %w", err)
+		}
+		// Keep authentication and the like, but not the headers of the original body
+		req.Header = headers.Clone()
+		req.Header.Del("Content-Type")
+		req.Header.Del("Content-Length")
+
+		startTime := time.Now()
+		resp, err := e.client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to poll %s: %w", statusURL, err)
+		}
+		maxBody := e.maxBody
+		if request.MaxBodyBytes > 0 {
+			maxBody = request.MaxBodyBytes
+		}
+		respBody, err := readBody(resp.Body, maxBody)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read poll response body: %w", err)
+		}
+		respBody, encoding, err := e.decodeBody(resp.Header, respBody, maxBody)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode == http.StatusAccepted || operationPending(respBody) {
+			wait = retryAfter(resp.Header, options.Interval)
+			if next := operationLocation(req.URL, resp.Header); next != "" {
+				statusURL = next
+			}
+			continue
+		}
+
+		response := &models.HTTPResponse{
+			StatusCode:      resp.StatusCode,
+			Status:          resp.Status,
+			Headers:         make(map[string][]string),
+			Body:            string(respBody),
+			ContentType:     resp.Header.Get("Content-Type"),
+			ContentLength:   resp.ContentLength,
+			ContentEncoding: encoding,
+			Duration:        initial.Duration + time.Since(startTime),
+			Request:         request,
+			RequestID:       initial.RequestID,
+			Timestamp:       time.Now(),
+			InitialResponse: initial,
+			PollAttempts:    attempt,
+			ResolvedRequest: initial.ResolvedRequest,
+		}
+		for name, values := range resp.Header {
+			response.Headers[name] = values
+		}
+		return response, nil
+	}
+}
+
+// operationLocation returns the status URL of an asynchronous operation, from
+// the Operation-Location or Location header, resolved against the request URL
+func operationLocation(requestURL *url.URL, headers http.Header) string {
+	location := headers.Get("Operation-Location")
+	if location == "" {
+		location = headers.Get("Location")
+	}
+	if location == "" {
+		return ""
+	}
+	resolved, err := requestURL.Parse(location)
+	if err != nil {
+		return ""
+	}
+	return resolved.String()
+}
+
+// retryAfter returns the delay a Retry-After header, given in seconds or as
+// an HTTP date, asks for, or the fallback
+func retryAfter(headers map[string][]string, fallback time.Duration) time.Duration {
+	value := strings.TrimSpace(http.Header(headers).Get("Retry-After"))
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(date); wait > 0 {
+			return wait
+		}
+		return 0
+	}
+	return fallback
+}
+
+// pendingStatuses are the values of the "status" field of an operation status
+// body for operations that are still running
+var pendingStatuses = map[string]bool{
+	"accepted":   true,
+	"notstarted": true,
+	"pending":    true,
+	"queued":     true,
+	"running":    true,
+	"inprogress": true,
+}
+
+// operationPending reports whether an operation status body says the
+// operation is still running, e.g. {"status": "Running"}
+func operationPending(body []byte) bool {
+	var status struct {
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(body, &status); err != nil {
+		return false
+	}
+	normalized := strings.ToLower(strings.NewReplacer("_", "", "-", "", " ", "").Replace(status.Status))
+	return pendingStatuses[normalized]
+}
+
 // ExecuteFile executes all requests in an HTTP file
 func (e *Executor) ExecuteFile(ctx context.Context, file *models.HTTPFile, variables map[string]string) ([]*models.HTTPResponse, error) {
 	responses := make([]*models.HTTPResponse, 0, len(file.Requests))
@@ -111,41 +473,26 @@ func (e *Executor) ExecuteFile(ctx context.Context, file *models.HTTPFile, varia
 
 // processVariables replaces variable references in the given text with their values
 func (e *Executor) processVariables(text string, variables map[string]string) string {
-	result := text
-
-	// Replace {{variable}} references
-	for name, value := range variables {
-		result = strings.ReplaceAll(result, "{{"+name+"}}", value)
-	}
-
-	return result
+	return models.ReplaceVariables(text, variables)
 }
 
-// combineVariables merges environment variables with request-specific variables
+// combineVariables merges the environment of the executor, also referenced as
+// {{env.name}}, with the variables of the request, already resolved by their
+// precedence, which override it
 func (e *Executor) combineVariables(requestVars map[string]string) map[string]string {
-	// Create a new map to avoid modifying the environment
-	vars := make(map[string]string)
-
-	// Copy environment variables
-	for k, v := range e.environment {
+	var set models.VariableSet
+	set.Set(models.VariableNamespaceEnv, e.environment)
+	vars := set.Variables()
+	for k, v := range requestVars {
 		vars[k] = v
 	}
-
-	// Add/override with request variables
-	if requestVars != nil {
-		for k, v := range requestVars {
-			vars[k] = v
-		}
-	}
-
 	return vars
 }
 
-// hasHeader checks if a specific header exists in the headers slice
-func hasHeader(headers []models.HTTPHeader, name string) bool {
-	lowerName := strings.ToLower(name)
-	for _, h := range headers {
-		if strings.ToLower(h.Name) == lowerName {
+// hasHeader checks if a specific header exists in the headers, whatever its case
+func hasHeader(headers map[string]string, name string) bool {
+	for header := range headers {
+		if strings.EqualFold(header, name) {
 			return true
 		}
 	}
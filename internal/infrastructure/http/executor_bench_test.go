@@ -0,0 +1,27 @@
+package http
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func BenchmarkExecutor_ProcessVariables(b *testing.B) {
+	variables := make(map[string]string)
+	var body strings.Builder
+	body.WriteString("{\n")
+	for i := 0; i < 50; i++ {
+		variables[fmt.Sprintf("field%d", i)] = fmt.Sprintf("value %d", i)
+		fmt.Fprintf(&body, "  \"field%d\": \"{{field%d}}\",\n", i, i)
+	}
+	body.WriteString("  \"missing\": \"{{missing}}\"\n}")
+	text := body.String()
+	executor := NewExecutor(time.Second, nil)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		executor.processVariables(text, variables)
+	}
+}
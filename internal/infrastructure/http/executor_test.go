@@ -1,9 +1,15 @@
 package http
 
 import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
 	"context"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -37,9 +43,9 @@ func TestExecutor_Execute(t *testing.T) {
 	request := &models.HTTPRequest{
 		Method: "GET",
 		URL:    "{{BASE_URL}}/api/test",
-		Headers: []models.HTTPHeader{
-			{Name: "Accept", Value: "application/json"},
-			{Name: "X-Custom-Header", Value: "{{HEADER_VALUE}}"},
+		Headers: map[string]string{
+			"Accept":          "application/json",
+			"X-Custom-Header": "{{HEADER_VALUE}}",
 		},
 		Path: "/api/test",
 	}
@@ -90,8 +96,8 @@ func TestExecutor_ExecuteWithBody(t *testing.T) {
 	request := &models.HTTPRequest{
 		Method: "POST",
 		URL:    "{{BASE_URL}}/api/create",
-		Headers: []models.HTTPHeader{
-			{Name: "Content-Type", Value: "application/json"},
+		Headers: map[string]string{
+			"Content-Type": "application/json",
 		},
 		Body: `{"name":"{{NAME_VALUE}}"}`,
 		Path: "/api/create",
@@ -143,16 +149,16 @@ func TestExecutor_ExecuteFile(t *testing.T) {
 			{
 				Method: "GET",
 				URL:    "{{BASE_URL}}/api/test1",
-				Headers: []models.HTTPHeader{
-					{Name: "Accept", Value: "application/json"},
+				Headers: map[string]string{
+					"Accept": "application/json",
 				},
 				Path: "/api/test1",
 			},
 			{
 				Method: "GET",
 				URL:    "{{BASE_URL}}/api/test2",
-				Headers: []models.HTTPHeader{
-					{Name: "Accept", Value: "application/json"},
+				Headers: map[string]string{
+					"Accept": "application/json",
 				},
 				Path: "/api/test2",
 			},
@@ -217,3 +223,440 @@ func TestExecutor_ProcessVariables(t *testing.T) {
 		})
 	}
 }
+
+func TestExecutor_ExecutePollsAsyncOperation(t *testing.T) {
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/jobs":
+			assert.Equal(t, "POST", r.Method)
+			w.Header().Set("Location", "/api/jobs/1/status")
+			w.WriteHeader(http.StatusAccepted)
+		case "/api/jobs/1/status":
+			assert.Equal(t, "GET", r.Method)
+			assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+			polls++
+			w.Header().Set("Content-Type", "application/json")
+			if polls < 2 {
+				w.Write([]byte(`{"status":"Running"}`))
+				return
+			}
+			w.Write([]byte(`{"status":"Succeeded","id":1}`))
+		}
+	}))
+	defer server.Close()
+
+	executor := NewExecutor(10*time.Second, nil, WithPolling(models.PollOptions{
+		Enabled:  true,
+		Interval: time.Millisecond,
+		Timeout:  5 * time.Second,
+	}))
+
+	request := &models.HTTPRequest{
+		Method:  "POST",
+		URL:     server.URL + "/api/jobs",
+		Headers: map[string]string{"Authorization": "Bearer token"},
+		Body:    `{"name":"job"}`,
+	}
+
+	response, err := executor.Execute(context.Background(), request, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, `{"status":"Succeeded","id":1}`, string(response.Body))
+	assert.Equal(t, 2, response.PollAttempts)
+	if assert.NotNil(t, response.InitialResponse) {
+		assert.Equal(t, http.StatusAccepted, response.InitialResponse.StatusCode)
+	}
+
+	// Polling can be turned off per request
+	request.Poll = &models.PollOptions{}
+	response, err = executor.Execute(context.Background(), request, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, response.StatusCode)
+	assert.Nil(t, response.InitialResponse)
+}
+
+func TestExecutor_ExecutePollTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Operation-Location", "/operations/1")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	executor := NewExecutor(10*time.Second, nil, WithPolling(models.PollOptions{
+		Enabled:  true,
+		Interval: 10 * time.Millisecond,
+		Timeout:  50 * time.Millisecond,
+	}))
+
+	_, err := executor.Execute(context.Background(), &models.HTTPRequest{Method: "GET", URL: server.URL + "/reports"}, nil)
+	assert.ErrorContains(t, err, "did not complete within 50ms")
+}
+
+func TestExecutor_ExecuteExtraMethods(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PURGE", r.Method)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	request := &models.HTTPRequest{Method: "PURGE", URL: server.URL + "/cache/users"}
+
+	_, err := NewExecutor(10*time.Second, nil).Execute(context.Background(), request, nil)
+	assert.ErrorContains(t, err, `unsupported HTTP method "PURGE"`)
+
+	response, err := NewExecutor(10*time.Second, nil, WithExtraMethods([]string{"PURGE"})).Execute(context.Background(), request, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+}
+
+func TestExecutor_ExecuteQueryParams(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "a&b c", r.URL.Query().Get("q"))
+		assert.Equal(t, []string{"1", "2"}, r.URL.Query()["id"])
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	request := &models.HTTPRequest{
+		Method:      "GET",
+		URL:         server.URL + "/search?q={{term}}&id=1&id=2",
+		QueryParams: models.ParseQueryParams("/search?q={{term}}&id=1&id=2"),
+	}
+	_, err := NewExecutor(10*time.Second, nil).Execute(context.Background(), request, map[string]string{"term": "a&b c"})
+	assert.NoError(t, err)
+}
+
+func TestExecutor_ExecuteGRPC(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		switch r.URL.Path {
+		case "/acme.users.v1.UserService/GetUser":
+			w.Header().Set("Trailer", "Grpc-Status")
+			w.Write([]byte(`{"id":"42","name":"Ada"}`))
+			w.Header().Set("Grpc-Status", "0")
+		case "/acme.users.v1.UserService/DeleteUser":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":"not_found","message":"user 7 not found"}`))
+		}
+	}))
+	defer server.Close()
+
+	executor := NewExecutor(10*time.Second, nil)
+
+	response, err := executor.Execute(context.Background(), &models.HTTPRequest{
+		Method: models.MethodGRPC,
+		URL:    server.URL + "/acme.users.v1.UserService/GetUser",
+		Body:   `{"id":"42"}`,
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, []string{"0"}, response.Headers[models.GRPCStatusHeader])
+	assert.Equal(t, `{"id":"42","name":"Ada"}`, string(response.Body))
+
+	response, err = executor.Execute(context.Background(), &models.HTTPRequest{
+		Method: models.MethodGRPC,
+		URL:    server.URL + "/acme.users.v1.UserService/DeleteUser",
+		Body:   `{"id":"7"}`,
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"5"}, response.Headers[models.GRPCStatusHeader])
+	assert.Equal(t, []string{"user 7 not found"}, response.Headers["Grpc-Message"])
+
+	_, err = executor.Execute(context.Background(), &models.HTTPRequest{
+		Method: models.MethodGRPC,
+		URL:    server.URL + "/users/7",
+	}, nil)
+	assert.Error(t, err)
+}
+
+func TestExecutor_ExecuteCompressed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+		switch r.URL.Path {
+		case "/gzip":
+			assert.Equal(t, "gzip, deflate", r.Header.Get("Accept-Encoding"))
+			writer := gzip.NewWriter(&buf)
+			writer.Write([]byte(`{"compressed":true}`))
+			writer.Close()
+			w.Header().Set("Content-Encoding", "gzip")
+		case "/deflate":
+			writer := zlib.NewWriter(&buf)
+			writer.Write([]byte(`deflated`))
+			writer.Close()
+			w.Header().Set("Content-Encoding", "deflate")
+		case "/br":
+			assert.Equal(t, "br", r.Header.Get("Accept-Encoding"))
+			buf.WriteString("not really brotli")
+			w.Header().Set("Content-Encoding", "br")
+		}
+		w.Write(buf.Bytes())
+	}))
+	defer server.Close()
+
+	executor := NewExecutor(10*time.Second, nil)
+
+	response, err := executor.Execute(context.Background(), &models.HTTPRequest{Method: "GET", URL: server.URL + "/gzip"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"compressed":true}`, string(response.Body))
+	assert.Equal(t, "gzip", response.ContentEncoding)
+	assert.Empty(t, response.Headers["Content-Encoding"])
+
+	response, err = executor.Execute(context.Background(), &models.HTTPRequest{Method: "GET", URL: server.URL + "/deflate"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `deflated`, string(response.Body))
+
+	// Encodings that can't be decoded are kept as received
+	response, err = executor.Execute(context.Background(), &models.HTTPRequest{
+		Method:  "GET",
+		URL:     server.URL + "/br",
+		Headers: map[string]string{"Accept-Encoding": "br"},
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "not really brotli", string(response.Body))
+	assert.Equal(t, "br", response.Encoding())
+
+	// Without decompression, bodies stay compressed
+	executor = NewExecutor(10*time.Second, nil, WithCompression(models.CompressionOptions{AcceptEncoding: "gzip, deflate"}))
+	response, err = executor.Execute(context.Background(), &models.HTTPRequest{Method: "GET", URL: server.URL + "/gzip"}, nil)
+	assert.NoError(t, err)
+	assert.NotEqual(t, `{"compressed":true}`, string(response.Body))
+	assert.Equal(t, []string{"gzip"}, response.Headers["Content-Encoding"])
+}
+
+func TestExecutor_ExecuteRedirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/old":
+			http.Redirect(w, r, "/moved", http.StatusMovedPermanently)
+		case "/moved":
+			http.Redirect(w, r, "/new", http.StatusFound)
+		case "/new":
+			w.Write([]byte("arrived"))
+		}
+	}))
+	defer server.Close()
+
+	executor := NewExecutor(10*time.Second, nil)
+
+	response, err := executor.Execute(context.Background(), &models.HTTPRequest{Method: "GET", URL: server.URL + "/old"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "arrived", string(response.Body))
+	assert.Equal(t, server.URL+"/new", response.URL)
+	assert.Equal(t, []models.RedirectHop{
+		{StatusCode: http.StatusMovedPermanently, URL: server.URL + "/old", Location: "/moved"},
+		{StatusCode: http.StatusFound, URL: server.URL + "/moved", Location: "/new"},
+	}, response.Redirects)
+
+	// A request's own policy overrides the executor's
+	response, err = executor.Execute(context.Background(), &models.HTTPRequest{
+		Method:    "GET",
+		URL:       server.URL + "/old",
+		Redirects: &models.RedirectPolicy{},
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusMovedPermanently, response.StatusCode)
+	assert.Empty(t, response.Redirects)
+
+	executor = NewExecutor(10*time.Second, nil, WithRedirects(models.RedirectPolicy{Follow: true, Max: 1}))
+	_, err = executor.Execute(context.Background(), &models.HTTPRequest{Method: "GET", URL: server.URL + "/old"}, nil)
+	assert.ErrorContains(t, err, "stopped after 1 redirects")
+}
+
+func TestExecutor_ExecuteTimeoutPhases(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/slow-headers":
+			time.Sleep(200 * time.Millisecond)
+		case "/slow-body":
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+			time.Sleep(200 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+
+	executor := NewExecutor(time.Second, nil, WithTimeouts(models.Timeouts{ResponseHeader: 50 * time.Millisecond}))
+	_, err := executor.Execute(context.Background(), &models.HTTPRequest{Method: "GET", URL: server.URL + "/slow-headers"}, nil)
+	var timeoutErr *models.TimeoutError
+	if assert.ErrorAs(t, err, &timeoutErr) {
+		assert.Equal(t, models.TimeoutPhaseResponseHeader, timeoutErr.Phase)
+		assert.Equal(t, 50*time.Millisecond, timeoutErr.Limit)
+		assert.Contains(t, err.Error(), "response-header timeout of 50ms exceeded")
+	}
+
+	executor = NewExecutor(100*time.Millisecond, nil)
+	_, err = executor.Execute(context.Background(), &models.HTTPRequest{Method: "GET", URL: server.URL + "/slow-body"}, nil)
+	if assert.ErrorAs(t, err, &timeoutErr) {
+		assert.Equal(t, models.TimeoutPhaseRequest, timeoutErr.Phase)
+		assert.Equal(t, models.TimeoutPhaseBody, timeoutErr.During)
+		assert.Contains(t, err.Error(), "request timeout of 100ms exceeded during body")
+	}
+}
+
+func TestExecutor_ExecuteRetries(t *testing.T) {
+	var attempts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		body, _ := io.ReadAll(r.Body)
+		if attempts < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	executor := NewExecutor(10*time.Second, nil, WithRetries(models.RetryOptions{Max: 3, InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}))
+
+	response, err := executor.Execute(context.Background(), &models.HTTPRequest{Method: "PUT", URL: server.URL, Body: "payload"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "payload", string(response.Body))
+	assert.Equal(t, 2, response.Retries)
+
+	// Requests that aren't idempotent aren't retried
+	attempts = 0
+	response, err = executor.Execute(context.Background(), &models.HTTPRequest{Method: "POST", URL: server.URL}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, response.StatusCode)
+	assert.Equal(t, 0, response.Retries)
+
+	// Nor are requests that turn retries off
+	attempts = 0
+	response, err = executor.Execute(context.Background(), &models.HTTPRequest{Method: "GET", URL: server.URL, Retries: &models.RetryOptions{}}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, response.StatusCode)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestRetryAfter(t *testing.T) {
+	assert.Equal(t, 2*time.Second, retryAfter(map[string][]string{"Retry-After": {"2"}}, time.Second))
+	assert.Equal(t, time.Second, retryAfter(map[string][]string{"Retry-After": {"soon"}}, time.Second))
+	assert.Equal(t, time.Duration(0), retryAfter(map[string][]string{"Retry-After": {"Wed, 21 Oct 2015 07:28:00 GMT"}}, time.Second))
+
+	wait := retryAfter(map[string][]string{"Retry-After": {time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)}}, time.Second)
+	assert.InDelta(t, float64(time.Minute), float64(wait), float64(2*time.Second))
+}
+
+func TestExecutor_ExecuteCircuitBreaker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	executor := NewExecutor(10*time.Second, nil, WithCircuitBreaker(models.CircuitBreakerOptions{Threshold: 2, CoolDown: time.Minute}))
+	request := &models.HTTPRequest{Method: "GET", URL: url}
+
+	for i := 0; i < 2; i++ {
+		_, err := executor.Execute(context.Background(), request, nil)
+		assert.ErrorContains(t, err, "failed to execute HTTP request")
+	}
+
+	_, err := executor.Execute(context.Background(), request, nil)
+	var circuitErr *models.CircuitOpenError
+	assert.ErrorAs(t, err, &circuitErr)
+}
+
+func TestExecutor_ExecuteMaxBodyBytes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := bytes.Repeat([]byte("a"), 100)
+		if r.URL.Path == "/gzip" {
+			var buf bytes.Buffer
+			writer := gzip.NewWriter(&buf)
+			writer.Write(body)
+			writer.Close()
+			w.Header().Set("Content-Encoding", "gzip")
+			body = buf.Bytes()
+		}
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	executor := NewExecutor(10*time.Second, nil, WithMaxBodyBytes(100))
+	response, err := executor.Execute(context.Background(), &models.HTTPRequest{Method: "GET", URL: server.URL + "/plain"}, nil)
+	assert.NoError(t, err)
+	assert.Len(t, response.Body, 100)
+
+	// A request's own limit overrides the executor's, decompressed bodies included
+	_, err = executor.Execute(context.Background(), &models.HTTPRequest{Method: "GET", URL: server.URL + "/gzip", MaxBodyBytes: 50}, nil)
+	assert.ErrorContains(t, err, "response body is larger than the limit of 50B")
+
+	executor = NewExecutor(10*time.Second, nil, WithMaxBodyBytes(99))
+	_, err = executor.Execute(context.Background(), &models.HTTPRequest{Method: "GET", URL: server.URL + "/plain"}, nil)
+	assert.ErrorContains(t, err, "response body is larger than the limit of 99B")
+}
+
+func TestExecutor_ExecuteBodyInclude(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, string(body))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "user.json"), []byte(`{"name":"{{NAME}}"}`), 0644)
+	executor := NewExecutor(10*time.Second, map[string]string{"NAME": "Ada"})
+	path := filepath.Join(dir, "users.http")
+
+	for _, body := range []string{"< ./user.json", "<@ ./user.json"} {
+		_, err := executor.Execute(context.Background(), &models.HTTPRequest{Method: "POST", URL: server.URL, Body: body, Path: path}, nil)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, []string{`{"name":"{{NAME}}"}`, `{"name":"Ada"}`}, received)
+
+	_, err := executor.Execute(context.Background(), &models.HTTPRequest{Method: "POST", URL: server.URL, Body: "< missing.json", Path: path}, nil)
+	assert.ErrorContains(t, err, "failed to read request body from missing.json")
+}
+
+func TestExecutor_StrictVariables(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+	}))
+	defer server.Close()
+
+	executor := NewExecutor(10*time.Second, map[string]string{"TENANT": "acme"})
+	request := &models.HTTPRequest{Method: "GET", URL: server.URL + "/{{TENANT}}/users/{{id}}"}
+	strict := models.ContextWithStrictVariables(context.Background(), true)
+
+	// Strict variables fail before anything is sent, listing the missing ones
+	_, err := executor.Execute(strict, request, nil)
+	var unresolved *models.UnresolvedVariablesError
+	if assert.ErrorAs(t, err, &unresolved) {
+		assert.Equal(t, []string{"id"}, unresolved.Names)
+	}
+	assert.EqualError(t, err, "unresolved variables: id")
+	assert.Empty(t, paths)
+
+	// The environment of the executor and the request's variables count
+	_, err = executor.Execute(strict, request, map[string]string{"id": "1"})
+	assert.NoError(t, err)
+
+	// Otherwise the placeholders are sent as written
+	_, err = executor.Execute(models.ContextWithStrictVariables(context.Background(), false), request, nil)
+	assert.NoError(t, err)
+	_, err = executor.Execute(context.Background(), request, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/acme/users/1", "/acme/users/{{id}}", "/acme/users/{{id}}"}, paths)
+}
+
+func TestExecutor_EnvironmentNamespace(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+	}))
+	defer server.Close()
+
+	// Request variables override the environment of the executor, which
+	// {{env.name}} reads whatever they hold
+	executor := NewExecutor(10*time.Second, map[string]string{"TENANT": "acme"})
+	request := &models.HTTPRequest{Method: "GET", URL: server.URL + "/{{TENANT}}/{{env.TENANT}}"}
+	_, err := executor.Execute(context.Background(), request, map[string]string{"TENANT": "globex"})
+	assert.NoError(t, err)
+	_, err = executor.Execute(context.Background(), request, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/globex/acme", "/acme/acme"}, paths)
+}
@@ -0,0 +1,163 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// Format writes an HTTP file normalized: requests are separated by "###"
+// lines between blank lines, comments come before directives, which are
+// written as "# @name value" in a fixed order, headers are sorted and written
+// as "Name: value" with their canonical name, JSON bodies are indented with two
+// spaces and runs of blank lines are collapsed. The version stamp, "@host"
+// lines, the titles of separators and the comments between requests are kept.
+func (w *Writer) Format(out io.Writer, file *models.HTTPFile) error {
+	var lines []string
+	for i := range file.Requests {
+		request := file.Requests[i]
+		head, tail, err := w.sourceLines(&request)
+		if err != nil {
+			return err
+		}
+
+		separated := false
+		for _, line := range head {
+			separated = separated || isSeparator(line)
+		}
+		if i > 0 && !separated {
+			lines = appendSeparator(lines, "###")
+		}
+		for _, line := range head {
+			if isSeparator(line) {
+				lines = appendSeparator(lines, line)
+			} else {
+				lines = append(lines, line)
+			}
+		}
+		if len(head) > 0 && !isSeparator(head[len(head)-1]) {
+			lines = appendBlank(lines)
+		}
+
+		lines = append(lines, requestLines(formatRequest(request))...)
+		if len(tail) > 0 {
+			lines = appendFree(appendBlank(lines), tail)
+		}
+	}
+	lines = appendFree(lines, file.Trailing)
+
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	buffered := bufio.NewWriter(out)
+	for _, line := range lines {
+		if _, err := buffered.WriteString(line + "\n"); err != nil {
+			return fmt.Errorf("failed to write HTTP file: %w", err)
+		}
+	}
+	if err := buffered.Flush(); err != nil {
+		return fmt.Errorf("failed to write HTTP file: %w", err)
+	}
+	return nil
+}
+
+// sourceLines returns the lines of the source of a request that have no
+// field of their own: the lines before it that are neither comments nor
+// directives, such as separators, the version stamp and "@host" lines, and
+// the lines after it up to the next request, such as comments
+func (w *Writer) sourceLines(request *models.HTTPRequest) (head, tail []string, err error) {
+	if len(request.Source) == 0 {
+		return nil, nil, nil
+	}
+	parsed, err := w.parser.Parse(strings.NewReader(strings.Join(request.Source, "\n")), request.Path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse source of request %s: %w", request.Name, err)
+	}
+	if len(parsed.Requests) != 1 {
+		return nil, nil, fmt.Errorf("failed to parse source of request %s: found %d requests", request.Name, len(parsed.Requests))
+	}
+	original := &parsed.Requests[0]
+
+	// The comments and directives are written from the request
+	for _, line := range request.Source[:original.Line-1] {
+		if kind, isComment := w.classify(line); kind == "" && !isComment && strings.TrimSpace(line) != "" {
+			head = append(head, strings.TrimSpace(line))
+		}
+	}
+	for _, line := range request.Source[original.EndLine:] {
+		tail = append(tail, strings.TrimRight(line, " \t"))
+	}
+	return head, tail, nil
+}
+
+// formatRequest returns a request with canonical header names and a body
+// without trailing blank lines, indented when it is JSON
+func formatRequest(request models.HTTPRequest) *models.HTTPRequest {
+	headers := make(map[string]string, len(request.Headers))
+	for name, value := range request.Headers {
+		headers[http.CanonicalHeaderKey(name)] = strings.TrimSpace(value)
+	}
+	request.Headers = headers
+
+	body := strings.TrimRight(request.Body, " \t\r\n")
+	if trimmed := strings.TrimSpace(body); strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, []byte(trimmed), "", "  "); err == nil {
+			body = indented.String()
+		}
+	}
+	request.Body = body
+	return &request
+}
+
+// isSeparator reports whether a line separates requests
+func isSeparator(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), "###")
+}
+
+// appendSeparator appends a separator between blank lines, keeping its
+// title as "### title"
+func appendSeparator(lines []string, separator string) []string {
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) > 0 {
+		lines = append(lines, "")
+	}
+	if title := strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(separator), "#")); title != "" {
+		separator = "### " + title
+	} else {
+		separator = "###"
+	}
+	return append(lines, separator, "")
+}
+
+// appendBlank appends a blank line unless the lines end with one
+func appendBlank(lines []string) []string {
+	if len(lines) > 0 && lines[len(lines)-1] != "" {
+		lines = append(lines, "")
+	}
+	return lines
+}
+
+// appendFree appends lines that belong to no request, such as comments
+// after the last one, collapsing blank lines
+func appendFree(lines, free []string) []string {
+	for _, line := range free {
+		switch {
+		case isSeparator(line):
+			lines = appendSeparator(lines, line)
+		case strings.TrimSpace(line) == "":
+			lines = appendBlank(lines)
+		default:
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,81 @@
+package http
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriter_Format(t *testing.T) {
+	content := `@host https://example.com
+# @tag users
+# Creates a user
+#   @name createUser
+POST /api/users
+content-type:application/json
+x-request-id:   abc
+
+{"name":"Ada","roles":["admin"]}
+
+
+
+####   Get users
+GET /api/users
+accept: application/json
+GET /api/users/{{id}}
+
+{"id": {{id}}}
+
+>> ./out/user.json
+### Notes
+
+
+# kept after the requests
+`
+	formatted := `@host https://example.com
+
+# Creates a user
+# @name createUser
+# @tag users
+POST /api/users
+Content-Type: application/json
+X-Request-Id: abc
+
+{
+  "name": "Ada",
+  "roles": [
+    "admin"
+  ]
+}
+
+### Get users
+
+GET /api/users
+Accept: application/json
+
+###
+
+GET /api/users/{{id}}
+
+{"id": {{id}}}
+
+>> ./out/user.json
+
+### Notes
+
+# kept after the requests
+`
+
+	writer := NewWriter()
+	for _, input := range []string{content, formatted} {
+		file, err := NewParser().Parse(strings.NewReader(input), "users.http")
+		require.NoError(t, err)
+
+		var out bytes.Buffer
+		require.NoError(t, writer.Format(&out, file))
+		assert.Equal(t, formatted, out.String())
+	}
+}
@@ -0,0 +1,54 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// setGRPCStatus records the gRPC status of a call made through a JSON
+// transcoding gateway in the response headers: the grpc-status header or
+// trailer sent by the gateway, the code of a JSON error body, or OK for a
+// successful response
+func setGRPCStatus(response *models.HTTPResponse, trailer http.Header) {
+	// Trailers are only known once the body has been read
+	for name, values := range trailer {
+		if _, ok := response.Headers[name]; !ok {
+			response.Headers[name] = values
+		}
+	}
+	if len(response.Headers[models.GRPCStatusHeader]) > 0 {
+		return
+	}
+
+	if response.StatusCode >= 200 && response.StatusCode <= 299 {
+		response.Headers[models.GRPCStatusHeader] = []string{"0"}
+		return
+	}
+
+	// Gateways report errors as {"code": 5, ...} or {"code": "not_found", ...}
+	var status struct {
+		Code    json.RawMessage `json:"code"`
+		Message string          `json:"message"`
+	}
+	if err := json.Unmarshal([]byte(response.Body), &status); err != nil || len(status.Code) == 0 {
+		return
+	}
+	code, err := strconv.Atoi(string(status.Code))
+	if err != nil {
+		var name string
+		if json.Unmarshal(status.Code, &name) != nil {
+			return
+		}
+		var ok bool
+		if code, ok = models.GRPCCode(name); !ok {
+			return
+		}
+	}
+	response.Headers[models.GRPCStatusHeader] = []string{strconv.Itoa(code)}
+	if status.Message != "" && len(response.Headers["Grpc-Message"]) == 0 {
+		response.Headers["Grpc-Message"] = []string{status.Message}
+	}
+}
@@ -0,0 +1,361 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// grpcHTTPStatus are the HTTP statuses native gRPC responses get by their
+// gRPC status code, as transcoding gateways map them
+var grpcHTTPStatus = map[codes.Code]int{
+	codes.OK:                 http.StatusOK,
+	codes.Canceled:           499,
+	codes.Unknown:            http.StatusInternalServerError,
+	codes.InvalidArgument:    http.StatusBadRequest,
+	codes.DeadlineExceeded:   http.StatusGatewayTimeout,
+	codes.NotFound:           http.StatusNotFound,
+	codes.AlreadyExists:      http.StatusConflict,
+	codes.PermissionDenied:   http.StatusForbidden,
+	codes.ResourceExhausted:  http.StatusTooManyRequests,
+	codes.FailedPrecondition: http.StatusBadRequest,
+	codes.Aborted:            http.StatusConflict,
+	codes.OutOfRange:         http.StatusBadRequest,
+	codes.Unimplemented:      http.StatusNotImplemented,
+	codes.Internal:           http.StatusInternalServerError,
+	codes.Unavailable:        http.StatusServiceUnavailable,
+	codes.DataLoss:           http.StatusInternalServerError,
+	codes.Unauthenticated:    http.StatusUnauthorized,
+}
+
+// WithGRPCDescriptorSets sets the descriptor set files, as written by
+// "protoc --include_imports --descriptor_set_out", native gRPC calls find
+// their methods in rather than asking the server through reflection
+func WithGRPCDescriptorSets(paths []string) ExecutorOption {
+	return executorOption(func(e *Executor) {
+		e.grpcDescriptors = nil
+		if len(paths) > 0 {
+			e.grpcDescriptors = &grpcDescriptors{paths: paths}
+		}
+	})
+}
+
+// grpcDescriptors are the descriptor sets of native gRPC calls, loaded by
+// the first call
+type grpcDescriptors struct {
+	paths []string
+	once  sync.Once
+	files *protoregistry.Files
+	err   error
+}
+
+// load reads the descriptor sets once
+func (d *grpcDescriptors) load() (*protoregistry.Files, error) {
+	d.once.Do(func() {
+		var protos []*descriptorpb.FileDescriptorProto
+		for _, path := range d.paths {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				d.err = fmt.Errorf("failed to read gRPC descriptor set: %w", err)
+				return
+			}
+			var set descriptorpb.FileDescriptorSet
+			if err := proto.Unmarshal(data, &set); err != nil {
+				d.err = fmt.Errorf("invalid gRPC descriptor set %s: %w", path, err)
+				return
+			}
+			protos = append(protos, set.File...)
+		}
+		d.files, d.err = newGRPCFiles(protos)
+	})
+	return d.files, d.err
+}
+
+// executeGRPC calls a unary gRPC method natively, with the JSON body as the
+// request message, and returns the reply as JSON with the gRPC status in the
+// Grpc-Status header. Methods are described by the descriptor sets, or by
+// the server through reflection.
+func (e *Executor) executeGRPC(ctx context.Context, request *models.HTTPRequest, rawURL, body string, vars map[string]string) (*models.HTTPResponse, error) {
+	serviceName, methodName, err := models.ParseGRPCTarget(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gRPC URL %q: %w", rawURL, err)
+	}
+
+	transport := insecure.NewCredentials()
+	if target.Scheme == "grpcs" {
+		transport = credentials.NewTLS(&tls.Config{})
+	}
+	conn, err := grpc.NewClient(target.Host, grpc.WithTransportCredentials(transport))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gRPC client: %w", err)
+	}
+	defer conn.Close()
+
+	if e.timeouts.Request > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.timeouts.Request)
+		defer cancel()
+	}
+
+	// Describe the method
+	var files *protoregistry.Files
+	if e.grpcDescriptors != nil {
+		files, err = e.grpcDescriptors.load()
+	} else {
+		files, err = reflectGRPCFiles(ctx, conn, serviceName)
+	}
+	if err != nil {
+		return nil, err
+	}
+	method, err := findGRPCMethod(files, serviceName, methodName)
+	if err != nil {
+		return nil, err
+	}
+
+	input := dynamicpb.NewMessage(method.Input())
+	if strings.TrimSpace(body) != "" {
+		if err := protojson.Unmarshal([]byte(body), input); err != nil {
+			return nil, fmt.Errorf("invalid gRPC request body for %s/%s: %w", serviceName, methodName, err)
+		}
+	}
+
+	// Headers are sent as metadata
+	requestHeaders := make(http.Header)
+	md := metadata.MD{}
+	for name, value := range request.Headers {
+		value = e.processVariables(value, vars)
+		requestHeaders.Add(name, value)
+		if !strings.EqualFold(name, "Content-Type") {
+			md.Append(name, value)
+		}
+	}
+
+	// Execute the call
+	output := dynamicpb.NewMessage(method.Output())
+	var header, trailer metadata.MD
+	startTime := time.Now()
+	err = conn.Invoke(metadata.NewOutgoingContext(ctx, md), "/"+serviceName+"/"+methodName, input, output,
+		grpc.Header(&header), grpc.Trailer(&trailer))
+	duration := time.Since(startTime)
+
+	// Replies and errors are answered as JSON, errors as {"code": 5, "message": "..."}
+	callStatus := status.Convert(err)
+	var respBody []byte
+	if callStatus.Code() == codes.OK {
+		respBody, err = protojson.Marshal(output)
+		if err == nil {
+			respBody, err = compactJSON(respBody)
+		}
+	} else {
+		respBody, err = json.Marshal(map[string]interface{}{
+			"code":    int(callStatus.Code()),
+			"message": callStatus.Message(),
+		})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode gRPC response: %w", err)
+	}
+
+	headers := map[string][]string{"Content-Type": {"application/json"}}
+	for _, md := range []metadata.MD{header, trailer} {
+		for name, values := range md {
+			if _, ok := headers[http.CanonicalHeaderKey(name)]; !ok {
+				headers[http.CanonicalHeaderKey(name)] = values
+			}
+		}
+	}
+	headers[models.GRPCStatusHeader] = []string{strconv.Itoa(int(callStatus.Code()))}
+	if callStatus.Message() != "" {
+		headers["Grpc-Message"] = []string{callStatus.Message()}
+	}
+
+	statusCode, ok := grpcHTTPStatus[callStatus.Code()]
+	if !ok {
+		statusCode = http.StatusInternalServerError
+	}
+	return &models.HTTPResponse{
+		StatusCode:      statusCode,
+		Status:          fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+		Headers:         headers,
+		Body:            string(respBody),
+		ContentType:     "application/json",
+		ContentLength:   int64(len(respBody)),
+		Duration:        duration,
+		URL:             rawURL,
+		ResolvedRequest: models.NewResolvedRequest(models.MethodGRPC, rawURL, requestHeaders, body),
+		Request:         request,
+		RequestID:       fmt.Sprintf("%s-%s", request.Method, request.Path),
+		Timestamp:       time.Now(),
+	}, nil
+}
+
+// compactJSON removes the whitespace protojson varies on purpose, so replies
+// compare the same between runs
+func compactJSON(data []byte) ([]byte, error) {
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, data); err != nil {
+		return nil, err
+	}
+	return compacted.Bytes(), nil
+}
+
+// findGRPCMethod returns the descriptor of a method of a service
+func findGRPCMethod(files *protoregistry.Files, serviceName, methodName string) (protoreflect.MethodDescriptor, error) {
+	descriptor, err := files.FindDescriptorByName(protoreflect.FullName(serviceName))
+	if err != nil {
+		return nil, fmt.Errorf("gRPC service %s not found", serviceName)
+	}
+	service, ok := descriptor.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("gRPC service %s not found", serviceName)
+	}
+	method := service.Methods().ByName(protoreflect.Name(methodName))
+	if method == nil {
+		return nil, fmt.Errorf("gRPC method %s/%s not found", serviceName, methodName)
+	}
+	if method.IsStreamingClient() || method.IsStreamingServer() {
+		return nil, fmt.Errorf("gRPC method %s/%s streams, only unary methods can be called", serviceName, methodName)
+	}
+	return method, nil
+}
+
+// reflectGRPCFiles asks the server for the file defining a service and the
+// files it depends on through server reflection
+func reflectGRPCFiles(ctx context.Context, conn *grpc.ClientConn, serviceName string) (*protoregistry.Files, error) {
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("gRPC server reflection failed: %w", err)
+	}
+	defer stream.CloseSend()
+
+	var protos []*descriptorpb.FileDescriptorProto
+	requested := make(map[string]bool)
+	next := &reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: serviceName},
+	}
+	for next != nil {
+		if err := stream.Send(next); err != nil {
+			return nil, fmt.Errorf("gRPC server reflection failed: %w", err)
+		}
+		response, err := stream.Recv()
+		if err != nil {
+			return nil, fmt.Errorf("gRPC server reflection failed: %w", err)
+		}
+		if failure := response.GetErrorResponse(); failure != nil {
+			return nil, fmt.Errorf("gRPC server reflection failed for %s: %s", serviceName, failure.GetErrorMessage())
+		}
+		for _, data := range response.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			file := &descriptorpb.FileDescriptorProto{}
+			if err := proto.Unmarshal(data, file); err != nil {
+				return nil, fmt.Errorf("invalid gRPC descriptor from server reflection: %w", err)
+			}
+			if !hasGRPCFile(protos, file.GetName()) {
+				requested[file.GetName()] = true
+				protos = append(protos, file)
+			}
+		}
+
+		// Ask for the dependencies the server didn't send, unless they are
+		// linked into the binary like the well-known types
+		next = nil
+		for _, file := range protos {
+			for _, dependency := range file.GetDependency() {
+				if next == nil && !requested[dependency] && !isLinkedGRPCFile(dependency) {
+					requested[dependency] = true
+					next = &reflectionpb.ServerReflectionRequest{
+						MessageRequest: &reflectionpb.ServerReflectionRequest_FileByFilename{FileByFilename: dependency},
+					}
+				}
+			}
+		}
+	}
+
+	return newGRPCFiles(protos)
+}
+
+// hasGRPCFile reports whether the file descriptors include one with the name
+func hasGRPCFile(protos []*descriptorpb.FileDescriptorProto, name string) bool {
+	for _, file := range protos {
+		if file.GetName() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isLinkedGRPCFile reports whether a file descriptor is linked into the binary
+func isLinkedGRPCFile(name string) bool {
+	_, err := protoregistry.GlobalFiles.FindFileByPath(name)
+	return err == nil
+}
+
+// newGRPCFiles builds a registry of file descriptors, registering the
+// dependencies of each file first; dependencies missing from the list, such
+// as the well-known types, are taken from the ones linked into the binary
+func newGRPCFiles(protos []*descriptorpb.FileDescriptorProto) (*protoregistry.Files, error) {
+	byName := make(map[string]*descriptorpb.FileDescriptorProto, len(protos))
+	for _, file := range protos {
+		byName[file.GetName()] = file
+	}
+
+	files := new(protoregistry.Files)
+	var register func(name string) error
+	register = func(name string) error {
+		if _, err := files.FindFileByPath(name); err == nil {
+			return nil
+		}
+		file, ok := byName[name]
+		if !ok {
+			linked, err := protoregistry.GlobalFiles.FindFileByPath(name)
+			if err != nil {
+				return fmt.Errorf("gRPC descriptor %s not found", name)
+			}
+			return files.RegisterFile(linked)
+		}
+		for _, dependency := range file.GetDependency() {
+			if err := register(dependency); err != nil {
+				return err
+			}
+		}
+		descriptor, err := protodesc.NewFile(file, files)
+		if err != nil {
+			return fmt.Errorf("invalid gRPC descriptor %s: %w", name, err)
+		}
+		return files.RegisterFile(descriptor)
+	}
+	for _, file := range protos {
+		if err := register(file.GetName()); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,110 @@
+package http
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// startHealthServer serves the gRPC health service, with server reflection
+// when asked, and returns its address and the metadata of the last call
+func startHealthServer(t *testing.T, withReflection bool) (string, *metadata.MD) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	received := &metadata.MD{}
+	server := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		*received, _ = metadata.FromIncomingContext(ctx)
+		return handler(ctx, req)
+	}))
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	if withReflection {
+		reflection.Register(server)
+	}
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	return listener.Addr().String(), received
+}
+
+func TestExecutor_NativeGRPC(t *testing.T) {
+	address, received := startHealthServer(t, true)
+	executor := NewExecutor(5*time.Second, nil)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantCode   string
+		wantBody   string
+	}{
+		{name: "ok", body: `{"service": ""}`, wantStatus: 200, wantCode: "0", wantBody: `{"status":"SERVING"}`},
+		{name: "empty body", wantStatus: 200, wantCode: "0", wantBody: `{"status":"SERVING"}`},
+		{name: "error status", body: `{"service": "acme.Missing"}`, wantStatus: 404, wantCode: "5", wantBody: `{"code":5,"message":"unknown service"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := &models.HTTPRequest{
+				Method:  models.MethodGRPC,
+				URL:     "grpc://{{address}}/grpc.health.v1.Health/Check",
+				Headers: map[string]string{"Authorization": "Bearer {{token}}"},
+				Body:    tt.body,
+			}
+
+			response, err := executor.Execute(context.Background(), request, map[string]string{"address": address, "token": "secret"})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, response.StatusCode)
+			assert.Equal(t, []string{tt.wantCode}, response.Headers[models.GRPCStatusHeader])
+			assert.JSONEq(t, tt.wantBody, response.Body)
+			assert.Equal(t, "application/json", response.ContentType)
+			assert.Equal(t, []string{"Bearer secret"}, received.Get("authorization"))
+		})
+	}
+}
+
+func TestExecutor_NativeGRPCDescriptorSets(t *testing.T) {
+	// The server doesn't offer reflection, the descriptor set describes it
+	address, _ := startHealthServer(t, false)
+	set := &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{
+		protodesc.ToFileDescriptorProto(healthpb.File_grpc_health_v1_health_proto),
+	}}
+	data, err := proto.Marshal(set)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "health.pb")
+	require.NoError(t, os.WriteFile(path, data, 0644))
+
+	executor := NewExecutor(5*time.Second, nil, WithGRPCDescriptorSets([]string{path}))
+	response, err := executor.Execute(context.Background(), &models.HTTPRequest{
+		Method: models.MethodGRPC,
+		URL:    "grpc://" + address + "/grpc.health.v1.Health/Check",
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, `{"status":"SERVING"}`, response.Body)
+
+	// Methods missing from the descriptor sets and streaming methods fail
+	for url, want := range map[string]string{
+		"grpc://" + address + "/grpc.health.v1.Health/Ping":  "gRPC method grpc.health.v1.Health/Ping not found",
+		"grpc://" + address + "/acme.users.v1.Users/Get":     "gRPC service acme.users.v1.Users not found",
+		"grpc://" + address + "/grpc.health.v1.Health/Watch": "gRPC method grpc.health.v1.Health/Watch streams, only unary methods can be called",
+	} {
+		_, err := executor.Execute(context.Background(), &models.HTTPRequest{Method: models.MethodGRPC, URL: url}, nil)
+		assert.EqualError(t, err, want, url)
+	}
+}
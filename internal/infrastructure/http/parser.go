@@ -3,188 +3,595 @@ package http
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/edgardnogueira/swagger-to-http/internal/version"
 )
 
 // Parser represents an HTTP file parser
 type Parser struct {
-	commentPattern *regexp.Regexp
-	tagPattern     *regexp.Regexp
-	namePattern    *regexp.Regexp
-	headerPattern  *regexp.Regexp
-	methodPattern  *regexp.Regexp
+	commentPattern   *regexp.Regexp
+	tagPattern       *regexp.Regexp
+	namePattern      *regexp.Regexp
+	dependsPattern   *regexp.Regexp
+	directivePattern *regexp.Regexp
+	methodPattern    *regexp.Regexp
+	hostPattern      *regexp.Regexp
+
+	// Template naming the requests without "@name"
+	nameTemplate string
+}
+
+// ParserOption configures a Parser
+type ParserOption interface {
+	applyParser(*Parser)
+}
+
+// parserOption is a ParserOption setting fields of the parser
+type parserOption func(*Parser)
+
+func (o parserOption) applyParser(p *Parser) { o(p) }
+
+// WithNameTemplate names the requests without "@name" with a template such as
+// "{{method}} {{path}} [{{tag}}]", see models.FormatTestName
+func WithNameTemplate(template string) ParserOption {
+	return parserOption(func(p *Parser) {
+		p.nameTemplate = template
+	})
+}
+
+// ExtraMethods is both a ParserOption and an ExecutorOption, so the requests
+// an executor sends use the methods its parser reads
+type ExtraMethods []string
+
+// WithExtraMethods lets requests use custom methods besides the standard ones,
+// e.g. "PURGE" or "LINK", upper-cased as models.ParseExtraMethods returns them.
+// Parsers read request lines with them and executors send them.
+func WithExtraMethods(methods []string) ExtraMethods {
+	return ExtraMethods(methods)
+}
+
+func (m ExtraMethods) applyParser(p *Parser) { p.methodPattern = methodPattern(m) }
+
+func (m ExtraMethods) applyExecutor(e *Executor) { e.methods = m }
+
+// methodPattern matches the request lines of the standard methods, GRPC and
+// the extra methods
+func methodPattern(extra []string) *regexp.Regexp {
+	methods := append([]string{}, models.StandardMethods...)
+	methods = append(methods, models.MethodGRPC)
+	for _, method := range extra {
+		methods = append(methods, regexp.QuoteMeta(method))
+	}
+	return regexp.MustCompile(`^(` + strings.Join(methods, "|") + `)\s+(.+)$`)
 }
 
 // NewParser creates a new HTTP file parser
-func NewParser() *Parser {
-	return &Parser{
-		commentPattern: regexp.MustCompile(`^#\s*(.*)$`),
-		tagPattern:     regexp.MustCompile(`^@tag\s+(.+)$`),
-		namePattern:    regexp.MustCompile(`^@name\s+(.+)$`),
-		headerPattern:  regexp.MustCompile(`^([^:]+):\s*(.+)$`),
-		methodPattern:  regexp.MustCompile(`^(GET|POST|PUT|DELETE|PATCH|HEAD|OPTIONS)\s+(.+)$`),
+func NewParser(options ...ParserOption) *Parser {
+	p := &Parser{
+		commentPattern:   regexp.MustCompile(`^#\s*(.*)$`),
+		tagPattern:       regexp.MustCompile(`^@tag\s+(.+)$`),
+		namePattern:      regexp.MustCompile(`^@name\s+(.+)$`),
+		dependsPattern:   regexp.MustCompile(`^@depends-on\s+(.+)$`),
+		directivePattern: regexp.MustCompile(`^@([a-z][a-z-]*)(?:\s+(.*))?$`),
+		methodPattern:    methodPattern(nil),
+		hostPattern:      regexp.MustCompile(`^@(?:host\s+|baseUrl\s*=\s*)(\S+)\s*$`),
+		nameTemplate:     models.DefaultNameTemplate,
 	}
+	for _, option := range options {
+		option.applyParser(p)
+	}
+	return p
 }
 
+// parseState is where the parser is in a request
+type parseState int
+
+const (
+	// stateOutside is between requests, where comments, directives and
+	// separators are
+	stateOutside parseState = iota
+	// stateHeaders follows a request line, until the blank line ending its headers
+	stateHeaders
+	// stateBody follows the headers, until the next separator
+	stateBody
+)
+
+// headerPattern matches a header line: a token name and a value
+var headerPattern = regexp.MustCompile("^([!#$%&'*+.^_`|~0-9A-Za-z-]+):[ \t]*(.*)$")
+
 // ParseFile parses an HTTP file from the file system
 func (p *Parser) ParseFile(filePath string) (*models.HTTPFile, error) {
-	// Open the file
 	file, err := os.Open(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
 	defer file.Close()
 
-	// Create an HTTP file with the filename
+	return p.Parse(file, filePath)
+}
+
+// Parse reads an HTTP file line by line, recording the position of its
+// requests, headers and bodies. Malformed headers and invalid directives are
+// returned together as models.ParseErrors, with their positions.
+//
+// Once the blank line after the headers is reached, every line up to the
+// next "###" separator belongs to the body, even if it looks like a header,
+// a comment or a request line, except the ">> path" lines saving the
+// response to a file. Only while the body has not started do
+// comments, directives and request lines apply to the next request, so that
+// requests without a body may follow one another without a separator.
+func (p *Parser) Parse(r io.Reader, filePath string) (*models.HTTPFile, error) {
 	httpFile := &models.HTTPFile{
 		Filename: filePath,
 		Requests: []models.HTTPRequest{},
 	}
 
-	scanner := bufio.NewScanner(file)
-	var currentRequest *models.HTTPRequest
-	var currentBody []string
-	var readingBody bool
-	var comments []string
-
-	// Parse the file line by line
-	for scanner.Scan() {
-		line := scanner.Text()
+	var (
+		state    parseState
+		current  *models.HTTPRequest
+		body     []string
+		comments []string
+		pending  requestDirectives
+		host     string
+		errs     models.ParseErrors
+
+		// The lines read, and the first line of the current request's source
+		lines      []string
+		chunkStart int
+	)
+
+	// finish saves the current request with its body and its source up to
+	// the line at index end. Lines without a request, such as those after a
+	// last separator, belong to the request before them.
+	finish := func(end int) {
+		if current != nil {
+			current.Body = strings.Join(body, "\n")
+			current.Source = lines[chunkStart:end:end]
+			httpFile.Requests = append(httpFile.Requests, *current)
+			chunkStart = end
+		} else if n := len(httpFile.Requests); n > 0 {
+			last := &httpFile.Requests[n-1]
+			last.Source = append(last.Source, lines[chunkStart:end]...)
+			chunkStart = end
+		}
+		current = nil
+		body = nil
+	}
 
-		// Check if this is a request separator
-		if strings.HasPrefix(line, "###") {
-			// Save the current request if there is one
-			if currentRequest != nil {
-				currentRequest.Body = strings.Join(currentBody, "\n")
-				httpFile.Requests = append(httpFile.Requests, *currentRequest)
-			}
+	// fail records an error at a column of a line
+	fail := func(line, column int, format string, args ...interface{}) {
+		errs = append(errs, &models.ParseError{
+			File:     filePath,
+			Position: models.Position{Line: line, Column: column},
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
 
-			// Reset for the next request
-			currentRequest = nil
-			currentBody = []string{}
-			readingBody = false
-			comments = []string{}
-			continue
+	reader := bufio.NewReader(r)
+	for lineNumber := 1; ; lineNumber++ {
+		line, readErr := reader.ReadString('\n')
+		if readErr != nil && readErr != io.EOF {
+			return nil, fmt.Errorf("error reading file: %w", readErr)
 		}
-
-		// Check if this is a comment
-		if matches := p.commentPattern.FindStringSubmatch(line); len(matches) > 1 {
-			comment := matches[1]
-			comments = append(comments, comment)
-			continue
+		if readErr == io.EOF && line == "" {
+			break
 		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+		lines = append(lines, line)
 
-		// Check if this is a tag
-		if matches := p.tagPattern.FindStringSubmatch(line); len(matches) > 1 {
-			tag := matches[1]
-			if currentRequest == nil {
-				// Create a new request if needed
-				currentRequest = &models.HTTPRequest{
-					Comments: comments,
-					Tag:      tag,
-					Path:     filePath,
-				}
-				comments = []string{}
+		// A separator ends the request, whatever state the parser is in, and
+		// starts the source of the next one
+		if strings.HasPrefix(line, "###") {
+			finish(lineNumber - 1)
+			state = stateOutside
+			comments = nil
+			pending = requestDirectives{}
+		} else if output, ok := models.ParseResponseOutput(line); ok && current != nil {
+			// The files the response is saved to follow the request
+			output.Line = lineNumber
+			current.Outputs = append(current.Outputs, output)
+			current.EndLine = lineNumber
+		} else if state == stateBody && (current.BodyLine > 0 || p.startsBody(line)) {
+			if current.BodyLine == 0 {
+				current.BodyLine = lineNumber
+			}
+			// Blank lines after the outputs don't belong to the body
+			if len(current.Outputs) == 0 || strings.TrimSpace(line) != "" {
+				body = append(body, line)
+			}
+			if strings.TrimSpace(line) != "" {
+				current.EndLine = lineNumber
+			}
+			if include, ok := models.ParseBodyInclude(line); ok {
+				include.Line = lineNumber
+				current.BodyIncludes = append(current.BodyIncludes, include)
+			}
+		} else if matches := p.methodPattern.FindStringSubmatch(line); len(matches) > 2 {
+			// A request line starts a new request, ending the one without a body
+			// after its last line
+			if current != nil {
+				finish(current.EndLine)
+			}
+			current = p.newRequest(matches[1], matches[2], filePath, lineNumber, host, httpFile.SpecHash, comments, pending)
+			state = stateHeaders
+			comments = nil
+			pending = requestDirectives{}
+		} else if stamp, ok := version.ParseStamp(line); ok && current == nil && len(httpFile.Requests) == 0 {
+			// The version stamp at the top of a generated file
+			httpFile.ToolVersion = stamp.Version
+			httpFile.SpecHash = stamp.SpecHash
+		} else if matches := p.hostPattern.FindStringSubmatch(strings.TrimSpace(strings.TrimPrefix(line, "#"))); len(matches) > 1 {
+			// The base URL of the relative URLs of the following requests, e.g.
+			// "@host https://api.example.com" or "@baseUrl = ..."
+			host = matches[1]
+		} else if matches := p.commentPattern.FindStringSubmatch(line); len(matches) > 1 {
+			// Directives may also be written as comments, e.g. "# @name createUser"
+			matched, err := p.parseDirective(matches[1], &pending)
+			if err != nil {
+				fail(lineNumber, directiveColumn(line), "%v", err)
+			} else if !matched {
+				comments = append(comments, matches[1])
+			}
+		} else if matched, err := p.parseDirective(line, &pending); matched || err != nil {
+			if err != nil {
+				fail(lineNumber, directiveColumn(line), "%v", err)
+			}
+		} else if state == stateHeaders {
+			if strings.TrimSpace(line) == "" {
+				state = stateBody
+			} else if matches := headerPattern.FindStringSubmatch(line); len(matches) > 2 {
+				current.Headers[matches[1]] = strings.TrimSpace(matches[2])
+				current.HeaderLines[matches[1]] = lineNumber
+				current.EndLine = lineNumber
 			} else {
-				currentRequest.Tag = tag
+				fail(lineNumber, 1, "malformed header %q: expected \"Name: value\", or a blank line before the body", line)
 			}
-			continue
 		}
 
-		// Check if this is a name
-		if matches := p.namePattern.FindStringSubmatch(line); len(matches) > 1 {
-			name := matches[1]
-			if currentRequest == nil {
-				// Create a new request if needed
-				currentRequest = &models.HTTPRequest{
-					Comments: comments,
-					Name:     name,
-					Path:     filePath,
-				}
-				comments = []string{}
-			} else {
-				currentRequest.Name = name
-			}
-			continue
+		if readErr == io.EOF {
+			break
 		}
+	}
 
-		// Handle HTTP method line (GET, POST, etc.)
-		if matches := p.methodPattern.FindStringSubmatch(line); len(matches) > 2 && !readingBody {
-			// Save the current request if there is one
-			if currentRequest != nil {
-				currentRequest.Body = strings.Join(currentBody, "\n")
-				httpFile.Requests = append(httpFile.Requests, *currentRequest)
-			}
+	finish(len(lines))
+	if len(httpFile.Requests) == 0 {
+		httpFile.Trailing = lines
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return httpFile, nil
+}
 
-			// Create a new request
-			method := matches[1]
-			url := matches[2]
+// startsBody reports whether a line after the headers starts the body rather
+// than being a blank line, a comment or a directive before it
+func (p *Parser) startsBody(line string) bool {
+	if strings.TrimSpace(line) == "" || p.commentPattern.MatchString(line) {
+		return false
+	}
+	if p.methodPattern.MatchString(line) || p.hostPattern.MatchString(strings.TrimSpace(line)) {
+		return false
+	}
+	matched, err := p.parseDirective(line, &requestDirectives{})
+	return !matched && err == nil
+}
 
-			currentRequest = &models.HTTPRequest{
-				Method:   method,
-				URL:      url,
-				Headers:  []models.HTTPHeader{},
-				Comments: comments,
-				Path:     filePath,
-			}
+// directiveColumn returns the column of the directive of a line, starting at 1
+func directiveColumn(line string) int {
+	if i := strings.Index(line, "@"); i >= 0 {
+		return utf8.RuneCountInString(line[:i]) + 1
+	}
+	return 1
+}
 
-			// If no explicit name was set, use the path as the name
-			if currentRequest.Name == "" {
-				currentRequest.Name = fmt.Sprintf("%s %s", method, p.simplifyPath(url))
-			}
+// newRequest creates the request of a request line with the directives and
+// comments that precede it
+func (p *Parser) newRequest(method, url, filePath string, line int, host, specHash string, comments []string, pending requestDirectives) *models.HTTPRequest {
+	request := &models.HTTPRequest{
+		Method:            method,
+		URL:               url,
+		Headers:           map[string]string{},
+		HeaderLines:       map[string]int{},
+		Comments:          comments,
+		Name:              pending.name,
+		Tag:               pending.tag,
+		DependsOn:         pending.dependsOn,
+		Scopes:            pending.scopes,
+		SnapshotTransform: pending.snapshotTransform,
+		IgnoreArrayOrder:  pending.ignoreArrayOrder,
+		ArrayOrderKey:     pending.arrayOrderKey,
+		Tolerances:        pending.tolerances,
+		Deprecated:        pending.deprecated,
+		Safe:              pending.safe,
+		RateLimit:         pending.rateLimit,
+		SerialGroup:       pending.serialGroup,
+		Priority:          pending.priority,
+		Quarantined:       pending.quarantined,
+		QuarantineReason:  pending.quarantineReason,
+		Skip:              pending.skip,
+		SkipReason:        pending.skipReason,
+		Only:              pending.only,
+		Callbacks:         pending.callbacks,
+		Poll:              pending.poll,
+		Redirects:         pending.redirects,
+		Paginate:          pending.paginate,
+		ExpectStatus:      pending.expectStatus,
+		XSD:               pending.xsd,
+		ExpectEncoding:    pending.expectEncoding,
+		ExpectHeaders:     pending.expectHeaders,
+		SnapshotCompare:   pending.snapshotCompare,
+		IgnoreHeaders:     pending.ignoreHeaders,
+		Path:              filePath,
+		SpecHash:          specHash,
+		Line:              line,
+		EndLine:           line,
+		Host:              host,
+		QueryParams:       models.ParseQueryParams(url),
+	}
 
-			// Reset for the new request
-			currentBody = []string{}
-			readingBody = false
-			comments = []string{}
-			continue
-		}
+	// If no explicit name was set, name the request with the template
+	if request.Name == "" {
+		request.Name = models.FormatTestName(p.nameTemplate, models.TestNameFields{
+			Method: method,
+			Path:   urlPath(url),
+			Slug:   p.simplifyPath(url),
+			Tag:    pending.tag,
+			File:   strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath)),
+		})
+		request.DerivedName = true
+	}
+	return request
+}
 
-		// Handle headers if not already reading the body
-		if !readingBody && currentRequest != nil {
-			if matches := p.headerPattern.FindStringSubmatch(line); len(matches) > 2 {
-				name := matches[1]
-				value := matches[2]
-				currentRequest.Headers = append(currentRequest.Headers, models.HTTPHeader{
-					Name:  name,
-					Value: value,
-				})
-				continue
-			}
-		}
+// requestDirectives holds the directives collected for the next request
+type requestDirectives struct {
+	name              string
+	tag               string
+	dependsOn         []string
+	scopes            []string
+	snapshotTransform string
+	ignoreArrayOrder  bool
+	arrayOrderKey     string
+	tolerances        map[string]models.Tolerance
+	deprecated        bool
+	safe              bool
+	rateLimit         *models.RateLimit
+	serialGroup       string
+	priority          int
+	quarantined       bool
+	quarantineReason  string
+	skip              bool
+	skipReason        string
+	only              bool
+	callbacks         []models.CallbackExpectation
+	poll              *models.PollOptions
+	redirects         *models.RedirectPolicy
+	paginate          *models.Pagination
+	expectStatus      string
+	xsd               string
+	expectEncoding    string
+	expectHeaders     []models.ExpectedHeader
+	snapshotCompare   models.SnapshotScope
+	ignoreHeaders     []string
+}
 
-		// Empty line after headers marks the start of the body or the end of the request
-		if line == "" && currentRequest != nil && !readingBody {
-			if len(currentRequest.Headers) > 0 {
-				readingBody = true
-			}
-			continue
-		}
+// parseDirective records @name, @tag, @depends-on and other directives for the
+// next request, reporting whether text is one. Known directives with an
+// invalid argument are an error.
+func (p *Parser) parseDirective(text string, pending *requestDirectives) (bool, error) {
+	text = strings.TrimSpace(text)
 
-		// If we're reading the body, add the line to the body
-		if readingBody && currentRequest != nil {
-			currentBody = append(currentBody, line)
+	if matches := p.namePattern.FindStringSubmatch(text); len(matches) > 1 {
+		pending.name = strings.TrimSpace(matches[1])
+		return true, nil
+	}
+	if matches := p.tagPattern.FindStringSubmatch(text); len(matches) > 1 {
+		// Tags may be comma separated and repeated, e.g. "@tag users,admin"
+		tags := append(models.SplitTags(pending.tag), models.SplitTags(matches[1])...)
+		pending.tag = strings.Join(models.SplitTags(strings.Join(tags, ",")), ",")
+		return true, nil
+	}
+	if matches := p.dependsPattern.FindStringSubmatch(text); len(matches) > 1 {
+		// Dependencies may be comma or space separated and repeated
+		for _, dep := range strings.FieldsFunc(matches[1], func(r rune) bool { return r == ',' || r == ' ' }) {
+			pending.dependsOn = append(pending.dependsOn, dep)
 		}
+		return true, nil
 	}
 
-	// Check for scanner errors
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading file: %w", err)
+	// Directives with a free-form argument
+	if matches := p.directivePattern.FindStringSubmatch(text); len(matches) > 2 {
+		value := strings.TrimSpace(matches[2])
+		switch matches[1] {
+		case "snapshot-transform":
+			pending.snapshotTransform = value
+			return true, nil
+		case "deprecated":
+			pending.deprecated = true
+			return true, nil
+		case "scopes":
+			// "@scopes <scope> ...", space or comma separated and repeatable,
+			// e.g. "@scopes read:pets write:pets"
+			scopes := strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+			if len(scopes) == 0 {
+				return true, fmt.Errorf("invalid @scopes directive: expected one or more scopes")
+			}
+			pending.scopes = append(pending.scopes, scopes...)
+			return true, nil
+		case "safe":
+			pending.safe = true
+			return true, nil
+		case "rate-limit":
+			// "@rate-limit <requests>/<period>", e.g. "@rate-limit 10/s"
+			limit, err := models.ParseRateLimit(value)
+			if err != nil {
+				return true, fmt.Errorf("invalid @%s directive: %w", matches[1], err)
+			}
+			pending.rateLimit = &limit
+			return true, nil
+		case "serial-group":
+			// "@serial-group <name>"
+			if value == "" || strings.ContainsAny(value, " \t") {
+				return true, fmt.Errorf("invalid @serial-group directive: expected a name without spaces")
+			}
+			pending.serialGroup = value
+			return true, nil
+		case "priority":
+			// "@priority <n>", lower values run first with --order priority
+			priority, err := strconv.Atoi(value)
+			if err != nil || priority < 1 {
+				return true, fmt.Errorf("invalid @priority directive %q: expected a number from 1", value)
+			}
+			pending.priority = priority
+			return true, nil
+		case "quarantine":
+			// "@quarantine [reason]", e.g. "@quarantine JIRA-123"
+			pending.quarantined = true
+			pending.quarantineReason = value
+			return true, nil
+		case "skip":
+			// "@skip [reason]"
+			pending.skip = true
+			pending.skipReason = value
+			return true, nil
+		case "only":
+			pending.only = true
+			return true, nil
+		case "callback":
+			// "@callback <name> [METHOD] [timeout=<duration>] [fields=<a,b>]"
+			callback, err := models.ParseCallbackDirective(value)
+			if err != nil {
+				return true, fmt.Errorf("invalid @%s directive: %w", matches[1], err)
+			}
+			pending.callbacks = append(pending.callbacks, callback)
+			return true, nil
+		case "poll":
+			// "@poll [off] [interval=<duration>] [timeout=<duration>]"
+			poll, err := models.ParsePollDirective(value)
+			if err != nil {
+				return true, fmt.Errorf("invalid @%s directive: %w", matches[1], err)
+			}
+			pending.poll = &poll
+			return true, nil
+		case "redirects":
+			// "@redirects off|max=<n>"
+			redirects, err := models.ParseRedirectDirective(value)
+			if err != nil {
+				return true, fmt.Errorf("invalid @%s directive: %w", matches[1], err)
+			}
+			pending.redirects = &redirects
+			return true, nil
+		case "paginate":
+			// "@paginate link|next=<path>|page=<param> [size=<param>:<n>] [items=<path>] ..."
+			paginate, err := models.ParsePaginateDirective(value)
+			if err != nil {
+				return true, fmt.Errorf("invalid @%s directive: %w", matches[1], err)
+			}
+			pending.paginate = &paginate
+			return true, nil
+		case "expect-status":
+			// "@expect-status <code>|<class>", e.g. "@expect-status 201" or "2xx"
+			status, err := models.ParseExpectedStatus(value)
+			if err != nil {
+				return true, fmt.Errorf("invalid @%s directive: %w", matches[1], err)
+			}
+			pending.expectStatus = status
+			return true, nil
+		case "expect-encoding":
+			// "@expect-encoding <encoding>", e.g. "@expect-encoding gzip" or "identity"
+			encoding, err := models.ParseExpectedEncoding(value)
+			if err != nil {
+				return true, fmt.Errorf("invalid @%s directive: %w", matches[1], err)
+			}
+			pending.expectEncoding = encoding
+			return true, nil
+		case "expect-header":
+			// "@expect-header <name>: <value>", e.g. "@expect-header X-Request-Id: <<uuid>>"
+			header, err := models.ParseExpectedHeader(value)
+			if err != nil {
+				return true, fmt.Errorf("invalid @%s directive: %w", matches[1], err)
+			}
+			pending.expectHeaders = append(pending.expectHeaders, header)
+			return true, nil
+		case "ignore-headers":
+			// "@ignore-headers <pattern>, ...", e.g. "@ignore-headers X-Trace-.*, !Date"
+			patterns, err := models.ParseHeaderPatterns(value)
+			if err != nil {
+				return true, fmt.Errorf("invalid @%s directive: %w", matches[1], err)
+			}
+			pending.ignoreHeaders = append(pending.ignoreHeaders, patterns...)
+			return true, nil
+		case "snapshot-compare":
+			// "@snapshot-compare body-only|headers-only|status-only|all"
+			scope, err := models.ParseSnapshotScope(value)
+			if err != nil {
+				return true, fmt.Errorf("invalid @%s directive: %w", matches[1], err)
+			}
+			pending.snapshotCompare = scope
+			return true, nil
+		case "xsd":
+			// "@xsd <file>", an XSD or WSDL file relative to the .http file
+			if value == "" {
+				return true, fmt.Errorf("invalid @xsd directive: expected a file")
+			}
+			pending.xsd = value
+			return true, nil
+		case "ignore-array-order":
+			pending.ignoreArrayOrder = true
+			pending.arrayOrderKey = value
+			return true, nil
+		case "approx":
+			// "@approx <path> [abs=<n>] [rel=<n>]"
+			fields := strings.SplitN(value, " ", 2)
+			spec := ""
+			if len(fields) > 1 {
+				spec = fields[1]
+			}
+			tolerance, err := models.ParseTolerance(spec)
+			if err != nil {
+				return true, fmt.Errorf("invalid @approx directive: %w", err)
+			}
+			if fields[0] == "" {
+				return true, fmt.Errorf("invalid @approx directive: expected a path")
+			}
+			if pending.tolerances == nil {
+				pending.tolerances = make(map[string]models.Tolerance)
+			}
+			pending.tolerances[fields[0]] = tolerance
+			return true, nil
+		}
 	}
 
-	// Save the last request if there is one
-	if currentRequest != nil {
-		currentRequest.Body = strings.Join(currentBody, "\n")
-		httpFile.Requests = append(httpFile.Requests, *currentRequest)
-	}
+	return false, nil
+}
 
-	return httpFile, nil
+// urlPath returns the path of a request URL, without its scheme and host or
+// base URL variable, and without its query
+func urlPath(url string) string {
+	path := strings.SplitN(url, "?", 2)[0]
+	if strings.HasPrefix(path, "http") {
+		parts := strings.SplitN(path, "/", 4)
+		path = "/"
+		if len(parts) >= 4 {
+			path += parts[3]
+		}
+	} else if end := strings.Index(path, "}}"); strings.HasPrefix(path, "{{") && end >= 0 {
+		path = path[end+2:]
+	}
+	if path == "" {
+		path = "/"
+	}
+	return path
 }
 
 // simplifyPath returns a simplified version of a URL path for use as a name
@@ -229,6 +636,11 @@ func (p *Parser) ParseDirectory(dirPath string) ([]*models.HTTPFile, error) {
 	for _, match := range matches {
 		file, err := p.ParseFile(match)
 		if err != nil {
+			// Parse errors already name the file
+			var parseErrors models.ParseErrors
+			if errors.As(err, &parseErrors) {
+				return nil, err
+			}
 			return nil, fmt.Errorf("failed to parse file %s: %w", match, err)
 		}
 		files = append(files, file)
@@ -239,65 +651,26 @@ func (p *Parser) ParseDirectory(dirPath string) ([]*models.HTTPFile, error) {
 
 // ParseContent parses raw HTTP file content into requests
 func (p *Parser) ParseContent(content []byte, filePath string) ([]models.HTTPRequest, error) {
-	// Create a temporary file with the content
-	tempDir, err := os.MkdirTemp("", "swagger-to-http")
-	if err != nil {
-		return nil, fmt.Errorf("failed to create temp directory: %w", err)
-	}
-	defer os.RemoveAll(tempDir)
-
-	tempFile := filepath.Join(tempDir, "temp.http")
-	if err := os.WriteFile(tempFile, content, 0644); err != nil {
-		return nil, fmt.Errorf("failed to write temp file: %w", err)
-	}
-
-	// Parse the temporary file
-	httpFile, err := p.ParseFile(tempFile)
+	httpFile, err := p.Parse(bytes.NewReader(content), filePath)
 	if err != nil {
 		return nil, err
 	}
-
-	// Update the file paths to the original
-	for i := range httpFile.Requests {
-		httpFile.Requests[i].Path = filePath
-	}
-
 	return httpFile.Requests, nil
 }
 
-// FindHTTPFiles finds all .http files in a directory (or matching a glob pattern)
+// FindHTTPFiles finds the .http files in a directory, not in its
+// subdirectories, or the files matching a glob pattern
 func (p *Parser) FindHTTPFiles(pattern string) ([]string, error) {
-	// Handle glob pattern
+	if info, err := os.Stat(pattern); err == nil && info.IsDir() {
+		pattern = filepath.Join(pattern, "*.http")
+	}
+
 	matches, err := filepath.Glob(pattern)
 	if err != nil {
 		return nil, fmt.Errorf("invalid pattern: %w", err)
 	}
-
-	if len(matches) > 0 {
-		return matches, nil
+	if matches == nil {
+		matches = []string{}
 	}
-
-	// If no matches and pattern doesn't have wildcard, try as directory
-	if !strings.Contains(pattern, "*") {
-		fileInfo, err := os.Stat(pattern)
-		if err == nil && fileInfo.IsDir() {
-			// It's a directory, find all .http files
-			var files []string
-			err = filepath.Walk(pattern, func(path string, info os.FileInfo, err error) error {
-				if err != nil {
-					return err
-				}
-				if !info.IsDir() && strings.HasSuffix(info.Name(), ".http") {
-					files = append(files, path)
-				}
-				return nil
-			})
-			if err != nil {
-				return nil, fmt.Errorf("failed to walk directory: %w", err)
-			}
-			return files, nil
-		}
-	}
-
-	return []string{}, nil
+	return matches, nil
 }
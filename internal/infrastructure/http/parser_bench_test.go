@@ -0,0 +1,43 @@
+package http
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// benchmarkHTTPFile returns a .http file with n requests using directives,
+// headers, variables and JSON bodies, as generated files do
+func benchmarkHTTPFile(n int) string {
+	var content strings.Builder
+	content.WriteString("@host = https://api.example.com\n\n")
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&content, "### Create user %d\n", i)
+		fmt.Fprintf(&content, "# @name createUser%d\n", i)
+		content.WriteString("# @tag users\n")
+		content.WriteString("# @expect-status 201\n")
+		fmt.Fprintf(&content, "POST {{host}}/users/%d?verbose=true\n", i)
+		content.WriteString("Content-Type: application/json\n")
+		content.WriteString("Authorization: Bearer {{token}}\n\n")
+		fmt.Fprintf(&content, "{\n  \"id\": %d,\n  \"name\": \"{{name}}\",\n  \"roles\": [\"admin\", \"user\"]\n}\n\n", i)
+	}
+	return content.String()
+}
+
+func BenchmarkParser_ParseFile(b *testing.B) {
+	path := filepath.Join(b.TempDir(), "users.http")
+	if err := os.WriteFile(path, []byte(benchmarkHTTPFile(200)), 0644); err != nil {
+		b.Fatal(err)
+	}
+	parser := NewParser()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := parser.ParseFile(path); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
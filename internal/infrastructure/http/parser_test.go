@@ -1,11 +1,14 @@
 package http
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestParser_ParseContent(t *testing.T) {
@@ -80,20 +83,31 @@ Accept: application/json
 func TestParser_ParseRequest(t *testing.T) {
 	parser := NewParser()
 
+	// parseRequest parses content holding a single request
+	parseRequest := func(content string) (*models.HTTPRequest, error) {
+		requests, err := parser.ParseContent([]byte(content), "test.http")
+		if err != nil {
+			return nil, err
+		}
+		if len(requests) != 1 {
+			return nil, fmt.Errorf("found %d requests", len(requests))
+		}
+		return &requests[0], nil
+	}
+
 	t.Run("Basic GET request", func(t *testing.T) {
 		content := `GET https://example.com/api/users
 Accept: application/json
 X-API-Key: abc123
 
 `
-		request, err := parser.parseRequest([]byte(content), 1, "test.http")
+		request, err := parseRequest(content)
 		assert.NoError(t, err)
 		assert.Equal(t, "GET", request.Method)
 		assert.Equal(t, "https://example.com/api/users", request.URL)
-		assert.Equal(t, "/api/users", request.Path)
+		assert.Equal(t, "test.http", request.Path)
 		assert.Len(t, request.Headers, 2)
-		assert.Equal(t, "Accept", request.Headers[0].Name)
-		assert.Equal(t, "application/json", request.Headers[0].Value)
+		assert.Equal(t, "application/json", request.Headers["Accept"])
 	})
 
 	t.Run("POST request with body", func(t *testing.T) {
@@ -104,11 +118,11 @@ Content-Type: application/json
   "name": "John Doe",
   "email": "john@example.com"
 }`
-		request, err := parser.parseRequest([]byte(content), 1, "test.http")
+		request, err := parseRequest(content)
 		assert.NoError(t, err)
 		assert.Equal(t, "POST", request.Method)
 		assert.Equal(t, "https://example.com/api/users", request.URL)
-		assert.Equal(t, "/api/users", request.Path)
+		assert.Equal(t, "test.http", request.Path)
 		assert.Contains(t, request.Body, "John Doe")
 	})
 
@@ -119,7 +133,7 @@ GET https://example.com/api/users/1
 Accept: application/json
 
 `
-		request, err := parser.parseRequest([]byte(content), 1, "test.http")
+		request, err := parseRequest(content)
 		assert.NoError(t, err)
 		assert.Equal(t, "GetUserDetails", request.Name)
 		assert.Equal(t, "users", request.Tag)
@@ -133,19 +147,57 @@ GET https://example.com/api/users
 Accept: application/json
 
 `
-		request, err := parser.parseRequest([]byte(content), 1, "test.http")
+		request, err := parseRequest(content)
 		assert.NoError(t, err)
 		assert.Len(t, request.Comments, 2)
-		assert.Equal(t, " This is a test comment", request.Comments[0])
+		assert.Equal(t, "This is a test comment", request.Comments[0])
 	})
 
 	t.Run("Invalid request - missing method", func(t *testing.T) {
 		content := `Accept: application/json`
-		_, err := parser.parseRequest([]byte(content), 1, "test.http")
+		_, err := parseRequest(content)
 		assert.Error(t, err)
 	})
 }
 
+func TestParser_ExtraMethods(t *testing.T) {
+	content := []byte(`TRACE https://example.com/api/users
+
+###
+
+PURGE https://example.com/cache/users
+`)
+
+	requests, err := NewParser().ParseContent(content, "test.http")
+	assert.NoError(t, err)
+	assert.Len(t, requests, 1)
+	assert.Equal(t, "TRACE", requests[0].Method)
+
+	requests, err = NewParser(WithExtraMethods([]string{"PURGE"})).ParseContent(content, "test.http")
+	assert.NoError(t, err)
+	assert.Len(t, requests, 2)
+	assert.Equal(t, "PURGE", requests[1].Method)
+}
+
+func TestParser_Host(t *testing.T) {
+	content := []byte(`@host https://api.example.com/v1
+
+GET /users
+
+###
+
+@baseUrl = http://localhost:8080
+GET /orders
+`)
+
+	requests, err := NewParser().ParseContent(content, "test.http")
+	assert.NoError(t, err)
+	assert.Len(t, requests, 2)
+	assert.Equal(t, "/users", requests[0].URL)
+	assert.Equal(t, "https://api.example.com/v1", requests[0].Host)
+	assert.Equal(t, "http://localhost:8080", requests[1].Host)
+}
+
 func TestParser_FindHTTPFiles(t *testing.T) {
 	parser := NewParser()
 	
@@ -229,3 +281,148 @@ func TestParser_FindHTTPFiles(t *testing.T) {
 		assert.GreaterOrEqual(t, len(files), 1, "Should find at least one file in subfolder")
 	})
 }
+
+func TestParser_Positions(t *testing.T) {
+	content := []byte(`# @name createUser
+POST https://example.com/api/users
+Content-Type: application/json
+X-Empty:
+
+{
+  "name": "Ada",
+  "note": "looks: like a header"
+}
+
+###
+
+GET https://example.com/api/users
+
+# @name second
+GET https://example.com/api/users/1
+`)
+
+	requests, err := NewParser().ParseContent(content, "users.http")
+	require.NoError(t, err)
+	require.Len(t, requests, 3)
+
+	create := requests[0]
+	assert.Equal(t, 2, create.Line)
+	assert.Equal(t, 9, create.EndLine)
+	assert.Equal(t, 6, create.BodyLine)
+	assert.Equal(t, map[string]string{"Content-Type": "application/json", "X-Empty": ""}, create.Headers)
+	assert.Equal(t, map[string]int{"Content-Type": 3, "X-Empty": 4}, create.HeaderLines)
+	assert.Equal(t, "{\n  \"name\": \"Ada\",\n  \"note\": \"looks: like a header\"\n}\n", create.Body)
+
+	// Requests without a body may follow one another without a separator
+	assert.Equal(t, 13, requests[1].Line)
+	assert.Empty(t, requests[1].Body)
+	assert.Equal(t, "second", requests[2].Name)
+	assert.Equal(t, 16, requests[2].Line)
+}
+
+func TestParser_BodyWithoutHeaders(t *testing.T) {
+	content := []byte("POST https://example.com/api/users\r\n\r\n{\"name\": \"Ada\"}\r\n# kept in the body\r\nGET not a request\r\n")
+
+	requests, err := NewParser().ParseContent(content, "users.http")
+	require.NoError(t, err)
+	require.Len(t, requests, 1)
+	assert.Empty(t, requests[0].Headers)
+	assert.Equal(t, "{\"name\": \"Ada\"}\n# kept in the body\nGET not a request", requests[0].Body)
+}
+
+func TestParser_WhitespaceSeparator(t *testing.T) {
+	for _, separator := range []string{"  ", "\t", " \t "} {
+		t.Run(fmt.Sprintf("%q", separator), func(t *testing.T) {
+			content := []byte("POST https://example.com/api/users\nContent-Type: application/json\n" + separator + "\n{\"name\": \"Ada\"}\n\n###\n\nGET https://example.com/api/users\n")
+
+			requests, err := NewParser().ParseContent(content, "users.http")
+			require.NoError(t, err)
+			require.Len(t, requests, 2)
+			assert.Equal(t, map[string]string{"Content-Type": "application/json"}, requests[0].Headers)
+			assert.Equal(t, "{\"name\": \"Ada\"}\n", requests[0].Body)
+			assert.Equal(t, "GET", requests[1].Method)
+		})
+	}
+}
+
+func TestParser_Errors(t *testing.T) {
+	content := []byte(`POST https://example.com/api/users
+Content-Type: application/json
+{"name": "Ada"}
+
+###
+
+# @priority first
+GET https://example.com/api/users
+`)
+
+	_, err := NewParser().ParseContent(content, "users.http")
+	var parseErrors models.ParseErrors
+	require.ErrorAs(t, err, &parseErrors)
+	assert.Equal(t, "users.http:3:1: malformed header \"{\\\"name\\\": \\\"Ada\\\"}\": expected \"Name: value\", or a blank line before the body\n"+
+		"users.http:7:3: invalid @priority directive \"first\": expected a number from 1", err.Error())
+}
+
+func TestParser_BodyIncludes(t *testing.T) {
+	content := []byte(`POST https://example.com/api/users
+Content-Type: application/json
+
+< ./payloads/user.json
+
+###
+
+POST https://example.com/api/orders
+Content-Type: application/xml
+
+<order><id>1</id></order>
+`)
+
+	requests, err := NewParser().ParseContent(content, "users.http")
+	require.NoError(t, err)
+	require.Len(t, requests, 2)
+	assert.Equal(t, "< ./payloads/user.json\n", requests[0].Body)
+	assert.Equal(t, []models.BodyInclude{{Path: "./payloads/user.json", Line: 4}}, requests[0].BodyIncludes)
+	assert.Empty(t, requests[1].BodyIncludes)
+}
+
+func TestParser_ResponseOutputs(t *testing.T) {
+	content := []byte(`POST https://example.com/api/users
+Content-Type: application/json
+
+{"name": "Ada"}
+
+>> ./out/{{name}}-{{status}}.json
+>>! ./out/last.json
+
+###
+
+GET https://example.com/api/users
+>> ./out/users.json
+`)
+
+	requests, err := NewParser().ParseContent(content, "users.http")
+	require.NoError(t, err)
+	require.Len(t, requests, 2)
+	assert.Equal(t, "{\"name\": \"Ada\"}\n", requests[0].Body)
+	assert.Equal(t, []models.ResponseOutput{
+		{Path: "./out/{{name}}-{{status}}.json", Line: 6},
+		{Path: "./out/last.json", Overwrite: true, Line: 7},
+	}, requests[0].Outputs)
+	assert.Equal(t, 7, requests[0].EndLine)
+	assert.Equal(t, []models.ResponseOutput{{Path: "./out/users.json", Line: 12}}, requests[1].Outputs)
+}
+
+func TestParser_Scopes(t *testing.T) {
+	content := []byte(`# @scopes read:pets write:pets
+# @scopes admin
+POST https://example.com/api/pets
+`)
+
+	requests, err := NewParser().ParseContent(content, "pets.http")
+	require.NoError(t, err)
+	require.Len(t, requests, 1)
+	assert.Equal(t, []string{"read:pets", "write:pets", "admin"}, requests[0].Scopes)
+
+	_, err = NewParser().ParseContent([]byte("# @scopes\nGET https://example.com/api/pets\n"), "pets.http")
+	assert.EqualError(t, err, "pets.http:1:3: invalid @scopes directive: expected one or more scopes")
+}
@@ -0,0 +1,30 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// checkRedirect returns the redirect check of a client following redirects
+// as the policy says, which records each redirect followed in hops
+func checkRedirect(policy models.RedirectPolicy, hops *[]models.RedirectHop) func(*http.Request, []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		// Return the redirect itself when redirects aren't followed
+		if !policy.Follow {
+			return http.ErrUseLastResponse
+		}
+		if len(via) > policy.MaxRedirects() {
+			return fmt.Errorf("stopped after %d redirects", policy.MaxRedirects())
+		}
+
+		hop := models.RedirectHop{URL: via[len(via)-1].URL.String()}
+		if req.Response != nil {
+			hop.StatusCode = req.Response.StatusCode
+			hop.Location = req.Response.Header.Get("Location")
+		}
+		*hops = append(*hops, hop)
+		return nil
+	}
+}
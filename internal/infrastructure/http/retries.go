@@ -0,0 +1,48 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// do sends a request, retrying it while an idempotent request is answered
+// with a transient error status and retries are left. The delay between
+// attempts is the one Retry-After asks for, or a jittered exponential
+// backoff, capped either way. It returns the last response and the number of
+// retries.
+func (e *Executor) do(ctx context.Context, client *http.Client, req *http.Request, tracker *phaseTracker, options models.RetryOptions) (*http.Response, int, error) {
+	retryable := models.IsIdempotentMethod(req.Method)
+
+	for retries := 0; ; retries++ {
+		tracker.set(models.TimeoutPhaseConnect)
+		resp, err := client.Do(req)
+		if err != nil || !retryable || retries >= options.Max || !models.IsRetryableStatus(resp.StatusCode) {
+			return resp, retries, err
+		}
+
+		wait := options.Cap(retryAfter(resp.Header, options.Backoff(retries, models.RandomFromContext(ctx).Float64())))
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, retries, ctx.Err()
+		case <-timer.C:
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, retries, fmt.Errorf("failed to rewind request body: %w", err)
+			}
+			req.Body = body
+		}
+	}
+}
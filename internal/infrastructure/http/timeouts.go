@@ -0,0 +1,99 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// newClient returns an HTTP client bounding the phases of requests by the
+// timeouts. Compression is negotiated and decoded by the executor, so the
+// encoding the server used is known.
+func newClient(timeouts models.Timeouts) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DisableCompression = true
+	if timeouts.Connect > 0 {
+		dialer := &net.Dialer{Timeout: timeouts.Connect, KeepAlive: 30 * time.Second}
+		transport.DialContext = dialer.DialContext
+	}
+	if timeouts.TLSHandshake > 0 {
+		transport.TLSHandshakeTimeout = timeouts.TLSHandshake
+	}
+	if timeouts.ResponseHeader > 0 {
+		transport.ResponseHeaderTimeout = timeouts.ResponseHeader
+	}
+
+	return &http.Client{
+		Timeout:   timeouts.Request,
+		Transport: transport,
+	}
+}
+
+// phaseTracker follows the phase a request is in
+type phaseTracker struct {
+	mu    sync.Mutex
+	phase string
+}
+
+// newPhaseTracker returns a tracker of a request that is about to connect
+func newPhaseTracker() *phaseTracker {
+	return &phaseTracker{phase: models.TimeoutPhaseConnect}
+}
+
+// set records the phase the request entered
+func (t *phaseTracker) set(phase string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.phase = phase
+}
+
+// current returns the phase the request is in
+func (t *phaseTracker) current() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.phase
+}
+
+// withTrace returns the request with a trace updating the tracker
+func (t *phaseTracker) withTrace(req *http.Request) *http.Request {
+	trace := &httptrace.ClientTrace{
+		TLSHandshakeStart: func() {
+			t.set(models.TimeoutPhaseTLSHandshake)
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.set(models.TimeoutPhaseResponseHeader)
+		},
+		GotConn: func(httptrace.GotConnInfo) {
+			t.set(models.TimeoutPhaseResponseHeader)
+		},
+		GotFirstResponseByte: func() {
+			t.set(models.TimeoutPhaseBody)
+		},
+	}
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+}
+
+// timeoutError returns the error of a request, as a *models.TimeoutError naming
+// the timeout that expired when it timed out. Deadlines of the caller's context
+// are left to the caller.
+func (e *Executor) timeoutError(ctx context.Context, err error, tracker *phaseTracker, elapsed time.Duration) error {
+	var netErr net.Error
+	if ctx.Err() != nil || !errors.As(err, &netErr) || !netErr.Timeout() {
+		return err
+	}
+
+	during := tracker.current()
+	phase, limit := during, e.timeouts.Limit(during)
+	// The overall timeout expired unless the phase has its own, shorter one
+	if limit <= 0 || (e.timeouts.Request > 0 && elapsed >= e.timeouts.Request) {
+		phase, limit = models.TimeoutPhaseRequest, e.timeouts.Request
+	}
+	return &models.TimeoutError{Phase: phase, During: during, Limit: limit, Err: err}
+}
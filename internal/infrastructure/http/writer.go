@@ -0,0 +1,368 @@
+package http
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/edgardnogueira/swagger-to-http/internal/version"
+)
+
+// Writer writes parsed HTTP files back to text, keeping what wasn't changed
+// as it was written: comments, spacing, the order of the requests and their
+// directives and headers, so tools can change a file without reformatting it
+type Writer struct {
+	parser *Parser
+}
+
+// NewWriter creates a writer of the HTTP files parsed with the options
+func NewWriter(options ...ParserOption) *Writer {
+	return &Writer{parser: NewParser(options...)}
+}
+
+// Writer returns a writer of the HTTP files parsed with the parser
+func (p *Parser) Writer() *Writer {
+	return &Writer{parser: p}
+}
+
+// Write writes an HTTP file. The requests parsed from text are written from
+// their source, rewriting only the parts of them that changed: the request
+// line, the headers, the body, the outputs, the comments and each kind of
+// directive. Other requests are written in full after a separator. Lines end
+// with "\n".
+func (w *Writer) Write(out io.Writer, file *models.HTTPFile) error {
+	var lines []string
+	for i := range file.Requests {
+		request := &file.Requests[i]
+		if len(request.Source) > 0 {
+			source, err := w.update(request)
+			if err != nil {
+				return err
+			}
+			// A request following one with a body needs a separator, or it
+			// would be read as part of the body
+			if i > 0 && file.Requests[i-1].Body != "" && !strings.HasPrefix(source[0], "###") {
+				lines = append(lines, "###")
+			}
+			lines = append(lines, source...)
+			continue
+		}
+
+		if len(lines) > 0 {
+			if strings.TrimSpace(lines[len(lines)-1]) != "" {
+				lines = append(lines, "")
+			}
+			lines = append(lines, "###", "")
+		}
+		lines = append(lines, requestLines(request)...)
+	}
+	lines = append(lines, file.Trailing...)
+
+	buffered := bufio.NewWriter(out)
+	for _, line := range lines {
+		if _, err := buffered.WriteString(line + "\n"); err != nil {
+			return fmt.Errorf("failed to write HTTP file: %w", err)
+		}
+	}
+	if err := buffered.Flush(); err != nil {
+		return fmt.Errorf("failed to write HTTP file: %w", err)
+	}
+	return nil
+}
+
+// update returns the source of a request with the parts that changed since
+// it was parsed rewritten. The parts are rewritten from the last to the
+// first, so the lines of the parts before are where they were parsed.
+func (w *Writer) update(request *models.HTTPRequest) ([]string, error) {
+	parsed, err := w.parser.Parse(strings.NewReader(strings.Join(request.Source, "\n")), request.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse source of request %s: %w", request.Name, err)
+	}
+	if len(parsed.Requests) != 1 {
+		return nil, fmt.Errorf("failed to parse source of request %s: found %d requests", request.Name, len(parsed.Requests))
+	}
+	original := &parsed.Requests[0]
+	lines := append([]string(nil), request.Source...)
+
+	// The files the response is saved to, after the body
+	if !reflect.DeepEqual(outputLines(original.Outputs), outputLines(request.Outputs)) {
+		at := original.EndLine
+		var removed []int
+		for _, output := range original.Outputs {
+			removed = append(removed, output.Line-1)
+		}
+		if len(removed) > 0 {
+			at = removed[0]
+			lines = removeLines(lines, removed)
+		}
+		added := outputLines(request.Outputs)
+		if len(original.Outputs) == 0 && len(added) > 0 {
+			added = append([]string{""}, added...)
+		}
+		lines = insertLines(lines, at, added)
+	}
+
+	// The body, from its first line, or after the headers when there was none
+	if original.Body != request.Body {
+		if original.BodyLine > 0 {
+			start := original.BodyLine - 1
+			end := start + len(strings.Split(original.Body, "\n"))
+			for _, output := range original.Outputs {
+				if output.Line-1 >= start && output.Line-1 < end {
+					return nil, fmt.Errorf("failed to update body of request %s: outputs are between its lines", request.Name)
+				}
+			}
+			lines = append(lines[:start], append(bodyLines(request.Body), lines[end:]...)...)
+		} else if request.Body != "" {
+			at := lastHeaderLine(original)
+			added := bodyLines(request.Body)
+			if at < len(lines) && strings.TrimSpace(lines[at]) == "" {
+				at++
+			} else {
+				added = append([]string{""}, added...)
+			}
+			lines = insertLines(lines, at, added)
+		}
+	}
+
+	// The headers, in the order they were written, keeping the text of those
+	// that didn't change, followed by the new ones
+	if !reflect.DeepEqual(headerLines(original.Headers), headerLines(request.Headers)) {
+		at := lastHeaderLine(original)
+		names := headerNames(original)
+		var removed []int
+		for _, name := range names {
+			removed = append(removed, original.HeaderLines[name]-1)
+		}
+		sort.Ints(removed)
+		var added []string
+		for _, name := range names {
+			value, ok := request.Headers[name]
+			switch {
+			case !ok:
+			case value == original.Headers[name]:
+				added = append(added, lines[original.HeaderLines[name]-1])
+			default:
+				added = append(added, name+": "+value)
+			}
+		}
+		for _, name := range sortedHeaderNames(request.Headers) {
+			if _, ok := original.Headers[name]; !ok {
+				added = append(added, name+": "+request.Headers[name])
+			}
+		}
+		if len(removed) > 0 {
+			at = removed[0]
+			lines = removeLines(lines, removed)
+		}
+		lines = insertLines(lines, at, added)
+	}
+
+	// The request line
+	if original.Method != request.Method || original.URL != request.URL {
+		lines[original.Line-1] = fmt.Sprintf("%s %s", request.Method, request.URL)
+	}
+
+	// The comments and directives before the request line
+	leading := w.updateLeading(lines[:original.Line-1], original, request)
+	return append(leading, lines[original.Line-1:]...), nil
+}
+
+// updateLeading returns the lines before the request line with the comments
+// and the kinds of directives that changed rewritten where the first of them
+// was, or before the request line when there were none
+func (w *Writer) updateLeading(leading []string, original, request *models.HTTPRequest) []string {
+	// A changed name is written even if the original was derived
+	if request.Name != original.Name {
+		named := *request
+		named.DerivedName = false
+		request = &named
+	}
+	before := groupDirectives(original.DirectiveLines())
+	after := groupDirectives(request.DirectiveLines())
+
+	changed := make(map[string]bool)
+	var kinds []string
+	for _, line := range append(original.DirectiveLines(), request.DirectiveLines()...) {
+		kind := models.DirectiveKind(line)
+		if !changed[kind] && !reflect.DeepEqual(before[kind], after[kind]) {
+			changed[kind] = true
+			kinds = append(kinds, kind)
+		}
+	}
+	commentsChanged := !reflect.DeepEqual(commentLines(original.Comments), commentLines(request.Comments))
+
+	var updated []string
+	written := make(map[string]bool)
+	for _, line := range leading {
+		kind, isComment := w.classify(line)
+		switch {
+		case isComment && commentsChanged:
+			if !written[""] {
+				updated = append(updated, commentLines(request.Comments)...)
+				written[""] = true
+			}
+		case kind != "" && changed[kind]:
+			if !written[kind] {
+				updated = append(updated, after[kind]...)
+				written[kind] = true
+			}
+		default:
+			updated = append(updated, line)
+		}
+	}
+
+	// Comments and kinds of directives that weren't there before
+	if commentsChanged && !written[""] {
+		updated = append(updated, commentLines(request.Comments)...)
+	}
+	for _, kind := range kinds {
+		if !written[kind] {
+			updated = append(updated, after[kind]...)
+		}
+	}
+	return updated
+}
+
+// classify returns the kind of directive of a line before a request line, or
+// whether it is a comment
+func (w *Writer) classify(line string) (kind string, isComment bool) {
+	if strings.HasPrefix(line, "###") {
+		return "", false
+	}
+	if _, ok := version.ParseStamp(line); ok {
+		return "", false
+	}
+	if w.parser.hostPattern.MatchString(strings.TrimSpace(strings.TrimPrefix(line, "#"))) {
+		return "", false
+	}
+	text := line
+	if matches := w.parser.commentPattern.FindStringSubmatch(line); len(matches) > 1 {
+		text = matches[1]
+		isComment = true
+	}
+	if matched, _ := w.parser.parseDirective(text, &requestDirectives{}); matched {
+		return models.DirectiveKind(text), false
+	}
+	return "", isComment
+}
+
+// requestLines returns the lines of a request written in full
+func requestLines(request *models.HTTPRequest) []string {
+	lines := commentLines(request.Comments)
+	lines = append(lines, request.DirectiveLines()...)
+	lines = append(lines, fmt.Sprintf("%s %s", request.Method, request.URL))
+	lines = append(lines, headerLines(request.Headers)...)
+	if request.Body != "" {
+		lines = append(lines, "")
+		lines = append(lines, bodyLines(request.Body)...)
+	}
+	if len(request.Outputs) > 0 {
+		lines = append(lines, "")
+		lines = append(lines, outputLines(request.Outputs)...)
+	}
+	return lines
+}
+
+// commentLines returns the lines of comments
+func commentLines(comments []string) []string {
+	var lines []string
+	for _, comment := range comments {
+		for _, line := range strings.Split(comment, "\n") {
+			lines = append(lines, strings.TrimSpace("# "+line))
+		}
+	}
+	return lines
+}
+
+// headerLines returns the lines of headers, sorted by name
+func headerLines(headers map[string]string) []string {
+	var lines []string
+	for _, name := range sortedHeaderNames(headers) {
+		lines = append(lines, name+": "+headers[name])
+	}
+	return lines
+}
+
+// sortedHeaderNames returns the names of headers, sorted
+func sortedHeaderNames(headers map[string]string) []string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// headerNames returns the names of the headers of a parsed request in the
+// order they were written
+func headerNames(request *models.HTTPRequest) []string {
+	names := make([]string, 0, len(request.HeaderLines))
+	for name := range request.HeaderLines {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return request.HeaderLines[names[i]] < request.HeaderLines[names[j]]
+	})
+	return names
+}
+
+// bodyLines returns the lines of a body, which ends with a blank line when
+// it ends with a newline
+func bodyLines(body string) []string {
+	if body == "" {
+		return nil
+	}
+	return strings.Split(body, "\n")
+}
+
+// outputLines returns the lines saving a response to files
+func outputLines(outputs []models.ResponseOutput) []string {
+	var lines []string
+	for _, output := range outputs {
+		lines = append(lines, output.String())
+	}
+	return lines
+}
+
+// groupDirectives groups directive lines by their kind
+func groupDirectives(lines []string) map[string][]string {
+	groups := make(map[string][]string)
+	for _, line := range lines {
+		kind := models.DirectiveKind(line)
+		groups[kind] = append(groups[kind], line)
+	}
+	return groups
+}
+
+// lastHeaderLine returns the index of the line after the last header of a
+// parsed request, or after its request line without headers
+func lastHeaderLine(request *models.HTTPRequest) int {
+	last := request.Line
+	for _, line := range request.HeaderLines {
+		if line > last {
+			last = line
+		}
+	}
+	return last
+}
+
+// removeLines removes the lines at the indexes, in increasing order
+func removeLines(lines []string, indexes []int) []string {
+	for i := len(indexes) - 1; i >= 0; i-- {
+		lines = append(lines[:indexes[i]], lines[indexes[i]+1:]...)
+	}
+	return lines
+}
+
+// insertLines inserts lines at an index
+func insertLines(lines []string, at int, added []string) []string {
+	if at > len(lines) {
+		at = len(lines)
+	}
+	return append(lines[:at], append(append([]string(nil), added...), lines[at:]...)...)
+}
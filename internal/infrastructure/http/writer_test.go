@@ -0,0 +1,131 @@
+package http
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const writerSource = `# Users API, maintained by hand
+@host https://example.com
+
+### Create a user
+# Creates the user the other requests use
+#   @name createUser
+# @tag users
+# @tag admin
+POST /api/users
+Content-Type:application/json
+X-Trace:   abc
+
+{
+  "name": "Ada"
+}
+
+>> ./out/user.json
+
+###
+GET /api/users
+GET /api/users/1
+Accept: application/json
+
+### Notes
+
+# Nothing else to see
+`
+
+func TestWriter_RoundTrip(t *testing.T) {
+	for _, content := range []string{writerSource, "# Nothing yet\n\n", ""} {
+		file, err := NewParser().Parse(strings.NewReader(content), "users.http")
+		require.NoError(t, err)
+
+		var out bytes.Buffer
+		require.NoError(t, NewWriter().Write(&out, file))
+		assert.Equal(t, content, out.String())
+	}
+}
+
+func TestWriter_Changes(t *testing.T) {
+	file, err := NewParser().Parse(strings.NewReader(writerSource), "users.http")
+	require.NoError(t, err)
+	require.Len(t, file.Requests, 3)
+
+	create := &file.Requests[0]
+	create.Tag = "users"
+	create.Priority = 1
+	create.Headers["X-Trace"] = "def"
+	create.Body = "{\"name\": \"Grace\"}\n"
+	create.Outputs = nil
+
+	list := &file.Requests[1]
+	list.Comments = []string{"Lists the users"}
+	list.Headers["Accept"] = "application/json"
+	list.Body = "{}"
+
+	file.Requests = append(file.Requests, models.HTTPRequest{
+		Name:    "deleteUser",
+		Method:  "DELETE",
+		URL:     "/api/users/1",
+		Outputs: []models.ResponseOutput{{Path: "./out/deleted.json"}},
+	})
+
+	var out bytes.Buffer
+	require.NoError(t, NewWriter().Write(&out, file))
+	assert.Equal(t, `# Users API, maintained by hand
+@host https://example.com
+
+### Create a user
+# Creates the user the other requests use
+#   @name createUser
+# @tag users
+# @priority 1
+POST /api/users
+Content-Type:application/json
+X-Trace: def
+
+{"name": "Grace"}
+
+
+###
+# Lists the users
+GET /api/users
+Accept: application/json
+
+{}
+###
+GET /api/users/1
+Accept: application/json
+
+### Notes
+
+# Nothing else to see
+
+###
+
+# @name deleteUser
+DELETE /api/users/1
+
+>> ./out/deleted.json
+`, out.String())
+}
+
+func TestWriter_Headers(t *testing.T) {
+	file, err := NewParser().Parse(strings.NewReader("GET /api/users\nX-Trace:abc\nAccept:  */*\nX-Debug: 1\n"), "users.http")
+	require.NoError(t, err)
+
+	request := &file.Requests[0]
+	delete(request.Headers, "X-Trace")
+	request.Headers["X-Debug"] = "2"
+	request.Headers["Authorization"] = "Bearer {{token}}"
+	request.Headers["Accept-Language"] = "en"
+
+	// Headers keep the order and text they were written with, followed by
+	// the new ones sorted by name
+	var out bytes.Buffer
+	require.NoError(t, NewWriter().Write(&out, file))
+	assert.Equal(t, "GET /api/users\nAccept:  */*\nX-Debug: 2\nAccept-Language: en\nAuthorization: Bearer {{token}}\n", out.String())
+}
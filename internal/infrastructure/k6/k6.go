@@ -0,0 +1,466 @@
+// Package k6 exports HTTP files and test sequences as k6 load test scripts, so
+// performance tests reuse the requests, checks and variable extractions of the
+// functional tests.
+package k6
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// Request is a request of the script, with the checks run on its response and
+// the variables extracted from it
+type Request struct {
+	Name          string
+	Method        string
+	URL           string // May hold {{variables}}
+	Headers       map[string]string
+	Body          string
+	ExpectStatus  string // Status code, e.g. "201", or class, e.g. "2xx"
+	ExpectHeaders []models.ExpectedHeader
+	Assertions    []models.TestAssertion
+	Extractions   []models.VariableExtraction
+}
+
+// Group is a set of requests run in order in a k6 group, such as the requests
+// of an HTTP file or the steps of a sequence
+type Group struct {
+	Name      string
+	Variables map[string]string // Set when the group starts
+	Requests  []Request
+}
+
+// Options configures the load of the script and its default variables
+type Options struct {
+	VUs        int
+	Iterations int
+	Duration   string // Runs for the duration instead of a number of iterations
+	Variables  map[string]string
+	Source     string // What the script was exported from, for its header
+}
+
+// exportedAssertions are the assertion types the script evaluates
+var exportedAssertions = map[string]bool{
+	"equals":      true,
+	"contains":    true,
+	"matches":     true,
+	"exists":      true,
+	"notexists":   true,
+	"in":          true,
+	"lessthan":    true,
+	"lt":          true,
+	"greaterthan": true,
+	"gt":          true,
+}
+
+// exportedSources are the response parts assertions and extractions of the
+// script can read
+var exportedSources = map[string]bool{
+	"body":        true,
+	"header":      true,
+	"status":      true,
+	"contenttype": true,
+}
+
+// FromHTTPRequest returns the request of the script running a request of an
+// HTTP file. Bodies read from files are inlined, as the script runs without
+// them; includes that can't be read are left as written.
+func FromHTTPRequest(request models.HTTPRequest) Request {
+	body := request.Body
+	keep := func(text string) (string, error) { return text, nil }
+	if expanded, err := models.ExpandBodyIncludes(body, request.Path, keep); err == nil {
+		body = expanded
+	}
+	return Request{
+		Name:          request.Name,
+		Method:        request.Method,
+		URL:           request.URL,
+		Headers:       request.Headers,
+		Body:          body,
+		ExpectStatus:  request.ExpectStatus,
+		ExpectHeaders: request.ExpectHeaders,
+	}
+}
+
+// FromSequence returns the group running the steps of a sequence, with their
+// assertions and variable extractions. Skipped steps are left out.
+func FromSequence(sequence *models.TestSequence) Group {
+	group := Group{Name: sequence.Name, Variables: sequence.Variables}
+	for _, step := range sequence.Steps {
+		if step.Skip || step.Request == nil {
+			continue
+		}
+		request := FromHTTPRequest(*step.Request)
+		request.Name = step.Name
+		if step.ExpectedStatus != 0 {
+			request.ExpectStatus = fmt.Sprint(step.ExpectedStatus)
+		}
+		request.Assertions = step.Assertions
+		request.Extractions = step.Variables
+		group.Requests = append(group.Requests, request)
+	}
+	return group
+}
+
+// Render returns the k6 script running the groups in order on every
+// iteration. Default variables can be overridden with k6's -e name=value, and
+// checks of assertions the script can't evaluate are left out with a comment.
+func Render(groups []Group, options Options) (string, error) {
+	var b strings.Builder
+	if options.Source != "" {
+		fmt.Fprintf(&b, "// Load test exported by swagger-to-http from %s\n", options.Source)
+	}
+	b.WriteString("import http from 'k6/http';\nimport { check, group } from 'k6';\n\n")
+
+	vus, iterations := options.VUs, options.Iterations
+	if vus < 1 {
+		vus = 1
+	}
+	if iterations < 1 {
+		iterations = vus
+	}
+	load := map[string]interface{}{"vus": vus}
+	if options.Duration != "" {
+		load["duration"] = options.Duration
+	} else {
+		load["iterations"] = iterations
+	}
+	if err := writeConst(&b, "options", load, true); err != nil {
+		return "", err
+	}
+	variables := options.Variables
+	if variables == nil {
+		variables = map[string]string{}
+	}
+	if err := writeConst(&b, "defaults", variables, false); err != nil {
+		return "", err
+	}
+
+	b.WriteString("\nexport default function () {\n  const vars = withEnv(defaults);\n  let res;\n")
+	for _, g := range groups {
+		name, err := jsValue(g.Name)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "\n  group(%s, function () {\n", name)
+		for _, key := range sortedKeys(g.Variables) {
+			if err := writeAssignment(&b, key, fmt.Sprintf("fill(%s, vars)", mustJS(g.Variables[key]))); err != nil {
+				return "", err
+			}
+		}
+		for _, request := range g.Requests {
+			if err := writeRequest(&b, request); err != nil {
+				return "", err
+			}
+		}
+		b.WriteString("  });\n")
+	}
+	b.WriteString("}\n\n")
+	b.WriteString(helpers)
+	return b.String(), nil
+}
+
+// Write renders the script of the groups to a file
+func Write(path string, groups []Group, options Options) error {
+	script, err := Render(groups, options)
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create directory %s: %w", dir, err)
+		}
+	}
+	if err := os.WriteFile(path, []byte(script), 0644); err != nil {
+		return fmt.Errorf("failed to write k6 script %s: %w", path, err)
+	}
+	return nil
+}
+
+// writeConst writes a constant holding a value as JSON
+func writeConst(b *strings.Builder, name string, value interface{}, exported bool) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", name, err)
+	}
+	if exported {
+		b.WriteString("export ")
+	}
+	fmt.Fprintf(b, "const %s = %s;\n", name, data)
+	return nil
+}
+
+// writeRequest writes the call sending a request, then its checks and
+// extractions
+func writeRequest(b *strings.Builder, request Request) error {
+	headers := map[string]string{}
+	for name, value := range request.Headers {
+		headers[name] = value
+	}
+	params, err := jsValue(map[string]interface{}{"headers": headers, "tags": map[string]string{"name": request.Name}})
+	if err != nil {
+		return err
+	}
+	body := "null"
+	if request.Body != "" {
+		body = fmt.Sprintf("fill(%s, vars)", mustJS(request.Body))
+	}
+	fmt.Fprintf(b, "    res = http.request(%s, fill(%s, vars), %s, fillParams(%s, vars));\n",
+		mustJS(strings.ToUpper(request.Method)), mustJS(request.URL), body, params)
+
+	checks, skipped := requestChecks(request)
+	for _, reason := range skipped {
+		fmt.Fprintf(b, "    // Not exported: %s\n", reason)
+	}
+	if len(checks) > 0 {
+		b.WriteString("    check(res, {\n")
+		for _, c := range checks {
+			fmt.Fprintf(b, "      %s: (r) => %s,\n", mustJS(request.Name+": "+c.name), c.expression)
+		}
+		b.WriteString("    });\n")
+	}
+
+	for _, extraction := range request.Extractions {
+		if !exportedSources[strings.ToLower(extraction.Source)] {
+			fmt.Fprintf(b, "    // Not exported: %s extracted from %s\n", extraction.Name, extraction.Source)
+			continue
+		}
+		spec, err := jsValue(extraction)
+		if err != nil {
+			return err
+		}
+		if err := writeAssignment(b, extraction.Name, fmt.Sprintf("extract(res, %s)", spec)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeAssignment writes the assignment of a variable
+func writeAssignment(b *strings.Builder, name, expression string) error {
+	key, err := jsValue(name)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(b, "    vars[%s] = %s;\n", key, expression)
+	return nil
+}
+
+// check is a k6 check of a response
+type check struct {
+	name       string
+	expression string
+}
+
+// requestChecks returns the checks of a request, and why the expectations
+// that can't be checked were left out. Requests without an expected status
+// check that the response is not an error.
+func requestChecks(request Request) ([]check, []string) {
+	var checks []check
+	var skipped []string
+
+	switch status := strings.ToLower(request.ExpectStatus); {
+	case status == "":
+		checks = append(checks, check{"status is not an error", "r.status < 400"})
+	case len(status) == 3 && strings.HasSuffix(status, "xx") && status[0] >= '1' && status[0] <= '5':
+		checks = append(checks, check{"status is " + status, "Math.floor(r.status / 100) === " + status[:1]})
+	default:
+		if _, err := strconv.Atoi(status); err != nil {
+			skipped = append(skipped, "expected status "+request.ExpectStatus)
+			break
+		}
+		checks = append(checks, check{"status is " + status, "r.status === " + status})
+	}
+
+	for _, expected := range request.ExpectHeaders {
+		if models.IsHeaderValuePattern(expected.Value) {
+			checks = append(checks, check{"has header " + expected.Name, fmt.Sprintf("header(r, %s) !== undefined", mustJS(expected.Name))})
+			continue
+		}
+		checks = append(checks, check{
+			fmt.Sprintf("header %s is %s", expected.Name, expected.Value),
+			fmt.Sprintf("header(r, %s) === %s", mustJS(expected.Name), mustJS(expected.Value)),
+		})
+	}
+
+	for _, assertion := range request.Assertions {
+		kind, source := strings.ToLower(assertion.Type), strings.ToLower(assertion.Source)
+		if !exportedAssertions[kind] || !exportedSources[source] {
+			skipped = append(skipped, fmt.Sprintf("%s assertion on %s %s", assertion.Type, assertion.Source, assertion.Path))
+			continue
+		}
+		spec, err := jsValue(assertion)
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf("%s assertion on %s %s: %v", assertion.Type, assertion.Source, assertion.Path, err))
+			continue
+		}
+		checks = append(checks, check{describeAssertion(assertion), fmt.Sprintf("assert(r, %s)", spec)})
+	}
+	return checks, skipped
+}
+
+// describeAssertion names the check of an assertion, e.g. "body id equals 42"
+func describeAssertion(assertion models.TestAssertion) string {
+	parts := []string{assertion.Source}
+	if assertion.Path != "" {
+		parts = append(parts, assertion.Path)
+	}
+	if assertion.Not {
+		parts = append(parts, "not")
+	}
+	parts = append(parts, assertion.Type)
+	if assertion.Value != "" {
+		parts = append(parts, assertion.Value)
+	} else if len(assertion.Values) > 0 {
+		parts = append(parts, strings.Join(assertion.Values, ", "))
+	}
+	return strings.Join(parts, " ")
+}
+
+// jsValue encodes a value as a JavaScript literal
+func jsValue(value interface{}) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode %v: %w", value, err)
+	}
+	return string(data), nil
+}
+
+// mustJS encodes a string as a JavaScript string literal
+func mustJS(value string) string {
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+// sortedKeys returns the keys of a map, sorted
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// helpers are the functions of the script substituting variables, reading
+// responses and evaluating assertions the way swagger-to-http does
+const helpers = `function withEnv(values) {
+  const vars = Object.assign({}, values);
+  for (const name of Object.keys(__ENV)) {
+    vars[name] = __ENV[name];
+  }
+  return vars;
+}
+
+function fill(text, vars) {
+  return text.replace(/\{\{\s*([^}\s]+)\s*\}\}/g, (match, name) => (vars[name] !== undefined ? String(vars[name]) : match));
+}
+
+function fillParams(params, vars) {
+  for (const name of Object.keys(params.headers)) {
+    params.headers[name] = fill(params.headers[name], vars);
+  }
+  return params;
+}
+
+function header(r, name) {
+  const wanted = name.toLowerCase();
+  for (const key of Object.keys(r.headers)) {
+    if (key.toLowerCase() === wanted) {
+      return r.headers[key];
+    }
+  }
+  return undefined;
+}
+
+function jsonPath(body, path) {
+  let current;
+  try {
+    current = JSON.parse(body);
+  } catch (e) {
+    return undefined;
+  }
+  for (const segment of path.replace(/^\.+|\.+$/g, '').split('.')) {
+    const indexed = segment.match(/^(.*)\[(\d+)\]$/);
+    const name = indexed ? indexed[1] : segment;
+    if (current === null || typeof current !== 'object' || !(name in current)) {
+      return undefined;
+    }
+    current = current[name];
+    if (indexed) {
+      if (!Array.isArray(current) || Number(indexed[2]) >= current.length) {
+        return undefined;
+      }
+      current = current[Number(indexed[2])];
+    }
+  }
+  return typeof current === 'object' && current !== null ? JSON.stringify(current) : String(current);
+}
+
+function value(r, source, path) {
+  switch (source.toLowerCase()) {
+    case 'body':
+      return path ? jsonPath(r.body, path) : r.body;
+    case 'header':
+      return header(r, path);
+    case 'status':
+      return String(r.status);
+    case 'contenttype':
+      return header(r, 'Content-Type');
+  }
+  return undefined;
+}
+
+function extract(r, extraction) {
+  let found = value(r, extraction.source, extraction.path);
+  if (found !== undefined && extraction.regexp) {
+    const match = found.match(new RegExp(extraction.regexp));
+    found = match ? (match.length > 1 ? match[1] : match[0]) : undefined;
+  }
+  return found !== undefined ? found : extraction.default || '';
+}
+
+function assert(r, assertion) {
+  const actual = value(r, assertion.source, assertion.path || '');
+  const text = actual === undefined ? '' : actual;
+  const expected = assertion.value || '';
+  let passed;
+  switch (assertion.type.toLowerCase()) {
+    case 'equals':
+      passed = text.toLowerCase() === expected.toLowerCase();
+      break;
+    case 'contains':
+      passed = assertion.ignoreCase ? text.toLowerCase().includes(expected.toLowerCase()) : text.includes(expected);
+      break;
+    case 'matches':
+      passed = new RegExp(expected).test(text);
+      break;
+    case 'exists':
+      passed = text !== '';
+      break;
+    case 'notexists':
+      return text === '';
+    case 'in':
+      passed = (assertion.values || []).includes(text);
+      break;
+    case 'lessthan':
+    case 'lt':
+      passed = Number(text) < Number(expected);
+      break;
+    case 'greaterthan':
+    case 'gt':
+      passed = Number(text) > Number(expected);
+      break;
+    default:
+      return false;
+  }
+  return passed !== Boolean(assertion.not);
+}
+`
@@ -0,0 +1,92 @@
+package k6
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	sequence := &models.TestSequence{
+		Name:      "pet lifecycle",
+		Variables: map[string]string{"petName": "Rex"},
+		Steps: []models.TestStep{
+			{
+				Name:           "createPet",
+				Request:        &models.HTTPRequest{Method: "post", URL: "{{baseUrl}}/pets", Headers: map[string]string{"Content-Type": "application/json"}, Body: `{"name": "{{petName}}"}`},
+				ExpectedStatus: 201,
+				Assertions: []models.TestAssertion{
+					{Type: "equals", Source: "body", Path: "name", Value: "Rex"},
+					{Type: "approx", Source: "body", Path: "weight", Value: "4.2"},
+				},
+				Variables: []models.VariableExtraction{{Name: "petId", Source: "body", Path: "id"}},
+			},
+			{Name: "skipped", Request: &models.HTTPRequest{Method: "GET", URL: "{{baseUrl}}/skipped"}, Skip: true},
+			{Name: "getPet", Request: &models.HTTPRequest{Method: "GET", URL: "{{baseUrl}}/pets/{{petId}}", ExpectStatus: "2xx"}},
+		},
+	}
+	groups := []Group{
+		{Name: "health.http", Requests: []Request{FromHTTPRequest(models.HTTPRequest{
+			Name:          "health",
+			Method:        "GET",
+			URL:           "{{baseUrl}}/health",
+			ExpectHeaders: []models.ExpectedHeader{{Name: "X-Request-Id", Value: "<<uuid>>"}, {Name: "Cache-Control", Value: "no-store"}},
+		})}},
+		FromSequence(sequence),
+	}
+
+	script, err := Render(groups, Options{VUs: 10, Duration: "30s", Variables: map[string]string{"baseUrl": "https://api.example.com"}, Source: "http-requests"})
+	require.NoError(t, err)
+
+	assert.Contains(t, script, "// Load test exported by swagger-to-http from http-requests\n")
+	assert.Contains(t, script, "export const options = {\n  \"duration\": \"30s\",\n  \"vus\": 10\n};")
+	assert.Contains(t, script, "const defaults = {\n  \"baseUrl\": \"https://api.example.com\"\n};")
+
+	// Requests of HTTP files check their expected status and headers
+	assert.Contains(t, script, `res = http.request("GET", fill("{{baseUrl}}/health", vars), null, fillParams({"headers":{},"tags":{"name":"health"}}, vars));`)
+	assert.Contains(t, script, `"health: status is not an error": (r) => r.status < 400,`)
+	assert.Contains(t, script, `"health: has header X-Request-Id": (r) => header(r, "X-Request-Id") !== undefined,`)
+	assert.Contains(t, script, `"health: header Cache-Control is no-store": (r) => header(r, "Cache-Control") === "no-store",`)
+
+	// Sequences set their variables, check their assertions and extract variables
+	assert.Contains(t, script, `vars["petName"] = fill("Rex", vars);`)
+	assert.Contains(t, script, `res = http.request("POST", fill("{{baseUrl}}/pets", vars), fill("{\"name\": \"{{petName}}\"}", vars),`)
+	assert.Contains(t, script, `"createPet: status is 201": (r) => r.status === 201,`)
+	assert.Contains(t, script, `"createPet: body name equals Rex": (r) => assert(r, {"type":"equals","source":"body","path":"name","value":"Rex"}),`)
+	assert.Contains(t, script, "// Not exported: approx assertion on body weight\n")
+	assert.Contains(t, script, `vars["petId"] = extract(res, {"name":"petId","source":"body","path":"id"});`)
+	assert.Contains(t, script, `"getPet: status is 2xx": (r) => Math.floor(r.status / 100) === 2,`)
+	assert.NotContains(t, script, "/skipped")
+
+	assert.Contains(t, script, "function assert(r, assertion) {")
+}
+
+func TestRenderIterations(t *testing.T) {
+	script, err := Render(nil, Options{VUs: 5})
+	require.NoError(t, err)
+	assert.Contains(t, script, "export const options = {\n  \"iterations\": 5,\n  \"vus\": 5\n};")
+	assert.Contains(t, script, "const defaults = {};")
+}
+
+func TestWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "load", "script.js")
+	require.NoError(t, Write(path, nil, Options{}))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "import http from 'k6/http';")
+}
+
+func TestFromHTTPRequestBodyInclude(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pet.json"), []byte(`{"name": "{{petName}}"}`), 0644))
+
+	request := FromHTTPRequest(models.HTTPRequest{Method: "POST", URL: "{{baseUrl}}/pets", Body: "<@ pet.json", Path: filepath.Join(dir, "pets.http")})
+	assert.Equal(t, `{"name": "{{petName}}"}`, request.Body)
+
+	request = FromHTTPRequest(models.HTTPRequest{Method: "POST", URL: "{{baseUrl}}/pets", Body: "< missing.json", Path: filepath.Join(dir, "pets.http")})
+	assert.Equal(t, "< missing.json", request.Body)
+}
@@ -0,0 +1,149 @@
+// Package monitor runs HTTP tests on a schedule and alerts webhooks when tests
+// start failing or recover.
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// DefaultStateFile is where the monitor keeps its state between runs
+const DefaultStateFile = ".swagger-to-http/monitor-state.json"
+
+// Runner runs the monitored tests
+type Runner interface {
+	RunTests(ctx context.Context, patterns []string, options models.TestRunOptions) (*models.TestReport, error)
+}
+
+// MonitorService runs tests on a schedule, tracking their health between runs
+type MonitorService struct {
+	runner           Runner
+	schedule         Schedule
+	stateFile        string
+	failureThreshold int
+	alerter          *Alerter
+	logger           *log.Logger
+	onReport         func(report *models.TestReport, events []Event)
+	trigger          <-chan struct{}
+}
+
+// MonitorOption configures a MonitorService
+type MonitorOption func(*MonitorService)
+
+// WithStateFile sets the file the state is kept in between runs
+func WithStateFile(path string) MonitorOption {
+	return func(s *MonitorService) {
+		s.stateFile = path
+	}
+}
+
+// WithFailureThreshold sets how many consecutive failures mark a test as failing
+func WithFailureThreshold(threshold int) MonitorOption {
+	return func(s *MonitorService) {
+		s.failureThreshold = threshold
+	}
+}
+
+// WithAlerter sets the alerter notified of health changes
+func WithAlerter(alerter *Alerter) MonitorOption {
+	return func(s *MonitorService) {
+		s.alerter = alerter
+	}
+}
+
+// WithReportHandler sets a function receiving the report and events of every run
+func WithReportHandler(handler func(report *models.TestReport, events []Event)) MonitorOption {
+	return func(s *MonitorService) {
+		s.onReport = handler
+	}
+}
+
+// WithTrigger sets a channel that starts a run immediately, outside of the schedule
+func WithTrigger(trigger <-chan struct{}) MonitorOption {
+	return func(s *MonitorService) {
+		s.trigger = trigger
+	}
+}
+
+// NewMonitorService creates a new MonitorService
+func NewMonitorService(runner Runner, schedule Schedule, options ...MonitorOption) *MonitorService {
+	s := &MonitorService{
+		runner:           runner,
+		schedule:         schedule,
+		stateFile:        DefaultStateFile,
+		failureThreshold: 1,
+		logger:           log.New(os.Stdout, "[Monitor] ", log.LstdFlags),
+	}
+	for _, option := range options {
+		option(s)
+	}
+	return s
+}
+
+// Run runs the tests immediately and then on the schedule until the context is cancelled
+func (s *MonitorService) Run(ctx context.Context, patterns []string, options models.TestRunOptions) error {
+	state, err := LoadState(s.stateFile)
+	if err != nil {
+		return err
+	}
+
+	for {
+		if _, err := s.RunOnce(ctx, patterns, options, state); err != nil {
+			s.logger.Printf("Run failed: %v", err)
+		}
+
+		next := s.schedule.Next(time.Now())
+		if next.IsZero() {
+			return fmt.Errorf("schedule has no upcoming runs")
+		}
+		s.logger.Printf("Next run at %s", next.Format(time.RFC3339))
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		case <-s.trigger:
+			timer.Stop()
+			s.logger.Printf("Run triggered")
+		}
+	}
+}
+
+// RunOnce runs the tests, updates and saves the state and sends alerts for the
+// tests that started failing or recovered
+func (s *MonitorService) RunOnce(ctx context.Context, patterns []string, options models.TestRunOptions, state *State) ([]Event, error) {
+	report, err := s.runner.RunTests(ctx, patterns, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run tests: %w", err)
+	}
+
+	events := state.Update(report, s.failureThreshold, time.Now())
+	if err := state.Save(s.stateFile); err != nil {
+		s.logger.Printf("Failed to save state: %v", err)
+	}
+
+	s.logger.Printf("Run %d: %d passed, %d failed, %d errors; %d tests failing",
+		state.Runs, report.Summary.PassedTests, report.Summary.FailedTests, report.Summary.ErrorTests, state.Failing())
+	for _, event := range events {
+		s.logger.Printf("%s %s", event.Test, event.Type)
+	}
+
+	if s.onReport != nil {
+		s.onReport(report, events)
+	}
+
+	if s.alerter != nil {
+		if err := s.alerter.Send(ctx, events); err != nil {
+			return events, err
+		}
+	}
+
+	return events, nil
+}
@@ -0,0 +1,112 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSchedule(t *testing.T) {
+	start := time.Date(2024, 3, 15, 10, 7, 30, 0, time.UTC) // Friday
+
+	tests := []struct {
+		spec     string
+		expected time.Time
+	}{
+		{"@every 5m", start.Add(5 * time.Minute)},
+		{"90s", start.Add(90 * time.Second)},
+		{"*/15 * * * *", time.Date(2024, 3, 15, 10, 15, 0, 0, time.UTC)},
+		{"0 9-17 * * 1-5", time.Date(2024, 3, 15, 11, 0, 0, 0, time.UTC)},
+		{"30 8 * * 1", time.Date(2024, 3, 18, 8, 30, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, 3, 17, 0, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, 3, 16, 0, 0, 0, 0, time.UTC)},
+		{"0 12 1,15 * *", time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			schedule, err := ParseSchedule(tt.spec)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, schedule.Next(start))
+		})
+	}
+
+	for _, spec := range []string{"", "* * *", "61 * * * *", "*/0 * * * *", "100ms", "soon"} {
+		_, err := ParseSchedule(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestStateUpdate(t *testing.T) {
+	state := NewState()
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+
+	run := func(status models.TestStatus) []Event {
+		now = now.Add(time.Minute)
+		report := &models.TestReport{Results: []models.TestResult{
+			{Name: "getUser", FilePath: "users.http", Status: status},
+		}}
+		return state.Update(report, 2, now)
+	}
+
+	assert.Empty(t, run(models.TestStatusPassed))
+	assert.Empty(t, run(models.TestStatusFailed), "below the failure threshold")
+
+	events := run(models.TestStatusError)
+	require.Len(t, events, 1)
+	assert.Equal(t, EventFailing, events[0].Type)
+	failingSince := events[0].Since
+
+	assert.Empty(t, run(models.TestStatusFailed), "already failing")
+	assert.Empty(t, run(models.TestStatusSkipped))
+
+	events = run(models.TestStatusPassed)
+	require.Len(t, events, 1)
+	assert.Equal(t, EventRecovered, events[0].Type)
+	assert.Equal(t, failingSince, events[0].Since)
+	assert.Equal(t, 0, state.Failing())
+
+	// The state survives a restart
+	path := filepath.Join(t.TempDir(), "state.json")
+	require.NoError(t, state.Save(path))
+	loaded, err := LoadState(path)
+	require.NoError(t, err)
+	assert.Equal(t, state.Runs, loaded.Runs)
+	assert.Equal(t, state.Tests["users.http#getUser"].LastStatus, loaded.Tests["users.http#getUser"].LastStatus)
+}
+
+func TestAlerterSend(t *testing.T) {
+	var payloads []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		payloads = append(payloads, payload)
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	events := []Event{
+		{Type: EventFailing, Test: "getUser", Status: models.TestStatusFailed, Error: "snapshot comparison failed", Since: now, Time: now},
+		{Type: EventRecovered, Test: "listUsers", Status: models.TestStatusPassed, Since: now.Add(-time.Minute), Time: now},
+	}
+
+	alerter := NewAlerter([]Webhook{
+		{URL: server.URL, Format: FormatGeneric},
+		{URL: server.URL, Format: FormatSlack},
+	}, time.Second)
+	require.NoError(t, alerter.Send(context.Background(), events))
+
+	require.Len(t, payloads, 2)
+	assert.Len(t, payloads[0]["events"], 2)
+	assert.Equal(t, ":red_circle: *getUser* is failing (failed): snapshot comparison failed\n:large_green_circle: *listUsers* recovered after 1m0s", payloads[1]["text"])
+
+	assert.Equal(t, FormatSlack, NewWebhook("https://hooks.slack.com/services/T/B/X").Format)
+}
@@ -0,0 +1,184 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// State is the monitoring state kept between runs
+type State struct {
+	Runs    int                  `json:"runs"`
+	LastRun time.Time            `json:"lastRun"`
+	Tests   map[string]TestState `json:"tests"`
+}
+
+// TestState tracks the health of a single test across runs
+type TestState struct {
+	// Failing is set once the test has failed FailureThreshold times in a row
+	Failing bool `json:"failing"`
+
+	// ConsecutiveFailures counts the failed runs since the test last passed
+	ConsecutiveFailures int `json:"consecutiveFailures"`
+
+	// Since is when the test entered its current failing or passing state
+	Since time.Time `json:"since"`
+
+	LastStatus models.TestStatus `json:"lastStatus"`
+	LastError  string            `json:"lastError,omitempty"`
+}
+
+// EventType identifies a change in the health of a test
+type EventType string
+
+const (
+	// EventFailing is raised when a test starts failing
+	EventFailing EventType = "failing"
+
+	// EventRecovered is raised when a failing test passes again
+	EventRecovered EventType = "recovered"
+)
+
+// Event is a change in the health of a test
+type Event struct {
+	Type     EventType         `json:"event"`
+	Test     string            `json:"test"`
+	FilePath string            `json:"filePath,omitempty"`
+	Status   models.TestStatus `json:"status"`
+	Error    string            `json:"error,omitempty"`
+
+	// Since is when the test started failing; for recoveries it gives the outage duration
+	Since time.Time `json:"since"`
+	Time  time.Time `json:"time"`
+}
+
+// NewState creates an empty state
+func NewState() *State {
+	return &State{Tests: make(map[string]TestState)}
+}
+
+// LoadState reads the state from a file, returning an empty state when the file doesn't exist
+func LoadState(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return NewState(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read monitor state: %w", err)
+	}
+
+	state := NewState()
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse monitor state %s: %w", path, err)
+	}
+	if state.Tests == nil {
+		state.Tests = make(map[string]TestState)
+	}
+	return state, nil
+}
+
+// Save writes the state to a file
+func (s *State) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode monitor state: %w", err)
+	}
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create directory for monitor state: %w", err)
+		}
+	}
+
+	// Write through a temporary file so an interrupted run doesn't corrupt the state
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write monitor state: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// Update records the results of a run and returns the tests that started failing
+// after failureThreshold consecutive failures or recovered. Skipped tests don't
+// change state.
+func (s *State) Update(report *models.TestReport, failureThreshold int, now time.Time) []Event {
+	if failureThreshold < 1 {
+		failureThreshold = 1
+	}
+
+	s.Runs++
+	s.LastRun = now
+
+	var events []Event
+	for _, result := range report.Results {
+		if result.Status == models.TestStatusSkipped {
+			continue
+		}
+
+		key := testKey(result)
+		test, known := s.Tests[key]
+		if !known {
+			test.Since = now
+		}
+		test.LastStatus = result.Status
+		test.LastError = result.Error
+
+		failed := result.Status == models.TestStatusFailed || result.Status == models.TestStatusError || result.Status == models.TestStatusCircuitOpen
+		switch {
+		case failed:
+			test.ConsecutiveFailures++
+			if !test.Failing && test.ConsecutiveFailures >= failureThreshold {
+				test.Failing = true
+				test.Since = now
+				events = append(events, newEvent(EventFailing, result, test.Since, now))
+			}
+		case test.Failing:
+			events = append(events, newEvent(EventRecovered, result, test.Since, now))
+			test.Failing = false
+			test.ConsecutiveFailures = 0
+			test.Since = now
+		default:
+			test.ConsecutiveFailures = 0
+		}
+
+		s.Tests[key] = test
+	}
+
+	return events
+}
+
+// Failing returns the number of tests currently failing
+func (s *State) Failing() int {
+	count := 0
+	for _, test := range s.Tests {
+		if test.Failing {
+			count++
+		}
+	}
+	return count
+}
+
+// testKey identifies a test across runs
+func testKey(result models.TestResult) string {
+	if result.FilePath == "" {
+		return result.Name
+	}
+	return result.FilePath + "#" + result.Name
+}
+
+// newEvent creates an event for a test result
+func newEvent(eventType EventType, result models.TestResult, since, now time.Time) Event {
+	return Event{
+		Type:     eventType,
+		Test:     result.Name,
+		FilePath: result.FilePath,
+		Status:   result.Status,
+		Error:    result.Error,
+		Since:    since,
+		Time:     now,
+	}
+}
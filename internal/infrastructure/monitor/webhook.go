@@ -0,0 +1,120 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Webhook payload formats
+const (
+	FormatGeneric = "generic"
+	FormatSlack   = "slack"
+)
+
+// Webhook is an HTTP endpoint notified of health changes
+type Webhook struct {
+	URL    string
+	Format string
+}
+
+// NewWebhook creates a webhook, using the Slack format for Slack incoming webhook URLs
+func NewWebhook(url string) Webhook {
+	format := FormatGeneric
+	if strings.Contains(url, "hooks.slack.com") {
+		format = FormatSlack
+	}
+	return Webhook{URL: url, Format: format}
+}
+
+// Alerter posts health change events to webhooks
+type Alerter struct {
+	client   *http.Client
+	webhooks []Webhook
+}
+
+// NewAlerter creates an Alerter for the webhooks
+func NewAlerter(webhooks []Webhook, timeout time.Duration) *Alerter {
+	return &Alerter{
+		client:   &http.Client{Timeout: timeout},
+		webhooks: webhooks,
+	}
+}
+
+// Send posts the events to every webhook, returning the errors of the webhooks that failed
+func (a *Alerter) Send(ctx context.Context, events []Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	var errs []string
+	for _, webhook := range a.webhooks {
+		if err := a.post(ctx, webhook, events); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to send alerts: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// post sends the events to a single webhook
+func (a *Alerter) post(ctx context.Context, webhook Webhook, events []Event) error {
+	var payload interface{}
+	if webhook.Format == FormatSlack {
+		payload = map[string]string{"text": slackText(events)}
+	} else {
+		payload = map[string]interface{}{
+			"source": "swagger-to-http",
+			"events": events,
+		}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook %s: %w", webhook.URL, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s: unexpected status %s", webhook.URL, resp.Status)
+	}
+	return nil
+}
+
+// slackText formats events as a Slack message
+func slackText(events []Event) string {
+	var lines []string
+	for _, event := range events {
+		switch event.Type {
+		case EventFailing:
+			line := fmt.Sprintf(":red_circle: *%s* is failing (%s)", event.Test, event.Status)
+			if event.Error != "" {
+				line += ": " + event.Error
+			}
+			lines = append(lines, line)
+		case EventRecovered:
+			lines = append(lines, fmt.Sprintf(":large_green_circle: *%s* recovered after %s",
+				event.Test, event.Time.Sub(event.Since).Round(time.Second)))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
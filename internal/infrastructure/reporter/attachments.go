@@ -0,0 +1,173 @@
+package reporter
+
+import (
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// attachmentNamePattern matches the characters replaced in attachment names
+var attachmentNamePattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// AttachmentsDir returns the directory the attachments of a report are saved
+// to: a directory next to the report, named after it
+func AttachmentsDir(reportPath string) string {
+	return strings.TrimSuffix(reportPath, filepath.Ext(reportPath)) + "-attachments"
+}
+
+// attachmentWriter saves the bodies of a report above a size threshold as
+// files of its attachments directory
+type attachmentWriter struct {
+	dir      string // Attachments directory
+	relDir   string // Attachments directory relative to the report
+	maxBytes int
+	created  bool
+	names    map[string]int
+}
+
+// externalizeBodies returns a copy of a report whose request and response
+// bodies larger than options.InlineMaxBytes are written to the attachments
+// directory of options.OutputPath and replaced by a reference to their file.
+// The report given is left untouched.
+func externalizeBodies(report *models.TestReport, options models.TestReportOptions) (*models.TestReport, error) {
+	dir := AttachmentsDir(options.OutputPath)
+	w := &attachmentWriter{
+		dir:      dir,
+		relDir:   filepath.Base(dir),
+		maxBytes: options.InlineMaxBytes,
+		names:    make(map[string]int),
+	}
+
+	// Stale attachments of a previous report would be mistaken for current ones
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, fmt.Errorf("failed to clear attachments directory: %w", err)
+	}
+
+	reportCopy := *report
+	reportCopy.Results = make([]models.TestResult, len(report.Results))
+	for i, result := range report.Results {
+		var err error
+		if result.Request, err = w.request(result.Name, result.Request); err != nil {
+			return nil, err
+		}
+		if result.Response, err = w.response(result.Name+" response", result.Response); err != nil {
+			return nil, err
+		}
+		if result.RawResponse, err = w.response(result.Name+" raw response", result.RawResponse); err != nil {
+			return nil, err
+		}
+		reportCopy.Results[i] = result
+	}
+
+	if report.Sequences != nil {
+		reportCopy.Sequences = make([]models.TestSequenceResult, len(report.Sequences))
+		for i, sequence := range report.Sequences {
+			steps := make([]models.TestSequenceStepResult, len(sequence.StepResults))
+			for j, step := range sequence.StepResults {
+				var err error
+				if step.Response, err = w.response(sequence.Name+" "+step.Name+" response", step.Response); err != nil {
+					return nil, err
+				}
+				steps[j] = step
+			}
+			sequence.StepResults = steps
+			reportCopy.Sequences[i] = sequence
+		}
+	}
+
+	return &reportCopy, nil
+}
+
+// request returns the request, or a copy referencing its attached body when
+// the body is too large
+func (w *attachmentWriter) request(name string, request *models.HTTPRequest) (*models.HTTPRequest, error) {
+	if request == nil || len(request.Body) <= w.maxBytes {
+		return request, nil
+	}
+
+	var contentType string
+	for header, value := range request.Headers {
+		if strings.EqualFold(header, "Content-Type") {
+			contentType = value
+		}
+	}
+
+	path, err := w.write(name+" request", contentType, request.Body)
+	if err != nil {
+		return nil, err
+	}
+	requestCopy := *request
+	requestCopy.Body = ""
+	requestCopy.BodyFile = path
+	return &requestCopy, nil
+}
+
+// response returns the response, or a copy referencing its attached body when
+// the body is too large
+func (w *attachmentWriter) response(name string, response *models.HTTPResponse) (*models.HTTPResponse, error) {
+	if response == nil || len(response.Body) <= w.maxBytes {
+		return response, nil
+	}
+
+	contentType := response.ContentType
+	for header, values := range response.Headers {
+		if contentType == "" && strings.EqualFold(header, "Content-Type") && len(values) > 0 {
+			contentType = values[0]
+		}
+	}
+
+	path, err := w.write(name, contentType, string(response.Body))
+	if err != nil {
+		return nil, err
+	}
+	responseCopy := *response
+	responseCopy.Body = ""
+	responseCopy.BodyFile = path
+	return &responseCopy, nil
+}
+
+// write saves a body as an attachment and returns its path relative to the report
+func (w *attachmentWriter) write(name, contentType, body string) (string, error) {
+	if !w.created {
+		if err := os.MkdirAll(w.dir, 0755); err != nil {
+			return "", fmt.Errorf("failed to create attachments directory: %w", err)
+		}
+		w.created = true
+	}
+
+	base := strings.Trim(attachmentNamePattern.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if base == "" {
+		base = "body"
+	}
+	w.names[base]++
+	if w.names[base] > 1 {
+		base = fmt.Sprintf("%s-%d", base, w.names[base])
+	}
+	fileName := base + attachmentExtension(contentType)
+
+	if err := os.WriteFile(filepath.Join(w.dir, fileName), []byte(body), 0644); err != nil {
+		return "", fmt.Errorf("failed to write attachment %s: %w", fileName, err)
+	}
+	return filepath.ToSlash(filepath.Join(w.relDir, fileName)), nil
+}
+
+// attachmentExtension returns the file extension of a body from its content type
+func attachmentExtension(contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case strings.Contains(mediaType, "json"):
+		return ".json"
+	case strings.Contains(mediaType, "xml"):
+		return ".xml"
+	case strings.Contains(mediaType, "html"):
+		return ".html"
+	case strings.HasPrefix(mediaType, "text/"):
+		return ".txt"
+	}
+	return ".bin"
+}
@@ -0,0 +1,68 @@
+package reporter
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveReportAttachments(t *testing.T) {
+	large := `{"items":"` + strings.Repeat("x", 100) + `"}`
+	report := &models.TestReport{
+		Name: "attachments",
+		Results: []models.TestResult{
+			{
+				Name:     "listUsers",
+				Request:  &models.HTTPRequest{Method: "GET", URL: "/users"},
+				Response: &models.HTTPResponse{StatusCode: 200, ContentType: "application/json", Body: large},
+			},
+			{
+				Name:     "getUser",
+				Request:  &models.HTTPRequest{Method: "GET", URL: "/users/1"},
+				Response: &models.HTTPResponse{StatusCode: 200, Body: `{"id":1}`},
+			},
+		},
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "report.json")
+	options := models.TestReportOptions{
+		Format:           "json",
+		OutputPath:       outputPath,
+		IncludeRequests:  true,
+		IncludeResponses: true,
+		InlineMaxBytes:   64,
+	}
+	require.NoError(t, NewTestReporterService().SaveReport(context.Background(), report, options))
+
+	data, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	var saved models.TestReport
+	require.NoError(t, json.Unmarshal(data, &saved))
+
+	// Large bodies are referenced by a path relative to the report
+	assert.Empty(t, saved.Results[0].Response.Body)
+	assert.Equal(t, "report-attachments/listusers-response.json", saved.Results[0].Response.BodyFile)
+	attachment, err := os.ReadFile(filepath.Join(filepath.Dir(outputPath), saved.Results[0].Response.BodyFile))
+	require.NoError(t, err)
+	assert.Equal(t, large, string(attachment))
+
+	// Small bodies stay inline
+	assert.Equal(t, `{"id":1}`, saved.Results[1].Response.Body)
+	assert.Empty(t, saved.Results[1].Response.BodyFile)
+
+	// The report given is left untouched
+	assert.Equal(t, large, report.Results[0].Response.Body)
+	assert.Empty(t, report.Results[0].Response.BodyFile)
+}
+
+func TestAttachmentsDir(t *testing.T) {
+	assert.Equal(t, filepath.Join("out", "report-attachments"), AttachmentsDir(filepath.Join("out", "report.html")))
+	assert.Equal(t, "results-attachments", AttachmentsDir("results"))
+}
@@ -0,0 +1,314 @@
+package reporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// maxCheckAnnotations is the number of annotations the GitHub API accepts per request
+const maxCheckAnnotations = 50
+
+// IsGitHubActions reports whether the tool runs inside GitHub Actions
+func IsGitHubActions() bool {
+	return os.Getenv("GITHUB_ACTIONS") == "true"
+}
+
+// generateGitHubReport writes workflow commands that annotate the failing requests
+// in their .http files, so failures show inline in pull requests
+func (s *TestReporterService) generateGitHubReport(report *models.TestReport, options models.TestReportOptions) (io.Reader, error) {
+	var buf bytes.Buffer
+
+	for _, warning := range report.Warnings {
+		fmt.Fprintf(&buf, "::warning::%s\n", escapeWorkflowData(warning))
+	}
+
+	for _, result := range report.Results {
+		properties := []string{"title=" + escapeWorkflowProperty(result.Name)}
+		if result.FilePath != "" {
+			properties = append(properties, "file="+escapeWorkflowProperty(annotationPath(result.FilePath)))
+			if line := resultLine(result); line > 0 {
+				properties = append(properties, fmt.Sprintf("line=%d", line))
+			}
+		}
+
+		if result.SchemaResult != nil && len(result.SchemaResult.Warnings) > 0 {
+			message := "Schema warnings:\n" + describeFindings(result.SchemaResult.Warnings)
+			fmt.Fprintf(&buf, "::warning %s::%s\n", strings.Join(properties, ","), escapeWorkflowData(message))
+		}
+
+		if result.Status != models.TestStatusFailed && result.Status != models.TestStatusError && result.Status != models.TestStatusCircuitOpen {
+			continue
+		}
+
+		// Known failures are flagged without failing the workflow's annotations
+		if result.Quarantined {
+			message := fmt.Sprintf("Quarantined (%s): %s", result.QuarantineReason, failureMessage(result))
+			fmt.Fprintf(&buf, "::warning %s::%s\n", strings.Join(properties, ","), escapeWorkflowData(message))
+			continue
+		}
+		fmt.Fprintf(&buf, "::error %s::%s\n", strings.Join(properties, ","), escapeWorkflowData(failureMessage(result)))
+	}
+
+	return &buf, nil
+}
+
+// failureMessage describes why a test failed, including the start of its snapshot diff
+func failureMessage(result models.TestResult) string {
+	message := result.Error
+	if message == "" {
+		message = fmt.Sprintf("test %s", result.Status)
+	}
+	if result.SnapshotResult != nil && result.SnapshotResult.Diff != nil && result.SnapshotResult.Diff.HasDiff {
+		message += "\n" + summarizeDiff(result.SnapshotResult.Diff.DiffString)
+	}
+	return message
+}
+
+// resultLine returns the line of the request in its .http file, or 0 when unknown
+func resultLine(result models.TestResult) int {
+	if result.Request == nil {
+		return 0
+	}
+	return result.Request.Line
+}
+
+// annotationPath makes a path relative to the repository root, as GitHub expects
+func annotationPath(path string) string {
+	workspace := os.Getenv("GITHUB_WORKSPACE")
+	if workspace == "" {
+		return filepath.ToSlash(path)
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	rel, err := filepath.Rel(workspace, abs)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// escapeWorkflowData escapes the message of a workflow command
+func escapeWorkflowData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeWorkflowProperty escapes a property value of a workflow command
+func escapeWorkflowProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+// GitHubCheckNotifier publishes the results of a run as a GitHub Check Run with an
+// annotation for every failed test
+type GitHubCheckNotifier struct {
+	apiURL     string
+	token      string
+	repository string
+	sha        string
+	name       string
+	client     *http.Client
+}
+
+// NewGitHubCheckNotifier creates a GitHubCheckNotifier for a commit of a repository ("owner/repo")
+func NewGitHubCheckNotifier(apiURL, token, repository, sha, name string, timeout time.Duration) *GitHubCheckNotifier {
+	if apiURL == "" {
+		apiURL = "https://api.github.com"
+	}
+	return &GitHubCheckNotifier{
+		apiURL:     strings.TrimSuffix(apiURL, "/"),
+		token:      token,
+		repository: repository,
+		sha:        sha,
+		name:       name,
+		client:     &http.Client{Timeout: timeout},
+	}
+}
+
+// NewGitHubCheckNotifierFromEnv creates a GitHubCheckNotifier for the commit being
+// built by GitHub Actions, authenticated with GITHUB_TOKEN
+func NewGitHubCheckNotifierFromEnv(name string, timeout time.Duration) (*GitHubCheckNotifier, error) {
+	token := os.Getenv("GITHUB_TOKEN")
+	repository := os.Getenv("GITHUB_REPOSITORY")
+	sha := os.Getenv("GITHUB_SHA")
+	if token == "" || repository == "" || sha == "" {
+		return nil, fmt.Errorf("creating a check run requires GITHUB_TOKEN, GITHUB_REPOSITORY and GITHUB_SHA")
+	}
+	return NewGitHubCheckNotifier(os.Getenv("GITHUB_API_URL"), token, repository, sha, name, timeout), nil
+}
+
+// checkRunAnnotation is an annotation of the GitHub Checks API
+type checkRunAnnotation struct {
+	Path            string `json:"path"`
+	StartLine       int    `json:"start_line"`
+	EndLine         int    `json:"end_line"`
+	AnnotationLevel string `json:"annotation_level"`
+	Title           string `json:"title,omitempty"`
+	Message         string `json:"message"`
+}
+
+// checkRunOutput is the output of a check run
+type checkRunOutput struct {
+	Title       string               `json:"title"`
+	Summary     string               `json:"summary"`
+	Annotations []checkRunAnnotation `json:"annotations,omitempty"`
+}
+
+// Notify creates a completed check run, adding annotations beyond the first 50 in
+// follow-up updates since the API limits them per request
+func (n *GitHubCheckNotifier) Notify(ctx context.Context, report *models.TestReport, options NotifyOptions) error {
+	conclusion := "success"
+	if report.Summary.FailedTests > 0 || report.Summary.ErrorTests > 0 {
+		conclusion = "failure"
+	}
+
+	output := checkRunOutput{
+		Title:   fmt.Sprintf("%d passed, %d failed, %d errors", report.Summary.PassedTests, report.Summary.FailedTests, report.Summary.ErrorTests),
+		Summary: checkRunSummary(report, options),
+	}
+	annotations := checkRunAnnotations(report)
+
+	first := annotations
+	if len(first) > maxCheckAnnotations {
+		first = first[:maxCheckAnnotations]
+	}
+	output.Annotations = first
+
+	payload := map[string]interface{}{
+		"name":        n.name,
+		"head_sha":    n.sha,
+		"status":      "completed",
+		"conclusion":  conclusion,
+		"output":      output,
+		"details_url": options.ReportURL,
+	}
+	if options.ReportURL == "" {
+		delete(payload, "details_url")
+	}
+
+	var created struct {
+		ID int64 `json:"id"`
+	}
+	if err := n.request(ctx, http.MethodPost, fmt.Sprintf("/repos/%s/check-runs", n.repository), payload, &created); err != nil {
+		return err
+	}
+
+	for start := maxCheckAnnotations; start < len(annotations); start += maxCheckAnnotations {
+		end := start + maxCheckAnnotations
+		if end > len(annotations) {
+			end = len(annotations)
+		}
+		output.Annotations = annotations[start:end]
+		path := fmt.Sprintf("/repos/%s/check-runs/%d", n.repository, created.ID)
+		if err := n.request(ctx, http.MethodPatch, path, map[string]interface{}{"output": output}, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// request sends a request to the GitHub API and decodes the response into result
+func (n *GitHubCheckNotifier) request(ctx context.Context, method, path string, payload, result interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode check run: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, n.apiURL+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create check run request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+n.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to publish check run: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("failed to publish check run: %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+	if result != nil {
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return fmt.Errorf("failed to decode check run response: %w", err)
+		}
+	}
+	return nil
+}
+
+// checkRunAnnotations annotates the requests of the failed tests
+func checkRunAnnotations(report *models.TestReport) []checkRunAnnotation {
+	var annotations []checkRunAnnotation
+	for _, result := range report.Results {
+		if (result.Status != models.TestStatusFailed && result.Status != models.TestStatusError && result.Status != models.TestStatusCircuitOpen) || result.FilePath == "" {
+			continue
+		}
+
+		line := resultLine(result)
+		if line == 0 {
+			line = 1
+		}
+		level := "failure"
+		if result.Quarantined {
+			level = "warning"
+		}
+		annotations = append(annotations, checkRunAnnotation{
+			Path:            annotationPath(result.FilePath),
+			StartLine:       line,
+			EndLine:         line,
+			AnnotationLevel: level,
+			Title:           result.Name,
+			Message:         failureMessage(result),
+		})
+	}
+	return annotations
+}
+
+// checkRunSummary formats the Markdown summary of a check run
+func checkRunSummary(report *models.TestReport, options NotifyOptions) string {
+	summary := NewRunSummary(report, options)
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "| Total | Passed | Failed | Errors | Skipped | Duration |\n")
+	fmt.Fprintf(&buf, "|-------|--------|--------|--------|---------|----------|\n")
+	fmt.Fprintf(&buf, "| %d | %d | %d | %d | %d | %s |\n",
+		report.Summary.TotalTests, report.Summary.PassedTests, report.Summary.FailedTests,
+		report.Summary.ErrorTests, report.Summary.SkippedTests,
+		(time.Duration(report.Summary.DurationMs) * time.Millisecond).String())
+
+	if len(summary.Failures) > 0 {
+		fmt.Fprintf(&buf, "\n**Failures**\n\n")
+		for _, failure := range summary.Failures {
+			name := failure.Name
+			if failure.URL != "" {
+				name = fmt.Sprintf("[%s](%s)", failure.Name, failure.URL)
+			}
+			fmt.Fprintf(&buf, "- %s (%s): %s\n", name, failure.Status, failure.Error)
+		}
+		if summary.MoreFailures > 0 {
+			fmt.Fprintf(&buf, "- …and %d more\n", summary.MoreFailures)
+		}
+	}
+
+	if options.ReportURL != "" {
+		fmt.Fprintf(&buf, "\n[View the full report](%s)\n", options.ReportURL)
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,87 @@
+package reporter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateGitHubReport(t *testing.T) {
+	t.Setenv("GITHUB_WORKSPACE", "")
+
+	report := &models.TestReport{
+		Warnings: []string{"users.http: generated by 2.0.0"},
+		Results: []models.TestResult{
+			{Name: "listUsers", FilePath: "http/users.http", Status: models.TestStatusPassed},
+			{
+				Name:     "getUser, by id",
+				FilePath: "http/users.http",
+				Request:  &models.HTTPRequest{Line: 12},
+				Status:   models.TestStatusFailed,
+				Error:    "snapshot comparison failed",
+				SnapshotResult: &models.SnapshotResult{Diff: &models.SnapshotDiff{
+					HasDiff:    true,
+					DiffString: "Status code mismatch: expected 200, got 500",
+				}},
+			},
+			{Name: "createUser", FilePath: "http/users.http", Status: models.TestStatusError, Error: "100% broken"},
+		},
+	}
+
+	reader, err := NewTestReporterService().GenerateReport(context.Background(), report, models.TestReportOptions{Format: "github"})
+	require.NoError(t, err)
+	output, err := io.ReadAll(reader)
+	require.NoError(t, err)
+
+	assert.Equal(t, "::warning::users.http: generated by 2.0.0\n"+
+		"::error title=getUser%2C by id,file=http/users.http,line=12::snapshot comparison failed%0AStatus code mismatch: expected 200, got 500\n"+
+		"::error title=createUser,file=http/users.http::100%25 broken\n", string(output))
+}
+
+func TestGitHubCheckNotifier(t *testing.T) {
+	var requests []string
+	var annotations int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		requests = append(requests, r.Method+" "+r.URL.Path)
+
+		var payload struct {
+			HeadSHA    string         `json:"head_sha"`
+			Conclusion string         `json:"conclusion"`
+			Output     checkRunOutput `json:"output"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		annotations += len(payload.Output.Annotations)
+		if r.Method == http.MethodPost {
+			assert.Equal(t, "abc123", payload.HeadSHA)
+			assert.Equal(t, "failure", payload.Conclusion)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"id": 42}`)
+		}
+	}))
+	defer server.Close()
+
+	report := &models.TestReport{Summary: models.TestSummary{FailedTests: 60}}
+	for i := 0; i < 60; i++ {
+		report.Results = append(report.Results, models.TestResult{
+			Name:     fmt.Sprintf("test%d", i),
+			FilePath: "http/users.http",
+			Status:   models.TestStatusFailed,
+		})
+	}
+
+	notifier := NewGitHubCheckNotifier(server.URL, "secret", "owner/repo", "abc123", "API tests", time.Second)
+	require.NoError(t, notifier.Notify(context.Background(), report, NotifyOptions{}))
+
+	assert.Equal(t, []string{"POST /repos/owner/repo/check-runs", "PATCH /repos/owner/repo/check-runs/42"}, requests)
+	assert.Equal(t, 60, annotations)
+}
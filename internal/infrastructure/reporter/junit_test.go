@@ -0,0 +1,99 @@
+package reporter
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateJUnitReport(t *testing.T) {
+	report := &models.TestReport{
+		Name:    "HTTP Tests",
+		Summary: models.TestSummary{TotalTests: 3, PassedTests: 1, FailedTests: 1, ErrorTests: 1},
+		Results: []models.TestResult{
+			{
+				Name:     "listUsers",
+				FilePath: "http/users.http",
+				Tags:     []string{"users"},
+				Request:  &models.HTTPRequest{Method: "GET", URL: "https://api.example.com/users", Line: 3},
+				Response: &models.HTTPResponse{StatusCode: 200, Status: "200 OK"},
+				Duration: 120 * time.Millisecond,
+				Status:   models.TestStatusPassed,
+			},
+			{
+				Name:     "listOrders",
+				FilePath: "http/orders.http",
+				Tags:     []string{"orders"},
+				Response: &models.HTTPResponse{StatusCode: 500, Status: "500 Internal Server Error", Body: `{"error":"boom"}`},
+				Status:   models.TestStatusFailed,
+				Error:    "snapshot comparison failed",
+			},
+			{Name: "getUser", FilePath: "http/users.http", Tags: []string{"users"}, Status: models.TestStatusError, Error: "connection refused"},
+		},
+	}
+
+	decode := func(groupBy string) junitTestSuites {
+		reader, err := NewTestReporterService().GenerateReport(context.Background(), report, models.TestReportOptions{Format: "junit", JUnitGroupBy: groupBy})
+		require.NoError(t, err)
+		data, err := io.ReadAll(reader)
+		require.NoError(t, err)
+
+		var suites junitTestSuites
+		require.NoError(t, xml.Unmarshal(data, &suites))
+		return suites
+	}
+
+	suites := decode(JUnitGroupByFile)
+	require.Len(t, suites.TestSuites, 2)
+	users := suites.TestSuites[0]
+	assert.Equal(t, "http/users.http", users.Name)
+	assert.Equal(t, 2, users.Tests)
+	assert.Equal(t, 1, users.Errors)
+	assert.Equal(t, 3, users.TestCases[0].Line)
+	assert.Equal(t, "http/users", users.TestCases[0].Classname)
+	assert.Equal(t, []junitProperty{
+		{Name: "method", Value: "GET"},
+		{Name: "url", Value: "https://api.example.com/users"},
+		{Name: "status_code", Value: "200"},
+		{Name: "duration_ms", Value: "120"},
+	}, users.TestCases[0].Properties.Properties)
+	assert.Equal(t, "connection refused", users.TestCases[1].Error.Message)
+
+	orders := suites.TestSuites[1]
+	assert.Equal(t, 1, orders.Failures)
+	assert.Equal(t, "500 Internal Server Error\n\n{\"error\":\"boom\"}", orders.TestCases[0].SystemOut)
+
+	assert.Len(t, decode(JUnitGroupByTag).TestSuites, 2)
+	assert.Len(t, decode(JUnitGroupByNone).TestSuites, 1)
+}
+
+func TestJUnitReportSkipReason(t *testing.T) {
+	report := &models.TestReport{
+		Name:    "HTTP Tests",
+		Summary: models.TestSummary{TotalTests: 2, SkippedTests: 2},
+		Results: []models.TestResult{
+			{Name: "deleteUser", FilePath: "users.http", Status: models.TestStatusSkipped, Error: "JIRA-7 endpoint disabled"},
+			{Name: "listUsers", FilePath: "users.http", Status: models.TestStatusSkipped, Error: models.SkipReasonNotFocused},
+		},
+	}
+
+	reader, err := NewTestReporterService().GenerateReport(context.Background(), report, models.TestReportOptions{Format: "junit"})
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(data), `<skipped message="JIRA-7 endpoint disabled"></skipped>`)
+	assert.Contains(t, string(data), `<skipped message="not focused"></skipped>`)
+
+	reader, err = NewTestReporterService().GenerateReport(context.Background(), report, models.TestReportOptions{Format: "console"})
+	require.NoError(t, err)
+	data, err = io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "     Reason: JIRA-7 endpoint disabled\n")
+}
@@ -0,0 +1,231 @@
+package reporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// Notification payload formats
+const (
+	NotifyFormatGeneric = "generic"
+	NotifyFormatSlack   = "slack"
+)
+
+// DefaultMaxFailures is the number of failures listed in notifications by default
+const DefaultMaxFailures = 5
+
+// Notifier sends the summary of a test run to an external service
+type Notifier interface {
+	// Notify sends the summary of the report
+	Notify(ctx context.Context, report *models.TestReport, options NotifyOptions) error
+}
+
+// NotifyOptions configures the content of notifications
+type NotifyOptions struct {
+	// ReportURL links to the published HTML report; failures link to their entry in it
+	ReportURL string
+
+	// RunURL links to the CI run that produced the report
+	RunURL string
+
+	// MaxFailures limits the failures listed in the notification
+	MaxFailures int
+}
+
+// RunSummary is the end-of-run summary posted by the generic webhook notifier
+type RunSummary struct {
+	Name         string             `json:"name"`
+	Status       models.TestStatus  `json:"status"`
+	Summary      models.TestSummary `json:"summary"`
+	ReportURL    string             `json:"reportUrl,omitempty"`
+	RunURL       string             `json:"runUrl,omitempty"`
+	Failures     []FailureSummary   `json:"failures"`
+	MoreFailures int                `json:"moreFailures,omitempty"`
+}
+
+// FailureSummary describes a failed test in a notification
+type FailureSummary struct {
+	Name     string            `json:"name"`
+	FilePath string            `json:"filePath,omitempty"`
+	Status   models.TestStatus `json:"status"`
+	Error    string            `json:"error,omitempty"`
+	URL      string            `json:"url,omitempty"`
+}
+
+// NewRunSummary builds the summary of a report, listing up to MaxFailures failures
+func NewRunSummary(report *models.TestReport, options NotifyOptions) RunSummary {
+	maxFailures := options.MaxFailures
+	if maxFailures <= 0 {
+		maxFailures = DefaultMaxFailures
+	}
+
+	summary := RunSummary{
+		Name:      report.Name,
+		Status:    models.TestStatusPassed,
+		Summary:   report.Summary,
+		ReportURL: options.ReportURL,
+		RunURL:    options.RunURL,
+		Failures:  []FailureSummary{},
+	}
+
+	for i, result := range report.Results {
+		if result.Quarantined || (result.Status != models.TestStatusFailed && result.Status != models.TestStatusError && result.Status != models.TestStatusCircuitOpen) {
+			continue
+		}
+		summary.Status = models.TestStatusFailed
+
+		if len(summary.Failures) == maxFailures {
+			summary.MoreFailures++
+			continue
+		}
+
+		failure := FailureSummary{
+			Name:     result.Name,
+			FilePath: result.FilePath,
+			Status:   result.Status,
+			Error:    result.Error,
+		}
+		// The HTML report anchors each result by its position
+		if options.ReportURL != "" {
+			failure.URL = fmt.Sprintf("%s#test-%d", options.ReportURL, i)
+		}
+		summary.Failures = append(summary.Failures, failure)
+	}
+
+	return summary
+}
+
+// WebhookNotifier posts the run summary as JSON to a webhook
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookNotifier creates a new WebhookNotifier
+func NewWebhookNotifier(url string, timeout time.Duration) *WebhookNotifier {
+	return &WebhookNotifier{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+// Notify posts the run summary to the webhook
+func (n *WebhookNotifier) Notify(ctx context.Context, report *models.TestReport, options NotifyOptions) error {
+	return postJSON(ctx, n.client, n.url, NewRunSummary(report, options))
+}
+
+// SlackNotifier posts the run summary as a message to a Slack incoming webhook
+type SlackNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewSlackNotifier creates a new SlackNotifier
+func NewSlackNotifier(url string, timeout time.Duration) *SlackNotifier {
+	return &SlackNotifier{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+// Notify posts the run summary to Slack
+func (n *SlackNotifier) Notify(ctx context.Context, report *models.TestReport, options NotifyOptions) error {
+	return postJSON(ctx, n.client, n.url, map[string]string{
+		"text": slackMessage(NewRunSummary(report, options)),
+	})
+}
+
+// NewNotifier creates a notifier for a webhook URL in the given format. An empty
+// format uses Slack for Slack incoming webhook URLs and the generic format otherwise.
+func NewNotifier(url, format string, timeout time.Duration) (Notifier, error) {
+	if format == "" || format == "auto" {
+		format = NotifyFormatGeneric
+		if strings.Contains(url, "hooks.slack.com") {
+			format = NotifyFormatSlack
+		}
+	}
+
+	switch format {
+	case NotifyFormatGeneric:
+		return NewWebhookNotifier(url, timeout), nil
+	case NotifyFormatSlack:
+		return NewSlackNotifier(url, timeout), nil
+	default:
+		return nil, fmt.Errorf("unknown notification format %q, expected generic or slack", format)
+	}
+}
+
+// slackMessage formats a run summary with Slack markup
+func slackMessage(summary RunSummary) string {
+	var buf strings.Builder
+
+	icon, outcome := ":white_check_mark:", "passed"
+	if summary.Status != models.TestStatusPassed {
+		icon, outcome = ":x:", "failed"
+	}
+	fmt.Fprintf(&buf, "%s *%s* %s: %d passed, %d failed, %d errors, %d skipped (%d total) in %s",
+		icon, slackEscape(summary.Name), outcome,
+		summary.Summary.PassedTests, summary.Summary.FailedTests, summary.Summary.ErrorTests,
+		summary.Summary.SkippedTests, summary.Summary.TotalTests,
+		(time.Duration(summary.Summary.DurationMs) * time.Millisecond).String())
+
+	for _, failure := range summary.Failures {
+		name := slackEscape(failure.Name)
+		if failure.URL != "" {
+			name = fmt.Sprintf("<%s|%s>", failure.URL, name)
+		}
+		fmt.Fprintf(&buf, "\n• %s (%s)", name, failure.Status)
+		if failure.Error != "" {
+			fmt.Fprintf(&buf, ": %s", slackEscape(models.TruncateString(failure.Error, 200)))
+		}
+	}
+	if summary.MoreFailures > 0 {
+		fmt.Fprintf(&buf, "\n…and %d more", summary.MoreFailures)
+	}
+
+	var links []string
+	if summary.ReportURL != "" {
+		links = append(links, fmt.Sprintf("<%s|View report>", summary.ReportURL))
+	}
+	if summary.RunURL != "" {
+		links = append(links, fmt.Sprintf("<%s|View run>", summary.RunURL))
+	}
+	if len(links) > 0 {
+		fmt.Fprintf(&buf, "\n%s", strings.Join(links, " · "))
+	}
+
+	return buf.String()
+}
+
+// slackEscape escapes the characters Slack treats as markup
+func slackEscape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// postJSON posts a JSON payload and checks the response status
+func postJSON(ctx context.Context, client *http.Client, url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create notification request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("notification webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package reporter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func notifierTestReport() *models.TestReport {
+	report := &models.TestReport{
+		Name: "HTTP Tests",
+		Summary: models.TestSummary{
+			TotalTests:  8,
+			PassedTests: 1,
+			FailedTests: 6,
+			ErrorTests:  1,
+			DurationMs:  1500,
+		},
+		Results: []models.TestResult{{Name: "listUsers", Status: models.TestStatusPassed}},
+	}
+	for i := 1; i <= 6; i++ {
+		report.Results = append(report.Results, models.TestResult{
+			Name:   fmt.Sprintf("test%d", i),
+			Status: models.TestStatusFailed,
+			Error:  "snapshot comparison failed",
+		})
+	}
+	report.Results = append(report.Results, models.TestResult{Name: "<createUser>", Status: models.TestStatusError, Error: "connection refused"})
+	return report
+}
+
+func TestNewRunSummary(t *testing.T) {
+	summary := NewRunSummary(notifierTestReport(), NotifyOptions{ReportURL: "https://ci.example.com/report.html"})
+
+	assert.Equal(t, models.TestStatusFailed, summary.Status)
+	require.Len(t, summary.Failures, DefaultMaxFailures)
+	assert.Equal(t, 2, summary.MoreFailures)
+	assert.Equal(t, "https://ci.example.com/report.html#test-1", summary.Failures[0].URL)
+
+	passing := NewRunSummary(&models.TestReport{Results: []models.TestResult{{Status: models.TestStatusPassed}}}, NotifyOptions{})
+	assert.Equal(t, models.TestStatusPassed, passing.Status)
+	assert.Empty(t, passing.Failures)
+}
+
+func TestNotifiers(t *testing.T) {
+	var payloads []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		var payload map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		payloads = append(payloads, payload)
+	}))
+	defer server.Close()
+
+	options := NotifyOptions{ReportURL: "https://ci.example.com/report.html", MaxFailures: 1}
+	for _, format := range []string{NotifyFormatGeneric, NotifyFormatSlack} {
+		notifier, err := NewNotifier(server.URL, format, time.Second)
+		require.NoError(t, err)
+		require.NoError(t, notifier.Notify(context.Background(), notifierTestReport(), options))
+	}
+
+	require.Len(t, payloads, 2)
+	assert.Equal(t, "failed", payloads[0]["status"])
+	assert.Equal(t, float64(6), payloads[0]["moreFailures"])
+	assert.Equal(t, ":x: *HTTP Tests* failed: 1 passed, 6 failed, 1 errors, 0 skipped (8 total) in 1.5s\n"+
+		"• <https://ci.example.com/report.html#test-1|test1> (failed): snapshot comparison failed\n"+
+		"…and 6 more\n"+
+		"<https://ci.example.com/report.html|View report>", payloads[1]["text"])
+
+	_, err := NewNotifier(server.URL, "teams", time.Second)
+	assert.Error(t, err)
+
+	notifier, err := NewNotifier("https://hooks.slack.com/services/T/B/X", "", time.Second)
+	require.NoError(t, err)
+	assert.IsType(t, &SlackNotifier{}, notifier)
+}
+
+func TestSlackEscape(t *testing.T) {
+	assert.Equal(t, "&lt;createUser&gt; &amp; more", slackEscape("<createUser> & more"))
+}
@@ -0,0 +1,36 @@
+package reporter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/edgardnogueira/swagger-to-http/pkg/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginReport(t *testing.T) {
+	registry := plugin.NewRegistry()
+	registry.RegisterReporter("text", plugin.ReporterFunc(func(ctx context.Context, report *plugin.TestReport) ([]byte, error) {
+		return []byte(fmt.Sprintf("%s: %d passed", report.Name, report.Summary.PassedTests)), nil
+	}))
+	s := NewTestReporterService(WithPlugins(registry))
+	report := &models.TestReport{Name: "smoke", Summary: models.TestSummary{TotalTests: 1, PassedTests: 1}}
+
+	reader, err := s.GenerateReport(context.Background(), report, models.TestReportOptions{Format: "text"})
+	require.NoError(t, err)
+	output, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "smoke: 1 passed", string(output))
+
+	// Unknown formats still fall back to JSON
+	reader, err = s.GenerateReport(context.Background(), report, models.TestReportOptions{Format: "allure"})
+	require.NoError(t, err)
+	output, err = io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.True(t, json.Valid(output))
+}
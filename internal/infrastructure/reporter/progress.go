@@ -0,0 +1,127 @@
+package reporter
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// progressBarWidth is the number of cells of the progress bar
+const progressBarWidth = 30
+
+// progressRedrawInterval limits how often the progress bar is redrawn
+const progressRedrawInterval = 100 * time.Millisecond
+
+// ProgressBar draws a live progress bar with running pass/fail counts on a
+// single terminal line. It implements models.ProgressListener.
+type ProgressBar struct {
+	writer      io.Writer
+	colorOutput bool
+
+	mu       sync.Mutex
+	total    int
+	done     int
+	passed   int
+	failed   int
+	errors   int
+	skipped  int
+	lastDraw time.Time
+	now      func() time.Time
+}
+
+// NewProgressBar creates a progress bar writing to writer
+func NewProgressBar(writer io.Writer, colorOutput bool) *ProgressBar {
+	return &ProgressBar{
+		writer:      writer,
+		colorOutput: colorOutput,
+		now:         time.Now,
+	}
+}
+
+// Start resets the counters and draws an empty bar for total tests
+func (p *ProgressBar) Start(total int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.total = total
+	p.done, p.passed, p.failed, p.errors, p.skipped = 0, 0, 0, 0, 0
+	p.draw()
+}
+
+// Completed counts a finished test and redraws the bar
+func (p *ProgressBar) Completed(result models.TestResult) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.done++
+	switch result.Status {
+	case models.TestStatusPassed:
+		p.passed++
+	case models.TestStatusFailed:
+		p.failed++
+	case models.TestStatusError, models.TestStatusCircuitOpen:
+		p.errors++
+	case models.TestStatusSkipped:
+		p.skipped++
+	}
+
+	// Redraw at most every progressRedrawInterval, but always for the last test
+	if p.done < p.total && p.now().Sub(p.lastDraw) < progressRedrawInterval {
+		return
+	}
+	p.draw()
+}
+
+// Finish draws the final state of the bar and ends its line
+func (p *ProgressBar) Finish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.draw()
+	fmt.Fprintln(p.writer)
+}
+
+// draw rewrites the progress line; the caller must hold the lock
+func (p *ProgressBar) draw() {
+	p.lastDraw = p.now()
+	fmt.Fprintf(p.writer, "\r%s", p.line())
+}
+
+// line renders the progress bar and counters
+func (p *ProgressBar) line() string {
+	total := p.total
+	if total < p.done {
+		total = p.done
+	}
+
+	filled := progressBarWidth
+	percent := 100
+	if total > 0 {
+		filled = p.done * progressBarWidth / total
+		percent = p.done * 100 / total
+	}
+	bar := strings.Repeat("#", filled) + strings.Repeat("-", progressBarWidth-filled)
+
+	passed := fmt.Sprintf("%d passed", p.passed)
+	failed := fmt.Sprintf("%d failed", p.failed)
+	errors := fmt.Sprintf("%d errors", p.errors)
+	if p.colorOutput {
+		passed = "\x1b[32m" + passed + "\x1b[0m"
+		if p.failed > 0 {
+			failed = "\x1b[31m" + failed + "\x1b[0m"
+		}
+		if p.errors > 0 {
+			errors = "\x1b[35m" + errors + "\x1b[0m"
+		}
+	}
+
+	line := fmt.Sprintf("[%s] %3d%% %d/%d  %s, %s, %s", bar, percent, p.done, total, passed, failed, errors)
+	if p.skipped > 0 {
+		line += fmt.Sprintf(", %d skipped", p.skipped)
+	}
+	return line
+}
@@ -0,0 +1,62 @@
+package reporter
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgressBar(t *testing.T) {
+	var out bytes.Buffer
+	bar := NewProgressBar(&out, false)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	bar.now = func() time.Time { return now }
+
+	bar.Start(4)
+	assert.Contains(t, out.String(), "[------------------------------]   0% 0/4")
+
+	// Results within the redraw interval are counted but not drawn
+	bar.Completed(models.TestResult{Status: models.TestStatusPassed})
+	bar.Completed(models.TestResult{Status: models.TestStatusFailed})
+	assert.NotContains(t, out.String(), "2/4")
+
+	now = now.Add(time.Second)
+	bar.Completed(models.TestResult{Status: models.TestStatusError})
+	assert.Contains(t, out.String(), "\r[######################--------]  75% 3/4  1 passed, 1 failed, 1 errors")
+
+	// The last result is always drawn
+	bar.Completed(models.TestResult{Status: models.TestStatusPassed})
+	bar.Finish()
+	assert.True(t, strings.HasSuffix(out.String(), "100% 4/4  2 passed, 1 failed, 1 errors\n"))
+}
+
+func TestConsoleReportFailuresOnly(t *testing.T) {
+	report := &models.TestReport{
+		Name:    "HTTP Tests",
+		Summary: models.TestSummary{TotalTests: 3, PassedTests: 1, FailedTests: 1, ErrorTests: 1},
+		Results: []models.TestResult{
+			{Name: "listUsers", Status: models.TestStatusPassed},
+			{Name: "listOrders", Status: models.TestStatusFailed, Error: "snapshot comparison failed"},
+			{Name: "getUser", Status: models.TestStatusError, Error: "connection refused"},
+		},
+	}
+
+	reader, err := NewTestReporterService().GenerateReport(context.Background(), report,
+		models.TestReportOptions{Format: "console", FailuresOnly: true})
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	output := string(data)
+
+	assert.NotContains(t, output, "listUsers")
+	assert.Contains(t, output, "2. listOrders [failed]")
+	assert.Contains(t, output, "3. getUser [error]")
+	assert.Less(t, strings.Index(output, "FAILURES:"), strings.Index(output, "SUMMARY:"))
+}
@@ -0,0 +1,54 @@
+package reporter
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func quarantineReport() *models.TestReport {
+	return &models.TestReport{
+		Name:    "HTTP Tests",
+		Summary: models.TestSummary{TotalTests: 3, PassedTests: 2, QuarantinedTests: 2, QuarantinedFailures: 1},
+		Results: []models.TestResult{
+			{Name: "listUsers", FilePath: "users.http", Status: models.TestStatusPassed},
+			{Name: "createOrder", FilePath: "orders.http", Status: models.TestStatusFailed, Error: "expected status 201, got 500", Quarantined: true, QuarantineReason: "JIRA-123"},
+			{Name: "getOrder", FilePath: "orders.http", Status: models.TestStatusPassed, Quarantined: true},
+		},
+	}
+}
+
+func TestConsoleReportQuarantine(t *testing.T) {
+	reader, err := NewTestReporterService().GenerateReport(context.Background(), quarantineReport(),
+		models.TestReportOptions{Format: "console", FailuresOnly: true})
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	output := string(data)
+
+	// Known failures are listed apart from the failures
+	assert.NotContains(t, output, "2. createOrder")
+	assert.Contains(t, output, "QUARANTINED:\n")
+	assert.Regexp(t, `createOrder\s+failed\s+JIRA-123\n`, output)
+	assert.Regexp(t, `getOrder\s+passed\s+no reason given\n`, output)
+	assert.Contains(t, output, "  Quarantined: 2, of which 1 failed without failing the run\n")
+}
+
+func TestJUnitReportQuarantine(t *testing.T) {
+	reader, err := NewTestReporterService().GenerateReport(context.Background(), quarantineReport(),
+		models.TestReportOptions{Format: "junit"})
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	output := string(data)
+
+	assert.NotContains(t, output, "<failure")
+	assert.Contains(t, output, `<property name="quarantine" value="JIRA-123"></property>`)
+	assert.Contains(t, output, "Quarantined failure: expected status 201, got 500")
+	assert.Equal(t, 2, strings.Count(output, `skipped="1"`))
+}
@@ -10,18 +10,37 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/edgardnogueira/swagger-to-http/pkg/plugin"
 )
 
 // TestReporterService implements the TestReporter interface
-type TestReporterService struct {}
+type TestReporterService struct {
+	plugins *plugin.Registry
+}
+
+// TestReporterOption configures a TestReporterService
+type TestReporterOption func(*TestReporterService)
+
+// WithPlugins renders the formats that aren't built in with the reporters
+// of a plugin registry, plugin.DefaultRegistry by default
+func WithPlugins(registry *plugin.Registry) TestReporterOption {
+	return func(s *TestReporterService) {
+		s.plugins = registry
+	}
+}
 
 // NewTestReporterService creates a new TestReporterService
-func NewTestReporterService() *TestReporterService {
-	return &TestReporterService{}
+func NewTestReporterService(options ...TestReporterOption) *TestReporterService {
+	s := &TestReporterService{plugins: plugin.DefaultRegistry}
+	for _, option := range options {
+		option(s)
+	}
+	return s
 }
 
 // GenerateReport generates a report in the specified format
@@ -35,13 +54,30 @@ func (s *TestReporterService) GenerateReport(ctx context.Context, report *models
 		return s.generateJUnitReport(report, options)
 	case "console":
 		return s.generateConsoleReport(report, options)
+	case "github":
+		return s.generateGitHubReport(report, options)
 	default:
+		if reporter, ok := s.plugins.Reporter(options.Format); ok {
+			output, err := reporter.Report(ctx, report)
+			if err != nil {
+				return nil, fmt.Errorf("failed to generate %s report: %w", options.Format, err)
+			}
+			return bytes.NewReader(output), nil
+		}
 		return s.generateJSONReport(report, options)
 	}
 }
 
 // SaveReport saves a report to the file system
 func (s *TestReporterService) SaveReport(ctx context.Context, report *models.TestReport, options models.TestReportOptions) error {
+	// Move large bodies out of reports meant to be opened as a whole
+	if options.InlineMaxBytes > 0 && (options.Format == "json" || options.Format == "html") {
+		var err error
+		if report, err = externalizeBodies(report, options); err != nil {
+			return fmt.Errorf("failed to save report attachments: %w", err)
+		}
+	}
+
 	// Generate the report
 	reader, err := s.GenerateReport(ctx, report, options)
 	if err != nil {
@@ -106,6 +142,13 @@ func (s *TestReporterService) generateJSONReport(report *models.TestReport, opti
 		}
 	}
 
+	reportCopy.Sequences = stripSequences(report.Sequences, options)
+
+	// The provenance of variables is only part of detailed reports
+	if !options.Detailed {
+		reportCopy.Variables = nil
+	}
+
 	// Marshal the report to JSON
 	var buf bytes.Buffer
 	encoder := json.NewEncoder(&buf)
@@ -171,6 +214,16 @@ func (s *TestReporterService) generateHTMLReport(report *models.TestReport, opti
         .failed .stat-value { color: #F44336; }
         .skipped .stat-value { color: #FF9800; }
         .error .stat-value { color: #9C27B0; }
+        .breakdown {
+            border-collapse: collapse;
+            margin-bottom: 20px;
+            background: white;
+        }
+        .breakdown th, .breakdown td {
+            text-align: left;
+            padding: 5px 15px;
+            border-bottom: 1px solid #eee;
+        }
         .results {
             margin-top: 20px;
         }
@@ -206,6 +259,7 @@ func (s *TestReporterService) generateHTMLReport(report *models.TestReport, opti
         .status-failed { background: #FFEBEE; color: #C62828; }
         .status-skipped { background: #FFF8E1; color: #F57F17; }
         .status-error { background: #F3E5F5; color: #6A1B9A; }
+        .status-circuit-open { background: #ECEFF1; color: #37474F; }
         .result-details {
             margin-top: 10px;
             font-size: 0.9em;
@@ -229,6 +283,15 @@ func (s *TestReporterService) generateHTMLReport(report *models.TestReport, opti
             font-family: monospace;
             white-space: pre-wrap;
         }
+        .schema-findings {
+            padding: 10px;
+            border-radius: 5px;
+            margin-top: 10px;
+            font-family: monospace;
+        }
+        .schema-findings ul { margin: 5px 0 0 0; }
+        .schema-errors { background: #FFEBEE; }
+        .schema-warnings { background: #FFF8E1; }
         .snapshot-diff {
             background: #F5F5F5;
             padding: 10px;
@@ -261,6 +324,67 @@ func (s *TestReporterService) generateHTMLReport(report *models.TestReport, opti
         .duration {
             font-weight: bold;
         }
+        .sequence {
+            background: white;
+            margin-bottom: 10px;
+            padding: 10px 15px;
+            border-radius: 5px;
+            box-shadow: 0 1px 3px rgba(0,0,0,0.1);
+        }
+        .sequence summary {
+            cursor: pointer;
+            font-weight: bold;
+        }
+        .timeline {
+            list-style: none;
+            margin: 10px 0 0 0;
+            padding-left: 15px;
+            border-left: 2px solid #ddd;
+        }
+        .timeline li {
+            margin-bottom: 8px;
+        }
+        .step-wait {
+            color: #777;
+            font-size: 0.9em;
+        }
+        .waterfall {
+            margin-top: 10px;
+            font-size: 0.9em;
+        }
+        .waterfall-row {
+            display: flex;
+            align-items: center;
+            margin-bottom: 3px;
+        }
+        .waterfall-label {
+            width: 200px;
+            overflow: hidden;
+            text-overflow: ellipsis;
+            white-space: nowrap;
+        }
+        .waterfall-track {
+            position: relative;
+            flex: 1;
+            height: 14px;
+            background: #f5f5f5;
+        }
+        .phase {
+            position: absolute;
+            top: 0;
+            height: 100%;
+            min-width: 1px;
+        }
+        .phase-wait-before, .phase-wait-after { background: #CFD8DC; }
+        .phase-request { background: #42A5F5; }
+        .phase-validation { background: #AB47BC; }
+        .phase-assertions { background: #66BB6A; }
+        .phase-extraction { background: #FFA726; }
+        .waterfall-legend span {
+            display: inline-block;
+            padding: 0 6px;
+            margin-right: 5px;
+        }
     </style>
 </head>
 <body>
@@ -293,6 +417,12 @@ func (s *TestReporterService) generateHTMLReport(report *models.TestReport, opti
                 <div class="stat-label">Total</div>
                 <div class="stat-value">{{.Summary.TotalTests}}</div>
             </div>
+            {{if .Summary.SequencesTotal}}
+            <div class="stat">
+                <div class="stat-label">Sequences Passed</div>
+                <div class="stat-value">{{.Summary.SequencesPassed}}/{{.Summary.SequencesTotal}}</div>
+            </div>
+            {{end}}
             <div class="stat">
                 <div class="stat-label">Snapshots Created</div>
                 <div class="stat-value">{{.Summary.SnapshotsCreated}}</div>
@@ -302,11 +432,139 @@ func (s *TestReporterService) generateHTMLReport(report *models.TestReport, opti
                 <div class="stat-value">{{.Summary.SnapshotsUpdated}}</div>
             </div>
         </div>
+
+        {{if .Summary.ByTag}}
+        <h3>By Tag</h3>
+        <table class="breakdown">
+            <tr><th>Tag</th><th>Passed</th><th>Failed</th><th>Errors</th><th>Total</th></tr>
+            {{range $tag, $counts := .Summary.ByTag}}
+            <tr><td>{{$tag}}</td><td>{{$counts.Passed}}</td><td>{{$counts.Failed}}</td><td>{{$counts.Errors}}</td><td>{{$counts.Total}}</td></tr>
+            {{end}}
+        </table>
+        {{end}}
+
+        {{if .Summary.ByMethod}}
+        <h3>By Method</h3>
+        <table class="breakdown">
+            <tr><th>Method</th><th>Passed</th><th>Failed</th><th>Errors</th><th>Total</th></tr>
+            {{range $method, $counts := .Summary.ByMethod}}
+            <tr><td>{{$method}}</td><td>{{$counts.Passed}}</td><td>{{$counts.Failed}}</td><td>{{$counts.Errors}}</td><td>{{$counts.Total}}</td></tr>
+            {{end}}
+        </table>
+        {{end}}
+
+        {{if .Summary.StatusCodes}}
+        <h3>Status Codes</h3>
+        <table class="breakdown">
+            <tr><th>Status</th><th>Responses</th></tr>
+            {{range $code, $count := .Summary.StatusCodes}}
+            <tr><td>{{$code}}</td><td>{{$count}}</td></tr>
+            {{end}}
+        </table>
+        {{end}}
+
+        {{if .Summary.Slowest}}
+        <h3>Slowest Requests</h3>
+        <table class="breakdown">
+            <tr><th>Test</th><th>Request</th><th>Duration</th></tr>
+            {{range .Summary.Slowest}}
+            <tr><td>{{.Name}}</td><td>{{.Method}} {{.URL}}</td><td>{{.DurationMs}}ms</td></tr>
+            {{end}}
+        </table>
+        {{end}}
+
+        {{if .Summary.BudgetViolations}}
+        <h3>Budget</h3>
+        <table class="breakdown">
+            <tr><th>Test</th><th>Violation</th></tr>
+            {{range .Summary.BudgetViolations}}
+            <tr><td>{{.Test}}</td><td>{{.Error}}</td></tr>
+            {{end}}
+        </table>
+        {{end}}
         
+        {{if .Sequences}}
+        <h2>Sequences</h2>
+        <div class="results">
+            {{range .Sequences}}
+            <details class="sequence"{{if not .Success}} open{{end}}>
+                <summary>
+                    {{.Name}}
+                    <span class="result-status status-{{sequenceStatus .}}">{{sequenceStatus .}}</span>
+                    <span class="duration">{{formatDuration .ExecutionTime}}ms</span>
+                </summary>
+                {{if .Error}}
+                <div class="result-error">{{.Error}}</div>
+                {{end}}
+                {{if hasPhases .}}
+                {{$span := waterfallSpan .}}
+                <div class="waterfall">
+                    {{range .StepResults}}
+                    <div class="waterfall-row">
+                        <span class="waterfall-label">{{.Name}}</span>
+                        <span class="waterfall-track">
+                            {{range .Phases}}<span class="phase phase-{{.Name}}" style="{{phaseStyle . $span}}" title="{{.Name}}: {{formatDuration .Duration}}ms"></span>{{end}}
+                        </span>
+                    </div>
+                    {{end}}
+                    <div class="waterfall-legend">
+                        <span class="phase-wait-before">wait</span>
+                        <span class="phase-request">request</span>
+                        <span class="phase-validation">validation</span>
+                        <span class="phase-assertions">assertions</span>
+                        <span class="phase-extraction">extraction</span>
+                    </div>
+                </div>
+                {{end}}
+                <ol class="timeline">
+                    {{range .StepResults}}
+                    <li>
+                        <span class="result-status status-{{.Status}}">{{.Status}}</span>
+                        <strong>{{.Name}}</strong>
+                        {{if .Request}}{{.Request.Method}} {{.Request.URL}}{{end}}
+                        <span class="duration">{{formatDuration .ExecutionTime}}ms</span>
+                        {{with stepWaits .}}<span class="step-wait">({{.}})</span>{{end}}
+                        {{if .Variables}}
+                        <pre>{{range $name, $value := .Variables}}{{$name}} = {{$value}}
+{{end}}</pre>
+                        {{end}}
+                        {{if .Error}}
+                        <div class="result-error">{{.Error}}</div>
+                        {{end}}
+                    </li>
+                    {{end}}
+                    {{range .CleanupResults}}
+                    <li>
+                        <span class="result-status status-{{.Status}}">{{.Status}}</span>
+                        cleanup {{.Method}} {{.URL}}
+                        {{if .Error}}<div class="result-error">{{.Error}}</div>{{end}}
+                    </li>
+                    {{end}}
+                </ol>
+            </details>
+            {{end}}
+        </div>
+        {{end}}
+
+        {{if and .Detailed .Variables}}
+        <h2>Variables</h2>
+        <table class="breakdown">
+            <tr><th>Scope</th><th>Variable</th><th>Value</th><th>Origin</th></tr>
+            {{range .Variables}}
+            <tr>
+                <td>{{.Scope}}</td>
+                <td>{{.Name}}</td>
+                <td>{{.Value}}</td>
+                <td>{{range $i, $origin := .Origins}}{{if $i}}<br>{{end}}{{describeOrigin $origin}} = {{$origin.Value}}{{end}}</td>
+            </tr>
+            {{end}}
+        </table>
+        {{end}}
+
         <h2>Results</h2>
         <div class="results">
-            {{range .Results}}
-            <div class="result result-{{.Status}}">
+            {{range $index, $result := .Results}}
+            <div id="test-{{$index}}" class="result result-{{.Status}}">
                 <div class="result-header">
                     <div class="result-name">{{.Name}}</div>
                     <div class="result-status status-{{.Status}}">{{.Status}}</div>
@@ -316,6 +574,7 @@ func (s *TestReporterService) generateHTMLReport(report *models.TestReport, opti
                         <span class="result-detail-label">File:</span>
                         <span class="result-detail-value">{{.FilePath}}</span>
                     </div>
+                    {{if .Request}}
                     <div class="result-detail">
                         <span class="result-detail-label">Method:</span>
                         <span class="result-detail-value">{{.Request.Method}}</span>
@@ -324,10 +583,17 @@ func (s *TestReporterService) generateHTMLReport(report *models.TestReport, opti
                         <span class="result-detail-label">URL:</span>
                         <span class="result-detail-value">{{.Request.URL}}</span>
                     </div>
+                    {{end}}
                     <div class="result-detail">
                         <span class="result-detail-label">Duration:</span>
                         <span class="result-detail-value">{{formatDuration .Duration}}ms</span>
                     </div>
+                    {{if .Retries}}
+                    <div class="result-detail">
+                        <span class="result-detail-label">Retries:</span>
+                        <span class="result-detail-value">{{.Retries}}</span>
+                    </div>
+                    {{end}}
                     {{if .Tags}}
                     <div class="result-detail">
                         <span class="result-detail-label">Tags:</span>
@@ -337,6 +603,18 @@ func (s *TestReporterService) generateHTMLReport(report *models.TestReport, opti
                     {{if .Error}}
                     <div class="result-error">{{.Error}}</div>
                     {{end}}
+                    {{if .SchemaResult}}
+                    {{if .SchemaResult.Errors}}
+                    <div class="schema-findings schema-errors">Schema errors:
+                        <ul>{{range .SchemaResult.Errors}}<li>{{describeFinding .}}</li>{{end}}</ul>
+                    </div>
+                    {{end}}
+                    {{if .SchemaResult.Warnings}}
+                    <div class="schema-findings schema-warnings">Schema warnings:
+                        <ul>{{range .SchemaResult.Warnings}}<li>{{describeFinding .}}</li>{{end}}</ul>
+                    </div>
+                    {{end}}
+                    {{end}}
                     {{if .SnapshotResult}}
                     {{if .SnapshotResult.Diff.HasDiff}}
                     <div class="result-detail">
@@ -349,7 +627,7 @@ func (s *TestReporterService) generateHTMLReport(report *models.TestReport, opti
                     {{end}}
                     {{end}}
                     
-                    {{if $.IncludeRequests}}
+                    {{if and $.IncludeRequests .Request}}
                     <div class="request-response">
                         <button class="toggle-button" onclick="toggleElement('request-{{$index}}')">Toggle Request</button>
                         <div id="request-{{$index}}" class="hidden">
@@ -359,6 +637,9 @@ func (s *TestReporterService) generateHTMLReport(report *models.TestReport, opti
                             {{if .Request.Body}}
                             <h4>Request Body</h4>
                             <pre>{{.Request.Body}}</pre>
+                            {{else if .Request.BodyFile}}
+                            <h4>Request Body</h4>
+                            <p><a href="{{.Request.BodyFile}}">{{.Request.BodyFile}}</a></p>
                             {{end}}
                         </div>
                     </div>
@@ -376,6 +657,9 @@ func (s *TestReporterService) generateHTMLReport(report *models.TestReport, opti
                             {{if .Response.Body}}
                             <h4>Response Body</h4>
                             <pre>{{formatBody .Response.Body .Response.ContentType}}</pre>
+                            {{else if .Response.BodyFile}}
+                            <h4>Response Body</h4>
+                            <p><a href="{{.Response.BodyFile}}">{{.Response.BodyFile}}</a></p>
                             {{end}}
                         </div>
                     </div>
@@ -416,18 +700,22 @@ func (s *TestReporterService) generateHTMLReport(report *models.TestReport, opti
 		"join": func(s []string, sep string) string {
 			return strings.Join(s, sep)
 		},
-		"formatBody": func(body []byte, contentType string) string {
+		"sequenceStatus": sequenceStatus,
+		"describeOrigin": describeOrigin,
+		"hasPhases":      hasPhases,
+		"waterfallSpan":  waterfallSpan,
+		"phaseStyle":     phaseStyle,
+		"stepWaits":      stepWaits,
+		"describeFinding": describeFinding,
+		"formatBody": func(body string, contentType string) string {
 			if strings.Contains(contentType, "application/json") {
 				var out bytes.Buffer
-				err := json.Indent(&out, body, "", "  ")
+				err := json.Indent(&out, []byte(body), "", "  ")
 				if err == nil {
 					return out.String()
 				}
 			}
-			return string(body)
-		},
-		"$index": func() int {
-			return 0 // Will be replaced in the loop
+			return body
 		},
 	}
 
@@ -442,10 +730,12 @@ func (s *TestReporterService) generateHTMLReport(report *models.TestReport, opti
 		*models.TestReport
 		IncludeRequests  bool
 		IncludeResponses bool
+		Detailed         bool
 	}{
 		TestReport:       report,
 		IncludeRequests:  options.IncludeRequests,
 		IncludeResponses: options.IncludeResponses,
+		Detailed:         options.Detailed,
 	}
 
 	// Execute the template
@@ -457,106 +747,131 @@ func (s *TestReporterService) generateHTMLReport(report *models.TestReport, opti
 	return &buf, nil
 }
 
-// generateJUnitReport generates a JUnit XML report
-func (s *TestReporterService) generateJUnitReport(report *models.TestReport, options models.TestReportOptions) (io.Reader, error) {
-	// Define JUnit XML structures
-	type JUnitProperty struct {
-		Name  string `xml:"name,attr"`
-		Value string `xml:"value,attr"`
-	}
+// JUnit test suite grouping modes
+const (
+	JUnitGroupByFile = "file"
+	JUnitGroupByTag  = "tag"
+	JUnitGroupByNone = "none"
+)
 
-	type JUnitProperties struct {
-		Properties []JUnitProperty `xml:"property"`
-	}
+// JUnit XML structures
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
 
-	type JUnitFailure struct {
-		Message string `xml:"message,attr"`
-		Type    string `xml:"type,attr"`
-		Content string `xml:",cdata"`
-	}
+type junitProperties struct {
+	Properties []junitProperty `xml:"property"`
+}
 
-	type JUnitTestCase struct {
-		Name      string        `xml:"name,attr"`
-		Classname string        `xml:"classname,attr"`
-		Time      float64       `xml:"time,attr"`
-		Failure   *JUnitFailure `xml:"failure,omitempty"`
-		Skipped   *struct{}     `xml:"skipped,omitempty"`
-		SystemOut string        `xml:"system-out,omitempty"`
-	}
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Content string `xml:",cdata"`
+}
 
-	type JUnitTestSuite struct {
-		Name       string           `xml:"name,attr"`
-		Tests      int              `xml:"tests,attr"`
-		Failures   int              `xml:"failures,attr"`
-		Errors     int              `xml:"errors,attr"`
-		Skipped    int              `xml:"skipped,attr"`
-		Time       float64          `xml:"time,attr"`
-		Timestamp  string           `xml:"timestamp,attr"`
-		Properties JUnitProperties  `xml:"properties"`
-		TestCases  []JUnitTestCase  `xml:"testcase"`
-	}
+// junitSkipped is a skipped test case with the reason it was skipped
+type junitSkipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
 
-	type JUnitTestSuites struct {
-		XMLName    xml.Name         `xml:"testsuites"`
-		TestSuites []JUnitTestSuite `xml:"testsuite"`
+type junitTestCase struct {
+	Name       string           `xml:"name,attr"`
+	Classname  string           `xml:"classname,attr"`
+	Time       float64          `xml:"time,attr"`
+	File       string           `xml:"file,attr,omitempty"`
+	Line       int              `xml:"line,attr,omitempty"`
+	Properties *junitProperties `xml:"properties,omitempty"`
+	Failure    *junitFailure    `xml:"failure,omitempty"`
+	Error      *junitFailure    `xml:"error,omitempty"`
+	Skipped    *junitSkipped    `xml:"skipped,omitempty"`
+	SystemOut  string           `xml:"system-out,omitempty"`
+	SystemErr  string           `xml:"system-err,omitempty"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Time       float64         `xml:"time,attr"`
+	Timestamp  string          `xml:"timestamp,attr"`
+	File       string          `xml:"file,attr,omitempty"`
+	Properties junitProperties `xml:"properties"`
+	TestCases  []junitTestCase `xml:"testcase"`
+}
+
+type junitTestSuites struct {
+	XMLName    xml.Name         `xml:"testsuites"`
+	Name       string           `xml:"name,attr"`
+	Tests      int              `xml:"tests,attr"`
+	Failures   int              `xml:"failures,attr"`
+	Errors     int              `xml:"errors,attr"`
+	Skipped    int              `xml:"skipped,attr"`
+	Time       float64          `xml:"time,attr"`
+	TestSuites []junitTestSuite `xml:"testsuite"`
+}
+
+// generateJUnitReport generates a JUnit XML report with a test suite per file or
+// tag, so CI viewers can group the results
+func (s *TestReporterService) generateJUnitReport(report *models.TestReport, options models.TestReportOptions) (io.Reader, error) {
+	// Environment properties are repeated on every suite
+	properties := make([]junitProperty, 0, len(report.Environment))
+	for _, k := range sortedKeys(report.Environment) {
+		properties = append(properties, junitProperty{Name: k, Value: report.Environment[k]})
 	}
 
-	// Create JUnit test cases
-	testCases := make([]JUnitTestCase, 0, len(report.Results))
+	// Group the test cases into suites in order of first appearance
+	var suites []*junitTestSuite
+	suiteIndex := make(map[string]*junitTestSuite)
 	for _, result := range report.Results {
-		testCase := JUnitTestCase{
-			Name:      result.Name,
-			Classname: result.FilePath,
-			Time:      float64(result.Duration.Milliseconds()) / 1000.0,
+		name, file := junitSuiteName(report, result, options.JUnitGroupBy)
+		suite, ok := suiteIndex[name]
+		if !ok {
+			suite = &junitTestSuite{
+				Name:       name,
+				Timestamp:  report.CreatedAt.Format(time.RFC3339),
+				File:       file,
+				Properties: junitProperties{Properties: properties},
+			}
+			suiteIndex[name] = suite
+			suites = append(suites, suite)
 		}
 
-		switch result.Status {
-		case models.TestStatusFailed:
-			testCase.Failure = &JUnitFailure{
-				Message: "Test failed",
-				Type:    "failure",
-				Content: result.Error,
-			}
-		case models.TestStatusError:
-			testCase.Failure = &JUnitFailure{
-				Message: "Test error",
-				Type:    "error",
-				Content: result.Error,
-			}
-		case models.TestStatusSkipped:
-			testCase.Skipped = &struct{}{}
+		testCase := junitCase(result)
+		suite.TestCases = append(suite.TestCases, testCase)
+		suite.Tests++
+		suite.Time += testCase.Time
+		switch {
+		case testCase.Failure != nil:
+			suite.Failures++
+		case testCase.Error != nil:
+			suite.Errors++
+		case testCase.Skipped != nil:
+			suite.Skipped++
 		}
+	}
 
-		testCases = append(testCases, testCase)
-	}
-
-	// Create properties
-	properties := make([]JUnitProperty, 0)
-	for k, v := range report.Environment {
-		properties = append(properties, JUnitProperty{
-			Name:  k,
-			Value: v,
-		})
-	}
-
-	// Create test suite
-	testSuite := JUnitTestSuite{
-		Name:      report.Name,
-		Tests:     report.Summary.TotalTests,
-		Failures:  report.Summary.FailedTests,
-		Errors:    report.Summary.ErrorTests,
-		Skipped:   report.Summary.SkippedTests,
-		Time:      float64(report.Summary.DurationMs) / 1000.0,
-		Timestamp: report.CreatedAt.Format(time.RFC3339),
-		Properties: JUnitProperties{
-			Properties: properties,
-		},
-		TestCases: testCases,
+	testSuites := junitTestSuites{
+		Name:     report.Name,
+		Tests:    report.Summary.TotalTests,
+		Failures: report.Summary.FailedTests,
+		Errors:   report.Summary.ErrorTests,
+		Skipped:  report.Summary.SkippedTests + report.Summary.QuarantinedFailures,
+		Time:     float64(report.Summary.DurationMs) / 1000.0,
+	}
+	for _, suite := range suites {
+		testSuites.TestSuites = append(testSuites.TestSuites, *suite)
 	}
 
-	// Create test suites
-	testSuites := JUnitTestSuites{
-		TestSuites: []JUnitTestSuite{testSuite},
+	// An empty run still produces a suite for the report
+	if len(testSuites.TestSuites) == 0 {
+		testSuites.TestSuites = []junitTestSuite{{
+			Name:       report.Name,
+			Timestamp:  report.CreatedAt.Format(time.RFC3339),
+			Properties: junitProperties{Properties: properties},
+		}}
 	}
 
 	// Marshal to XML
@@ -571,6 +886,113 @@ func (s *TestReporterService) generateJUnitReport(report *models.TestReport, opt
 	return &buf, nil
 }
 
+// junitSuiteName returns the suite of a result and the file the suite belongs
+// to; the steps of a sequence always make up a suite of their own
+func junitSuiteName(report *models.TestReport, result models.TestResult, groupBy string) (string, string) {
+	if sequence := result.MetaData[models.SequenceMetaKey]; sequence != "" {
+		return sequence, result.FilePath
+	}
+
+	switch groupBy {
+	case JUnitGroupByNone:
+		return report.Name, ""
+	case JUnitGroupByTag:
+		for _, tag := range result.Tags {
+			if tag != "" {
+				return tag, ""
+			}
+		}
+		return "untagged", ""
+	default:
+		if result.FilePath == "" {
+			return report.Name, ""
+		}
+		return result.FilePath, result.FilePath
+	}
+}
+
+// junitCase converts a test result to a JUnit test case with its request details as
+// properties and, for failures, the response as system output
+func junitCase(result models.TestResult) junitTestCase {
+	testCase := junitTestCase{
+		Name:      result.Name,
+		Classname: strings.TrimSuffix(filepath.ToSlash(result.FilePath), ".http"),
+		Time:      float64(result.Duration.Milliseconds()) / 1000.0,
+		File:      result.FilePath,
+	}
+
+	var properties []junitProperty
+	if result.Request != nil {
+		testCase.Line = result.Request.Line
+		properties = append(properties,
+			junitProperty{Name: "method", Value: result.Request.Method},
+			junitProperty{Name: "url", Value: result.Request.URL},
+		)
+	}
+	if result.Response != nil {
+		properties = append(properties, junitProperty{Name: "status_code", Value: fmt.Sprintf("%d", result.Response.StatusCode)})
+	}
+	properties = append(properties, junitProperty{Name: "duration_ms", Value: fmt.Sprintf("%d", result.Duration.Milliseconds())})
+	if result.Retries > 0 {
+		properties = append(properties, junitProperty{Name: "retries", Value: fmt.Sprintf("%d", result.Retries)})
+	}
+	if result.Quarantined {
+		properties = append(properties, junitProperty{Name: "quarantine", Value: result.QuarantineReason})
+	}
+	for _, name := range sortedKeys(result.ExtractedVars) {
+		properties = append(properties, junitProperty{Name: "var." + name, Value: result.ExtractedVars[name]})
+	}
+	testCase.Properties = &junitProperties{Properties: properties}
+
+	// Known failures are reported as skipped so they don't fail the build
+	if result.QuarantinedFailure() {
+		testCase.Skipped = &junitSkipped{Message: strings.TrimSpace("Quarantined " + result.QuarantineReason)}
+		testCase.SystemErr = "Quarantined failure: " + junitMessage(result, "Test failed")
+		return testCase
+	}
+
+	switch result.Status {
+	case models.TestStatusFailed:
+		testCase.Failure = &junitFailure{
+			Message: junitMessage(result, "Test failed"),
+			Type:    "failure",
+			Content: failureMessage(result),
+		}
+	case models.TestStatusError, models.TestStatusCircuitOpen:
+		testCase.Error = &junitFailure{
+			Message: junitMessage(result, "Test error"),
+			Type:    "error",
+			Content: result.Error,
+		}
+	case models.TestStatusSkipped:
+		testCase.Skipped = &junitSkipped{Message: result.Error}
+	}
+
+	// Attach the response of failed tests to help diagnose them
+	if (testCase.Failure != nil || testCase.Error != nil) && result.Response != nil {
+		status := result.Response.Status
+		if status == "" {
+			status = fmt.Sprintf("%d", result.Response.StatusCode)
+		}
+		testCase.SystemOut = fmt.Sprintf("%s\n\n%s", status, result.Response.Body)
+	}
+
+	// Schema warnings don't fail the test but are kept apart from the output
+	if result.SchemaResult != nil && len(result.SchemaResult.Warnings) > 0 {
+		testCase.SystemErr = "Schema warnings:\n" + describeFindings(result.SchemaResult.Warnings)
+	}
+
+	return testCase
+}
+
+// junitMessage returns the first line of a result's error, or a fallback message
+func junitMessage(result models.TestResult, fallback string) string {
+	if result.Error == "" {
+		return fallback
+	}
+	return strings.SplitN(result.Error, "\n", 2)[0]
+}
+
 // generateConsoleReport generates a console (text) report
 func (s *TestReporterService) generateConsoleReport(report *models.TestReport, options models.TestReportOptions) (io.Reader, error) {
 	var buf bytes.Buffer
@@ -581,60 +1003,251 @@ func (s *TestReporterService) generateConsoleReport(report *models.TestReport, o
 	fmt.Fprintf(&buf, "===============================================\n")
 	fmt.Fprintf(&buf, "Started: %s\n", report.Summary.StartTime.Format("2006-01-02 15:04:05"))
 	fmt.Fprintf(&buf, "Duration: %.2f ms\n", float64(report.Summary.DurationMs))
+	if seed, ok := report.Environment[models.SeedEnvironmentKey]; ok {
+		fmt.Fprintf(&buf, "Seed: %s\n", seed)
+	}
 	fmt.Fprintf(&buf, "\n")
 
+	// In quiet mode only the failures are listed, followed by the summary
+	if options.FailuresOnly {
+		writeConsoleWarnings(&buf, report)
+		writeConsoleSequences(&buf, report, true)
+		if options.Detailed {
+			writeConsoleVariables(&buf, report)
+		}
+		fmt.Fprintf(&buf, "FAILURES:\n")
+		for i, result := range report.Results {
+			if !result.Quarantined && (result.Status == models.TestStatusFailed || result.Status == models.TestStatusError || result.Status == models.TestStatusCircuitOpen) {
+				writeConsoleResult(&buf, i, result, options)
+			}
+		}
+		writeConsoleQuarantine(&buf, report)
+		writeConsoleSummary(&buf, report)
+		return &buf, nil
+	}
+
 	// Write summary
-	fmt.Fprintf(&buf, "SUMMARY:\n")
-	fmt.Fprintf(&buf, "  Total:   %d\n", report.Summary.TotalTests)
-	fmt.Fprintf(&buf, "  Passed:  %d\n", report.Summary.PassedTests)
-	fmt.Fprintf(&buf, "  Failed:  %d\n", report.Summary.FailedTests)
-	fmt.Fprintf(&buf, "  Skipped: %d\n", report.Summary.SkippedTests)
-	fmt.Fprintf(&buf, "  Errors:  %d\n", report.Summary.ErrorTests)
-	fmt.Fprintf(&buf, "\n")
-	fmt.Fprintf(&buf, "  Snapshots:\n")
-	fmt.Fprintf(&buf, "    Created: %d\n", report.Summary.SnapshotsCreated)
-	fmt.Fprintf(&buf, "    Updated: %d\n", report.Summary.SnapshotsUpdated)
-	fmt.Fprintf(&buf, "\n")
+	writeConsoleSummary(&buf, report)
+
+	// Write warnings
+	writeConsoleWarnings(&buf, report)
+
+	// Write the known failures apart from the others
+	writeConsoleQuarantine(&buf, report)
+
+	// Write the language matrix
+	if len(report.LanguageMatrix) > 0 {
+		fmt.Fprintf(&buf, "LANGUAGES:\n")
+		fmt.Fprintf(&buf, "  %-50s", "TEST")
+		for _, language := range report.Languages {
+			fmt.Fprintf(&buf, " %-8s", language)
+		}
+		fmt.Fprintf(&buf, "\n")
+		for _, row := range report.LanguageMatrix {
+			fmt.Fprintf(&buf, "  %-50s", models.TruncateString(row.Test, 50))
+			for _, language := range report.Languages {
+				status := string(row.Statuses[language])
+				if status == "" {
+					status = "-"
+				}
+				fmt.Fprintf(&buf, " %-8s", status)
+			}
+			fmt.Fprintf(&buf, "\n")
+		}
+		fmt.Fprintf(&buf, "\n")
+	}
+
+	// Write per data row summaries
+	if len(report.DataRows) > 0 {
+		fmt.Fprintf(&buf, "DATA ROWS:\n")
+		for _, row := range report.DataRows {
+			fmt.Fprintf(&buf, "  %s: %d passed, %d failed, %d errors (%d total)\n",
+				row.ID, row.Summary.PassedTests, row.Summary.FailedTests, row.Summary.ErrorTests, row.Summary.TotalTests)
+		}
+		fmt.Fprintf(&buf, "\n")
+	}
+
+	// Write the sequence timelines
+	writeConsoleSequences(&buf, report, false)
+
+	// Write where the variables came from
+	if options.Detailed {
+		writeConsoleVariables(&buf, report)
+	}
 
 	// Write results
 	fmt.Fprintf(&buf, "RESULTS:\n")
 	for i, result := range report.Results {
-		// Format status with color if enabled
-		status := string(result.Status)
-		if options.ColorOutput {
-			switch result.Status {
-			case models.TestStatusPassed:
-				status = "\x1b[32mPASSED\x1b[0m" // Green
-			case models.TestStatusFailed:
-				status = "\x1b[31mFAILED\x1b[0m" // Red
-			case models.TestStatusSkipped:
-				status = "\x1b[33mSKIPPED\x1b[0m" // Yellow
-			case models.TestStatusError:
-				status = "\x1b[35mERROR\x1b[0m" // Magenta
-			}
+		writeConsoleResult(&buf, i, result, options)
+	}
+
+	return &buf, nil
+}
+
+// writeConsoleSummary writes the summary block of the console report
+func writeConsoleSummary(buf *bytes.Buffer, report *models.TestReport) {
+	fmt.Fprintf(buf, "SUMMARY:\n")
+	fmt.Fprintf(buf, "  Total:   %d\n", report.Summary.TotalTests)
+	fmt.Fprintf(buf, "  Passed:  %d\n", report.Summary.PassedTests)
+	fmt.Fprintf(buf, "  Failed:  %d\n", report.Summary.FailedTests)
+	fmt.Fprintf(buf, "  Skipped: %d\n", report.Summary.SkippedTests)
+	fmt.Fprintf(buf, "  Errors:  %d\n", report.Summary.ErrorTests)
+	if report.Summary.CircuitOpenTests > 0 {
+		fmt.Fprintf(buf, "  Circuit open: %d of the errors failed fast on an unreachable host\n", report.Summary.CircuitOpenTests)
+	}
+	if report.Summary.QuarantinedTests > 0 {
+		fmt.Fprintf(buf, "  Quarantined: %d, of which %d failed without failing the run\n",
+			report.Summary.QuarantinedTests, report.Summary.QuarantinedFailures)
+	}
+	if report.Summary.RunTimedOut {
+		fmt.Fprintf(buf, "  Run timeout reached: the remaining tests were skipped\n")
+	}
+	if report.Summary.SequencesTotal > 0 {
+		fmt.Fprintf(buf, "  Sequences: %d passed, %d failed (%d total)\n",
+			report.Summary.SequencesPassed, report.Summary.SequencesFailed, report.Summary.SequencesTotal)
+	}
+	fmt.Fprintf(buf, "\n")
+	fmt.Fprintf(buf, "  Snapshots:\n")
+	fmt.Fprintf(buf, "    Created: %d\n", report.Summary.SnapshotsCreated)
+	fmt.Fprintf(buf, "    Updated: %d\n", report.Summary.SnapshotsUpdated)
+	fmt.Fprintf(buf, "\n")
+
+	writeConsoleBreakdowns(buf, report)
+}
+
+// writeConsoleBreakdowns writes the per-tag, per-method and per-status-code
+// statistics and the slowest requests of the summary
+func writeConsoleBreakdowns(buf *bytes.Buffer, report *models.TestReport) {
+	writeCounts := func(title string, groups map[string]models.StatusCounts) {
+		if len(groups) == 0 {
+			return
+		}
+		names := make([]string, 0, len(groups))
+		for name := range groups {
+			names = append(names, name)
 		}
+		sort.Strings(names)
 
-		fmt.Fprintf(&buf, "  %d. %s [%s]\n", i+1, result.Name, status)
-		fmt.Fprintf(&buf, "     File: %s\n", result.FilePath)
-		if result.Request != nil {
-			fmt.Fprintf(&buf, "     Method: %s %s\n", result.Request.Method, result.Request.URL)
+		fmt.Fprintf(buf, "%s:\n", title)
+		for _, name := range names {
+			counts := groups[name]
+			fmt.Fprintf(buf, "  %-30s %d passed, %d failed, %d errors (%d total)\n",
+				models.TruncateString(name, 30), counts.Passed, counts.Failed, counts.Errors, counts.Total)
 		}
-		if result.Duration > 0 {
-			fmt.Fprintf(&buf, "     Duration: %.2f ms\n", float64(result.Duration.Milliseconds()))
+		fmt.Fprintf(buf, "\n")
+	}
+	writeCounts("BY TAG", report.Summary.ByTag)
+	writeCounts("BY METHOD", report.Summary.ByMethod)
+
+	if len(report.Summary.StatusCodes) > 0 {
+		fmt.Fprintf(buf, "STATUS CODES:\n")
+		for _, code := range report.Summary.SortedStatusCodes() {
+			fmt.Fprintf(buf, "  %d: %d\n", code, report.Summary.StatusCodes[code])
 		}
-		if len(result.Tags) > 0 {
-			fmt.Fprintf(&buf, "     Tags: %s\n", strings.Join(result.Tags, ", "))
+		fmt.Fprintf(buf, "\n")
+	}
+
+	if len(report.Summary.Slowest) > 0 {
+		fmt.Fprintf(buf, "SLOWEST:\n")
+		for i, slow := range report.Summary.Slowest {
+			fmt.Fprintf(buf, "  %d. %s (%d ms)\n", i+1, slow.Name, slow.DurationMs)
 		}
-		if result.Error != "" {
-			fmt.Fprintf(&buf, "     Error: %s\n", result.Error)
+		fmt.Fprintf(buf, "\n")
+	}
+
+	if len(report.Summary.BudgetViolations) > 0 {
+		fmt.Fprintf(buf, "BUDGET:\n")
+		for _, violation := range report.Summary.BudgetViolations {
+			fmt.Fprintf(buf, "  %s: %s\n", violation.Test, violation.Error)
 		}
-		if result.SnapshotResult != nil && result.SnapshotResult.Diff != nil && result.SnapshotResult.Diff.HasDiff {
-			fmt.Fprintf(&buf, "     Snapshot Diff: %s\n", summarizeDiff(result.SnapshotResult.Diff.DiffString))
+		fmt.Fprintf(buf, "\n")
+	}
+}
+
+// writeConsoleWarnings writes the report warnings, if any
+func writeConsoleWarnings(buf *bytes.Buffer, report *models.TestReport) {
+	if len(report.Warnings) == 0 {
+		return
+	}
+	fmt.Fprintf(buf, "WARNINGS:\n")
+	for _, warning := range report.Warnings {
+		fmt.Fprintf(buf, "  - %s\n", warning)
+	}
+	fmt.Fprintf(buf, "\n")
+}
+
+// writeConsoleQuarantine writes the quarantined tests with their status and
+// the reason they are quarantined, if any
+func writeConsoleQuarantine(buf *bytes.Buffer, report *models.TestReport) {
+	if report.Summary.QuarantinedTests == 0 {
+		return
+	}
+	fmt.Fprintf(buf, "QUARANTINED:\n")
+	for _, result := range report.Results {
+		if !result.Quarantined {
+			continue
 		}
-		fmt.Fprintf(&buf, "\n")
+		reason := result.QuarantineReason
+		if reason == "" {
+			reason = "no reason given"
+		}
+		fmt.Fprintf(buf, "  %-50s %-8s %s\n", models.TruncateString(result.Name, 50), result.Status, reason)
 	}
+	fmt.Fprintf(buf, "\n")
+}
 
-	return &buf, nil
+// writeConsoleResult writes a single numbered test result
+func writeConsoleResult(buf *bytes.Buffer, i int, result models.TestResult, options models.TestReportOptions) {
+	// Format status with color if enabled
+	status := string(result.Status)
+	if options.ColorOutput {
+		switch result.Status {
+		case models.TestStatusPassed:
+			status = "\x1b[32mPASSED\x1b[0m" // Green
+		case models.TestStatusFailed:
+			status = "\x1b[31mFAILED\x1b[0m" // Red
+		case models.TestStatusSkipped:
+			status = "\x1b[33mSKIPPED\x1b[0m" // Yellow
+		case models.TestStatusError:
+			status = "\x1b[35mERROR\x1b[0m" // Magenta
+		case models.TestStatusCircuitOpen:
+			status = "\x1b[35mCIRCUIT-OPEN\x1b[0m" // Magenta
+		}
+	}
+
+	fmt.Fprintf(buf, "  %d. %s [%s]\n", i+1, result.Name, status)
+	fmt.Fprintf(buf, "     File: %s\n", result.FilePath)
+	if result.Request != nil {
+		fmt.Fprintf(buf, "     Method: %s %s\n", result.Request.Method, result.Request.URL)
+	}
+	if result.Duration > 0 {
+		fmt.Fprintf(buf, "     Duration: %.2f ms\n", float64(result.Duration.Milliseconds()))
+	}
+	if result.Retries > 0 {
+		fmt.Fprintf(buf, "     Retries: %d\n", result.Retries)
+	}
+	if result.Quarantined {
+		fmt.Fprintf(buf, "     Quarantined: %s\n", result.QuarantineReason)
+	}
+	if len(result.Tags) > 0 {
+		fmt.Fprintf(buf, "     Tags: %s\n", strings.Join(result.Tags, ", "))
+	}
+	if len(result.ChainedVars) > 0 {
+		fmt.Fprintf(buf, "     Chained:\n")
+		for _, ref := range sortedKeys(result.ChainedVars) {
+			fmt.Fprintf(buf, "       %s = %s\n", ref, models.TruncateString(result.ChainedVars[ref], 80))
+		}
+	}
+	if result.Error != "" && result.Status == models.TestStatusSkipped {
+		fmt.Fprintf(buf, "     Reason: %s\n", result.Error)
+	} else if result.Error != "" {
+		fmt.Fprintf(buf, "     Error: %s\n", result.Error)
+	}
+	writeConsoleSchemaFindings(buf, result)
+	if result.SnapshotResult != nil && result.SnapshotResult.Diff != nil && result.SnapshotResult.Diff.HasDiff {
+		fmt.Fprintf(buf, "     Snapshot Diff: %s\n", summarizeDiff(result.SnapshotResult.Diff.DiffString))
+	}
+	fmt.Fprintf(buf, "\n")
 }
 
 // summarizeDiff returns a shortened version of a diff for console output
@@ -646,3 +1259,13 @@ func summarizeDiff(diff string) string {
 	}
 	return diff
 }
+
+// sortedKeys returns the keys of a string map in sorted order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,53 @@
+package reporter
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// describeFinding describes a schema validation error or warning, e.g.
+// "user.email: string is not a valid email (value: bob)"
+func describeFinding(finding models.ValidationError) string {
+	description := finding.Message
+	if finding.Path != "" {
+		description = finding.Path + ": " + description
+	}
+	if finding.Value != "" {
+		description += fmt.Sprintf(" (value: %s)", models.TruncateString(finding.Value, 80))
+	}
+	return description
+}
+
+// describeFindings describes schema validation findings, one per line
+func describeFindings(findings []models.ValidationError) string {
+	lines := make([]string, len(findings))
+	for i, finding := range findings {
+		lines[i] = "- " + describeFinding(finding)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// writeConsoleSchemaFindings writes the schema errors and warnings of a result
+func writeConsoleSchemaFindings(buf *bytes.Buffer, result models.TestResult) {
+	if result.SchemaResult == nil {
+		return
+	}
+	for _, tier := range []struct {
+		title    string
+		findings []models.ValidationError
+	}{
+		{"Schema Errors", result.SchemaResult.Errors},
+		{"Schema Warnings", result.SchemaResult.Warnings},
+	} {
+		if len(tier.findings) == 0 {
+			continue
+		}
+		fmt.Fprintf(buf, "     %s:\n", tier.title)
+		for _, finding := range tier.findings {
+			fmt.Fprintf(buf, "       - %s\n", describeFinding(finding))
+		}
+	}
+}
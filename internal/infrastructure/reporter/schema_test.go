@@ -0,0 +1,42 @@
+package reporter
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaFindingsReport(t *testing.T) {
+	t.Setenv("GITHUB_WORKSPACE", "")
+
+	report := &models.TestReport{
+		Name:    "HTTP Tests",
+		Summary: models.TestSummary{TotalTests: 1, PassedTests: 1},
+		Results: []models.TestResult{{
+			Name:     "getUser",
+			FilePath: "http/users.http",
+			Status:   models.TestStatusPassed,
+			SchemaResult: &models.SchemaValidationResult{
+				Valid:    true,
+				Warnings: []models.ValidationError{{Path: "legacy", Message: "deprecated property", Value: "x"}},
+			},
+		}},
+	}
+
+	generate := func(format string) string {
+		reader, err := NewTestReporterService().GenerateReport(context.Background(), report, models.TestReportOptions{Format: format, Detailed: true})
+		require.NoError(t, err)
+		data, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	assert.Contains(t, generate("console"), "Schema Warnings:\n       - legacy: deprecated property (value: x)\n")
+	assert.Contains(t, generate("html"), `class="schema-findings schema-warnings"`)
+	assert.Contains(t, generate("junit"), "<system-err>Schema warnings:&#xA;- legacy: deprecated property (value: x)")
+	assert.Equal(t, "::warning title=getUser,file=http/users.http::Schema warnings:%0A- legacy: deprecated property (value: x)\n", generate("github"))
+}
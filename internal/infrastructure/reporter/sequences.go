@@ -0,0 +1,125 @@
+package reporter
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// sequenceStatus returns the status of a sequence as a whole
+func sequenceStatus(sequence models.TestSequenceResult) models.TestStatus {
+	if sequence.Success {
+		return models.TestStatusPassed
+	}
+	return models.TestStatusFailed
+}
+
+// stripSequences returns copies of the sequences without the requests or
+// responses of their steps, as the report options ask
+func stripSequences(sequences []models.TestSequenceResult, options models.TestReportOptions) []models.TestSequenceResult {
+	if sequences == nil || (options.IncludeRequests && options.IncludeResponses) {
+		return sequences
+	}
+	stripped := make([]models.TestSequenceResult, len(sequences))
+	for i, sequence := range sequences {
+		steps := make([]models.TestSequenceStepResult, len(sequence.StepResults))
+		for j, step := range sequence.StepResults {
+			if !options.IncludeRequests {
+				step.Request = nil
+			}
+			if !options.IncludeResponses {
+				step.Response = nil
+			}
+			steps[j] = step
+		}
+		sequence.StepResults = steps
+		stripped[i] = sequence
+	}
+	return stripped
+}
+
+// writeConsoleSequences writes the timeline of each sequence: its steps in
+// order with their status, duration, waits and extracted variables. Only the
+// failed sequences are written when failuresOnly is set.
+func writeConsoleSequences(buf *bytes.Buffer, report *models.TestReport, failuresOnly bool) {
+	if len(report.Sequences) == 0 {
+		return
+	}
+	fmt.Fprintf(buf, "SEQUENCES:\n")
+	for i, sequence := range report.Sequences {
+		if failuresOnly && sequence.Success {
+			continue
+		}
+		fmt.Fprintf(buf, "  %d. %s [%s] (%d ms)\n", i+1, sequence.Name, sequenceStatus(sequence), sequence.ExecutionTime.Milliseconds())
+		for j, step := range sequence.StepResults {
+			fmt.Fprintf(buf, "     %d) %s [%s] %d ms", j+1, step.Name, step.Status, step.ExecutionTime.Milliseconds())
+			if waits := stepWaits(step); waits != "" {
+				fmt.Fprintf(buf, ", %s", waits)
+			}
+			fmt.Fprintf(buf, "\n")
+			for _, name := range sortedKeys(step.Variables) {
+				fmt.Fprintf(buf, "        %s = %s\n", name, models.TruncateString(step.Variables[name], 80))
+			}
+			if step.Error != "" {
+				fmt.Fprintf(buf, "        Error: %s\n", step.Error)
+			}
+		}
+		if sequence.Error != "" {
+			fmt.Fprintf(buf, "     Error: %s\n", sequence.Error)
+		}
+		for _, cleanup := range sequence.CleanupResults {
+			fmt.Fprintf(buf, "     cleanup %s %s [%s]\n", cleanup.Method, cleanup.URL, cleanup.Status)
+		}
+	}
+	fmt.Fprintf(buf, "\n")
+}
+
+// stepWaits describes the pauses around a sequence step, empty without any
+func stepWaits(step models.TestSequenceStepResult) string {
+	var waits []string
+	if step.WaitBefore > 0 {
+		waits = append(waits, fmt.Sprintf("waited %s before", step.WaitBefore))
+	}
+	if step.WaitAfter > 0 {
+		waits = append(waits, fmt.Sprintf("waited %s after", step.WaitAfter))
+	}
+	return strings.Join(waits, ", ")
+}
+
+// waterfallSpan returns the time the waterfall of a sequence spans: its
+// execution time, or the end of its last phase when that's later
+func waterfallSpan(sequence models.TestSequenceResult) time.Duration {
+	span := sequence.ExecutionTime
+	for _, step := range sequence.StepResults {
+		for _, phase := range step.Phases {
+			if end := phase.Offset + phase.Duration; end > span {
+				span = end
+			}
+		}
+	}
+	return span
+}
+
+// phaseStyle positions the bar of a phase on a waterfall spanning span
+func phaseStyle(phase models.StepPhase, span time.Duration) template.CSS {
+	if span <= 0 {
+		return ""
+	}
+	left := float64(phase.Offset) / float64(span) * 100
+	width := float64(phase.Duration) / float64(span) * 100
+	return template.CSS(fmt.Sprintf("left: %.2f%%; width: %.2f%%", left, width))
+}
+
+// hasPhases reports whether any step of a sequence recorded its phases
+func hasPhases(sequence models.TestSequenceResult) bool {
+	for _, step := range sequence.StepResults {
+		if len(step.Phases) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package reporter
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSequenceReports(t *testing.T) {
+	report := &models.TestReport{
+		Name: "Sequence Tests",
+		Summary: models.TestSummary{
+			TotalTests: 2, PassedTests: 1, FailedTests: 1,
+			SequencesTotal: 1, SequencesFailed: 1,
+		},
+		Sequences: []models.TestSequenceResult{{
+			Name:          "checkout",
+			ExecutionTime: 1500 * time.Millisecond,
+			StepResults: []models.TestSequenceStepResult{
+				{
+					Name:          "create cart",
+					Status:        models.TestStatusPassed,
+					ExecutionTime: 120 * time.Millisecond,
+					Variables:     map[string]string{"cartId": "42"},
+					Request:       &models.HTTPRequest{Method: "POST", URL: "https://api.example.com/carts"},
+					Response:      &models.HTTPResponse{StatusCode: 201},
+					WaitAfter:     time.Second,
+					Phases: []models.StepPhase{
+						{Name: models.StepPhaseRequest, Duration: 120 * time.Millisecond},
+						{Name: models.StepPhaseWaitAfter, Offset: 150 * time.Millisecond, Duration: time.Second},
+					},
+				},
+				{Name: "pay", Status: models.TestStatusFailed, ExecutionTime: 80 * time.Millisecond, Error: "Expected status code 200 but got 402"},
+			},
+		}},
+		Results: []models.TestResult{
+			{
+				Name:          "checkout - create cart",
+				FilePath:      "sequences/checkout.json",
+				Status:        models.TestStatusPassed,
+				ExtractedVars: map[string]string{"cartId": "42"},
+				MetaData:      map[string]string{models.SequenceMetaKey: "checkout", models.StepMetaKey: "create cart"},
+			},
+			{
+				Name:     "checkout - pay",
+				FilePath: "sequences/checkout.json",
+				Status:   models.TestStatusFailed,
+				Error:    "Expected status code 200 but got 402",
+				MetaData: map[string]string{models.SequenceMetaKey: "checkout", models.StepMetaKey: "pay"},
+			},
+		},
+	}
+	generate := func(options models.TestReportOptions) string {
+		reader, err := NewTestReporterService().GenerateReport(context.Background(), report, options)
+		require.NoError(t, err)
+		data, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	console := generate(models.TestReportOptions{Format: "console"})
+	assert.Contains(t, console, "Sequences: 0 passed, 1 failed (1 total)")
+	assert.Contains(t, console, "1. checkout [failed] (1500 ms)")
+	assert.Contains(t, console, "1) create cart [passed] 120 ms, waited 1s after")
+	assert.Contains(t, console, "cartId = 42")
+	assert.Contains(t, console, "Error: Expected status code 200 but got 402")
+
+	html := generate(models.TestReportOptions{Format: "html"})
+	assert.Contains(t, html, `<details class="sequence" open>`)
+	assert.Contains(t, html, "POST https://api.example.com/carts")
+	assert.Contains(t, html, "(waited 1s after)")
+	assert.Contains(t, html, `<span class="phase phase-request" style="left: 0.00%; width: 8.00%" title="request: 120.00ms">`)
+	assert.Contains(t, html, `<span class="phase phase-wait-after" style="left: 10.00%; width: 66.67%"`)
+
+	var suites junitTestSuites
+	require.NoError(t, xml.Unmarshal([]byte(generate(models.TestReportOptions{Format: "junit", JUnitGroupBy: JUnitGroupByTag})), &suites))
+	require.Len(t, suites.TestSuites, 1)
+	assert.Equal(t, "checkout", suites.TestSuites[0].Name)
+	assert.Equal(t, 2, suites.TestSuites[0].Tests)
+	assert.Equal(t, 1, suites.TestSuites[0].Failures)
+	assert.Contains(t, suites.TestSuites[0].TestCases[0].Properties.Properties, junitProperty{Name: "var.cartId", Value: "42"})
+
+	var decoded models.TestReport
+	require.NoError(t, json.Unmarshal([]byte(generate(models.TestReportOptions{Format: "json"})), &decoded))
+	assert.Nil(t, decoded.Sequences[0].StepResults[0].Response)
+	assert.NotNil(t, report.Sequences[0].StepResults[0].Response, "the report itself is left untouched")
+}
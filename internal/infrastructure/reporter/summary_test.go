@@ -0,0 +1,81 @@
+package reporter
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportBreakdowns(t *testing.T) {
+	results := []models.TestResult{
+		{
+			Name:     "listUsers",
+			Tags:     []string{"users"},
+			Request:  &models.HTTPRequest{Method: "GET", URL: "/users"},
+			Response: &models.HTTPResponse{StatusCode: 200},
+			Duration: 30 * time.Millisecond,
+			Status:   models.TestStatusPassed,
+		},
+		{
+			Name:     "createUser",
+			Tags:     []string{"users"},
+			Request:  &models.HTTPRequest{Method: "post", URL: "/users"},
+			Response: &models.HTTPResponse{StatusCode: 500},
+			Duration: 250 * time.Millisecond,
+			Status:   models.TestStatusFailed,
+		},
+		{
+			Name:     "health",
+			Tags:     []string{""},
+			Request:  &models.HTTPRequest{Method: "GET", URL: "/health"},
+			Response: &models.HTTPResponse{StatusCode: 200},
+			Duration: 5 * time.Millisecond,
+			Status:   models.TestStatusPassed,
+		},
+		{Name: "getOrder", Tags: []string{"orders"}, Request: &models.HTTPRequest{Method: "GET"}, Status: models.TestStatusError},
+	}
+
+	report := &models.TestReport{Name: "HTTP Tests", Results: results}
+	report.Summary.AddBreakdowns(results, 2)
+
+	assert.Equal(t, models.StatusCounts{Total: 2, Passed: 1, Failed: 1}, report.Summary.ByTag["users"])
+	assert.Equal(t, models.StatusCounts{Total: 1, Passed: 1}, report.Summary.ByTag[models.UntaggedGroup])
+	assert.Equal(t, models.StatusCounts{Total: 1, Errors: 1}, report.Summary.ByTag["orders"])
+	assert.Equal(t, models.StatusCounts{Total: 3, Passed: 2, Errors: 1}, report.Summary.ByMethod["GET"])
+	assert.Equal(t, models.StatusCounts{Total: 1, Failed: 1}, report.Summary.ByMethod["POST"])
+	assert.Equal(t, map[int]int{200: 2, 500: 1}, report.Summary.StatusCodes)
+	require.Len(t, report.Summary.Slowest, 2)
+	assert.Equal(t, "createUser", report.Summary.Slowest[0].Name)
+	assert.Equal(t, int64(250), report.Summary.Slowest[0].DurationMs)
+	assert.Equal(t, "listUsers", report.Summary.Slowest[1].Name)
+
+	service := NewTestReporterService()
+
+	reader, err := service.GenerateReport(context.Background(), report, models.TestReportOptions{Format: "console"})
+	require.NoError(t, err)
+	console, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Contains(t, string(console), "BY TAG:\n  orders")
+	assert.Contains(t, string(console), "users                          1 passed, 1 failed, 0 errors (2 total)")
+	assert.Contains(t, string(console), "STATUS CODES:\n  200: 2\n  500: 1\n")
+	assert.Contains(t, string(console), "SLOWEST:\n  1. createUser (250 ms)\n  2. listUsers (30 ms)\n")
+
+	reader, err = service.GenerateReport(context.Background(), report, models.TestReportOptions{Format: "html"})
+	require.NoError(t, err)
+	html, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Contains(t, string(html), "<h3>By Tag</h3>")
+	assert.Contains(t, string(html), "<tr><td>POST</td><td>0</td><td>1</td><td>0</td><td>1</td></tr>")
+	assert.Contains(t, string(html), "<tr><td>500</td><td>1</td></tr>")
+
+	reader, err = service.GenerateReport(context.Background(), report, models.TestReportOptions{Format: "json"})
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"statusCodes":{"200":2,"500":1}`)
+}
@@ -0,0 +1,45 @@
+package reporter
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// describeOrigin describes where a value of a variable came from, e.g.
+// "extracted from login: header Authorization (raw: Bearer abc)"
+func describeOrigin(origin models.VariableOrigin) string {
+	description := origin.Source
+	if origin.Step != "" {
+		description += " from " + origin.Step
+	}
+	if origin.Extractor != "" {
+		description += ": " + origin.Extractor
+	}
+	if origin.Raw != "" {
+		description += fmt.Sprintf(" (raw: %s)", models.TruncateString(origin.Raw, 80))
+	}
+	return description
+}
+
+// writeConsoleVariables writes the final value of each variable, grouped by
+// sequence or file, followed by the chain of values it took
+func writeConsoleVariables(buf *bytes.Buffer, report *models.TestReport) {
+	if len(report.Variables) == 0 {
+		return
+	}
+	fmt.Fprintf(buf, "VARIABLES:\n")
+	scope := ""
+	for i, variable := range report.Variables {
+		if i == 0 || variable.Scope != scope {
+			scope = variable.Scope
+			fmt.Fprintf(buf, "  %s:\n", scope)
+		}
+		fmt.Fprintf(buf, "    %s = %s\n", variable.Name, models.TruncateString(variable.Value, 80))
+		for j, origin := range variable.Origins {
+			fmt.Fprintf(buf, "      %d. %s = %s\n", j+1, describeOrigin(origin), models.TruncateString(origin.Value, 80))
+		}
+	}
+	fmt.Fprintf(buf, "\n")
+}
@@ -0,0 +1,49 @@
+package reporter
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVariablesReport(t *testing.T) {
+	report := &models.TestReport{
+		Name: "Sequence Tests",
+		Variables: []models.VariableProvenance{{
+			Scope: "checkout",
+			Name:  "token",
+			Value: "abc",
+			Origins: []models.VariableOrigin{
+				{Source: models.VariableSourceEnvironment, Value: "env-token"},
+				{Source: models.VariableSourceExtracted, Step: "login", Extractor: "header Authorization ~ Bearer (.*)", Raw: "Bearer abc", Value: "abc"},
+			},
+		}},
+	}
+	generate := func(options models.TestReportOptions) string {
+		reader, err := NewTestReporterService().GenerateReport(context.Background(), report, options)
+		require.NoError(t, err)
+		data, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	assert.NotContains(t, generate(models.TestReportOptions{Format: "console"}), "VARIABLES:")
+	console := generate(models.TestReportOptions{Format: "console", Detailed: true})
+	assert.Contains(t, console, "  checkout:\n    token = abc\n")
+	assert.Contains(t, console, "      1. environment = env-token\n")
+	assert.Contains(t, console, "      2. extracted from login: header Authorization ~ Bearer (.*) (raw: Bearer abc) = abc\n")
+
+	html := generate(models.TestReportOptions{Format: "html", Detailed: true})
+	assert.Contains(t, html, "extracted from login: header Authorization")
+
+	var decoded models.TestReport
+	require.NoError(t, json.Unmarshal([]byte(generate(models.TestReportOptions{Format: "json"})), &decoded))
+	assert.Empty(t, decoded.Variables)
+	require.NoError(t, json.Unmarshal([]byte(generate(models.TestReportOptions{Format: "json", Detailed: true})), &decoded))
+	assert.Equal(t, report.Variables, decoded.Variables)
+}
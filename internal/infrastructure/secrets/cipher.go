@@ -0,0 +1,209 @@
+// Package secrets encrypts and decrypts variable files so they can be committed safely
+package secrets
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	// Header marks the first line of an encrypted file
+	Header = "$SWAGGER-TO-HTTP-ENCRYPTED;v1"
+
+	// PassphraseEnv is the environment variable holding the passphrase
+	PassphraseEnv = "SWAGGER_TO_HTTP_VARS_PASSPHRASE"
+
+	// KeyFileEnv is the environment variable holding the path to a key file
+	KeyFileEnv = "SWAGGER_TO_HTTP_VARS_KEY_FILE"
+
+	saltSize   = 16
+	keySize    = 32
+	iterations = 100000
+)
+
+var (
+	// ErrNoKey is returned when an encrypted file is read without a passphrase or key file
+	ErrNoKey = errors.New("file is encrypted but no passphrase or key file was provided")
+
+	// ErrDecrypt is returned when the passphrase is wrong or the file was tampered with
+	ErrDecrypt = errors.New("failed to decrypt: wrong passphrase or corrupted file")
+)
+
+// Cipher encrypts and decrypts data with AES-256-GCM using a key derived from a passphrase
+type Cipher struct {
+	passphrase []byte
+}
+
+// NewCipher creates a cipher for the given passphrase
+func NewCipher(passphrase string) *Cipher {
+	return &Cipher{passphrase: []byte(passphrase)}
+}
+
+// NewCipherFromKeyFile creates a cipher using the contents of a key file as passphrase
+func NewCipherFromKeyFile(path string) (*Cipher, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	key := strings.TrimSpace(string(data))
+	if key == "" {
+		return nil, fmt.Errorf("key file %s is empty", path)
+	}
+
+	return NewCipher(key), nil
+}
+
+// ResolveCipher creates a cipher from a passphrase or key file, falling back to
+// the SWAGGER_TO_HTTP_VARS_PASSPHRASE and SWAGGER_TO_HTTP_VARS_KEY_FILE environment
+// variables. It returns nil when no key is configured.
+func ResolveCipher(passphrase, keyFile string) (*Cipher, error) {
+	if passphrase == "" && keyFile == "" {
+		passphrase = os.Getenv(PassphraseEnv)
+		keyFile = os.Getenv(KeyFileEnv)
+	}
+
+	switch {
+	case passphrase != "":
+		return NewCipher(passphrase), nil
+	case keyFile != "":
+		return NewCipherFromKeyFile(keyFile)
+	default:
+		return nil, nil
+	}
+}
+
+// IsEncrypted checks if data starts with the encrypted file header
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(Header))
+}
+
+// Encrypt encrypts data and encodes it as an armored text file
+func (c *Cipher) Encrypt(data []byte) ([]byte, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	gcm, err := c.gcm(salt)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	// Layout: salt | nonce | ciphertext
+	sealed := append(salt, nonce...)
+	sealed = gcm.Seal(sealed, nonce, data, []byte(Header))
+
+	var b bytes.Buffer
+	b.WriteString(Header)
+	b.WriteString("\n")
+	encoded := base64.StdEncoding.EncodeToString(sealed)
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76])
+		b.WriteString("\n")
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded)
+	b.WriteString("\n")
+
+	return b.Bytes(), nil
+}
+
+// Decrypt decrypts an armored file produced by Encrypt
+func (c *Cipher) Decrypt(data []byte) ([]byte, error) {
+	if !IsEncrypted(data) {
+		return nil, errors.New("data is not encrypted")
+	}
+
+	encoded := strings.Join(strings.Fields(string(data[len(Header):])), "")
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode encrypted data: %w", err)
+	}
+	if len(sealed) < saltSize {
+		return nil, ErrDecrypt
+	}
+
+	gcm, err := c.gcm(sealed[:saltSize])
+	if err != nil {
+		return nil, err
+	}
+
+	rest := sealed[saltSize:]
+	if len(rest) < gcm.NonceSize() {
+		return nil, ErrDecrypt
+	}
+
+	plain, err := gcm.Open(nil, rest[:gcm.NonceSize()], rest[gcm.NonceSize():], []byte(Header))
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+
+	return plain, nil
+}
+
+// DecryptIfNeeded decrypts data when it is encrypted and returns it unchanged otherwise
+func DecryptIfNeeded(data []byte, c *Cipher) ([]byte, error) {
+	if !IsEncrypted(data) {
+		return data, nil
+	}
+	if c == nil {
+		return nil, ErrNoKey
+	}
+	return c.Decrypt(data)
+}
+
+// gcm creates the AES-GCM cipher for the key derived with the given salt
+func (c *Cipher) gcm(salt []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(pbkdf2Key(c.passphrase, salt, iterations, keySize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// pbkdf2Key derives a key with PBKDF2-HMAC-SHA256 (RFC 8018)
+func pbkdf2Key(password, salt []byte, iter, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	hashLen := prf.Size()
+	numBlocks := (keyLen + hashLen - 1) / hashLen
+
+	var counter [4]byte
+	dk := make([]byte, 0, numBlocks*hashLen)
+	u := make([]byte, hashLen)
+	for block := 1; block <= numBlocks; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		binary.BigEndian.PutUint32(counter[:], uint32(block))
+		prf.Write(counter[:])
+		dk = prf.Sum(dk)
+
+		t := dk[len(dk)-hashLen:]
+		copy(u, t)
+		for n := 2; n <= iter; n++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for i := range u {
+				t[i] ^= u[i]
+			}
+		}
+	}
+
+	return dk[:keyLen]
+}
@@ -0,0 +1,79 @@
+package secrets
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	plain := []byte(`{"token": "s3cret"}`)
+
+	encrypted, err := NewCipher("passphrase").Encrypt(plain)
+	require.NoError(t, err)
+	assert.True(t, IsEncrypted(encrypted))
+	assert.NotContains(t, string(encrypted), "s3cret")
+
+	decrypted, err := NewCipher("passphrase").Decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, plain, decrypted)
+
+	_, err = NewCipher("wrong").Decrypt(encrypted)
+	assert.ErrorIs(t, err, ErrDecrypt)
+}
+
+func TestDecryptIfNeeded(t *testing.T) {
+	plain := []byte("TOKEN=abc\n")
+
+	data, err := DecryptIfNeeded(plain, nil)
+	require.NoError(t, err)
+	assert.Equal(t, plain, data)
+
+	encrypted, err := NewCipher("passphrase").Encrypt(plain)
+	require.NoError(t, err)
+
+	_, err = DecryptIfNeeded(encrypted, nil)
+	assert.ErrorIs(t, err, ErrNoKey)
+}
+
+func TestResolveCipher(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "vars.key")
+	require.NoError(t, os.WriteFile(keyFile, []byte("from-file\n"), 0600))
+
+	t.Setenv(PassphraseEnv, "")
+	t.Setenv(KeyFileEnv, "")
+
+	c, err := ResolveCipher("", "")
+	require.NoError(t, err)
+	assert.Nil(t, c)
+
+	c, err = ResolveCipher("", keyFile)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("from-file"), c.passphrase)
+
+	t.Setenv(PassphraseEnv, "from-env")
+	c, err = ResolveCipher("", "")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("from-env"), c.passphrase)
+}
+
+func TestPBKDF2Key(t *testing.T) {
+	// Test vectors for PBKDF2-HMAC-SHA256
+	tests := []struct {
+		iterations int
+		expected   string
+	}{
+		{1, "120fb6cffcf8b32c43e7225256c4f837a86548c92ccc35480805987cb70be17b"},
+		{2, "ae4d0c95af6b46d32d0adff928f06dd02a303f8ef3c251dfd6e2d85a95474c43"},
+		{4096, "c5e478d59288c841aa530db6845c4c8d962893a001ce4e11a4963873aa98134a"},
+	}
+
+	for _, tt := range tests {
+		key := pbkdf2Key([]byte("password"), []byte("salt"), tt.iterations, 32)
+		assert.Equal(t, tt.expected, hex.EncodeToString(key))
+	}
+}
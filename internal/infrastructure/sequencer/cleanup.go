@@ -0,0 +1,100 @@
+package sequencer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// pendingCleanup is a cleanup request registered by a step, resolved when it runs
+// so it can use the variables extracted after the step
+type pendingCleanup struct {
+	request  models.CleanupRequest
+	location string
+	step     *models.HTTPRequest // Request of the step, whose headers such as Authorization are reused
+}
+
+// registerCleanup returns the cleanup registered by a step for the resource its
+// response created, with the Location of the response resolved against the request
+func registerCleanup(step models.TestStep, request *models.HTTPRequest, response *models.HTTPResponse) (pendingCleanup, error) {
+	method, target, err := models.ParseCleanup(step.RegisterCleanup)
+	if err != nil {
+		return pendingCleanup{}, err
+	}
+
+	location := http.Header(response.Headers).Get("Location")
+	if location != "" {
+		if base, err := url.Parse(request.URL); err == nil && base.IsAbs() {
+			if resolved, err := base.Parse(location); err == nil {
+				location = resolved.String()
+			}
+		}
+	}
+
+	return pendingCleanup{
+		request:  models.CleanupRequest{Step: step.Name, Method: method, URL: target},
+		location: location,
+		step:     request,
+	}, nil
+}
+
+// runCleanups runs the cleanup requests in reverse registration order, so
+// resources are deleted before the ones they depend on. They run even when the
+// sequence was cancelled.
+func (s *SequenceRunnerService) runCleanups(ctx context.Context, cleanups []pendingCleanup, variables map[string]string) []models.CleanupResult {
+	ctx = context.WithoutCancel(ctx)
+	results := make([]models.CleanupResult, 0, len(cleanups))
+
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		cleanup := cleanups[i]
+
+		vars := make(map[string]string, len(variables)+1)
+		for k, v := range variables {
+			vars[k] = v
+		}
+		if cleanup.location != "" {
+			vars[models.LocationVariable] = cleanup.location
+		}
+
+		result := models.CleanupResult{CleanupRequest: cleanup.request}
+		result.URL = s.variableExtractor.ReplaceVariables(result.URL, vars, "${%s}")
+		result.URL = s.variableExtractor.ReplaceVariables(result.URL, vars, "{{%s}}")
+		if strings.Contains(result.URL, "{{") || strings.Contains(result.URL, "${") {
+			result.Status = models.TestStatusError
+			result.Error = fmt.Sprintf("cleanup of step %s has unresolved variables: %s", result.Step, result.URL)
+			results = append(results, result)
+			continue
+		}
+
+		startTime := time.Now()
+		response, err := s.httpExecutor.Execute(ctx, &models.HTTPRequest{
+			Name:    "cleanup " + result.Step,
+			Method:  result.Method,
+			URL:     result.URL,
+			Headers: cleanup.step.Headers,
+		}, vars)
+		result.Duration = time.Since(startTime)
+
+		switch {
+		case err != nil:
+			result.Status = models.TestStatusError
+			result.Error = err.Error()
+		case response.StatusCode >= 200 && response.StatusCode < 300, response.StatusCode == http.StatusNotFound:
+			// A resource that is already gone counts as cleaned up
+			result.Status = models.TestStatusPassed
+			result.StatusCode = response.StatusCode
+		default:
+			result.Status = models.TestStatusFailed
+			result.StatusCode = response.StatusCode
+			result.Error = fmt.Sprintf("cleanup of step %s returned status %d", result.Step, response.StatusCode)
+		}
+		results = append(results, result)
+	}
+
+	return results
+}
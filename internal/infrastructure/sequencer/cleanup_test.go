@@ -0,0 +1,82 @@
+package sequencer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/extractor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cleanupExecutor answers requests with a status per URL and records them
+type cleanupExecutor struct {
+	statuses map[string]int
+	requests []string
+}
+
+func (e *cleanupExecutor) Execute(ctx context.Context, request *models.HTTPRequest, variables map[string]string) (*models.HTTPResponse, error) {
+	e.requests = append(e.requests, request.Method+" "+request.URL)
+	return &models.HTTPResponse{StatusCode: e.statuses[request.URL]}, nil
+}
+
+func (e *cleanupExecutor) ExecuteFile(ctx context.Context, file *models.HTTPFile, variables map[string]string) ([]*models.HTTPResponse, error) {
+	return nil, nil
+}
+
+func TestRunCleanups(t *testing.T) {
+	executor := &cleanupExecutor{statuses: map[string]int{
+		"https://api.example.com/users/1":    204,
+		"https://api.example.com/orders/7":   404,
+		"https://api.example.com/invoices/3": 500,
+	}}
+	runner := &SequenceRunnerService{httpExecutor: executor, variableExtractor: extractor.NewVariableExtractorService()}
+
+	createUser, err := registerCleanup(
+		models.TestStep{Name: "create user", RegisterCleanup: "DELETE {{location}}"},
+		&models.HTTPRequest{Method: "POST", URL: "https://api.example.com/users"},
+		&models.HTTPResponse{StatusCode: 201, Headers: map[string][]string{"Location": {"/users/1"}}},
+	)
+	require.NoError(t, err)
+	createOrder, err := registerCleanup(
+		models.TestStep{Name: "create order", RegisterCleanup: "https://api.example.com/orders/${orderId}"},
+		&models.HTTPRequest{Method: "POST", URL: "https://api.example.com/orders"},
+		&models.HTTPResponse{StatusCode: 201},
+	)
+	require.NoError(t, err)
+	createInvoice, err := registerCleanup(
+		models.TestStep{Name: "create invoice", RegisterCleanup: "DELETE https://api.example.com/invoices/{{invoiceId}}"},
+		&models.HTTPRequest{Method: "POST", URL: "https://api.example.com/invoices"},
+		&models.HTTPResponse{StatusCode: 201},
+	)
+	require.NoError(t, err)
+	createRefund, err := registerCleanup(
+		models.TestStep{Name: "create refund", RegisterCleanup: "DELETE {{location}}"},
+		&models.HTTPRequest{Method: "POST", URL: "https://api.example.com/refunds"},
+		&models.HTTPResponse{StatusCode: 202},
+	)
+	require.NoError(t, err)
+
+	// A cancelled sequence still cleans up
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := runner.runCleanups(ctx, []pendingCleanup{createUser, createOrder, createInvoice, createRefund}, map[string]string{
+		"orderId":   "7",
+		"invoiceId": "3",
+	})
+
+	// Cleanups run in reverse order
+	require.Len(t, results, 4)
+	assert.Equal(t, models.TestStatusError, results[0].Status, "refund has no Location")
+	assert.Equal(t, models.TestStatusFailed, results[1].Status)
+	assert.Equal(t, 500, results[1].StatusCode)
+	assert.Equal(t, models.TestStatusPassed, results[2].Status, "404 counts as cleaned up")
+	assert.Equal(t, models.TestStatusPassed, results[3].Status)
+	assert.Equal(t, []string{
+		"DELETE https://api.example.com/invoices/3",
+		"DELETE https://api.example.com/orders/7",
+		"DELETE https://api.example.com/users/1",
+	}, executor.requests)
+}
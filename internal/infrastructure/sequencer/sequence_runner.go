@@ -51,14 +51,29 @@ func (s *SequenceRunnerService) RunSequence(
 		Variables:   make(map[string]string),
 	}
 	
-	// Copy initial variables from sequence and options
-	for k, v := range sequence.Variables {
-		result.Variables[k] = v
-	}
-	for k, v := range options.EnvironmentVars {
-		result.Variables[k] = v
-	}
+	// Start from the variables of the environment, the sequence and the
+	// command line, referenced by their bare or namespaced names such as
+	// ${env.TOKEN}, see models.VariablePrecedence
+	var set models.VariableSet
+	set.Set(models.VariableNamespaceEnv, options.EnvironmentVars)
+	set.Set(models.VariableNamespaceSequence, sequence.Variables)
+	set.Set(models.VariableNamespaceCLI, options.Variables)
+	result.Variables = set.Bare()
+	variables := set.Variables()
+	tracker := models.VariableTrackerFromContext(ctx)
+	tracker.RecordAll(sequence.Name, models.VariableSourceEnvironment, options.EnvironmentVars)
+	tracker.RecordAll(sequence.Name, models.VariableSourceSequence, sequence.Variables)
+	tracker.RecordAll(sequence.Name, models.VariableSourceCLI, options.Variables)
 	
+	// Delete the resources registered by the steps when the sequence ends,
+	// however it ends
+	var cleanups []pendingCleanup
+	defer func() {
+		if len(cleanups) > 0 {
+			result.CleanupResults = s.runCleanups(ctx, cleanups, variables)
+		}
+	}()
+
 	// Run each step in the sequence
 	for _, step := range sequence.Steps {
 		// Check if step should be skipped
@@ -73,18 +88,19 @@ func (s *SequenceRunnerService) RunSequence(
 		// Check skip condition if provided
 		if step.SkipCondition != "" {
 			// Evaluate the skip condition (basic implementation - supports ${var} == "value" syntax)
-			skipCondition := s.variableExtractor.ReplaceVariables(step.SkipCondition, result.Variables, "${%s}")
+			skipCondition := s.variableExtractor.ReplaceVariables(step.SkipCondition, variables, "${%s}")
 			if s.evaluateSkipCondition(skipCondition) {
 				result.StepResults = append(result.StepResults, models.TestSequenceStepResult{
 					Name:   step.Name,
 					Status: models.TestStatusSkipped,
-					Message: fmt.Sprintf("Skipped due to condition: %s", step.SkipCondition),
+					Error:  fmt.Sprintf("Skipped due to condition: %s", step.SkipCondition),
 				})
 				continue
 			}
 		}
 		
 		// Wait before step if specified
+		waitStart := time.Now()
 		if step.WaitBefore > 0 {
 			select {
 			case <-ctx.Done():
@@ -93,11 +109,12 @@ func (s *SequenceRunnerService) RunSequence(
 				// Continue execution after waiting
 			}
 		}
+		waited := time.Since(waitStart)
 		
 		// Create a copy of the request with variables replaced
 		requestWithVars, err := s.variableExtractor.ReplaceVariablesInRequest(
 			step.Request,
-			result.Variables,
+			variables,
 			"${%s}",
 		)
 		if err != nil {
@@ -115,7 +132,7 @@ func (s *SequenceRunnerService) RunSequence(
 		
 		// Execute the request
 		startTime := time.Now()
-		response, err := s.httpExecutor.Execute(ctx, requestWithVars, result.Variables)
+		response, err := s.httpExecutor.Execute(ctx, requestWithVars, variables)
 		executionTime := time.Since(startTime)
 		
 		// Initialize step result
@@ -123,7 +140,24 @@ func (s *SequenceRunnerService) RunSequence(
 			Name:          step.Name,
 			ExecutionTime: executionTime,
 			Variables:     make(map[string]string),
+			Request:       requestWithVars,
+			WaitBefore:    step.WaitBefore,
+			WaitAfter:     step.WaitAfter,
 		}
+
+		// Place the wait and the request on the timeline of the sequence
+		if step.WaitBefore > 0 {
+			stepResult.Phases = append(stepResult.Phases, models.StepPhase{
+				Name:     models.StepPhaseWaitBefore,
+				Offset:   waitStart.Sub(result.StartTime),
+				Duration: waited,
+			})
+		}
+		stepResult.Phases = append(stepResult.Phases, models.StepPhase{
+			Name:     models.StepPhaseRequest,
+			Offset:   startTime.Sub(result.StartTime),
+			Duration: executionTime,
+		})
 		
 		// Handle request execution error
 		if err != nil {
@@ -139,6 +173,22 @@ func (s *SequenceRunnerService) RunSequence(
 		
 		// Store the response
 		stepResult.Response = response
+
+		// Track the resource the step created so it's deleted when the sequence ends
+		if step.RegisterCleanup != "" && response.StatusCode >= 200 && response.StatusCode < 300 {
+			cleanup, err := registerCleanup(step, requestWithVars, response)
+			if err != nil {
+				stepResult.Status = models.TestStatusError
+				stepResult.Error = err.Error()
+				result.StepResults = append(result.StepResults, stepResult)
+				result.Success = false
+				if options.FailFast || step.StopOnFail {
+					break
+				}
+				continue
+			}
+			cleanups = append(cleanups, cleanup)
+		}
 		
 		// Check expected status code if specified
 		if step.ExpectedStatus != 0 && response.StatusCode != step.ExpectedStatus {
@@ -166,6 +216,7 @@ func (s *SequenceRunnerService) RunSequence(
 			
 			if swaggerDoc != nil {
 				// Validate response against swagger schema
+				validationStart := time.Now()
 				validationResult, err := s.schemaValidator.ValidateResponseWithSwagger(
 					ctx,
 					response,
@@ -174,6 +225,7 @@ func (s *SequenceRunnerService) RunSequence(
 					requestWithVars.Method,
 					options.ValidationOptions,
 				)
+				stepResult.AddPhase(models.StepPhaseValidation, result.StartTime, validationStart)
 				
 				if err != nil {
 					stepResult.ValidationError = fmt.Sprintf("Schema validation error: %v", err)
@@ -199,7 +251,9 @@ func (s *SequenceRunnerService) RunSequence(
 		
 		// Evaluate assertions if provided
 		if len(step.Assertions) > 0 {
+			assertionsStart := time.Now()
 			assertionResults, err := s.assertionEvaluator.Evaluate(ctx, response, step.Assertions)
+			stepResult.AddPhase(models.StepPhaseAssertions, result.StartTime, assertionsStart)
 			if err != nil {
 				stepResult.Status = models.TestStatusError
 				stepResult.Error = fmt.Sprintf("Error evaluating assertions: %v", err)
@@ -215,12 +269,12 @@ func (s *SequenceRunnerService) RunSequence(
 			
 			// Check if any assertions failed
 			for _, assertionResult := range assertionResults {
-				if !assertionResult.Succeeded {
+				if !assertionResult.Passed {
 					stepResult.Status = models.TestStatusFailed
 					stepResult.Error = fmt.Sprintf(
 						"Assertion failed: %s - %s",
 						assertionResult.Type,
-						assertionResult.Message,
+						assertionResult.Error,
 					)
 					result.Success = false
 					if options.FailFast || step.StopOnFail {
@@ -238,7 +292,9 @@ func (s *SequenceRunnerService) RunSequence(
 		
 		// Extract variables if provided
 		if len(step.Variables) > 0 {
+			extractionStart := time.Now()
 			extractedVars, err := s.variableExtractor.Extract(ctx, response, step.Variables)
+			stepResult.AddPhase(models.StepPhaseExtraction, result.StartTime, extractionStart)
 			if err != nil {
 				stepResult.Status = models.TestStatusError
 				stepResult.Error = fmt.Sprintf("Error extracting variables: %v", err)
@@ -252,9 +308,12 @@ func (s *SequenceRunnerService) RunSequence(
 			
 			// Store extracted variables
 			for k, v := range extractedVars {
-				result.Variables[k] = v
 				stepResult.Variables[k] = v
 			}
+			set.Add(models.VariableNamespaceExtracted, extractedVars)
+			result.Variables = set.Bare()
+			variables = set.Variables()
+			s.recordExtractedVars(ctx, tracker, sequence.Name, step, response, extractedVars)
 		}
 		
 		// If step status wasn't set by assertions or errors, it passed
@@ -267,12 +326,14 @@ func (s *SequenceRunnerService) RunSequence(
 		
 		// Wait after step if specified
 		if step.WaitAfter > 0 {
+			waitStart := time.Now()
 			select {
 			case <-ctx.Done():
 				return result, ctx.Err()
 			case <-time.After(step.WaitAfter):
 				// Continue execution after waiting
 			}
+			result.StepResults[len(result.StepResults)-1].AddPhase(models.StepPhaseWaitAfter, result.StartTime, waitStart)
 		}
 	}
 	
@@ -311,6 +372,16 @@ func (s *SequenceRunnerService) ParseSequenceFile(
 	// Set the file path
 	sequence.FilePath = filePath
 	
+	// Check the cleanup requests before anything runs
+	for _, step := range sequence.Steps {
+		if step.RegisterCleanup == "" {
+			continue
+		}
+		if _, _, err := models.ParseCleanup(step.RegisterCleanup); err != nil {
+			return nil, fmt.Errorf("step %s: %w", step.Name, err)
+		}
+	}
+
 	// Set default values for steps if needed
 	for i := range sequence.Steps {
 		if sequence.Steps[i].Variables == nil {
@@ -410,6 +481,40 @@ func (s *SequenceRunnerService) matchesFilter(sequence *models.TestSequence, fil
 	return true
 }
 
+// recordExtractedVars records the provenance of the variables a step
+// extracted: the step, the extractor and, for extractors with a regexp, the
+// value the regexp was applied to
+func (s *SequenceRunnerService) recordExtractedVars(
+	ctx context.Context,
+	tracker *models.VariableTracker,
+	sequence string,
+	step models.TestStep,
+	response *models.HTTPResponse,
+	extractedVars map[string]string,
+) {
+	for _, extraction := range step.Variables {
+		value, ok := extractedVars[extraction.Name]
+		if !ok {
+			continue
+		}
+		origin := models.VariableOrigin{
+			Source:    models.VariableSourceExtracted,
+			Step:      step.Name,
+			Extractor: strings.TrimSpace(extraction.Source + " " + extraction.Path),
+			Value:     value,
+		}
+		if extraction.Regexp != "" {
+			origin.Extractor += " ~ " + extraction.Regexp
+			raw := extraction
+			raw.Regexp = ""
+			if values, err := s.variableExtractor.Extract(ctx, response, []models.VariableExtraction{raw}); err == nil {
+				origin.Raw = models.TruncateString(values[raw.Name], 200)
+			}
+		}
+		tracker.Record(sequence, extraction.Name, origin)
+	}
+}
+
 // evaluateSkipCondition evaluates a skip condition expression
 // Supports basic syntax: "value1 == value2", "value1 != value2"
 func (s *SequenceRunnerService) evaluateSkipCondition(condition string) bool {
@@ -0,0 +1,65 @@
+package sequencer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// bodyExecutor answers requests with 200 OK and the body of their URL, and
+// records the URLs requested
+type bodyExecutor struct {
+	bodies map[string]string
+	urls   []string
+}
+
+func (e *bodyExecutor) Execute(ctx context.Context, request *models.HTTPRequest, variables map[string]string) (*models.HTTPResponse, error) {
+	e.urls = append(e.urls, request.URL)
+	return &models.HTTPResponse{StatusCode: 200, Body: e.bodies[request.URL]}, nil
+}
+
+func (e *bodyExecutor) ExecuteFile(ctx context.Context, file *models.HTTPFile, variables map[string]string) ([]*models.HTTPResponse, error) {
+	return nil, nil
+}
+
+func TestRunSequence_VariablePrecedence(t *testing.T) {
+	executor := &bodyExecutor{bodies: map[string]string{
+		"https://api.example.com/eu/cli/env-token?env=env-region": "us",
+	}}
+	runner := NewSequenceRunnerService(executor, nil)
+
+	sequence := &models.TestSequence{
+		Name:      "regions",
+		Variables: map[string]string{"region": "eu", "tenant": "sequence"},
+		Steps: []models.TestStep{
+			{
+				Name:      "login",
+				Request:   &models.HTTPRequest{Method: "GET", URL: "https://api.example.com/${region}/${tenant}/${token}?env=${env.region}"},
+				Variables: []models.VariableExtraction{{Name: "region", Source: "body"}},
+			},
+			{
+				Name:    "profile",
+				Request: &models.HTTPRequest{Method: "GET", URL: "https://api.example.com/${region}/${sequence.region}"},
+			},
+		},
+	}
+	options := models.TestRunOptions{
+		EnvironmentVars: map[string]string{"region": "env-region", "tenant": "env-tenant", "token": "env-token"},
+		Variables:       map[string]string{"tenant": "cli"},
+	}
+
+	result, err := runner.RunSequence(context.Background(), sequence, options)
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+
+	// The sequence overrides the environment, which used to win, extracted
+	// values override the sequence and the command line overrides them all
+	assert.Equal(t, []string{
+		"https://api.example.com/eu/cli/env-token?env=env-region",
+		"https://api.example.com/us/eu",
+	}, executor.urls)
+	assert.Equal(t, map[string]string{"region": "us", "tenant": "cli", "token": "env-token"}, result.Variables)
+}
@@ -0,0 +1,44 @@
+package snapshot
+
+import (
+	"context"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/application/snapshot"
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// RunnerAdapter exposes a snapshot manager to the test runners, choosing the
+// snapshot format by the content type of the response
+type RunnerAdapter struct {
+	manager snapshot.Manager
+}
+
+// NewRunnerAdapter creates a new runner adapter for the manager
+func NewRunnerAdapter(manager snapshot.Manager) *RunnerAdapter {
+	return &RunnerAdapter{manager: manager}
+}
+
+// SaveSnapshot saves a response as a snapshot
+func (a *RunnerAdapter) SaveSnapshot(ctx context.Context, response *models.HTTPResponse, path string) error {
+	return a.manager.SaveSnapshot(response, path, response.ContentType)
+}
+
+// LoadSnapshot loads a snapshot, failing when it doesn't exist
+func (a *RunnerAdapter) LoadSnapshot(ctx context.Context, path string) (*models.HTTPResponse, error) {
+	return a.manager.LoadSnapshot(path, "")
+}
+
+// CompareWithSnapshot compares a response with its snapshot
+func (a *RunnerAdapter) CompareWithSnapshot(ctx context.Context, response *models.HTTPResponse, snapshotPath string) (*models.SnapshotDiff, error) {
+	result, err := a.manager.CompareSnapshots(response, snapshotPath, response.ContentType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.SnapshotDiff{
+		HasDiff:    !result.Matches,
+		DiffString: result.Diff,
+		StatusDiff: !result.StatusMatch,
+		Equal:      result.Matches,
+	}, nil
+}
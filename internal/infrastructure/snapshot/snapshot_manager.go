@@ -2,26 +2,97 @@ package snapshot
 
 import (
 	"fmt"
+	"log"
 	"path/filepath"
 	"strings"
 
 	"github.com/edgardnogueira/swagger-to-http/internal/application/snapshot"
 	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
-	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/fs"
+	"github.com/edgardnogueira/swagger-to-http/internal/infrastructure/snapstore"
+	"github.com/edgardnogueira/swagger-to-http/internal/version"
 )
 
 // SnapshotManager implements the snapshot.Manager interface
 type SnapshotManager struct {
-	fileWriter fs.FileWriter
+	store          snapstore.Store
+	dedupe         bool
+	compression    snapstore.Compression
+	strictVersion  func() bool
+	compareOptions snapshot.CompareOptions
+	ignoreHeaders  models.HeaderIgnoreRules
 }
 
-// NewSnapshotManager creates a new snapshot manager with a file writer
-func NewSnapshotManager(fileWriter fs.FileWriter) snapshot.Manager {
-	return &SnapshotManager{
-		fileWriter: fileWriter,
+// SnapshotManagerOption is a function that configures a SnapshotManager
+type SnapshotManagerOption func(*SnapshotManager)
+
+// WithStrictVersion makes loading snapshots from an incompatible major version an error
+func WithStrictVersion(strict bool) SnapshotManagerOption {
+	return WithStrictVersionFunc(func() bool { return strict })
+}
+
+// WithStrictVersionFunc is WithStrictVersion with the setting read on every
+// load, for settings the command line may still override
+func WithStrictVersionFunc(strict func() bool) SnapshotManagerOption {
+	return func(m *SnapshotManager) {
+		m.strictVersion = strict
+	}
+}
+
+// WithCompareOptions sets the default options for comparing responses with snapshots
+func WithCompareOptions(options snapshot.CompareOptions) SnapshotManagerOption {
+	return func(m *SnapshotManager) {
+		m.compareOptions = options
 	}
 }
 
+// WithIgnoredHeaders sets the headers left out of comparisons, for all
+// requests and per tag
+func WithIgnoredHeaders(rules models.HeaderIgnoreRules) SnapshotManagerOption {
+	return func(m *SnapshotManager) {
+		m.ignoreHeaders = rules
+	}
+}
+
+// WithStore sets where snapshots are stored, the local file system by default
+func WithStore(store snapstore.Store) SnapshotManagerOption {
+	return func(m *SnapshotManager) {
+		m.store = store
+	}
+}
+
+// WithDedupe stores the bodies of snapshots as blobs named by their hash,
+// shared by all snapshots with the same body
+func WithDedupe(dedupe bool) SnapshotManagerOption {
+	return func(m *SnapshotManager) {
+		m.dedupe = dedupe
+	}
+}
+
+// WithCompression compresses the snapshots written; snapshots are read
+// whether they are compressed or not
+func WithCompression(compression snapstore.Compression) SnapshotManagerOption {
+	return func(m *SnapshotManager) {
+		m.compression = compression
+	}
+}
+
+// NewSnapshotManager creates a new snapshot manager
+func NewSnapshotManager(options ...SnapshotManagerOption) snapshot.Manager {
+	manager := &SnapshotManager{
+		store:         snapstore.Local{},
+		strictVersion: func() bool { return false },
+		ignoreHeaders: models.DefaultHeaderIgnoreRules(),
+	}
+
+	// Apply options
+	for _, option := range options {
+		option(manager)
+	}
+	manager.store = snapstore.NewCompressed(manager.store, manager.compression)
+
+	return manager
+}
+
 // SaveSnapshot saves a HTTP response as a snapshot file
 func (m *SnapshotManager) SaveSnapshot(response *models.HTTPResponse, path string, format string) error {
 	// Create formatter for the specified format
@@ -30,30 +101,90 @@ func (m *SnapshotManager) SaveSnapshot(response *models.HTTPResponse, path strin
 		return err
 	}
 
-	// Format the response
-	formatted, err := formatter.Format(response)
+	// Format the response, keeping the header patterns of the snapshot it
+	// replaces
+	formatted, err := formatter.Format(m.keepHeaderPatterns(response, path, format))
 	if err != nil {
 		return err
 	}
 
-	// Ensure the snapshot directory exists
-	dir := filepath.Dir(path)
-	if err := m.fileWriter.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	// Stamp the snapshot with the tool version and the spec of the originating
+	// request, followed by the request as it was sent
+	specHash := ""
+	if response.Request != nil {
+		specHash = response.Request.SpecHash
+	}
+	formatted = fmt.Sprintf("# %s\n%s%s", version.NewStamp(specHash), snapshot.FormatRequestComment(response), formatted)
+
+	// Move the body to its blob when deduplicating
+	data := []byte(formatted)
+	if m.dedupe {
+		if data, err = snapstore.StoreBody(m.store, path, data); err != nil {
+			return err
+		}
 	}
 
 	// Write the snapshot file
-	if err := m.fileWriter.WriteFile(path, []byte(formatted), 0644); err != nil {
+	if err := m.store.Write(path, data); err != nil {
 		return fmt.Errorf("failed to write snapshot file: %w", err)
 	}
 
 	return nil
 }
 
+// keepHeaderPatterns returns the response with the values of the headers
+// that the existing snapshot at path gives as patterns, such as "<<uuid>>",
+// replaced by these patterns as long as they still match, so that updating a
+// snapshot doesn't lose them
+func (m *SnapshotManager) keepHeaderPatterns(response *models.HTTPResponse, path string, format string) *models.HTTPResponse {
+	if exists, err := m.store.Exists(path); err != nil || !exists {
+		return response
+	}
+	existing, err := m.LoadSnapshot(path, format)
+	if err != nil {
+		return response
+	}
+
+	var headers map[string][]string
+	for name, values := range existing.Headers {
+		actual, ok := response.Headers[name]
+		if !ok || !hasHeaderPattern(values) {
+			continue
+		}
+		if matched, err := models.MatchHeaderValues(values, actual); err != nil || !matched {
+			continue
+		}
+		if headers == nil {
+			headers = make(map[string][]string, len(response.Headers))
+			for key, value := range response.Headers {
+				headers[key] = value
+			}
+		}
+		headers[name] = values
+	}
+	if headers == nil {
+		return response
+	}
+
+	kept := *response
+	kept.Headers = headers
+	return &kept
+}
+
+// hasHeaderPattern reports whether any of the values of a header is a pattern
+func hasHeaderPattern(values []string) bool {
+	for _, value := range values {
+		if models.IsHeaderValuePattern(value) {
+			return true
+		}
+	}
+	return false
+}
+
 // LoadSnapshot loads a snapshot from a file
 func (m *SnapshotManager) LoadSnapshot(path string, format string) (*models.HTTPResponse, error) {
 	// Check if the snapshot file exists
-	exists, err := m.fileWriter.FileExists(path)
+	exists, err := m.store.Exists(path)
 	if err != nil {
 		return nil, err
 	}
@@ -63,10 +194,24 @@ func (m *SnapshotManager) LoadSnapshot(path string, format string) (*models.HTTP
 	}
 
 	// Read the snapshot file
-	data, err := m.fileWriter.ReadFile(path)
+	data, err := m.store.Read(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read snapshot file: %w", err)
 	}
+	if data, err = snapstore.LoadBody(m.store, path, data); err != nil {
+		return nil, err
+	}
+
+	// Check the version stamp before parsing
+	content := string(data)
+	if stamp, ok := version.ParseStamp(strings.SplitN(content, "\n", 2)[0]); ok {
+		if err := version.CheckCompatibility(stamp.Version); err != nil {
+			if m.strictVersion() {
+				return nil, fmt.Errorf("snapshot %s: %w", path, err)
+			}
+			log.Printf("Warning: snapshot %s: %v", path, err)
+		}
+	}
 
 	// Get formatter for the specified format
 	formatter, err := snapshot.GetFormatter(format)
@@ -75,7 +220,7 @@ func (m *SnapshotManager) LoadSnapshot(path string, format string) (*models.HTTP
 	}
 
 	// Parse the snapshot
-	return formatter.Parse(string(data))
+	return formatter.Parse(content)
 }
 
 // CompareSnapshots compares a current response with a snapshot
@@ -86,14 +231,55 @@ func (m *SnapshotManager) CompareSnapshots(current *models.HTTPResponse, snapsho
 		return nil, err
 	}
 
+	// Requests may ignore array order, set tolerances, limit the comparison and
+	// ignore headers on their own
+	options := m.compareOptions
+	if current.Request != nil && current.Request.IgnoreArrayOrder {
+		options.IgnoreArrayOrder = true
+		if current.Request.ArrayOrderKey != "" {
+			options.ArrayOrderKey = current.Request.ArrayOrderKey
+		}
+	}
+	if current.Request != nil && len(current.Request.Tolerances) > 0 {
+		tolerances := make(map[string]models.Tolerance, len(options.Tolerances)+len(current.Request.Tolerances))
+		for path, tolerance := range options.Tolerances {
+			tolerances[path] = tolerance
+		}
+		for path, tolerance := range current.Request.Tolerances {
+			tolerances[path] = tolerance
+		}
+		options.Tolerances = tolerances
+	}
+	if current.Request != nil && current.Request.SnapshotCompare != models.SnapshotScopeAll {
+		options.Scope = current.Request.SnapshotCompare
+	}
+
+	// Leave out the headers ignored for all requests, the request's tag and
+	// the request itself
+	var tags, requestPatterns []string
+	if current.Request != nil {
+		tags, requestPatterns = current.Request.TagList(), current.Request.IgnoreHeaders
+	}
+	options.IgnoreHeaders, err = models.NewHeaderMatcher(m.ignoreHeaders.Resolve(tags, requestPatterns))
+	if err != nil {
+		return nil, err
+	}
+
 	// Get formatter for the specified format
-	formatter, err := snapshot.GetFormatter(format)
+	formatter, err := snapshot.GetFormatterWithOptions(format, options)
 	if err != nil {
 		return nil, err
 	}
 
-	// Compare the responses
-	return formatter.Compare(expected, current)
+	// Compare the responses, showing the request behind a mismatch
+	result, err := snapshot.CompareScoped(formatter, expected, current, options.Scope)
+	if err != nil {
+		return nil, err
+	}
+	if !result.Matches {
+		result.Diff += snapshot.DescribeRequests(expected.ResolvedRequest, current.ResolvedRequest)
+	}
+	return result, nil
 }
 
 // GetSnapshotPath generates a snapshot path for a HTTP request
@@ -119,7 +305,18 @@ func (m *SnapshotManager) GetSnapshotPath(httpFile string, requestName string, b
 
 // ListSnapshots returns a list of all snapshot files
 func (m *SnapshotManager) ListSnapshots(snapshotsDir string) ([]string, error) {
-	return m.fileWriter.Glob(filepath.Join(snapshotsDir, "**/*.snap"))
+	files, err := m.store.List(snapshotsDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	var snapshots []string
+	for _, file := range files {
+		if filepath.Ext(file) == ".snap" {
+			snapshots = append(snapshots, file)
+		}
+	}
+	return snapshots, nil
 }
 
 // CleanupSnapshots removes orphaned snapshots that don't have corresponding HTTP requests
@@ -133,11 +330,13 @@ func (m *SnapshotManager) CleanupSnapshots(snapshotsDir string, activeSnapshots
 	// Remove snapshots that are not in the active set
 	for _, snap := range allSnapshots {
 		if !activeSnapshots[snap] {
-			if err := m.fileWriter.Remove(snap); err != nil {
+			if err := m.store.Remove(snap); err != nil {
 				return fmt.Errorf("failed to remove orphaned snapshot %s: %w", snap, err)
 			}
 		}
 	}
 
-	return nil
+	// Remove the bodies no remaining snapshot references
+	_, err = snapstore.CollectBlobs(m.store, snapshotsDir)
+	return err
 }
@@ -0,0 +1,144 @@
+package snapstore
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// BlobDir is the directory holding the bodies shared by snapshots, named by
+// their SHA-256 hash, next to the snapshots under __snapshots__
+const BlobDir = "__blobs__"
+
+// blobReferencePrefix starts the body of a snapshot whose body is in a blob
+const blobReferencePrefix = "@body sha256:"
+
+// Reader reads files, such as a Store or the files of a git revision
+type Reader interface {
+	Read(path string) ([]byte, error)
+}
+
+// StoreBody moves the body of the content of a snapshot to the blob named by
+// its hash, writing the blob unless a snapshot with the same body already
+// did, and returns the content referencing it. Content without a body is
+// returned as it is.
+func StoreBody(store Store, snapshotPath string, content []byte) ([]byte, error) {
+	head, body, ok := splitBody(string(content))
+	if !ok || body == "" || isBlobReference(body) {
+		return content, nil
+	}
+
+	sum := sha256.Sum256([]byte(body))
+	hash := hex.EncodeToString(sum[:])
+	path := blobPath(snapshotPath, hash)
+	exists, err := store.Exists(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check snapshot body %s: %w", hash, err)
+	}
+	if !exists {
+		if err := store.Write(path, []byte(body)); err != nil {
+			return nil, fmt.Errorf("failed to write snapshot body %s: %w", hash, err)
+		}
+	}
+	return []byte(head + blobReferencePrefix + hash + "\n"), nil
+}
+
+// LoadBody returns the content of a snapshot with the body it references, if
+// any, read from its blob
+func LoadBody(store Reader, snapshotPath string, content []byte) ([]byte, error) {
+	head, body, ok := splitBody(string(content))
+	if !ok || !isBlobReference(body) {
+		return content, nil
+	}
+
+	hash := strings.TrimSpace(strings.TrimPrefix(body, blobReferencePrefix))
+	if _, err := hex.DecodeString(hash); err != nil || len(hash) != sha256.Size*2 {
+		return nil, fmt.Errorf("invalid body reference in snapshot %s: %q", snapshotPath, hash)
+	}
+	data, err := store.Read(blobPath(snapshotPath, hash))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body of snapshot %s: %w", snapshotPath, err)
+	}
+	return []byte(head + string(data)), nil
+}
+
+// CollectBlobs removes the blobs under a directory that no snapshot under it
+// references any more, returning their paths
+func CollectBlobs(store Store, dir string) ([]string, error) {
+	files, err := store.List(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	referenced := make(map[string]bool)
+	var blobs []string
+	for _, file := range files {
+		if isBlob(file) {
+			blobs = append(blobs, file)
+			continue
+		}
+		content, err := store.Read(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read snapshot %s: %w", file, err)
+		}
+		if _, body, ok := splitBody(string(content)); ok && isBlobReference(body) {
+			referenced[strings.TrimSpace(strings.TrimPrefix(body, blobReferencePrefix))] = true
+		}
+	}
+
+	var removed []string
+	for _, blob := range blobs {
+		if referenced[filepath.Base(blob)] {
+			continue
+		}
+		if err := store.Remove(blob); err != nil {
+			return removed, fmt.Errorf("failed to remove snapshot body %s: %w", blob, err)
+		}
+		removed = append(removed, blob)
+	}
+	return removed, nil
+}
+
+// splitBody splits the content of a snapshot after the blank line ending its
+// headers
+func splitBody(content string) (head, body string, ok bool) {
+	i := strings.Index(content, "\n\n")
+	if i < 0 {
+		return content, "", false
+	}
+	return content[:i+2], content[i+2:], true
+}
+
+// isBlobReference reports whether the body of a snapshot references a blob
+func isBlobReference(body string) bool {
+	return strings.HasPrefix(body, blobReferencePrefix) && !strings.Contains(strings.TrimSpace(body), "\n")
+}
+
+// blobPath returns the path of the blob of a hash for a snapshot: under the
+// __snapshots__ directory holding it, so that all its snapshots share the
+// blobs, or next to it otherwise
+func blobPath(snapshotPath, hash string) string {
+	dir := filepath.Dir(snapshotPath)
+	for d := dir; ; d = filepath.Dir(d) {
+		if filepath.Base(d) == "__snapshots__" {
+			dir = d
+			break
+		}
+		if parent := filepath.Dir(d); parent == d {
+			break
+		}
+	}
+	return filepath.Join(dir, BlobDir, hash[:2], hash)
+}
+
+// isBlob reports whether a file is a blob
+func isBlob(path string) bool {
+	for _, part := range strings.Split(filepath.ToSlash(path), "/") {
+		if part == BlobDir {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package snapstore
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreBody(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "__snapshots__")
+	store := Local{}
+	content := "HTTP 404 Not Found\nContent-Type: application/json\n\n{\n  \"error\": \"not found\"\n}"
+
+	first := filepath.Join(dir, "users", "getUser.snap")
+	stored, err := StoreBody(store, first, []byte(content))
+	require.NoError(t, err)
+	assert.Regexp(t, `^HTTP 404 Not Found\nContent-Type: application/json\n\n@body sha256:[0-9a-f]{64}\n$`, string(stored))
+	require.NoError(t, store.Write(first, stored))
+
+	second := filepath.Join(dir, "orders", "getOrder.snap")
+	other, err := StoreBody(store, second, []byte(content))
+	require.NoError(t, err)
+	assert.Equal(t, string(stored), string(other))
+
+	loaded, err := LoadBody(store, second, other)
+	require.NoError(t, err)
+	assert.Equal(t, content, string(loaded))
+
+	files, err := store.List(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+
+	// Without a body the content is kept as it is
+	empty, err := StoreBody(store, first, []byte("HTTP 204 No Content\n\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "HTTP 204 No Content\n\n", string(empty))
+}
+
+func TestCollectBlobs(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "__snapshots__")
+	store := Local{}
+	path := filepath.Join(dir, "getUser.snap")
+
+	kept, err := StoreBody(store, path, []byte("HTTP 200 OK\n\n{\"id\": 1}"))
+	require.NoError(t, err)
+	require.NoError(t, store.Write(path, kept))
+	_, err = StoreBody(store, path, []byte("HTTP 200 OK\n\n{\"id\": 2}"))
+	require.NoError(t, err)
+
+	removed, err := CollectBlobs(store, dir)
+	require.NoError(t, err)
+	assert.Len(t, removed, 1)
+
+	loaded, err := LoadBody(store, path, kept)
+	require.NoError(t, err)
+	assert.Equal(t, "HTTP 200 OK\n\n{\"id\": 1}", string(loaded))
+
+	// Blobs referenced by snapshots of other extensions are kept too
+	other := filepath.Join(dir, "getUser.json")
+	stored, err := StoreBody(store, other, []byte("HTTP 200 OK\n\n{\"id\": 3}"))
+	require.NoError(t, err)
+	require.NoError(t, store.Write(other, stored))
+
+	removed, err = CollectBlobs(store, dir)
+	require.NoError(t, err)
+	assert.Empty(t, removed)
+	loaded, err = LoadBody(store, other, stored)
+	require.NoError(t, err)
+	assert.Equal(t, "HTTP 200 OK\n\n{\"id\": 3}", string(loaded))
+}
@@ -0,0 +1,144 @@
+package snapstore
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path/filepath"
+)
+
+// Compression is how snapshot files are compressed when written
+type Compression string
+
+// Compressions of snapshot files
+const (
+	CompressionNone Compression = "none"
+	CompressionGzip Compression = "gzip"
+)
+
+// Magic bytes starting compressed files
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// ParseCompression parses the compression of snapshots.compression, none
+// when empty
+func ParseCompression(value string) (Compression, error) {
+	switch Compression(value) {
+	case "", CompressionNone:
+		return CompressionNone, nil
+	case CompressionGzip:
+		return CompressionGzip, nil
+	default:
+		return "", fmt.Errorf("invalid snapshot compression %q: use none or gzip", value)
+	}
+}
+
+// Compress compresses the content of a file
+func Compress(data []byte, compression Compression) ([]byte, error) {
+	if compression != CompressionGzip {
+		return data, nil
+	}
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to compress snapshot: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress snapshot: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Decompress returns the content of a file compressed or not, telling them
+// apart by their magic bytes
+func Decompress(data []byte) ([]byte, error) {
+	switch {
+	case bytes.HasPrefix(data, gzipMagic):
+		reader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress snapshot: %w", err)
+		}
+		defer reader.Close()
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress snapshot: %w", err)
+		}
+		return content, nil
+	case bytes.HasPrefix(data, zstdMagic):
+		return nil, fmt.Errorf("zstd compressed snapshots are not supported, recompress them with gzip")
+	default:
+		return data, nil
+	}
+}
+
+// compressed compresses the files written to a store and decompresses those
+// read from it
+type compressed struct {
+	Store
+	compression Compression
+}
+
+// NewCompressed compresses the files written to a store with a compression,
+// and reads its files whether they are compressed or not
+func NewCompressed(store Store, compression Compression) Store {
+	return &compressed{Store: store, compression: compression}
+}
+
+func (c *compressed) Read(path string) ([]byte, error) {
+	data, err := c.Store.Read(path)
+	if err != nil {
+		return nil, err
+	}
+	content, err := Decompress(data)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot %s: %w", path, err)
+	}
+	return content, nil
+}
+
+func (c *compressed) Write(path string, data []byte) error {
+	data, err := Compress(data, c.compression)
+	if err != nil {
+		return err
+	}
+	return c.Store.Write(path, data)
+}
+
+// Migrate rewrites the snapshots and blobs under a directory of a store with
+// a compression, returning the paths of those that changed
+func Migrate(store Store, dir string, compression Compression) ([]string, error) {
+	files, err := store.List(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	var migrated []string
+	for _, file := range files {
+		if filepath.Ext(file) != ".snap" && !isBlob(file) {
+			continue
+		}
+		data, err := store.Read(file)
+		if err != nil {
+			return migrated, fmt.Errorf("failed to read snapshot %s: %w", file, err)
+		}
+		content, err := Decompress(data)
+		if err != nil {
+			return migrated, fmt.Errorf("snapshot %s: %w", file, err)
+		}
+		target, err := Compress(content, compression)
+		if err != nil {
+			return migrated, err
+		}
+		if bytes.Equal(target, data) {
+			continue
+		}
+		if err := store.Write(file, target); err != nil {
+			return migrated, fmt.Errorf("failed to write snapshot %s: %w", file, err)
+		}
+		migrated = append(migrated, file)
+	}
+	return migrated, nil
+}
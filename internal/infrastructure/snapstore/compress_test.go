@@ -0,0 +1,69 @@
+package snapstore
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressed(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "getUser.snap")
+	content := "HTTP 200 OK\nContent-Type: application/json\n\n{\"id\": 1}"
+
+	store := NewCompressed(Local{}, CompressionGzip)
+	require.NoError(t, store.Write(path, []byte(content)))
+
+	raw, err := Local{}.Read(path)
+	require.NoError(t, err)
+	assert.Equal(t, gzipMagic, raw[:2])
+
+	data, err := store.Read(path)
+	require.NoError(t, err)
+	assert.Equal(t, content, string(data))
+
+	// Plain files are read as they are
+	data, err = NewCompressed(Local{}, CompressionGzip).Read(path)
+	require.NoError(t, err)
+	assert.Equal(t, content, string(data))
+	_, err = Decompress(append(zstdMagic, 0))
+	assert.Error(t, err)
+}
+
+func TestMigrate(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "getUser.snap")
+	require.NoError(t, Local{}.Write(path, []byte("HTTP 204 No Content\n\n")))
+	require.NoError(t, Local{}.Write(filepath.Join(dir, "notes.txt"), []byte("notes")))
+
+	migrated, err := Migrate(Local{}, dir, CompressionGzip)
+	require.NoError(t, err)
+	assert.Equal(t, []string{path}, migrated)
+
+	migrated, err = Migrate(Local{}, dir, CompressionGzip)
+	require.NoError(t, err)
+	assert.Empty(t, migrated)
+
+	migrated, err = Migrate(Local{}, dir, CompressionNone)
+	require.NoError(t, err)
+	assert.Equal(t, []string{path}, migrated)
+	data, err := Local{}.Read(path)
+	require.NoError(t, err)
+	assert.Equal(t, "HTTP 204 No Content\n\n", string(data))
+
+	// Files other than snapshots and blobs are left as they are
+	data, err = Local{}.Read(filepath.Join(dir, "notes.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "notes", string(data))
+}
+
+func TestParseCompression(t *testing.T) {
+	compression, err := ParseCompression("")
+	require.NoError(t, err)
+	assert.Equal(t, CompressionNone, compression)
+
+	_, err = ParseCompression("brotli")
+	assert.Error(t, err)
+}
@@ -0,0 +1,174 @@
+package snapstore
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// GCSConfig holds the endpoint and credentials of a Cloud Storage bucket
+type GCSConfig struct {
+	Endpoint string // Endpoint of an emulator; Cloud Storage when empty
+	Token    string // OAuth 2.0 access token, e.g. from `gcloud auth print-access-token`
+	Client   *http.Client
+}
+
+// GCSConfigFromEnv reads the configuration from STORAGE_EMULATOR_HOST and
+// GOOGLE_OAUTH_ACCESS_TOKEN
+func GCSConfigFromEnv() GCSConfig {
+	endpoint := os.Getenv("STORAGE_EMULATOR_HOST")
+	if endpoint != "" && !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+	return GCSConfig{
+		Endpoint: endpoint,
+		Token:    os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"),
+	}
+}
+
+// GCS stores snapshots as the objects of a Cloud Storage bucket under a
+// prefix, through the JSON API
+type GCS struct {
+	bucket string
+	prefix string
+	config GCSConfig
+}
+
+// NewGCS creates a store for the objects of a bucket under a prefix
+func NewGCS(bucket, prefix string, config GCSConfig) *GCS {
+	if config.Endpoint == "" {
+		config.Endpoint = "https://storage.googleapis.com"
+	}
+	config.Endpoint = strings.TrimSuffix(config.Endpoint, "/")
+	if config.Client == nil {
+		config.Client = &http.Client{Timeout: time.Minute}
+	}
+	return &GCS{bucket: bucket, prefix: prefix, config: config}
+}
+
+// Read downloads the object of a file
+func (g *GCS) Read(path string) ([]byte, error) {
+	resp, err := g.do(http.MethodGet, g.objectURL(path)+"?alt=media", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("snapshot %s: %w", path, fs.ErrNotExist)
+	}
+	if err := checkStatus(resp, "read snapshot "+path); err != nil {
+		return nil, err
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// Write uploads the object of a file
+func (g *GCS) Write(path string, data []byte) error {
+	endpoint := fmt.Sprintf("%s/upload/storage/v1/b/%s/o?uploadType=media&name=%s",
+		g.config.Endpoint, url.PathEscape(g.bucket), url.QueryEscape(objectKey(g.prefix, path)))
+	resp, err := g.do(http.MethodPost, endpoint, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return checkStatus(resp, "write snapshot "+path)
+}
+
+// Exists reports whether the object of a file exists
+func (g *GCS) Exists(path string) (bool, error) {
+	resp, err := g.do(http.MethodGet, g.objectURL(path), nil)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if err := checkStatus(resp, "check snapshot "+path); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// gcsListResult is the part of an objects list response the store reads
+type gcsListResult struct {
+	Items []struct {
+		Name string `json:"name"`
+	} `json:"items"`
+	NextPageToken string `json:"nextPageToken"`
+}
+
+// List returns the files under a directory, following the pages of the listing
+func (g *GCS) List(dir string) ([]string, error) {
+	var paths []string
+	query := url.Values{"prefix": {listPrefix(g.prefix, dir)}}
+	for {
+		endpoint := fmt.Sprintf("%s/storage/v1/b/%s/o?%s", g.config.Endpoint, url.PathEscape(g.bucket), query.Encode())
+		resp, err := g.do(http.MethodGet, endpoint, nil)
+		if err != nil {
+			return nil, err
+		}
+		var result gcsListResult
+		err = checkStatus(resp, "list snapshots")
+		if err == nil {
+			err = json.NewDecoder(resp.Body).Decode(&result)
+		}
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list snapshots: %w", err)
+		}
+
+		for _, object := range result.Items {
+			paths = append(paths, localPath(g.prefix, object.Name))
+		}
+		if result.NextPageToken == "" {
+			break
+		}
+		query.Set("pageToken", result.NextPageToken)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// Remove deletes the object of a file
+func (g *GCS) Remove(path string) error {
+	resp, err := g.do(http.MethodDelete, g.objectURL(path), nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return checkStatus(resp, "remove snapshot "+path)
+}
+
+// objectURL returns the URL of the metadata of the object of a file
+func (g *GCS) objectURL(path string) string {
+	return fmt.Sprintf("%s/storage/v1/b/%s/o/%s", g.config.Endpoint, url.PathEscape(g.bucket), url.PathEscape(objectKey(g.prefix, path)))
+}
+
+// do sends a request authorized with the access token, if any
+func (g *GCS) do(method, endpoint string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequest(method, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Cloud Storage request: %w", err)
+	}
+	if g.config.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+g.config.Token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+
+	resp, err := g.config.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach Cloud Storage: %w", err)
+	}
+	return resp, nil
+}
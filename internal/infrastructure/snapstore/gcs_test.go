@@ -0,0 +1,65 @@
+package snapstore
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGCS(t *testing.T) {
+	objects := make(map[string][]byte)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		switch {
+		case r.URL.Path == "/upload/storage/v1/b/snapshots/o":
+			objects[r.URL.Query().Get("name")], _ = io.ReadAll(r.Body)
+		case r.URL.Path == "/storage/v1/b/snapshots/o":
+			var items []map[string]string
+			for name := range objects {
+				if strings.HasPrefix(name, r.URL.Query().Get("prefix")) {
+					items = append(items, map[string]string{"name": name})
+				}
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"items": items})
+		default:
+			name, _ := url.PathUnescape(strings.TrimPrefix(r.URL.EscapedPath(), "/storage/v1/b/snapshots/o/"))
+			data, ok := objects[name]
+			switch {
+			case !ok:
+				w.WriteHeader(http.StatusNotFound)
+			case r.Method == http.MethodDelete:
+				delete(objects, name)
+				w.WriteHeader(http.StatusNoContent)
+			case r.URL.Query().Get("alt") == "media":
+				w.Write(data)
+			default:
+				json.NewEncoder(w).Encode(map[string]string{"name": name})
+			}
+		}
+	}))
+	defer server.Close()
+	store := NewGCS("snapshots", "ci", GCSConfig{Endpoint: server.URL, Token: "token"})
+
+	require.NoError(t, store.Write("snapshots/users/getUser.snap", []byte("snapshot")))
+	assert.Contains(t, objects, "ci/snapshots/users/getUser.snap")
+
+	data, err := store.Read("snapshots/users/getUser.snap")
+	require.NoError(t, err)
+	assert.Equal(t, "snapshot", string(data))
+
+	paths, err := store.List("snapshots")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"snapshots/users/getUser.snap"}, paths)
+
+	require.NoError(t, store.Remove("snapshots/users/getUser.snap"))
+	exists, err := store.Exists("snapshots/users/getUser.snap")
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
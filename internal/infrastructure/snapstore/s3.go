@@ -0,0 +1,286 @@
+package snapstore
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Config holds the region, endpoint and credentials of an S3 bucket
+type S3Config struct {
+	Region          string
+	Endpoint        string // Endpoint of S3-compatible storage such as MinIO, addressed path-style; AWS when empty
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Client          *http.Client
+}
+
+// S3ConfigFromEnv reads the configuration from the standard AWS environment
+// variables: AWS_REGION (or AWS_DEFAULT_REGION), AWS_ENDPOINT_URL_S3 (or
+// AWS_ENDPOINT_URL), AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and
+// AWS_SESSION_TOKEN
+func S3ConfigFromEnv() S3Config {
+	return S3Config{
+		Region:          firstEnv("AWS_REGION", "AWS_DEFAULT_REGION"),
+		Endpoint:        firstEnv("AWS_ENDPOINT_URL_S3", "AWS_ENDPOINT_URL"),
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+}
+
+// S3 stores snapshots as the objects of an S3 bucket under a prefix, with
+// requests signed with AWS Signature Version 4
+type S3 struct {
+	bucket string
+	prefix string
+	config S3Config
+	now    func() time.Time
+}
+
+// NewS3 creates a store for the objects of a bucket under a prefix
+func NewS3(bucket, prefix string, config S3Config) *S3 {
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	if config.Client == nil {
+		config.Client = &http.Client{Timeout: time.Minute}
+	}
+	return &S3{bucket: bucket, prefix: prefix, config: config, now: time.Now}
+}
+
+// Read downloads the object of a file
+func (s *S3) Read(path string) ([]byte, error) {
+	resp, err := s.do(http.MethodGet, objectKey(s.prefix, path), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("snapshot %s: %w", path, fs.ErrNotExist)
+	}
+	if err := checkStatus(resp, "read snapshot "+path); err != nil {
+		return nil, err
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// Write uploads the object of a file
+func (s *S3) Write(path string, data []byte) error {
+	resp, err := s.do(http.MethodPut, objectKey(s.prefix, path), nil, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return checkStatus(resp, "write snapshot "+path)
+}
+
+// Exists reports whether the object of a file exists
+func (s *S3) Exists(path string) (bool, error) {
+	resp, err := s.do(http.MethodHead, objectKey(s.prefix, path), nil, nil)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if err := checkStatus(resp, "check snapshot "+path); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// s3ListResult is the part of a ListObjectsV2 response the store reads
+type s3ListResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// List returns the files under a directory, following the pages of the listing
+func (s *S3) List(dir string) ([]string, error) {
+	var paths []string
+	query := url.Values{"list-type": {"2"}, "prefix": {listPrefix(s.prefix, dir)}}
+	for {
+		resp, err := s.do(http.MethodGet, "", query, nil)
+		if err != nil {
+			return nil, err
+		}
+		var result s3ListResult
+		err = checkStatus(resp, "list snapshots")
+		if err == nil {
+			err = xml.NewDecoder(resp.Body).Decode(&result)
+		}
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list snapshots: %w", err)
+		}
+
+		for _, object := range result.Contents {
+			paths = append(paths, localPath(s.prefix, object.Key))
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			break
+		}
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// Remove deletes the object of a file
+func (s *S3) Remove(path string) error {
+	resp, err := s.do(http.MethodDelete, objectKey(s.prefix, path), nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return checkStatus(resp, "remove snapshot "+path)
+}
+
+// do sends a signed request for an object of the bucket, or the bucket itself
+// when the key is empty
+func (s *S3) do(method, key string, query url.Values, body []byte) (*http.Response, error) {
+	var endpoint string
+	if s.config.Endpoint != "" {
+		endpoint = strings.TrimSuffix(s.config.Endpoint, "/") + "/" + s.bucket + "/" + key
+	} else {
+		endpoint = fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", s.bucket, s.config.Region, key)
+	}
+	req, err := http.NewRequest(method, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 request: %w", err)
+	}
+	req.URL.RawQuery = query.Encode()
+	if s.config.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.config.SessionToken)
+	}
+	signV4(req, body, s.config, s.now())
+
+	resp, err := s.config.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach S3: %w", err)
+	}
+	return resp, nil
+}
+
+// signV4 signs a request for S3 with AWS Signature Version 4, signing all its
+// headers along with the host
+func signV4(req *http.Request, body []byte, config S3Config, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	payloadHash := sha256Hex(body)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uriEncode(req.URL.Path, false),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + config.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+config.SecretAccessKey), date)
+	key = hmacSHA256(key, config.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		config.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// canonicalQuery encodes a query sorted by name, as signed
+func canonicalQuery(query url.Values) string {
+	pairs := make([]string, 0, len(query))
+	for name, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, uriEncode(name, true)+"="+uriEncode(value, true))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode percent-encodes everything but the unreserved characters, and
+// the slashes of paths unless encodeSlash is set
+func uriEncode(s string, encodeSlash bool) string {
+	var encoded strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			encoded.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			encoded.WriteByte(c)
+		default:
+			fmt.Fprintf(&encoded, "%%%02X", c)
+		}
+	}
+	return encoded.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// checkStatus turns the error responses of a bucket into errors
+func checkStatus(resp *http.Response, action string) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("failed to %s: %s: %s", action, resp.Status, strings.TrimSpace(string(body)))
+}
+
+// firstEnv returns the first environment variable of the names that is set
+func firstEnv(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,309 @@
+// Package snapstore stores snapshots on the local file system or in a bucket
+// shared by CI jobs on ephemeral runners, selected by a location such as
+// "snapshots", "s3://bucket/prefix" or "gs://bucket/prefix".
+package snapstore
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Store reads and writes snapshot files by their local path, e.g.
+// "snapshots/__snapshots__/users/getUser.snap"
+type Store interface {
+	// Read returns the content of a file, an error wrapping fs.ErrNotExist
+	// when there is none
+	Read(path string) ([]byte, error)
+
+	// Write creates or replaces a file
+	Write(path string, data []byte) error
+
+	// Exists reports whether a file exists
+	Exists(path string) (bool, error)
+
+	// List returns the paths of the files under a directory, sorted
+	List(dir string) ([]string, error)
+
+	// Remove deletes a file
+	Remove(path string) error
+}
+
+// Open opens the store at a location: the local file system for "" or a
+// path, and a cached S3 or GCS bucket for "s3://bucket/prefix" or
+// "gs://bucket/prefix", with credentials from the environment
+func Open(location string) (Store, error) {
+	scheme, rest, ok := strings.Cut(location, "://")
+	if !ok {
+		return Local{}, nil
+	}
+	bucket, prefix, _ := strings.Cut(rest, "/")
+	if bucket == "" {
+		return nil, fmt.Errorf("invalid snapshot store %q: missing bucket", location)
+	}
+
+	switch scheme {
+	case "s3":
+		return NewCached(NewS3(bucket, prefix, S3ConfigFromEnv())), nil
+	case "gs":
+		return NewCached(NewGCS(bucket, prefix, GCSConfigFromEnv())), nil
+	default:
+		return nil, fmt.Errorf("invalid snapshot store %q: use s3://bucket/prefix or gs://bucket/prefix", location)
+	}
+}
+
+// Local stores snapshots on the local file system
+type Local struct{}
+
+// Read returns the content of a file
+func (Local) Read(path string) ([]byte, error) {
+	return os.ReadFile(path)
+}
+
+// Write creates or replaces a file, creating its directory
+func (Local) Write(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Exists reports whether a file exists
+func (Local) Exists(path string) (bool, error) {
+	_, err := os.Stat(path)
+	if err == nil {
+		return true, nil
+	}
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return false, err
+}
+
+// List returns the paths of the files under a directory, none when it
+// doesn't exist
+func (Local) List(dir string) ([]string, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if !entry.IsDir() {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	return paths, err
+}
+
+// Remove deletes a file
+func (Local) Remove(path string) error {
+	return os.Remove(path)
+}
+
+// objectKey returns the key of the object holding the file at a local path
+// in a bucket, under the prefix of the store
+func objectKey(prefix, localPath string) string {
+	key := strings.TrimPrefix(path.Clean(filepath.ToSlash(localPath)), "./")
+	key = strings.TrimPrefix(key, "/")
+	if prefix = strings.Trim(prefix, "/"); prefix != "" {
+		key = prefix + "/" + key
+	}
+	return key
+}
+
+// localPath returns the local path of the file held by an object
+func localPath(prefix, key string) string {
+	if prefix = strings.Trim(prefix, "/"); prefix != "" {
+		key = strings.TrimPrefix(key, prefix+"/")
+	}
+	return filepath.FromSlash(key)
+}
+
+// listPrefix returns the key prefix of the objects under a local directory
+func listPrefix(prefix, dir string) string {
+	key := strings.Trim(prefix, "/")
+	if dir := path.Clean(filepath.ToSlash(dir)); dir != "." && dir != "/" {
+		key = objectKey(prefix, dir)
+	}
+	if key != "" && !strings.HasSuffix(key, "/") {
+		key += "/"
+	}
+	return key
+}
+
+// cached keeps the files read from and written to a remote store in memory
+// for the run, so each snapshot is downloaded at most once
+type cached struct {
+	store Store
+
+	mu      sync.Mutex
+	files   map[string][]byte
+	missing map[string]bool
+	lists   map[string][]string
+}
+
+// NewCached caches the reads, existence checks and listings of a store;
+// writes and removals go through to the store and update the cache
+func NewCached(store Store) Store {
+	return &cached{
+		store:   store,
+		files:   make(map[string][]byte),
+		missing: make(map[string]bool),
+		lists:   make(map[string][]string),
+	}
+}
+
+func (c *cached) Read(path string) ([]byte, error) {
+	c.mu.Lock()
+	data, ok := c.files[path]
+	missing := c.missing[path]
+	c.mu.Unlock()
+	if ok {
+		return append([]byte(nil), data...), nil
+	}
+	if missing {
+		return nil, fmt.Errorf("snapshot %s: %w", path, fs.ErrNotExist)
+	}
+
+	data, err := c.store.Read(path)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch {
+	case err == nil:
+		c.files[path] = data
+		return append([]byte(nil), data...), nil
+	case errors.Is(err, fs.ErrNotExist):
+		c.missing[path] = true
+	}
+	return nil, err
+}
+
+func (c *cached) Write(path string, data []byte) error {
+	if err := c.store.Write(path, data); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.files[path] = append([]byte(nil), data...)
+	delete(c.missing, path)
+	c.lists = make(map[string][]string)
+	return nil
+}
+
+func (c *cached) Exists(path string) (bool, error) {
+	c.mu.Lock()
+	_, ok := c.files[path]
+	missing := c.missing[path]
+	c.mu.Unlock()
+	if ok || missing {
+		return ok, nil
+	}
+
+	exists, err := c.store.Exists(path)
+	if err == nil && !exists {
+		c.mu.Lock()
+		c.missing[path] = true
+		c.mu.Unlock()
+	}
+	return exists, err
+}
+
+func (c *cached) List(dir string) ([]string, error) {
+	c.mu.Lock()
+	paths, ok := c.lists[dir]
+	c.mu.Unlock()
+	if ok {
+		return append([]string(nil), paths...), nil
+	}
+
+	paths, err := c.store.List(dir)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.lists[dir] = paths
+	c.mu.Unlock()
+	return append([]string(nil), paths...), nil
+}
+
+func (c *cached) Remove(path string) error {
+	if err := c.store.Remove(path); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.files, path)
+	c.missing[path] = true
+	c.lists = make(map[string][]string)
+	return nil
+}
+
+// lazy opens its store on first use, once the command line has chosen it
+type lazy struct {
+	open  func() (Store, error)
+	once  sync.Once
+	store Store
+	err   error
+}
+
+// NewLazy returns a store opened by a function on first use, e.g. with the
+// location given on the command line after the store is created
+func NewLazy(open func() (Store, error)) Store {
+	return &lazy{open: open}
+}
+
+func (l *lazy) get() (Store, error) {
+	l.once.Do(func() {
+		l.store, l.err = l.open()
+	})
+	return l.store, l.err
+}
+
+func (l *lazy) Read(path string) ([]byte, error) {
+	store, err := l.get()
+	if err != nil {
+		return nil, err
+	}
+	return store.Read(path)
+}
+
+func (l *lazy) Write(path string, data []byte) error {
+	store, err := l.get()
+	if err != nil {
+		return err
+	}
+	return store.Write(path, data)
+}
+
+func (l *lazy) Exists(path string) (bool, error) {
+	store, err := l.get()
+	if err != nil {
+		return false, err
+	}
+	return store.Exists(path)
+}
+
+func (l *lazy) List(dir string) ([]string, error) {
+	store, err := l.get()
+	if err != nil {
+		return nil, err
+	}
+	return store.List(dir)
+}
+
+func (l *lazy) Remove(path string) error {
+	store, err := l.get()
+	if err != nil {
+		return err
+	}
+	return store.Remove(path)
+}
@@ -0,0 +1,97 @@
+package snapstore
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpen(t *testing.T) {
+	store, err := Open("snapshots")
+	require.NoError(t, err)
+	assert.Equal(t, Local{}, store)
+
+	_, err = Open("s3://")
+	assert.Error(t, err)
+
+	_, err = Open("ftp://bucket/prefix")
+	assert.Error(t, err)
+}
+
+func TestLocal(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "users", "getUser.snap")
+	store := Local{}
+
+	exists, err := store.Exists(path)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	require.NoError(t, store.Write(path, []byte("snapshot")))
+	data, err := store.Read(path)
+	require.NoError(t, err)
+	assert.Equal(t, "snapshot", string(data))
+
+	paths, err := store.List(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{path}, paths)
+
+	paths, err = store.List(filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+	assert.Empty(t, paths)
+
+	require.NoError(t, store.Remove(path))
+	_, err = store.Read(path)
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+}
+
+func TestObjectKey(t *testing.T) {
+	assert.Equal(t, "ci/snapshots/users/getUser.snap", objectKey("ci/", "./snapshots/users/getUser.snap"))
+	assert.Equal(t, "snapshots/users/getUser.snap", objectKey("", "snapshots/users/getUser.snap"))
+	assert.Equal(t, filepath.FromSlash("snapshots/users/getUser.snap"), localPath("ci", "ci/snapshots/users/getUser.snap"))
+	assert.Equal(t, "ci/snapshots/", listPrefix("ci", "snapshots"))
+	assert.Equal(t, "ci/", listPrefix("ci", "."))
+}
+
+// countingStore counts the reads of a store
+type countingStore struct {
+	Store
+	reads int
+}
+
+func (c *countingStore) Read(path string) ([]byte, error) {
+	c.reads++
+	return c.Store.Read(path)
+}
+
+func TestCached(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "getUser.snap")
+	counting := &countingStore{Store: Local{}}
+	store := NewCached(counting)
+
+	_, err := store.Read(path)
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+	_, err = store.Read(path)
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+	assert.Equal(t, 1, counting.reads)
+
+	require.NoError(t, store.Write(path, []byte("snapshot")))
+	data, err := store.Read(path)
+	require.NoError(t, err)
+	assert.Equal(t, "snapshot", string(data))
+	assert.Equal(t, 1, counting.reads)
+
+	paths, err := store.List(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{path}, paths)
+
+	require.NoError(t, store.Remove(path))
+	exists, err := store.Exists(path)
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
@@ -15,7 +15,7 @@ import (
 
 // AdvancedTestRunnerService extends the basic TestRunnerService with advanced testing features
 type AdvancedTestRunnerService struct {
-	*TestRunnerService
+	*application.TestRunnerService
 	schemaValidator   *validator.SchemaValidatorService
 	variableExtractor *extractor.VariableExtractorService
 	assertionEvaluator *asserter.AssertionEvaluatorService
@@ -27,8 +27,9 @@ func NewAdvancedTestRunnerService(
 	executor application.HTTPExecutor,
 	snapshotManager application.SnapshotManager,
 	fileWriter application.FileWriter,
+	options ...application.TestRunnerOption,
 ) *AdvancedTestRunnerService {
-	baseRunner := NewTestRunnerService(executor, snapshotManager, fileWriter)
+	baseRunner := application.NewTestRunnerService(executor, snapshotManager, fileWriter, options...)
 	schemaValidator := validator.NewSchemaValidatorService()
 	
 	return &AdvancedTestRunnerService{
@@ -97,9 +98,43 @@ func (s *AdvancedTestRunnerService) RunSequences(
 	
 	// Set start time
 	report.Summary.StartTime = time.Now()
+	options = options.WithRunDeadline(report.Summary.StartTime)
+
+	// Track where the variables of the sequences come from
+	tracker := models.NewVariableTracker()
+	ctx = models.ContextWithVariableTracker(ctx, tracker)
+
+	// Fail the steps using variables without a value when they are strict
+	ctx = models.ContextWithStrictVariables(ctx, options.StrictVars)
+
+	// Cancel outstanding requests when the run timeout is reached
+	if !options.RunDeadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, options.RunDeadline)
+		defer cancel()
+	}
 	
 	// Run each sequence
 	for _, sequence := range sequences {
+		// Skip the sequences that can't start before the run timeout
+		if options.RunDeadlineExceeded() {
+			for _, step := range sequence.Steps {
+				report.Results = append(report.Results, models.TestResult{
+					Name:     fmt.Sprintf("%s - %s", sequence.Name, step.Name),
+					FilePath: sequence.FilePath,
+					Request:  step.Request,
+					Status:   models.TestStatusSkipped,
+					Error:    models.RunTimeoutReason,
+					Tags:     sequence.Tags,
+					MetaData: map[string]string{
+						models.SequenceMetaKey: sequence.Name,
+						models.StepMetaKey:     step.Name,
+					},
+				})
+			}
+			continue
+		}
+
 		// Load variables from file if configured
 		if options.VariablesPath != "" {
 			vars, err := s.variableExtractor.LoadVariables(ctx, options.VariablesPath)
@@ -128,6 +163,15 @@ func (s *AdvancedTestRunnerService) RunSequences(
 			report.Summary.SequencesFailed++
 		}
 		
+		// Report the cleanup outcomes apart from the steps
+		report.Summary.CleanupsTotal += len(sequenceResult.CleanupResults)
+		for _, cleanup := range sequenceResult.CleanupResults {
+			if cleanup.Status != models.TestStatusPassed {
+				report.Summary.CleanupsFailed++
+				report.Warnings = append(report.Warnings, fmt.Sprintf("sequence %s: %s %s: %s", sequence.Name, cleanup.Method, cleanup.URL, cleanup.Error))
+			}
+		}
+
 		// Convert sequence step results to test results for compatibility
 		for _, stepResult := range sequenceResult.StepResults {
 			testResult := models.TestResult{
@@ -139,8 +183,8 @@ func (s *AdvancedTestRunnerService) RunSequences(
 				Error:    stepResult.Error,
 				Tags:     sequence.Tags,
 				MetaData: map[string]string{
-					"sequence": sequence.Name,
-					"step":     stepResult.Name,
+					models.SequenceMetaKey: sequence.Name,
+					models.StepMetaKey:     stepResult.Name,
 				},
 			}
 			
@@ -190,6 +234,14 @@ func (s *AdvancedTestRunnerService) RunSequences(
 			}
 		}
 	}
+
+	// Reflect the steps that didn't run because the run timeout was reached
+	if timedOut := models.CountRunTimeouts(report.Results); timedOut > 0 {
+		report.Summary.RunTimedOut = true
+		report.Warnings = append(report.Warnings, fmt.Sprintf("run timeout of %s reached: %d steps did not run", options.RunTimeout, timedOut))
+	}
+
+	report.Variables = tracker.Provenance()
 	
 	return report, nil
 }
@@ -245,6 +297,11 @@ func (s *AdvancedTestRunnerService) RunTest(
 				result.Status = models.TestStatusFailed
 			}
 		}
+
+		// Validate the following pages of a paginated request too
+		if result.Status == models.TestStatusPassed && result.Pagination != nil {
+			s.validatePages(ctx, request, result, options)
+		}
 	}
 	
 	// Extract variables if enabled
@@ -277,12 +334,12 @@ func (s *AdvancedTestRunnerService) RunTest(
 			
 			// Check if any assertions failed
 			for _, assertionResult := range assertionResults {
-				if !assertionResult.Succeeded {
+				if !assertionResult.Passed {
 					result.Status = models.TestStatusFailed
 					result.Error = fmt.Sprintf(
 						"Assertion failed: %s - %s",
 						assertionResult.Type,
-						assertionResult.Message,
+						assertionResult.Error,
 					)
 					break
 				}
@@ -300,21 +357,45 @@ func (s *AdvancedTestRunnerService) RunTestFile(
 	options models.TestRunOptions,
 ) ([]*models.TestResult, error) {
 	var results []*models.TestResult
+
+	// Responses of earlier requests in the file, for chained references
+	chain := application.NewResponseChain()
 	
 	for _, request := range file.Requests {
 		// Check if the test meets the filter criteria
-		if !s.matchesFilter(&request, options.Filter) {
+		if !s.MatchesFilter(&request, options.Filter) {
 			continue
 		}
 		
 		// Set the file path in the request
 		request.Path = file.Filename
+
+		// Resolve references to earlier responses
+		resolved, chainedVars, err := chain.Resolve(&request)
+		if err != nil {
+			results = append(results, &models.TestResult{
+				Name:     request.Name,
+				Request:  &request,
+				FilePath: request.Path,
+				Tags:     request.TagList(),
+				Status:   models.TestStatusError,
+				Error:    err.Error(),
+			})
+			if options.StopOnFailure {
+				break
+			}
+			continue
+		}
 		
 		// Run the test
-		result, err := s.RunTest(ctx, &request, options)
+		result, err := s.RunTest(ctx, resolved, options)
 		if err != nil {
 			return nil, err
 		}
+		if len(chainedVars) > 0 {
+			result.ChainedVars = chainedVars
+		}
+		chain.Add(request.Name, result.Response)
 		
 		results = append(results, result)
 		
@@ -326,3 +407,52 @@ func (s *AdvancedTestRunnerService) RunTestFile(
 	
 	return results, nil
 }
+
+// validatePages validates every page after the first one of a paginated
+// request against the swagger schema, failing the test on the first invalid page
+func (s *AdvancedTestRunnerService) validatePages(
+	ctx context.Context,
+	request *models.HTTPRequest,
+	result *models.TestResult,
+	options models.TestRunOptions,
+) {
+	for i, page := range result.Pagination.Responses {
+		schemaResult, err := s.schemaValidator.ValidateResponseWithSwagger(
+			ctx,
+			page,
+			options.SwaggerDoc,
+			request.Path,
+			request.Method,
+			options.ValidationOptions,
+		)
+		if err != nil {
+			result.Error = fmt.Sprintf("Schema validation error on page %d: %v", i+2, err)
+			result.Status = models.TestStatusError
+			return
+		}
+		if len(schemaResult.Warnings) > 0 && result.SchemaResult != nil {
+			result.SchemaResult.Warnings = append(result.SchemaResult.Warnings, pageWarnings(i+2, schemaResult.Warnings)...)
+		}
+		if !schemaResult.Valid {
+			result.SchemaResult = schemaResult
+			result.Error = fmt.Sprintf(
+				"Schema validation failed on page %d with %d errors",
+				i+2,
+				len(schemaResult.Errors),
+			)
+			result.Status = models.TestStatusFailed
+			return
+		}
+	}
+}
+
+// pageWarnings marks the schema warnings of a page of a paginated request
+// with the number of the page
+func pageWarnings(page int, warnings []models.ValidationError) []models.ValidationError {
+	marked := make([]models.ValidationError, len(warnings))
+	for i, warning := range warnings {
+		warning.Path = fmt.Sprintf("page %d: %s", page, warning.Path)
+		marked[i] = warning
+	}
+	return marked
+}
@@ -0,0 +1,124 @@
+package test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowExecutor answers requests with 200 OK, taking delay for the URLs it
+// lists, and records the URLs requested
+type slowExecutor struct {
+	mu     sync.Mutex
+	delays map[string]time.Duration
+	urls   []string
+}
+
+func (e *slowExecutor) Execute(ctx context.Context, request *models.HTTPRequest, variables map[string]string) (*models.HTTPResponse, error) {
+	e.mu.Lock()
+	e.urls = append(e.urls, request.URL)
+	e.mu.Unlock()
+	time.Sleep(e.delays[request.URL])
+	return &models.HTTPResponse{StatusCode: 200, Request: request}, nil
+}
+
+func (e *slowExecutor) ExecuteFile(ctx context.Context, file *models.HTTPFile, variables map[string]string) ([]*models.HTTPResponse, error) {
+	return nil, nil
+}
+
+// noSnapshots is a snapshot manager without any snapshot
+type noSnapshots struct{}
+
+func (noSnapshots) SaveSnapshot(ctx context.Context, response *models.HTTPResponse, path string) error {
+	return nil
+}
+
+func (noSnapshots) LoadSnapshot(ctx context.Context, path string) (*models.HTTPResponse, error) {
+	return nil, os.ErrNotExist
+}
+
+func (noSnapshots) CompareWithSnapshot(ctx context.Context, response *models.HTTPResponse, snapshotPath string) (*models.SnapshotDiff, error) {
+	return nil, os.ErrNotExist
+}
+
+func TestRunSequences_RunTimeout(t *testing.T) {
+	dir := t.TempDir()
+	sequences := map[string]string{
+		"a-users.json": `{"name": "users", "steps": [
+			{"name": "list", "request": {"method": "GET", "url": "https://api.example.com/users"}},
+			{"name": "get", "request": {"method": "GET", "url": "https://api.example.com/users/1"}}
+		]}`,
+		"b-orders.json": `{"name": "orders", "steps": [
+			{"name": "list", "request": {"method": "GET", "url": "https://api.example.com/orders"}},
+			{"name": "get", "request": {"method": "GET", "url": "https://api.example.com/orders/1"}}
+		]}`,
+	}
+	for name, content := range sequences {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+
+	// The last step of the first sequence completes after the deadline
+	executor := &slowExecutor{delays: map[string]time.Duration{"https://api.example.com/users/1": 300 * time.Millisecond}}
+	runner := NewAdvancedTestRunnerService(executor, nil, nil)
+
+	report, err := runner.RunSequences(context.Background(), []string{filepath.Join(dir, "*.json")}, models.TestRunOptions{RunTimeout: 100 * time.Millisecond})
+	require.NoError(t, err)
+
+	// The sequence not started by the deadline is skipped without being sent
+	assert.Equal(t, []string{"https://api.example.com/users", "https://api.example.com/users/1"}, executor.urls)
+	require.Len(t, report.Results, 4)
+	for _, result := range report.Results[:2] {
+		assert.Equal(t, models.TestStatusPassed, result.Status, result.Name)
+	}
+	for i, name := range []string{"orders - list", "orders - get"} {
+		result := report.Results[2+i]
+		assert.Equal(t, name, result.Name)
+		assert.Equal(t, models.TestStatusSkipped, result.Status)
+		assert.Equal(t, models.RunTimeoutReason, result.Error)
+		assert.Equal(t, "orders", result.MetaData[models.SequenceMetaKey])
+	}
+
+	assert.Equal(t, 1, report.Summary.SequencesTotal)
+	assert.Equal(t, 2, report.Summary.SkippedTests)
+	assert.True(t, report.Summary.RunTimedOut)
+	assert.Equal(t, []string{"run timeout of 100ms reached: 2 steps did not run"}, report.Warnings)
+}
+
+func TestRunTest_Assertions(t *testing.T) {
+	tests := []struct {
+		name       string
+		enabled    bool
+		value      string
+		wantStatus models.TestStatus
+		wantError  string
+	}{
+		{name: "passing", enabled: true, value: "200", wantStatus: models.TestStatusPassed},
+		{name: "failing", enabled: true, value: "201", wantStatus: models.TestStatusFailed, wantError: "Assertion failed: equals - Expected '201', got '200'"},
+		{name: "disabled", enabled: false, value: "201", wantStatus: models.TestStatusPassed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := NewAdvancedTestRunnerService(&slowExecutor{}, noSnapshots{}, nil)
+			request := &models.HTTPRequest{
+				Name:         "listUsers",
+				Method:       "GET",
+				URL:          "https://api.example.com/users",
+				ExpectStatus: "200",
+				Assertions:   []models.TestAssertion{{Type: "equals", Source: "status", Value: tt.value}},
+			}
+
+			result, err := runner.RunTest(context.Background(), request, models.TestRunOptions{EnableAssertions: tt.enabled})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, result.Status)
+			assert.Equal(t, tt.wantError, result.Error)
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// schemaRefs returns the named schemas of a document that $refs may point to,
+// keyed by reference: definitions of Swagger 2.0 and components of OpenAPI 3.0
+func schemaRefs(document map[string]interface{}) map[string]interface{} {
+	refs := make(map[string]interface{})
+	if definitions, ok := document["definitions"].(map[string]interface{}); ok {
+		for name, schema := range definitions {
+			refs["#/definitions/"+name] = schema
+		}
+	}
+	if components, ok := document["components"].(map[string]interface{}); ok {
+		if schemas, ok := components["schemas"].(map[string]interface{}); ok {
+			for name, schema := range schemas {
+				refs["#/components/schemas/"+name] = schema
+			}
+		}
+	}
+	return refs
+}
+
+// documentRefs returns the named schemas of a Swagger/OpenAPI document
+func documentRefs(doc *models.SwaggerDoc) (map[string]interface{}, error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal swagger document: %w", err)
+	}
+	var document map[string]interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("failed to parse swagger document: %w", err)
+	}
+	return schemaRefs(document), nil
+}
+
+// validationScope is the state of the validation of a response
+type validationScope struct {
+	refs          map[string]interface{}   // Named schemas $refs point to
+	formats       *FormatRegistry          // Checkers of string formats
+	discriminated map[string]bool          // Paths whose discriminator is being applied
+	partial       map[string]bool          // Paths validated against a part of their schema
+	warnings      []models.ValidationError // Findings that don't fail the response
+}
+
+// newValidationScope creates the scope of the validation of a response
+func newValidationScope(refs map[string]interface{}, formats *FormatRegistry) *validationScope {
+	return &validationScope{
+		refs:          refs,
+		formats:       formats,
+		discriminated: make(map[string]bool),
+		partial:       make(map[string]bool),
+	}
+}
+
+// warn records a finding that doesn't fail the response
+func (v *validationScope) warn(warning models.ValidationError) {
+	v.warnings = append(v.warnings, warning)
+}
+
+// tiers returns the errors and the warnings of the validation, the warnings
+// counting as errors when the options say so
+func (v *validationScope) tiers(errors []models.ValidationError, options models.ValidationOptions) ([]models.ValidationError, []models.ValidationError) {
+	if options.WarningsAsErrors {
+		return append(errors, v.warnings...), nil
+	}
+	return errors, v.warnings
+}
+
+// resolve returns the schema a $ref points to. A bare schema name, as
+// discriminator mappings may use, is looked up in components and definitions.
+func (v *validationScope) resolve(ref string) (map[string]interface{}, bool) {
+	candidates := []string{ref}
+	if !strings.Contains(ref, "/") {
+		candidates = append(candidates, "#/components/schemas/"+ref, "#/definitions/"+ref)
+	}
+	for _, candidate := range candidates {
+		if schema, ok := v.refs[candidate].(map[string]interface{}); ok {
+			return schema, true
+		}
+	}
+	return nil, false
+}
+
+// refName returns the name of the schema a $ref points to
+func refName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+// discriminatedSchema selects the schema a polymorphic value validates
+// against from the value of its discriminator property: the schema the
+// mapping points it to, or the branch of oneOf or anyOf named after it. It
+// returns nil with no error when the schema has no discriminator, or when it's
+// already being applied to the value, as subschemas extending the schema
+// holding it with allOf meet it again.
+func (v *validationScope) discriminatedSchema(data interface{}, schema map[string]interface{}, path string) (map[string]interface{}, *models.ValidationError) {
+	discriminator, ok := schema["discriminator"].(map[string]interface{})
+	if !ok || v.discriminated[path] {
+		return nil, nil
+	}
+	property, _ := discriminator["propertyName"].(string)
+	object, ok := data.(map[string]interface{})
+	if property == "" || !ok {
+		return nil, nil
+	}
+
+	value, ok := object[property]
+	if !ok {
+		return nil, &models.ValidationError{
+			Path:    joinPath(path, property),
+			Message: fmt.Sprintf("discriminator property %q missing", property),
+		}
+	}
+	name := fmt.Sprintf("%v", value)
+
+	// Schemas the discriminator can select, by value
+	candidates := make(map[string]string)
+	for _, keyword := range []string{"oneOf", "anyOf"} {
+		branches, _ := schema[keyword].([]interface{})
+		for _, branch := range branches {
+			branch, _ := branch.(map[string]interface{})
+			if ref, ok := branch["$ref"].(string); ok {
+				candidates[refName(ref)] = ref
+			}
+		}
+	}
+	if mapping, ok := discriminator["mapping"].(map[string]interface{}); ok {
+		for key, target := range mapping {
+			if ref, ok := target.(string); ok {
+				candidates[key] = ref
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	ref, ok := candidates[name]
+	target, resolved := v.resolve(ref)
+	if !ok || !resolved {
+		known := make([]string, 0, len(candidates))
+		for key := range candidates {
+			known = append(known, key)
+		}
+		sort.Strings(known)
+		return nil, &models.ValidationError{
+			Path:    joinPath(path, property),
+			Message: fmt.Sprintf("discriminator value %q maps to no known schema (expected one of: %s)", name, strings.Join(known, ", ")),
+			Value:   name,
+		}
+	}
+	return target, nil
+}
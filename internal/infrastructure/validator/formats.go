@@ -0,0 +1,132 @@
+package validator
+
+import (
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ExtensionFormat names a custom format of a string schema, checked instead of
+// its format so that tools unaware of it still read the standard one
+const ExtensionFormat = "x-format"
+
+// FormatFunc checks that a string is valid for a format, returning why it isn't
+type FormatFunc func(value string) error
+
+// FormatRegistry maps the formats of string schemas to their checkers. Strings
+// of formats it doesn't know aren't checked.
+type FormatRegistry struct {
+	formats map[string]FormatFunc
+}
+
+// NewFormatRegistry creates an empty FormatRegistry
+func NewFormatRegistry() *FormatRegistry {
+	return &FormatRegistry{formats: make(map[string]FormatFunc)}
+}
+
+// DefaultFormats creates a registry with the date-time, date, email, uuid,
+// uri, ipv4 and ipv6 formats
+func DefaultFormats() *FormatRegistry {
+	registry := NewFormatRegistry()
+	registry.Register("date-time", checkDateTime)
+	registry.Register("date", checkDate)
+	registry.Register("email", checkEmail)
+	registry.Register("uuid", checkUUID)
+	registry.Register("uri", checkURI)
+	registry.Register("ipv4", checkIPv4)
+	registry.Register("ipv6", checkIPv6)
+	return registry
+}
+
+// Register sets the checker of a format, replacing any previous one
+func (r *FormatRegistry) Register(name string, check FormatFunc) {
+	r.formats[name] = check
+}
+
+// Check checks a string against a format. Known reports whether the registry
+// has a checker for the format.
+func (r *FormatRegistry) Check(name, value string) (known bool, err error) {
+	if r == nil {
+		return false, nil
+	}
+	check, ok := r.formats[name]
+	if !ok {
+		return false, nil
+	}
+	return true, check(value)
+}
+
+// uncheckedFormats are formats of the specification that don't constrain the
+// text of a string, and aren't reported as unknown
+var uncheckedFormats = map[string]bool{
+	"byte":     true,
+	"binary":   true,
+	"password": true,
+}
+
+// schemaFormat returns the format a string schema declares, its x-format first
+func schemaFormat(schema map[string]interface{}) string {
+	if format, ok := schema[ExtensionFormat].(string); ok && format != "" {
+		return format
+	}
+	format, _ := schema["format"].(string)
+	return format
+}
+
+func checkDateTime(value string) error {
+	if _, err := time.Parse(time.RFC3339, value); err != nil {
+		return fmt.Errorf("not an RFC 3339 date-time")
+	}
+	return nil
+}
+
+func checkDate(value string) error {
+	if _, err := time.Parse("2006-01-02", value); err != nil {
+		return fmt.Errorf("not a YYYY-MM-DD date")
+	}
+	return nil
+}
+
+func checkEmail(value string) error {
+	address, err := mail.ParseAddress(value)
+	if err != nil || address.Address != value {
+		return fmt.Errorf("not an email address")
+	}
+	return nil
+}
+
+// uuidPattern matches UUIDs in their canonical textual form
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+func checkUUID(value string) error {
+	if !uuidPattern.MatchString(value) {
+		return fmt.Errorf("not a UUID")
+	}
+	return nil
+}
+
+func checkURI(value string) error {
+	parsed, err := url.Parse(value)
+	if err != nil || !parsed.IsAbs() {
+		return fmt.Errorf("not an absolute URI")
+	}
+	return nil
+}
+
+func checkIPv4(value string) error {
+	if ip := net.ParseIP(value); ip == nil || strings.Contains(value, ":") {
+		return fmt.Errorf("not an IPv4 address")
+	}
+	return nil
+}
+
+func checkIPv6(value string) error {
+	if ip := net.ParseIP(value); ip == nil || !strings.Contains(value, ":") {
+		return fmt.Errorf("not an IPv6 address")
+	}
+	return nil
+}
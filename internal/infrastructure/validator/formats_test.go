@@ -0,0 +1,78 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultFormats(t *testing.T) {
+	tests := []struct {
+		format string
+		valid  []string
+		bad    []string
+	}{
+		{"date-time", []string{"2025-01-01T12:00:00Z", "2025-01-01T12:00:00.5+02:00"}, []string{"2025-01-01", "yesterday"}},
+		{"date", []string{"2025-01-31"}, []string{"2025-02-30", "31/01/2025"}},
+		{"email", []string{"user@example.com"}, []string{"user", "User <user@example.com>"}},
+		{"uuid", []string{"123e4567-e89b-12d3-a456-426614174000"}, []string{"123e4567e89b12d3a456426614174000"}},
+		{"uri", []string{"https://example.com/a?b=c", "urn:isbn:0451450523"}, []string{"/relative/path"}},
+		{"ipv4", []string{"192.168.0.1"}, []string{"::1", "256.0.0.1"}},
+		{"ipv6", []string{"::1", "2001:db8::8a2e:370:7334"}, []string{"192.168.0.1"}},
+	}
+
+	registry := DefaultFormats()
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			for _, value := range tt.valid {
+				known, err := registry.Check(tt.format, value)
+				assert.True(t, known)
+				assert.NoError(t, err, value)
+			}
+			for _, value := range tt.bad {
+				_, err := registry.Check(tt.format, value)
+				assert.Error(t, err, value)
+			}
+		})
+	}
+
+	known, err := registry.Check("color", "red")
+	assert.False(t, known)
+	assert.NoError(t, err)
+}
+
+func TestValidateFormats(t *testing.T) {
+	registry := DefaultFormats()
+	registry.Register("iban", func(value string) error {
+		if !strings.HasPrefix(value, "DE") {
+			return fmt.Errorf("unknown country")
+		}
+		return nil
+	})
+	validator := NewSchemaValidatorService(WithFormats(registry))
+	schema := decode(t, `{
+		"type": "object",
+		"properties": {
+			"id": {"type": "string", "format": "uuid"},
+			"account": {"type": "string", "format": "string", "x-format": "iban"}
+		}
+	}`)
+	body := map[string]interface{}{"id": "42", "account": "FR76"}
+
+	scope := newValidationScope(nil, validator.formats)
+	validationErrors := validator.validateAgainstSchema(body, schema, scope, models.ValidationOptions{}, "")
+	require.Len(t, validationErrors, 2)
+	assert.Empty(t, scope.warnings)
+
+	scope = newValidationScope(nil, validator.formats)
+	assert.Empty(t, validator.validateAgainstSchema(body, schema, scope, models.ValidationOptions{FormatWarnings: true}, ""))
+	require.Len(t, scope.warnings, 2)
+
+	scope = newValidationScope(nil, validator.formats)
+	assert.Empty(t, validator.validateAgainstSchema(body, schema, scope, models.ValidationOptions{IgnoreFormats: true}, ""))
+	assert.Empty(t, scope.warnings)
+}
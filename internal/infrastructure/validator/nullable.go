@@ -0,0 +1,55 @@
+package validator
+
+// declaredType returns the type a value is validated as and whether the schema
+// accepts null. Of a list of types, as OpenAPI 3.1 allows, it's the one the
+// value has, or the first other than "null".
+func declaredType(schema map[string]interface{}, data interface{}) (string, bool) {
+	nullable, _ := schema["nullable"].(bool)
+
+	switch declared := schema["type"].(type) {
+	case string:
+		return declared, nullable || declared == "null"
+	case []interface{}:
+		var types []string
+		for _, item := range declared {
+			name, _ := item.(string)
+			if name == "null" {
+				nullable = true
+			} else if name != "" {
+				types = append(types, name)
+			}
+		}
+		if len(types) == 0 {
+			return "", nullable
+		}
+		for _, name := range types {
+			if name == jsonType(data) || (name == "number" && jsonType(data) == "integer") {
+				return name, nullable
+			}
+		}
+		return types[0], nullable
+	}
+	return "", nullable
+}
+
+// jsonType returns the schema type of a decoded JSON value
+func jsonType(data interface{}) string {
+	switch value := data.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		if value == float64(int64(value)) {
+			return "integer"
+		}
+		return "number"
+	}
+	return ""
+}
@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
-	"path/filepath"
 	"regexp"
 	"strings"
 
@@ -13,11 +12,31 @@ import (
 )
 
 // SchemaValidatorService implements the SchemaValidator interface
-type SchemaValidatorService struct {}
+type SchemaValidatorService struct {
+	formats *FormatRegistry
+}
+
+// SchemaValidatorOption represents an option for configuring the schema validator
+type SchemaValidatorOption func(*SchemaValidatorService)
+
+// WithFormats sets the registry of the string formats checked
+func WithFormats(registry *FormatRegistry) SchemaValidatorOption {
+	return func(s *SchemaValidatorService) {
+		if registry != nil {
+			s.formats = registry
+		}
+	}
+}
 
 // NewSchemaValidatorService creates a new SchemaValidatorService
-func NewSchemaValidatorService() *SchemaValidatorService {
-	return &SchemaValidatorService{}
+func NewSchemaValidatorService(opts ...SchemaValidatorOption) *SchemaValidatorService {
+	service := &SchemaValidatorService{
+		formats: DefaultFormats(),
+	}
+	for _, opt := range opts {
+		opt(service)
+	}
+	return service
 }
 
 // ValidateResponse validates a response against a schema
@@ -41,7 +60,7 @@ func (s *SchemaValidatorService) ValidateResponse(
 
 	// Parse the response body
 	var responseBody interface{}
-	if err := json.Unmarshal(response.Body, &responseBody); err != nil {
+	if err := json.Unmarshal([]byte(response.Body), &responseBody); err != nil {
 		return &models.SchemaValidationResult{
 			Valid:          false,
 			Errors:         []models.ValidationError{{
@@ -55,11 +74,13 @@ func (s *SchemaValidatorService) ValidateResponse(
 	}
 
 	// Validate the response against the schema
-	result := s.validateAgainstSchema(responseBody, schema, options, "")
+	scope := newValidationScope(schemaRefs(schema), s.formats)
+	result, warnings := scope.tiers(s.validateAgainstSchema(responseBody, schema, scope, options, ""), options)
 	
 	return &models.SchemaValidationResult{
 		Valid:          len(result) == 0,
 		Errors:         result,
+		Warnings:       warnings,
 		SchemaPath:     schemaPath,
 		ResponseStatus: response.StatusCode,
 		ContentType:    response.ContentType,
@@ -87,9 +108,15 @@ func (s *SchemaValidatorService) ValidateResponseWithSwagger(
 		return nil, fmt.Errorf("failed to parse schema: %w", err)
 	}
 
+	// The schema may reference those of the document
+	refs, err := documentRefs(swaggerDoc)
+	if err != nil {
+		return nil, err
+	}
+
 	// Parse the response body
 	var responseBody interface{}
-	if err := json.Unmarshal(response.Body, &responseBody); err != nil {
+	if err := json.Unmarshal([]byte(response.Body), &responseBody); err != nil {
 		return &models.SchemaValidationResult{
 			Valid:          false,
 			Errors:         []models.ValidationError{{
@@ -103,11 +130,13 @@ func (s *SchemaValidatorService) ValidateResponseWithSwagger(
 	}
 
 	// Validate the response against the schema
-	result := s.validateAgainstSchema(responseBody, schema, options, "")
+	scope := newValidationScope(refs, s.formats)
+	result, warnings := scope.tiers(s.validateAgainstSchema(responseBody, schema, scope, options, ""), options)
 	
 	return &models.SchemaValidationResult{
 		Valid:          len(result) == 0,
 		Errors:         result,
+		Warnings:       warnings,
 		SchemaPath:     fmt.Sprintf("%s %s - %d", method, path, response.StatusCode),
 		ResponseStatus: response.StatusCode,
 		ContentType:    response.ContentType,
@@ -179,13 +208,19 @@ func (s *SchemaValidatorService) GetSchemaForOperation(
 		}
 	}
 
-	// Extract the schema
-	if response.Schema == nil {
+	// Extract the schema, of the JSON content for OpenAPI 3.0
+	schema := response.Schema
+	if schema == nil {
+		if mediaType, ok := response.Content["application/json"]; ok {
+			schema = mediaType.Schema
+		}
+	}
+	if schema == nil {
 		return "", fmt.Errorf("schema not found for response")
 	}
 
 	// Convert the schema to JSON
-	schemaJson, err := json.Marshal(response.Schema)
+	schemaJson, err := json.Marshal(schema)
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal schema: %w", err)
 	}
@@ -197,17 +232,68 @@ func (s *SchemaValidatorService) GetSchemaForOperation(
 func (s *SchemaValidatorService) validateAgainstSchema(
 	data interface{}, 
 	schema map[string]interface{}, 
+	scope *validationScope,
 	options models.ValidationOptions,
 	path string,
 ) []models.ValidationError {
 	var errors []models.ValidationError
 
+	// Null values are accepted by nullable schemas, which OpenAPI 3.0 marks with
+	// nullable: true and 3.1 by listing "null" among their types
+	schemaType, nullable := declaredType(schema, data)
+	if data == nil && (nullable || options.IgnoreNullable) {
+		return nil
+	}
+
+	// Follow references to the schemas of the document
+	if ref, ok := schema["$ref"].(string); ok {
+		target, ok := scope.resolve(ref)
+		if !ok {
+			return []models.ValidationError{{
+				Path:    path,
+				Message: fmt.Sprintf("unresolved schema reference %s", ref),
+			}}
+		}
+		return s.validateAgainstSchema(data, target, scope, options, path)
+	}
+
+	// Every member of allOf applies, each declaring part of the properties
+	if members, ok := schema["allOf"].([]interface{}); ok {
+		partial := scope.partial[path]
+		scope.partial[path] = true
+		for _, member := range members {
+			memberSchema, _ := member.(map[string]interface{})
+			errors = append(errors, s.validateAgainstSchema(data, memberSchema, scope, options, path)...)
+		}
+		scope.partial[path] = partial
+	}
+
+	// A discriminator selects the schema of a polymorphic value
+	target, discriminatorErr := scope.discriminatedSchema(data, schema, path)
+	if discriminatorErr != nil {
+		return append(errors, *discriminatorErr)
+	}
+	if target != nil {
+		partial := scope.partial[path]
+		scope.discriminated[path] = true
+		scope.partial[path] = true
+		errors = append(errors, s.validateAgainstSchema(data, target, scope, options, path)...)
+		delete(scope.discriminated, path)
+		scope.partial[path] = partial
+	}
+
 	// This is a simplified schema validation implementation
 	// In a real implementation, you would use a proper JSON Schema validator
 	// like github.com/xeipuuv/gojsonschema
 	
 	// For now, we'll just do some basic type validation based on the schema type
-	schemaType, _ := schema["type"].(string)
+	if data == nil && schemaType != "" {
+		return append(errors, models.ValidationError{
+			Path:    path,
+			Message: fmt.Sprintf("null value for non-nullable %s", schemaType),
+			Schema:  fmt.Sprintf("%v", schema),
+		})
+	}
 	
 	switch schemaType {
 	case "object":
@@ -227,7 +313,7 @@ func (s *SchemaValidatorService) validateAgainstSchema(
 		if required, ok := schema["required"].([]interface{}); ok && !options.RequiredPropertiesOnly {
 			for _, req := range required {
 				propName, _ := req.(string)
-				if _, ok := dataObj[propName]; !ok {
+				if _, ok := dataObj[propName]; !ok && !skipWriteOnly(schema, propName, options) {
 					errors = append(errors, models.ValidationError{
 						Path:    joinPath(path, propName),
 						Message: "required property missing",
@@ -248,9 +334,15 @@ func (s *SchemaValidatorService) validateAgainstSchema(
 				// If property exists in data, validate it
 				if propValue, ok := dataObj[propName]; ok {
 					propSchemaMap, _ := propSchema.(map[string]interface{})
-					subErrors := s.validateAgainstSchema(propValue, propSchemaMap, options, joinPath(path, propName))
+					if deprecated, _ := propSchemaMap["deprecated"].(bool); deprecated {
+						scope.warn(models.ValidationError{
+							Path:    joinPath(path, propName),
+							Message: "deprecated property",
+						})
+					}
+					subErrors := s.validateAgainstSchema(propValue, propSchemaMap, scope, options, joinPath(path, propName))
 					errors = append(errors, subErrors...)
-				} else if isRequired(schema, propName) && !options.RequiredPropertiesOnly {
+				} else if isRequired(schema, propName) && !options.RequiredPropertiesOnly && !skipWriteOnly(schema, propName, options) {
 					// Property is required but missing
 					errors = append(errors, models.ValidationError{
 						Path:    joinPath(path, propName),
@@ -276,6 +368,17 @@ func (s *SchemaValidatorService) validateAgainstSchema(
 					}
 				}
 			}
+		} else if properties, ok := schema["properties"].(map[string]interface{}); ok && !options.IgnoreAdditionalProperties && !scope.partial[path] && schema["discriminator"] == nil {
+			// Undeclared properties are allowed, but may be typos or leaks
+			for propName := range dataObj {
+				if _, ok := properties[propName]; !ok {
+					scope.warn(models.ValidationError{
+						Path:    joinPath(path, propName),
+						Message: "undeclared property",
+						Value:   fmt.Sprintf("%v", dataObj[propName]),
+					})
+				}
+			}
 		}
 		
 	case "array":
@@ -295,7 +398,7 @@ func (s *SchemaValidatorService) validateAgainstSchema(
 		if items, ok := schema["items"].(map[string]interface{}); ok {
 			for i, item := range dataArr {
 				itemPath := fmt.Sprintf("%s[%d]", path, i)
-				subErrors := s.validateAgainstSchema(item, items, options, itemPath)
+				subErrors := s.validateAgainstSchema(item, items, scope, options, itemPath)
 				errors = append(errors, subErrors...)
 			}
 		}
@@ -325,6 +428,31 @@ func (s *SchemaValidatorService) validateAgainstSchema(
 				})
 			}
 		}
+
+		// Check the format if known and format validation is enabled
+		if format := schemaFormat(schema); format != "" && !options.IgnoreFormats {
+			known, err := scope.formats.Check(format, dataStr)
+			if !known && !uncheckedFormats[format] {
+				scope.warn(models.ValidationError{
+					Path:    path,
+					Message: fmt.Sprintf("unknown format %q, not checked", format),
+					Schema:  fmt.Sprintf("format: %s", format),
+				})
+			}
+			if known && err != nil {
+				formatError := models.ValidationError{
+					Path:    path,
+					Message: fmt.Sprintf("string is not a valid %s: %v", format, err),
+					Value:   dataStr,
+					Schema:  fmt.Sprintf("format: %s", format),
+				}
+				if options.FormatWarnings {
+					scope.warn(formatError)
+				} else {
+					errors = append(errors, formatError)
+				}
+			}
+		}
 		
 	case "number", "integer":
 		// Check if data is a number
@@ -373,6 +501,18 @@ func isRequired(schema map[string]interface{}, propertyName string) bool {
 	return false
 }
 
+// skipWriteOnly reports whether a missing property is writeOnly, which
+// responses leave out, unless the options require it
+func skipWriteOnly(schema map[string]interface{}, propertyName string, options models.ValidationOptions) bool {
+	if options.RequireWriteOnly {
+		return false
+	}
+	properties, _ := schema["properties"].(map[string]interface{})
+	property, _ := properties[propertyName].(map[string]interface{})
+	writeOnly, _ := property["writeOnly"].(bool)
+	return writeOnly
+}
+
 // joinPath joins path segments
 func joinPath(base, property string) string {
 	if base == "" {
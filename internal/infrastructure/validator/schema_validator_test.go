@@ -0,0 +1,188 @@
+package validator
+
+import (
+	"encoding/json"
+	"sort"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decode parses a JSON document for the tests
+func decode(t *testing.T, document string) map[string]interface{} {
+	t.Helper()
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(document), &decoded))
+	return decoded
+}
+
+func TestValidateDiscriminator(t *testing.T) {
+	document := decode(t, `{
+		"components": {"schemas": {
+			"Pet": {
+				"type": "object",
+				"required": ["petType"],
+				"properties": {"petType": {"type": "string"}},
+				"discriminator": {"propertyName": "petType", "mapping": {"cat": "#/components/schemas/Cat", "dog": "Dog"}}
+			},
+			"Cat": {"allOf": [{"$ref": "#/components/schemas/Pet"}, {"type": "object", "required": ["indoor"], "properties": {"indoor": {"type": "boolean"}}}]},
+			"Dog": {"allOf": [{"$ref": "#/components/schemas/Pet"}, {"type": "object", "required": ["bark"], "properties": {"bark": {"type": "string"}}}]}
+		}}
+	}`)
+	oneOf := map[string]interface{}{
+		"oneOf":         []interface{}{map[string]interface{}{"$ref": "#/components/schemas/Cat"}, map[string]interface{}{"$ref": "#/components/schemas/Dog"}},
+		"discriminator": map[string]interface{}{"propertyName": "petType"},
+	}
+	base := map[string]interface{}{"$ref": "#/components/schemas/Pet"}
+
+	tests := []struct {
+		name   string
+		schema map[string]interface{}
+		body   string
+		errors []string
+	}{
+		{"mapped subschema", base, `{"petType": "cat", "indoor": true}`, nil},
+		{"mapped to a bare name", base, `{"petType": "dog", "bark": "woof"}`, nil},
+		{"subschema is validated", base, `{"petType": "dog", "bark": 1}`, []string{"bark: expected string but got different type"}},
+		{"oneOf branch by name", oneOf, `{"petType": "Cat", "indoor": "yes"}`, []string{"indoor: expected boolean but got different type"}},
+		{"unknown value", base, `{"petType": "bird"}`, []string{`petType: discriminator value "bird" maps to no known schema (expected one of: cat, dog)`}},
+		{"missing property", oneOf, `{"indoor": true}`, []string{`petType: discriminator property "petType" missing`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body interface{}
+			require.NoError(t, json.Unmarshal([]byte(tt.body), &body))
+
+			validationErrors := NewSchemaValidatorService().validateAgainstSchema(body, tt.schema, newValidationScope(schemaRefs(document), nil), models.ValidationOptions{}, "")
+			var messages []string
+			for _, validationError := range validationErrors {
+				messages = append(messages, validationError.Path+": "+validationError.Message)
+			}
+			assert.Equal(t, tt.errors, messages)
+		})
+	}
+}
+
+func TestValidateWriteOnly(t *testing.T) {
+	schema := decode(t, `{
+		"type": "object",
+		"required": ["id", "password"],
+		"properties": {
+			"id": {"type": "integer", "readOnly": true},
+			"password": {"type": "string", "writeOnly": true}
+		}
+	}`)
+	body := map[string]interface{}{"id": float64(1)}
+	validator := NewSchemaValidatorService()
+
+	assert.Empty(t, validator.validateAgainstSchema(body, schema, newValidationScope(nil, nil), models.ValidationOptions{}, ""))
+
+	validationErrors := validator.validateAgainstSchema(body, schema, newValidationScope(nil, nil), models.ValidationOptions{RequireWriteOnly: true}, "")
+	require.NotEmpty(t, validationErrors)
+	assert.Equal(t, "password", validationErrors[0].Path)
+}
+
+func TestValidateNullable(t *testing.T) {
+	schema := decode(t, `{
+		"type": "object",
+		"properties": {
+			"address": {
+				"type": "object",
+				"nullable": true,
+				"properties": {"street": {"type": "string"}, "unit": {"type": ["string", "null"]}}
+			},
+			"manager": {"nullable": true, "allOf": [{"$ref": "#/components/schemas/User"}]},
+			"tags": {"type": ["array", "null"], "items": {"type": "string", "nullable": true}},
+			"scores": {"type": "array", "items": {"type": ["integer", "string"]}},
+			"name": {"type": "string"}
+		}
+	}`)
+	refs := map[string]interface{}{"#/components/schemas/User": decode(t, `{"type": "object", "properties": {"id": {"type": "integer"}}}`)}
+
+	tests := []struct {
+		name    string
+		body    string
+		options models.ValidationOptions
+		errors  []string
+	}{
+		{"nullable object", `{"address": null, "manager": null, "tags": null}`, models.ValidationOptions{}, nil},
+		{"nullable nested values", `{"address": {"street": "Main", "unit": null}, "tags": ["a", null]}`, models.ValidationOptions{}, nil},
+		{"type lists", `{"scores": [1, "high"]}`, models.ValidationOptions{}, nil},
+		{"non-nullable nested values", `{"address": {"street": null}, "scores": [null], "name": null}`, models.ValidationOptions{}, []string{
+			"address.street: null value for non-nullable string",
+			"name: null value for non-nullable string",
+			"scores[0]: null value for non-nullable integer",
+		}},
+		{"nullable referenced object", `{"manager": {"id": "1"}}`, models.ValidationOptions{}, []string{"manager.id: expected integer but got different type"}},
+		{"ignored", `{"address": {"street": null}, "name": null}`, models.ValidationOptions{IgnoreNullable: true}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body interface{}
+			require.NoError(t, json.Unmarshal([]byte(tt.body), &body))
+
+			validationErrors := NewSchemaValidatorService().validateAgainstSchema(body, schema, newValidationScope(refs, nil), tt.options, "")
+			var messages []string
+			for _, validationError := range validationErrors {
+				messages = append(messages, validationError.Path+": "+validationError.Message)
+			}
+			sort.Strings(messages)
+			assert.Equal(t, tt.errors, messages)
+		})
+	}
+}
+
+func TestValidateWarnings(t *testing.T) {
+	schema := decode(t, `{
+		"type": "object",
+		"properties": {
+			"id": {"type": "string", "format": "snowflake"},
+			"secret": {"type": "string", "format": "password"},
+			"legacy": {"type": "string", "deprecated": true},
+			"meta": {"type": "object", "properties": {"a": {"type": "string"}}, "additionalProperties": true}
+		}
+	}`)
+
+	tests := []struct {
+		name     string
+		body     string
+		options  models.ValidationOptions
+		errors   []string
+		warnings []string
+	}{
+		{"clean", `{"secret": "x", "meta": {"a": "1", "b": 2}}`, models.ValidationOptions{}, nil, nil},
+		{"findings", `{"id": "1", "legacy": "x", "extra": true}`, models.ValidationOptions{}, nil, []string{
+			"extra: undeclared property",
+			`id: unknown format "snowflake", not checked`,
+			"legacy: deprecated property",
+		}},
+		{"additional properties ignored", `{"extra": true}`, models.ValidationOptions{IgnoreAdditionalProperties: true}, nil, nil},
+		{"warnings as errors", `{"legacy": "x"}`, models.ValidationOptions{WarningsAsErrors: true}, []string{"legacy: deprecated property"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body interface{}
+			require.NoError(t, json.Unmarshal([]byte(tt.body), &body))
+
+			scope := newValidationScope(nil, DefaultFormats())
+			validationErrors, warnings := scope.tiers(NewSchemaValidatorService().validateAgainstSchema(body, schema, scope, tt.options, ""), tt.options)
+			assert.Equal(t, tt.errors, findingMessages(validationErrors))
+			assert.Equal(t, tt.warnings, findingMessages(warnings))
+		})
+	}
+}
+
+// findingMessages returns the sorted paths and messages of validation findings
+func findingMessages(findings []models.ValidationError) []string {
+	var messages []string
+	for _, finding := range findings {
+		messages = append(messages, finding.Path+": "+finding.Message)
+	}
+	sort.Strings(messages)
+	return messages
+}
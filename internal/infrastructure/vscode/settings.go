@@ -0,0 +1,169 @@
+// Package vscode writes settings for the VS Code REST Client extension so the
+// generated HTTP files can be run from the editor with the CLI's variables.
+package vscode
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// EnvironmentVariablesKey is the settings key holding the REST Client environments
+	EnvironmentVariablesKey = "rest-client.environmentVariables"
+
+	// SharedEnvironment holds the variables available in every environment
+	SharedEnvironment = "$shared"
+
+	// DefaultSettingsPath is where VS Code reads workspace settings from
+	DefaultSettingsPath = ".vscode/settings.json"
+)
+
+// Environments maps REST Client environment names to their variables
+type Environments map[string]map[string]string
+
+// NewEnvironments builds the REST Client environments: the shared variables
+// apply to every environment, and every profile becomes an environment setting
+// baseURLVariable to the profile's base URL
+func NewEnvironments(profiles map[string]string, shared map[string]string, baseURLVariable string) Environments {
+	environments := make(Environments)
+	if len(shared) > 0 {
+		environments[SharedEnvironment] = copyVariables(shared)
+	}
+	for name, baseURL := range profiles {
+		environments[name] = map[string]string{baseURLVariable: baseURL}
+	}
+	return environments
+}
+
+// WriteSettings merges the environments into the settings file at path,
+// creating it if needed. Other settings and variables that were added by hand
+// are kept. Comments in an existing file are not preserved.
+func WriteSettings(path string, environments Environments) error {
+	settings := make(map[string]interface{})
+
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(stripJSONC(data), &settings); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	case !os.IsNotExist(err):
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	merged := make(map[string]map[string]interface{})
+	if existing, ok := settings[EnvironmentVariablesKey].(map[string]interface{}); ok {
+		for name, value := range existing {
+			if variables, ok := value.(map[string]interface{}); ok {
+				merged[name] = variables
+			}
+		}
+	}
+	for name, variables := range environments {
+		if merged[name] == nil {
+			merged[name] = make(map[string]interface{})
+		}
+		for k, v := range variables {
+			merged[name][k] = v
+		}
+	}
+	settings[EnvironmentVariablesKey] = merged
+
+	output, err := json.MarshalIndent(settings, "", "    ")
+	if err != nil {
+		return fmt.Errorf("failed to encode settings: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, append(output, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// stripJSONC removes the comments and trailing commas VS Code allows in
+// settings files, leaving plain JSON
+func stripJSONC(data []byte) []byte {
+	return stripTrailingCommas(stripComments(data))
+}
+
+// stripComments removes // and /* */ comments outside of strings
+func stripComments(data []byte) []byte {
+	var out []byte
+	inString := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case inString:
+			out = append(out, c)
+			if c == '\\' && i+1 < len(data) {
+				i++
+				out = append(out, data[i])
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+			out = append(out, c)
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i+1 < len(data) && data[i+1] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			end := strings.Index(string(data[i+2:]), "*/")
+			if end < 0 {
+				return out
+			}
+			i += end + 3
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// stripTrailingCommas removes commas followed only by whitespace before a
+// closing bracket
+func stripTrailingCommas(data []byte) []byte {
+	var out []byte
+	inString := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case inString:
+			out = append(out, c)
+			if c == '\\' && i+1 < len(data) {
+				i++
+				out = append(out, data[i])
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+			out = append(out, c)
+		case c == ',':
+			next := strings.TrimLeft(string(data[i+1:]), " \t\r\n")
+			if strings.HasPrefix(next, "}") || strings.HasPrefix(next, "]") {
+				continue
+			}
+			out = append(out, c)
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// copyVariables returns a copy of variables
+func copyVariables(variables map[string]string) map[string]string {
+	result := make(map[string]string, len(variables))
+	for k, v := range variables {
+		result[k] = v
+	}
+	return result
+}
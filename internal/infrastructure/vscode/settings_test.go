@@ -0,0 +1,54 @@
+package vscode
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteSettingsMergesExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".vscode", "settings.json")
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(`{
+    // Keep the editor settings
+    "editor.tabSize": 2,
+    "rest-client.environmentVariables": {
+        "staging": {"baseUrl": "https://old.example.com", "token": "abc"},
+        "local": {"baseUrl": "http://localhost:8080"}, /* added by hand */
+    },
+}
+`), 0644))
+
+	environments := NewEnvironments(
+		map[string]string{"staging": "https://staging.example.com", "prod": "https://api.example.com"},
+		map[string]string{"apiVersion": "v1"},
+		"baseUrl",
+	)
+	require.NoError(t, WriteSettings(path, environments))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var settings map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &settings))
+
+	assert.Equal(t, float64(2), settings["editor.tabSize"])
+	assert.Equal(t, map[string]interface{}{
+		"$shared": map[string]interface{}{"apiVersion": "v1"},
+		"staging": map[string]interface{}{"baseUrl": "https://staging.example.com", "token": "abc"},
+		"prod":    map[string]interface{}{"baseUrl": "https://api.example.com"},
+		"local":   map[string]interface{}{"baseUrl": "http://localhost:8080"},
+	}, settings[EnvironmentVariablesKey])
+}
+
+func TestStripJSONCKeepsStrings(t *testing.T) {
+	input := `{"url": "http://example.com/*not a comment*/", "list": ["a,", "b",], // comment
+}`
+	var value map[string]interface{}
+	require.NoError(t, json.Unmarshal(stripJSONC([]byte(input)), &value))
+	assert.Equal(t, "http://example.com/*not a comment*/", value["url"])
+	assert.Equal(t, []interface{}{"a,", "b"}, value["list"])
+}
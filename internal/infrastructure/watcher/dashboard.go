@@ -0,0 +1,480 @@
+package watcher
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/application"
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/fatih/color"
+)
+
+const (
+	// clearScreen moves the cursor home and clears the terminal
+	clearScreen = "\033[H\033[2J"
+
+	// maxDiffLines limits the height of the diff pane
+	maxDiffLines = 20
+)
+
+// Keys read by the dashboard other than printable characters
+const (
+	keyUp        = "up"
+	keyDown      = "down"
+	keyEnter     = "enter"
+	keyBackspace = "backspace"
+	keyEscape    = "esc"
+	keyInterrupt = "ctrl-c"
+)
+
+// Dashboard is an interactive terminal UI for watch mode. It shows the live list
+// of tests with their status and the trend of their recent runs, the diff of the selected test,
+// and keys to re-run tests, update snapshots and filter the list.
+type Dashboard struct {
+	watcher    *TestWatcherService
+	testRunner application.TestRunner
+	patterns   []string
+	options    models.TestRunOptions
+	input      io.Reader
+	output     io.Writer
+
+	mu       sync.Mutex
+	results  []models.TestResult
+	lastRun  time.Time
+	selected int
+	filter   string
+	status   string
+	typing   bool // Keys edit the filter until Enter or Escape
+	jump     int  // Number of the test typed so far
+	lineMode bool // Keys arrive line by line, each line ended by Enter
+	raw      bool // The terminal is in raw mode, lines end with \r\n
+	afterKey bool // The last key wasn't Enter
+}
+
+// NewDashboard creates a new watch mode dashboard
+func NewDashboard(watcher *TestWatcherService, testRunner application.TestRunner,
+	patterns []string, options models.TestRunOptions) *Dashboard {
+	return &Dashboard{
+		watcher:    watcher,
+		testRunner: testRunner,
+		patterns:   patterns,
+		options:    options,
+		input:      os.Stdin,
+		output:     os.Stdout,
+		status:     "Running tests...",
+	}
+}
+
+// Run starts the watcher and blocks until the user quits the dashboard or
+// interrupts it. Keys are read as they are pressed when the input is a
+// terminal, line by line otherwise.
+func (d *Dashboard) Run(ctx context.Context) error {
+	ctx, cancel := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	// Read single keys from a terminal, restoring it however the dashboard ends
+	d.lineMode = true
+	if file, ok := d.input.(*os.File); ok && isTerminal(file) {
+		if restore, err := rawMode(file); err == nil {
+			defer restore()
+			d.lineMode = false
+			d.raw = true
+		}
+	}
+
+	// Forward watch run reports to the dashboard
+	d.watcher.SetReportHandler(d.handleReport)
+	defer d.watcher.SetReportHandler(nil)
+
+	if err := d.watcher.Watch(ctx, d.patterns, d.options); err != nil {
+		return fmt.Errorf("failed to start watcher: %w", err)
+	}
+	defer d.watcher.Stop()
+
+	d.render()
+
+	// Read keys until the user quits or input is closed
+	keys := make(chan string)
+	go func() {
+		defer close(keys)
+		reader := bufio.NewReader(d.input)
+		for {
+			key, err := readKey(reader)
+			if err != nil {
+				return
+			}
+			select {
+			case keys <- key:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case key, ok := <-keys:
+			if !ok || !d.handleKey(ctx, key) {
+				return nil
+			}
+			d.render()
+		}
+	}
+}
+
+// readKey reads a key press: a printable character, or keyUp, keyDown,
+// keyEnter, keyBackspace, keyEscape or keyInterrupt. Escape sequences of
+// other keys are read as "".
+func readKey(reader *bufio.Reader) (string, error) {
+	r, _, err := reader.ReadRune()
+	if err != nil {
+		return "", err
+	}
+
+	switch r {
+	case '\r':
+		// Line mode input may end lines with \r\n
+		if next, err := reader.Peek(1); err == nil && next[0] == '\n' {
+			reader.Discard(1)
+		}
+		return keyEnter, nil
+	case '\n':
+		return keyEnter, nil
+	case '\b', 0x7f:
+		return keyBackspace, nil
+	case 0x03:
+		return keyInterrupt, nil
+	case 0x1b:
+		// Arrows send ESC [ A or ESC O A at once, a lone ESC is the key
+		if reader.Buffered() < 2 {
+			return keyEscape, nil
+		}
+		next, _ := reader.Peek(2)
+		if next[0] != '[' && next[0] != 'O' {
+			return keyEscape, nil
+		}
+		code := next[1]
+		reader.Discard(2)
+		switch code {
+		case 'A':
+			return keyUp, nil
+		case 'B':
+			return keyDown, nil
+		}
+		return "", nil
+	}
+	return string(r), nil
+}
+
+// handleReport stores the results of a watch run and redraws the dashboard
+func (d *Dashboard) handleReport(report *models.TestReport, err error) {
+	d.mu.Lock()
+	d.lastRun = time.Now()
+	if err != nil {
+		d.status = fmt.Sprintf("Error running tests: %v", err)
+	} else {
+		d.results = report.Results
+		d.status = fmt.Sprintf("%d passed, %d failed, %d errors",
+			report.Summary.PassedTests, report.Summary.FailedTests, report.Summary.ErrorTests)
+		if d.selected >= len(d.visible()) {
+			d.selected = 0
+		}
+	}
+	d.mu.Unlock()
+
+	d.render()
+}
+
+// handleKey executes the action of a key and reports whether to keep running
+func (d *Dashboard) handleKey(ctx context.Context, key string) bool {
+	d.mu.Lock()
+	typing := d.typing
+	endsLine := d.lineMode && d.afterKey && key == keyEnter
+	d.afterKey = key != keyEnter
+	d.mu.Unlock()
+
+	// In raw mode Ctrl-C arrives as a key rather than a signal
+	if key == keyInterrupt {
+		return false
+	}
+	if typing {
+		d.editFilter(key)
+		return true
+	}
+
+	// Line by line, Enter only ends the keys typed before it
+	if endsLine {
+		return true
+	}
+
+	// Digits jump straight to a test by its number
+	if len(key) == 1 && key[0] >= '0' && key[0] <= '9' {
+		d.jumpTo(int(key[0] - '0'))
+		return true
+	}
+	d.mu.Lock()
+	d.jump = 0
+	d.mu.Unlock()
+
+	switch key {
+	case "q":
+		return false
+	case "k", keyUp:
+		d.move(-1)
+	case "j", keyDown, keyEnter:
+		d.move(1)
+	case "f", "/":
+		d.mu.Lock()
+		d.typing = true
+		d.filter = ""
+		d.selected = 0
+		d.mu.Unlock()
+	case "a":
+		d.setStatus("Running tests...")
+		d.render()
+		d.watcher.Trigger(ctx, d.patterns, d.options)
+	case "r":
+		d.runSelected(ctx, d.options.UpdateSnapshots, "Re-running test...")
+	case "u":
+		d.runSelected(ctx, "all", "Updating snapshot...")
+	case "", " ", keyEscape, keyBackspace:
+		// Keys without an action
+	default:
+		d.setStatus(fmt.Sprintf("Unknown key: %s", key))
+	}
+
+	return true
+}
+
+// editFilter edits the filter as it is typed: Enter keeps it, Escape clears it
+func (d *Dashboard) editFilter(key string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	switch key {
+	case keyEnter:
+		d.typing = false
+	case keyEscape:
+		d.typing = false
+		d.filter = ""
+	case keyBackspace:
+		if runes := []rune(d.filter); len(runes) > 0 {
+			d.filter = string(runes[:len(runes)-1])
+		}
+	case keyUp, keyDown, "":
+		return
+	case " ":
+		// Line by line, "f text" filters on text
+		if d.filter == "" {
+			return
+		}
+		d.filter += key
+	default:
+		d.filter += key
+	}
+	d.selected = 0
+}
+
+// jumpTo selects a test by its number, typed one digit at a time: a digit
+// extends the number typed so far while a test has the longer number
+func (d *Dashboard) jumpTo(digit int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	count := len(d.visible())
+	row := d.jump*10 + digit
+	if row == 0 || row > count {
+		row = digit
+	}
+	if row == 0 || row > count {
+		d.jump = 0
+		return
+	}
+	d.jump = row
+	d.selected = row - 1
+}
+
+// move changes the selected test by the given offset
+func (d *Dashboard) move(offset int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	count := len(d.visible())
+	if count == 0 {
+		return
+	}
+	d.selected = (d.selected + offset + count) % count
+}
+
+// runSelected re-runs the selected test with the given snapshot update mode
+func (d *Dashboard) runSelected(ctx context.Context, updateMode, message string) {
+	d.mu.Lock()
+	visible := d.visible()
+	if len(visible) == 0 {
+		d.mu.Unlock()
+		return
+	}
+	index := visible[d.selected]
+	request := d.results[index].Request
+	d.status = message
+	d.mu.Unlock()
+
+	if request == nil {
+		d.setStatus("Test has no request to re-run")
+		return
+	}
+	d.render()
+
+	// Run the single test outside the lock
+	options := d.options
+	options.UpdateSnapshots = updateMode
+	result, err := d.testRunner.RunTest(ctx, request, options)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err != nil {
+		d.status = fmt.Sprintf("Error: %v", err)
+		return
+	}
+	if index < len(d.results) {
+		d.results[index] = *result
+	}
+	d.status = fmt.Sprintf("%s: %s", dashboardLabel(*result), result.Status)
+}
+
+// setStatus updates the status line
+func (d *Dashboard) setStatus(status string) {
+	d.mu.Lock()
+	d.status = status
+	d.mu.Unlock()
+}
+
+// visible returns the indexes of results matching the current filter.
+// Callers must hold the mutex.
+func (d *Dashboard) visible() []int {
+	var indexes []int
+	filter := strings.ToLower(d.filter)
+	for i, result := range d.results {
+		if filter == "" || strings.Contains(strings.ToLower(dashboardLabel(result)), filter) {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// render redraws the whole dashboard
+func (d *Dashboard) render() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var b strings.Builder
+	b.WriteString(clearScreen)
+
+	// Header
+	b.WriteString(color.New(color.Bold).Sprint("swagger-to-http watch"))
+	if !d.lastRun.IsZero() {
+		b.WriteString(fmt.Sprintf("  (last run %s)", d.lastRun.Format("15:04:05")))
+	}
+	b.WriteString("\n")
+	b.WriteString(d.status)
+	b.WriteString("\n\n")
+
+	// Test list
+	visible := d.visible()
+	if len(visible) == 0 {
+		b.WriteString("  No tests\n")
+	}
+	for row, index := range visible {
+		result := d.results[index]
+		cursor := " "
+		if row == d.selected {
+			cursor = ">"
+		}
+		b.WriteString(fmt.Sprintf("%s %3d %s %-50s %s\n", cursor, row+1, statusSymbol(result.Status),
+			models.TruncateString(dashboardLabel(result), 50), d.watcher.History().Trend(result)))
+	}
+
+	// Failure diff pane
+	if len(visible) > 0 {
+		b.WriteString("\n")
+		b.WriteString(dashboardDetail(d.results[visible[d.selected]]))
+	}
+
+	// Footer
+	b.WriteString("\n")
+	if d.typing {
+		b.WriteString(fmt.Sprintf("Filter: %s_  (Enter to keep, Esc to clear)\n", d.filter))
+	} else if d.filter != "" {
+		b.WriteString(fmt.Sprintf("Filter: %s\n", d.filter))
+	}
+	b.WriteString("Keys: j/k or ↑/↓ select • <n> jump • r re-run • u update snapshot • a run all • f filter • q quit\n")
+
+	// Raw mode doesn't return the cursor to the start of new lines
+	screen := b.String()
+	if d.raw {
+		screen = strings.ReplaceAll(screen, "\n", "\r\n")
+	}
+	fmt.Fprint(d.output, screen)
+}
+
+// dashboardDetail renders the diff pane for a test result
+func dashboardDetail(result models.TestResult) string {
+	var b strings.Builder
+
+	if result.Error != "" {
+		b.WriteString(color.RedString("Error: %s", result.Error))
+		b.WriteString("\n")
+	}
+	if result.SnapshotResult != nil && result.SnapshotResult.Diff != nil && result.SnapshotResult.Diff.HasDiff {
+		diff := result.SnapshotResult.Diff.DiffString
+		if diff == "" {
+			diff = result.SnapshotResult.Diff.BodyDiff
+		}
+
+		// Keep the pane to a reasonable height
+		lines := strings.Split(diff, "\n")
+		if len(lines) > maxDiffLines {
+			lines = append(lines[:maxDiffLines], "...")
+		}
+		b.WriteString(strings.Join(lines, "\n"))
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// dashboardLabel returns the display label of a test
+func dashboardLabel(result models.TestResult) string {
+	if result.Request != nil {
+		name := result.Name
+		if name == "" {
+			name = result.Request.URL
+		}
+		return fmt.Sprintf("%s %s", result.Request.Method, name)
+	}
+	return result.Name
+}
+
+// statusSymbol returns a colored marker for a test status
+func statusSymbol(status models.TestStatus) string {
+	switch status {
+	case models.TestStatusPassed:
+		return color.GreenString("✓")
+	case models.TestStatusFailed:
+		return color.RedString("✗")
+	case models.TestStatusError, models.TestStatusCircuitOpen:
+		return color.YellowString("!")
+	default:
+		return "-"
+	}
+}
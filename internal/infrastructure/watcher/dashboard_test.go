@@ -0,0 +1,163 @@
+package watcher
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadKey(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{name: "characters", input: "jk/é", want: []string{"j", "k", "/", "é"}},
+		{name: "arrows", input: "\x1b[A\x1b[B\x1bOA\x1bOB", want: []string{keyUp, keyDown, keyUp, keyDown}},
+		{name: "other escape sequences", input: "\x1b[Cq", want: []string{"", "q"}},
+		{name: "lone escape", input: "\x1b", want: []string{keyEscape}},
+		{name: "enter", input: "\r\n\n\r", want: []string{keyEnter, keyEnter, keyEnter}},
+		{name: "backspace and ctrl-c", input: "\x7f\b\x03", want: []string{keyBackspace, keyBackspace, keyInterrupt}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := bufio.NewReader(strings.NewReader(tt.input))
+			var keys []string
+			for {
+				key, err := readKey(reader)
+				if err != nil {
+					assert.Equal(t, io.EOF, err)
+					break
+				}
+				keys = append(keys, key)
+			}
+			assert.Equal(t, tt.want, keys)
+		})
+	}
+}
+
+// newTestDashboard returns a dashboard listing twelve tests, test01 to test12
+func newTestDashboard(runner *fakeRunner) *Dashboard {
+	watcher := NewTestWatcherService(runner, &fakeReporter{})
+	dashboard := NewDashboard(watcher, runner, []string{"*.http"}, models.TestRunOptions{UpdateSnapshots: "none"})
+	dashboard.output = io.Discard
+	for i := 1; i <= 12; i++ {
+		name := fmt.Sprintf("test%02d", i)
+		dashboard.results = append(dashboard.results, models.TestResult{
+			Name:    name,
+			Request: &models.HTTPRequest{Name: name, Method: "GET", URL: "https://api.example.com/" + name},
+		})
+	}
+	watcher.SetReportHandler(dashboard.handleReport)
+	return dashboard
+}
+
+// press handles keys in order and reports whether the dashboard keeps running
+func press(d *Dashboard, keys ...string) bool {
+	for _, key := range keys {
+		if !d.handleKey(context.Background(), key) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestDashboard_HandleKey(t *testing.T) {
+	tests := []struct {
+		name         string
+		lineMode     bool
+		keys         []string
+		wantRunning  bool
+		wantSelected int
+		wantFilter   string
+		wantTyping   bool
+		wantStatus   string
+	}{
+		{name: "next", keys: []string{"j", keyDown, keyEnter}, wantRunning: true, wantSelected: 3},
+		{name: "previous wraps around", keys: []string{"k"}, wantRunning: true, wantSelected: 11},
+		{name: "previous with arrow", keys: []string{"j", "j", keyUp}, wantRunning: true, wantSelected: 1},
+		{name: "jump", keys: []string{"7"}, wantRunning: true, wantSelected: 6},
+		{name: "jump to two digits", keys: []string{"1", "2"}, wantRunning: true, wantSelected: 11},
+		{name: "digit past the last test starts over", keys: []string{"1", "2", "3"}, wantRunning: true, wantSelected: 2},
+		{name: "other keys end the number", keys: []string{"1", "j", "2"}, wantRunning: true, wantSelected: 1},
+		{name: "zero alone", keys: []string{"j", "0"}, wantRunning: true, wantSelected: 1},
+		{name: "quit", keys: []string{"q", "j"}, wantRunning: false},
+		{name: "interrupt", keys: []string{keyInterrupt}, wantRunning: false},
+		{name: "typing a filter", keys: []string{"f", "t", "1"}, wantRunning: true, wantFilter: "t1", wantTyping: true},
+		{name: "filter kept with enter", keys: []string{"/", "1", "x", keyBackspace, keyEnter, "j"}, wantRunning: true, wantFilter: "1", wantSelected: 1},
+		{name: "filter cleared with escape", keys: []string{"f", "1", keyEscape}, wantRunning: true},
+		{name: "filter keys don't quit", keys: []string{"f", "q"}, wantRunning: true, wantFilter: "q", wantTyping: true},
+		{name: "interrupt while typing a filter", keys: []string{"f", "t", keyInterrupt}, wantRunning: false},
+		{name: "f alone clears the filter", keys: []string{"f", "1", keyEnter, "f", keyEnter}, wantRunning: true},
+		{name: "unknown key", keys: []string{"x"}, wantRunning: true, wantStatus: "Unknown key: x"},
+		{name: "ignored keys", keys: []string{"", " ", keyEscape, keyBackspace}, wantRunning: true, wantStatus: "Running tests..."},
+		{name: "line mode: enter ends the keys of a line", lineMode: true, keys: []string{"j", keyEnter, "1", "2", keyEnter}, wantRunning: true, wantSelected: 11},
+		{name: "line mode: a bare enter selects the next test", lineMode: true, keys: []string{"j", keyEnter, keyEnter}, wantRunning: true, wantSelected: 2},
+		{name: "line mode: filter", lineMode: true, keys: []string{"f", " ", "1", "1", keyEnter, keyEnter}, wantRunning: true, wantFilter: "11"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newTestDashboard(&fakeRunner{})
+			d.lineMode = tt.lineMode
+
+			assert.Equal(t, tt.wantRunning, press(d, tt.keys...))
+			if !tt.wantRunning {
+				return
+			}
+			assert.Equal(t, tt.wantSelected, d.selected)
+			assert.Equal(t, tt.wantFilter, d.filter)
+			assert.Equal(t, tt.wantTyping, d.typing)
+			if tt.wantStatus != "" {
+				assert.Equal(t, tt.wantStatus, d.status)
+			}
+		})
+	}
+}
+
+func TestDashboard_HandleKeyActions(t *testing.T) {
+	runner := &fakeRunner{report: &models.TestReport{
+		Results: []models.TestResult{{Name: "listUsers", Status: models.TestStatusFailed}},
+		Summary: models.TestSummary{FailedTests: 1},
+	}}
+	d := newTestDashboard(runner)
+
+	// r re-runs the selected test with the update mode of the run, u updates its snapshot
+	assert.True(t, press(d, "j", "r"))
+	assert.Equal(t, "GET test02: passed", d.status)
+	assert.True(t, press(d, "u"))
+	assert.Equal(t, []string{"none", "all"}, runner.updateModes)
+	assert.Equal(t, models.TestStatusPassed, d.results[1].Status)
+
+	// a re-runs all the tests, whose report replaces the list
+	assert.True(t, press(d, "a"))
+	assert.Equal(t, 1, runner.runs)
+	assert.Equal(t, runner.report.Results, d.results)
+	assert.Equal(t, "0 passed, 1 failed, 0 errors", d.status)
+	assert.Equal(t, 0, d.selected)
+}
+
+func TestDashboard_RenderRaw(t *testing.T) {
+	d := newTestDashboard(&fakeRunner{})
+	var output strings.Builder
+	d.output = &output
+
+	// Lines end with \r\n in raw mode, where the terminal doesn't add \r
+	d.raw = true
+	d.render()
+	screen := output.String()
+	assert.Contains(t, screen, "test01")
+	assert.NotRegexp(t, `[^\r]\n`, screen)
+
+	output.Reset()
+	d.raw = false
+	d.render()
+	assert.NotContains(t, output.String(), "\r\n")
+}
@@ -0,0 +1,122 @@
+package watcher
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// DefaultHistorySize is the number of runs kept per request in watch mode
+const DefaultHistorySize = 10
+
+// RunRecord is the outcome of a request in a watch run
+type RunRecord struct {
+	Status   models.TestStatus
+	Duration time.Duration
+}
+
+// History keeps the last runs of each request in watch mode, so that the
+// trend shows whether a change fixed or broke an endpoint
+type History struct {
+	mu   sync.Mutex
+	size int
+	runs map[string][]RunRecord
+}
+
+// NewHistory creates a history keeping the last size runs of each request,
+// DefaultHistorySize when size is not positive
+func NewHistory(size int) *History {
+	if size <= 0 {
+		size = DefaultHistorySize
+	}
+	return &History{size: size, runs: make(map[string][]RunRecord)}
+}
+
+// historyKey identifies a request across runs
+func historyKey(result models.TestResult) string {
+	return result.FilePath + "\x00" + result.Name
+}
+
+// Record adds the results of a run to the history
+func (h *History) Record(report *models.TestReport) {
+	if report == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, result := range report.Results {
+		key := historyKey(result)
+		runs := append(h.runs[key], RunRecord{Status: result.Status, Duration: result.Duration})
+		if len(runs) > h.size {
+			runs = runs[len(runs)-h.size:]
+		}
+		h.runs[key] = runs
+	}
+}
+
+// Runs returns the recorded runs of a request, oldest first
+func (h *History) Runs(result models.TestResult) []RunRecord {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]RunRecord(nil), h.runs[historyKey(result)]...)
+}
+
+// Trend describes the recorded runs of a request: a symbol per run, oldest
+// first, and how its latency changed since the previous run, e.g.
+// "✓✓✗✓ 120ms→95ms"
+func (h *History) Trend(result models.TestResult) string {
+	runs := h.Runs(result)
+	if len(runs) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, run := range runs {
+		b.WriteString(trendSymbol(run.Status))
+	}
+
+	last := runs[len(runs)-1]
+	b.WriteString(" ")
+	if len(runs) > 1 {
+		b.WriteString(formatLatency(runs[len(runs)-2].Duration))
+		b.WriteString("→")
+	}
+	b.WriteString(formatLatency(last.Duration))
+	return b.String()
+}
+
+// Write writes the trend of each request of a report
+func (h *History) Write(w io.Writer, report *models.TestReport) {
+	if report == nil || len(report.Results) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "TREND (last %d runs):\n", h.size)
+	for _, result := range report.Results {
+		fmt.Fprintf(w, "  %-50s %s\n", models.TruncateString(result.Name, 50), h.Trend(result))
+	}
+	fmt.Fprintf(w, "\n")
+}
+
+// trendSymbol returns the marker of a status in a trend
+func trendSymbol(status models.TestStatus) string {
+	switch status {
+	case models.TestStatusPassed:
+		return "✓"
+	case models.TestStatusFailed:
+		return "✗"
+	case models.TestStatusError, models.TestStatusCircuitOpen:
+		return "!"
+	default:
+		return "-"
+	}
+}
+
+// formatLatency formats a duration in whole milliseconds, e.g. "95ms"
+func formatLatency(d time.Duration) string {
+	return fmt.Sprintf("%dms", d.Milliseconds())
+}
@@ -0,0 +1,42 @@
+package watcher
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func historyRun(status models.TestStatus, duration time.Duration) *models.TestReport {
+	return &models.TestReport{Results: []models.TestResult{
+		{Name: "listUsers", FilePath: "users.http", Status: status, Duration: duration},
+	}}
+}
+
+func TestHistoryTrend(t *testing.T) {
+	history := NewHistory(4)
+	result := models.TestResult{Name: "listUsers", FilePath: "users.http"}
+	assert.Equal(t, "", history.Trend(result))
+
+	history.Record(historyRun(models.TestStatusPassed, 130*time.Millisecond))
+	assert.Equal(t, "✓ 130ms", history.Trend(result))
+
+	history.Record(historyRun(models.TestStatusPassed, 125*time.Millisecond))
+	history.Record(historyRun(models.TestStatusFailed, 140*time.Millisecond))
+	history.Record(historyRun(models.TestStatusPassed, 120*time.Millisecond))
+	history.Record(historyRun(models.TestStatusPassed, 95*time.Millisecond))
+
+	// Only the last runs are kept
+	assert.Len(t, history.Runs(result), 4)
+	assert.Equal(t, "✓✗✓✓ 120ms→95ms", history.Trend(result))
+
+	// Requests of the same name in other files have their own history
+	assert.Equal(t, "", history.Trend(models.TestResult{Name: "listUsers", FilePath: "admin.http"}))
+
+	var out bytes.Buffer
+	history.Write(&out, historyRun(models.TestStatusPassed, 95*time.Millisecond))
+	assert.Contains(t, out.String(), "TREND (last 4 runs):\n")
+	assert.Contains(t, out.String(), "✓✗✓✓ 120ms→95ms\n")
+}
@@ -0,0 +1,24 @@
+package watcher
+
+import (
+	"os"
+
+	"golang.org/x/term"
+)
+
+// isTerminal reports whether a file is a terminal
+func isTerminal(file *os.File) bool {
+	return term.IsTerminal(int(file.Fd()))
+}
+
+// rawMode puts the terminal of a file in raw mode, passing each key as it is
+// pressed without echo, and returns the function restoring its settings
+func rawMode(file *os.File) (func(), error) {
+	saved, err := term.MakeRaw(int(file.Fd()))
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		_ = term.Restore(int(file.Fd()), saved)
+	}, nil
+}
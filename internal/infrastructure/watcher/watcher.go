@@ -3,6 +3,7 @@ package watcher
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
@@ -19,9 +20,15 @@ type TestWatcherService struct {
 	testReporter application.TestReporter
 	stopChan     chan struct{}
 	wg           sync.WaitGroup
+	runMu        sync.Mutex
 	logger       *log.Logger
+	onReport     ReportHandler
+	history      *History
 }
 
+// ReportHandler receives the report of each watch run instead of printing it
+type ReportHandler func(report *models.TestReport, err error)
+
 // NewTestWatcherService creates a new TestWatcherService
 func NewTestWatcherService(
 	testRunner application.TestRunner,
@@ -32,9 +39,15 @@ func NewTestWatcherService(
 		testReporter: testReporter,
 		stopChan:     make(chan struct{}),
 		logger:       log.New(os.Stdout, "[Watcher] ", log.LstdFlags),
+		history:      NewHistory(DefaultHistorySize),
 	}
 }
 
+// History returns the recent runs of each request
+func (s *TestWatcherService) History() *History {
+	return s.history
+}
+
 // Watch starts watching for changes and running tests
 func (s *TestWatcherService) Watch(ctx context.Context, patterns []string, options models.TestRunOptions) error {
 	// Stop any existing watches
@@ -48,6 +61,11 @@ func (s *TestWatcherService) Watch(ctx context.Context, patterns []string, optio
 		options.WatchIntervalMs = 1000 // Default to 1 second
 	}
 
+	// Keep the configured number of runs per request
+	if options.WatchHistory > 0 {
+		s.history = NewHistory(options.WatchHistory)
+	}
+
 	// Find files to watch
 	watchPaths := make(map[string]time.Time)
 	if len(options.WatchPaths) > 0 {
@@ -107,6 +125,23 @@ func (s *TestWatcherService) Watch(ctx context.Context, patterns []string, optio
 	return nil
 }
 
+// SetReportHandler routes watch run reports to the given handler instead of stdout.
+// Watcher log output is silenced while a handler is set so it doesn't interfere
+// with interactive displays.
+func (s *TestWatcherService) SetReportHandler(handler ReportHandler) {
+	s.onReport = handler
+	if handler != nil {
+		s.logger.SetOutput(io.Discard)
+	} else {
+		s.logger.SetOutput(os.Stdout)
+	}
+}
+
+// Trigger forces an immediate test run outside of the polling cycle
+func (s *TestWatcherService) Trigger(ctx context.Context, patterns []string, options models.TestRunOptions) {
+	s.runTests(ctx, patterns, options)
+}
+
 // Stop stops watching for changes
 func (s *TestWatcherService) Stop() error {
 	select {
@@ -253,8 +288,22 @@ func (s *TestWatcherService) checkForChanges(watchPaths map[string]time.Time) bo
 
 // runTests runs the tests and reports the results
 func (s *TestWatcherService) runTests(ctx context.Context, patterns []string, options models.TestRunOptions) {
+	// Serialize runs triggered by the poller and by Trigger
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+
 	// Run the tests
 	report, err := s.testRunner.RunTests(ctx, patterns, options)
+	if err == nil {
+		s.history.Record(report)
+	}
+
+	// Hand the report over if a handler is registered
+	if s.onReport != nil {
+		s.onReport(report, err)
+		return
+	}
+
 	if err != nil {
 		s.logger.Printf("Error running tests: %v", err)
 		return
@@ -273,6 +322,9 @@ func (s *TestWatcherService) runTests(ctx context.Context, patterns []string, op
 	if err != nil {
 		s.logger.Printf("Error printing report: %v", err)
 	}
+
+	// Show whether the last changes fixed or broke each request
+	s.history.Write(os.Stdout, report)
 }
 
 // contains checks if a string contains a substring
@@ -0,0 +1,105 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRunner answers RunTests with its report or error, RunTest with a passed
+// result, and records the calls
+type fakeRunner struct {
+	mu          sync.Mutex
+	report      *models.TestReport
+	err         error
+	runs        int
+	updateModes []string
+}
+
+func (r *fakeRunner) RunTests(ctx context.Context, patterns []string, options models.TestRunOptions) (*models.TestReport, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.runs++
+	return r.report, r.err
+}
+
+func (r *fakeRunner) RunTest(ctx context.Context, request *models.HTTPRequest, options models.TestRunOptions) (*models.TestResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.updateModes = append(r.updateModes, options.UpdateSnapshots)
+	return &models.TestResult{Name: request.Name, Request: request, Status: models.TestStatusPassed}, nil
+}
+
+func (r *fakeRunner) RunTestFile(ctx context.Context, file *models.HTTPFile, options models.TestRunOptions) ([]*models.TestResult, error) {
+	return nil, nil
+}
+
+func (r *fakeRunner) FindTests(ctx context.Context, patterns []string, filter models.TestFilter) ([]*models.HTTPFile, error) {
+	return nil, nil
+}
+
+// fakeReporter records the reports printed
+type fakeReporter struct {
+	printed []*models.TestReport
+}
+
+func (r *fakeReporter) GenerateReport(ctx context.Context, report *models.TestReport, options models.TestReportOptions) (io.Reader, error) {
+	return nil, nil
+}
+
+func (r *fakeReporter) SaveReport(ctx context.Context, report *models.TestReport, options models.TestReportOptions) error {
+	return nil
+}
+
+func (r *fakeReporter) PrintReport(ctx context.Context, report *models.TestReport, options models.TestReportOptions, writer io.Writer) error {
+	r.printed = append(r.printed, report)
+	return nil
+}
+
+func TestSetReportHandler(t *testing.T) {
+	runner := &fakeRunner{report: historyRun(models.TestStatusPassed, 0)}
+	reporter := &fakeReporter{}
+	watcher := NewTestWatcherService(runner, reporter)
+
+	// Reports go to the handler instead of the reporter, with the logger silenced
+	var reports []*models.TestReport
+	watcher.SetReportHandler(func(report *models.TestReport, err error) {
+		require.NoError(t, err)
+		reports = append(reports, report)
+	})
+	assert.Equal(t, io.Discard, watcher.logger.Writer())
+
+	watcher.Trigger(context.Background(), []string{"*.http"}, models.TestRunOptions{})
+	assert.Equal(t, []*models.TestReport{runner.report}, reports)
+	assert.Empty(t, reporter.printed)
+	assert.Len(t, watcher.History().Runs(runner.report.Results[0]), 1)
+
+	// Without a handler reports are printed again
+	watcher.SetReportHandler(nil)
+	assert.NotEqual(t, io.Discard, watcher.logger.Writer())
+	watcher.Trigger(context.Background(), []string{"*.http"}, models.TestRunOptions{})
+	assert.Len(t, reports, 1)
+	assert.Equal(t, []*models.TestReport{runner.report}, reporter.printed)
+}
+
+func TestTrigger_Error(t *testing.T) {
+	runner := &fakeRunner{err: errors.New("no tests found")}
+	watcher := NewTestWatcherService(runner, &fakeReporter{})
+
+	// Failed runs are handed over without being recorded in the history
+	var got error
+	watcher.SetReportHandler(func(report *models.TestReport, err error) {
+		assert.Nil(t, report)
+		got = err
+	})
+	watcher.Trigger(context.Background(), nil, models.TestRunOptions{})
+	assert.EqualError(t, got, "no tests found")
+	assert.Equal(t, 1, runner.runs)
+	assert.Empty(t, watcher.History().Runs(models.TestResult{Name: "listUsers", FilePath: "users.http"}))
+}
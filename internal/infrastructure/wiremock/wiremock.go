@@ -0,0 +1,272 @@
+// Package wiremock exports recorded and snapshotted request/response pairs as
+// WireMock stub mappings, so a stub server can mirror the state of the tests.
+package wiremock
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// Directories of a WireMock root
+const (
+	MappingsDir = "mappings"
+	FilesDir    = "__files"
+)
+
+var (
+	// variablePattern matches {{variable}} references
+	variablePattern = regexp.MustCompile(`\{\{[^}]*\}\}`)
+
+	// slugPattern matches the characters replaced in file names
+	slugPattern = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// skippedHeaders are response headers describing the original transfer, and
+// the pseudo-headers of snapshots, which stubs don't replay
+var skippedHeaders = map[string]bool{
+	"connection":        true,
+	"content-encoding":  true,
+	"content-length":    true,
+	"date":              true,
+	"keep-alive":        true,
+	"transfer-encoding": true,
+	"initial-status":    true,
+	"original-encoding": true,
+	"final-url":         true,
+}
+
+// Exchange is a request and the response to replay for it
+type Exchange struct {
+	Name         string
+	Method       string
+	URL          string // May hold {{variables}}, which match any value
+	Body         string // Request body
+	Status       int
+	Headers      map[string][]string // Response headers
+	ResponseBody string
+}
+
+// Mapping is a WireMock stub mapping
+type Mapping struct {
+	Name     string             `json:"name,omitempty"`
+	Request  RequestPattern     `json:"request"`
+	Response ResponseDefinition `json:"response"`
+}
+
+// RequestPattern is the request a stub matches
+type RequestPattern struct {
+	Method       string                   `json:"method"`
+	URL          string                   `json:"url,omitempty"`
+	URLPattern   string                   `json:"urlPattern,omitempty"`
+	BodyPatterns []map[string]interface{} `json:"bodyPatterns,omitempty"`
+}
+
+// ResponseDefinition is the response a stub returns
+type ResponseDefinition struct {
+	Status       int                    `json:"status"`
+	Headers      map[string]interface{} `json:"headers,omitempty"`
+	BodyFileName string                 `json:"bodyFileName,omitempty"`
+}
+
+// FromResults returns the exchanges recorded in test results, including the
+// steps of sequences flattened by the caller
+func FromResults(results []models.TestResult) []Exchange {
+	var exchanges []Exchange
+	for _, result := range results {
+		response := result.RawResponse
+		if response == nil {
+			response = result.Response
+		}
+		if result.Request == nil || response == nil {
+			continue
+		}
+		exchanges = append(exchanges, Exchange{
+			Name:         result.Name,
+			Method:       result.Request.Method,
+			URL:          result.Request.URL,
+			Body:         result.Request.Body,
+			Status:       response.StatusCode,
+			Headers:      response.Headers,
+			ResponseBody: string(response.Body),
+		})
+	}
+	return exchanges
+}
+
+// requestURL returns the path and query of a request URL, dropping the scheme
+// and host or a leading base URL variable such as {{baseUrl}}
+func requestURL(rawURL string) string {
+	if strings.HasPrefix(rawURL, "{{") {
+		if end := strings.Index(rawURL, "}}"); end >= 0 {
+			rest := rawURL[end+2:]
+			if rest == "" || strings.HasPrefix(rest, "/") || strings.HasPrefix(rest, "?") {
+				rawURL = rest
+			}
+		}
+	}
+	if strings.Contains(rawURL, "://") {
+		if parsed, err := url.Parse(variablePattern.ReplaceAllString(rawURL, "x")); err == nil && parsed.Host != "" {
+			// Keep variables in the path by cutting the original after the host
+			rawURL = rawURL[strings.Index(rawURL, "://")+3:]
+			if slash := strings.IndexAny(rawURL, "/?"); slash >= 0 {
+				rawURL = rawURL[slash:]
+			} else {
+				rawURL = ""
+			}
+		}
+	}
+	if !strings.HasPrefix(rawURL, "/") {
+		rawURL = "/" + rawURL
+	}
+	return rawURL
+}
+
+// NewMapping returns the stub mapping of an exchange, whose response body is
+// served from the body file given
+func NewMapping(exchange Exchange, bodyFileName string) Mapping {
+	method := strings.ToUpper(exchange.Method)
+	if method == models.MethodGRPC {
+		method = "POST"
+	}
+
+	mapping := Mapping{
+		Name: exchange.Name,
+		Request: RequestPattern{
+			Method: method,
+		},
+		Response: ResponseDefinition{
+			Status:       exchange.Status,
+			BodyFileName: bodyFileName,
+		},
+	}
+
+	// Variables match any value of a path segment or query parameter
+	path := requestURL(exchange.URL)
+	if variablePattern.MatchString(path) {
+		parts := variablePattern.Split(path, -1)
+		for i, part := range parts {
+			parts[i] = regexp.QuoteMeta(part)
+		}
+		mapping.Request.URLPattern = strings.Join(parts, "[^/?&]+")
+	} else {
+		mapping.Request.URL = path
+	}
+
+	// Match the request body unless it depends on variables
+	body := strings.TrimSpace(exchange.Body)
+	if body != "" && !variablePattern.MatchString(body) {
+		if json.Valid([]byte(body)) {
+			mapping.Request.BodyPatterns = []map[string]interface{}{{"equalToJson": body, "ignoreExtraElements": true}}
+		} else {
+			mapping.Request.BodyPatterns = []map[string]interface{}{{"equalTo": body}}
+		}
+	}
+
+	for name, values := range exchange.Headers {
+		if skippedHeaders[strings.ToLower(name)] || len(values) == 0 {
+			continue
+		}
+		if mapping.Response.Headers == nil {
+			mapping.Response.Headers = make(map[string]interface{})
+		}
+		if len(values) == 1 {
+			mapping.Response.Headers[name] = values[0]
+		} else {
+			mapping.Response.Headers[name] = values
+		}
+	}
+
+	return mapping
+}
+
+// Write writes a stub mapping per exchange into the mappings directory of a
+// WireMock root, with the response bodies in its __files directory. Exchanges
+// repeating the method, URL and body of an earlier one are skipped. It returns
+// the number of stubs written.
+func Write(root string, exchanges []Exchange) (int, error) {
+	for _, dir := range []string{MappingsDir, FilesDir} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			return 0, fmt.Errorf("failed to create %s: %w", dir, err)
+		}
+	}
+
+	seen := make(map[string]bool)
+	names := make(map[string]int)
+	written := 0
+	for _, exchange := range exchanges {
+		key := strings.ToUpper(exchange.Method) + " " + requestURL(exchange.URL) + "\n" + strings.TrimSpace(exchange.Body)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		name := slug(exchange)
+		names[name]++
+		if names[name] > 1 {
+			name = fmt.Sprintf("%s-%d", name, names[name])
+		}
+
+		bodyFileName := ""
+		if exchange.ResponseBody != "" {
+			bodyFileName = name + bodyExtension(exchange.Headers)
+			if err := os.WriteFile(filepath.Join(root, FilesDir, bodyFileName), []byte(exchange.ResponseBody), 0644); err != nil {
+				return written, fmt.Errorf("failed to write body of %s: %w", name, err)
+			}
+		}
+
+		data, err := json.MarshalIndent(NewMapping(exchange, bodyFileName), "", "  ")
+		if err != nil {
+			return written, fmt.Errorf("failed to encode mapping %s: %w", name, err)
+		}
+		if err := os.WriteFile(filepath.Join(root, MappingsDir, name+".json"), append(data, '\n'), 0644); err != nil {
+			return written, fmt.Errorf("failed to write mapping %s: %w", name, err)
+		}
+		written++
+	}
+
+	return written, nil
+}
+
+// slug returns the file name of an exchange's stub, from its method and path
+func slug(exchange Exchange) string {
+	path, _, _ := strings.Cut(requestURL(exchange.URL), "?")
+	path = variablePattern.ReplaceAllStringFunc(path, func(variable string) string {
+		return strings.Trim(variable, "{}")
+	})
+	name := strings.Trim(slugPattern.ReplaceAllString(strings.ToLower(exchange.Method+" "+path), "-"), "-")
+	if name == "" {
+		return "stub"
+	}
+	return name
+}
+
+// bodyExtension returns the file extension of a response body from its content type
+func bodyExtension(headers map[string][]string) string {
+	var contentType string
+	for name, values := range headers {
+		if strings.EqualFold(name, "Content-Type") && len(values) > 0 {
+			contentType = values[0]
+		}
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case strings.Contains(mediaType, "json"):
+		return ".json"
+	case strings.Contains(mediaType, "xml"):
+		return ".xml"
+	case strings.Contains(mediaType, "html"):
+		return ".html"
+	case strings.HasPrefix(mediaType, "text/"):
+		return ".txt"
+	}
+	return ".bin"
+}
@@ -0,0 +1,92 @@
+package wiremock
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"https://api.example.com/users/42?active=true", "/users/42?active=true"},
+		{"{{baseUrl}}/users/{{userId}}", "/users/{{userId}}"},
+		{"http://{{host}}/users", "/users"},
+		{"https://api.example.com", "/"},
+		{"/health", "/health"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			assert.Equal(t, tt.expected, requestURL(tt.url))
+		})
+	}
+}
+
+func TestNewMapping(t *testing.T) {
+	mapping := NewMapping(Exchange{
+		Name:   "getUser",
+		Method: "get",
+		URL:    "{{baseUrl}}/users/{{userId}}?expand=roles",
+		Status: 200,
+		Headers: map[string][]string{
+			"Content-Type":   {"application/json"},
+			"Content-Length": {"27"},
+			"Set-Cookie":     {"a=1", "b=2"},
+		},
+	}, "get-users-userid.json")
+
+	assert.Equal(t, "GET", mapping.Request.Method)
+	assert.Empty(t, mapping.Request.URL)
+	assert.Equal(t, `/users/[^/?&]+\?expand=roles`, mapping.Request.URLPattern)
+	assert.Equal(t, map[string]interface{}{
+		"Content-Type": "application/json",
+		"Set-Cookie":   []string{"a=1", "b=2"},
+	}, mapping.Response.Headers)
+	assert.Equal(t, "get-users-userid.json", mapping.Response.BodyFileName)
+
+	mapping = NewMapping(Exchange{Method: "POST", URL: "https://api.example.com/users", Body: `{"name": "Ada"}`, Status: 201}, "")
+	assert.Equal(t, "/users", mapping.Request.URL)
+	assert.Equal(t, []map[string]interface{}{{"equalToJson": `{"name": "Ada"}`, "ignoreExtraElements": true}}, mapping.Request.BodyPatterns)
+
+	mapping = NewMapping(Exchange{Method: "POST", URL: "/users", Body: `{"name": "{{name}}"}`, Status: 201}, "")
+	assert.Empty(t, mapping.Request.BodyPatterns)
+}
+
+func TestWrite(t *testing.T) {
+	root := t.TempDir()
+	exchanges := []Exchange{
+		{Method: "GET", URL: "{{baseUrl}}/users/1", Status: 200, Headers: map[string][]string{"Content-Type": {"application/json; charset=utf-8"}}, ResponseBody: `{"id": 1}`},
+		{Method: "GET", URL: "{{baseUrl}}/users/1", Status: 200, ResponseBody: `{"id": 1}`},
+		{Method: "GET", URL: "{{baseUrl}}/users/1?verbose=true", Status: 200, ResponseBody: "ok"},
+		{Method: "DELETE", URL: "{{baseUrl}}/users/1", Status: 204},
+	}
+
+	written, err := Write(root, exchanges)
+	require.NoError(t, err)
+	assert.Equal(t, 3, written)
+
+	data, err := os.ReadFile(filepath.Join(root, MappingsDir, "get-users-1.json"))
+	require.NoError(t, err)
+	var mapping Mapping
+	require.NoError(t, json.Unmarshal(data, &mapping))
+	assert.Equal(t, "/users/1", mapping.Request.URL)
+	assert.Equal(t, "get-users-1.json", mapping.Response.BodyFileName)
+
+	body, err := os.ReadFile(filepath.Join(root, FilesDir, "get-users-1.json"))
+	require.NoError(t, err)
+	assert.Equal(t, `{"id": 1}`, string(body))
+
+	assert.FileExists(t, filepath.Join(root, MappingsDir, "get-users-1-2.json"))
+	assert.FileExists(t, filepath.Join(root, FilesDir, "get-users-1-2.bin"))
+
+	data, err = os.ReadFile(filepath.Join(root, MappingsDir, "delete-users-1.json"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "bodyFileName")
+}
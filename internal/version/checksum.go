@@ -0,0 +1,50 @@
+package version
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ChecksumFile holds, in an output directory, the checksum of the generation
+// that wrote it
+const ChecksumFile = ".swagger-to-http.sum"
+
+// GenerationChecksum identifies a generation by the running tool version, the
+// hash of the spec and the options, given as "name=value", in any order
+func GenerationChecksum(specHash string, options []string) string {
+	sorted := append([]string(nil), options...)
+	sort.Strings(sorted)
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "version=%s\nspec=%s\n", Version, specHash)
+	for _, option := range sorted {
+		fmt.Fprintf(hash, "%s\n", option)
+	}
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil))
+}
+
+// ReadChecksum returns the generation checksum recorded in a directory, empty
+// when there is none
+func ReadChecksum(dir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ChecksumFile))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read generation checksum: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// WriteChecksum records the checksum of the generation that wrote a directory
+func WriteChecksum(dir, checksum string) error {
+	if err := os.WriteFile(filepath.Join(dir, ChecksumFile), []byte(checksum+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write generation checksum: %w", err)
+	}
+	return nil
+}
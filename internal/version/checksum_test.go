@@ -0,0 +1,35 @@
+package version
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerationChecksum(t *testing.T) {
+	original := Version
+	defer func() { Version = original }()
+	Version = "1.4.0"
+
+	checksum := GenerationChecksum("sha256:ab", []string{"base-url=https://api.example.com", "body-mode=full"})
+	assert.Equal(t, checksum, GenerationChecksum("sha256:ab", []string{"body-mode=full", "base-url=https://api.example.com"}))
+	assert.NotEqual(t, checksum, GenerationChecksum("sha256:cd", []string{"base-url=https://api.example.com", "body-mode=full"}))
+	assert.NotEqual(t, checksum, GenerationChecksum("sha256:ab", []string{"base-url=https://api.example.com", "body-mode=minimal"}))
+
+	Version = "1.5.0"
+	assert.NotEqual(t, checksum, GenerationChecksum("sha256:ab", []string{"base-url=https://api.example.com", "body-mode=full"}))
+}
+
+func TestChecksumFile(t *testing.T) {
+	dir := t.TempDir()
+
+	checksum, err := ReadChecksum(dir)
+	require.NoError(t, err)
+	assert.Empty(t, checksum)
+
+	require.NoError(t, WriteChecksum(dir, "sha256:ab"))
+	checksum, err = ReadChecksum(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:ab", checksum)
+}
@@ -0,0 +1,98 @@
+package version
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// StampDirective marks the version stamp comment in generated .http files and snapshots
+const StampDirective = "@generated-by"
+
+// ErrIncompatibleVersion is returned when an artifact was generated by an incompatible major version
+var ErrIncompatibleVersion = errors.New("incompatible artifact version")
+
+// Stamp identifies the tool version and spec that produced an artifact
+type Stamp struct {
+	Version  string
+	SpecHash string
+}
+
+// NewStamp creates a stamp for the running tool version
+func NewStamp(specHash string) Stamp {
+	return Stamp{
+		Version:  Version,
+		SpecHash: specHash,
+	}
+}
+
+// String formats the stamp as a directive, e.g. "@generated-by swagger-to-http/1.2.0 spec=sha256:ab12..."
+func (s Stamp) String() string {
+	stamp := fmt.Sprintf("%s swagger-to-http/%s", StampDirective, s.Version)
+	if s.SpecHash != "" {
+		stamp += " spec=" + s.SpecHash
+	}
+	return stamp
+}
+
+// ParseStamp parses a stamp directive, with or without a leading comment marker
+func ParseStamp(line string) (Stamp, bool) {
+	line = strings.TrimSpace(line)
+	line = strings.TrimSpace(strings.TrimLeft(line, "#/"))
+	if !strings.HasPrefix(line, StampDirective+" ") {
+		return Stamp{}, false
+	}
+
+	var stamp Stamp
+	for _, field := range strings.Fields(strings.TrimPrefix(line, StampDirective)) {
+		switch {
+		case strings.HasPrefix(field, "swagger-to-http/"):
+			stamp.Version = strings.TrimPrefix(field, "swagger-to-http/")
+		case strings.HasPrefix(field, "spec="):
+			stamp.SpecHash = strings.TrimPrefix(field, "spec=")
+		}
+	}
+
+	return stamp, stamp.Version != ""
+}
+
+// HashSpec returns the content hash used to identify a Swagger/OpenAPI document
+func HashSpec(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// MajorVersion returns the major component of a semantic version.
+// Development builds and unparseable versions report false.
+func MajorVersion(v string) (int, bool) {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	majorPart := strings.SplitN(v, ".", 2)[0]
+
+	major, err := strconv.Atoi(majorPart)
+	if err != nil {
+		return 0, false
+	}
+	return major, true
+}
+
+// CheckCompatibility checks whether an artifact generated by the given version
+// can be used with the running tool. Unknown versions are treated as compatible.
+func CheckCompatibility(artifactVersion string) error {
+	artifactMajor, ok := MajorVersion(artifactVersion)
+	if !ok {
+		return nil
+	}
+	currentMajor, ok := MajorVersion(Version)
+	if !ok {
+		return nil
+	}
+
+	if artifactMajor != currentMajor {
+		return fmt.Errorf("%w: generated by v%s, running v%s", ErrIncompatibleVersion,
+			strings.TrimPrefix(artifactVersion, "v"), strings.TrimPrefix(Version, "v"))
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package version
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStampRoundTrip(t *testing.T) {
+	stamp := Stamp{Version: "1.4.0", SpecHash: HashSpec([]byte(`{"openapi":"3.0.0"}`))}
+
+	parsed, ok := ParseStamp("# " + stamp.String())
+	assert.True(t, ok)
+	assert.Equal(t, stamp, parsed)
+
+	_, ok = ParseStamp("# @name getUsers")
+	assert.False(t, ok)
+}
+
+func TestCheckCompatibility(t *testing.T) {
+	original := Version
+	defer func() { Version = original }()
+
+	tests := []struct {
+		name           string
+		current        string
+		artifact       string
+		expectIncompat bool
+	}{
+		{"same major", "1.4.0", "1.0.2", false},
+		{"different major", "2.0.0", "1.9.0", true},
+		{"v prefix", "v2.1.0", "2.0.0", false},
+		{"dev build", "dev", "1.0.0", false},
+		{"unstamped artifact", "1.0.0", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Version = tt.current
+			err := CheckCompatibility(tt.artifact)
+			assert.Equal(t, tt.expectIncompat, errors.Is(err, ErrIncompatibleVersion))
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Kinds of the requests sent to executable plugins
+const (
+	KindGenerate = "generate"
+	KindReport   = "report"
+)
+
+// ExecRequest is the JSON an executable plugin reads on its standard input
+type ExecRequest struct {
+	Kind     string         `json:"kind"`
+	Generate *GenerateInput `json:"generate,omitempty"`
+	Report   *TestReport    `json:"report,omitempty"`
+}
+
+// ExecResponse is the JSON an executable plugin writes on its standard
+// output: the files it generated or the report it rendered, or why it failed
+type ExecResponse struct {
+	Files   []File `json:"files,omitempty"`
+	Content string `json:"content,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Executable is a plugin run as an external program, acting as a generator
+// and a reporter
+type Executable struct {
+	command string
+	args    []string
+}
+
+// NewExecutable creates an Executable running a command line, split on spaces
+func NewExecutable(commandLine string) *Executable {
+	fields := strings.Fields(commandLine)
+	if len(fields) == 0 {
+		return &Executable{}
+	}
+	return &Executable{command: fields[0], args: fields[1:]}
+}
+
+// Generate runs the executable to generate the files of its format
+func (e *Executable) Generate(ctx context.Context, input *GenerateInput) ([]File, error) {
+	response, err := e.call(ctx, &ExecRequest{Kind: KindGenerate, Generate: input})
+	if err != nil {
+		return nil, err
+	}
+	return response.Files, nil
+}
+
+// Report runs the executable to render a report in its format
+func (e *Executable) Report(ctx context.Context, report *TestReport) ([]byte, error) {
+	response, err := e.call(ctx, &ExecRequest{Kind: KindReport, Report: report})
+	if err != nil {
+		return nil, err
+	}
+	return []byte(response.Content), nil
+}
+
+// call runs the executable with a request and reads its response
+func (e *Executable) call(ctx context.Context, request *ExecRequest) (*ExecResponse, error) {
+	if e.command == "" {
+		return nil, fmt.Errorf("plugin has no command")
+	}
+	input, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode plugin request: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, e.command, e.args...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("plugin %s failed: %w: %s", e.command, err, message)
+		}
+		return nil, fmt.Errorf("plugin %s failed: %w", e.command, err)
+	}
+
+	var response ExecResponse
+	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response of plugin %s: %w", e.command, err)
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf("plugin %s failed: %s", e.command, response.Error)
+	}
+	return &response, nil
+}
@@ -0,0 +1,27 @@
+package plugin
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WriteFiles writes the files of a generator under dir, refusing paths
+// outside of it
+func WriteFiles(dir string, files []File) error {
+	for _, file := range files {
+		path := filepath.Clean(filepath.FromSlash(file.Path))
+		if file.Path == "" || filepath.IsAbs(path) || path == ".." || strings.HasPrefix(path, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("invalid plugin file path %q: must be relative to the output directory", file.Path)
+		}
+		target := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", target, err)
+		}
+		if err := os.WriteFile(target, []byte(file.Content), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", target, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package plugin
+
+import (
+	"fmt"
+	goplugin "plugin"
+)
+
+// RegisterSymbol is the name of the function Go plugins export to register
+// their generators and reporters, of type func(*Registry)
+const RegisterSymbol = "Register"
+
+// LoadGoPlugin opens a Go plugin built with -buildmode=plugin and calls its
+// Register function with registry. Go plugins must be built with the same Go
+// version and dependencies as swagger-to-http, and are only supported on
+// Linux, FreeBSD and macOS.
+func LoadGoPlugin(path string, registry *Registry) error {
+	p, err := goplugin.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open plugin %s: %w", path, err)
+	}
+	symbol, err := p.Lookup(RegisterSymbol)
+	if err != nil {
+		return fmt.Errorf("failed to load plugin %s: %w", path, err)
+	}
+	register, ok := symbol.(func(*Registry))
+	if !ok {
+		return fmt.Errorf("failed to load plugin %s: %s is %T, not func(*plugin.Registry)", path, RegisterSymbol, symbol)
+	}
+	register(registry)
+	return nil
+}
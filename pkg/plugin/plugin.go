@@ -0,0 +1,191 @@
+// Package plugin lets third parties add output formats to the generate command
+// and report formats to the test commands without changing swagger-to-http.
+// Programs embedding swagger-to-http register them on a Registry, Go plugins
+// export a Register function doing the same, and programs written in any
+// language are run as executables exchanging JSON on their standard input and
+// output.
+package plugin
+
+import (
+	"context"
+	"path"
+	"sort"
+	"sync"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+)
+
+// SwaggerDoc is the parsed Swagger/OpenAPI document handed to generators
+type SwaggerDoc = models.SwaggerDoc
+
+// TestReport is the report of a test run handed to reporters
+type TestReport = models.TestReport
+
+// Request is a request generated from an operation of the spec
+type Request struct {
+	File    string            `json:"file"`
+	Name    string            `json:"name"`
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Path    string            `json:"path,omitempty"`
+	Tag     string            `json:"tag,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+}
+
+// CollectionRequests lists the requests of a generated collection, with the
+// path of their file relative to the root directory
+func CollectionRequests(collection *models.HTTPCollection) []Request {
+	requests := []Request{}
+	add := func(dir string, file models.HTTPFile) {
+		for _, request := range file.Requests {
+			headers := make(map[string]string, len(request.Headers))
+			for name, value := range request.Headers {
+				headers[name] = value
+			}
+			requests = append(requests, Request{
+				File:    path.Join(dir, file.Filename),
+				Name:    request.Name,
+				Method:  request.Method,
+				URL:     request.URL,
+				Path:    request.Path,
+				Tag:     request.Tag,
+				Headers: headers,
+				Body:    request.Body,
+			})
+		}
+	}
+	for _, file := range collection.RootFiles {
+		add("", file)
+	}
+	for _, dir := range collection.Directories {
+		for _, file := range dir.Files {
+			add(dir.Path, file)
+		}
+	}
+	return requests
+}
+
+// GenerateInput is what generators generate their files from
+type GenerateInput struct {
+	Spec     *SwaggerDoc       `json:"spec"`
+	Requests []Request         `json:"requests"`
+	Options  map[string]string `json:"options,omitempty"`
+}
+
+// File is a file written by a generator, its path relative to the output
+// directory
+type File struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// Generator generates the files of an output format
+type Generator interface {
+	Generate(ctx context.Context, input *GenerateInput) ([]File, error)
+}
+
+// Reporter renders test reports in a report format
+type Reporter interface {
+	Report(ctx context.Context, report *TestReport) ([]byte, error)
+}
+
+// GeneratorFunc adapts a function to the Generator interface
+type GeneratorFunc func(ctx context.Context, input *GenerateInput) ([]File, error)
+
+// Generate calls f
+func (f GeneratorFunc) Generate(ctx context.Context, input *GenerateInput) ([]File, error) {
+	return f(ctx, input)
+}
+
+// ReporterFunc adapts a function to the Reporter interface
+type ReporterFunc func(ctx context.Context, report *TestReport) ([]byte, error)
+
+// Report calls f
+func (f ReporterFunc) Report(ctx context.Context, report *TestReport) ([]byte, error) {
+	return f(ctx, report)
+}
+
+// Registry holds the generators and reporters by the format they produce
+type Registry struct {
+	mu         sync.RWMutex
+	generators map[string]Generator
+	reporters  map[string]Reporter
+}
+
+// NewRegistry creates an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{
+		generators: make(map[string]Generator),
+		reporters:  make(map[string]Reporter),
+	}
+}
+
+// DefaultRegistry is the registry the commands look formats up in
+var DefaultRegistry = NewRegistry()
+
+// RegisterGenerator registers the generator of a format, replacing any
+// registered before
+func (r *Registry) RegisterGenerator(format string, generator Generator) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.generators[format] = generator
+}
+
+// RegisterReporter registers the reporter of a format, replacing any
+// registered before
+func (r *Registry) RegisterReporter(format string, reporter Reporter) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reporters[format] = reporter
+}
+
+// Generator returns the generator of a format
+func (r *Registry) Generator(format string) (Generator, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	generator, ok := r.generators[format]
+	return generator, ok
+}
+
+// Reporter returns the reporter of a format
+func (r *Registry) Reporter(format string) (Reporter, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	reporter, ok := r.reporters[format]
+	return reporter, ok
+}
+
+// Generators returns the formats of the registered generators, sorted
+func (r *Registry) Generators() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return sortedKeys(r.generators)
+}
+
+// Reporters returns the formats of the registered reporters, sorted
+func (r *Registry) Reporters() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return sortedKeys(r.reporters)
+}
+
+// RegisterGenerator registers the generator of a format in DefaultRegistry
+func RegisterGenerator(format string, generator Generator) {
+	DefaultRegistry.RegisterGenerator(format, generator)
+}
+
+// RegisterReporter registers the reporter of a format in DefaultRegistry
+func RegisterReporter(format string, reporter Reporter) {
+	DefaultRegistry.RegisterReporter(format, reporter)
+}
+
+// sortedKeys returns the keys of a map, sorted
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
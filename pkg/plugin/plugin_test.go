@@ -0,0 +1,107 @@
+package plugin
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/edgardnogueira/swagger-to-http/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry()
+	registry.RegisterGenerator("k6", GeneratorFunc(func(ctx context.Context, input *GenerateInput) ([]File, error) {
+		return []File{{Path: "script.js", Content: input.Requests[0].URL}}, nil
+	}))
+	registry.RegisterReporter("text", ReporterFunc(func(ctx context.Context, report *TestReport) ([]byte, error) {
+		return []byte(report.Name), nil
+	}))
+
+	assert.Equal(t, []string{"k6"}, registry.Generators())
+	assert.Equal(t, []string{"text"}, registry.Reporters())
+
+	generator, ok := registry.Generator("k6")
+	require.True(t, ok)
+	files, err := generator.Generate(context.Background(), &GenerateInput{Requests: []Request{{URL: "https://api.example.com/pets"}}})
+	require.NoError(t, err)
+	assert.Equal(t, []File{{Path: "script.js", Content: "https://api.example.com/pets"}}, files)
+
+	reporter, ok := registry.Reporter("text")
+	require.True(t, ok)
+	output, err := reporter.Report(context.Background(), &TestReport{Name: "smoke"})
+	require.NoError(t, err)
+	assert.Equal(t, "smoke", string(output))
+
+	_, ok = registry.Generator("gatling")
+	assert.False(t, ok)
+}
+
+func TestCollectionRequests(t *testing.T) {
+	collection := &models.HTTPCollection{
+		RootFiles: []models.HTTPFile{{Filename: "default.http", Requests: []models.HTTPRequest{
+			{Name: "health", Method: "GET", URL: "{{baseUrl}}/health"},
+		}}},
+		Directories: []models.HTTPDirectory{{Path: "pets", Files: []models.HTTPFile{{Filename: "pets.http", Requests: []models.HTTPRequest{
+			{Name: "addPet", Method: "POST", URL: "{{baseUrl}}/pets", Tag: "pets", Headers: map[string]string{"Content-Type": "application/json"}, Body: "{}"},
+		}}}}},
+	}
+
+	requests := CollectionRequests(collection)
+	require.Len(t, requests, 2)
+	assert.Equal(t, "default.http", requests[0].File)
+	assert.Equal(t, Request{
+		File:    "pets/pets.http",
+		Name:    "addPet",
+		Method:  "POST",
+		URL:     "{{baseUrl}}/pets",
+		Tag:     "pets",
+		Headers: map[string]string{"Content-Type": "application/json"},
+		Body:    "{}",
+	}, requests[1])
+}
+
+func TestExecutable(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+	dir := t.TempDir()
+	script := filepath.Join(dir, "plugin.sh")
+	require.NoError(t, os.WriteFile(script, []byte(`#!/bin/sh
+input=$(cat)
+case "$input" in
+  *'"kind":"generate"'*) echo '{"files":[{"path":"load/script.js","content":"export default function () {}"}]}' ;;
+  *'"kind":"report"'*) echo '{"content":"1 passed"}' ;;
+  *) echo '{"error":"unknown request"}' ;;
+esac
+`), 0755))
+
+	plugin := NewExecutable("sh " + script)
+	files, err := plugin.Generate(context.Background(), &GenerateInput{})
+	require.NoError(t, err)
+	assert.Equal(t, []File{{Path: "load/script.js", Content: "export default function () {}"}}, files)
+
+	output, err := plugin.Report(context.Background(), &TestReport{Name: "smoke"})
+	require.NoError(t, err)
+	assert.Equal(t, "1 passed", string(output))
+
+	failing := filepath.Join(dir, "failing.sh")
+	require.NoError(t, os.WriteFile(failing, []byte("#!/bin/sh\necho 'no spec' >&2\nexit 1\n"), 0755))
+	_, err = NewExecutable("sh "+failing).Generate(context.Background(), &GenerateInput{})
+	assert.ErrorContains(t, err, "no spec")
+}
+
+func TestWriteFiles(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, WriteFiles(dir, []File{{Path: "load/script.js", Content: "export default function () {}"}}))
+	data, err := os.ReadFile(filepath.Join(dir, "load", "script.js"))
+	require.NoError(t, err)
+	assert.Equal(t, "export default function () {}", string(data))
+
+	for _, path := range []string{"", "../outside.js", "/etc/passwd"} {
+		assert.Error(t, WriteFiles(dir, []File{{Path: path}}), path)
+	}
+}